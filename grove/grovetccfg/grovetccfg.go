@@ -24,6 +24,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -522,7 +523,44 @@ func createRulesOldAPI(toc *to.Session, host string, certDir string, servers map
 	}
 	dsCerts := makeDSCertMap(cdnSSLKeys)
 
-	return createRulesOld(host, deliveryservices, parents, deliveryserviceRegexes, cdns, serverParameters, dsCerts, certDir)
+	originGroups, err := getOriginGroups(toc, hostServer.CDNName)
+	if err != nil {
+		fmt.Println(time.Now().Format(time.RFC3339Nano) + " Error getting '" + hostServer.CDNName + "' origin groups: " + err.Error())
+		os.Exit(1)
+	}
+
+	return createRulesOld(host, deliveryservices, parents, deliveryserviceRegexes, cdns, serverParameters, dsCerts, certDir, originGroups)
+}
+
+// getOriginGroups returns the origin groups of the delivery services on the given CDN, keyed by delivery service XMLID.
+// If Traffic Ops doesn't support origin groups, no groups are returned.
+func getOriginGroups(toc *to.Session, cdnName string) (map[string]tc.OriginGroup, error) {
+	// RawRequest should generally never be used, but the v2 client has no origin group funcs, because origin groups are new in API 3.0.
+	resp, _, err := toc.RawRequest(http.MethodGet, "/api/3.0/origin_groups?cdn="+url.QueryEscape(cdnName), nil)
+	if err != nil {
+		return nil, errors.New("requesting origin groups: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	originGroups := map[string]tc.OriginGroup{}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		fmt.Println(time.Now().Format(time.RFC3339Nano) + " Traffic Ops does not support origin groups, creating rules without them")
+		return originGroups, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("requesting origin groups: Traffic Ops returned " + strconv.Itoa(resp.StatusCode))
+	}
+	groupsResp := tc.OriginGroupsResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&groupsResp); err != nil {
+		return nil, errors.New("decoding origin groups: " + err.Error())
+	}
+	for _, group := range groupsResp.Response {
+		if group.DeliveryService == nil {
+			continue
+		}
+		originGroups[*group.DeliveryService] = group
+	}
+	return originGroups, nil
 }
 
 // func createRulesNewAPI(toc *to.Session, host string, certDir string) (remap.RemapRules, error) {
@@ -863,6 +901,7 @@ func createRulesOld(
 	hostParams []tc.Parameter,
 	dsCerts map[string]tc.CDNSSLKeys,
 	certDir string,
+	originGroups map[string]tc.OriginGroup,
 ) (remap.RemapRules, error) {
 	rules := []remapdata.RemapRule{}
 	allowedIPs, err := getAllowIP(hostParams)
@@ -962,17 +1001,23 @@ func createRulesOld(
 					if err != nil {
 						return remap.RemapRules{}, fmt.Errorf("error parsing deliveryservice %v proxy_url: %v", *ds.XMLID, proxyURLStr)
 					}
-					ruleTo := remapdata.RemapRuleTo{
-						RemapRuleToBase: remapdata.RemapRuleToBase{
-							URL:      orgServerFQDN,
-							Weight:   &weight,
-							RetryNum: &retryNum,
-						},
-						RetryCodes: DefaultRetryCodes(),
-						ProxyURL:   proxyURL,
-						Timeout:    &timeout,
+					if group, ok := originGroups[*ds.XMLID]; ok && len(group.Members) > 0 {
+						groupTo, groupParentSelection := makeOriginGroupTo(group, proxyURL, retryNum, timeout)
+						rule.To = append(rule.To, groupTo...)
+						rule.ParentSelection = &groupParentSelection
+					} else {
+						ruleTo := remapdata.RemapRuleTo{
+							RemapRuleToBase: remapdata.RemapRuleToBase{
+								URL:      orgServerFQDN,
+								Weight:   &weight,
+								RetryNum: &retryNum,
+							},
+							RetryCodes: DefaultRetryCodes(),
+							ProxyURL:   proxyURL,
+							Timeout:    &timeout,
+						}
+						rule.To = append(rule.To, ruleTo)
 					}
-					rule.To = append(rule.To, ruleTo)
 					rule.RetryNum = &retryNum
 					rule.Timeout = &timeout
 					rule.RetryCodes = DefaultRetryCodes()
@@ -1049,6 +1094,37 @@ func createRulesOld(
 	return remapRules, nil
 }
 
+// makeOriginGroupTo returns the rule To of the members of an origin group, and the parent selection to choose among them.
+// Failover groups are tried in order, primaries before secondaries. Weighted groups are consistent-hashed by weight.
+func makeOriginGroupTo(group tc.OriginGroup, proxyURL *url.URL, retryNum int, timeout time.Duration) ([]remapdata.RemapRuleTo, remapdata.ParentSelectionType) {
+	members := append(group.MembersByRole(tc.OriginGroupMemberRolePrimary), group.MembersByRole(tc.OriginGroupMemberRoleSecondary)...)
+	parentSelection := remapdata.ParentSelectionTypeFailover
+	if group.Policy != nil && *group.Policy == tc.OriginGroupPolicyWeighted {
+		parentSelection = remapdata.ParentSelectionTypeConsistentHash
+	}
+
+	tos := make([]remapdata.RemapRuleTo, 0, len(members))
+	for _, member := range members {
+		weight := DefaultRuleWeight
+		if member.Weight != nil {
+			weight = float64(*member.Weight)
+		}
+		memberRetryNum := retryNum
+		memberTimeout := timeout
+		tos = append(tos, remapdata.RemapRuleTo{
+			RemapRuleToBase: remapdata.RemapRuleToBase{
+				URL:      member.URL(),
+				Weight:   &weight,
+				RetryNum: &memberRetryNum,
+			},
+			RetryCodes: DefaultRetryCodes(),
+			ProxyURL:   proxyURL,
+			Timeout:    &memberTimeout,
+		})
+	}
+	return tos, parentSelection
+}
+
 func getCertFileName(cert tc.CDNSSLKeys, dir string) string {
 	return dir + string(os.PathSeparator) + strings.Replace(cert.Hostname, "*.", "", -1) + ".crt"
 }
@@ -46,6 +46,7 @@ type ParentSelectionType string
 const (
 	ParentSelectionTypeConsistentHash = ParentSelectionType("consistent-hash")
 	ParentSelectionTypeRoundRobin     = ParentSelectionType("round-robin")
+	ParentSelectionTypeFailover       = ParentSelectionType("failover")
 	ParentSelectionTypeInvalid        = ParentSelectionType("")
 )
 
@@ -55,6 +56,8 @@ func (t ParentSelectionType) String() string {
 		return "consistent-hash"
 	case ParentSelectionTypeRoundRobin:
 		return "round-robin"
+	case ParentSelectionTypeFailover:
+		return "failover"
 	default:
 		return "invalid"
 	}
@@ -68,6 +71,9 @@ func ParentSelectionTypeFromString(s string) ParentSelectionType {
 	if s == "round-robin" {
 		return ParentSelectionTypeRoundRobin
 	}
+	if s == "failover" {
+		return ParentSelectionTypeFailover
+	}
 	return ParentSelectionTypeInvalid
 }
 
@@ -166,6 +172,9 @@ func (r RemapRule) uriGetTo(fromURI string, failures int) (string, *url.URL, *ht
 	switch *r.ParentSelection {
 	case ParentSelectionTypeConsistentHash:
 		return r.uriGetToConsistentHash(fromURI, failures)
+	case ParentSelectionTypeFailover:
+		to := r.To[failures%len(r.To)]
+		return to.URL, to.ProxyURL, to.Transport
 	default:
 		log.Errorf("RemapRule.URI: Rule '%v': Unknown Parent Selection type %v - using first URI in rule\n", r.Name, r.ParentSelection)
 		return r.To[0].URL, r.To[0].ProxyURL, r.To[0].Transport
@@ -65,6 +65,18 @@ func (s *ServerInfo) IsTopLevelCache() bool {
 		(s.SecondaryParentCacheGroupType == tc.CacheGroupOriginTypeName || s.SecondaryParentCacheGroupID == InvalidID)
 }
 
+// TopologyPlacement returns whether the given cachegroup is a node of the given topology,
+// and whether it's in the last tier of the topology, i.e. a node with no parents, which requests the origin.
+func TopologyPlacement(cacheGroup string, topology tc.Topology) (bool, bool) {
+	for _, node := range topology.Nodes {
+		if node.Cachegroup != cacheGroup {
+			continue
+		}
+		return true, len(node.Parents) == 0
+	}
+	return false, false
+}
+
 func HeaderCommentWithTOVersionStr(name string, nameVersionStr string) string {
 	return "# DO NOT EDIT - Generated for " + name + " by " + nameVersionStr + " on " + time.Now().UTC().Format(HeaderCommentDateFormat) + "\n"
 }
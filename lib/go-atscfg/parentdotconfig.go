@@ -68,6 +68,11 @@ type ParentConfigDS struct {
 	QStringHandling string
 
 	RequiredCapabilities map[ServerCapability]struct{}
+
+	// OriginGroup is the Origin Group of the DS, or nil if it has none.
+	// Top-level caches fail over between, or distribute by weight across,
+	// the members of an Origin Group, rather than going direct to OriginFQDN.
+	OriginGroup *tc.OriginGroup
 }
 
 type ParentConfigDSTopLevel struct {
@@ -231,20 +236,23 @@ func MakeParentDotConfig(
 				parents, secondaryParents := getMSOParentStrs(ds, parentInfos[OriginHost(orgURI.Hostname())], atsMajorVer)
 				textLine += parents + secondaryParents + ` round_robin=` + ds.MSOAlgorithm + ` qstring=` + parentQStr + ` go_direct=false parent_is_proxy=false`
 
-				parentRetry := ds.MSOParentRetry
-				if atsMajorVer >= 6 && parentRetry != "" {
-					if unavailableServerRetryResponsesValid(ds.MSOUnavailableServerRetryResponses) {
-						textLine += ` parent_retry=` + parentRetry + ` unavailable_server_retry_responses=` + ds.MSOUnavailableServerRetryResponses
-					} else {
-						if ds.MSOUnavailableServerRetryResponses != "" {
-							log.Errorln("Malformed unavailable_server_retry_responses parameter '" + ds.MSOUnavailableServerRetryResponses + "', not using!")
-						}
-						textLine += ` parent_retry=` + parentRetry
-					}
-					textLine += ` max_simple_retries=` + ds.MSOMaxSimpleRetries + ` max_unavailable_server_retries=` + ds.MSOMaxUnavailableServerRetries
-				}
+				textLine += getParentRetryStr(ds, atsMajorVer)
 				textLine += "\n" // TODO remove, and join later on "\n" instead of ""?
 				textArr = append(textArr, textLine)
+			} else if ds.OriginGroup != nil && len(ds.OriginGroup.Members) > 0 {
+				roundRobin := "false" // ATS uses the first live parent, in order
+				if ds.OriginGroup.Policy != nil && *ds.OriginGroup.Policy == tc.OriginGroupPolicyWeighted {
+					roundRobin = tc.AlgorithmConsistentHash
+				}
+				groupQStr := "ignore"
+				if ds.QStringHandling == "" && roundRobin == tc.AlgorithmConsistentHash && ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp {
+					groupQStr = "consider"
+				}
+				parents, secondaryParents := getOriginGroupParentStrs(*ds.OriginGroup, atsMajorVer)
+				textLine += "dest_domain=" + orgURI.Hostname() + " port=" + orgURI.Port() + " " + parents + secondaryParents + ` round_robin=` + roundRobin + ` qstring=` + groupQStr + ` go_direct=false parent_is_proxy=false`
+				textLine += getParentRetryStr(ds, atsMajorVer)
+				textLine += "\n"
+				textArr = append(textArr, textLine)
 			}
 		}
 		sort.Sort(sort.StringSlice(textArr))
@@ -440,6 +448,53 @@ func getMSOParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMa
 	return parents, secondaryParents
 }
 
+// getParentRetryStr returns the parent_retry= and related retry settings for ATS parent.config lines which go to origins, for MSO and Origin Groups.
+func getParentRetryStr(ds ParentConfigDSTopLevel, atsMajorVer int) string {
+	parentRetry := ds.MSOParentRetry
+	if atsMajorVer < 6 || parentRetry == "" {
+		return ""
+	}
+	text := ""
+	if unavailableServerRetryResponsesValid(ds.MSOUnavailableServerRetryResponses) {
+		text += ` parent_retry=` + parentRetry + ` unavailable_server_retry_responses=` + ds.MSOUnavailableServerRetryResponses
+	} else {
+		if ds.MSOUnavailableServerRetryResponses != "" {
+			log.Errorln("Malformed unavailable_server_retry_responses parameter '" + ds.MSOUnavailableServerRetryResponses + "', not using!")
+		}
+		text += ` parent_retry=` + parentRetry
+	}
+	text += ` max_simple_retries=` + ds.MSOMaxSimpleRetries + ` max_unavailable_server_retries=` + ds.MSOMaxUnavailableServerRetries
+	return text
+}
+
+// getOriginGroupParentStrs returns the parents= and secondary_parents= strings for ATS parent.config lines, for the members of an Origin Group.
+// Members keep the order of the group, so failover groups are tried in order.
+func getOriginGroupParentStrs(group tc.OriginGroup, atsMajorVer int) (string, string) {
+	parentInfo := []string{}
+	secondaryParentInfo := []string{}
+	for _, member := range group.Members {
+		host, port := member.HostPort()
+		if host == "" {
+			continue
+		}
+		weight := 1
+		if member.Weight != nil {
+			weight = *member.Weight
+		}
+		pTxt := host + ":" + strconv.Itoa(port) + "|" + strconv.Itoa(weight) + ";"
+		if member.Role != nil && *member.Role == tc.OriginGroupMemberRoleSecondary {
+			secondaryParentInfo = append(secondaryParentInfo, pTxt)
+		} else {
+			parentInfo = append(parentInfo, pTxt)
+		}
+	}
+
+	if atsMajorVer >= 6 && len(secondaryParentInfo) > 0 {
+		return `parent="` + strings.Join(parentInfo, "") + `"`, ` secondary_parent="` + strings.Join(secondaryParentInfo, "") + `"`
+	}
+	return `parent="` + strings.Join(parentInfo, "") + strings.Join(secondaryParentInfo, "") + `"`, ""
+}
+
 func MakeParentInfo(
 	server *ServerInfo,
 	serverDomain string, // getCDNDomainByProfileID(tx, server.ProfileID)
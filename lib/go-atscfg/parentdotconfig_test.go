@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
 )
 
 func TestMakeParentDotConfig(t *testing.T) {
@@ -324,3 +325,64 @@ func TestMakeParentDotConfigMSOSecondaryParent(t *testing.T) {
 		t.Errorf("expected secondary parent 'my-parent-1.my-parent-1-domain', actual: '%v'", txt)
 	}
 }
+
+func TestMakeParentDotConfigOriginGroup(t *testing.T) {
+	serverName := "myserver"
+	toolName := "myToolName"
+	toURL := "https://myto.example.net"
+
+	primary := tc.OriginGroupMemberRolePrimary
+	secondary := tc.OriginGroupMemberRoleSecondary
+	policy := tc.OriginGroupPolicyFailover
+	group := &tc.OriginGroup{
+		DeliveryService: util.StrPtr("ds0"),
+		Policy:          &policy,
+		Members: []tc.OriginGroupMember{
+			{FQDN: util.StrPtr("o1.example.net"), Protocol: util.StrPtr("http"), Role: &primary, Weight: util.IntPtr(1)},
+			{FQDN: util.StrPtr("o0.example.net"), Protocol: util.StrPtr("https"), Role: &primary, Weight: util.IntPtr(2)},
+			{FQDN: util.StrPtr("o2.example.net"), Protocol: util.StrPtr("http"), Port: util.IntPtr(8080), Role: &secondary, Weight: util.IntPtr(1)},
+		},
+	}
+
+	parentConfigDSes := []ParentConfigDSTopLevel{
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:          "ds0",
+				QStringIgnore: tc.QStringIgnoreUseInCacheKeyAndPassUp,
+				OriginFQDN:    "http://o1.example.net",
+				Type:          tc.DSTypeHTTP,
+				OriginGroup:   group,
+			},
+			MSOParentRetry:                 ParentConfigDSParamDefaultMSOParentRetry,
+			MSOMaxSimpleRetries:            ParentConfigDSParamDefaultMaxSimpleRetries,
+			MSOMaxUnavailableServerRetries: ParentConfigDSParamDefaultMaxUnavailableServerRetries,
+		},
+	}
+
+	serverInfo := &ServerInfo{
+		CacheGroupID:                42,
+		CDN:                         "myCDN",
+		HostName:                    "myserver",
+		ParentCacheGroupID:          InvalidID,
+		SecondaryParentCacheGroupID: InvalidID,
+		Type:                        "MID",
+	}
+
+	txt := MakeParentDotConfig(serverInfo, 7, toolName, toURL, parentConfigDSes, map[string]string{}, map[OriginHost][]ParentInfo{})
+
+	testComment(t, txt, serverName, toolName, toURL)
+
+	expected := `dest_domain=o1.example.net port=80 parent="o1.example.net:80|1;o0.example.net:443|2;" secondary_parent="o2.example.net:8080|1;" round_robin=false qstring=ignore go_direct=false parent_is_proxy=false parent_retry=both`
+	if !strings.Contains(txt, expected) {
+		t.Errorf("expected failover origin group line '%v', actual: '%v'", expected, txt)
+	}
+
+	weighted := tc.OriginGroupPolicyWeighted
+	group.Policy = &weighted
+	txt = MakeParentDotConfig(serverInfo, 5, toolName, toURL, parentConfigDSes, map[string]string{}, map[OriginHost][]ParentInfo{})
+
+	expected = `parent="o1.example.net:80|1;o0.example.net:443|2;o2.example.net:8080|1;" round_robin=consistent_hash qstring=consider go_direct=false parent_is_proxy=false` + "\n"
+	if !strings.Contains(txt, expected) {
+		t.Errorf("expected weighted origin group line with secondaries as parents for ATS 5 and no retry parameters '%v', actual: '%v'", expected, txt)
+	}
+}
@@ -62,6 +62,8 @@ type RemapConfigDSData struct {
 	AnonymousBlockingEnabled *bool
 	RangeSliceBlockSize      *int
 	Active                   bool
+	// Strategy is the name of the strategies.yaml strategy the remap rules use, or nil if they use parent.config.
+	Strategy *string
 }
 
 func MakeRemapDotConfig(
@@ -113,6 +115,9 @@ func GetServerConfigRemapDotConfigForMid(
 		hasCacheKey := false
 
 		midRemap := ""
+		if ds.Strategy != nil {
+			midRemap += ` @strategy=` + *ds.Strategy
+		}
 		if ds.MidHeaderRewrite != nil && *ds.MidHeaderRewrite != "" {
 			midRemap += ` @plugin=header_rewrite.so @pparam=` + MidHeaderRewriteConfigFileName(ds.Name)
 		}
@@ -221,6 +226,10 @@ func BuildRemapLine(cacheURLConfigParams map[string]string, atsMajorVersion int,
 		text += "map	" + mapFrom + "     " + mapTo + ` @plugin=header_rewrite.so @pparam=dscp/set_dscp_` + strconv.Itoa(ds.DSCP) + ".config"
 	}
 
+	if ds.Strategy != nil {
+		text += ` @strategy=` + *ds.Strategy
+	}
+
 	if ds.EdgeHeaderRewrite != nil && *ds.EdgeHeaderRewrite != "" {
 		text += ` @plugin=header_rewrite.so @pparam=` + EdgeHeaderRewriteConfigFileName(ds.Name)
 	}
@@ -128,6 +128,62 @@ func TestMakeRemapDotConfig(t *testing.T) {
 	}
 }
 
+func TestMakeRemapDotConfigStrategy(t *testing.T) {
+	serverName := tc.CacheName("server0")
+	toToolName := "to0"
+	toURL := "trafficops.example.net"
+	atsMajorVersion := 9
+
+	serverInfo := &ServerInfo{
+		CacheGroupID:                42,
+		CDN:                         "mycdn",
+		CDNID:                       43,
+		DomainName:                  "mydomain",
+		HostName:                    "myhost",
+		HTTPSPort:                   12443,
+		ID:                          44,
+		IP:                          "192.168.2.4",
+		ParentCacheGroupID:          InvalidID,
+		ProfileID:                   46,
+		ProfileName:                 "MyProfile",
+		Port:                        12080,
+		SecondaryParentCacheGroupID: InvalidID,
+		Type:                        "EDGE",
+	}
+
+	remapDSData := []RemapConfigDSData{
+		RemapConfigDSData{
+			ID:         48,
+			Type:       "HTTP",
+			OriginFQDN: util.StrPtr("origin.example.test"),
+			Name:       "mydsname",
+			DSCP:       0,
+			Pattern:    util.StrPtr("myregexpattern"),
+			RegexType:  util.StrPtr(string(tc.DSMatchTypeHostRegex)),
+			Domain:     util.StrPtr("mydomain"),
+			Protocol:   util.IntPtr(0),
+			Active:     true,
+			Strategy:   util.StrPtr("mydsname"),
+		},
+	}
+
+	txt := MakeRemapDotConfig(serverName, toToolName, toURL, atsMajorVersion, map[string]string{}, map[int]map[string]string{}, map[string]string{}, serverInfo, remapDSData)
+
+	txt = strings.TrimSpace(txt)
+
+	testComment(t, txt, string(serverName), toToolName, toURL)
+
+	txtLines := strings.Split(txt, "\n")
+
+	if len(txtLines) != 2 {
+		t.Fatalf("expected one line for each remap plus a comment, actual: '%v' count %v", txt, len(txtLines))
+	}
+
+	if !strings.Contains(txtLines[1], " @strategy=mydsname") {
+		t.Errorf("expected to contain strategy, actual '%v'", txt)
+	}
+}
+
 func TestMakeRemapDotConfigMidLiveLocalExcluded(t *testing.T) {
 	serverName := tc.CacheName("server0")
 	toToolName := "to0"
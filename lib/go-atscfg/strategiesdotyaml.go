@@ -0,0 +1,170 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const StrategiesYAMLFileName = "strategies.yaml"
+const ContentTypeStrategiesDotYAML = ContentTypeLoggingDotYAML
+const LineCommentStrategiesDotYAML = LineCommentHash
+
+// StrategiesDotYAMLRetryResponseCodes are the origin response codes on which
+// a request is retried on the next member of an Origin Group.
+var StrategiesDotYAMLRetryResponseCodes = []int{404, 502, 503}
+
+// StrategiesDotYAMLMinATSMajorVersion is the first major version of ATS
+// which supports strategies.yaml, and remap rules with @strategy=.
+const StrategiesDotYAMLMinATSMajorVersion = 9
+
+// UsesOriginGroup returns whether the given cache, in the given cachegroup,
+// requests the origin of a Delivery Service with the given Topology, and so
+// uses the Delivery Service's Origin Group, if it has one. The topology must
+// be nil if the Delivery Service has no Topology.
+//
+// Without a Topology, top-level caches request the origin. With one, the
+// caches in the last tier of the Topology do, and caches not in the Topology
+// don't serve the Delivery Service at all.
+func UsesOriginGroup(server *ServerInfo, cacheGroup string, topology *tc.Topology) bool {
+	if topology == nil {
+		return server.IsTopLevelCache()
+	}
+	_, lastTier := TopologyPlacement(cacheGroup, *topology)
+	return lastTier
+}
+
+// strategiesAnchorRegex matches the characters which can't be used in YAML anchors.
+var strategiesAnchorRegex = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// MakeStrategiesDotYAML returns the ATS strategies.yaml of a cache, with a
+// strategy for each Delivery Service with an Origin Group, named by the
+// Delivery Service's XMLID. The remap rules of caches which request the
+// origin use the strategy with @strategy=, see UsesOriginGroup.
+//
+// Failover groups use the first live member, primaries before secondaries;
+// weighted groups consistent-hash the request path across members by weight.
+func MakeStrategiesDotYAML(
+	serverName tc.CacheName,
+	toToolName string, // tm.toolname global parameter (TODO: cache itself?)
+	toURL string, // tm.url global parameter (TODO: cache itself?)
+	originGroups []tc.OriginGroup,
+) string {
+	groups := make([]tc.OriginGroup, 0, len(originGroups))
+	for _, group := range originGroups {
+		if group.DeliveryService == nil || len(group.Members) == 0 {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return *groups[i].DeliveryService < *groups[j].DeliveryService })
+
+	text := GenericHeaderComment(string(serverName), toToolName, toURL)
+	if len(groups) == 0 {
+		return text
+	}
+
+	text += "hosts:\n"
+	for _, group := range groups {
+		for i, member := range group.Members {
+			host, port := member.HostPort()
+			scheme := string(tc.ProtocolHTTP)
+			if member.Protocol != nil && *member.Protocol != "" {
+				scheme = *member.Protocol
+			}
+			text += "  - &" + strategiesHostAnchor(group, i) + "\n"
+			text += "    host: " + host + "\n"
+			text += "    protocol:\n"
+			text += "      - scheme: " + scheme + "\n"
+			text += "        port: " + strconv.Itoa(port) + "\n"
+			if healthCheckURL := group.HealthCheckURL(member); healthCheckURL != "" {
+				text += "        health_check_url: " + healthCheckURL + "\n"
+			}
+		}
+	}
+
+	text += "groups:\n"
+	for _, group := range groups {
+		for _, role := range []tc.OriginGroupMemberRole{tc.OriginGroupMemberRolePrimary, tc.OriginGroupMemberRoleSecondary} {
+			if len(group.MembersByRole(role)) == 0 {
+				continue
+			}
+			text += "  - &" + strategiesGroupAnchor(group, role) + "\n"
+			for i, member := range group.Members {
+				if member.Role == nil || *member.Role != role {
+					continue
+				}
+				weight := 1
+				if member.Weight != nil {
+					weight = *member.Weight
+				}
+				text += "    - <<: *" + strategiesHostAnchor(group, i) + "\n"
+				text += "      weight: " + strconv.Itoa(weight) + "\n"
+			}
+		}
+	}
+
+	retryCodes := ""
+	for i, code := range StrategiesDotYAMLRetryResponseCodes {
+		if i > 0 {
+			retryCodes += ", "
+		}
+		retryCodes += strconv.Itoa(code)
+	}
+
+	text += "strategies:\n"
+	for _, group := range groups {
+		policy := "first_live"
+		if group.Policy != nil && *group.Policy == tc.OriginGroupPolicyWeighted {
+			policy = tc.AlgorithmConsistentHash
+		}
+		text += "  - strategy: '" + *group.DeliveryService + "'\n"
+		text += "    policy: " + policy + "\n"
+		text += "    hash_key: path\n"
+		text += "    go_direct: false\n"
+		text += "    parent_is_proxy: false\n"
+		text += "    groups:\n"
+		for _, role := range []tc.OriginGroupMemberRole{tc.OriginGroupMemberRolePrimary, tc.OriginGroupMemberRoleSecondary} {
+			if len(group.MembersByRole(role)) > 0 {
+				text += "      - *" + strategiesGroupAnchor(group, role) + "\n"
+			}
+		}
+		text += "    failover:\n"
+		text += "      ring_mode: exhaust_ring\n"
+		text += "      response_codes: [" + retryCodes + "]\n"
+		text += "      health_check:\n"
+		text += "        - passive\n"
+	}
+	return text
+}
+
+// strategiesHostAnchor returns the YAML anchor of the i'th member of the Origin Group.
+func strategiesHostAnchor(group tc.OriginGroup, i int) string {
+	return strategiesAnchorRegex.ReplaceAllString(*group.DeliveryService, "_") + "_host_" + strconv.Itoa(i)
+}
+
+// strategiesGroupAnchor returns the YAML anchor of the members of the Origin Group with the given role.
+func strategiesGroupAnchor(group tc.OriginGroup, role tc.OriginGroupMemberRole) string {
+	return strategiesAnchorRegex.ReplaceAllString(*group.DeliveryService, "_") + "_" + string(role)
+}
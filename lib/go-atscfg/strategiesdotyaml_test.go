@@ -0,0 +1,128 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestMakeStrategiesDotYAML(t *testing.T) {
+	serverName := "myserver"
+	toolName := "myToolName"
+	toURL := "https://myto.example.net"
+
+	primary := tc.OriginGroupMemberRolePrimary
+	secondary := tc.OriginGroupMemberRoleSecondary
+	failover := tc.OriginGroupPolicyFailover
+	weighted := tc.OriginGroupPolicyWeighted
+	groups := []tc.OriginGroup{
+		{
+			DeliveryService: util.StrPtr("ds1"),
+			Policy:          &weighted,
+			Members: []tc.OriginGroupMember{
+				{FQDN: util.StrPtr("o3.example.net"), Protocol: util.StrPtr("http"), Role: &primary, Weight: util.IntPtr(3)},
+			},
+		},
+		{
+			DeliveryService:           util.StrPtr("ds-0"),
+			Policy:                    &failover,
+			HealthCheckPath:           util.StrPtr("/health"),
+			HealthCheckExpectedStatus: util.IntPtr(200),
+			Members: []tc.OriginGroupMember{
+				{FQDN: util.StrPtr("o1.example.net"), Protocol: util.StrPtr("https"), Role: &primary, Weight: util.IntPtr(1)},
+				{FQDN: util.StrPtr("o2.example.net"), Protocol: util.StrPtr("http"), Port: util.IntPtr(8080), Role: &secondary, Weight: util.IntPtr(1)},
+			},
+		},
+		{
+			DeliveryService: util.StrPtr("ds-empty"),
+			Policy:          &failover,
+		},
+	}
+
+	txt := MakeStrategiesDotYAML(tc.CacheName(serverName), toolName, toURL, groups)
+
+	testComment(t, txt, serverName, toolName, toURL)
+
+	if strings.Contains(txt, "ds-empty") {
+		t.Errorf("expected config to omit origin group without members, actual: '%v'", txt)
+	}
+	if !strings.Contains(txt, "health_check_url: https://o1.example.net:443/health") {
+		t.Errorf("expected config to contain health check URL of o1, actual: '%v'", txt)
+	}
+
+	cfg := struct {
+		Strategies []struct {
+			Strategy string                     `yaml:"strategy"`
+			Policy   string                     `yaml:"policy"`
+			Groups   [][]map[string]interface{} `yaml:"groups"`
+		} `yaml:"strategies"`
+	}{}
+	if err := yaml.Unmarshal([]byte(txt), &cfg); err != nil {
+		t.Fatalf("expected config to be valid YAML, actual error: %v, config: '%v'", err, txt)
+	}
+	if len(cfg.Strategies) != 2 {
+		t.Fatalf("expected 2 strategies, actual: %+v", cfg.Strategies)
+	}
+	if s := cfg.Strategies[0]; s.Strategy != "ds-0" || s.Policy != "first_live" || len(s.Groups) != 2 {
+		t.Errorf("expected strategy 'ds-0' with policy first_live and 2 groups, actual: %+v", s)
+	} else if host := s.Groups[1][0]["host"]; host != "o2.example.net" {
+		t.Errorf("expected secondary group host 'o2.example.net', actual: '%v'", host)
+	}
+	if s := cfg.Strategies[1]; s.Strategy != "ds1" || s.Policy != "consistent_hash" || len(s.Groups) != 1 {
+		t.Errorf("expected strategy 'ds1' with policy consistent_hash and 1 group, actual: %+v", s)
+	} else if weight := s.Groups[0][0]["weight"]; weight != 3 {
+		t.Errorf("expected weight 3, actual: %v", weight)
+	}
+}
+
+func TestUsesOriginGroup(t *testing.T) {
+	topLevel := &ServerInfo{ParentCacheGroupID: InvalidID, SecondaryParentCacheGroupID: InvalidID}
+	edge := &ServerInfo{ParentCacheGroupID: 42, ParentCacheGroupType: tc.CacheGroupMidTypeName, SecondaryParentCacheGroupID: InvalidID}
+
+	if !UsesOriginGroup(topLevel, "mid", nil) {
+		t.Errorf("expected top-level cache of DS without topology to use origin group, actual: false")
+	}
+	if UsesOriginGroup(edge, "edge", nil) {
+		t.Errorf("expected non-top-level cache of DS without topology not to use origin group, actual: true")
+	}
+
+	topology := &tc.Topology{
+		Name: "mytop",
+		Nodes: []tc.TopologyNode{
+			{Cachegroup: "edge", Parents: []int{1}},
+			{Cachegroup: "mid"},
+		},
+	}
+	if !UsesOriginGroup(edge, "mid", topology) {
+		t.Errorf("expected cache in last topology tier to use origin group, actual: false")
+	}
+	if UsesOriginGroup(topLevel, "edge", topology) {
+		t.Errorf("expected cache with topology parents not to use origin group, even if top-level, actual: true")
+	}
+	if UsesOriginGroup(topLevel, "other", topology) {
+		t.Errorf("expected cache not in topology not to use origin group, actual: true")
+	}
+}
@@ -0,0 +1,298 @@
+// Package consistenthash implements the consistent hashing Traffic Router uses to choose the caches that serve a
+// client request, so that other Traffic Control components can predict Traffic Router's choices without asking a
+// running Traffic Router.
+//
+// The hashing must match Traffic Router's implementation exactly - any change here must be made there as well, and
+// vice versa.
+package consistenthash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/md5"
+	"errors"
+	"math"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultHashCount is the number of hashes Traffic Router generates for a cache whose hashCount is missing or not
+// positive.
+const DefaultHashCount = 1000
+
+// Hash returns the hash of s, which is its MD5 sum interpreted as an unsigned big-endian integer and converted to
+// the nearest float64.
+func Hash(s string) float64 {
+	sum := md5.Sum([]byte(s))
+	f, _ := new(big.Float).SetInt(new(big.Int).SetBytes(sum[:])).Float64()
+	return f
+}
+
+// Hashable is something that may be chosen by consistent hashing, e.g. a cache or a steering target.
+type Hashable struct {
+	// ID identifies the Hashable to the caller; it plays no part in hashing.
+	ID string
+	// Order places a Hashable without hashes relative to the others; see Select.
+	Order  int
+	hashes []float64
+}
+
+// NewHashable returns a Hashable identified by id, with hashCount hashes generated from hashID the way Traffic
+// Router generates the hashes of a cache. If hashCount is not positive, DefaultHashCount is used.
+func NewHashable(id string, hashID string, hashCount int) Hashable {
+	if hashCount <= 0 {
+		hashCount = DefaultHashCount
+	}
+	return NewOrderedHashable(id, hashID, hashCount, 0)
+}
+
+// NewOrderedHashable returns a Hashable identified by id, with hashCount hashes generated from hashID and the given
+// order, the way Traffic Router generates the hashes of a steering target from its Delivery Service and weight.
+// Unlike NewHashable, no hashes are generated if hashCount is not positive.
+func NewOrderedHashable(id string, hashID string, hashCount int, order int) Hashable {
+	if hashCount < 0 {
+		hashCount = 0
+	}
+	unique := make(map[float64]struct{}, hashCount)
+	hashes := make([]float64, 0, hashCount)
+	for i := 0; i < hashCount; i++ {
+		h := Hash(hashID + "--" + strconv.Itoa(i))
+		if _, ok := unique[h]; ok {
+			continue
+		}
+		unique[h] = struct{}{}
+		hashes = append(hashes, h)
+	}
+	sort.Float64s(hashes)
+	return Hashable{ID: id, Order: order, hashes: hashes}
+}
+
+// closestHash returns the hash of h closest to hash. If two are equally close, the smaller is returned.
+func (h Hashable) closestHash(hash float64) float64 {
+	i := sort.SearchFloat64s(h.hashes, hash)
+	if i < len(h.hashes) && h.hashes[i] == hash {
+		return hash
+	}
+	if i == len(h.hashes) {
+		return h.hashes[len(h.hashes)-1]
+	}
+	if i == 0 {
+		return h.hashes[0]
+	}
+	if math.Abs(h.hashes[i]-hash) < math.Abs(h.hashes[i-1]-hash) {
+		return h.hashes[i]
+	}
+	return h.hashes[i-1]
+}
+
+// Select returns the given Hashables ordered by their preference for s, most preferred first. Traffic Router
+// chooses the first Hashables of this order which are available, up to the dispersion limit of the Delivery Service.
+//
+// Hashables without hashes aren't hashed, but placed by their Order: those with a negative Order before all others,
+// and the rest after all others, in ascending Order.
+func Select(hashables []Hashable, s string) []Hashable {
+	hash := Hash(s)
+	byDelta := make(map[float64]Hashable, len(hashables))
+	deltas := make([]float64, 0, len(hashables))
+	unhashed := []Hashable{}
+	for _, h := range hashables {
+		if len(h.hashes) == 0 {
+			unhashed = append(unhashed, h)
+			continue
+		}
+		delta := math.Abs(hash - h.closestHash(hash))
+		// Traffic Router breaks ties by moving the later Hashable to the next representable value
+		for _, ok := byDelta[delta]; ok; _, ok = byDelta[delta] {
+			delta = math.Float64frombits(math.Float64bits(delta) + 1)
+		}
+		byDelta[delta] = h
+		deltas = append(deltas, delta)
+	}
+	sort.Float64s(deltas)
+	hashed := make([]Hashable, 0, len(deltas))
+	for _, delta := range deltas {
+		hashed = append(hashed, byDelta[delta])
+	}
+	if len(unhashed) == 0 {
+		return hashed
+	}
+
+	// Traffic Router sorts Hashables without hashes with negative Orders in descending Order, and then prepends
+	// each of them in turn, which reverses those of equal Order.
+	sort.SliceStable(unhashed, func(i, j int) bool {
+		if unhashed[i].Order < 0 && unhashed[j].Order < 0 {
+			return unhashed[i].Order > unhashed[j].Order
+		}
+		return unhashed[i].Order < unhashed[j].Order
+	})
+	head := []Hashable{}
+	tail := []Hashable{}
+	for _, h := range unhashed {
+		if h.Order >= 0 {
+			tail = append(tail, h)
+		} else {
+			head = append([]Hashable{h}, head...)
+		}
+	}
+	selected := make([]Hashable, 0, len(hashables))
+	selected = append(selected, head...)
+	selected = append(selected, hashed...)
+	return append(selected, tail...)
+}
+
+// CompileRegex compiles a regular expression written for Traffic Router, such as a consistent hash regex.
+//
+// Traffic Router uses Java regular expressions, and Go uses RE2 syntax. Most Java constructs that RE2 lacks, such as
+// lookarounds, possessive quantifiers and backreferences, fail to compile. Those which compile but mean something else
+// in RE2 - class intersections ("&&"), POSIX classes ("[:alpha:]"), octal escapes without a leading zero ("\123"),
+// "\v" and the "U" flag - are rejected here, so a regex is never evaluated differently than Traffic Router would evaluate it.
+func CompileRegex(pattern string) (*regexp.Regexp, error) {
+	if err := checkJavaCompatible(pattern); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(pattern)
+}
+
+// checkJavaCompatible returns an error if the pattern contains a construct that compiles in RE2 but doesn't mean the
+// same thing as it does in a Java regular expression.
+func checkJavaCompatible(pattern string) error {
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			switch next := pattern[i]; {
+			case next == 'Q':
+				end := strings.Index(pattern[i:], `\E`)
+				if end < 0 {
+					return nil
+				}
+				i += end + 1
+			case next >= '1' && next <= '9':
+				return errors.New("unsupported escape '\\" + string(next) + "': backreferences and octal escapes without a leading zero differ from Traffic Router")
+			case next == 'v':
+				return errors.New("unsupported escape '\\v': vertical whitespace in Traffic Router is a vertical tab in Go")
+			}
+		case c == '(' && !inClass && strings.HasPrefix(pattern[i+1:], "?"):
+			flags := pattern[i+2:]
+			if end := strings.IndexAny(flags, ":)"); end >= 0 && strings.Contains(flags[:end], "U") {
+				return errors.New("unsupported flag 'U': it enables Unicode character classes in Traffic Router, but ungreedy matching in Go")
+			}
+		case c == '[' && !inClass:
+			inClass = true
+			// a ']' first in a class is literal
+			if strings.HasPrefix(pattern[i+1:], "^]") {
+				i += 2
+			} else if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case c == '[' && inClass && strings.HasPrefix(pattern[i+1:], ":"):
+			return errors.New("unsupported POSIX character class: Traffic Router doesn't support '[:name:]' classes")
+		case c == '&' && inClass && strings.HasPrefix(pattern[i+1:], "&"):
+			return errors.New("unsupported character class intersection '&&'")
+		case c == ']' && inClass:
+			inClass = false
+		}
+	}
+	return nil
+}
+
+// PatternBasedHashString returns the part of requestPath that Traffic Router hashes for the given consistent hash
+// regular expression: the concatenation of the groups captured by the first match of regex in requestPath.
+//
+// If regex is nil, doesn't match, or has no groups, requestPath is returned unaltered. A group that doesn't
+// participate in the match contributes "null", as it does in Traffic Router.
+func PatternBasedHashString(regex *regexp.Regexp, requestPath string) string {
+	if regex == nil {
+		return requestPath
+	}
+	match := regex.FindStringSubmatchIndex(requestPath)
+	if match == nil || len(match) <= 2 {
+		return requestPath
+	}
+	s := strings.Builder{}
+	for i := 2; i < len(match); i += 2 {
+		if match[i] < 0 {
+			s.WriteString("null")
+			continue
+		}
+		s.WriteString(requestPath[match[i]:match[i+1]])
+	}
+	return s.String()
+}
+
+// QueryParamsHashString returns the part of a query string that Traffic Router hashes: the URL-decoded query
+// parameters whose names are in params, sorted and concatenated. If the query string can't be decoded, the empty
+// string is returned.
+func QueryParamsHashString(query string, params []string) string {
+	if query == "" || len(params) == 0 {
+		return ""
+	}
+	significant := make(map[string]struct{}, len(params))
+	for _, p := range params {
+		significant[p] = struct{}{}
+	}
+
+	qparams := map[string]struct{}{}
+	for _, qparam := range strings.Split(query, "&") {
+		if qparam == "" {
+			continue
+		}
+		parts := strings.Split(qparam, "=")
+		for len(parts) > 0 && parts[len(parts)-1] == "" {
+			parts = parts[:len(parts)-1]
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		for i, part := range parts {
+			decoded, err := url.QueryUnescape(part)
+			if err != nil {
+				return ""
+			}
+			parts[i] = decoded
+		}
+		if _, ok := significant[parts[0]]; ok {
+			qparams[strings.Join(parts, "=")] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(qparams))
+	for q := range qparams {
+		sorted = append(sorted, q)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, "")
+}
+
+// HashString returns the string Traffic Router hashes to choose the caches for a request of a Delivery Service with
+// the given consistent hash regular expression and query parameters. The request path must not contain the query
+// string.
+func HashString(regex *regexp.Regexp, queryParams []string, requestPath string, query string) string {
+	s := ""
+	if requestPath != "" {
+		s = PatternBasedHashString(regex, requestPath)
+	}
+	return s + QueryParamsHashString(query, queryParams)
+}
@@ -0,0 +1,134 @@
+package consistenthash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	// expected values are the MD5 sums as unsigned integers, rounded to the nearest double, as Traffic Router's
+	// MD5HashFunction computes them
+	expected := map[string]float64{
+		"":           2.8194976848941264e+38,
+		"edge1--0":   1.5769443827125184e+37,
+		"/path/m3u8": 8.159396361594915e+37,
+	}
+	for s, exp := range expected {
+		if actual := Hash(s); actual != exp {
+			t.Errorf("Hash(%q) expected %v actual %v", s, exp, actual)
+		}
+	}
+}
+
+func TestCompileRegex(t *testing.T) {
+	valid := []string{`/.*?(/.*?/).*?(m3u8)`, `[]a&]`, `[^]:]`, `\Q[[:x:]]\E(a)`, `(?i:a)`, `\0101`, `(?<n>a)`}
+	for _, pattern := range valid {
+		if _, err := CompileRegex(pattern); err != nil {
+			t.Errorf("expected '%s' to compile, actual error: %v", pattern, err)
+		}
+	}
+	invalid := []string{`(a)\1`, `\123`, `\v`, `[[:alpha:]]`, `[a-z&&[^b]]`, `(?U)a*`, `(?iU:a)`, `(?=a)`, `a*+`, `\Z`}
+	for _, pattern := range invalid {
+		if _, err := CompileRegex(pattern); err == nil {
+			t.Errorf("expected '%s' to be rejected, actual: no error", pattern)
+		}
+	}
+}
+
+func TestPatternBasedHashString(t *testing.T) {
+	regex := regexp.MustCompile(`/.*?(/.*?/).*?(m3u8)`)
+	if actual := PatternBasedHashString(regex, "/test/path/asset.m3u8"); actual != "/path/m3u8" {
+		t.Errorf("expected '/path/m3u8', actual '%s'", actual)
+	}
+	if actual := PatternBasedHashString(regex, "/test/path/asset.mpd"); actual != "/test/path/asset.mpd" {
+		t.Errorf("expected a path that doesn't match to be unaltered, actual '%s'", actual)
+	}
+	if actual := PatternBasedHashString(nil, "/test/path/asset.m3u8"); actual != "/test/path/asset.m3u8" {
+		t.Errorf("expected a nil regex to leave the path unaltered, actual '%s'", actual)
+	}
+	if actual := PatternBasedHashString(regexp.MustCompile(`/test/(a)?(path)`), "/test/path"); actual != "nullpath" {
+		t.Errorf("expected 'nullpath', actual '%s'", actual)
+	}
+}
+
+func TestQueryParamsHashString(t *testing.T) {
+	params := []string{"format", "bitrate"}
+	if actual := QueryParamsHashString("format=ts&foo=bar&bitrate=1%2C2&format=ts", params); actual != "bitrate=1,2format=ts" {
+		t.Errorf("expected 'bitrate=1,2format=ts', actual '%s'", actual)
+	}
+	if actual := QueryParamsHashString("format=ts", nil); actual != "" {
+		t.Errorf("expected no significant parameters to produce an empty string, actual '%s'", actual)
+	}
+	if actual := QueryParamsHashString("format=%zz", params); actual != "" {
+		t.Errorf("expected a malformed query string to produce an empty string, actual '%s'", actual)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	hashables := []Hashable{
+		NewHashable("edge1", "edge1", 100),
+		NewHashable("edge2", "edge2", 100),
+		NewHashable("edge3", "edge3", 0),
+	}
+	if len(hashables[2].hashes) != DefaultHashCount {
+		t.Errorf("expected a hash count of 0 to generate %d hashes, actual %d", DefaultHashCount, len(hashables[2].hashes))
+	}
+
+	selected := Select(hashables, "/path/m3u8")
+	if len(selected) != len(hashables) {
+		t.Fatalf("expected %d selected hashables, actual %d", len(hashables), len(selected))
+	}
+	seen := map[string]struct{}{}
+	for _, h := range selected {
+		seen[h.ID] = struct{}{}
+	}
+	if len(seen) != len(hashables) {
+		t.Errorf("expected every hashable to be selected exactly once, actual %+v", selected)
+	}
+
+	reversed := []Hashable{hashables[2], hashables[1], hashables[0]}
+	for i, h := range Select(reversed, "/path/m3u8") {
+		if h.ID != selected[i].ID {
+			t.Errorf("expected selection not to depend on input order, position %d expected %s actual %s", i, selected[i].ID, h.ID)
+		}
+	}
+}
+
+func TestSelectUnhashed(t *testing.T) {
+	hashables := []Hashable{
+		NewOrderedHashable("last", "last", 0, 2),
+		NewOrderedHashable("hashed", "hashed", 10, 0),
+		NewOrderedHashable("zero", "zero", 0, 0),
+		NewOrderedHashable("second", "second", 0, -1),
+		NewOrderedHashable("first", "first", 0, -2),
+	}
+	expected := []string{"first", "second", "hashed", "zero", "last"}
+	selected := Select(hashables, "/path/m3u8")
+	if len(selected) != len(expected) {
+		t.Fatalf("expected %d selected hashables, actual %d", len(expected), len(selected))
+	}
+	for i, h := range selected {
+		if h.ID != expected[i] {
+			t.Errorf("position %d expected %s actual %s", i, expected[i], h.ID)
+		}
+	}
+}
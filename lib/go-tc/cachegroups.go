@@ -80,6 +80,10 @@ type CacheGroupNullable struct {
 	TypeID                      *int                  `json:"typeId" db:"type_id"`     // aliased to type_id to disambiguate struct scans due join on 'type' table
 	LastUpdated                 *TimeNoMod            `json:"lastUpdated" db:"last_updated"`
 	Fallbacks                   *[]string             `json:"fallbacks" db:"fallbacks"`
+	// Tenant and TenantID identify the tenant which owns the cachegroup, if any. They're only available in API 3.0
+	// and later, and a cachegroup without a tenant may be managed by users of any tenant.
+	Tenant   *string `json:"tenant,omitempty" db:"tenant"`
+	TenantID *int    `json:"tenantId,omitempty" db:"tenant_id"`
 }
 
 // CachegroupTrimmedName is useful when the only info about a cache group you
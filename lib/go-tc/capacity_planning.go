@@ -0,0 +1,110 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import "time"
+
+// CapacityPlanResponse is the type of a response from Traffic Ops to a request
+// for a capacity plan.
+type CapacityPlanResponse struct {
+	Response CapacityPlan `json:"response"`
+	Alerts
+}
+
+// CapacityPlan is a report of the historical bandwidth utilization of the
+// cachegroups, delivery services and topology tiers of a CDN over a period of
+// time, relative to the capacity of the caches serving them.
+//
+// All bandwidths are in kilobits per second.
+type CapacityPlan struct {
+	CDN              string                        `json:"cdn"`
+	StartDate        time.Time                     `json:"startDate"`
+	EndDate          time.Time                     `json:"endDate"`
+	Cachegroups      []CapacityPlanCachegroup      `json:"cachegroups"`
+	DeliveryServices []CapacityPlanDeliveryService `json:"deliveryServices"`
+	TopologyTiers    []CapacityPlanTopologyTier    `json:"topologyTiers"`
+}
+
+// CapacityPlanUtilization is the utilization of some capacity over the period
+// of a CapacityPlan.
+type CapacityPlanUtilization struct {
+	// CapacityKbps is the sum of the maxBandwidth of the monitored interfaces
+	// of the ONLINE and REPORTED caches providing the capacity.
+	CapacityKbps float64 `json:"capacityKbps"`
+	PeakKbps     float64 `json:"peakKbps"`
+	P95Kbps      float64 `json:"p95Kbps"`
+	// PeakUtilizationPercent and P95UtilizationPercent are nil if the
+	// capacity is unknown, i.e. zero.
+	PeakUtilizationPercent *float64 `json:"peakUtilizationPercent"`
+	P95UtilizationPercent  *float64 `json:"p95UtilizationPercent"`
+	// HeadroomKbps is the capacity remaining above the peak. It is negative if
+	// the peak exceeded the capacity.
+	HeadroomKbps float64 `json:"headroomKbps"`
+	// GrowthKbpsPerDay is the slope of the linear trend of the daily peaks.
+	GrowthKbpsPerDay float64 `json:"growthKbpsPerDay"`
+	// DaysUntilExhausted is the number of days until the peak reaches the
+	// capacity if the trend continues, or nil if the trend is not growth or
+	// the capacity is unknown.
+	DaysUntilExhausted *float64 `json:"daysUntilExhausted"`
+}
+
+// CapacityPlanCachegroup is the capacity plan of a single cachegroup.
+type CapacityPlanCachegroup struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Servers is the number of ONLINE and REPORTED caches in the cachegroup,
+	// and ServersWithoutCapacity the number of those with no monitored
+	// interface with a maxBandwidth, which add nothing to its capacity.
+	Servers                int `json:"servers"`
+	ServersWithoutCapacity int `json:"serversWithoutCapacity"`
+	CapacityPlanUtilization
+	// SiblingFailureRisk is whether the cachegroup would exceed its capacity
+	// at its 95th percentile utilization if any one of the SiblingFailures
+	// happened.
+	SiblingFailureRisk bool                         `json:"siblingFailureRisk"`
+	SiblingFailures    []CapacityPlanSiblingFailure `json:"siblingFailures"`
+}
+
+// CapacityPlanSiblingFailure is the projected utilization of a cachegroup if
+// another cachegroup failed and some or all of its traffic moved to it.
+type CapacityPlanSiblingFailure struct {
+	// Cachegroup is the name of the cachegroup which failed.
+	Cachegroup                  string   `json:"cachegroup"`
+	ProjectedKbps               float64  `json:"projectedKbps"`
+	ProjectedUtilizationPercent *float64 `json:"projectedUtilizationPercent"`
+	ExceedsCapacity             bool     `json:"exceedsCapacity"`
+}
+
+// CapacityPlanDeliveryService is the utilization of a single delivery
+// service over the period of a CapacityPlan.
+type CapacityPlanDeliveryService struct {
+	XMLID    string  `json:"xmlId"`
+	PeakKbps float64 `json:"peakKbps"`
+	P95Kbps  float64 `json:"p95Kbps"`
+}
+
+// CapacityPlanTopologyTier is the capacity plan of all of the cachegroups of
+// a single type in a topology, taken together.
+type CapacityPlanTopologyTier struct {
+	Topology    string   `json:"topology"`
+	Tier        string   `json:"tier"`
+	Cachegroups []string `json:"cachegroups"`
+	CapacityPlanUtilization
+}
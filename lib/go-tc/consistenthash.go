@@ -0,0 +1,59 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// DeliveryServiceConsistentHashResponse is the type of a response from
+// Traffic Ops to a request for the caches a Delivery Service request path
+// consistently hashes to.
+type DeliveryServiceConsistentHashResponse struct {
+	Response DeliveryServiceConsistentHash `json:"response"`
+	Alerts
+}
+
+// DeliveryServiceConsistentHash is the result of consistently hashing a
+// request path of a Delivery Service the way Traffic Router does, using the
+// current CDN snapshot.
+type DeliveryServiceConsistentHash struct {
+	DeliveryService string `json:"deliveryService"`
+	RequestPath     string `json:"requestPath"`
+	// ResultingPathToConsistentHash is the string Traffic Router hashes: the
+	// part of the request path selected by the Delivery Service's consistent
+	// hash regex, followed by its consistent hash query parameters.
+	ResultingPathToConsistentHash string                                    `json:"resultingPathToConsistentHash"`
+	Dispersion                    CRConfigDispersion                        `json:"dispersion"`
+	Cachegroups                   []DeliveryServiceConsistentHashCachegroup `json:"cachegroups"`
+}
+
+// DeliveryServiceConsistentHashCachegroup is the order in which Traffic Router
+// prefers the caches of a single cachegroup for a hashed request path.
+type DeliveryServiceConsistentHashCachegroup struct {
+	Name string `json:"name"`
+	// Caches are the available caches of the cachegroup serving the Delivery
+	// Service, most preferred first. Traffic Router chooses among the first
+	// Dispersion.Limit of them that are healthy.
+	Caches []DeliveryServiceConsistentHashCache `json:"caches"`
+}
+
+// DeliveryServiceConsistentHashCache is a cache a request path may hash to.
+type DeliveryServiceConsistentHashCache struct {
+	HostName string `json:"hostName"`
+	FQDN     string `json:"fqdn"`
+	Status   string `json:"status"`
+}
@@ -0,0 +1,84 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// CoverageZonesResponse is a list of CoverageZones as a response.
+type CoverageZonesResponse struct {
+	Response []CoverageZoneNullable `json:"response"`
+	Alerts
+}
+
+// CoverageZoneResponse is a single CoverageZone as a response.
+type CoverageZoneResponse struct {
+	Response CoverageZoneNullable `json:"response"`
+	Alerts
+}
+
+// CoverageZoneNullable is a mapping of a network to the cachegroup to which
+// Traffic Router should route clients in that network, in a single CDN.
+//
+// Deep coverage zones are rendered into the Deep Coverage Zone File, and all
+// others into the Coverage Zone File. Within a CDN, no two networks of the
+// same kind of coverage zone may overlap. CDNName and CachegroupName are only
+// populated on read.
+type CoverageZoneNullable struct {
+	ID             *int       `json:"id" db:"id"`
+	CDNID          *int       `json:"cdnId" db:"cdn"`
+	CDNName        *string    `json:"cdnName" db:"cdn_name"`
+	CachegroupID   *int       `json:"cachegroupId" db:"cachegroup"`
+	CachegroupName *string    `json:"cachegroupName" db:"cachegroup_name"`
+	Network        *string    `json:"network" db:"network"`
+	Deep           *bool      `json:"deep" db:"deep"`
+	LastUpdated    *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// CoverageZoneFile is the Coverage Zone File used by Traffic Router, mapping
+// the names of cachegroups to the networks they serve.
+type CoverageZoneFile struct {
+	Revision      string                          `json:"revision"`
+	CoverageZones map[string]CoverageZoneLocation `json:"coverageZones"`
+}
+
+// DeepCoverageZoneFile is the Deep Coverage Zone File used by Traffic Router,
+// mapping the names of cachegroups to the networks they serve and the caches
+// in them to which Traffic Router may route clients of delivery services with
+// deep caching.
+type DeepCoverageZoneFile struct {
+	Revision          string                          `json:"revision"`
+	DeepCoverageZones map[string]CoverageZoneLocation `json:"deepCoverageZones"`
+}
+
+// CoverageZoneLocation is a single location in a CoverageZoneFile or
+// DeepCoverageZoneFile.
+//
+// Caches is only used in a DeepCoverageZoneFile.
+type CoverageZoneLocation struct {
+	Network     []string                 `json:"network"`
+	Network6    []string                 `json:"network6"`
+	Coordinates *CoverageZoneCoordinates `json:"coordinates,omitempty"`
+	Caches      []string                 `json:"caches,omitempty"`
+}
+
+// CoverageZoneCoordinates is the geographic location of a
+// CoverageZoneLocation.
+type CoverageZoneCoordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
@@ -168,6 +168,11 @@ type CRConfigDispersion struct {
 type CRConfigBackupLocations struct {
 	FallbackToClosest bool     `json:"fallbackToClosest,string"`
 	List              []string `json:"list,omitempty"`
+	// Topologies are the cachegroups to fall back to for the Delivery
+	// Services of each Topology, in order, keyed by Topology name. They are
+	// the other edge cachegroups of the Topology which share a parent with
+	// this one, and are tried before List.
+	Topologies map[string][]string `json:"topologies,omitempty"`
 }
 
 type CRConfigLatitudeLongitude struct {
@@ -40,6 +40,10 @@ type IsAvailable struct {
 	IsAvailable   bool `json:"isAvailable"`
 	Ipv4Available bool `json:"ipv4Available"`
 	Ipv6Available bool `json:"ipv6Available"`
+	// Warming is whether the cache recently became available, and is still ramping up to its full share of load.
+	Warming bool `json:"warming,omitempty"`
+	// Weight is the fraction of its full share of load a Warming cache should receive, from 0 to 1. It is meaningless if Warming is false.
+	Weight float64 `json:"weight,omitempty"`
 }
 
 // NewCRStates creates a new CR states object, initializing pointer members.
@@ -0,0 +1,71 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// DeliveryServiceTopologyServersResponse is the type of a response from
+// Traffic Ops to a request for the servers of each tier of a Delivery
+// Service's Topology.
+type DeliveryServiceTopologyServersResponse struct {
+	Response DeliveryServiceTopologyServers `json:"response"`
+	Alerts
+}
+
+// DeliveryServiceTopologyServers is the expansion of a Delivery Service's
+// Topology into the cachegroups of each of its tiers, and the servers of
+// those cachegroups which are eligible to serve the Delivery Service.
+type DeliveryServiceTopologyServers struct {
+	DeliveryService      string                        `json:"deliveryService"`
+	Topology             string                        `json:"topology"`
+	CDNName              string                        `json:"cdnName"`
+	RequiredCapabilities []string                      `json:"requiredCapabilities"`
+	Tiers                []DeliveryServiceTopologyTier `json:"tiers"`
+}
+
+// DeliveryServiceTopologyTier is all of the cachegroups of a single type in a
+// Topology, e.g. its EDGE_LOC cachegroups.
+type DeliveryServiceTopologyTier struct {
+	Type        string                              `json:"type"`
+	Cachegroups []DeliveryServiceTopologyCachegroup `json:"cachegroups"`
+	// EligibleServers is the number of servers of all of the tier's
+	// cachegroups which are eligible to serve the Delivery Service.
+	EligibleServers int `json:"eligibleServers"`
+}
+
+// DeliveryServiceTopologyCachegroup is a node of a Topology, and the servers
+// of its cachegroup on the Delivery Service's CDN.
+type DeliveryServiceTopologyCachegroup struct {
+	Name    string                          `json:"name"`
+	Parents []string                        `json:"parents"`
+	Servers []DeliveryServiceTopologyServer `json:"servers"`
+}
+
+// DeliveryServiceTopologyServer is a server of a Topology cachegroup.
+//
+// A cache server is eligible to serve the Delivery Service if its status is
+// ONLINE or REPORTED and it has all of the Delivery Service's required
+// capabilities; an origin server need only have one of those statuses.
+type DeliveryServiceTopologyServer struct {
+	ID                  int      `json:"id"`
+	HostName            string   `json:"hostName"`
+	Type                string   `json:"type"`
+	Status              string   `json:"status"`
+	Eligible            bool     `json:"eligible"`
+	MissingCapabilities []string `json:"missingCapabilities"`
+}
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-util"
 
@@ -143,6 +144,81 @@ func (r *ResolverMapping) Validate(tx *sql.Tx) error {
 	return nil
 }
 
+// Normalize rewrites each resolver of the mapping in its canonical form, as
+// given by NormalizeFederationResolver, and removes duplicates. Resolvers that
+// can't be normalized are left untouched, so that Validate can report them.
+func (r *ResolverMapping) Normalize() {
+	r.Resolve4 = normalizeFederationResolvers(r.Resolve4)
+	r.Resolve6 = normalizeFederationResolvers(r.Resolve6)
+}
+
+func normalizeFederationResolvers(resolvers []string) []string {
+	if resolvers == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(resolvers))
+	normalized := make([]string, 0, len(resolvers))
+	for _, res := range resolvers {
+		if n, err := NormalizeFederationResolver(res); err == nil {
+			res = n
+		}
+		if _, ok := seen[res]; ok {
+			continue
+		}
+		seen[res] = struct{}{}
+		normalized = append(normalized, res)
+	}
+	return normalized
+}
+
+// NormalizeFederationResolver returns the canonical form of a Federation
+// Resolver, which may be an IPv4 or IPv6 address or CIDR-notation network.
+// Addresses are returned in their shortest form (IPv4-mapped IPv6 addresses
+// become IPv4 addresses), and networks are returned as the network address
+// followed by the prefix length, e.g. "192.0.2.7/24" becomes "192.0.2.0/24".
+func NormalizeFederationResolver(res string) (string, error) {
+	if ip := net.ParseIP(res); ip != nil {
+		return ip.String(), nil
+	}
+	_, ipNet, err := net.ParseCIDR(res)
+	if err != nil {
+		return "", fmt.Errorf("[ %s ] is not a valid ip address.", res)
+	}
+	return ipNet.String(), nil
+}
+
+// federationResolverNetwork returns the network of a Federation Resolver;
+// addresses are treated as networks of a single address.
+func federationResolverNetwork(res string) (*net.IPNet, bool) {
+	if ip := net.ParseIP(res); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, true
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, true
+	}
+	_, ipNet, err := net.ParseCIDR(res)
+	if err != nil {
+		return nil, false
+	}
+	return ipNet, true
+}
+
+// FederationResolversOverlap returns whether the Federation Resolvers a and b,
+// each of which may be an address or a CIDR-notation network, have any
+// address in common. Resolvers that can't be parsed never overlap.
+func FederationResolversOverlap(a, b string) bool {
+	netA, ok := federationResolverNetwork(a)
+	if !ok {
+		return false
+	}
+	netB, ok := federationResolverNetwork(b)
+	if !ok {
+		return false
+	}
+	// Two networks overlap exactly when one contains the other's network address.
+	return netA.Contains(netB.IP) || netB.Contains(netA.IP)
+}
+
 // FederationResolverMapping is the set of all resolvers - both IPv4 and IPv6 - for a specific
 // Federation.
 type FederationResolverMapping struct {
@@ -241,3 +317,68 @@ func (req *DeliveryServiceFederationResolverMappingRequest) Validate(tx *sql.Tx)
 	}
 	return nil
 }
+
+// FederationResolverMappingsPreview is the response to a request to replace
+// the Federation Resolver mappings of the current user made with the dryRun
+// query parameter, which describes the effect of the replacement without
+// making it.
+type FederationResolverMappingsPreview struct {
+	// Added holds, per Delivery Service and Federation, the resolvers that
+	// would be added.
+	Added []AllDeliveryServiceFederationsMapping `json:"added"`
+	// Removed holds, per Delivery Service and Federation, the resolvers that
+	// would be removed.
+	Removed []AllDeliveryServiceFederationsMapping `json:"removed"`
+	// Result is the resulting Federation data Traffic Router would receive
+	// for the affected Delivery Services, as given by federations/all.
+	Result []AllDeliveryServiceFederationsMapping `json:"result"`
+}
+
+// FederationResolverMappingsPreviewResponse is the type of a response from
+// Traffic Ops to a PUT request to the /federations endpoint made with the
+// dryRun query parameter.
+type FederationResolverMappingsPreviewResponse struct {
+	Response FederationResolverMappingsPreview `json:"response"`
+	Alerts
+}
+
+// FederationResolverHistoryAction is the kind of change to a Federation
+// Resolver mapping recorded in its history.
+type FederationResolverHistoryAction string
+
+const (
+	// FederationResolverHistoryAdded is the action of a resolver being
+	// assigned to a Federation.
+	FederationResolverHistoryAdded = FederationResolverHistoryAction("added")
+	// FederationResolverHistoryRemoved is the action of a resolver being
+	// removed from a Federation.
+	FederationResolverHistoryRemoved = FederationResolverHistoryAction("removed")
+	// FederationResolverHistoryTTL is the action of the TTL of a Federation,
+	// and therefore of each of its resolvers, being changed.
+	FederationResolverHistoryTTL = FederationResolverHistoryAction("ttl")
+)
+
+// FederationResolverHistory is a single change to the mapping of a
+// Federation's CNAME to one of its resolvers.
+type FederationResolverHistory struct {
+	ID           int                             `json:"id" db:"id"`
+	FederationID int                             `json:"federationId" db:"federation"`
+	IPAddress    string                          `json:"ipAddress" db:"ip_address"`
+	Type         string                          `json:"type" db:"type"`
+	Action       FederationResolverHistoryAction `json:"action" db:"action"`
+	CName        string                          `json:"cname" db:"cname"`
+	// TTL is the TTL of the Federation after the change.
+	TTL int `json:"ttl" db:"ttl"`
+	// PreviousTTL is the TTL of the Federation before the change, which is
+	// only recorded for changes of the "ttl" action.
+	PreviousTTL *int      `json:"previousTtl" db:"previous_ttl"`
+	Username    string    `json:"username" db:"username"`
+	Timestamp   time.Time `json:"timestamp" db:"timestamp"`
+}
+
+// FederationResolverHistoryResponse is the type of a response from Traffic
+// Ops to a GET request to the /federations/{{ID}}/history endpoint.
+type FederationResolverHistoryResponse struct {
+	Response []FederationResolverHistory `json:"response"`
+	Alerts
+}
@@ -0,0 +1,67 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import "fmt"
+
+func ExampleNormalizeFederationResolver() {
+	for _, res := range []string{"192.0.2.1", "192.0.2.7/24", "::ffff:192.0.2.1", "2001:DB8:0:0::1", "2001:db8::7/32", "not an address"} {
+		normalized, err := NormalizeFederationResolver(res)
+		fmt.Println(normalized, err)
+	}
+
+	// Output: 192.0.2.1 <nil>
+	// 192.0.2.0/24 <nil>
+	// 192.0.2.1 <nil>
+	// 2001:db8::1 <nil>
+	// 2001:db8::/32 <nil>
+	//  [ not an address ] is not a valid ip address.
+}
+
+func ExampleResolverMapping_Normalize() {
+	r := ResolverMapping{
+		Resolve4: []string{"192.0.2.0/24", "192.0.2.255/24", "invalid"},
+		Resolve6: []string{"2001:db8::0001", "2001:db8::1"},
+	}
+	r.Normalize()
+	fmt.Println(r.Resolve4)
+	fmt.Println(r.Resolve6)
+
+	// Output: [192.0.2.0/24 invalid]
+	// [2001:db8::1]
+}
+
+func ExampleFederationResolversOverlap() {
+	fmt.Println(FederationResolversOverlap("192.0.2.0/24", "192.0.2.128/25"))
+	fmt.Println(FederationResolversOverlap("192.0.2.128/25", "192.0.2.0/24"))
+	fmt.Println(FederationResolversOverlap("192.0.2.0/25", "192.0.2.128/25"))
+	fmt.Println(FederationResolversOverlap("192.0.2.1", "192.0.2.0/31"))
+	fmt.Println(FederationResolversOverlap("::ffff:192.0.2.1", "192.0.2.1"))
+	fmt.Println(FederationResolversOverlap("2001:db8::/32", "2001:db8:1::1"))
+	fmt.Println(FederationResolversOverlap("2001:db8::/32", "192.0.2.1"))
+
+	// Output: true
+	// true
+	// false
+	// true
+	// true
+	// true
+	// false
+}
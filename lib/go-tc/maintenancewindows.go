@@ -0,0 +1,70 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import "time"
+
+// MaintenanceWindowScope is the kind of thing to which a MaintenanceWindow
+// applies.
+type MaintenanceWindowScope string
+
+const (
+	// MaintenanceWindowScopeServer windows apply to a single server.
+	MaintenanceWindowScopeServer = MaintenanceWindowScope("server")
+	// MaintenanceWindowScopeCachegroup windows apply to every server in a
+	// cachegroup.
+	MaintenanceWindowScopeCachegroup = MaintenanceWindowScope("cachegroup")
+	// MaintenanceWindowScopeTopology windows apply to every server in every
+	// cachegroup of a topology.
+	MaintenanceWindowScopeTopology = MaintenanceWindowScope("topology")
+)
+
+// MaintenanceWindowsResponse is a list of MaintenanceWindows as a response.
+type MaintenanceWindowsResponse struct {
+	Response []MaintenanceWindowNullable `json:"response"`
+	Alerts
+}
+
+// MaintenanceWindowResponse is a single MaintenanceWindow as a response.
+type MaintenanceWindowResponse struct {
+	Response MaintenanceWindowNullable `json:"response"`
+	Alerts
+}
+
+// MaintenanceWindowNullable is a scheduled period of time during which the
+// servers in its scope are treated as ADMIN_DOWN by Traffic Monitor and
+// Traffic Router, regardless of their configured status.
+//
+// Exactly one of ServerID, CachegroupID and Topology must be set, according
+// to the Scope. ServerHostName and CachegroupName are only populated on read.
+type MaintenanceWindowNullable struct {
+	ID             *int                    `json:"id" db:"id"`
+	Scope          *MaintenanceWindowScope `json:"scope" db:"scope"`
+	ServerID       *int                    `json:"serverId" db:"server"`
+	ServerHostName *string                 `json:"serverHostName" db:"server_host_name"`
+	CachegroupID   *int                    `json:"cachegroupId" db:"cachegroup"`
+	CachegroupName *string                 `json:"cachegroupName" db:"cachegroup_name"`
+	Topology       *string                 `json:"topology" db:"topology"`
+	StartTime      *time.Time              `json:"startTime" db:"start_time"`
+	EndTime        *time.Time              `json:"endTime" db:"end_time"`
+	Reason         *string                 `json:"reason" db:"reason"`
+	Active         *bool                   `json:"active" db:"active"`
+	LastUpdated    *TimeNoMod              `json:"lastUpdated" db:"last_updated"`
+}
@@ -0,0 +1,125 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// OpenAPIVersion is the version of the OpenAPI Specification to which Traffic Ops' generated API documents conform.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIDocument is an OpenAPI 3 description of a single major version of the Traffic Ops API, as returned by
+// /api/{{version}}/openapi.json.
+//
+// Only the subset of the OpenAPI Specification used by Traffic Ops is modeled.
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Servers    []OpenAPIServer            `json:"servers"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+// OpenAPIInfo is the metadata about the API described by an OpenAPIDocument.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is a base URL at which the paths of an OpenAPIDocument are served.
+type OpenAPIServer struct {
+	URL       string                           `json:"url"`
+	Variables map[string]OpenAPIServerVariable `json:"variables,omitempty"`
+}
+
+// OpenAPIServerVariable is a substitutable part of an OpenAPIServer URL.
+type OpenAPIServerVariable struct {
+	Default string   `json:"default"`
+	Enum    []string `json:"enum,omitempty"`
+}
+
+// OpenAPIPathItem maps the lower-case HTTP methods allowed on a path to the operations they perform.
+type OpenAPIPathItem map[string]OpenAPIOperation
+
+// OpenAPIOperation describes a single API operation, i.e. a Traffic Ops route.
+//
+// The "x-traffic-ops-*" extensions carry Traffic Ops route information that has no OpenAPI equivalent.
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security"`
+	RouteID     int                        `json:"x-traffic-ops-route-id"`
+	PrivLevel   int                        `json:"x-traffic-ops-priv-level"`
+	// Since is the first minor version of the document's major version in which the operation is available.
+	Since string `json:"x-traffic-ops-since"`
+}
+
+// OpenAPIParameter describes a path or query string parameter of an OpenAPIOperation.
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the payload accepted by an OpenAPIOperation.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a payload returned by an OpenAPIOperation.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema of a payload of a particular media type.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema is a (subset of a) JSON Schema, as used by OpenAPI 3.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AllOf                []*OpenAPISchema          `json:"allOf,omitempty"`
+}
+
+// OpenAPIComponents holds the reusable objects referenced by an OpenAPIDocument.
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes a means of authenticating to the API.
+type OpenAPISecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,141 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strconv"
+)
+
+// OriginGroupPolicy is the way caches choose among the primary members of an
+// Origin Group.
+type OriginGroupPolicy string
+
+const (
+	// OriginGroupPolicyFailover sends requests to the first available primary
+	// member, in order, and then to the first available secondary member.
+	OriginGroupPolicyFailover = OriginGroupPolicy("failover")
+	// OriginGroupPolicyWeighted distributes requests among the available
+	// primary members by consistent hashing, in proportion to their weights,
+	// and then among the available secondary members.
+	OriginGroupPolicyWeighted = OriginGroupPolicy("weighted")
+)
+
+// OriginGroupMemberRole is the role of an Origin in an Origin Group.
+type OriginGroupMemberRole string
+
+const (
+	// OriginGroupMemberRolePrimary members serve requests while any of them
+	// is available.
+	OriginGroupMemberRolePrimary = OriginGroupMemberRole("primary")
+	// OriginGroupMemberRoleSecondary members serve requests when no primary
+	// member is available.
+	OriginGroupMemberRoleSecondary = OriginGroupMemberRole("secondary")
+)
+
+// OriginGroupMember is an Origin of a Delivery Service in the Delivery
+// Service's Origin Group. Only OriginID, Role and Weight are given by clients;
+// the other fields are read from the Origin.
+type OriginGroupMember struct {
+	OriginID *int                   `json:"originId"`
+	Origin   *string                `json:"origin"`
+	Protocol *string                `json:"protocol"`
+	FQDN     *string                `json:"fqdn"`
+	Port     *int                   `json:"port"`
+	Role     *OriginGroupMemberRole `json:"role"`
+	Weight   *int                   `json:"weight"`
+}
+
+// HostPort returns the host and port caches connect to for the member. If
+// the Origin has no port, the default port of its protocol is used.
+func (m OriginGroupMember) HostPort() (string, int) {
+	host := ""
+	if m.FQDN != nil {
+		host = *m.FQDN
+	}
+	if m.Port != nil {
+		return host, *m.Port
+	}
+	if m.Protocol != nil && *m.Protocol == string(ProtocolHTTPS) {
+		return host, 443
+	}
+	return host, 80
+}
+
+// URL returns the URL of the member, e.g. "https://origin.example.net:443".
+func (m OriginGroupMember) URL() string {
+	scheme := string(ProtocolHTTP)
+	if m.Protocol != nil && *m.Protocol != "" {
+		scheme = *m.Protocol
+	}
+	host, port := m.HostPort()
+	return scheme + "://" + host + ":" + strconv.Itoa(port)
+}
+
+// OriginGroup is a group of Origins of a Delivery Service among which caches
+// fail over, or distribute requests by weight.
+//
+// HealthCheckPath and HealthCheckExpectedStatus describe how each member is
+// checked: a request for the path on the member's URL must respond with the
+// expected status.
+type OriginGroup struct {
+	DeliveryServiceID         *int                `json:"deliveryServiceId"`
+	DeliveryService           *string             `json:"deliveryService"`
+	CDNName                   *string             `json:"cdnName"`
+	Policy                    *OriginGroupPolicy  `json:"policy"`
+	HealthCheckPath           *string             `json:"healthCheckPath"`
+	HealthCheckExpectedStatus *int                `json:"healthCheckExpectedStatus"`
+	Members                   []OriginGroupMember `json:"members"`
+	LastUpdated               *TimeNoMod          `json:"lastUpdated"`
+}
+
+// HealthCheckURL returns the URL with which the member of the group is health
+// checked, or the empty string if the group has no health check.
+func (g OriginGroup) HealthCheckURL(m OriginGroupMember) string {
+	if g.HealthCheckPath == nil || *g.HealthCheckPath == "" {
+		return ""
+	}
+	return m.URL() + *g.HealthCheckPath
+}
+
+// MembersByRole returns the members of the group with the given role, in
+// order.
+func (g OriginGroup) MembersByRole(role OriginGroupMemberRole) []OriginGroupMember {
+	members := []OriginGroupMember{}
+	for _, m := range g.Members {
+		if m.Role != nil && *m.Role == role {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+// OriginGroupsResponse is the type of a response from Traffic Ops to a GET
+// request made to its /origin_groups endpoint.
+type OriginGroupsResponse struct {
+	Response []OriginGroup `json:"response"`
+	Alerts
+}
+
+// OriginGroupResponse is the type of a response from Traffic Ops to a PUT
+// request made to its /deliveryservices/{{ID}}/origin_group endpoint.
+type OriginGroupResponse struct {
+	Response OriginGroup `json:"response"`
+	Alerts
+}
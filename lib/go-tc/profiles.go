@@ -72,6 +72,10 @@ type ProfileNullable struct {
 	RoutingDisabled *bool               `json:"routingDisabled" db:"routing_disabled"`
 	Type            *string             `json:"type" db:"type"`
 	Parameters      []ParameterNullable `json:"params,omitempty"`
+	// Tenant and TenantID identify the tenant which owns the profile, if any. They're only available in API 3.0 and
+	// later, and a profile without a tenant may be managed by users of any tenant.
+	Tenant   *string `json:"tenant,omitempty" db:"tenant"`
+	TenantID *int    `json:"tenantId,omitempty" db:"tenant_id"`
 }
 
 // ProfileCopy contains details about the profile created from an existing profile.
@@ -101,6 +105,8 @@ type ProfileExportImportNullable struct {
 	Description *string `json:"description"`
 	CDNName     *string `json:"cdn"`
 	Type        *string `json:"type"`
+	// TenantID is the ID of the tenant which owns the profile, if any. It's not a part of API versions before 3.0.
+	TenantID *int `json:"tenantId,omitempty"`
 }
 
 // ProfileExportResponse is an object of the form used by Traffic Ops
@@ -46,6 +46,10 @@ type Role struct {
 	//
 	// required: true
 	Capabilities *[]string `json:"capabilities" db:"-"`
+
+	// MFARequired is whether users with the Role must use multi-factor
+	// authentication. It is only present in API version 3.0 and later.
+	MFARequired *bool `json:"mfaRequired,omitempty" db:"mfa_required"`
 }
 
 // RoleV11 ...
@@ -0,0 +1,205 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ServerLifecycleState is a state of the provisioning lifecycle of a server.
+// The state of a server is the name of its Status.
+type ServerLifecycleState string
+
+const (
+	// ServerLifecycleStatePreProd is the state of a server which has been
+	// created, but not yet been racked and tested.
+	ServerLifecycleStatePreProd = ServerLifecycleState("PRE_PROD")
+	// ServerLifecycleStateBurnIn is the state of a server whose hardware is
+	// being tested before it is put into service.
+	ServerLifecycleStateBurnIn = ServerLifecycleState("BURN_IN")
+	// ServerLifecycleStateReported is the state of a server which is monitored
+	// by Traffic Monitor, and serves traffic if it is healthy.
+	ServerLifecycleStateReported = ServerLifecycleState(CacheStatusReported)
+	// ServerLifecycleStateOnline is the state of a server which serves traffic
+	// irrespective of its health.
+	ServerLifecycleStateOnline = ServerLifecycleState(CacheStatusOnline)
+	// ServerLifecycleStateDecommissioned is the state of a server which has
+	// been permanently taken out of service.
+	ServerLifecycleStateDecommissioned = ServerLifecycleState("DECOMMISSIONED")
+)
+
+// ServerLifecycleTransitions are the states to which a server in each
+// lifecycle state may transition.
+//
+// A server whose Status is not a lifecycle state may only transition to
+// PRE_PROD or DECOMMISSIONED.
+var ServerLifecycleTransitions = map[ServerLifecycleState][]ServerLifecycleState{
+	ServerLifecycleStatePreProd:        {ServerLifecycleStateBurnIn, ServerLifecycleStateDecommissioned},
+	ServerLifecycleStateBurnIn:         {ServerLifecycleStateReported, ServerLifecycleStatePreProd, ServerLifecycleStateDecommissioned},
+	ServerLifecycleStateReported:       {ServerLifecycleStateOnline, ServerLifecycleStateDecommissioned},
+	ServerLifecycleStateOnline:         {ServerLifecycleStateReported, ServerLifecycleStateDecommissioned},
+	ServerLifecycleStateDecommissioned: {ServerLifecycleStatePreProd},
+}
+
+// ServerLifecycleStateFromStatus returns the lifecycle state of a server with
+// the given Status name, and whether the Status is a lifecycle state.
+func ServerLifecycleStateFromStatus(status string) (ServerLifecycleState, bool) {
+	state := ServerLifecycleState(status)
+	_, ok := ServerLifecycleTransitions[state]
+	return state, ok
+}
+
+// NextServerLifecycleStates returns the states to which a server with the
+// given Status name may transition.
+func NextServerLifecycleStates(status string) []ServerLifecycleState {
+	if state, ok := ServerLifecycleStateFromStatus(status); ok {
+		return ServerLifecycleTransitions[state]
+	}
+	return []ServerLifecycleState{ServerLifecycleStatePreProd, ServerLifecycleStateDecommissioned}
+}
+
+// ServerLifecycleResponse is the type of a response from Traffic Ops to a
+// request for the lifecycle of a server.
+type ServerLifecycleResponse struct {
+	Response ServerLifecycle `json:"response"`
+	Alerts
+}
+
+// ServerLifecycle is the provisioning lifecycle of a server: its current
+// Status, and the lifecycle states to which it may transition.
+type ServerLifecycle struct {
+	ServerID int    `json:"serverId"`
+	HostName string `json:"hostName"`
+	Status   string `json:"status"`
+	// State is the lifecycle state of the server, or nil if its Status is not
+	// a lifecycle state.
+	State       *ServerLifecycleState       `json:"state"`
+	Transitions []ServerLifecycleTransition `json:"transitions"`
+}
+
+// ServerLifecycleTransition is a lifecycle state to which a server may
+// transition, and the results of the gate checks of the transition.
+type ServerLifecycleTransition struct {
+	State ServerLifecycleState `json:"state"`
+	// Ready is whether all of the gate checks pass, so the server may
+	// transition now.
+	Ready  bool                   `json:"ready"`
+	Checks []ServerLifecycleCheck `json:"checks"`
+}
+
+// ServerLifecycleCheck is the result of a single gate check of a lifecycle
+// transition.
+type ServerLifecycleCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// ServerLifecycleTransitionRequest is a request to transition a server to a
+// lifecycle state.
+type ServerLifecycleTransitionRequest struct {
+	State ServerLifecycleState `json:"state"`
+}
+
+// Validate returns an error if the requested state is not a lifecycle state.
+func (req ServerLifecycleTransitionRequest) Validate() error {
+	if req.State == "" {
+		return errors.New("state is required")
+	}
+	if _, ok := ServerLifecycleTransitions[req.State]; !ok {
+		states := make([]string, 0, len(ServerLifecycleTransitions))
+		for _, state := range []ServerLifecycleState{ServerLifecycleStatePreProd, ServerLifecycleStateBurnIn, ServerLifecycleStateReported, ServerLifecycleStateOnline, ServerLifecycleStateDecommissioned} {
+			states = append(states, string(state))
+		}
+		return fmt.Errorf("state must be one of %s", strings.Join(states, ", "))
+	}
+	return nil
+}
+
+// ServerHardwareInventory is the inventory of the hardware of a server, posted
+// by the server itself, from which its hwinfo is populated.
+type ServerHardwareInventory struct {
+	Manufacturer string               `json:"manufacturer"`
+	Model        string               `json:"model"`
+	SerialNumber string               `json:"serialNumber"`
+	BIOSVersion  string               `json:"biosVersion"`
+	MemoryMB     int                  `json:"memoryMB"`
+	CPUs         []ServerHardwareCPU  `json:"cpus"`
+	Disks        []ServerHardwareDisk `json:"disks"`
+	NICs         []ServerHardwareNIC  `json:"nics"`
+}
+
+// ServerHardwareCPU is a CPU socket of a ServerHardwareInventory.
+type ServerHardwareCPU struct {
+	Model   string `json:"model"`
+	Cores   int    `json:"cores"`
+	Threads int    `json:"threads"`
+}
+
+// ServerHardwareDisk is a disk of a ServerHardwareInventory.
+type ServerHardwareDisk struct {
+	Device       string `json:"device"`
+	Model        string `json:"model"`
+	SerialNumber string `json:"serialNumber"`
+	SizeGB       int    `json:"sizeGB"`
+}
+
+// ServerHardwareNIC is a network interface card of a ServerHardwareInventory.
+type ServerHardwareNIC struct {
+	Name       string `json:"name"`
+	MACAddress string `json:"macAddress"`
+	Driver     string `json:"driver"`
+	Firmware   string `json:"firmware"`
+	SpeedMbps  int    `json:"speedMbps"`
+}
+
+// Validate returns an error if the inventory has disks or NICs without names,
+// or with the same name.
+func (inv ServerHardwareInventory) Validate() error {
+	errs := []string{}
+	devices := map[string]struct{}{}
+	for _, disk := range inv.Disks {
+		if disk.Device == "" {
+			errs = append(errs, "disks must have a device")
+			continue
+		}
+		if _, ok := devices[disk.Device]; ok {
+			errs = append(errs, "duplicate disk device "+disk.Device)
+		}
+		devices[disk.Device] = struct{}{}
+	}
+	names := map[string]struct{}{}
+	for _, nic := range inv.NICs {
+		if nic.Name == "" {
+			errs = append(errs, "nics must have a name")
+			continue
+		}
+		if _, ok := names[nic.Name]; ok {
+			errs = append(errs, "duplicate nic name "+nic.Name)
+		}
+		names[nic.Name] = struct{}{}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -381,6 +381,10 @@ type CommonServerProperties struct {
 	UpdPending       *bool                `json:"updPending" db:"upd_pending"`
 	XMPPID           *string              `json:"xmppId" db:"xmpp_id"`
 	XMPPPasswd       *string              `json:"xmppPasswd" db:"xmpp_passwd"`
+	// Tenant and TenantID identify the tenant which owns the server, if any. They're only available in API 3.0 and
+	// later, and a server without a tenant may be managed by users of any tenant.
+	Tenant   *string `json:"tenant,omitempty" db:"tenant"`
+	TenantID *int    `json:"tenantId,omitempty" db:"tenant_id"`
 }
 
 // ServerNullableV11 is a server as it appeared in API version 1.1.
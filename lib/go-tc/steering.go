@@ -43,3 +43,64 @@ type SteeringSteeringTarget struct {
 	Longitude       *float64            `json:"longitude,omitempty"`
 	Latitude        *float64            `json:"latitude,omitempty"`
 }
+
+// SteeringSimulationLocationSource is the way the client location of a
+// steering simulation was determined.
+type SteeringSimulationLocationSource string
+
+const (
+	// SteeringSimulationLocationSourceRequest indicates that the client
+	// location was given in the simulation request.
+	SteeringSimulationLocationSourceRequest = SteeringSimulationLocationSource("request")
+	// SteeringSimulationLocationSourceCoverageZone indicates that the client
+	// location is that of the cachegroup of the coverage zone containing the
+	// client IP address.
+	SteeringSimulationLocationSourceCoverageZone = SteeringSimulationLocationSource("coverageZone")
+)
+
+// SteeringSimulationRequest is the request body of a simulation of the
+// routing of a client request of a steering Delivery Service.
+type SteeringSimulationRequest struct {
+	RequestPath string   `json:"requestPath"`
+	ClientIP    *string  `json:"clientIp"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+}
+
+// SteeringSimulationLocation is the client location used by a steering
+// simulation.
+type SteeringSimulationLocation struct {
+	Latitude   float64                          `json:"latitude"`
+	Longitude  float64                          `json:"longitude"`
+	Source     SteeringSimulationLocationSource `json:"source"`
+	Cachegroup *string                          `json:"cachegroup"`
+}
+
+// SteeringSimulationTarget is a target of a steering Delivery Service, as
+// ordered by a steering simulation. Cachegroup is the cachegroup whose caches
+// would serve the client for the target, if it's known.
+type SteeringSimulationTarget struct {
+	SteeringSteeringTarget
+	Cachegroup *string `json:"cachegroup"`
+}
+
+// SteeringSimulation is the result of a simulation of the routing of a client
+// request of a steering Delivery Service. Targets are the targets Traffic
+// Router would use, in order of preference.
+type SteeringSimulation struct {
+	DeliveryService               DeliveryServiceName         `json:"deliveryService"`
+	ClientSteering                bool                        `json:"clientSteering"`
+	RequestPath                   string                      `json:"requestPath"`
+	ResultingPathToConsistentHash string                      `json:"resultingPathToConsistentHash"`
+	Dispersion                    CRConfigDispersion          `json:"dispersion"`
+	ClientLocation                *SteeringSimulationLocation `json:"clientLocation"`
+	BypassTarget                  *DeliveryServiceName        `json:"bypassTarget"`
+	Targets                       []SteeringSimulationTarget  `json:"targets"`
+}
+
+// SteeringSimulationResponse is the type of a response from Traffic Ops to a
+// request to simulate the routing of a steering Delivery Service.
+type SteeringSimulationResponse struct {
+	Response SteeringSimulation `json:"response"`
+	Alerts
+}
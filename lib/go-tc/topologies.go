@@ -25,6 +25,10 @@ type Topology struct {
 	Name        string         `json:"name" db:"name"`
 	Nodes       []TopologyNode `json:"nodes"`
 	LastUpdated *TimeNoMod     `json:"lastUpdated" db:"last_updated"`
+	// Tenant and TenantID identify the tenant which owns the topology, if any, which may be managed only by users
+	// of that tenant or its parents; a topology without a tenant may be managed by users of any tenant.
+	Tenant   *string `json:"tenant,omitempty" db:"tenant"`
+	TenantID *int    `json:"tenantId,omitempty" db:"tenant_id"`
 }
 
 // TopologyNode holds a reference to a cachegroup and the indices of up to 2 parent
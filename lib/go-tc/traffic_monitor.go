@@ -144,6 +144,16 @@ type TMParameters struct {
 	HistoryCount            int    `json:"history.count"`
 	MinFreeKbps             int64
 	Thresholds              map[string]HealthThreshold `json:"health_threshold"`
+	// Rules are the health rule expressions, keyed on the rule name. A cache is unhealthy when any rule expression is true.
+	Rules map[string]string `json:"health_rule"`
+	// HysteresisDown is the number of consecutive polls which must exceed a threshold or rule before a cache is marked unavailable. Values less than 1 are treated as 1.
+	HysteresisDown int `json:"health.hysteresis.down"`
+	// HysteresisUp is the number of consecutive polls which must be within all thresholds and rules before a cache marked unavailable by a threshold or rule is marked available again. Values less than 1 are treated as 1.
+	HysteresisUp int `json:"health.hysteresis.up"`
+	// SlowStartPeriod is the number of milliseconds over which a cache which becomes available after being unavailable is ramped up to its full share of load. If 0, caches receive their full share of load as soon as they become available.
+	SlowStartPeriod int `json:"health.slowstart.period"`
+	// StatMappings are the names of the stats in the payload of caches whose health.polling.format doesn't have fixed stat names, keyed on the stat Traffic Monitor requires, such as `loadavg` or `interface.bytes_out`.
+	StatMappings map[string]string `json:"health_polling_stat"`
 }
 
 const DefaultHealthThresholdComparator = "<"
@@ -218,6 +228,22 @@ func (params *TMParameters) UnmarshalJSON(bytes []byte) (err error) {
 		}
 	}
 
+	if vi, ok := raw["health.hysteresis.down"]; ok {
+		if v, ok := vi.(float64); !ok {
+			return fmt.Errorf("Unmarshalling TMParameters health.hysteresis.down expected integer, got %v", vi)
+		} else {
+			params.HysteresisDown = int(v)
+		}
+	}
+
+	if vi, ok := raw["health.hysteresis.up"]; ok {
+		if v, ok := vi.(float64); !ok {
+			return fmt.Errorf("Unmarshalling TMParameters health.hysteresis.up expected integer, got %v", vi)
+		} else {
+			params.HysteresisUp = int(v)
+		}
+	}
+
 	params.Thresholds = map[string]HealthThreshold{}
 	thresholdPrefix := "health.threshold."
 	for k, v := range raw {
@@ -231,6 +257,34 @@ func (params *TMParameters) UnmarshalJSON(bytes []byte) (err error) {
 			}
 		}
 	}
+
+	if vi, ok := raw["health.slowstart.period"]; ok {
+		if v, ok := vi.(float64); !ok {
+			return fmt.Errorf("Unmarshalling TMParameters health.slowstart.period expected integer, got %v", vi)
+		} else {
+			params.SlowStartPeriod = int(v)
+		}
+	}
+
+	params.Rules = map[string]string{}
+	rulePrefix := "health.rule."
+	for k, v := range raw {
+		if strings.HasPrefix(k, rulePrefix) {
+			if vStr, ok := v.(string); !ok {
+				return fmt.Errorf("Unmarshalling TMParameters `health.rule.` parameter value expected string, got %v: rule '%s'", v, k)
+			} else {
+				params.Rules[k[len(rulePrefix):]] = vStr
+			}
+		}
+	}
+
+	params.StatMappings = map[string]string{}
+	statMappingPrefix := "health.polling.stat."
+	for k, v := range raw {
+		if strings.HasPrefix(k, statMappingPrefix) {
+			params.StatMappings[k[len(statMappingPrefix):]] = fmt.Sprintf("%v", v) // allows string or numeric JSON types, for scales.
+		}
+	}
 	return nil
 }
 
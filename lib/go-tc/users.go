@@ -23,6 +23,7 @@ import "database/sql"
 import "encoding/json"
 import "errors"
 import "fmt"
+import "time"
 
 import "github.com/apache/trafficcontrol/lib/go-rfc"
 import "github.com/apache/trafficcontrol/lib/go-util"
@@ -104,6 +105,10 @@ type User struct {
 	// It's done that way in order to maintain "rolename" vs "roleName" JSON field capitalization for the different users APIs.
 	// TODO: make the breaking API change to make all user APIs use "roleName" consistently.
 	RoleName *string `json:"roleName,omitempty" db:"-"`
+	// Locked is whether the user is locked out after too many failed login
+	// attempts. It is only present in API 3.0+ responses, and is ignored in
+	// requests.
+	Locked *bool `json:"locked,omitempty" db:"locked"`
 	commonUserFields
 }
 
@@ -359,3 +364,91 @@ func (urr *UserRegistrationRequest) Validate(tx *sql.Tx) error {
 
 	return util.JoinErrs(errs)
 }
+
+// UserMFAStatus is the state of the multi-factor authentication of the current
+// user.
+type UserMFAStatus struct {
+	// Enabled is whether the user has enrolled in and verified multi-factor
+	// authentication.
+	Enabled bool `json:"enabled"`
+	// Enrolling is whether the user has begun enrolling in, but not yet
+	// verified, multi-factor authentication.
+	Enrolling bool `json:"enrolling"`
+	// Required is whether the user's Role requires multi-factor
+	// authentication.
+	Required bool `json:"required"`
+	// Verified is whether the user verified a second factor for the current
+	// session. If the user's Role requires it, or they enabled it, the session
+	// may only be used to enroll or verify until they do.
+	Verified               bool `json:"verified"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// UserMFAStatusResponse can hold a Traffic Ops API response to a request to
+// get the multi-factor authentication state of the current user.
+type UserMFAStatusResponse struct {
+	Response UserMFAStatus `json:"response"`
+	Alerts
+}
+
+// UserMFAEnrollment is a new TOTP secret generated for the current user, which
+// they must add to an authenticator app and then verify with a code from it to
+// enable multi-factor authentication.
+type UserMFAEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth URI of the secret, which authenticator apps
+	// typically accept as a QR code.
+	URI string `json:"uri"`
+}
+
+// UserMFAEnrollmentResponse can hold a Traffic Ops API response to a request to
+// enroll the current user in multi-factor authentication.
+type UserMFAEnrollmentResponse struct {
+	Response UserMFAEnrollment `json:"response"`
+	Alerts
+}
+
+// UserMFACodeRequest is a request to verify or disable multi-factor
+// authentication, with a TOTP or recovery code as proof of a second factor.
+type UserMFACodeRequest struct {
+	OTP string `json:"otp"`
+}
+
+// UserMFAVerification is the result of verifying a second factor.
+type UserMFAVerification struct {
+	// RecoveryCodes are generated only when a verification enables
+	// multi-factor authentication, and are never shown again.
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// UserMFAVerificationResponse can hold a Traffic Ops API response to a request
+// to verify a second factor.
+type UserMFAVerificationResponse struct {
+	Response UserMFAVerification `json:"response"`
+	Alerts
+}
+
+// UserSession is a session of a Traffic Ops user, begun by logging in.
+type UserSession struct {
+	ID int `json:"id"`
+	// Created is when the user logged in.
+	Created time.Time `json:"created"`
+	// LastSeen is when the session was last used.
+	LastSeen time.Time `json:"lastSeen"`
+	// Expires is when the session expires, unless it's used before then.
+	Expires time.Time `json:"expires"`
+	// UserAgent is the User-Agent of the client which logged in.
+	UserAgent string `json:"userAgent"`
+	// ClientIP is the IP address of the client which logged in.
+	ClientIP string `json:"clientIp"`
+	// Current is whether this is the session with which the request for it
+	// was made.
+	Current bool `json:"current"`
+}
+
+// UserSessionsResponse can hold a Traffic Ops API response to a request to
+// get the sessions of the current user.
+type UserSessionsResponse struct {
+	Response []UserSession `json:"response"`
+	Alerts
+}
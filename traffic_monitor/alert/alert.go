@@ -0,0 +1,289 @@
+// Package alert evaluates alert rules against the state of the CDN, and notifies webhooks, syslog, and email addresses when alerts fire and resolve.
+//
+// Rules and notifiers are configured by the alert_rules and alert_notifiers of traffic_monitor.cfg. An alert fires for each subject of a rule, e.g. each cache or Cache Group, once the rule's condition has been true of it for the rule's duration, and resolves once it's no longer true. Each is notified once when it fires, and once when it resolves.
+package alert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+const (
+	// RuleTypeCacheDown is the type of rules which fire for each cache which is unavailable.
+	RuleTypeCacheDown = "cache_down"
+	// RuleTypeCacheGroupDown is the type of rules which fire for each Cache Group with at least Count unavailable caches.
+	RuleTypeCacheGroupDown = "cachegroup_down"
+	// RuleTypeDSAvailableBandwidth is the type of rules which fire for each Delivery Service whose available caches have less than Kbps of bandwidth available in total.
+	RuleTypeDSAvailableBandwidth = "ds_available_bandwidth"
+)
+
+const (
+	// StatusFiring is the status of a Notification of an alert which fired.
+	StatusFiring = "firing"
+	// StatusResolved is the status of a Notification of an alert which resolved.
+	StatusResolved = "resolved"
+)
+
+// State is the state of the CDN which alert rules are evaluated against.
+type State struct {
+	Time time.Time
+	// Caches are the states of the caches which are monitored, i.e. REPORTED or ONLINE. Caches which are ADMIN_DOWN or OFFLINE are being maintained, and are expected to be unavailable.
+	Caches      map[tc.CacheName]tc.IsAvailable
+	CacheGroups map[tc.CacheName]tc.CacheGroupName
+	// DSAvailableKbps is the total bandwidth available on the available caches of each Delivery Service.
+	DSAvailableKbps map[tc.DeliveryServiceName]float64
+}
+
+// NewState returns the State of the given cache states, TO data, stat info history, and monitor config, which has the statuses of the caches.
+func NewState(now time.Time, caches map[tc.CacheName]tc.IsAvailable, toData todata.TOData, infoHistory cache.ResultInfoHistory, monitorConfig tc.LegacyTrafficMonitorConfigMap) State {
+	monitoredCaches := make(map[tc.CacheName]tc.IsAvailable, len(caches))
+	for cacheName, available := range caches {
+		switch tc.CacheStatusFromString(monitorConfig.TrafficServer[string(cacheName)].ServerStatus) {
+		case tc.CacheStatusReported, tc.CacheStatusOnline:
+			monitoredCaches[cacheName] = available
+		}
+	}
+	state := State{
+		Time:            now,
+		Caches:          monitoredCaches,
+		CacheGroups:     toData.ServerCachegroups,
+		DSAvailableKbps: make(map[tc.DeliveryServiceName]float64, len(toData.DeliveryServiceServers)),
+	}
+	for ds, dsCaches := range toData.DeliveryServiceServers {
+		kbps := 0.0
+		for _, cacheName := range dsCaches {
+			if !caches[cacheName].IsAvailable || len(infoHistory[cacheName]) == 0 {
+				continue
+			}
+			if vitals := infoHistory[cacheName][0].Vitals; vitals.MaxKbpsOut > vitals.KbpsOut {
+				kbps += float64(vitals.MaxKbpsOut - vitals.KbpsOut)
+			}
+		}
+		state.DSAvailableKbps[ds] = kbps
+	}
+	return state
+}
+
+// Notification is an alert firing or resolving.
+type Notification struct {
+	Monitor     string    `json:"monitor"`
+	Rule        string    `json:"rule"`
+	Type        string    `json:"type"`
+	Subject     string    `json:"subject"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Time        time.Time `json:"time"`
+}
+
+// Alerter evaluates alert rules, and sends Notifications of the alerts which fire and resolve to its notifiers. Evaluate and Notify are each not safe for multiple goroutines, but one goroutine may Evaluate while another Notifies.
+type Alerter struct {
+	monitor   string
+	rules     []config.AlertRule
+	notifiers []Notifier
+	pending   map[string]time.Time    // when the condition of each alert became true, by alertKey
+	firing    map[string]Notification // the Notification of each firing alert, by alertKey
+}
+
+// New creates a new Alerter of the given rules and notifiers. The monitor is the name of this Traffic Monitor, and the timeout is the timeout of sending notifications.
+// If there are no rules, nil is returned, and alerting is disabled.
+func New(rules []config.AlertRule, notifiers []config.AlertNotifier, monitor string, timeout time.Duration) (*Alerter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	names := map[string]struct{}{}
+	for _, rule := range rules {
+		if err := validateRule(rule); err != nil {
+			return nil, fmt.Errorf("alert rule '%s': %v", rule.Name, err)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return nil, errors.New("duplicate alert rule name '" + rule.Name + "'")
+		}
+		names[rule.Name] = struct{}{}
+	}
+
+	a := &Alerter{
+		monitor:   monitor,
+		rules:     rules,
+		notifiers: make([]Notifier, 0, len(notifiers)),
+		pending:   map[string]time.Time{},
+		firing:    map[string]Notification{},
+	}
+	for i, notifierConfig := range notifiers {
+		notifier, err := NewNotifier(notifierConfig, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("alert notifier %d: %v", i, err)
+		}
+		a.notifiers = append(a.notifiers, notifier)
+	}
+	return a, nil
+}
+
+func validateRule(rule config.AlertRule) error {
+	if rule.Name == "" {
+		return errors.New("missing name")
+	}
+	switch rule.Type {
+	case RuleTypeCacheDown:
+	case RuleTypeCacheGroupDown:
+		if rule.Count < 1 {
+			return errors.New("count must be at least 1")
+		}
+	case RuleTypeDSAvailableBandwidth:
+		if rule.Kbps <= 0 {
+			return errors.New("kbps must be positive")
+		}
+	default:
+		return errors.New("unknown type '" + rule.Type + "'")
+	}
+	return nil
+}
+
+// Evaluate evaluates the rules against the given State, returning the Notifications of the alerts which fired or resolved since the last evaluation, ordered by rule and subject.
+func (a *Alerter) Evaluate(state State) []Notification {
+	notifications := []Notification{}
+	conditions := map[string]Notification{}
+	for _, rule := range a.rules {
+		for subject, description := range evaluateRule(rule, state) {
+			conditions[alertKey(rule.Name, subject)] = Notification{
+				Monitor:     a.monitor,
+				Rule:        rule.Name,
+				Type:        rule.Type,
+				Subject:     subject,
+				Status:      StatusFiring,
+				Description: description,
+				Time:        state.Time,
+			}
+		}
+	}
+
+	for key, notification := range conditions {
+		since, ok := a.pending[key]
+		if !ok {
+			since = state.Time
+			a.pending[key] = since
+		}
+		if _, ok := a.firing[key]; ok {
+			continue
+		}
+		if duration := ruleDuration(a.rules, notification.Rule); state.Time.Sub(since) < duration {
+			continue
+		}
+		a.firing[key] = notification
+		notifications = append(notifications, notification)
+	}
+
+	for key := range a.pending {
+		if _, ok := conditions[key]; !ok {
+			delete(a.pending, key)
+		}
+	}
+	for key, notification := range a.firing {
+		if _, ok := conditions[key]; ok {
+			continue
+		}
+		delete(a.firing, key)
+		notification.Status = StatusResolved
+		notification.Time = state.Time
+		notifications = append(notifications, notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		if notifications[i].Rule != notifications[j].Rule {
+			return notifications[i].Rule < notifications[j].Rule
+		}
+		return notifications[i].Subject < notifications[j].Subject
+	})
+	return notifications
+}
+
+// Notify sends the given Notification to all notifiers, returning the errors of any which failed.
+func (a *Alerter) Notify(notification Notification) error {
+	errs := []string{}
+	for _, notifier := range a.notifiers {
+		if err := notifier.Notify(notification); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func alertKey(rule string, subject string) string {
+	return rule + "/" + subject
+}
+
+func ruleDuration(rules []config.AlertRule, name string) time.Duration {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return time.Duration(rule.DurationMs) * time.Millisecond
+		}
+	}
+	return 0
+}
+
+// evaluateRule returns the subjects the condition of the given rule is true of in the given State, mapped to a description of why.
+func evaluateRule(rule config.AlertRule, state State) map[string]string {
+	subjects := map[string]string{}
+	switch rule.Type {
+	case RuleTypeCacheDown:
+		for cacheName, available := range state.Caches {
+			if available.IsAvailable || (rule.CacheGroup != "" && string(state.CacheGroups[cacheName]) != rule.CacheGroup) {
+				continue
+			}
+			subjects[string(cacheName)] = fmt.Sprintf("cache %s is unavailable", cacheName)
+		}
+	case RuleTypeCacheGroupDown:
+		caches := map[tc.CacheGroupName]int{}
+		down := map[tc.CacheGroupName]int{}
+		for cacheName, available := range state.Caches {
+			cacheGroup := state.CacheGroups[cacheName]
+			caches[cacheGroup]++
+			if !available.IsAvailable {
+				down[cacheGroup]++
+			}
+		}
+		for cacheGroup, downCount := range down {
+			if downCount < rule.Count || (rule.CacheGroup != "" && string(cacheGroup) != rule.CacheGroup) {
+				continue
+			}
+			subjects[string(cacheGroup)] = fmt.Sprintf("%d of %d caches in cachegroup %s are unavailable", downCount, caches[cacheGroup], cacheGroup)
+		}
+	case RuleTypeDSAvailableBandwidth:
+		for ds, kbps := range state.DSAvailableKbps {
+			if kbps >= rule.Kbps || (rule.DeliveryService != "" && string(ds) != rule.DeliveryService) {
+				continue
+			}
+			subjects[string(ds)] = fmt.Sprintf("delivery service %s has %.0f kbps available, below %.0f kbps", ds, kbps, rule.Kbps)
+		}
+	}
+	return subjects
+}
@@ -0,0 +1,295 @@
+package alert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+func TestEvaluateCacheDown(t *testing.T) {
+	a, err := New([]config.AlertRule{{Name: "down", Type: RuleTypeCacheDown, DurationMs: 60000}}, nil, "tm", time.Second)
+	if err != nil {
+		t.Fatalf("creating alerter: %v", err)
+	}
+	start := time.Now()
+	state := func(offset time.Duration, available bool) State {
+		return State{Time: start.Add(offset), Caches: map[tc.CacheName]tc.IsAvailable{"edge": {IsAvailable: available}, "mid": {IsAvailable: true}}}
+	}
+
+	if n := a.Evaluate(state(0, false)); len(n) != 0 {
+		t.Errorf("expected no notifications before the rule duration, actual %+v", n)
+	}
+	n := a.Evaluate(state(time.Minute, false))
+	if len(n) != 1 || n[0].Subject != "edge" || n[0].Status != StatusFiring || n[0].Monitor != "tm" {
+		t.Fatalf("expected edge to fire after the rule duration, actual %+v", n)
+	}
+	if n := a.Evaluate(state(2*time.Minute, false)); len(n) != 0 {
+		t.Errorf("expected a firing alert not to be notified again, actual %+v", n)
+	}
+	n = a.Evaluate(state(3*time.Minute, true))
+	if len(n) != 1 || n[0].Subject != "edge" || n[0].Status != StatusResolved || n[0].Description != "cache edge is unavailable" {
+		t.Fatalf("expected edge to resolve, actual %+v", n)
+	}
+
+	// a cache which is down and up again within the duration never fires
+	a.Evaluate(state(4*time.Minute, false))
+	a.Evaluate(state(4*time.Minute+30*time.Second, true))
+	if n := a.Evaluate(state(6*time.Minute, true)); len(n) != 0 {
+		t.Errorf("expected no notifications for a cache down shorter than the duration, actual %+v", n)
+	}
+}
+
+func TestEvaluateCacheGroupDownAndDSBandwidth(t *testing.T) {
+	a, err := New([]config.AlertRule{
+		{Name: "cg", Type: RuleTypeCacheGroupDown, Count: 2},
+		{Name: "bw", Type: RuleTypeDSAvailableBandwidth, Kbps: 1000, DeliveryService: "ds1"},
+	}, nil, "tm", time.Second)
+	if err != nil {
+		t.Fatalf("creating alerter: %v", err)
+	}
+	state := State{
+		Time:            time.Now(),
+		Caches:          map[tc.CacheName]tc.IsAvailable{"a": {}, "b": {}, "c": {IsAvailable: true}, "d": {}},
+		CacheGroups:     map[tc.CacheName]tc.CacheGroupName{"a": "cg0", "b": "cg0", "c": "cg0", "d": "cg1"},
+		DSAvailableKbps: map[tc.DeliveryServiceName]float64{"ds1": 500, "ds2": 0},
+	}
+	n := a.Evaluate(state)
+	if len(n) != 2 {
+		t.Fatalf("expected 2 notifications, actual %+v", n)
+	}
+	if n[0].Rule != "bw" || n[0].Subject != "ds1" {
+		t.Errorf("expected ds1 below its available bandwidth, actual %+v", n[0])
+	}
+	if n[1].Rule != "cg" || n[1].Subject != "cg0" || n[1].Description != "2 of 3 caches in cachegroup cg0 are unavailable" {
+		t.Errorf("expected cg0 with 2 caches down, actual %+v", n[1])
+	}
+}
+
+func TestNewState(t *testing.T) {
+	toData := todata.New()
+	toData.DeliveryServiceServers = map[tc.DeliveryServiceName][]tc.CacheName{"ds1": {"a", "b", "c"}}
+	caches := map[tc.CacheName]tc.IsAvailable{"a": {IsAvailable: true}, "b": {IsAvailable: true}, "c": {}}
+	history := cache.ResultInfoHistory{
+		"a": {{Vitals: cache.Vitals{KbpsOut: 100, MaxKbpsOut: 1000}}},
+		"b": {{Vitals: cache.Vitals{KbpsOut: 200, MaxKbpsOut: 500}}},
+		"c": {{Vitals: cache.Vitals{KbpsOut: 0, MaxKbpsOut: 1000}}},
+	}
+	monitorConfig := tc.LegacyTrafficMonitorConfigMap{TrafficServer: map[string]tc.LegacyTrafficServer{
+		"a": {ServerStatus: string(tc.CacheStatusReported)},
+		"b": {ServerStatus: string(tc.CacheStatusOnline)},
+		"c": {ServerStatus: string(tc.CacheStatusReported)},
+	}}
+	state := NewState(time.Now(), caches, *toData, history, monitorConfig)
+	if kbps := state.DSAvailableKbps["ds1"]; kbps != 1200 {
+		t.Errorf("expected ds1 to have the 1200 kbps available on its available caches, actual %v", kbps)
+	}
+}
+
+func TestNewStateMaintenance(t *testing.T) {
+	toData := todata.New()
+	toData.ServerCachegroups = map[tc.CacheName]tc.CacheGroupName{"a": "cg", "b": "cg", "c": "cg", "d": "cg"}
+	caches := map[tc.CacheName]tc.IsAvailable{"a": {IsAvailable: true}, "b": {}, "c": {}, "d": {}}
+	monitorConfig := tc.LegacyTrafficMonitorConfigMap{TrafficServer: map[string]tc.LegacyTrafficServer{
+		"a": {ServerStatus: string(tc.CacheStatusReported)},
+		"b": {ServerStatus: string(tc.CacheStatusReported)},
+		"c": {ServerStatus: string(tc.CacheStatusAdminDown)},
+		"d": {ServerStatus: string(tc.CacheStatusOffline)},
+	}}
+	state := NewState(time.Now(), caches, *toData, cache.ResultInfoHistory{}, monitorConfig)
+
+	down := evaluateRule(config.AlertRule{Name: "down", Type: RuleTypeCacheDown}, state)
+	if _, ok := down["b"]; !ok || len(down) != 1 {
+		t.Errorf("expected cache_down to fire for only the REPORTED unavailable cache b, actual %v", down)
+	}
+	cgDown := evaluateRule(config.AlertRule{Name: "cgdown", Type: RuleTypeCacheGroupDown, Count: 2}, state)
+	if len(cgDown) != 0 {
+		t.Errorf("expected cachegroup_down not to count ADMIN_DOWN or OFFLINE caches, actual %v", cgDown)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for name, rules := range map[string][]config.AlertRule{
+		"missing name":   {{Type: RuleTypeCacheDown}},
+		"unknown type":   {{Name: "r", Type: "nope"}},
+		"missing count":  {{Name: "r", Type: RuleTypeCacheGroupDown}},
+		"missing kbps":   {{Name: "r", Type: RuleTypeDSAvailableBandwidth}},
+		"duplicate name": {{Name: "r", Type: RuleTypeCacheDown}, {Name: "r", Type: RuleTypeCacheDown}},
+	} {
+		if _, err := New(rules, nil, "tm", time.Second); err == nil {
+			t.Errorf("%s: expected error, actual nil", name)
+		}
+	}
+	if _, err := New([]config.AlertRule{{Name: "r", Type: RuleTypeCacheDown}}, []config.AlertNotifier{{Type: NotifierTypeWebhook}}, "tm", time.Second); err == nil {
+		t.Errorf("webhook without url: expected error, actual nil")
+	}
+	if a, err := New(nil, nil, "tm", time.Second); a != nil || err != nil {
+		t.Errorf("no rules: expected nil alerter and error, actual %v %v", a, err)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan Notification, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := Notification{}
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("decoding notification: %v", err)
+		}
+		received <- n
+	}))
+	defer srv.Close()
+
+	a, err := New([]config.AlertRule{{Name: "down", Type: RuleTypeCacheDown}}, []config.AlertNotifier{{Type: NotifierTypeWebhook, URL: srv.URL}}, "tm", time.Second)
+	if err != nil {
+		t.Fatalf("creating alerter: %v", err)
+	}
+	sent := Notification{Monitor: "tm", Rule: "down", Type: RuleTypeCacheDown, Subject: "edge", Status: StatusFiring, Description: "cache edge is unavailable", Time: time.Now().UTC()}
+	if err := a.Notify(sent); err != nil {
+		t.Fatalf("notifying: %v", err)
+	}
+	if n := <-received; n.Subject != sent.Subject || n.Status != sent.Status || !n.Time.Equal(sent.Time) {
+		t.Errorf("expected webhook to receive %+v, actual %+v", sent, n)
+	}
+}
+
+func TestSMTPNotifierStartTLS(t *testing.T) {
+	// httptest's TLS server has a certificate for 127.0.0.1, which the fake SMTP server reuses.
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsSrv.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(tlsSrv.Certificate())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	defer ln.Close()
+
+	type received struct {
+		authTLS bool
+		auth    string
+		msg     string
+		err     error
+	}
+	done := make(chan received, 1)
+	go func() {
+		r := received{}
+		defer func() { done <- r }()
+		conn, err := ln.Accept()
+		if err != nil {
+			r.err = err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		isTLS := false
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost fake smtp")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				r.err = err
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO":
+				if isTLS {
+					tp.PrintfLine("250-localhost\r\n250 AUTH PLAIN")
+				} else {
+					tp.PrintfLine("250-localhost\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+				}
+			case "STARTTLS":
+				tp.PrintfLine("220 ready")
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: tlsSrv.TLS.Certificates})
+				if err := tlsConn.Handshake(); err != nil {
+					r.err = err
+					return
+				}
+				conn = tlsConn
+				tp = textproto.NewConn(conn)
+				isTLS = true
+			case "AUTH":
+				r.authTLS = isTLS
+				r.auth = line
+				tp.PrintfLine("235 ok")
+			case "MAIL", "RCPT":
+				tp.PrintfLine("250 ok")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				msg, err := tp.ReadDotBytes()
+				if err != nil {
+					r.err = err
+					return
+				}
+				r.msg = string(msg)
+				tp.PrintfLine("250 ok")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 unknown command")
+			}
+		}
+	}()
+
+	n := smtpNotifier{
+		address: ln.Addr().String(),
+		host:    "127.0.0.1",
+		from:    "tm@example.test",
+		to:      []string{"ops@example.test"},
+		auth:    smtp.PlainAuth("", "user", "pass", "127.0.0.1"),
+		timeout: 5 * time.Second,
+		rootCAs: rootCAs,
+	}
+	sent := Notification{Monitor: "tm", Rule: "down", Type: RuleTypeCacheDown, Subject: "edge", Status: StatusFiring, Description: "cache edge is unavailable", Time: time.Now().UTC()}
+	if err := n.Notify(sent); err != nil {
+		t.Fatalf("notifying: %v", err)
+	}
+
+	r := <-done
+	if r.err != nil {
+		t.Fatalf("fake smtp server: %v", r.err)
+	}
+	if !r.authTLS {
+		t.Errorf("expected auth after starttls, actual auth over plaintext")
+	}
+	if expected := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00pass")); r.auth != expected {
+		t.Errorf("expected auth '%v', actual '%v'", expected, r.auth)
+	}
+	if !strings.Contains(r.msg, sent.Description) {
+		t.Errorf("expected message to contain '%v', actual '%v'", sent.Description, r.msg)
+	}
+}
@@ -0,0 +1,200 @@
+package alert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/syslog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+
+	"github.com/json-iterator/go"
+)
+
+const (
+	// NotifierTypeWebhook is the type of notifiers which POST each Notification as JSON to a URL.
+	NotifierTypeWebhook = "webhook"
+	// NotifierTypeSyslog is the type of notifiers which log each Notification to syslog, local if no Address is given.
+	NotifierTypeSyslog = "syslog"
+	// NotifierTypeSMTP is the type of notifiers which email each Notification.
+	NotifierTypeSMTP = "smtp"
+)
+
+// DefaultSyslogTag is the syslog tag of notifications, if a syslog notifier has no Tag.
+const DefaultSyslogTag = "traffic_monitor"
+
+// Notifier sends Notifications somewhere.
+type Notifier interface {
+	Notify(Notification) error
+}
+
+// NewNotifier creates the Notifier of the given configuration, whose sends time out after the given timeout.
+func NewNotifier(cfg config.AlertNotifier, timeout time.Duration) (Notifier, error) {
+	switch cfg.Type {
+	case NotifierTypeWebhook:
+		if cfg.URL == "" {
+			return nil, errors.New("webhook missing url")
+		}
+		return webhookNotifier{url: cfg.URL, client: &http.Client{Timeout: timeout}}, nil
+	case NotifierTypeSyslog:
+		tag := cfg.Tag
+		if tag == "" {
+			tag = DefaultSyslogTag
+		}
+		return &syslogNotifier{network: cfg.Network, address: cfg.Address, tag: tag}, nil
+	case NotifierTypeSMTP:
+		if cfg.Address == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, errors.New("smtp missing address, from, or to")
+		}
+		host, _, err := net.SplitHostPort(cfg.Address)
+		if err != nil {
+			return nil, errors.New("smtp address must be host:port: " + err.Error())
+		}
+		n := smtpNotifier{address: cfg.Address, host: host, from: cfg.From, to: cfg.To, timeout: timeout}
+		if cfg.User != "" {
+			n.auth = smtp.PlainAuth("", cfg.User, cfg.Password, host)
+		}
+		return n, nil
+	}
+	return nil, errors.New("unknown type '" + cfg.Type + "'")
+}
+
+// summary returns a one-line summary of the given Notification.
+func summary(n Notification) string {
+	return fmt.Sprintf("%s %s %s: %s", strings.ToUpper(n.Status), n.Rule, n.Subject, n.Description)
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n webhookNotifier) Notify(notification Notification) error {
+	json := jsoniter.ConfigFastest
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return errors.New("marshalling notification: " + err.Error())
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.New("posting to webhook " + n.url + ": " + err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("posting to webhook %s: bad HTTP status %v", n.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// syslogNotifier connects to syslog on its first notification, so an unreachable syslog server doesn't stop Traffic Monitor from starting. The syslog.Writer reconnects by itself after errors.
+type syslogNotifier struct {
+	network string
+	address string
+	tag     string
+	writer  *syslog.Writer
+}
+
+func (n *syslogNotifier) Notify(notification Notification) error {
+	if n.writer == nil {
+		writer, err := syslog.Dial(n.network, n.address, syslog.LOG_WARNING|syslog.LOG_DAEMON, n.tag)
+		if err != nil {
+			return errors.New("connecting to syslog: " + err.Error())
+		}
+		n.writer = writer
+	}
+	if notification.Status == StatusResolved {
+		return n.writer.Notice(summary(notification))
+	}
+	return n.writer.Crit(summary(notification))
+}
+
+type smtpNotifier struct {
+	address string
+	host    string
+	from    string
+	to      []string
+	auth    smtp.Auth
+	timeout time.Duration
+	rootCAs *x509.CertPool // nil uses the system roots
+}
+
+// Notify emails the Notification. It's smtp.SendMail, with a timeout.
+func (n smtpNotifier) Notify(notification Notification) error {
+	conn, err := net.DialTimeout("tcp", n.address, n.timeout)
+	if err != nil {
+		return errors.New("connecting to smtp server: " + err.Error())
+	}
+	defer conn.Close()
+	if n.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(n.timeout))
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return errors.New("smtp: " + err.Error())
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host, RootCAs: n.rootCAs}); err != nil {
+			return errors.New("smtp starttls: " + err.Error())
+		}
+	}
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return errors.New("smtp auth: " + err.Error())
+		}
+	}
+	if err := client.Mail(n.from); err != nil {
+		return errors.New("smtp mail: " + err.Error())
+	}
+	for _, to := range n.to {
+		if err := client.Rcpt(to); err != nil {
+			return errors.New("smtp rcpt " + to + ": " + err.Error())
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return errors.New("smtp data: " + err.Error())
+	}
+	msg := "From: " + n.from + "\r\n" +
+		"To: " + strings.Join(n.to, ", ") + "\r\n" +
+		"Subject: [" + notification.Monitor + "] " + summary(notification) + "\r\n" +
+		"\r\n" +
+		notification.Description + "\r\n" +
+		"\r\nRule: " + notification.Rule + " (" + notification.Type + ")\r\n" +
+		"Time: " + notification.Time.Format(time.RFC3339) + "\r\n"
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return errors.New("smtp write: " + err.Error())
+	}
+	if err := w.Close(); err != nil {
+		return errors.New("smtp data: " + err.Error())
+	}
+	return client.Quit()
+}
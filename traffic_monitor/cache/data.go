@@ -72,6 +72,27 @@ type AvailableStatus struct {
 	UnavailableStat string
 	// Poller is the name of the poller which set this availability status.
 	Poller string
+	// ThresholdFailures is the number of consecutive evaluations in which a
+	// threshold or health rule was exceeded.
+	ThresholdFailures uint64
+	// FailingStat is the stat whose threshold or health rule was exceeded by
+	// the ThresholdFailures, while they're too few to mark the cache server
+	// unavailable. This exists so a poller which doesn't have the stat won't
+	// reset the count of failures.
+	FailingStat string
+	// ThresholdPasses is the number of consecutive evaluations in which all
+	// thresholds and health rules were met, counting only evaluations by
+	// pollers which have the UnavailableStat.
+	ThresholdPasses uint64
+	// WarmingStart is when the cache server was last marked available after
+	// being unavailable, from which its slow start ramp-up is calculated. It
+	// is zero if the cache server is unavailable, or has been available since
+	// it was first polled.
+	WarmingStart time.Time
+	// CircuitBreaker is why the circuit breaker held the cache server
+	// available, although polling found it unavailable. It is empty if the
+	// cache server isn't held.
+	CircuitBreaker string
 }
 
 // CacheAvailableStatuses is the available status of each cache.
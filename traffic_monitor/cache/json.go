@@ -0,0 +1,101 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+
+	"github.com/json-iterator/go"
+)
+
+// JSONFormat is the format of cache servers whose stats are in arbitrary JSON, which is mapped to the stats Traffic Monitor requires by the `health.polling.stat.` Parameters of their Profiles.
+// Nested stats are named by the path to them, joined by dots, e.g. the name of `{"net": {"eth0": {"tx": 42}}}` is `net.eth0.tx`. Array elements are named by their index.
+const JSONFormat = "json"
+
+func init() {
+	// there are no defaults, because there are no conventional stat names; a cache must map at least its loadavg.
+	registerStatMappingDefaults(JSONFormat, map[string]string{})
+	registerDecoder(JSONFormat, jsonParse, jsonPrecompute)
+}
+
+func jsonParse(cacheName string, data io.Reader) (Statistics, map[string]interface{}, error) {
+	var stats Statistics
+	if data == nil {
+		log.Warnf("Cannot read stats data for cache '%s' - nil data reader", cacheName)
+		return stats, nil, errors.New("handler got nil reader")
+	}
+
+	mapping := getStatMapping(cacheName, nil)
+	if mapping == nil {
+		return stats, nil, errors.New("cache '" + cacheName + "' has no health.polling.stat Parameters mapping its stats")
+	}
+
+	var raw interface{}
+	json := jsoniter.ConfigFastest
+	if err := json.NewDecoder(data).Decode(&raw); err != nil {
+		return stats, nil, err
+	}
+
+	miscStats := map[string]interface{}{}
+	flattenJSONStats("", raw, miscStats)
+
+	stats, err := mapping.statistics(cacheName, miscStats)
+	if err != nil {
+		return stats, nil, err
+	}
+	return stats, miscStats, nil
+}
+
+// flattenJSONStats adds the primitive values of the given decoded JSON to the given stats, named by their path from the given prefix.
+func flattenJSONStats(prefix string, val interface{}, stats map[string]interface{}) {
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			flattenJSONStats(join(name), child, stats)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSONStats(join(strconv.Itoa(i)), child, stats)
+		}
+	case string, float64, bool:
+		if prefix != "" {
+			stats[prefix] = v
+		}
+	}
+}
+
+func jsonPrecompute(cacheName string, data todata.TOData, stats Statistics, miscStats map[string]interface{}) PrecomputedData {
+	mapping := getStatMapping(cacheName, nil)
+	if mapping == nil {
+		return PrecomputedData{DeliveryServiceStats: map[string]*DSStat{}}
+	}
+	return mapping.precompute(cacheName, data, stats, miscStats)
+}
@@ -0,0 +1,110 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+func TestJSONParse(t *testing.T) {
+	data := `{
+		"system": {"load": [0.25, 0.5, 0.75]},
+		"interfaces": {
+			"eth0": {"rx": 100, "tx": "200", "mbps": 1000},
+			"lo": {"rx": 5}
+		},
+		"deliveryServices": {
+			"demo1": {"bytesOut": 4096, "responses": {"2xx": 7, "4xx": 1}},
+			"demo2.mycdn.test": {"bytesOut": 10}
+		},
+		"version": "1.2.3"
+	}`
+
+	if _, _, err := jsonParse("edge", strings.NewReader(data)); err == nil {
+		t.Errorf("Expected parsing the stats of a cache with no stat mapping to fail, got no error")
+	}
+
+	mapping, err := NewStatMapping(JSONFormat, map[string]string{
+		MappedStatLoadAvg:           "system.load.0",
+		MappedStatInterfaceBytesIn:  "interfaces." + InterfacePlaceholder + ".rx",
+		MappedStatInterfaceBytesOut: "interfaces." + InterfacePlaceholder + ".tx",
+		MappedStatInterfaceSpeed:    "interfaces." + InterfacePlaceholder + ".mbps",
+		MappedStatDSOutBytes:        "deliveryServices." + DeliveryServicePlaceholder + ".bytesOut",
+		MappedStatDS2xx:             "deliveryServices." + DeliveryServicePlaceholder + ".responses.2xx",
+		MappedStatDS4xx:             "deliveryServices." + DeliveryServicePlaceholder + ".responses.4xx",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStatMappings(map[tc.CacheName]*StatMapping{"edge": mapping})
+	defer SetStatMappings(nil)
+
+	stats, misc, err := jsonParse("edge", strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Loadavg.One != 0.25 {
+		t.Errorf("Incorrect one-minute loadavg, expected 0.25, got %v", stats.Loadavg.One)
+	}
+	if iface := stats.Interfaces["eth0"]; iface.BytesIn != 100 || iface.BytesOut != 200 || iface.Speed != 1000 {
+		t.Errorf("Incorrect interface 'eth0', expected rx 100 tx 200 speed 1000, got %+v", iface)
+	}
+	if iface := stats.Interfaces["lo"]; len(stats.Interfaces) != 2 || iface.BytesIn != 5 {
+		t.Errorf("Expected interfaces 'eth0' and 'lo', with 'lo' rx 5, got %+v", stats.Interfaces)
+	}
+	if misc["version"] != "1.2.3" {
+		t.Errorf("Expected unmapped stat 'version' in miscellaneous stats, got %v", misc["version"])
+	}
+
+	toData := todata.New()
+	toData.DeliveryServiceTypes["demo1"] = tc.DSTypeCategoryHTTP
+	toData.DeliveryServiceTypes["demo2"] = tc.DSTypeCategoryHTTP
+	toData.DeliveryServiceRegexes.DirectMatches["demo2.mycdn.test"] = "demo2"
+	precomputed := jsonPrecompute("edge", *toData, stats, misc)
+	if ds := precomputed.DeliveryServiceStats["demo1"]; ds == nil || ds.OutBytes != 4096 || ds.Status2xx != 7 || ds.Status4xx != 1 {
+		t.Errorf("Incorrect stats of Delivery Service 'demo1' by XMLID, expected out 4096 2xx 7 4xx 1, got %+v", ds)
+	}
+	if ds := precomputed.DeliveryServiceStats["demo2"]; ds == nil || ds.OutBytes != 10 {
+		t.Errorf("Incorrect stats of Delivery Service 'demo2' by FQDN, expected out 10, got %+v", ds)
+	}
+	if precomputed.OutBytes != 200 {
+		t.Errorf("Incorrect out bytes, expected 200, got %v", precomputed.OutBytes)
+	}
+}
+
+func TestNewStatMappingErrors(t *testing.T) {
+	if m, err := NewStatMapping("astats", map[string]string{MappedStatLoadAvg: "load"}); m != nil || err != nil {
+		t.Errorf("Expected no stat mapping for a format with fixed stat names, got %+v %v", m, err)
+	}
+	for _, params := range []map[string]string{
+		{},
+		{MappedStatLoadAvg: "load", MappedStatInterfaceBytesOut: "net.tx"},
+		{MappedStatLoadAvg: "load", MappedStatDSOutBytes: "ds.{ds}.{ds}.out"},
+		{MappedStatLoadAvg: "load", MappedStatLoadAvg + StatMappingScaleSuffix: "half"},
+	} {
+		if _, err := NewStatMapping(JSONFormat, params); err == nil {
+			t.Errorf("Expected stat mapping %+v to be invalid, got no error", params)
+		}
+	}
+}
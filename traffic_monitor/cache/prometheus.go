@@ -0,0 +1,186 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+// PrometheusFormat is the format of cache servers exposing their stats in the Prometheus text exposition format, or OpenMetrics.
+const PrometheusFormat = "prometheus"
+
+// prometheusDefaultMapping maps the stats of Apache Traffic Server's stats_over_http Prometheus output, which are its stats_over_http stats, with dots replaced by underscores.
+// Its remap stats aren't mapped, because their Delivery Service FQDNs can't be recovered from their names, so caches must map Delivery Service stats with Parameters.
+var prometheusDefaultMapping *StatMapping
+
+func init() {
+	registerStatMappingDefaults(PrometheusFormat, map[string]string{
+		MappedStatLoadAvg:                          "plugin_system_stats_loadavg_one",
+		MappedStatLoadAvg + StatMappingScaleSuffix: strconv.FormatFloat(1.0/LOADAVG_SHIFT, 'g', -1, 64),
+		MappedStatInterfaceBytesIn:                 "plugin_system_stats_net_" + InterfacePlaceholder + "_rx_bytes",
+		MappedStatInterfaceBytesOut:                "plugin_system_stats_net_" + InterfacePlaceholder + "_tx_bytes",
+		MappedStatInterfaceSpeed:                   "plugin_system_stats_net_" + InterfacePlaceholder + "_speed",
+	})
+	mapping, err := NewStatMapping(PrometheusFormat, nil)
+	if err != nil {
+		panic("creating default " + PrometheusFormat + " stat mapping: " + err.Error())
+	}
+	prometheusDefaultMapping = mapping
+	registerDecoder(PrometheusFormat, prometheusParse, prometheusPrecompute)
+}
+
+func prometheusParse(cacheName string, data io.Reader) (Statistics, map[string]interface{}, error) {
+	var stats Statistics
+	if data == nil {
+		log.Warnf("Cannot read stats data for cache '%s' - nil data reader", cacheName)
+		return stats, nil, errors.New("handler got nil reader")
+	}
+
+	miscStats, err := parsePrometheusText(data)
+	if err != nil {
+		return stats, nil, err
+	}
+
+	stats, err = getStatMapping(cacheName, prometheusDefaultMapping).statistics(cacheName, miscStats)
+	if err != nil {
+		return stats, nil, err
+	}
+	return stats, miscStats, nil
+}
+
+func prometheusPrecompute(cacheName string, data todata.TOData, stats Statistics, miscStats map[string]interface{}) PrecomputedData {
+	return getStatMapping(cacheName, prometheusDefaultMapping).precompute(cacheName, data, stats, miscStats)
+}
+
+// parsePrometheusText parses the given Prometheus text exposition format, or OpenMetrics, into a map of each sample's name to its value.
+// The name of a sample with labels is its metric name followed by its labels in braces, sorted by name, e.g. `node_network_transmit_bytes_total{device="eth0"}`.
+// Samples whose value isn't finite are skipped, because stats must be serializable as JSON.
+func parsePrometheusText(data io.Reader) (map[string]interface{}, error) {
+	stats := map[string]interface{}{}
+	scanner := bufio.NewScanner(data)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, rest, err := parsePrometheusSampleName(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 1 {
+			return nil, fmt.Errorf("line %d: sample '%s' has no value", lineNum, name)
+		}
+		val, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: sample '%s' value '%s' is not a number", lineNum, name, fields[0])
+		}
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			continue
+		}
+		stats[name] = val
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("reading Prometheus data: " + err.Error())
+	}
+	return stats, nil
+}
+
+// parsePrometheusSampleName returns the name of the sample of the given line, with its labels sorted, and the rest of the line after the name and labels.
+func parsePrometheusSampleName(line string) (string, string, error) {
+	i := strings.IndexAny(line, "{ \t")
+	if i == -1 {
+		return "", "", errors.New("sample has no value")
+	}
+	metric := line[:i]
+	if metric == "" {
+		return "", "", errors.New("sample has no metric name")
+	}
+	if line[i] != '{' {
+		return metric, line[i:], nil
+	}
+
+	labels := []string{}
+	rest := line[i+1:]
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if strings.HasPrefix(rest, "}") {
+			rest = rest[1:]
+			break
+		}
+		eq := strings.Index(rest, "=")
+		if eq == -1 {
+			return "", "", errors.New("malformed labels of metric " + metric)
+		}
+		labelName := strings.TrimSpace(rest[:eq])
+		rest = strings.TrimLeft(rest[eq+1:], " \t")
+		if !strings.HasPrefix(rest, `"`) {
+			return "", "", errors.New("unquoted value of label " + labelName + " of metric " + metric)
+		}
+		labelVal, afterVal, err := parsePrometheusLabelValue(rest[1:])
+		if err != nil {
+			return "", "", errors.New("label " + labelName + " of metric " + metric + ": " + err.Error())
+		}
+		labels = append(labels, labelName+`="`+labelVal+`"`)
+		rest = afterVal
+	}
+
+	if len(labels) == 0 {
+		return metric, rest, nil
+	}
+	sort.Strings(labels)
+	return metric + "{" + strings.Join(labels, ",") + "}", rest, nil
+}
+
+// parsePrometheusLabelValue returns the unescaped label value at the beginning of the given string, which is after the value's opening quote, and the rest of the string after its closing quote.
+func parsePrometheusLabelValue(s string) (string, string, error) {
+	val := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return val.String(), s[i+1:], nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", errors.New("unterminated escape")
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				val.WriteByte('\n')
+			default:
+				val.WriteByte(s[i])
+			}
+		default:
+			val.WriteByte(s[i])
+		}
+	}
+	return "", "", errors.New("unterminated value")
+}
@@ -0,0 +1,127 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+const testPrometheusData = `# HELP plugin_system_stats_loadavg_one One-minute loadavg
+# TYPE plugin_system_stats_loadavg_one gauge
+plugin_system_stats_loadavg_one 6080
+plugin_system_stats_net_eth0_rx_bytes 4363732
+plugin_system_stats_net_eth0_tx_bytes 237634637 1591023845000
+plugin_system_stats_net_eth0_speed 10000
+remap_out_bytes_total{status="ok", remap="demo1.mycdn.test"} 1024
+remap_responses_total{class="2xx",remap="demo1.mycdn.test"} 12
+remap_responses_total{remap="demo1.mycdn.test",class="5xx"} 3
+proxy_process_cache_ratio NaN
+# EOF
+`
+
+func TestPrometheusParse(t *testing.T) {
+	stats, misc, err := prometheusParse("test", strings.NewReader(testPrometheusData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Loadavg.One <= 0.092773437 || stats.Loadavg.One >= 0.092773439 {
+		t.Errorf("Incorrect one-minute loadavg, expected roughly 0.092773438, got '%.10f'", stats.Loadavg.One)
+	}
+	iface, ok := stats.Interfaces["eth0"]
+	if len(stats.Interfaces) != 1 || !ok {
+		t.Fatalf("Expected exactly the 'eth0' interface, got %+v", stats.Interfaces)
+	}
+	if iface.Speed != 10000 || iface.BytesIn != 4363732 || iface.BytesOut != 237634637 {
+		t.Errorf("Incorrect interface, expected speed 10000 rx 4363732 tx 237634637, got %+v", iface)
+	}
+
+	if val := misc[`remap_responses_total{class="5xx",remap="demo1.mycdn.test"}`]; val != float64(3) {
+		t.Errorf("Expected labelled sample to be named with its labels sorted, with value 3, got %v", val)
+	}
+	if _, ok := misc["proxy_process_cache_ratio"]; ok {
+		t.Errorf("Expected NaN sample to be skipped")
+	}
+}
+
+func TestPrometheusParseMalformed(t *testing.T) {
+	for _, data := range []string{
+		"plugin_system_stats_loadavg_one",
+		"plugin_system_stats_loadavg_one abc",
+		`remap_out_bytes_total{remap="demo1.mycdn.test} 1`,
+		`remap_out_bytes_total{remap=demo1} 1`,
+	} {
+		if _, err := parsePrometheusText(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected parsing '%s' to fail, got no error", data)
+		}
+	}
+}
+
+func TestPrometheusStatMapping(t *testing.T) {
+	mapping, err := NewStatMapping(PrometheusFormat, map[string]string{
+		MappedStatLoadAvg:                                 "node_load1",
+		MappedStatInterfaceBytesIn:                        `node_network_receive_bytes_total{device="` + InterfacePlaceholder + `"}`,
+		MappedStatInterfaceBytesOut:                       `node_network_transmit_bytes_total{device="` + InterfacePlaceholder + `"}`,
+		MappedStatInterfaceSpeed:                          `node_network_speed_bytes{device="` + InterfacePlaceholder + `"}`,
+		MappedStatInterfaceSpeed + StatMappingScaleSuffix: "0.000008",
+		MappedStatDSOutBytes:                              `remap_out_bytes_total{remap="` + DeliveryServicePlaceholder + `",status="ok"}`,
+		MappedStatDS2xx:                                   `remap_responses_total{class="2xx",remap="` + DeliveryServicePlaceholder + `"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStatMappings(map[tc.CacheName]*StatMapping{"edge": mapping})
+	defer SetStatMappings(nil)
+
+	data := testPrometheusData + `node_load1 0.5
+node_network_receive_bytes_total{device="eth1"} 2048
+node_network_transmit_bytes_total{device="eth1"} 4096
+node_network_speed_bytes{device="eth1"} 1250000000
+`
+	stats, misc, err := prometheusParse("edge", strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Loadavg.One != 0.5 {
+		t.Errorf("Incorrect one-minute loadavg, expected 0.5, got %v", stats.Loadavg.One)
+	}
+	if iface := stats.Interfaces["eth1"]; len(stats.Interfaces) != 1 || iface.BytesIn != 2048 || iface.BytesOut != 4096 || iface.Speed != 10000 {
+		t.Errorf("Expected only interface 'eth1' with rx 2048 tx 4096 and speed 10000, got %+v", stats.Interfaces)
+	}
+
+	toData := todata.New()
+	toData.DeliveryServiceTypes["demo1"] = tc.DSTypeCategoryHTTP
+	toData.DeliveryServiceRegexes.DirectMatches["demo1.mycdn.test"] = "demo1"
+	precomputed := prometheusPrecompute("edge", *toData, stats, misc)
+	dsStat, ok := precomputed.DeliveryServiceStats["demo1"]
+	if !ok {
+		t.Fatalf("Expected stats of Delivery Service 'demo1', got %+v", precomputed.DeliveryServiceStats)
+	}
+	if dsStat.OutBytes != 1024 || dsStat.Status2xx != 12 || dsStat.Status5xx != 0 {
+		t.Errorf("Incorrect Delivery Service stats, expected out 1024 2xx 12 5xx 0, got %+v", *dsStat)
+	}
+	if precomputed.MaxKbps != 10000000 {
+		t.Errorf("Incorrect max kbps, expected 10000000, got %v", precomputed.MaxKbps)
+	}
+}
@@ -0,0 +1,351 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+// The stats Traffic Monitor requires, which a StatMapping maps from the stats of a cache server's payload.
+// They're the names of the mapping Parameters, after the `health.polling.stat.` prefix.
+const (
+	MappedStatLoadAvg           = "loadavg"
+	MappedStatInterfaceBytesIn  = "interface.bytes_in"
+	MappedStatInterfaceBytesOut = "interface.bytes_out"
+	MappedStatInterfaceSpeed    = "interface.speed"
+	MappedStatDSInBytes         = "ds.in_bytes"
+	MappedStatDSOutBytes        = "ds.out_bytes"
+	MappedStatDS2xx             = "ds.status_2xx"
+	MappedStatDS3xx             = "ds.status_3xx"
+	MappedStatDS4xx             = "ds.status_4xx"
+	MappedStatDS5xx             = "ds.status_5xx"
+)
+
+// InterfacePlaceholder is the part of the payload stat names of the interface stats which is the name of the interface.
+const InterfacePlaceholder = "{interface}"
+
+// DeliveryServicePlaceholder is the part of the payload stat names of the Delivery Service stats which is the Delivery Service's XMLID, or the FQDN of one of its remap rules.
+const DeliveryServicePlaceholder = "{ds}"
+
+// StatMappingScaleSuffix is the suffix of the mapping Parameter of the number every value of the stat is multiplied by, to convert it to Traffic Monitor's unit. For example, `interface.speed.scale` of 0.000008 converts an interface speed in bytes per second to megabits per second.
+const StatMappingScaleSuffix = ".scale"
+
+var mappedStatPlaceholders = map[string]string{
+	MappedStatLoadAvg:           "",
+	MappedStatInterfaceBytesIn:  InterfacePlaceholder,
+	MappedStatInterfaceBytesOut: InterfacePlaceholder,
+	MappedStatInterfaceSpeed:    InterfacePlaceholder,
+	MappedStatDSInBytes:         DeliveryServicePlaceholder,
+	MappedStatDSOutBytes:        DeliveryServicePlaceholder,
+	MappedStatDS2xx:             DeliveryServicePlaceholder,
+	MappedStatDS3xx:             DeliveryServicePlaceholder,
+	MappedStatDS4xx:             DeliveryServicePlaceholder,
+	MappedStatDS5xx:             DeliveryServicePlaceholder,
+}
+
+// statTemplate is the name of a payload stat, which may contain a placeholder, whose value is captured when matching a name.
+type statTemplate struct {
+	prefix         string
+	suffix         string
+	hasPlaceholder bool
+}
+
+func parseStatTemplate(template string, placeholder string) (statTemplate, error) {
+	if placeholder == "" {
+		return statTemplate{prefix: template}, nil
+	}
+	i := strings.Index(template, placeholder)
+	if i == -1 {
+		return statTemplate{}, errors.New("must contain " + placeholder)
+	}
+	t := statTemplate{prefix: template[:i], suffix: template[i+len(placeholder):], hasPlaceholder: true}
+	if strings.Contains(t.suffix, placeholder) {
+		return statTemplate{}, errors.New("must contain " + placeholder + " only once")
+	}
+	return t, nil
+}
+
+// match returns the value of the placeholder in the given stat name, and whether the name matches the template.
+func (t statTemplate) match(name string) (string, bool) {
+	if !t.hasPlaceholder {
+		return "", name == t.prefix
+	}
+	if len(name) <= len(t.prefix)+len(t.suffix) || !strings.HasPrefix(name, t.prefix) || !strings.HasSuffix(name, t.suffix) {
+		return "", false
+	}
+	return name[len(t.prefix) : len(name)-len(t.suffix)], true
+}
+
+// StatMapping maps the stats of a cache server's payload, in a format without fixed stat names, to the stats Traffic Monitor requires.
+type StatMapping struct {
+	templates map[string]statTemplate
+	scales    map[string]float64
+}
+
+var statMappingDefaults = map[string]map[string]string{}
+
+// registerStatMappingDefaults registers the given format as one whose stats are mapped with a StatMapping, with the given default payload stat names of each mapped stat. It MUST only be called from an init func.
+func registerStatMappingDefaults(format string, defaults map[string]string) {
+	statMappingDefaults[format] = defaults
+}
+
+// NewStatMapping returns the StatMapping of the given format, from the given mapping Parameters, which override the format's defaults of the stats they map.
+// If the format's stats aren't mapped, nil is returned.
+func NewStatMapping(format string, params map[string]string) (*StatMapping, error) {
+	defaults, ok := statMappingDefaults[format]
+	if !ok {
+		return nil, nil
+	}
+	m := &StatMapping{templates: map[string]statTemplate{}, scales: map[string]float64{}}
+	for stat, placeholder := range mappedStatPlaceholders {
+		// a default scale is for the default stat, so it doesn't apply to a stat mapped by a Parameter.
+		src := defaults
+		if _, ok := params[stat]; ok {
+			src = params
+		}
+		if template, ok := src[stat]; ok && template != "" {
+			t, err := parseStatTemplate(template, placeholder)
+			if err != nil {
+				return nil, fmt.Errorf("stat %s '%s': %v", stat, template, err)
+			}
+			m.templates[stat] = t
+		}
+
+		scale, ok := params[stat+StatMappingScaleSuffix]
+		if !ok {
+			scale, ok = src[stat+StatMappingScaleSuffix]
+		}
+		if ok {
+			val, err := strconv.ParseFloat(scale, 64)
+			if err != nil {
+				return nil, fmt.Errorf("stat %s scale '%s' is not a number", stat, scale)
+			}
+			m.scales[stat] = val
+		}
+	}
+	if _, ok := m.templates[MappedStatLoadAvg]; !ok {
+		return nil, errors.New("no stat is mapped to " + MappedStatLoadAvg)
+	}
+	return m, nil
+}
+
+// value returns the given payload stat value of the given mapped stat, as a number in Traffic Monitor's unit.
+func (m StatMapping) value(stat string, val interface{}) (float64, error) {
+	f := float64(0)
+	switch v := val.(type) {
+	case float64:
+		f = v
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse '%s' as a number", v)
+		}
+		f = parsed
+	case bool:
+		if v {
+			f = 1
+		}
+	default:
+		return 0, fmt.Errorf("value '%v' is of unrecognized type %T", val, val)
+	}
+	if scale, ok := m.scales[stat]; ok {
+		f *= scale
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("value %v is not a finite number", f)
+	}
+	return f, nil
+}
+
+func (m StatMapping) uintValue(stat string, val interface{}) (uint64, error) {
+	f, err := m.value(stat, val)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 || f > math.MaxUint64 {
+		return 0, fmt.Errorf("value %v out of range for uint64", f)
+	}
+	return uint64(f), nil
+}
+
+// statistics returns the Statistics of the given payload stats.
+func (m StatMapping) statistics(cacheName string, stats map[string]interface{}) (Statistics, error) {
+	statistics := Statistics{Interfaces: map[string]Interface{}}
+
+	loadAvgName := m.templates[MappedStatLoadAvg].prefix
+	loadAvg, ok := stats[loadAvgName]
+	if !ok {
+		return statistics, errors.New("data was missing '" + loadAvgName + "'")
+	}
+	one, err := m.value(MappedStatLoadAvg, loadAvg)
+	if err != nil {
+		return statistics, fmt.Errorf("parsing loadavg '%s': %v", loadAvgName, err)
+	}
+	statistics.Loadavg.One = one
+
+	ifaceTemplates := map[string]statTemplate{}
+	for _, stat := range []string{MappedStatInterfaceBytesIn, MappedStatInterfaceBytesOut, MappedStatInterfaceSpeed} {
+		if t, ok := m.templates[stat]; ok {
+			ifaceTemplates[stat] = t
+		}
+	}
+	for name, val := range stats {
+		for stat, t := range ifaceTemplates {
+			ifaceName, ok := t.match(name)
+			if !ok {
+				continue
+			}
+			f, err := m.value(stat, val)
+			if err != nil {
+				log.Warnf("cache '%s' interface '%s' stat '%s': %v", cacheName, ifaceName, name, err)
+				continue
+			}
+			if f < 0 || f > math.MaxInt64 {
+				log.Warnf("cache '%s' interface '%s' stat '%s' out of range: %v", cacheName, ifaceName, name, f)
+				continue
+			}
+			iface := statistics.Interfaces[ifaceName]
+			switch stat {
+			case MappedStatInterfaceBytesIn:
+				iface.BytesIn = uint64(f)
+			case MappedStatInterfaceBytesOut:
+				iface.BytesOut = uint64(f)
+			case MappedStatInterfaceSpeed:
+				iface.Speed = int64(f)
+			}
+			statistics.Interfaces[ifaceName] = iface
+		}
+	}
+	if len(statistics.Interfaces) < 1 {
+		return statistics, fmt.Errorf("cache '%s' had no interfaces", cacheName)
+	}
+	return statistics, nil
+}
+
+// deliveryService returns the Delivery Service of the given placeholder value, which may be its XMLID, or the FQDN of one of its remap rules.
+func deliveryService(name string, toData todata.TOData) (tc.DeliveryServiceName, bool) {
+	if _, ok := toData.DeliveryServiceTypes[tc.DeliveryServiceName(name)]; ok {
+		return tc.DeliveryServiceName(name), true
+	}
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) < 3 {
+		return "", false
+	}
+	return toData.DeliveryServiceRegexes.DeliveryService(parts[2], parts[1], parts[0])
+}
+
+// precompute returns the PrecomputedData of the given Statistics and payload stats.
+func (m StatMapping) precompute(cacheName string, toData todata.TOData, stats Statistics, miscStats map[string]interface{}) PrecomputedData {
+	precomputed := PrecomputedData{DeliveryServiceStats: map[string]*DSStat{}}
+	for _, iface := range stats.Interfaces {
+		precomputed.OutBytes += iface.BytesOut
+		if iface.Speed > precomputed.MaxKbps {
+			precomputed.MaxKbps = iface.Speed
+		}
+	}
+	precomputed.MaxKbps *= 1000
+
+	dsTemplates := map[string]statTemplate{}
+	for _, stat := range []string{MappedStatDSInBytes, MappedStatDSOutBytes, MappedStatDS2xx, MappedStatDS3xx, MappedStatDS4xx, MappedStatDS5xx} {
+		if t, ok := m.templates[stat]; ok {
+			dsTemplates[stat] = t
+		}
+	}
+	for name, val := range miscStats {
+		for stat, t := range dsTemplates {
+			dsStr, ok := t.match(name)
+			if !ok {
+				continue
+			}
+			ds, ok := deliveryService(dsStr, toData)
+			if !ok || ds == "" {
+				err := errors.New("no Delivery Service match for stat")
+				log.Infof("precomputing cache %s stat %s value %v error %v", cacheName, name, val, err)
+				precomputed.Errors = append(precomputed.Errors, err)
+				continue
+			}
+			parsed, err := m.uintValue(stat, val)
+			if err != nil {
+				err = fmt.Errorf("couldn't parse numeric stat: %v", err)
+				log.Infof("precomputing cache %s stat %s value %v error %v", cacheName, name, val, err)
+				precomputed.Errors = append(precomputed.Errors, err)
+				continue
+			}
+
+			dsStat := precomputed.DeliveryServiceStats[string(ds)]
+			if dsStat == nil {
+				dsStat = &DSStat{}
+				precomputed.DeliveryServiceStats[string(ds)] = dsStat
+			}
+			// a Delivery Service with multiple remap rules has stats for each, which are summed.
+			switch stat {
+			case MappedStatDSInBytes:
+				dsStat.InBytes += parsed
+			case MappedStatDSOutBytes:
+				dsStat.OutBytes += parsed
+			case MappedStatDS2xx:
+				dsStat.Status2xx += parsed
+			case MappedStatDS3xx:
+				dsStat.Status3xx += parsed
+			case MappedStatDS4xx:
+				dsStat.Status4xx += parsed
+			case MappedStatDS5xx:
+				dsStat.Status5xx += parsed
+			}
+		}
+	}
+	return precomputed
+}
+
+// statMappings is the StatMapping of each cache server whose Profile has mapping Parameters.
+var statMappings = struct {
+	m        sync.RWMutex
+	mappings map[string]*StatMapping
+}{mappings: map[string]*StatMapping{}}
+
+// SetStatMappings sets the StatMapping of each cache server, replacing all previously set. Cache servers without one use the defaults of their format.
+func SetStatMappings(mappings map[tc.CacheName]*StatMapping) {
+	newMappings := make(map[string]*StatMapping, len(mappings))
+	for cacheName, mapping := range mappings {
+		newMappings[string(cacheName)] = mapping
+	}
+	statMappings.m.Lock()
+	statMappings.mappings = newMappings
+	statMappings.m.Unlock()
+}
+
+// getStatMapping returns the StatMapping of the given cache server, or the given default if it has none.
+func getStatMapping(cacheName string, defaultMapping *StatMapping) *StatMapping {
+	statMappings.m.RLock()
+	defer statMappings.m.RUnlock()
+	if mapping, ok := statMappings.mappings[cacheName]; ok && mapping != nil {
+		return mapping
+	}
+	return defaultMapping
+}
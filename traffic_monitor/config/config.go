@@ -102,6 +102,7 @@ type Config struct {
 	PeerPollingInterval          time.Duration   `json:"-"`
 	PeerOptimistic               bool            `json:"peer_optimistic"`
 	PeerOptimisticQuorumMin      int             `json:"peer_optimistic_quorum_min"`
+	PeerOutlierThreshold         float64         `json:"peer_outlier_threshold"`
 	MaxEvents                    uint64          `json:"max_events"`
 	MaxStatHistory               uint64          `json:"max_stat_history"`
 	MaxHealthHistory             uint64          `json:"max_health_history"`
@@ -125,6 +126,37 @@ type Config struct {
 	TrafficOpsDiskRetryMax       uint64          `json:"-"`
 	CachePollingProtocol         PollingProtocol `json:"cache_polling_protocol"`
 	PeerPollingProtocol          PollingProtocol `json:"peer_polling_protocol"`
+	HistoryStoreDir              string          `json:"history_store_dir"`
+	HistoryStoreRetention        time.Duration   `json:"-"`
+	HistoryStoreSegment          time.Duration   `json:"-"`
+	AlertInterval                time.Duration   `json:"-"`
+	AlertRules                   []AlertRule     `json:"alert_rules"`
+	AlertNotifiers               []AlertNotifier `json:"alert_notifiers"`
+	StreamDSStatsInterval        time.Duration   `json:"-"`
+}
+
+// AlertRule is a rule of when to send alerts. Which fields are used depends on its Type; see the alert package.
+type AlertRule struct {
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	DurationMs      uint64  `json:"duration_ms"`
+	Count           int     `json:"count"`
+	Kbps            float64 `json:"kbps"`
+	CacheGroup      string  `json:"cachegroup"`
+	DeliveryService string  `json:"deliveryservice"`
+}
+
+// AlertNotifier is a destination alerts are sent to. Which fields are used depends on its Type; see the alert package.
+type AlertNotifier struct {
+	Type     string   `json:"type"`
+	URL      string   `json:"url"`
+	Network  string   `json:"network"`
+	Address  string   `json:"address"`
+	Tag      string   `json:"tag"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	User     string   `json:"user"`
+	Password string   `json:"password"`
 }
 
 func (c Config) ErrorLog() log.LogLocation   { return log.LogLocation(c.LogLocationError) }
@@ -142,6 +174,7 @@ var DefaultConfig = Config{
 	PeerPollingInterval:          5 * time.Second,
 	PeerOptimistic:               true,
 	PeerOptimisticQuorumMin:      0,
+	PeerOutlierThreshold:         0,
 	MaxEvents:                    200,
 	MaxStatHistory:               5,
 	MaxHealthHistory:             5,
@@ -165,6 +198,11 @@ var DefaultConfig = Config{
 	TrafficOpsDiskRetryMax:       2,
 	CachePollingProtocol:         Both,
 	PeerPollingProtocol:          Both,
+	HistoryStoreDir:              "",
+	HistoryStoreRetention:        time.Hour,
+	HistoryStoreSegment:          5 * time.Minute,
+	AlertInterval:                10 * time.Second,
+	StreamDSStatsInterval:        5 * time.Second,
 }
 
 // MarshalJSON marshals custom millisecond durations. Aliasing inspired by http://choly.ca/post/go-json-marshalling/
@@ -184,6 +222,10 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		StatBufferIntervalMs           uint64 `json:"stat_buffer_interval_ms"`
 		ServeReadTimeoutMs             uint64 `json:"serve_read_timeout_ms"`
 		ServeWriteTimeoutMs            uint64 `json:"serve_write_timeout_ms"`
+		HistoryStoreRetentionMs        uint64 `json:"history_store_retention_ms"`
+		HistoryStoreSegmentMs          uint64 `json:"history_store_segment_ms"`
+		AlertIntervalMs                uint64 `json:"alert_interval_ms"`
+		StreamDSStatsIntervalMs        uint64 `json:"stream_ds_stats_interval_ms"`
 		*Alias
 	}{
 		CacheHealthPollingIntervalMs:   uint64(c.CacheHealthPollingInterval / time.Millisecond),
@@ -196,6 +238,10 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		HealthFlushIntervalMs:          uint64(c.HealthFlushInterval / time.Millisecond),
 		StatFlushIntervalMs:            uint64(c.StatFlushInterval / time.Millisecond),
 		StatBufferIntervalMs:           uint64(c.StatBufferInterval / time.Millisecond),
+		HistoryStoreRetentionMs:        uint64(c.HistoryStoreRetention / time.Millisecond),
+		HistoryStoreSegmentMs:          uint64(c.HistoryStoreSegment / time.Millisecond),
+		AlertIntervalMs:                uint64(c.AlertInterval / time.Millisecond),
+		StreamDSStatsIntervalMs:        uint64(c.StreamDSStatsInterval / time.Millisecond),
 		Alias:                          (*Alias)(c),
 	})
 }
@@ -221,6 +267,10 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 		TrafficOpsDiskRetryMax         *uint64 `json:"traffic_ops_disk_retry_max"`
 		CRConfigBackupFile             *string `json:"crconfig_backup_file"`
 		TMConfigBackupFile             *string `json:"tmconfig_backup_file"`
+		HistoryStoreRetentionMs        *uint64 `json:"history_store_retention_ms"`
+		HistoryStoreSegmentMs          *uint64 `json:"history_store_segment_ms"`
+		AlertIntervalMs                *uint64 `json:"alert_interval_ms"`
+		StreamDSStatsIntervalMs        *uint64 `json:"stream_ds_stats_interval_ms"`
 		*Alias
 	}{
 		Alias: (*Alias)(c),
@@ -281,6 +331,18 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	if aux.TMConfigBackupFile != nil {
 		c.TMConfigBackupFile = *aux.TMConfigBackupFile
 	}
+	if aux.HistoryStoreRetentionMs != nil {
+		c.HistoryStoreRetention = time.Duration(*aux.HistoryStoreRetentionMs) * time.Millisecond
+	}
+	if aux.HistoryStoreSegmentMs != nil {
+		c.HistoryStoreSegment = time.Duration(*aux.HistoryStoreSegmentMs) * time.Millisecond
+	}
+	if aux.AlertIntervalMs != nil {
+		c.AlertInterval = time.Duration(*aux.AlertIntervalMs) * time.Millisecond
+	}
+	if aux.StreamDSStatsIntervalMs != nil {
+		c.StreamDSStatsInterval = time.Duration(*aux.StreamDSStatsIntervalMs) * time.Millisecond
+	}
 	return nil
 }
 
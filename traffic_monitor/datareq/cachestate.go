@@ -59,6 +59,8 @@ type CacheStatus struct {
 	IPv4Available         *bool    `json:"ipv4_available,omitempty"`
 	IPv6Available         *bool    `json:"ipv6_available,omitempty"`
 	CombinedAvailable     *bool    `json:"combined_available,omitempty"`
+	// CircuitBreaker is why the circuit breaker is holding the cache available, although polling found it unavailable.
+	CircuitBreaker *string `json:"circuit_breaker,omitempty"`
 
 	Interfaces *map[string]CacheInterfaceStatus `json:"interfaces,omitempty"`
 }
@@ -215,6 +217,11 @@ func createCacheStatuses(
 			CombinedAvailable:      &combinedStatus,
 			Interfaces:             &interfaceStatus,
 		}
+		if why := localCacheStatus[cacheName][tc.CacheInterfacesAggregate].CircuitBreaker; why != "" {
+			status := statii[cacheName]
+			status.CircuitBreaker = &why
+			statii[cacheName] = status
+		}
 	}
 	return statii
 }
@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package datareq
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/persist"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+
+	"github.com/json-iterator/go"
+)
+
+// CacheStatHistory is the response of the cache stat history endpoint: the values of a stat of a cache, newest first.
+type CacheStatHistory struct {
+	Cache  tc.CacheName          `json:"cache"`
+	Stat   string                `json:"stat"`
+	Values []cache.ResultStatVal `json:"values"`
+}
+
+// srvAPICacheStatHistory serves the values of the stat and cache in the given params, polled in the time range of the params. They're from the history store, if there is one, and otherwise from the in-memory stat history, which only has the newest values.
+func srvAPICacheStatHistory(params url.Values, errorCount threadsafe.Uint, path string, statResultHistory threadsafe.ResultStatHistory, historyStore *persist.Store) ([]byte, int) {
+	cacheName := tc.CacheName(params.Get("cache"))
+	stat := params.Get("stat")
+	if cacheName == "" || stat == "" {
+		err := errors.New("missing required parameters 'cache' and 'stat'")
+		HandleErr(errorCount, path, err)
+		return []byte(err.Error()), http.StatusBadRequest
+	}
+	since, err := parseTimeParam(params, "since", time.Time{})
+	if err != nil {
+		HandleErr(errorCount, path, err)
+		return []byte(err.Error()), http.StatusBadRequest
+	}
+	until, err := parseTimeParam(params, "until", time.Now())
+	if err != nil {
+		HandleErr(errorCount, path, err)
+		return []byte(err.Error()), http.StatusBadRequest
+	}
+
+	vals := []cache.ResultStatVal{}
+	if historyStore != nil {
+		if vals, err = historyStore.QueryStat(cacheName, stat, since, until); err != nil {
+			return WrapErrCode(errorCount, path, nil, errors.New("querying history store: "+err.Error()))
+		}
+	} else {
+		statResultHistory.Range(func(name tc.CacheName, interfaceName string, history threadsafe.ResultStatValHistory) bool {
+			if name != cacheName || interfaceName != tc.CacheInterfacesAggregate {
+				return true
+			}
+			for _, val := range history.Load(stat) {
+				if !val.Time.Before(since) && !val.Time.After(until) {
+					vals = append(vals, val)
+				}
+			}
+			return false
+		})
+	}
+
+	json := jsoniter.ConfigFastest
+	bytes, err := json.Marshal(CacheStatHistory{Cache: cacheName, Stat: stat, Values: vals})
+	return WrapErrCode(errorCount, path, bytes, err)
+}
+
+// parseTimeParam returns the time of the given param, which may be RFC3339 or Unix seconds, or the given default if the param is missing.
+func parseTimeParam(params url.Values, name string, defaultTime time.Time) (time.Time, error) {
+	val := params.Get(name)
+	if val == "" {
+		return defaultTime, nil
+	}
+	if secs, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}, errors.New("parameter '" + name + "' must be an RFC3339 time or Unix seconds")
+	}
+	return t, nil
+}
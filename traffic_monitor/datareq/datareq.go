@@ -33,6 +33,9 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/persist"
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -62,6 +65,12 @@ func MakeDispatchMap(
 	lastStats threadsafe.LastStats,
 	unpolledCaches threadsafe.UnpolledCaches,
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
+	syntheticResults synthetic.ResultsThreadsafe,
+	historyStore *persist.Store,
+	peerConsensus peer.ConsensusThreadsafe,
+	crStatesStream *stream.Stream,
+	dsStatsStream *stream.Stream,
+	serveWriteTimeout time.Duration,
 ) map[string]http.HandlerFunc {
 
 	// wrap composes all universal wrapper functions. Right now, it's only the UnpolledCheck, but there may be others later. For example, security headers.
@@ -128,6 +137,20 @@ func MakeDispatchMap(
 		"/api/crconfig-history": wrap(WrapErr(errorCount, func() ([]byte, error) {
 			return srvAPICRConfigHist(toSession)
 		}, ContentTypeJSON)),
+		"/api/synthetic-checks": wrap(WrapErr(errorCount, func() ([]byte, error) {
+			return srvAPISyntheticChecks(syntheticResults)
+		}, ContentTypeJSON)),
+		"/api/cache-stat-history": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
+			return srvAPICacheStatHistory(params, errorCount, path, statResultHistory, historyStore)
+		}, ContentTypeJSON)),
+		"/api/peer-consensus": wrap(WrapErr(errorCount, func() ([]byte, error) {
+			return srvAPIPeerConsensus(peerConsensus)
+		}, ContentTypeJSON)),
+		"/api/crstates-stream": wrap(srvCRStatesStream(errorCount, crStatesStream, combinedStates, StreamMaxDuration(serveWriteTimeout))),
+		"/api/ds-stats-stream": wrap(srvDSStatsStream(errorCount, dsStatsStream, StreamMaxDuration(serveWriteTimeout))),
+		"/metrics": wrap(WrapBytes(func() []byte {
+			return srvMetrics(staticAppData, healthPollInterval, lastHealthDurations, fetchCount, healthIteration, errorCount, toData, statResultHistory, dsStats, combinedStates, monitorConfig)
+		}, ContentTypePrometheus)),
 	}
 	return addTrailingSlashEndpoints(dispatchMap)
 }
@@ -0,0 +1,415 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package datareq
+
+import (
+	"bytes"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/dsdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+// ContentTypePrometheus is the content type of the Prometheus text exposition format.
+const ContentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsPrefix is the prefix of the name of every metric served by the /metrics endpoint.
+const MetricsPrefix = "traffic_monitor_"
+
+const (
+	metricTypeGauge   = "gauge"
+	metricTypeCounter = "counter"
+)
+
+type metricLabel struct {
+	Name  string
+	Value string
+}
+
+type metricSample struct {
+	Labels []metricLabel
+	Value  float64
+}
+
+// metricFamily is a single Prometheus metric, and all its samples. The exposition format requires all samples of a metric to be grouped together, after its HELP and TYPE lines.
+type metricFamily struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []metricSample
+}
+
+func (m *metricFamily) add(value float64, labels ...metricLabel) {
+	m.Samples = append(m.Samples, metricSample{Labels: labels, Value: value})
+}
+
+// metricFamilies is an ordered collection of metric families.
+type metricFamilies struct {
+	names    []string
+	families map[string]*metricFamily
+}
+
+func newMetricFamilies() *metricFamilies {
+	return &metricFamilies{families: map[string]*metricFamily{}}
+}
+
+// get returns the family with the given name, creating it if it doesn't exist. The help and type of an existing family are not changed.
+func (fs *metricFamilies) get(name string, help string, metricType string) *metricFamily {
+	if f, ok := fs.families[name]; ok {
+		return f
+	}
+	f := &metricFamily{Name: name, Help: help, Type: metricType}
+	fs.families[name] = f
+	fs.names = append(fs.names, name)
+	return f
+}
+
+// write writes all families in the Prometheus text exposition format. Families are written in the order they were created.
+func (fs *metricFamilies) write(buf *bytes.Buffer) {
+	for _, name := range fs.names {
+		f := fs.families[name]
+		if len(f.Samples) == 0 {
+			continue
+		}
+		buf.WriteString("# HELP " + f.Name + " " + escapeMetricHelp(f.Help) + "\n")
+		buf.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		for _, sample := range f.Samples {
+			buf.WriteString(f.Name)
+			if len(sample.Labels) > 0 {
+				buf.WriteString("{")
+				for i, label := range sample.Labels {
+					if i > 0 {
+						buf.WriteString(",")
+					}
+					buf.WriteString(label.Name + `="` + escapeMetricLabelValue(label.Value) + `"`)
+				}
+				buf.WriteString("}")
+			}
+			buf.WriteString(" " + formatMetricValue(sample.Value) + "\n")
+		}
+	}
+}
+
+var metricHelpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var metricLabelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeMetricHelp(s string) string       { return metricHelpEscaper.Replace(s) }
+func escapeMetricLabelValue(s string) string { return metricLabelValueEscaper.Replace(s) }
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// healthThresholdStats returns the names of the stats with a health threshold in any profile of the monitor config. These are the only polled cache stats served as metrics, so the number of samples doesn't grow with every stat a cache reports.
+func healthThresholdStats(monitorConfig tc.LegacyTrafficMonitorConfigMap) map[string]struct{} {
+	stats := map[string]struct{}{}
+	for _, profile := range monitorConfig.Profile {
+		for stat := range profile.Parameters.Thresholds {
+			stats[stat] = struct{}{}
+		}
+	}
+	return stats
+}
+
+// metricStatValue returns the numeric value of a polled cache stat. Strings are parsed as numbers if possible, and booleans are 1 or 0. Any other value returns false.
+func metricStatValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case bool:
+		return boolMetricValue(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func boolMetricValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func srvMetrics(
+	staticAppData config.StaticAppData,
+	healthPollInterval time.Duration,
+	lastHealthDurations threadsafe.DurationMap,
+	fetchCount threadsafe.Uint,
+	healthIteration threadsafe.Uint,
+	errorCount threadsafe.Uint,
+	toData todata.TODataThreadsafe,
+	statResultHistory threadsafe.ResultStatHistory,
+	dsStats threadsafe.DSStatsReader,
+	combinedStates peer.CRStatesThreadsafe,
+	monitorConfig threadsafe.TrafficMonitorConfigMap,
+) []byte {
+	return getMetrics(staticAppData, healthPollInterval, lastHealthDurations.Get(), fetchCount.Get(), healthIteration.Get(), errorCount.Get(), toData.Get(), statResultHistory, dsStats.Get(), combinedStates.Get(), monitorConfig.Get())
+}
+
+// getMetrics returns the cache, delivery service, and Traffic Monitor stats, in the Prometheus text exposition format.
+func getMetrics(
+	staticAppData config.StaticAppData,
+	healthPollInterval time.Duration,
+	lastHealthDurations map[tc.CacheName]time.Duration,
+	fetchCount uint64,
+	healthIteration uint64,
+	errorCount uint64,
+	toData todata.TOData,
+	statResultHistory threadsafe.ResultStatHistory,
+	dsStats dsdata.StatsReadonly,
+	crStates tc.CRStates,
+	monitorConfig tc.LegacyTrafficMonitorConfigMap,
+) []byte {
+	fs := newMetricFamilies()
+	addMonitorMetrics(fs, staticAppData, healthPollInterval, fetchCount, healthIteration, errorCount)
+	addCacheMetrics(fs, toData, lastHealthDurations, statResultHistory, crStates, healthThresholdStats(monitorConfig))
+	addDSMetrics(fs, toData, dsStats, crStates)
+
+	buf := &bytes.Buffer{}
+	fs.write(buf)
+	return buf.Bytes()
+}
+
+// addMonitorMetrics adds the health of this Traffic Monitor itself, i.e. the data served by /publish/Stats.
+func addMonitorMetrics(fs *metricFamilies, staticAppData config.StaticAppData, healthPollInterval time.Duration, fetchCount uint64, healthIteration uint64, errorCount uint64) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	fs.get(MetricsPrefix+"build_info", "Traffic Monitor version information, with a constant value of 1.", metricTypeGauge).add(1,
+		metricLabel{Name: "version", Value: staticAppData.Version},
+		metricLabel{Name: "revision", Value: staticAppData.GitRevision},
+		metricLabel{Name: "name", Value: staticAppData.Name},
+	)
+	fs.get(MetricsPrefix+"uptime_seconds", "Time since Traffic Monitor started.", metricTypeGauge).add(time.Since(staticAppData.StartTime).Seconds())
+	fs.get(MetricsPrefix+"fetches_total", "Number of stat and health polls made to caches.", metricTypeCounter).add(float64(fetchCount))
+	fs.get(MetricsPrefix+"health_iterations_total", "Number of health poll results processed.", metricTypeCounter).add(float64(healthIteration))
+	fs.get(MetricsPrefix+"errors_total", "Number of errors encountered.", metricTypeCounter).add(float64(errorCount))
+	fs.get(MetricsPrefix+"health_poll_interval_seconds", "Configured interval between health polls.", metricTypeGauge).add(healthPollInterval.Seconds())
+	fs.get(MetricsPrefix+"goroutines", "Number of goroutines that currently exist.", metricTypeGauge).add(float64(runtime.NumGoroutine()))
+	fs.get(MetricsPrefix+"memory_alloc_bytes", "Bytes of allocated heap objects.", metricTypeGauge).add(float64(memStats.Alloc))
+	fs.get(MetricsPrefix+"memory_alloc_bytes_total", "Cumulative bytes allocated for heap objects.", metricTypeCounter).add(float64(memStats.TotalAlloc))
+	fs.get(MetricsPrefix+"memory_sys_bytes", "Bytes of memory obtained from the OS.", metricTypeGauge).add(float64(memStats.Sys))
+	fs.get(MetricsPrefix+"gc_cpu_fraction", "Fraction of CPU time used by the garbage collector since Traffic Monitor started.", metricTypeGauge).add(memStats.GCCPUFraction)
+}
+
+func cacheMetricLabels(toData todata.TOData, cacheName tc.CacheName) []metricLabel {
+	return []metricLabel{
+		{Name: "cache", Value: string(cacheName)},
+		{Name: "cachegroup", Value: string(toData.ServerCachegroups[cacheName])},
+		{Name: "type", Value: string(toData.ServerTypes[cacheName])},
+	}
+}
+
+// addCacheMetrics adds the availability, poll duration, and latest polled values of the given stats of every cache.
+func addCacheMetrics(fs *metricFamilies, toData todata.TOData, lastHealthDurations map[tc.CacheName]time.Duration, statResultHistory threadsafe.ResultStatHistory, crStates tc.CRStates, metricStats map[string]struct{}) {
+	available := fs.get(MetricsPrefix+"cache_available", "Whether the cache is available, per the combined states of all Traffic Monitors.", metricTypeGauge)
+	ipv4Available := fs.get(MetricsPrefix+"cache_ipv4_available", "Whether the cache is available over IPv4, per the combined states of all Traffic Monitors.", metricTypeGauge)
+	ipv6Available := fs.get(MetricsPrefix+"cache_ipv6_available", "Whether the cache is available over IPv6, per the combined states of all Traffic Monitors.", metricTypeGauge)
+	cacheNames := make([]tc.CacheName, 0, len(crStates.Caches))
+	for cacheName := range crStates.Caches {
+		cacheNames = append(cacheNames, cacheName)
+	}
+	for _, cacheName := range sortCacheNames(cacheNames) {
+		state := crStates.Caches[cacheName]
+		labels := cacheMetricLabels(toData, cacheName)
+		available.add(boolMetricValue(state.IsAvailable), labels...)
+		ipv4Available.add(boolMetricValue(state.Ipv4Available), labels...)
+		ipv6Available.add(boolMetricValue(state.Ipv6Available), labels...)
+	}
+
+	pollDuration := fs.get(MetricsPrefix+"cache_poll_duration_seconds", "Duration of the last health poll of the cache.", metricTypeGauge)
+	cacheNames = make([]tc.CacheName, 0, len(lastHealthDurations))
+	for cacheName := range lastHealthDurations {
+		cacheNames = append(cacheNames, cacheName)
+	}
+	for _, cacheName := range sortCacheNames(cacheNames) {
+		pollDuration.add(lastHealthDurations[cacheName].Seconds(), cacheMetricLabels(toData, cacheName)...)
+	}
+
+	type cacheInterfaceStats struct {
+		cache         tc.CacheName
+		interfaceName string
+		stats         map[string]interface{}
+	}
+	latest := []cacheInterfaceStats{}
+	statResultHistory.Range(func(cacheName tc.CacheName, interfaceName string, history threadsafe.ResultStatValHistory) bool {
+		stats := map[string]interface{}{}
+		history.Range(func(stat string, vals []cache.ResultStatVal) bool {
+			if _, ok := metricStats[stat]; !ok {
+				return true
+			}
+			if len(vals) > 0 {
+				stats[stat] = vals[0].Val
+			}
+			return true
+		})
+		latest = append(latest, cacheInterfaceStats{cache: cacheName, interfaceName: interfaceName, stats: stats})
+		return true
+	})
+	sort.Slice(latest, func(i, j int) bool {
+		if latest[i].cache != latest[j].cache {
+			return latest[i].cache < latest[j].cache
+		}
+		return latest[i].interfaceName < latest[j].interfaceName
+	})
+
+	stat := fs.get(MetricsPrefix+"cache_stat", "The latest polled value of a cache stat with a health threshold.", metricTypeGauge)
+	for _, cacheStats := range latest {
+		labels := append(cacheMetricLabels(toData, cacheStats.cache), metricLabel{Name: "interface", Value: cacheStats.interfaceName})
+		stats := make([]string, 0, len(cacheStats.stats))
+		for stat := range cacheStats.stats {
+			stats = append(stats, stat)
+		}
+		sort.Strings(stats)
+		for _, statName := range stats {
+			val, ok := metricStatValue(cacheStats.stats[statName])
+			if !ok {
+				continue
+			}
+			statLabels := append(append([]metricLabel{}, labels...), metricLabel{Name: "stat", Value: statName})
+			stat.add(val, statLabels...)
+		}
+	}
+}
+
+// dsMetricStats are the delivery service stats served as metrics, for the total of each delivery service, and for each of its cachegroups and cache types.
+var dsMetricStats = []struct {
+	Name  string
+	Help  string
+	Type  string
+	Value func(s *dsdata.StatCacheStats) float64
+}{
+	{"kbps", "Bandwidth served, in kilobits per second.", metricTypeGauge, func(s *dsdata.StatCacheStats) float64 { return s.Kbps.Value }},
+	{"tps_total", "Transactions per second.", metricTypeGauge, func(s *dsdata.StatCacheStats) float64 { return s.TpsTotal.Value }},
+	{"tps_2xx", "Transactions per second with 2xx response codes.", metricTypeGauge, func(s *dsdata.StatCacheStats) float64 { return s.Tps2xx.Value }},
+	{"tps_3xx", "Transactions per second with 3xx response codes.", metricTypeGauge, func(s *dsdata.StatCacheStats) float64 { return s.Tps3xx.Value }},
+	{"tps_4xx", "Transactions per second with 4xx response codes.", metricTypeGauge, func(s *dsdata.StatCacheStats) float64 { return s.Tps4xx.Value }},
+	{"tps_5xx", "Transactions per second with 5xx response codes.", metricTypeGauge, func(s *dsdata.StatCacheStats) float64 { return s.Tps5xx.Value }},
+	{"status_2xx", "Number of responses with 2xx response codes.", metricTypeCounter, func(s *dsdata.StatCacheStats) float64 { return float64(s.Status2xx.Value) }},
+	{"status_3xx", "Number of responses with 3xx response codes.", metricTypeCounter, func(s *dsdata.StatCacheStats) float64 { return float64(s.Status3xx.Value) }},
+	{"status_4xx", "Number of responses with 4xx response codes.", metricTypeCounter, func(s *dsdata.StatCacheStats) float64 { return float64(s.Status4xx.Value) }},
+	{"status_5xx", "Number of responses with 5xx response codes.", metricTypeCounter, func(s *dsdata.StatCacheStats) float64 { return float64(s.Status5xx.Value) }},
+	{"in_bytes", "Bytes received from clients.", metricTypeCounter, func(s *dsdata.StatCacheStats) float64 { return s.InBytes.Value }},
+	{"out_bytes", "Bytes sent to clients.", metricTypeCounter, func(s *dsdata.StatCacheStats) float64 { return float64(s.OutBytes.Value) }},
+}
+
+func addDSCacheStatsMetrics(fs *metricFamilies, prefix string, scope string, stats *dsdata.StatCacheStats, labels ...metricLabel) {
+	for _, stat := range dsMetricStats {
+		fs.get(prefix+stat.Name, stat.Help+" Summed over the "+scope+".", stat.Type).add(stat.Value(stats), labels...)
+	}
+}
+
+// addDSMetrics adds the availability and stats of every delivery service.
+func addDSMetrics(fs *metricFamilies, toData todata.TOData, dsStats dsdata.StatsReadonly, crStates tc.CRStates) {
+	dsNames := make([]string, 0, len(toData.DeliveryServiceTypes))
+	for dsName := range toData.DeliveryServiceTypes {
+		dsNames = append(dsNames, string(dsName))
+	}
+	sort.Strings(dsNames)
+
+	available := fs.get(MetricsPrefix+"ds_available", "Whether the delivery service is available, per the combined states of all Traffic Monitors.", metricTypeGauge)
+	for _, dsName := range dsNames {
+		if state, ok := crStates.DeliveryService[tc.DeliveryServiceName(dsName)]; ok {
+			available.add(boolMetricValue(state.IsAvailable), metricLabel{Name: "ds", Value: dsName})
+		}
+	}
+
+	healthy := fs.get(MetricsPrefix+"ds_healthy", "Whether the delivery service is healthy.", metricTypeGauge)
+	cachesConfigured := fs.get(MetricsPrefix+"ds_caches_configured", "Number of caches assigned to the delivery service.", metricTypeGauge)
+	cachesAvailable := fs.get(MetricsPrefix+"ds_caches_available", "Number of caches assigned to the delivery service which are available.", metricTypeGauge)
+	stats := map[string]*dsdata.Stat{}
+	for _, dsName := range dsNames {
+		stat, ok := dsStats.Get(tc.DeliveryServiceName(dsName))
+		if !ok {
+			continue
+		}
+		stats[dsName] = stat.Copy()
+		dsLabel := metricLabel{Name: "ds", Value: dsName}
+		healthy.add(boolMetricValue(stat.Common().Healthy().Value), dsLabel)
+		cachesConfigured.add(float64(stat.Common().CachesConfigured().Value), dsLabel)
+		cachesAvailable.add(float64(stat.Common().CachesAvailable().Value), dsLabel)
+	}
+
+	// Each scope is added in a separate pass, so every metric family is contiguous.
+	for _, dsName := range dsNames {
+		if stat, ok := stats[dsName]; ok {
+			addDSCacheStatsMetrics(fs, MetricsPrefix+"ds_", "delivery service", &stat.TotalStats, metricLabel{Name: "ds", Value: dsName})
+		}
+	}
+	for _, dsName := range dsNames {
+		stat, ok := stats[dsName]
+		if !ok {
+			continue
+		}
+		cacheGroups := make([]string, 0, len(stat.CacheGroups))
+		for cacheGroup := range stat.CacheGroups {
+			cacheGroups = append(cacheGroups, string(cacheGroup))
+		}
+		sort.Strings(cacheGroups)
+		for _, cacheGroup := range cacheGroups {
+			addDSCacheStatsMetrics(fs, MetricsPrefix+"ds_cachegroup_", "delivery service's caches in the cachegroup", stat.CacheGroups[tc.CacheGroupName(cacheGroup)], metricLabel{Name: "ds", Value: dsName}, metricLabel{Name: "cachegroup", Value: cacheGroup})
+		}
+	}
+	for _, dsName := range dsNames {
+		stat, ok := stats[dsName]
+		if !ok {
+			continue
+		}
+		cacheTypes := make([]string, 0, len(stat.Types))
+		for cacheType := range stat.Types {
+			cacheTypes = append(cacheTypes, string(cacheType))
+		}
+		sort.Strings(cacheTypes)
+		for _, cacheType := range cacheTypes {
+			addDSCacheStatsMetrics(fs, MetricsPrefix+"ds_type_", "delivery service's caches of the type", stat.Types[tc.CacheType(cacheType)], metricLabel{Name: "ds", Value: dsName}, metricLabel{Name: "type", Value: cacheType})
+		}
+	}
+}
+
+func sortCacheNames(names []tc.CacheName) []tc.CacheName {
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package datareq
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/dsdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+func TestGetMetrics(t *testing.T) {
+	toData := todata.New()
+	toData.ServerCachegroups["edge0"] = "cg0"
+	toData.ServerTypes["edge0"] = tc.CacheTypeEdge
+	toData.DeliveryServiceTypes["ds0"] = tc.DSTypeCategoryHTTP
+
+	statResultHistory := threadsafe.NewResultStatHistory()
+	aggregate := threadsafe.NewResultStatValHistory()
+	aggregate.Store("proxy.process.http.current_client_connections", []cache.ResultStatVal{{Val: float64(42), Time: time.Now(), Span: 1}, {Val: float64(7), Time: time.Now(), Span: 1}})
+	aggregate.Store("proxy.process.http.total_incoming_connections", []cache.ResultStatVal{{Val: "9", Time: time.Now(), Span: 1}})
+	aggregate.Store("proxy.process.http.completed_requests", []cache.ResultStatVal{{Val: float64(500), Time: time.Now(), Span: 1}})
+	aggregate.Store("proxy.process.version.server.short", []cache.ResultStatVal{{Val: "8.0.5", Time: time.Now(), Span: 1}})
+	eth0 := threadsafe.NewResultStatValHistory()
+	eth0.Store("proxy.process.http.current_client_connections", []cache.ResultStatVal{{Val: float64(40), Time: time.Now(), Span: 1}})
+	statResultHistory.Store(tc.CacheName("edge0"), map[string]threadsafe.ResultStatValHistory{tc.CacheInterfacesAggregate: aggregate, "eth0": eth0})
+
+	dsStats := dsdata.NewStats(1)
+	dsStat := dsdata.NewStat()
+	dsStat.CommonStats.IsHealthy.Value = true
+	dsStat.CommonStats.CachesConfiguredNum.Value = 1
+	dsStat.TotalStats.Kbps.Value = 1.5
+	dsStat.TotalStats.Status2xx.Value = 100
+	dsStat.CacheGroups["cg0"] = &dsdata.StatCacheStats{Kbps: dsdata.StatFloat{Value: 1.5}}
+	dsStats.DeliveryService["ds0"] = dsStat
+
+	crStates := tc.NewCRStates()
+	crStates.Caches["edge0"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+	crStates.DeliveryService["ds0"] = tc.CRStatesDeliveryService{IsAvailable: true}
+
+	lastHealthDurations := map[tc.CacheName]time.Duration{"edge0": 250 * time.Millisecond}
+
+	monitorConfig := tc.LegacyTrafficMonitorConfigMap{Profile: map[string]tc.TMProfile{
+		"edgeProfile": {Parameters: tc.TMParameters{Thresholds: map[string]tc.HealthThreshold{
+			"proxy.process.http.current_client_connections": {Val: 1000, Comparator: "<"},
+			"proxy.process.http.total_incoming_connections": {Val: 1000, Comparator: "<"},
+			"proxy.process.version.server.short":            {Val: 1, Comparator: ">"},
+		}}},
+	}}
+
+	metrics := string(getMetrics(getMockStaticAppData(), 6*time.Second, lastHealthDurations, 10, 5, 2, *toData, statResultHistory, *dsStats, crStates, monitorConfig))
+
+	expectedLines := []string{
+		`traffic_monitor_fetches_total 10`,
+		`traffic_monitor_errors_total 2`,
+		`traffic_monitor_health_poll_interval_seconds 6`,
+		`traffic_monitor_cache_available{cache="edge0",cachegroup="cg0",type="EDGE"} 1`,
+		`traffic_monitor_cache_ipv6_available{cache="edge0",cachegroup="cg0",type="EDGE"} 0`,
+		`traffic_monitor_cache_poll_duration_seconds{cache="edge0",cachegroup="cg0",type="EDGE"} 0.25`,
+		`traffic_monitor_cache_stat{cache="edge0",cachegroup="cg0",type="EDGE",interface="aggregate",stat="proxy.process.http.current_client_connections"} 42`,
+		`traffic_monitor_cache_stat{cache="edge0",cachegroup="cg0",type="EDGE",interface="aggregate",stat="proxy.process.http.total_incoming_connections"} 9`,
+		`traffic_monitor_cache_stat{cache="edge0",cachegroup="cg0",type="EDGE",interface="eth0",stat="proxy.process.http.current_client_connections"} 40`,
+		`traffic_monitor_ds_available{ds="ds0"} 1`,
+		`traffic_monitor_ds_healthy{ds="ds0"} 1`,
+		`traffic_monitor_ds_caches_configured{ds="ds0"} 1`,
+		`traffic_monitor_ds_kbps{ds="ds0"} 1.5`,
+		`traffic_monitor_ds_status_2xx{ds="ds0"} 100`,
+		`traffic_monitor_ds_cachegroup_kbps{ds="ds0",cachegroup="cg0"} 1.5`,
+		`# TYPE traffic_monitor_ds_status_2xx counter`,
+	}
+	lines := map[string]struct{}{}
+	for _, line := range strings.Split(metrics, "\n") {
+		lines[line] = struct{}{}
+	}
+	for _, expected := range expectedLines {
+		if _, ok := lines[expected]; !ok {
+			t.Errorf("expected metrics to contain line '%s', actual:\n%s", expected, metrics)
+		}
+	}
+
+	if strings.Contains(metrics, "proxy.process.version.server.short") {
+		t.Errorf("expected non-numeric stats to be omitted, actual:\n%s", metrics)
+	}
+	if strings.Contains(metrics, "proxy.process.http.completed_requests") {
+		t.Errorf("expected stats without a health threshold to be omitted, actual:\n%s", metrics)
+	}
+
+	// every sample of a metric must immediately follow its TYPE line, and each metric may only be described once
+	seen := map[string]struct{}{}
+	current := ""
+	for _, line := range strings.Split(strings.TrimSpace(metrics), "\n") {
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		if strings.HasPrefix(line, "# TYPE ") {
+			current = strings.Fields(line)[2]
+			if _, ok := seen[current]; ok {
+				t.Errorf("expected metric '%s' to be described once, actual: multiple times", current)
+			}
+			seen[current] = struct{}{}
+			continue
+		}
+		name := strings.FieldsFunc(line, func(r rune) bool { return r == '{' || r == ' ' })[0]
+		if name != current {
+			t.Errorf("expected sample '%s' to follow the TYPE of its metric, actual: follows '%s'", line, current)
+		}
+	}
+}
+
+func TestEscapeMetricLabelValue(t *testing.T) {
+	actual := escapeMetricLabelValue("a\"b\\c\nd")
+	expected := `a\"b\\c\nd`
+	if actual != expected {
+		t.Errorf("escapeMetricLabelValue expected '%s', actual '%s'", expected, actual)
+	}
+}
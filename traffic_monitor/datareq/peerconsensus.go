@@ -1,7 +1,3 @@
-package v13
-
-import tc "github.com/apache/trafficcontrol/lib/go-tc"
-
 /*
  * Licensed to the Apache Software Foundation (ASF) under one
  * or more contributor license agreements.  See the NOTICE file
@@ -21,11 +17,15 @@ import tc "github.com/apache/trafficcontrol/lib/go-tc"
  * under the License.
  */
 
-// Alerts - informs the client of server side messages
-// swagger:response Alerts
-type Alerts struct {
-	// Alerts Response Body
-	//
-	// in: body
-	Alerts []tc.Alert `json:"alerts"`
+package datareq
+
+import (
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+
+	"github.com/json-iterator/go"
+)
+
+func srvAPIPeerConsensus(peerConsensus peer.ConsensusThreadsafe) ([]byte, error) {
+	json := jsoniter.ConfigFastest
+	return json.Marshal(peerConsensus.Get())
 }
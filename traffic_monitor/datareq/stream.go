@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package datareq
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+
+	"github.com/json-iterator/go"
+)
+
+const ContentTypeEventStream = "text/event-stream"
+
+// StreamKeepaliveInterval is how often a comment is sent on an idle stream, so proxies don't close it.
+const StreamKeepaliveInterval = 15 * time.Second
+
+// StreamRetryMs is the time clients are told to wait before reconnecting after a stream ends.
+const StreamRetryMs = 1000
+
+// streamSnapshotFunc returns a snapshot event with all the data of a stream. It's sent to clients which connect without a sequence number, or can't resume from theirs.
+type streamSnapshotFunc func() (name string, data []byte, err error)
+
+// srvCRStatesStream streams the combined CRStates, as Server-Sent Events: a snapshot of all states, then a delta each time any change.
+func srvCRStatesStream(errorCount threadsafe.Uint, crStatesStream *stream.Stream, combinedStates peer.CRStatesThreadsafe, maxDuration time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		srvStream(w, r, errorCount, crStatesStream, "delta", func() (string, []byte, error) {
+			json := jsoniter.ConfigFastest
+			bytes, err := json.Marshal(combinedStates.Get())
+			return "snapshot", bytes, err
+		}, maxDuration)
+	}
+}
+
+// srvDSStatsStream streams the DS stats, as Server-Sent Events: a frame of all DS stats every stream interval.
+func srvDSStatsStream(errorCount threadsafe.Uint, dsStatsStream *stream.Stream, maxDuration time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		srvStream(w, r, errorCount, dsStatsStream, "dsstats", nil, maxDuration)
+	}
+}
+
+// srvStream writes the events of the given stream to the client as Server-Sent Events named eventName, until the client disconnects or maxDuration passes. A maxDuration of 0 is unlimited.
+// Clients resume from the Last-Event-ID header, or the 'since' query parameter. Clients without either, or which missed events no longer kept, are sent the snapshot; if snapshot is nil, each event is complete, and they're sent the newest event instead.
+func srvStream(w http.ResponseWriter, r *http.Request, errorCount threadsafe.Uint, s *stream.Stream, eventName string, snapshot streamSnapshotFunc, maxDuration time.Duration) {
+	path := r.URL.EscapedPath()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		HandleErr(errorCount, path, errors.New("streaming unsupported by the response writer"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	seq, resuming, err := getStreamSeq(r)
+	if err != nil {
+		HandleErr(errorCount, path, err)
+		w.WriteHeader(http.StatusBadRequest)
+		log.Write(w, []byte(err.Error()), path)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", StreamRetryMs); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	var deadline <-chan time.Time
+	if maxDuration > 0 {
+		timer := time.NewTimer(maxDuration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	keepalive := time.NewTicker(StreamKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		events, latest, resumable, published := s.Since(seq)
+		out := make([]sseEvent, 0, len(events))
+		if !resuming || !resumable {
+			if snapshot != nil {
+				name, data, err := snapshot()
+				if err != nil {
+					HandleErr(errorCount, path, errors.New("getting stream snapshot: "+err.Error()))
+					return
+				}
+				out = append(out, sseEvent{id: latest, name: name, data: data})
+			} else if len(events) > 0 {
+				newest := events[len(events)-1]
+				out = append(out, sseEvent{id: newest.Seq, name: eventName, data: newest.Data})
+			}
+			resuming = true
+		} else {
+			for _, event := range events {
+				out = append(out, sseEvent{id: event.Seq, name: eventName, data: event.Data})
+			}
+		}
+		for _, event := range out {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.id, event.name, event.data); err != nil {
+				return
+			}
+		}
+		if len(out) > 0 {
+			flusher.Flush()
+		}
+		seq = latest
+
+		select {
+		case <-published:
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-deadline:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+type sseEvent struct {
+	id   uint64
+	name string
+	data []byte
+}
+
+// getStreamSeq returns the sequence number the client is resuming from, and whether it's resuming at all. The Last-Event-ID header, sent by EventSource clients when they reconnect, takes precedence over the 'since' query parameter.
+func getStreamSeq(r *http.Request) (uint64, bool, error) {
+	seqStr := r.Header.Get("Last-Event-ID")
+	if seqStr == "" {
+		seqStr = r.URL.Query().Get("since")
+	}
+	if seqStr == "" {
+		return 0, false, nil
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return 0, false, errors.New("invalid sequence number '" + seqStr + "': must be a non-negative integer")
+	}
+	return seq, true, nil
+}
+
+// StreamMaxDuration returns how long a stream may last on a server with the given write timeout. Streams end shortly before the write timeout would cut them off mid-event, and clients reconnect and resume.
+func StreamMaxDuration(writeTimeout time.Duration) time.Duration {
+	if writeTimeout <= 0 {
+		return 0
+	}
+	if writeTimeout > 2*time.Second {
+		return writeTimeout - time.Second
+	}
+	return writeTimeout / 2
+}
@@ -0,0 +1,104 @@
+package datareq
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+)
+
+func serveStream(handler http.HandlerFunc, target string, lastEventID string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if lastEventID != "" {
+		r.Header.Set("Last-Event-ID", lastEventID)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestSrvStreamSnapshot(t *testing.T) {
+	s := stream.New(2)
+	for _, data := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		s.Publish([]byte(data))
+	}
+	snapshot := func() (string, []byte, error) { return "snapshot", []byte(`{"all":true}`), nil }
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		srvStream(w, r, threadsafe.NewUint(), s, "delta", snapshot, 50*time.Millisecond)
+	}
+
+	w := serveStream(handler, "/api/crstates-stream", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ContentTypeEventStream {
+		t.Fatalf("expected 200 %v, actual %v %v", ContentTypeEventStream, w.Code, w.Header().Get("Content-Type"))
+	}
+	if body := w.Body.String(); !strings.Contains(body, "id: 3\nevent: snapshot\ndata: {\"all\":true}\n\n") || strings.Contains(body, "event: delta") {
+		t.Errorf("new client expected only a snapshot with id 3, actual %q", body)
+	}
+
+	w = serveStream(handler, "/api/crstates-stream", "1")
+	if body := w.Body.String(); !strings.Contains(body, "id: 2\nevent: delta\ndata: {\"n\":2}\n\nid: 3\nevent: delta\ndata: {\"n\":3}\n\n") || strings.Contains(body, "snapshot") {
+		t.Errorf("client resuming from 1 expected deltas 2 and 3, actual %q", body)
+	}
+
+	w = serveStream(handler, "/api/crstates-stream?since=0", "")
+	if body := w.Body.String(); !strings.Contains(body, "id: 3\nevent: snapshot\n") || strings.Contains(body, "event: delta") {
+		t.Errorf("client resuming from evicted 0 expected only a snapshot, actual %q", body)
+	}
+
+	w = serveStream(handler, "/api/crstates-stream?since=abc", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid since expected %v, actual %v", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestSrvDSStatsStream(t *testing.T) {
+	s := stream.New(10)
+	handler := srvDSStatsStream(threadsafe.NewUint(), s, 50*time.Millisecond)
+	if body := serveStream(handler, "/api/ds-stats-stream", "").Body.String(); strings.Contains(body, "event:") {
+		t.Errorf("new client of empty stream expected no events, actual %q", body)
+	}
+
+	s.Publish([]byte(`{"n":1}`))
+	s.Publish([]byte(`{"n":2}`))
+	if body := serveStream(handler, "/api/ds-stats-stream", "").Body.String(); !strings.Contains(body, "id: 2\nevent: dsstats\ndata: {\"n\":2}\n\n") || strings.Contains(body, `{"n":1}`) {
+		t.Errorf("new client expected only the newest frame, actual %q", body)
+	}
+	if body := serveStream(handler, "/api/ds-stats-stream", "0").Body.String(); !strings.Contains(body, `{"n":1}`) || !strings.Contains(body, `{"n":2}`) {
+		t.Errorf("client resuming from 0 expected both frames, actual %q", body)
+	}
+}
+
+func TestStreamMaxDuration(t *testing.T) {
+	for writeTimeout, expected := range map[time.Duration]time.Duration{
+		0:                0,
+		10 * time.Second: 9 * time.Second,
+		time.Second:      500 * time.Millisecond,
+	} {
+		if actual := StreamMaxDuration(writeTimeout); actual != expected {
+			t.Errorf("StreamMaxDuration(%v) expected %v, actual %v", writeTimeout, expected, actual)
+		}
+	}
+}
@@ -1,37 +1,3 @@
-/*
- Package docs Traffic Ops API
-
- The following REST APIs are used by the Traffic Portal UI along with several Traffic Control backend component and services.
-
- This documentation is fully compliant with the Swagger 2.0 specification
-
-     Schemes: http, https
-	 Host: your-traffic-ops
-     BasePath: /api/1.3
-     Version: 1.3
-     License: Apache 2 https://github.com/apache/trafficcontrol/blob/master/LICENSE
-     Contact: Traffic Control Dev List<dev@trafficcontrol.apache.org> http://traffic-control-cdn.readthedocs.io/en/latest/index.html
-
-     Consumes:
-     - application/json
-
-     Produces:
-     - application/json
-
-     Security:
-     - Cookie:
-
-     SecurityDefinitions:
-     Cookie:
-          type: apiKey
-          name: cookie
-          in: header
-
-
- swagger:meta
-*/
-package v13
-
 /*
  * Licensed to the Apache Software Foundation (ASF) under one
  * or more contributor license agreements.  See the NOTICE file
@@ -50,3 +16,16 @@ package v13
  * specific language governing permissions and limitations
  * under the License.
  */
+
+package datareq
+
+import (
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
+
+	"github.com/json-iterator/go"
+)
+
+func srvAPISyntheticChecks(syntheticResults synthetic.ResultsThreadsafe) ([]byte, error) {
+	json := jsoniter.ConfigFastest
+	return json.Marshal(syntheticResults.Get())
+}
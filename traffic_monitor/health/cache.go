@@ -21,6 +21,7 @@ package health
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -29,6 +30,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/cache"
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
@@ -148,13 +150,110 @@ func EvalCache(result cache.ResultInfo, resultStats *threadsafe.ResultStatValHis
 		}
 	}
 
+	ruleStats := cacheRuleStats{result: result, serverInfo: serverInfo, serverProfile: serverProfile, computedStats: computedStats, history: resultStats}
+	for _, name := range sortedRuleNames(serverProfile.Parameters.Rules) {
+		expr := serverProfile.Parameters.Rules[name]
+		rule, err := GetRule(expr)
+		if err != nil {
+			log.Errorf("health.EvalCache profile %s rule %s '%s' is invalid, ignoring: %v", serverInfo.Profile, name, expr, err)
+			continue
+		}
+		unhealthy, err := rule.Eval(ruleStats)
+		if err == ErrRuleStatMissing {
+			continue // this poller doesn't have the rule's stats, e.g. the health poller
+		} else if err != nil {
+			log.Errorf("health.EvalCache profile %s rule %s '%s' could not be evaluated, ignoring: %v", serverInfo.Profile, name, expr, err)
+			continue
+		}
+		if unhealthy {
+			return false, result.UsingIPv4, eventDesc(status, fmt.Sprintf("health rule %s matched (%s)", name, expr)), ruleUnavailableStat(rule, computedStats)
+		}
+	}
+
 	return avail, result.UsingIPv4, eventDescVal, eventMsg
 }
 
+// cacheRuleStats provides the stats of a cache poll result to health rules.
+type cacheRuleStats struct {
+	result        cache.ResultInfo
+	serverInfo    tc.LegacyTrafficServer
+	serverProfile tc.TMProfile
+	computedStats map[string]cache.StatComputeFunc
+	history       *threadsafe.ResultStatValHistory
+}
+
+// Latest returns the latest value of the given computed or polled stat. It is part of the RuleStats interface.
+func (s cacheRuleStats) Latest(stat string) (float64, bool) {
+	vals, ok := s.Window(stat, 1)
+	if !ok {
+		return 0, false
+	}
+	return vals[0], true
+}
+
+// Window returns the given number of the most recent polled values of the given stat. Computed stats have no history, so only their latest value is returned. It is part of the RuleStats interface.
+func (s cacheRuleStats) Window(stat string, polls int) ([]float64, bool) {
+	if computedStatF, ok := s.computedStats[stat]; ok {
+		val, ok := util.ToNumeric(computedStatF(s.result, s.serverInfo, s.serverProfile, tc.IsAvailable{}))
+		if !ok {
+			return nil, false
+		}
+		return []float64{val}, true
+	}
+	if s.history == nil {
+		return nil, false
+	}
+	history := s.history.Load(stat)
+	vals := make([]float64, 0, polls)
+	for _, statVal := range history {
+		val, ok := util.ToNumeric(statVal.Val)
+		if !ok {
+			return nil, false
+		}
+		// each history value is the value of Span consecutive polls
+		for i := uint64(0); i < statVal.Span && len(vals) < polls; i++ {
+			vals = append(vals, val)
+		}
+		if len(vals) >= polls {
+			break
+		}
+	}
+	if len(vals) == 0 {
+		return nil, false
+	}
+	return vals, true
+}
+
+// ruleUnavailableStat returns the stat to mark a cache unavailable by, when the given rule is true. This is the first stat of the rule which isn't computed, so pollers without the rule's stats won't mark the cache available; or the first stat, if all the rule's stats are computed.
+func ruleUnavailableStat(rule *Rule, computedStats map[string]cache.StatComputeFunc) string {
+	for _, stat := range rule.Stats() {
+		if _, ok := computedStats[stat]; !ok {
+			return stat
+		}
+	}
+	if len(rule.Stats()) > 0 {
+		return rule.Stats()[0]
+	}
+	return ""
+}
+
+func sortedRuleNames(rules map[string]string) []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // CalcAvailabilityWithStats calculates the availability of each cache in results.
 // statResultHistory may be nil, in which case stats won't be used to calculate availability.
-func CalcAvailability(results []cache.Result, pollerName string, statResultHistory *threadsafe.ResultStatHistory, mc tc.LegacyTrafficMonitorConfigMap, toData todata.TOData, localCacheStatusThreadsafe threadsafe.CacheAvailableStatus, localStates peer.CRStatesThreadsafe, events ThreadsafeEvents, protocol config.PollingProtocol) {
+func CalcAvailability(results []cache.Result, pollerName string, statResultHistory *threadsafe.ResultStatHistory, mc tc.LegacyTrafficMonitorConfigMap, toData todata.TOData, localCacheStatusThreadsafe threadsafe.CacheAvailableStatus, localStates peer.CRStatesThreadsafe, events ThreadsafeEvents, syntheticResults synthetic.ResultsThreadsafe, circuitBreaker CircuitBreaker, protocol config.PollingProtocol) {
 	localCacheStatuses := localCacheStatusThreadsafe.Get().Copy()
+	cacheGroupCaches := map[tc.CacheGroupName]int{}
+	for _, cacheGroup := range toData.ServerCachegroups {
+		cacheGroupCaches[cacheGroup]++
+	}
 	statResults := (*threadsafe.ResultStatValHistory)(nil)
 	statResultsVal := (*map[string]threadsafe.ResultStatValHistory)(nil)
 	processAvailableTuple := func(tuple cache.AvailableTuple, serverInfo tc.LegacyTrafficServer) bool {
@@ -204,8 +303,11 @@ func CalcAvailability(results []cache.Result, pollerName string, statResultHisto
 			previousStatus, hasPreviousStatus := localCacheStatuses[tc.CacheName(result.ID)][interfaceName]
 			availableTuple := cache.AvailableTuple{}
 
+			counts := hysteresisCounts{}
 			if hasPreviousStatus {
 				availableTuple = previousStatus.Available
+				profileParams := mc.Profile[serverInfo.Profile].Parameters
+				isAvailable, whyAvailable, unavailableStat, counts = applyHysteresis(previousStatus, usingIPv4, result.HasStat, profileParams, isAvailable, whyAvailable, unavailableStat)
 				availableTuple.SetAvailability(usingIPv4, isAvailable)
 
 				if processAvailableTuple(availableTuple, serverInfo) {
@@ -237,6 +339,9 @@ func CalcAvailability(results []cache.Result, pollerName string, statResultHisto
 				UnavailableStat:    unavailableStat,
 				Poller:             pollerName,
 				LastCheckedIPv4:    usingIPv4,
+				ThresholdFailures:  counts.failures,
+				ThresholdPasses:    counts.passes,
+				FailingStat:        counts.failingStat,
 			} // TODO move within localStates?
 		}
 
@@ -290,13 +395,32 @@ func CalcAvailability(results []cache.Result, pollerName string, statResultHisto
 			aggregateStatus.Poller = status.Poller
 		}
 		aggregateStatus.ProcessedAvailable = processAvailableTuple(aggregateStatus.Available, serverInfo)
+		previousAggregateStatus, hasPreviousAggregateStatus := localCacheStatuses[tc.CacheName(result.ID)][tc.CacheInterfacesAggregate]
+		now := time.Now()
+
+		// only polling can trip the circuit breaker; operators may set as many caches to ADMIN_DOWN or OFFLINE as they like.
+		if hasPreviousAggregateStatus && previousAggregateStatus.ProcessedAvailable && !aggregateStatus.ProcessedAvailable && tc.CacheStatusFromString(serverInfo.ServerStatus) == tc.CacheStatusReported {
+			cacheGroup := toData.ServerCachegroups[tc.CacheName(result.ID)]
+			if held, why := circuitBreaker.hold(mc.Config, tc.CacheName(result.ID), cacheGroup, cacheGroupCaches[cacheGroup], len(toData.ServerCachegroups), events, now); held {
+				aggregateStatus.Available = previousAggregateStatus.Available
+				aggregateStatus.ProcessedAvailable = true
+				aggregateStatus.CircuitBreaker = why
+			}
+		}
+		aggregateStatus.WarmingStart = nextWarmingStart(previousAggregateStatus, hasPreviousAggregateStatus, aggregateStatus.ProcessedAvailable, now)
 		localCacheStatuses[tc.CacheName(result.ID)][tc.CacheInterfacesAggregate] = aggregateStatus
 
-		localStates.SetCache(tc.CacheName(result.ID), tc.IsAvailable{
+		weight := slowStartWeight(aggregateStatus.WarmingStart, time.Duration(mc.Profile[serverInfo.Profile].Parameters.SlowStartPeriod)*time.Millisecond, now)
+		cacheState := tc.IsAvailable{
 			IsAvailable:   aggregateStatus.ProcessedAvailable,
 			Ipv4Available: aggregateStatus.Available.IPv4,
 			Ipv6Available: aggregateStatus.Available.IPv6,
-		})
+		}
+		if weight < 1 {
+			cacheState.Warming = true
+			cacheState.Weight = weight
+		}
+		localStates.SetCache(tc.CacheName(result.ID), cacheState)
 
 		if statResultsVal != nil {
 			t := (*statResultsVal)[tc.CacheInterfacesAggregate]
@@ -316,10 +440,83 @@ func CalcAvailability(results []cache.Result, pollerName string, statResultHisto
 				IPv4Available: aggregateStatus.Available.IPv4, IPv6Available: aggregateStatus.Available.IPv6})
 		}
 	}
-	calculateDeliveryServiceState(toData.DeliveryServiceServers, localStates, toData)
+	calculateDeliveryServiceState(toData.DeliveryServiceServers, localStates, toData, syntheticChecksIfAffectAvailability(mc, syntheticResults))
 	localCacheStatusThreadsafe.Set(localCacheStatuses)
 }
 
+// hysteresisCounts are the consecutive threshold failures and passes of a cache, which applyHysteresis counts toward marking it unavailable or available.
+type hysteresisCounts struct {
+	failures    uint64
+	passes      uint64
+	failingStat string
+}
+
+// applyHysteresis returns the availability, reason, and unavailable stat of an evaluation of a cache which has a previous status, after applying the profile's health hysteresis, and the new consecutive threshold failure and pass counts.
+// A cache which is available is only marked unavailable by a threshold or rule once HysteresisDown consecutive evaluations have exceeded one, and a cache which was marked unavailable by a threshold or rule is only marked available once HysteresisUp consecutive evaluations have been within all of them. Unavailability for any other reason, such as a poll error, is never delayed.
+// The hasStat must return whether the evaluated result has the given stat. Evaluations by pollers without the stat which is failing, or which made the cache unavailable, count as neither failures nor passes, so e.g. the health poller can't reset the failures of a rule on a stat only the stat poller has.
+func applyHysteresis(previous cache.AvailableStatus, usingIPv4 bool, hasStat func(stat string) bool, params tc.TMParameters, isAvailable bool, why string, unavailableStat string) (bool, string, string, hysteresisCounts) {
+	wasAvailable := previous.Available.IPv6
+	if usingIPv4 {
+		wasAvailable = previous.Available.IPv4
+	}
+
+	counts := hysteresisCounts{}
+	switch {
+	case !isAvailable && unavailableStat != "":
+		counts.failures = previous.ThresholdFailures + 1
+		counts.failingStat = unavailableStat
+	case isAvailable && previous.FailingStat != "" && !hasStat(previous.FailingStat):
+		counts = hysteresisCounts{failures: previous.ThresholdFailures, passes: previous.ThresholdPasses, failingStat: previous.FailingStat}
+	case isAvailable && (previous.UnavailableStat == "" || hasStat(previous.UnavailableStat)):
+		counts.passes = previous.ThresholdPasses + 1
+	case isAvailable:
+		counts.passes = previous.ThresholdPasses
+	}
+
+	down := uint64(1)
+	if params.HysteresisDown > 1 {
+		down = uint64(params.HysteresisDown)
+	}
+	up := uint64(1)
+	if params.HysteresisUp > 1 {
+		up = uint64(params.HysteresisUp)
+	}
+
+	if wasAvailable && counts.failures > 0 && counts.failures < down {
+		return true, fmt.Sprintf("%s (%d of %d consecutive failures to mark unavailable)", why, counts.failures, down), "", counts
+	}
+	if !wasAvailable && isAvailable && previous.UnavailableStat != "" && counts.passes < up {
+		return false, fmt.Sprintf("%s (%d of %d consecutive passes to mark available)", why, counts.passes, up), previous.UnavailableStat, counts
+	}
+	return isAvailable, why, unavailableStat, counts
+}
+
+// nextWarmingStart returns the time the slow start of a cache began, given its previous aggregate status and whether it's now available. Caches which become available on their first poll don't slow start, because there's no way to know they were previously unavailable.
+func nextWarmingStart(previous cache.AvailableStatus, hasPrevious bool, isAvailable bool, now time.Time) time.Time {
+	switch {
+	case !isAvailable:
+		return time.Time{}
+	case hasPrevious && !previous.ProcessedAvailable:
+		return now
+	}
+	return previous.WarmingStart
+}
+
+// slowStartWeight returns the fraction of its full share of load a cache which began its slow start at warmingStart should receive, ramping linearly from 0 to 1 over the period. If warmingStart is zero or the period is not positive, the cache isn't slow starting, and 1 is returned.
+func slowStartWeight(warmingStart time.Time, period time.Duration, now time.Time) float64 {
+	if warmingStart.IsZero() || period <= 0 {
+		return 1
+	}
+	elapsed := now.Sub(warmingStart)
+	if elapsed >= period {
+		return 1
+	}
+	if elapsed < 0 {
+		return 0
+	}
+	return float64(elapsed) / float64(period)
+}
+
 func setErr(newResult *cache.Result, err error) {
 	newResult.Error = err
 	newResult.Available = false
@@ -365,8 +562,17 @@ func eventDesc(status tc.CacheStatus, message string) string {
 	return fmt.Sprintf("%s - %s", status, message)
 }
 
+// syntheticChecksIfAffectAvailability returns the latest synthetic check results, if the monitor config makes failing checks affect Delivery Service availability, else no results.
+func syntheticChecksIfAffectAvailability(mc tc.LegacyTrafficMonitorConfigMap, syntheticResults synthetic.ResultsThreadsafe) synthetic.Results {
+	if !synthetic.AffectsAvailability(mc.Config) {
+		return synthetic.Results{}
+	}
+	return syntheticResults.Get()
+}
+
 //calculateDeliveryServiceState calculates the state of delivery services from the new cache state data `cacheState` and the CRConfig data `deliveryServiceServers` and puts the calculated state in the outparam `deliveryServiceStates`
-func calculateDeliveryServiceState(deliveryServiceServers map[tc.DeliveryServiceName][]tc.CacheName, states peer.CRStatesThreadsafe, toData todata.TOData) {
+// A cache whose latest synthetic check of a delivery service in `syntheticChecks` failed is treated as unavailable for that delivery service.
+func calculateDeliveryServiceState(deliveryServiceServers map[tc.DeliveryServiceName][]tc.CacheName, states peer.CRStatesThreadsafe, toData todata.TOData, syntheticChecks synthetic.Results) {
 	cacheStates := states.GetCaches()
 
 	deliveryServices := states.GetDeliveryServices()
@@ -375,14 +581,14 @@ func calculateDeliveryServiceState(deliveryServiceServers map[tc.DeliveryService
 			log.Infof("CRConfig does not have delivery service %s, but traffic monitor poller does; skipping\n", deliveryServiceName)
 			continue
 		}
-		deliveryServiceState.DisabledLocations = getDisabledLocations(deliveryServiceName, toData.DeliveryServiceServers[deliveryServiceName], cacheStates, toData.ServerCachegroups)
+		deliveryServiceState.DisabledLocations = getDisabledLocations(deliveryServiceName, toData.DeliveryServiceServers[deliveryServiceName], cacheStates, toData.ServerCachegroups, syntheticChecks)
 		states.SetDeliveryService(deliveryServiceName, deliveryServiceState)
 	}
 }
 
-func getDisabledLocations(deliveryService tc.DeliveryServiceName, deliveryServiceServers []tc.CacheName, cacheStates map[tc.CacheName]tc.IsAvailable, serverCacheGroups map[tc.CacheName]tc.CacheGroupName) []tc.CacheGroupName {
+func getDisabledLocations(deliveryService tc.DeliveryServiceName, deliveryServiceServers []tc.CacheName, cacheStates map[tc.CacheName]tc.IsAvailable, serverCacheGroups map[tc.CacheName]tc.CacheGroupName, syntheticChecks synthetic.Results) []tc.CacheGroupName {
 	disabledLocations := []tc.CacheGroupName{} // it's important this isn't nil, so it serialises to the JSON `[]` instead of `null`
-	dsCacheStates := getDeliveryServiceCacheAvailability(cacheStates, deliveryServiceServers)
+	dsCacheStates := getDeliveryServiceCacheAvailability(deliveryService, cacheStates, deliveryServiceServers, syntheticChecks)
 	dsCachegroupsAvailable := getDeliveryServiceCachegroupAvailability(dsCacheStates, serverCacheGroups)
 	for cg, avail := range dsCachegroupsAvailable {
 		if avail {
@@ -393,10 +599,14 @@ func getDisabledLocations(deliveryService tc.DeliveryServiceName, deliveryServic
 	return disabledLocations
 }
 
-func getDeliveryServiceCacheAvailability(cacheStates map[tc.CacheName]tc.IsAvailable, deliveryServiceServers []tc.CacheName) map[tc.CacheName]tc.IsAvailable {
+func getDeliveryServiceCacheAvailability(deliveryService tc.DeliveryServiceName, cacheStates map[tc.CacheName]tc.IsAvailable, deliveryServiceServers []tc.CacheName, syntheticChecks synthetic.Results) map[tc.CacheName]tc.IsAvailable {
 	dsCacheStates := map[tc.CacheName]tc.IsAvailable{}
 	for _, server := range deliveryServiceServers {
-		dsCacheStates[server] = cacheStates[tc.CacheName(server)]
+		cacheState := cacheStates[tc.CacheName(server)]
+		if syntheticChecks.Failing(deliveryService, server) {
+			cacheState.IsAvailable = false
+		}
+		dsCacheStates[server] = cacheState
 	}
 	return dsCacheStates
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_monitor/cache"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
@@ -120,10 +121,10 @@ func TestCalcAvailabilityThresholds(t *testing.T) {
 	// Ensure that if the interfaces haven't been reported yet that CalcAvailability doesn't panic
 	original := results[0].Statistics.Interfaces
 	results[0].Statistics.Interfaces = make(map[string]cache.Interface)
-	CalcAvailability(results, pollerName, statResultHistory, mc, toData, localCacheStatusThreadsafe, localStates, events, config.Both)
+	CalcAvailability(results, pollerName, statResultHistory, mc, toData, localCacheStatusThreadsafe, localStates, events, synthetic.NewResultsThreadsafe(), NewCircuitBreaker(), config.Both)
 	results[0].Statistics.Interfaces = original
 
-	CalcAvailability(results, pollerName, statResultHistory, mc, toData, localCacheStatusThreadsafe, localStates, events, config.Both)
+	CalcAvailability(results, pollerName, statResultHistory, mc, toData, localCacheStatusThreadsafe, localStates, events, synthetic.NewResultsThreadsafe(), NewCircuitBreaker(), config.Both)
 
 	localCacheStatuses := localCacheStatusThreadsafe.Get()
 	if _, ok := localCacheStatuses[tc.CacheName(result.ID)]; !ok {
@@ -159,7 +160,7 @@ func TestCalcAvailabilityThresholds(t *testing.T) {
 	GetVitals(&healthResult, &result, nil)
 	healthPollerName := "health"
 	healthResults := []cache.Result{healthResult}
-	CalcAvailability(healthResults, healthPollerName, nil, mc, toData, localCacheStatusThreadsafe, localStates, events, config.Both)
+	CalcAvailability(healthResults, healthPollerName, nil, mc, toData, localCacheStatusThreadsafe, localStates, events, synthetic.NewResultsThreadsafe(), NewCircuitBreaker(), config.Both)
 
 	localCacheStatuses = localCacheStatusThreadsafe.Get()
 	localCacheStatus := localCacheStatuses[tc.CacheName(result.ID)]["bond0"]
@@ -177,3 +178,247 @@ func TestCalcAvailabilityThresholds(t *testing.T) {
 		t.Fatalf("localCacheStatus.Why expected 'availableBandwidthInKbps too low' actual %v", localCacheStatus.Why)
 	}
 }
+
+func TestApplyHysteresis(t *testing.T) {
+	params := tc.TMParameters{HysteresisDown: 3, HysteresisUp: 2}
+	status := cache.AvailableStatus{Available: cache.AvailableTuple{IPv4: true}}
+
+	// eval evaluates one poll, and updates the status as CalcAvailability would
+	eval := func(available bool, unavailableStat string, hasUnavailableStat bool) bool {
+		hasStat := func(stat string) bool { return hasUnavailableStat }
+		isAvailable, why, stat, counts := applyHysteresis(status, true, hasStat, params, available, "why", unavailableStat)
+		status = cache.AvailableStatus{
+			Available:         cache.AvailableTuple{IPv4: isAvailable},
+			Why:               why,
+			UnavailableStat:   stat,
+			ThresholdFailures: counts.failures,
+			ThresholdPasses:   counts.passes,
+			FailingStat:       counts.failingStat,
+		}
+		return isAvailable
+	}
+
+	if !eval(false, "loadavg", true) || !eval(false, "loadavg", true) {
+		t.Fatalf("expected cache to stay available until %d consecutive threshold failures, actual: unavailable", params.HysteresisDown)
+	}
+	if !eval(true, "", true) {
+		t.Fatalf("expected cache to stay available after passing, actual: unavailable")
+	}
+	if status.ThresholdFailures != 0 {
+		t.Fatalf("expected a pass to reset threshold failures, actual: %d", status.ThresholdFailures)
+	}
+	eval(false, "loadavg", true)
+	eval(false, "loadavg", true)
+	if eval(false, "loadavg", true) {
+		t.Fatalf("expected cache to be unavailable after %d consecutive threshold failures, actual: available", params.HysteresisDown)
+	}
+	if status.UnavailableStat != "loadavg" {
+		t.Fatalf("expected unavailable stat 'loadavg', actual: '%s'", status.UnavailableStat)
+	}
+
+	if eval(true, "", true) {
+		t.Fatalf("expected cache to stay unavailable until %d consecutive passes, actual: available", params.HysteresisUp)
+	}
+	if eval(true, "", false) {
+		t.Fatalf("expected a poller without the unavailable stat not to mark the cache available, actual: available")
+	}
+	if !eval(true, "", true) {
+		t.Fatalf("expected cache to be available after %d consecutive passes, actual: unavailable", params.HysteresisUp)
+	}
+
+	// errors aren't thresholds, and mark the cache unavailable immediately
+	if eval(false, "", true) {
+		t.Fatalf("expected a non-threshold failure to mark the cache unavailable immediately, actual: available")
+	}
+	if !eval(true, "", true) {
+		t.Fatalf("expected a cache unavailable for a non-threshold failure to be marked available immediately, actual: unavailable")
+	}
+}
+
+func TestCalcAvailabilityHysteresisInterleavedPolls(t *testing.T) {
+	cacheName := tc.CacheName("myCacheName")
+	mc := tc.LegacyTrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.LegacyTrafficServer{
+			string(cacheName): {ServerStatus: string(tc.CacheStatusReported), Profile: "myProfileName", IP: "192.0.2.1"},
+		},
+		Profile: map[string]tc.TMProfile{
+			"myProfileName": {
+				Name: "myProfileName",
+				Parameters: tc.TMParameters{
+					HysteresisDown: 3,
+					Rules:          map[string]string{"connections": "proxy.process.http.current_client_connections > 100"},
+				},
+			},
+		},
+	}
+	toData := todata.TOData{
+		ServerTypes:            map[tc.CacheName]tc.CacheType{cacheName: tc.CacheTypeEdge},
+		DeliveryServiceServers: map[tc.DeliveryServiceName][]tc.CacheName{},
+		ServerCachegroups:      map[tc.CacheName]tc.CacheGroupName{cacheName: "myCG"},
+	}
+
+	// only the stat poller has the connections stat, and its history
+	statHistory := threadsafe.NewResultStatValHistory()
+	statHistory.Store("proxy.process.http.current_client_connections", []cache.ResultStatVal{{Val: float64(1000), Time: time.Now(), Span: 1}})
+	statResultHistory := threadsafe.NewResultStatHistory()
+	statResultHistory.Store(cacheName, map[string]threadsafe.ResultStatValHistory{"bond0": statHistory, tc.CacheInterfacesAggregate: statHistory})
+
+	newResult := func(misc map[string]interface{}) cache.Result {
+		return cache.Result{
+			ID:            string(cacheName),
+			Miscellaneous: misc,
+			Statistics:    cache.Statistics{Interfaces: map[string]cache.Interface{"bond0": {Speed: 20000}}},
+			Time:          time.Now(),
+			PollFinished:  make(chan uint64, 1),
+			Available:     true,
+			UsingIPv4:     true,
+		}
+	}
+	statResult := newResult(map[string]interface{}{"proxy.process.http.current_client_connections": float64(1000)})
+	healthResult := newResult(map[string]interface{}{})
+
+	localCacheStatusThreadsafe := threadsafe.NewCacheAvailableStatus()
+	localStates := peer.NewCRStatesThreadsafe()
+	events := NewThreadsafeEvents(200)
+	circuitBreaker := NewCircuitBreaker()
+	statPoll := func() {
+		CalcAvailability([]cache.Result{statResult}, "stat", &statResultHistory, mc, toData, localCacheStatusThreadsafe, localStates, events, synthetic.NewResultsThreadsafe(), circuitBreaker, config.Both)
+	}
+	healthPoll := func() {
+		CalcAvailability([]cache.Result{healthResult}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events, synthetic.NewResultsThreadsafe(), circuitBreaker, config.Both)
+	}
+	available := func() bool {
+		return localCacheStatusThreadsafe.Get()[cacheName]["bond0"].Available.IPv4
+	}
+
+	healthPoll()
+	if !available() {
+		t.Fatal("expected cache to be available after a health poll, actual: unavailable")
+	}
+	for i := 1; i < mc.Profile["myProfileName"].Parameters.HysteresisDown; i++ {
+		statPoll()
+		healthPoll()
+		if !available() {
+			t.Fatalf("expected cache to stay available after %d of %d consecutive rule failures, actual: unavailable", i, mc.Profile["myProfileName"].Parameters.HysteresisDown)
+		}
+	}
+	if failures := localCacheStatusThreadsafe.Get()[cacheName]["bond0"].ThresholdFailures; failures != uint64(mc.Profile["myProfileName"].Parameters.HysteresisDown-1) {
+		t.Fatalf("expected health polls not to reset the rule failures, actual failures: %d", failures)
+	}
+	statPoll()
+	if available() {
+		t.Fatalf("expected cache to be unavailable after %d consecutive stat polls failed the rule, interleaved with health polls, actual: available", mc.Profile["myProfileName"].Parameters.HysteresisDown)
+	}
+	healthPoll()
+	if available() {
+		t.Fatal("expected a health poll without the rule's stat not to mark the cache available, actual: available")
+	}
+}
+
+func TestEvalCacheRules(t *testing.T) {
+	mc := tc.LegacyTrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.LegacyTrafficServer{
+			"myCacheName": {ServerStatus: string(tc.CacheStatusReported), Profile: "myProfileName"},
+		},
+		Profile: map[string]tc.TMProfile{
+			"myProfileName": {
+				Name: "myProfileName",
+				Parameters: tc.TMParameters{
+					Rules: map[string]string{
+						"busy":    `loadavg > 20 && avg(proxy.process.http.current_client_connections, 3) > 1000`,
+						"invalid": `loadavg >`,
+					},
+				},
+			},
+		},
+	}
+	result := cache.ResultInfo{ID: "myCacheName", Available: true, UsingIPv4: true, Vitals: cache.Vitals{LoadAvg: 25}}
+
+	// the health poller doesn't have the connections stat, so the rule can't be evaluated
+	if avail, _, _, _ := EvalCache(result, nil, &mc); !avail {
+		t.Errorf("expected rule with missing stats to be ignored, actual: unavailable")
+	}
+
+	history := threadsafe.NewResultStatValHistory()
+	history.Store("proxy.process.http.current_client_connections", []cache.ResultStatVal{{Val: float64(2000), Span: 2}, {Val: float64(500), Span: 5}})
+	avail, _, why, unavailableStat := EvalCache(result, &history, &mc)
+	if avail {
+		t.Errorf("expected average connections over 3 polls of 1500 to match rule, actual: available")
+	} else if unavailableStat != "proxy.process.http.current_client_connections" {
+		t.Errorf("expected unavailable stat to be the rule's polled stat, actual: '%s'", unavailableStat)
+	} else if !strings.Contains(why, "health rule busy matched") {
+		t.Errorf("expected why to contain 'health rule busy matched', actual: '%s'", why)
+	}
+
+	history.Store("proxy.process.http.current_client_connections", []cache.ResultStatVal{{Val: float64(2000), Span: 1}, {Val: float64(500), Span: 5}})
+	if avail, _, _, _ := EvalCache(result, &history, &mc); !avail {
+		t.Errorf("expected average connections over 3 polls of 1000 not to match rule, actual: unavailable")
+	}
+}
+
+func TestSlowStart(t *testing.T) {
+	now := time.Now()
+	period := 10 * time.Minute
+
+	if start := nextWarmingStart(cache.AvailableStatus{}, false, true, now); !start.IsZero() {
+		t.Errorf("expected a cache available on its first poll not to slow start, actual: warming since %v", start)
+	}
+	start := nextWarmingStart(cache.AvailableStatus{ProcessedAvailable: false}, true, true, now)
+	if !start.Equal(now) {
+		t.Fatalf("expected a cache becoming available to slow start now, actual: %v", start)
+	}
+	if next := nextWarmingStart(cache.AvailableStatus{ProcessedAvailable: true, WarmingStart: start}, true, true, now.Add(time.Minute)); !next.Equal(start) {
+		t.Errorf("expected a cache staying available to keep its slow start time, actual: %v", next)
+	}
+	if next := nextWarmingStart(cache.AvailableStatus{ProcessedAvailable: true, WarmingStart: start}, true, false, now.Add(time.Minute)); !next.IsZero() {
+		t.Errorf("expected an unavailable cache not to be slow starting, actual: %v", next)
+	}
+
+	tests := []struct {
+		elapsed  time.Duration
+		expected float64
+	}{
+		{0, 0},
+		{time.Minute, 0.1},
+		{5 * time.Minute, 0.5},
+		{10 * time.Minute, 1},
+		{time.Hour, 1},
+	}
+	for _, test := range tests {
+		if actual := slowStartWeight(start, period, start.Add(test.elapsed)); actual != test.expected {
+			t.Errorf("slowStartWeight after %v expected %v, actual %v", test.elapsed, test.expected, actual)
+		}
+	}
+	if actual := slowStartWeight(time.Time{}, period, now); actual != 1 {
+		t.Errorf("slowStartWeight of a cache not slow starting expected 1, actual %v", actual)
+	}
+	if actual := slowStartWeight(start, 0, now); actual != 1 {
+		t.Errorf("slowStartWeight with no period expected 1, actual %v", actual)
+	}
+}
+
+func TestGetDisabledLocationsSyntheticChecks(t *testing.T) {
+	ds := tc.DeliveryServiceName("ds0")
+	servers := []tc.CacheName{"edge0", "edge1", "edge2"}
+	cacheStates := map[tc.CacheName]tc.IsAvailable{
+		"edge0": {IsAvailable: true},
+		"edge1": {IsAvailable: true},
+		"edge2": {IsAvailable: true},
+	}
+	cachegroups := map[tc.CacheName]tc.CacheGroupName{"edge0": "cg0", "edge1": "cg1", "edge2": "cg1"}
+
+	checks := synthetic.Results{
+		ds:    {"edge0": {Available: false}, "edge1": {Available: false}, "edge2": {Available: true}},
+		"ds1": {"edge2": {Available: false}},
+	}
+
+	disabled := getDisabledLocations(ds, servers, cacheStates, cachegroups, checks)
+	if len(disabled) != 1 || disabled[0] != "cg0" {
+		t.Errorf("expected failing synthetic checks to disable cachegroups with no passing caches [cg0], actual: %v", disabled)
+	}
+
+	disabled = getDisabledLocations(ds, servers, cacheStates, cachegroups, synthetic.Results{})
+	if len(disabled) != 0 {
+		t.Errorf("expected no disabled cachegroups without synthetic checks, actual: %v", disabled)
+	}
+}
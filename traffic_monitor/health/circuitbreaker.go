@@ -0,0 +1,151 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	// CircuitBreakerCacheGroupParam is the Traffic Monitor config Parameter of the maximum fraction of the caches in a Cache Group which may be marked unavailable within the circuit breaker window.
+	CircuitBreakerCacheGroupParam = "health.circuitbreaker.cachegroup"
+	// CircuitBreakerCDNParam is the Traffic Monitor config Parameter of the maximum fraction of all caches which may be marked unavailable within the circuit breaker window.
+	CircuitBreakerCDNParam = "health.circuitbreaker.cdn"
+	// CircuitBreakerWindowParam is the Traffic Monitor config Parameter of the circuit breaker window, in milliseconds.
+	CircuitBreakerWindowParam = "health.circuitbreaker.window"
+)
+
+// DefaultCircuitBreakerWindow is the circuit breaker window, if the CircuitBreakerWindowParam is missing or invalid.
+const DefaultCircuitBreakerWindow = time.Minute
+
+// circuitBreakerCDNScope is the scope of the CDN-wide limit, which can't be the name of a Cache Group, because it contains a space.
+const circuitBreakerCDNScope = "the CDN"
+
+// CircuitBreaker limits how many caches may be marked unavailable within a window of time, per Cache Group and across the CDN, so a bad threshold doesn't make most of a CDN unavailable at once.
+// Caches beyond the limits are held available, until older transitions leave the window. It's safe for multiple goroutines.
+type CircuitBreaker struct {
+	downs   *[]circuitBreakerDown // the caches marked unavailable within the window, oldest first
+	tripped map[string]bool       // the scopes which have held caches since they last had room, so the event is only added once
+	m       *sync.Mutex
+}
+
+type circuitBreakerDown struct {
+	cacheGroup tc.CacheGroupName
+	time       time.Time
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker, in which no caches have been marked unavailable.
+func NewCircuitBreaker() CircuitBreaker {
+	return CircuitBreaker{downs: &[]circuitBreakerDown{}, tripped: map[string]bool{}, m: &sync.Mutex{}}
+}
+
+// circuitBreakerConfig is the configuration of a CircuitBreaker, from the Traffic Monitor config Parameters. A limit of 0 is disabled.
+type circuitBreakerConfig struct {
+	cacheGroupMax float64
+	cdnMax        float64
+	window        time.Duration
+}
+
+func getCircuitBreakerConfig(cfg map[string]interface{}) circuitBreakerConfig {
+	conf := circuitBreakerConfig{
+		cacheGroupMax: getConfigFloat(cfg, CircuitBreakerCacheGroupParam),
+		cdnMax:        getConfigFloat(cfg, CircuitBreakerCDNParam),
+		window:        time.Duration(getConfigFloat(cfg, CircuitBreakerWindowParam)) * time.Millisecond,
+	}
+	if conf.window <= 0 {
+		conf.window = DefaultCircuitBreakerWindow
+	}
+	return conf
+}
+
+// getConfigFloat returns the number of the given Traffic Monitor config Parameter, or 0 if it's missing or invalid.
+func getConfigFloat(cfg map[string]interface{}, param string) float64 {
+	switch val := cfg[param].(type) {
+	case float64:
+		return val
+	case string:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return 0
+}
+
+// maxDowns returns the number of caches of a scope with the given number of caches which may be marked unavailable within the window. At least one always may, so small Cache Groups can't be held available forever.
+func maxDowns(fraction float64, caches int) int {
+	if n := int(fraction * float64(caches)); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// hold returns whether the given cache, which is about to be marked unavailable, must be held available instead, and why. If it isn't held, it's recorded as marked unavailable now.
+// The cacheGroupCaches and cdnCaches are the numbers of caches in the cache's Cache Group, and in the CDN. If the circuit breaker trips, a critical event is added.
+func (b CircuitBreaker) hold(cfg map[string]interface{}, cacheName tc.CacheName, cacheGroup tc.CacheGroupName, cacheGroupCaches int, cdnCaches int, events ThreadsafeEvents, now time.Time) (bool, string) {
+	conf := getCircuitBreakerConfig(cfg)
+	if conf.cacheGroupMax <= 0 && conf.cdnMax <= 0 {
+		return false, ""
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	downs := *b.downs
+	for len(downs) > 0 && now.Sub(downs[0].time) >= conf.window {
+		downs = downs[1:]
+	}
+	*b.downs = downs
+
+	cacheGroupDowns := 0
+	for _, down := range downs {
+		if down.cacheGroup == cacheGroup {
+			cacheGroupDowns++
+		}
+	}
+
+	scope, scopeDowns, scopeCaches := "", 0, 0
+	switch {
+	case conf.cacheGroupMax > 0 && cacheGroupDowns >= maxDowns(conf.cacheGroupMax, cacheGroupCaches):
+		scope, scopeDowns, scopeCaches = "Cache Group "+string(cacheGroup), cacheGroupDowns, cacheGroupCaches
+	case conf.cdnMax > 0 && len(downs) >= maxDowns(conf.cdnMax, cdnCaches):
+		scope, scopeDowns, scopeCaches = circuitBreakerCDNScope, len(downs), cdnCaches
+	}
+
+	if scope == "" {
+		*b.downs = append(downs, circuitBreakerDown{cacheGroup: cacheGroup, time: now})
+		delete(b.tripped, "Cache Group "+string(cacheGroup))
+		delete(b.tripped, circuitBreakerCDNScope)
+		return false, ""
+	}
+
+	why := fmt.Sprintf("circuit breaker: %d of %d caches in %s already marked unavailable in the last %v", scopeDowns, scopeCaches, scope, conf.window)
+	if !b.tripped[scope] {
+		b.tripped[scope] = true
+		log.Errorf("Circuit breaker tripped: %d of %d caches in %s marked unavailable in the last %v, holding %s and any further caches available\n", scopeDowns, scopeCaches, scope, conf.window, cacheName)
+		events.Add(Event{Time: Time(now), Description: "CRITICAL: " + why + ", holding further caches available", Name: string(cacheName), Hostname: string(cacheName), Type: "CIRCUIT_BREAKER", Available: true})
+	}
+	return true, why
+}
@@ -0,0 +1,101 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestCircuitBreakerCacheGroup(t *testing.T) {
+	cfg := map[string]interface{}{
+		CircuitBreakerCacheGroupParam: "0.5",
+		CircuitBreakerWindowParam:     float64(60000),
+	}
+	b := NewCircuitBreaker()
+	events := NewThreadsafeEvents(10)
+	now := time.Now()
+
+	// 2 of the 4 caches in cg0 may be marked unavailable within the window
+	for i, expected := range []bool{false, false, true, true} {
+		if held, why := b.hold(cfg, tc.CacheName("cache"), "cg0", 4, 100, events, now); held != expected {
+			t.Errorf("cache %d expected held %v, actual %v (%v)", i, expected, held, why)
+		}
+	}
+	if held, _ := b.hold(cfg, "other", "cg1", 4, 100, events, now); held {
+		t.Errorf("expected cache in another cachegroup not to be held")
+	}
+	if len(events.Get()) != 1 {
+		t.Errorf("expected a single event when the circuit breaker tripped, actual %v", len(events.Get()))
+	}
+
+	// once the first transitions leave the window, more caches may be marked unavailable
+	if held, why := b.hold(cfg, "cache", "cg0", 4, 100, events, now.Add(time.Minute)); held {
+		t.Errorf("expected cache not to be held after the window, actual held: %v", why)
+	}
+}
+
+func TestCircuitBreakerCDN(t *testing.T) {
+	cfg := map[string]interface{}{
+		CircuitBreakerCDNParam: float64(0.1),
+	}
+	b := NewCircuitBreaker()
+	events := NewThreadsafeEvents(10)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if held, why := b.hold(cfg, "cache", tc.CacheGroupName(fmt.Sprintf("cg%d", i)), 10, 30, events, now); held {
+			t.Errorf("cache %d expected not held, actual held: %v", i, why)
+		}
+	}
+	if held, _ := b.hold(cfg, "cache", "cg3", 10, 30, events, now.Add(DefaultCircuitBreakerWindow-time.Second)); !held {
+		t.Errorf("expected the 4th cache of 30 to be held by a 0.1 CDN limit")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker()
+	events := NewThreadsafeEvents(10)
+	for i := 0; i < 10; i++ {
+		if held, _ := b.hold(map[string]interface{}{}, "cache", "cg0", 10, 10, events, time.Now()); held {
+			t.Fatalf("expected a circuit breaker without parameters never to hold")
+		}
+	}
+}
+
+func TestMaxDowns(t *testing.T) {
+	for _, test := range []struct {
+		fraction float64
+		caches   int
+		expected int
+	}{
+		{0.5, 10, 5},
+		{0.25, 10, 2},
+		{0.1, 2, 1},
+		{1, 3, 3},
+	} {
+		if actual := maxDowns(test.fraction, test.caches); actual != test.expected {
+			t.Errorf("maxDowns(%v, %v) expected %v, actual %v", test.fraction, test.caches, test.expected, actual)
+		}
+	}
+}
@@ -89,3 +89,16 @@ func (o *ThreadsafeEvents) Add(e Event) {
 	*o.nextIndex++
 	o.m.Unlock()
 }
+
+// Load replaces the events with the given events, which must be ordered newest first, like those returned by Get, and continues indexing new events after the newest. It's for restoring events, so unlike Add, the events aren't logged. This MUST NOT be called by multiple threads, or concurrently with Add.
+func (o *ThreadsafeEvents) Load(events []Event) {
+	if uint64(len(events)) > o.max {
+		events = events[:o.max]
+	}
+	o.m.Lock()
+	*o.events = copyEvents(events)
+	if len(events) > 0 {
+		*o.nextIndex = events[0].Index + 1
+	}
+	o.m.Unlock()
+}
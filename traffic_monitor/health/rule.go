@@ -0,0 +1,533 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Rule is a parsed health rule expression. A rule combines stats with arithmetic (+ - * /), comparisons (< <= > >= == !=), and boolean logic (&& || !), for example `bandwidth > 0.9*maxKbps && loadavg > 20`. A cache is unhealthy when a rule is true.
+//
+// Stats are referenced by name. Names containing characters other than letters, digits, underscores, and periods may be double-quoted, for example `"plugin.remap_stats.my-ds.example.net.status_5xx" > 100`.
+//
+// The functions avg(stat, n), min(stat, n), and max(stat, n) aggregate the last n polls of a stat.
+//
+// Booleans are numbers: comparisons are 1 if true and 0 if false, and any nonzero number is true.
+type Rule struct {
+	Expr  string
+	root  ruleNode
+	stats []string
+}
+
+// RuleStats provides the stat values a Rule is evaluated against.
+type RuleStats interface {
+	// Latest returns the latest value of the given stat, and false if the stat doesn't exist or isn't a number.
+	Latest(stat string) (float64, bool)
+	// Window returns up to the given number of the most recent values of the given stat, most recent first, and false if the stat doesn't exist or isn't a number.
+	Window(stat string, polls int) ([]float64, bool)
+}
+
+// ErrRuleStatMissing is returned when evaluating a rule whose stats aren't all available, for example, a rule using a stat the health poller doesn't have.
+var ErrRuleStatMissing = errors.New("rule stat missing")
+
+// Stats returns the names of the stats used by the rule, in the order they first appear.
+func (r *Rule) Stats() []string {
+	return r.stats
+}
+
+// Eval returns whether the rule is true for the given stats. If any stat the rule uses is missing, ErrRuleStatMissing is returned.
+func (r *Rule) Eval(stats RuleStats) (bool, error) {
+	val, err := r.root.eval(stats)
+	if err != nil {
+		return false, err
+	}
+	return val != 0, nil
+}
+
+// ruleCache is the parsed Rule of each expression, so rules aren't parsed on every poll.
+var ruleCache = sync.Map{} // map[string]*Rule
+
+// GetRule returns the parsed Rule for the given expression, parsing it if it hasn't been already.
+func GetRule(expr string) (*Rule, error) {
+	if rule, ok := ruleCache.Load(expr); ok {
+		return rule.(*Rule), nil
+	}
+	rule, err := ParseRule(expr)
+	if err != nil {
+		return nil, err
+	}
+	ruleCache.Store(expr, rule)
+	return rule, nil
+}
+
+// ParseRule parses the given health rule expression.
+func ParseRule(expr string) (*Rule, error) {
+	tokens, err := lexRule(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens, stats: map[string]struct{}{}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != ruleTokenEOF {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", p.peek().text, p.peek().pos)
+	}
+	return &Rule{Expr: expr, root: root, stats: p.statOrder}, nil
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokenEOF ruleTokenKind = iota
+	ruleTokenNum
+	ruleTokenIdent
+	ruleTokenOp
+	ruleTokenLParen
+	ruleTokenRParen
+	ruleTokenComma
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// ruleOps are the operators, longest first so two-character operators aren't lexed as one-character operators.
+var ruleOps = []string{"&&", "||", "<=", ">=", "==", "!=", "<", ">", "+", "-", "*", "/", "!"}
+
+func isRuleIdentRune(r rune, first bool) bool {
+	if r == '_' || unicode.IsLetter(r) {
+		return true
+	}
+	return !first && (r == '.' || unicode.IsDigit(r))
+}
+
+func lexRule(expr string) ([]ruleToken, error) {
+	tokens := []ruleToken{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, ruleToken{kind: ruleTokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, ruleToken{kind: ruleTokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, ruleToken{kind: ruleTokenComma, text: ",", pos: i})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quoted stat name at position %d", i)
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("empty quoted stat name at position %d", i)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenIdent, text: string(runes[i+1 : end]), pos: i})
+			i = end + 1
+		case unicode.IsDigit(r) || r == '.':
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.' || runes[end] == 'e' || runes[end] == 'E' || ((runes[end] == '-' || runes[end] == '+') && (runes[end-1] == 'e' || runes[end-1] == 'E'))) {
+				end++
+			}
+			num, err := strconv.ParseFloat(string(runes[i:end]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at position %d", string(runes[i:end]), i)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenNum, text: string(runes[i:end]), num: num, pos: i})
+			i = end
+		case isRuleIdentRune(r, true):
+			end := i
+			for end < len(runes) && isRuleIdentRune(runes[end], end == i) {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenIdent, text: string(runes[i:end]), pos: i})
+			i = end
+		default:
+			op := ""
+			for _, o := range ruleOps {
+				if strings.HasPrefix(string(runes[i:]), o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", r, i)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, ruleToken{kind: ruleTokenEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+type ruleParser struct {
+	tokens    []ruleToken
+	pos       int
+	stats     map[string]struct{}
+	statOrder []string
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ruleParser) next() ruleToken {
+	t := p.tokens[p.pos]
+	if t.kind != ruleTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// acceptOp consumes the next token and returns true if it's one of the given operators.
+func (p *ruleParser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != ruleTokenOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.next()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *ruleParser) expect(kind ruleTokenKind, text string) error {
+	t := p.next()
+	if t.kind != kind {
+		return fmt.Errorf("expected '%s' at position %d, got '%s'", text, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *ruleParser) addStat(stat string) {
+	if _, ok := p.stats[stat]; ok {
+		return
+	}
+	p.stats[stat] = struct{}{}
+	p.statOrder = append(p.statOrder, stat)
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseNot() (ruleNode, error) {
+	if _, ok := p.acceptOp("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return ruleUnary{op: "!", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return ruleBinary{op: op, left: left, right: right}, nil
+}
+
+func (p *ruleParser) parseSum() (ruleNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseProduct() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	if _, ok := p.acceptOp("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return ruleUnary{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *ruleParser) parsePrimary() (ruleNode, error) {
+	t := p.next()
+	switch t.kind {
+	case ruleTokenNum:
+		return ruleNum(t.num), nil
+	case ruleTokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(ruleTokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case ruleTokenIdent:
+		if p.peek().kind != ruleTokenLParen {
+			p.addStat(t.text)
+			return ruleStat(t.text), nil
+		}
+		return p.parseWindow(t)
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d", t.text, t.pos)
+}
+
+// parseWindow parses a windowed aggregate function call, after the function name.
+func (p *ruleParser) parseWindow(fn ruleToken) (ruleNode, error) {
+	aggregate, ok := ruleAggregates[fn.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s' at position %d", fn.text, fn.pos)
+	}
+	p.next() // (
+	stat := p.next()
+	if stat.kind != ruleTokenIdent {
+		return nil, fmt.Errorf("expected stat name as first argument of %s at position %d, got '%s'", fn.text, stat.pos, stat.text)
+	}
+	if err := p.expect(ruleTokenComma, ","); err != nil {
+		return nil, err
+	}
+	polls := p.next()
+	if polls.kind != ruleTokenNum || polls.num < 1 || polls.num != math.Trunc(polls.num) {
+		return nil, fmt.Errorf("expected positive integer number of polls as second argument of %s at position %d, got '%s'", fn.text, polls.pos, polls.text)
+	}
+	if err := p.expect(ruleTokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	p.addStat(stat.text)
+	return ruleWindow{stat: stat.text, polls: int(polls.num), aggregate: aggregate}, nil
+}
+
+type ruleNode interface {
+	eval(stats RuleStats) (float64, error)
+}
+
+type ruleNum float64
+
+func (n ruleNum) eval(stats RuleStats) (float64, error) { return float64(n), nil }
+
+type ruleStat string
+
+func (s ruleStat) eval(stats RuleStats) (float64, error) {
+	val, ok := stats.Latest(string(s))
+	if !ok {
+		return 0, ErrRuleStatMissing
+	}
+	return val, nil
+}
+
+var ruleAggregates = map[string]func(vals []float64) float64{
+	"avg": func(vals []float64) float64 {
+		sum := 0.0
+		for _, val := range vals {
+			sum += val
+		}
+		return sum / float64(len(vals))
+	},
+	"min": func(vals []float64) float64 {
+		min := vals[0]
+		for _, val := range vals[1:] {
+			min = math.Min(min, val)
+		}
+		return min
+	},
+	"max": func(vals []float64) float64 {
+		max := vals[0]
+		for _, val := range vals[1:] {
+			max = math.Max(max, val)
+		}
+		return max
+	},
+}
+
+type ruleWindow struct {
+	stat      string
+	polls     int
+	aggregate func(vals []float64) float64
+}
+
+func (w ruleWindow) eval(stats RuleStats) (float64, error) {
+	vals, ok := stats.Window(w.stat, w.polls)
+	if !ok || len(vals) == 0 {
+		return 0, ErrRuleStatMissing
+	}
+	return w.aggregate(vals), nil
+}
+
+type ruleUnary struct {
+	op      string
+	operand ruleNode
+}
+
+func (u ruleUnary) eval(stats RuleStats) (float64, error) {
+	val, err := u.operand.eval(stats)
+	if err != nil {
+		return 0, err
+	}
+	if u.op == "!" {
+		return ruleBool(val == 0), nil
+	}
+	return -val, nil
+}
+
+type ruleBinary struct {
+	op    string
+	left  ruleNode
+	right ruleNode
+}
+
+func (b ruleBinary) eval(stats RuleStats) (float64, error) {
+	left, err := b.left.eval(stats)
+	if err != nil {
+		return 0, err
+	}
+	// short-circuit, so a rule like `loadavg > 20 && avg(some.stat, 5) > 1` doesn't need the stat unless the load is high
+	switch {
+	case b.op == "&&" && left == 0:
+		return 0, nil
+	case b.op == "||" && left != 0:
+		return 1, nil
+	}
+	right, err := b.right.eval(stats)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case "&&", "||":
+		return ruleBool(right != 0), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		return left / right, nil
+	case "<":
+		return ruleBool(left < right), nil
+	case "<=":
+		return ruleBool(left <= right), nil
+	case ">":
+		return ruleBool(left > right), nil
+	case ">=":
+		return ruleBool(left >= right), nil
+	case "==":
+		return ruleBool(left == right), nil
+	case "!=":
+		return ruleBool(left != right), nil
+	}
+	return 0, fmt.Errorf("invalid operator '%s'", b.op)
+}
+
+func ruleBool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
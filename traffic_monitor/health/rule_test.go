@@ -0,0 +1,136 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mockRuleStats map[string][]float64
+
+func (s mockRuleStats) Latest(stat string) (float64, bool) {
+	vals, ok := s.Window(stat, 1)
+	if !ok {
+		return 0, false
+	}
+	return vals[0], true
+}
+
+func (s mockRuleStats) Window(stat string, polls int) ([]float64, bool) {
+	vals, ok := s[stat]
+	if !ok || len(vals) == 0 {
+		return nil, false
+	}
+	if len(vals) > polls {
+		vals = vals[:polls]
+	}
+	return vals, true
+}
+
+func TestRuleEval(t *testing.T) {
+	stats := mockRuleStats{
+		"bandwidth":  {950, 100, 100},
+		"maxKbps":    {1000},
+		"loadavg":    {25},
+		"my-ds.5xx":  {7},
+		"conns.curr": {10, 20, 30, 40},
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{`bandwidth > 0.9*maxKbps && loadavg > 20`, true},
+		{`bandwidth > 0.9*maxKbps && loadavg > 30`, false},
+		{`bandwidth > 0.99*maxKbps || loadavg >= 25`, true},
+		{`!(loadavg > 20)`, false},
+		{`(bandwidth - 50) / maxKbps == 0.9`, true},
+		{`-loadavg < -24`, true},
+		{`1 + 2 * 3 == 7`, true},
+		{`"my-ds.5xx" != 0`, true},
+		{`avg(conns.curr, 2) == 15`, true},
+		{`avg(conns.curr, 10) == 25`, true},
+		{`max(conns.curr, 3) == 30 && min(conns.curr, 3) == 10`, true},
+		{`avg(bandwidth, 3) > 0.9*maxKbps`, false},
+		{`loadavg > 100 && missing > 1`, false}, // short-circuits, so the missing stat isn't needed
+		{`loadavg`, true},
+		{`1.5e3 > bandwidth`, true},
+	}
+
+	for _, test := range tests {
+		rule, err := ParseRule(test.expr)
+		if err != nil {
+			t.Errorf("parsing '%s' expected no error, actual: %v", test.expr, err)
+			continue
+		}
+		actual, err := rule.Eval(stats)
+		if err != nil {
+			t.Errorf("evaluating '%s' expected no error, actual: %v", test.expr, err)
+		} else if actual != test.expected {
+			t.Errorf("evaluating '%s' expected %v, actual %v", test.expr, test.expected, actual)
+		}
+	}
+}
+
+func TestRuleEvalMissingStat(t *testing.T) {
+	rule, err := ParseRule(`loadavg > 20 && avg(some.stat, 5) > 1`)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if _, err := rule.Eval(mockRuleStats{"loadavg": {25}}); err != ErrRuleStatMissing {
+		t.Errorf("expected ErrRuleStatMissing, actual: %v", err)
+	}
+}
+
+func TestRuleStats(t *testing.T) {
+	rule, err := ParseRule(`bandwidth > 0.9*maxKbps && avg(proxy.process.http.current_client_connections, 5) > 1000 || bandwidth > 1`)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	expected := []string{"bandwidth", "maxKbps", "proxy.process.http.current_client_connections"}
+	if !reflect.DeepEqual(rule.Stats(), expected) {
+		t.Errorf("expected stats %v, actual %v", expected, rule.Stats())
+	}
+}
+
+func TestParseRuleInvalid(t *testing.T) {
+	invalid := []string{
+		``,
+		`loadavg >`,
+		`loadavg > 20 &&`,
+		`(loadavg > 20`,
+		`loadavg > 20)`,
+		`loadavg # 20`,
+		`loadavg 20`,
+		`median(loadavg, 5) > 1`,
+		`avg(loadavg) > 1`,
+		`avg(loadavg, 0) > 1`,
+		`avg(loadavg, 1.5) > 1`,
+		`avg(1, 5) > 1`,
+		`"unterminated > 1`,
+		`1.2.3 > 1`,
+	}
+	for _, expr := range invalid {
+		if _, err := ParseRule(expr); err == nil {
+			t.Errorf("parsing '%s' expected error, actual: nil", expr)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/alert"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+// alertNotificationQueueSize is the number of notifications which may be waiting to be sent, before new notifications are dropped.
+const alertNotificationQueueSize = 1000
+
+// StartAlertManager starts the goroutine which evaluates the alert rules against the combined states and latest stats every interval, and adds events when alerts fire and resolve. If the alerter is nil, alerting is disabled, and nothing is started.
+// Notifications are sent by a separate goroutine, so a slow notifier doesn't delay evaluations.
+func StartAlertManager(
+	alerter *alert.Alerter,
+	interval time.Duration,
+	events health.ThreadsafeEvents,
+	combinedStates peer.CRStatesThreadsafe,
+	toData todata.TODataThreadsafe,
+	statInfoHistory threadsafe.ResultInfoHistory,
+	monitorConfig threadsafe.TrafficMonitorConfigMap,
+) {
+	if alerter == nil {
+		return
+	}
+	notifications := make(chan alert.Notification, alertNotificationQueueSize)
+	go func() {
+		for notification := range notifications {
+			if err := alerter.Notify(notification); err != nil {
+				log.Errorf("sending alert %v %v notification: %v\n", notification.Rule, notification.Subject, err)
+			}
+		}
+	}()
+	go func() {
+		tick := time.NewTicker(interval)
+		for now := range tick.C {
+			state := alert.NewState(now, combinedStates.GetCaches(), toData.Get(), statInfoHistory.Get(), monitorConfig.Get())
+			for _, notification := range alerter.Evaluate(state) {
+				events.Add(health.Event{Time: health.Time(notification.Time), Description: "Alert " + notification.Rule + " " + notification.Status + ": " + notification.Description, Name: notification.Subject, Hostname: notification.Subject, Type: "ALERT", Available: notification.Status == alert.StatusResolved})
+				select {
+				case notifications <- notification:
+				default:
+					log.Errorf("dropping alert %v %v notification: %v notifications are already waiting to be sent\n", notification.Rule, notification.Subject, alertNotificationQueueSize)
+				}
+			}
+		}
+	}()
+}
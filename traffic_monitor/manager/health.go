@@ -28,6 +28,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
@@ -48,6 +49,8 @@ func StartHealthResultManager(
 	cfg config.Config,
 	events health.ThreadsafeEvents,
 	localCacheStatus threadsafe.CacheAvailableStatus,
+	syntheticResults synthetic.ResultsThreadsafe,
+	circuitBreaker health.CircuitBreaker,
 ) (threadsafe.DurationMap, threadsafe.ResultHistory) {
 	lastHealthDurations := threadsafe.NewDurationMap()
 	healthHistory := threadsafe.NewResultHistory()
@@ -63,6 +66,8 @@ func StartHealthResultManager(
 		errorCount,
 		events,
 		localCacheStatus,
+		syntheticResults,
+		circuitBreaker,
 		cfg,
 	)
 	return lastHealthDurations, healthHistory
@@ -80,6 +85,8 @@ func healthResultManagerListen(
 	errorCount threadsafe.Uint,
 	events health.ThreadsafeEvents,
 	localCacheStatus threadsafe.CacheAvailableStatus,
+	syntheticResults synthetic.ResultsThreadsafe,
+	circuitBreaker health.CircuitBreaker,
 	cfg config.Config,
 ) {
 	lastHealthEndTimes := map[tc.CacheName]time.Time{}
@@ -101,6 +108,8 @@ func healthResultManagerListen(
 			lastHealthEndTimes,
 			healthHistory,
 			results,
+			syntheticResults,
+			circuitBreaker,
 			cfg,
 		)
 	}
@@ -147,6 +156,8 @@ func processHealthResult(
 	lastHealthEndTimes map[tc.CacheName]time.Time,
 	healthHistory threadsafe.ResultHistory,
 	results []cache.Result,
+	syntheticResults synthetic.ResultsThreadsafe,
+	circuitBreaker health.CircuitBreaker,
 	cfg config.Config,
 ) {
 	if len(results) == 0 {
@@ -186,7 +197,7 @@ func processHealthResult(
 
 	pollerName := "health"
 	statResultHistoryNil := (*threadsafe.ResultStatHistory)(nil) // health poller doesn't have stats
-	health.CalcAvailability(results, pollerName, statResultHistoryNil, monitorConfigCopy, toDataCopy, localCacheStatusThreadsafe, localStates, events, cfg.CachePollingProtocol)
+	health.CalcAvailability(results, pollerName, statResultHistoryNil, monitorConfigCopy, toDataCopy, localCacheStatusThreadsafe, localStates, events, syntheticResults, circuitBreaker, cfg.CachePollingProtocol)
 
 	healthHistory.Set(healthHistoryCopy)
 	// TODO determine if we should combineCrStates() here
@@ -0,0 +1,71 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/persist"
+)
+
+// HistoryStoreSnapshotInterval is how often events and local states are written to the history store, and written stats are flushed to disk. It's the most history which may be lost when Traffic Monitor stops.
+const HistoryStoreSnapshotInterval = 5 * time.Second
+
+// restoreHistoryState restores the events and local states stored in the given history store, so they're reported from the moment Traffic Monitor starts, instead of starting empty and reporting every cache unavailable until it's polled. It MUST be called before any events are added, or local states are set.
+func restoreHistoryState(historyStore *persist.Store, events health.ThreadsafeEvents, localStates peer.CRStatesThreadsafe, maxEvents uint64) {
+	if historyStore == nil {
+		return
+	}
+	storedEvents, crStates, err := historyStore.LoadState(time.Now(), maxEvents)
+	if err != nil {
+		log.Errorln("restoring events and states from history store: " + err.Error())
+		return
+	}
+	events.Load(storedEvents)
+	if crStates == nil {
+		return
+	}
+	for cacheName, available := range crStates.Caches {
+		localStates.AddCache(cacheName, available)
+	}
+	for dsName, ds := range crStates.DeliveryService {
+		localStates.SetDeliveryService(dsName, ds)
+	}
+	log.Infof("restored %v events and the states of %v caches and %v delivery services from history store\n", len(storedEvents), len(crStates.Caches), len(crStates.DeliveryService))
+}
+
+// StartHistoryStoreManager periodically writes the events and local states to the given history store, and flushes the stats written to it. If the store is nil, it does nothing.
+func StartHistoryStoreManager(historyStore *persist.Store, events health.ThreadsafeEvents, localStates peer.CRStatesThreadsafe) {
+	if historyStore == nil {
+		return
+	}
+	go func() {
+		tick := time.NewTicker(HistoryStoreSnapshotInterval)
+		defer tick.Stop()
+		for now := range tick.C {
+			if err := historyStore.AddSnapshot(events.Get(), localStates.Get(), now); err != nil {
+				log.Errorln("writing history store snapshot: " + err.Error())
+			}
+		}
+	}()
+}
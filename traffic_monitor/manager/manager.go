@@ -29,12 +29,16 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/alert"
 	"github.com/apache/trafficcontrol/traffic_monitor/cache"
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/handler"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/persist"
 	"github.com/apache/trafficcontrol/traffic_monitor/poller"
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -60,16 +64,32 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 	monitorConfigPoller := poller.NewMonitorConfig(cfg.MonitorConfigPollingInterval)
 	peerHandler := peer.NewHandler()
 	peerPoller := poller.NewCache(cfg.PeerPollingInterval, false, peerHandler, cfg, appData, cfg.PeerPollingProtocol)
+	syntheticHandler := synthetic.NewHandler()
+	syntheticPoller := poller.NewCache(synthetic.DefaultInterval, false, syntheticHandler, cfg, appData, cfg.CachePollingProtocol)
 
 	go monitorConfigPoller.Poll()
 	go cacheHealthPoller.Poll()
 	go cacheStatPoller.Poll()
 	go peerPoller.Poll()
+	go syntheticPoller.Poll()
 
 	events := health.NewThreadsafeEvents(cfg.MaxEvents)
 
+	historyStore, err := persist.Open(cfg.HistoryStoreDir, cfg.HistoryStoreRetention, cfg.HistoryStoreSegment)
+	if err != nil {
+		return fmt.Errorf("opening history store '%v': %v", cfg.HistoryStoreDir, err)
+	}
+	restoreHistoryState(historyStore, events, localStates, cfg.MaxEvents)
+
+	alerter, err := alert.New(cfg.AlertRules, cfg.AlertNotifiers, appData.Hostname, cfg.HTTPTimeout)
+	if err != nil {
+		return fmt.Errorf("configuring alerts: %v", err)
+	}
+
 	cachesChanged := make(chan struct{})
 	peerStates := peer.NewCRStatesPeersThreadsafe(cfg.PeerOptimisticQuorumMin) // each peer's last state is saved in this map
+	syntheticResults := synthetic.NewResultsThreadsafe()                       // the latest synthetic check of each delivery service through each cache
+	circuitBreaker := health.NewCircuitBreaker()                               // limits how many caches polling may mark unavailable at once
 
 	monitorConfig := StartMonitorConfigManager(
 		monitorConfigPoller.ConfigChannel,
@@ -78,15 +98,20 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		cacheStatPoller.ConfigChannel,
 		cacheHealthPoller.ConfigChannel,
 		peerPoller.ConfigChannel,
+		syntheticPoller.ConfigChannel,
 		monitorConfigPoller.IntervalChan,
 		cachesChanged,
 		cfg,
 		appData,
 		toSession,
 		toData,
+		syntheticResults,
 	)
 
-	combinedStates, combineStateFunc := StartStateCombiner(events, peerStates, localStates, toData)
+	peerConsensus := peer.NewConsensusThreadsafe()
+	crStatesStream := stream.New(CRStatesStreamSize)
+	dsStatsStream := stream.New(DSStatsStreamSize)
+	combinedStates, combineStateFunc := StartStateCombiner(events, peerStates, localStates, toData, peerConsensus, cfg.PeerOutlierThreshold, appData.Hostname, crStatesStream)
 
 	StartPeerManager(
 		peerHandler.ResultChannel,
@@ -95,6 +120,12 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		combineStateFunc,
 	)
 
+	StartSyntheticManager(
+		syntheticHandler.ResultChannel,
+		syntheticResults,
+		events,
+	)
+
 	statInfoHistory, statResultHistory, statMaxKbpses, _, lastKbpsStats, dsStats, unpolledCaches, localCacheStatus := StartStatHistoryManager(
 		cacheStatHandler.ResultChan(),
 		localStates,
@@ -106,8 +137,15 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		monitorConfig,
 		events,
 		combineStateFunc,
+		syntheticResults,
+		circuitBreaker,
+		historyStore,
 	)
 
+	StartHistoryStoreManager(historyStore, events, localStates)
+	StartAlertManager(alerter, cfg.AlertInterval, events, combinedStates, toData, statInfoHistory, monitorConfig)
+	StartDSStatsStreamManager(dsStatsStream, cfg.StreamDSStatsInterval, dsStats, toData)
+
 	lastHealthDurations, healthHistory := StartHealthResultManager(
 		cacheHealthHandler.ResultChan(),
 		toData,
@@ -119,6 +157,8 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		cfg,
 		events,
 		localCacheStatus,
+		syntheticResults,
+		circuitBreaker,
 	)
 
 	StartOpsConfigManager(
@@ -146,6 +186,11 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		localCacheStatus,
 		unpolledCaches,
 		monitorConfig,
+		syntheticResults,
+		historyStore,
+		peerConsensus,
+		crStatesStream,
+		dsStatsStream,
 		cfg,
 	)
 
@@ -33,6 +33,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
 	"github.com/apache/trafficcontrol/traffic_monitor/poller"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -45,10 +46,11 @@ type PollIntervals struct {
 	PeerNoKeepAlive   bool
 	Stat              time.Duration
 	StatNoKeepAlive   bool
+	Synthetic         time.Duration
 	TO                time.Duration
 }
 
-// getPollIntervals reads the Traffic Ops Client monitorConfig structure, and parses and returns the health, peer, stat, and TrafficOps poll intervals
+// getPollIntervals reads the Traffic Ops Client monitorConfig structure, and parses and returns the health, peer, stat, synthetic check, and TrafficOps poll intervals
 func getIntervals(monitorConfig tc.LegacyTrafficMonitorConfigMap, cfg config.Config, logMissingParams bool) (PollIntervals, error) {
 	intervals := PollIntervals{}
 	peerPollIntervalI, peerPollIntervalExists := monitorConfig.Config["peers.polling.interval"]
@@ -106,6 +108,8 @@ func getIntervals(monitorConfig tc.LegacyTrafficMonitorConfigMap, cfg config.Con
 	intervals.HealthNoKeepAlive = getNoKeepAlive("health.polling.keepalive")
 	intervals.StatNoKeepAlive = getNoKeepAlive("stat.polling.keepalive")
 
+	intervals.Synthetic = synthetic.GetInterval(monitorConfig.Config)
+
 	multiplyByRatio := func(i time.Duration) time.Duration {
 		return time.Duration(float64(i) * PollIntervalRatio)
 	}
@@ -114,6 +118,7 @@ func getIntervals(monitorConfig tc.LegacyTrafficMonitorConfigMap, cfg config.Con
 	intervals.Health = multiplyByRatio(intervals.Health)
 	intervals.Peer = multiplyByRatio(intervals.Peer)
 	intervals.Stat = multiplyByRatio(intervals.Stat)
+	intervals.Synthetic = multiplyByRatio(intervals.Synthetic)
 	return intervals, nil
 }
 
@@ -125,12 +130,14 @@ func StartMonitorConfigManager(
 	statURLSubscriber chan<- poller.CachePollerConfig,
 	healthURLSubscriber chan<- poller.CachePollerConfig,
 	peerURLSubscriber chan<- poller.CachePollerConfig,
+	syntheticURLSubscriber chan<- poller.CachePollerConfig,
 	toIntervalSubscriber chan<- time.Duration,
 	cachesChangeSubscriber chan<- struct{},
 	cfg config.Config,
 	staticAppData config.StaticAppData,
 	toSession towrap.ITrafficOpsSession,
 	toData todata.TODataThreadsafe,
+	syntheticResults synthetic.ResultsThreadsafe,
 ) threadsafe.TrafficMonitorConfigMap {
 	monitorConfig := threadsafe.NewTrafficMonitorConfigMap()
 	go monitorConfigListen(monitorConfig,
@@ -140,12 +147,14 @@ func StartMonitorConfigManager(
 		statURLSubscriber,
 		healthURLSubscriber,
 		peerURLSubscriber,
+		syntheticURLSubscriber,
 		toIntervalSubscriber,
 		cachesChangeSubscriber,
 		cfg,
 		staticAppData,
 		toSession,
 		toData,
+		syntheticResults,
 	)
 	return monitorConfig
 }
@@ -195,12 +204,14 @@ func monitorConfigListen(
 	statURLSubscriber chan<- poller.CachePollerConfig,
 	healthURLSubscriber chan<- poller.CachePollerConfig,
 	peerURLSubscriber chan<- poller.CachePollerConfig,
+	syntheticURLSubscriber chan<- poller.CachePollerConfig,
 	toIntervalSubscriber chan<- time.Duration,
 	cachesChangeSubscriber chan<- struct{},
 	cfg config.Config,
 	staticAppData config.StaticAppData,
 	toSession towrap.ITrafficOpsSession,
 	toData todata.TODataThreadsafe,
+	syntheticResults synthetic.ResultsThreadsafe,
 ) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -225,6 +236,7 @@ func monitorConfigListen(
 		statURLs := map[string]poller.PollConfig{}
 		peerURLs := map[string]poller.PollConfig{}
 		caches := map[string]string{}
+		statMappings := map[tc.CacheName]*cache.StatMapping{}
 
 		intervals, err := getIntervals(monitorConfig, cfg, logMissingIntervalParams)
 		logMissingIntervalParams = false // only log missing parameters once
@@ -263,6 +275,12 @@ func monitorConfigListen(
 				log.Infof("health.polling.format for '%v' is empty, using default '%v'", srv.HostName, format)
 			}
 
+			if mapping, err := cache.NewStatMapping(format, monitorConfig.Profile[srv.Profile].Parameters.StatMappings); err != nil {
+				log.Errorf("profile %v health.polling.stat Parameters are invalid for format '%v', can't map stats of '%v': %v", srv.Profile, format, srv.HostName, err)
+			} else if mapping != nil {
+				statMappings[tc.CacheName(srv.HostName)] = mapping
+			}
+
 			pollType := monitorConfig.Profile[srv.Profile].Parameters.HealthPollingType
 			if pollType == "" {
 				pollType = poller.DefaultPollerType
@@ -299,9 +317,20 @@ func monitorConfigListen(
 			peerSet[tc.TrafficMonitorName(srv.HostName)] = struct{}{}
 		}
 
+		cache.SetStatMappings(statMappings)
+
 		statURLSubscriber <- poller.CachePollerConfig{Urls: statURLs, PollingProtocol: cfg.CachePollingProtocol, Interval: intervals.Stat, NoKeepAlive: intervals.StatNoKeepAlive}
 		healthURLSubscriber <- poller.CachePollerConfig{Urls: healthURLs, PollingProtocol: cfg.CachePollingProtocol, Interval: intervals.Health, NoKeepAlive: intervals.HealthNoKeepAlive}
 		peerURLSubscriber <- poller.CachePollerConfig{Urls: peerURLs, PollingProtocol: cfg.PeerPollingProtocol, Interval: intervals.Peer, NoKeepAlive: intervals.PeerNoKeepAlive}
+
+		syntheticURLs := getSyntheticURLs(monitorConfig, toData.Get())
+		syntheticURLSubscriber <- poller.CachePollerConfig{Urls: syntheticURLs, PollingProtocol: cfg.CachePollingProtocol, Interval: intervals.Synthetic}
+		syntheticPollIDs := map[string]struct{}{}
+		for id := range syntheticURLs {
+			syntheticPollIDs[id] = struct{}{}
+		}
+		syntheticResults.Retain(syntheticPollIDs)
+
 		toIntervalSubscriber <- intervals.TO
 		peerStates.SetTimeout((intervals.Peer + cfg.HTTPTimeout) * 2)
 		peerStates.SetPeers(peerSet)
@@ -334,6 +363,31 @@ func monitorConfigListen(
 	}
 }
 
+// getSyntheticURLs returns the poll configs of the synthetic checks of each Delivery Service with a check URL, through each of its caches which isn't OFFLINE, keyed on the poll ID of the Delivery Service and cache.
+func getSyntheticURLs(monitorConfig tc.LegacyTrafficMonitorConfigMap, toData todata.TOData) map[string]poller.PollConfig {
+	urls := map[string]poller.PollConfig{}
+	timeout := synthetic.GetTimeout(monitorConfig.Config)
+	for ds, checkURL := range synthetic.GetCheckURLs(monitorConfig.Config) {
+		if _, ok := monitorConfig.DeliveryService[string(ds)]; !ok {
+			log.Warnf("monitor config has a synthetic check URL for delivery service '%s', which doesn't exist; not checking", ds)
+			continue
+		}
+		for _, cacheName := range toData.DeliveryServiceServers[ds] {
+			srv, ok := monitorConfig.TrafficServer[string(cacheName)]
+			if !ok || tc.CacheStatusFromString(srv.ServerStatus) == tc.CacheStatusOffline {
+				continue
+			}
+			url4, url6, host, err := synthetic.CreatePollURLs(checkURL, srv)
+			if err != nil {
+				log.Errorf("monitor config delivery service '%s' synthetic check URL '%s' is invalid, not checking: %v", ds, checkURL, err)
+				break
+			}
+			urls[synthetic.PollID(ds, cacheName)] = poller.PollConfig{URL: url4, URLv6: url6, Host: host, Timeout: timeout, PollType: poller.PollerTypeSynthetic}
+		}
+	}
+	return urls
+}
+
 // createServerHealthPollURLs takes the template pollingURLStr, and replaces variables with data from srv, and returns the polling URL for srv.
 func createServerHealthPollURLs(pollingURLStr string, srv tc.LegacyTrafficServer) (string, string) {
 	pollingURL4Str := ""
@@ -37,7 +37,10 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/handler"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/persist"
 	"github.com/apache/trafficcontrol/traffic_monitor/srvhttp"
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -73,6 +76,11 @@ func StartOpsConfigManager(
 	localCacheStatus threadsafe.CacheAvailableStatus,
 	unpolledCaches threadsafe.UnpolledCaches,
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
+	syntheticResults synthetic.ResultsThreadsafe,
+	historyStore *persist.Store,
+	peerConsensus peer.ConsensusThreadsafe,
+	crStatesStream *stream.Stream,
+	dsStatsStream *stream.Stream,
 	cfg config.Config,
 ) (threadsafe.OpsConfig, error) {
 
@@ -130,6 +138,12 @@ func StartOpsConfigManager(
 			lastStats,
 			unpolledCaches,
 			monitorConfig,
+			syntheticResults,
+			historyStore,
+			peerConsensus,
+			crStatesStream,
+			dsStatsStream,
+			cfg.ServeWriteTimeout,
 		)
 
 		// If the HTTPS Listener is defined in the traffic_ops.cfg file then it creates the HTTPS endpoint and the corresponding HTTP endpoint as a redirect
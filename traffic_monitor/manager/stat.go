@@ -31,6 +31,8 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/ds"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/persist"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
@@ -69,6 +71,9 @@ func StartStatHistoryManager(
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
 	events health.ThreadsafeEvents,
 	combineState func(),
+	syntheticResults synthetic.ResultsThreadsafe,
+	circuitBreaker health.CircuitBreaker,
+	historyStore *persist.Store,
 ) (threadsafe.ResultInfoHistory, threadsafe.ResultStatHistory, threadsafe.CacheKbpses, threadsafe.DurationMap, threadsafe.LastStats, threadsafe.DSStatsReader, threadsafe.UnpolledCaches, threadsafe.CacheAvailableStatus) {
 	statInfoHistory := threadsafe.NewResultInfoHistory()
 	statResultHistory := threadsafe.NewResultStatHistory()
@@ -85,6 +90,18 @@ func StartStatHistoryManager(
 	lastResults := map[tc.CacheName]cache.Result{}
 	overrideMap := map[tc.CacheName]bool{}
 
+	// restore the stat history from before Traffic Monitor restarted, and the last result of each cache, so kbps is computed from the first poll.
+	err := historyStore.LoadStats(time.Now(), func(stored persist.StatResult) {
+		result := cache.Result{ID: string(stored.Cache), Time: stored.Time, Miscellaneous: stored.Stats, Vitals: stored.Vitals}
+		if err := statResultHistory.Add(result, stored.Limit); err != nil {
+			log.Errorf("restoring stat history of %v: %v\n", stored.Cache, err)
+		}
+		lastResults[stored.Cache] = result
+	})
+	if err != nil {
+		log.Errorln("restoring stat history from history store: " + err.Error())
+	}
+
 	haveCachesChanged := func() bool {
 		select {
 		case <-cachesChanged:
@@ -98,7 +115,7 @@ func StartStatHistoryManager(
 		if haveCachesChanged() {
 			unpolledCaches.SetNewCaches(getNewCaches(localStates, monitorConfig))
 		}
-		processStatResults(results, statInfoHistory, statResultHistory, statMaxKbpses, combinedStates, lastStats, toData.Get(), errorCount, dsStats, lastStatEndTimes, lastStatDurations, unpolledCaches, monitorConfig.Get(), precomputedData, lastResults, localStates, events, localCacheStatus, overrideMap, combineState, cfg.CachePollingProtocol)
+		processStatResults(results, statInfoHistory, statResultHistory, statMaxKbpses, combinedStates, lastStats, toData.Get(), errorCount, dsStats, lastStatEndTimes, lastStatDurations, unpolledCaches, monitorConfig.Get(), precomputedData, lastResults, localStates, events, localCacheStatus, overrideMap, combineState, syntheticResults, circuitBreaker, historyStore, cfg.CachePollingProtocol)
 	}
 
 	go func() {
@@ -254,6 +271,9 @@ func processStatResults(
 	localCacheStatusThreadsafe threadsafe.CacheAvailableStatus,
 	overrideMap map[tc.CacheName]bool,
 	combineState func(),
+	syntheticResults synthetic.ResultsThreadsafe,
+	circuitBreaker health.CircuitBreaker,
+	historyStore *persist.Store,
 	pollingProtocol config.PollingProtocol,
 ) {
 	if len(results) == 0 {
@@ -291,6 +311,9 @@ func processStatResults(
 		if err := statResultHistoryThreadsafe.Add(result, maxStats); err != nil {
 			log.Errorf("Adding result from %v: %v\n", result.ID, err)
 		}
+		if err := historyStore.AddStatResult(result, maxStats); err != nil {
+			log.Errorf("Storing result from %v: %v\n", result.ID, err)
+		}
 		// Don't add errored maxes or precomputed DSStats
 		if result.Error == nil {
 			// max and precomputed always contain the latest result from each cache
@@ -320,7 +343,7 @@ func processStatResults(
 	}
 
 	pollerName := "stat"
-	health.CalcAvailability(results, pollerName, &statResultHistoryThreadsafe, mc, toData, localCacheStatusThreadsafe, localStates, events, pollingProtocol)
+	health.CalcAvailability(results, pollerName, &statResultHistoryThreadsafe, mc, toData, localCacheStatusThreadsafe, localStates, events, syntheticResults, circuitBreaker, pollingProtocol)
 	combineState()
 
 	endTime := time.Now()
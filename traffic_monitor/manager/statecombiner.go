@@ -29,11 +29,16 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+
+	"github.com/json-iterator/go"
 )
 
 // StartStateCombiner starts the State Combiner goroutine, and returns the threadsafe CombinedStates, and a func to signal to combine states.
-func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates peer.CRStatesThreadsafe, toData todata.TODataThreadsafe) (peer.CRStatesThreadsafe, func()) {
+// Each time states are combined, the local states are compared to the consensus of the available peers, which is set in peerConsensus. If more than outlierThreshold of the caches with a consensus disagree with the local states, this Traffic Monitor is degraded, and the combined states follow the peer consensus.
+// The changes of the combined states are published to crStatesStream.
+func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates peer.CRStatesThreadsafe, toData todata.TODataThreadsafe, peerConsensus peer.ConsensusThreadsafe, outlierThreshold float64, hostname string, crStatesStream *stream.Stream) (peer.CRStatesThreadsafe, func()) {
 	combinedStates := peer.NewCRStatesThreadsafe()
 
 	// the chan buffer just reduces the number of goroutines on our infinite buffer hack in combineState(), no real writer will block, since combineState() writes in a goroutine.
@@ -56,26 +61,64 @@ func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStates
 
 	go func() {
 		overrideMap := map[tc.CacheName]bool{}
+		publishedStates := tc.NewCRStates()
 		for range combineStateChan {
 			drain(combineStateChan)
-			combineCrStates(events, true, peerStates, localStates.Get(), combinedStates, overrideMap, toData.Get())
+			localCRStates := localStates.Get()
+			consensus := peer.NewConsensus(localCRStates, peerStates.AvailablePeerStates(), outlierThreshold)
+			compareConsensus(events, hostname, peerConsensus.Get(), consensus)
+			peerConsensus.Set(consensus)
+			combineCrStates(events, true, peerStates, localCRStates, combinedStates, overrideMap, toData.Get(), consensus)
+			publishedStates = publishCRStatesDelta(crStatesStream, publishedStates, combinedStates.Get())
 		}
 	}()
 
 	return combinedStates, combineState
 }
 
+// publishCRStatesDelta publishes the changes from the last published states to the given states, if any, and returns the states which are now published.
+func publishCRStatesDelta(crStatesStream *stream.Stream, published tc.CRStates, states tc.CRStates) tc.CRStates {
+	delta, changed := stream.DiffCRStates(published, states)
+	if !changed {
+		return published
+	}
+	json := jsoniter.ConfigFastest
+	bytes, err := json.Marshal(delta)
+	if err != nil {
+		log.Errorf("marshalling CRStates delta: %v\n", err)
+		return published
+	}
+	crStatesStream.Publish(bytes)
+	return states
+}
+
+// compareConsensus adds an event if this Traffic Monitor became, or stopped being, degraded.
+func compareConsensus(events health.ThreadsafeEvents, hostname string, previous peer.Consensus, consensus peer.Consensus) {
+	if previous.Degraded == consensus.Degraded {
+		return
+	}
+	description := fmt.Sprintf("Local states disagree with peer consensus on %d of %d caches; degraded, serving peer consensus", consensus.Disagreements, consensus.ConsensusCaches)
+	if !consensus.Degraded {
+		description = fmt.Sprintf("Local states disagree with peer consensus on %d of %d caches; no longer degraded", consensus.Disagreements, consensus.ConsensusCaches)
+	}
+	events.Add(health.Event{Time: health.Time(time.Now()), Description: description, Name: hostname, Hostname: hostname, Type: "PEER", Available: !consensus.Degraded})
+}
+
 func combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, events health.ThreadsafeEvents, peerOptimistic bool, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
 	overrideCondition := ""
 	available := false
 	ipv4Available := false
 	ipv6Available := false
+	weight := 1.0
 	override := overrideMap[cacheName]
 
 	if localCacheState.IsAvailable {
 		available = true // we don't care about the peers, we got a "good one", and we're optimistic
 		ipv4Available = localCacheState.Ipv4Available
 		ipv6Available = localCacheState.Ipv6Available
+		if localCacheState.Warming {
+			weight = localCacheState.Weight
+		}
 
 		if override {
 			overrideCondition = "cleared; healthy locally"
@@ -92,10 +135,17 @@ func combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, e
 			ipv4OnlineOnPeers := make([]string, 0)
 			ipv6OnlineOnPeers := make([]string, 0)
 
+			// a cache healthy on peers is as warm as it is on the peer where it's warmest
+			peerWeight := 0.0
 			for peer, peerCrStates := range peerStates.GetCrstates() {
 				if peerStates.GetPeerAvailability(peer) {
 					if peerCrStates.Caches[cacheName].IsAvailable {
 						onlineOnPeers = append(onlineOnPeers, peer.String())
+						if !peerCrStates.Caches[cacheName].Warming {
+							peerWeight = 1
+						} else if peerCrStates.Caches[cacheName].Weight > peerWeight {
+							peerWeight = peerCrStates.Caches[cacheName].Weight
+						}
 					}
 					if peerCrStates.Caches[cacheName].Ipv4Available {
 						ipv4OnlineOnPeers = append(ipv4OnlineOnPeers, peer.String())
@@ -108,6 +158,7 @@ func combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, e
 
 			if len(onlineOnPeers) > 0 {
 				available = true
+				weight = peerWeight
 				ipv4Available = len(ipv4OnlineOnPeers) > 0
 				ipv6Available = len(ipv6OnlineOnPeers) > 0
 
@@ -128,7 +179,12 @@ func combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, e
 		events.Add(health.Event{Time: health.Time(time.Now()), Description: fmt.Sprintf("Health protocol override condition %s", overrideCondition), Name: cacheName.String(), Hostname: cacheName.String(), Type: toData.ServerTypes[cacheName].String(), Available: available, IPv4Available: ipv4Available, IPv6Available: ipv6Available})
 	}
 
-	combinedStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: available, Ipv4Available: ipv4Available, Ipv6Available: ipv6Available})
+	combinedState := tc.IsAvailable{IsAvailable: available, Ipv4Available: ipv4Available, Ipv6Available: ipv6Available}
+	if available && weight < 1 {
+		combinedState.Warming = true
+		combinedState.Weight = weight
+	}
+	combinedStates.AddCache(cacheName, combinedState)
 }
 
 func combineDSState(
@@ -197,8 +253,13 @@ func pruneCombinedCaches(combinedStates peer.CRStatesThreadsafe, localStates tc.
 	}
 }
 
-func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
+func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData, consensus peer.Consensus) {
 	for cacheName, localCacheState := range localStates.Caches { // localStates gets pruned when servers are disabled, it's the source of truth
+		if votes := consensus.Caches[cacheName]; consensus.Degraded && votes.Consensus != nil && !*votes.Consensus {
+			// we're the outlier, so a cache the peers agree is unavailable is, even if it's healthy locally. Caches they agree are available already are, optimistically.
+			combinedStates.AddCache(cacheName, tc.IsAvailable{})
+			continue
+		}
 		combineCacheState(cacheName, localCacheState, events, peerOptimistic, peerStates, localStates, combinedStates, overrideMap, toData)
 	}
 
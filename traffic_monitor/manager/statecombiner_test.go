@@ -0,0 +1,66 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+func TestCombineCrStatesDegraded(t *testing.T) {
+	localStates := tc.NewCRStates()
+	localStates.Caches["up-on-peers"] = tc.IsAvailable{IsAvailable: false}
+	localStates.Caches["down-on-peers"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+	localStates.Caches["no-consensus"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+
+	peerStates := peer.NewCRStatesPeersThreadsafe(0)
+	availablePeerStates := map[tc.TrafficMonitorName]tc.CRStates{}
+	for _, name := range []tc.TrafficMonitorName{"tm1", "tm2"} {
+		states := tc.NewCRStates()
+		states.Caches["up-on-peers"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+		states.Caches["down-on-peers"] = tc.IsAvailable{IsAvailable: false}
+		availablePeerStates[name] = states
+	}
+
+	for _, degraded := range []bool{false, true} {
+		threshold := 0.0
+		if degraded {
+			threshold = 0.5
+		}
+		consensus := peer.NewConsensus(localStates, availablePeerStates, threshold)
+		if consensus.Degraded != degraded {
+			t.Fatalf("expected degraded %v, actual %v", degraded, consensus.Degraded)
+		}
+		combinedStates := peer.NewCRStatesThreadsafe()
+		combineCrStates(health.NewThreadsafeEvents(10), true, peerStates, localStates, combinedStates, map[tc.CacheName]bool{}, *todata.New(), consensus)
+		caches := combinedStates.GetCaches()
+
+		if caches["down-on-peers"].IsAvailable == degraded {
+			t.Errorf("degraded %v: expected cache the peers agree is down available %v, actual %v", degraded, !degraded, caches["down-on-peers"].IsAvailable)
+		}
+		if !caches["no-consensus"].IsAvailable {
+			t.Errorf("degraded %v: expected cache without consensus to keep its local state", degraded)
+		}
+	}
+}
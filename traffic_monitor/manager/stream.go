@@ -0,0 +1,69 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/datareq"
+	"github.com/apache/trafficcontrol/traffic_monitor/stream"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+
+	"github.com/json-iterator/go"
+)
+
+// CRStatesStreamSize is the number of CRStates deltas kept, so clients of the CRStates stream can resume after missing them.
+const CRStatesStreamSize = 1000
+
+// DSStatsStreamSize is the number of DS stats frames kept. Each frame is complete, so clients only need the latest; the others are kept so a client briefly behind can resume without skipping a frame.
+const DSStatsStreamSize = 10
+
+// StartDSStatsStreamManager starts the goroutine which publishes a frame of the latest DS stats, as served by /publish/DsStats, to the dsStatsStream every interval.
+func StartDSStatsStreamManager(
+	dsStatsStream *stream.Stream,
+	interval time.Duration,
+	dsStats threadsafe.DSStatsReader,
+	toData todata.TODataThreadsafe,
+) {
+	if interval <= 0 {
+		log.Infof("stream DS stats interval %v, DS stats stream disabled\n", interval)
+		return
+	}
+	go func() {
+		tick := time.NewTicker(interval)
+		for range tick.C {
+			filter, err := datareq.NewDSStatFilter("", url.Values{}, toData.Get().DeliveryServiceTypes)
+			if err != nil {
+				log.Errorf("creating DS stats stream filter: %v\n", err)
+				continue
+			}
+			json := jsoniter.ConfigFastest
+			bytes, err := json.Marshal(dsStats.Get().JSON(filter, url.Values{}))
+			if err != nil {
+				log.Errorf("marshalling DS stats stream frame: %v\n", err)
+				continue
+			}
+			dsStatsStream.Publish(bytes)
+		}
+	}()
+}
@@ -0,0 +1,56 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/synthetic"
+)
+
+// StartSyntheticManager listens for synthetic check results, and when it gets one, it sets it as the latest result of its Delivery Service and cache, and adds an event if the check started or stopped failing.
+func StartSyntheticManager(
+	syntheticChan <-chan synthetic.Result,
+	syntheticResults synthetic.ResultsThreadsafe,
+	events health.ThreadsafeEvents,
+) {
+	go func() {
+		for result := range syntheticChan {
+			compareSyntheticResult(events, result, syntheticResults.Get())
+			syntheticResults.Set(result)
+			result.PollFinished <- result.PollID
+		}
+	}()
+}
+
+func compareSyntheticResult(events health.ThreadsafeEvents, result synthetic.Result, previous synthetic.Results) {
+	previousResult, ok := previous[result.DeliveryService][result.Cache]
+	if ok && previousResult.Available == result.Available {
+		return
+	}
+	if !ok && result.Available {
+		return // don't add an event for every check which passes when it's first configured
+	}
+
+	description := "Synthetic check of " + string(result.DeliveryService) + " passed"
+	if !result.Available {
+		description = "Synthetic check of " + string(result.DeliveryService) + " failed: " + result.Error
+	}
+	events.Add(health.Event{Time: health.Time(result.Time), Description: description, Name: string(result.Cache), Hostname: string(result.Cache), Type: "SYNTHETIC", Available: result.Available})
+}
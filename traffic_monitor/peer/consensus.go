@@ -0,0 +1,132 @@
+package peer
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// ConsensusMinPeers is the minimum number of available peers needed to reach a consensus. With a single peer, neither Traffic Monitor can tell which of them is the outlier.
+const ConsensusMinPeers = 2
+
+// CacheVotes is the tally of the votes of the available peers on whether a cache is available.
+// Peers which don't report the cache don't vote.
+type CacheVotes struct {
+	Local bool `json:"local"`
+	Up    int  `json:"up"`
+	Down  int  `json:"down"`
+	// Consensus is whether a majority of the available peers reports the cache available, or nil if there is no majority either way.
+	Consensus *bool `json:"consensus"`
+}
+
+// Disagrees returns whether there is a consensus on the cache, and the local state isn't it.
+func (v CacheVotes) Disagrees() bool {
+	return v.Consensus != nil && *v.Consensus != v.Local
+}
+
+// Consensus is the agreement of the local states with the states of the available peers.
+// The local Traffic Monitor is degraded, meaning it's the outlier and its local states shouldn't be trusted, when it disagrees with the peer consensus on more than the threshold fraction of the caches which have a consensus.
+type Consensus struct {
+	Degraded        bool                        `json:"degraded"`
+	Threshold       float64                     `json:"threshold"`
+	AvailablePeers  int                         `json:"availablePeers"`
+	ConsensusCaches int                         `json:"consensusCaches"`
+	Disagreements   int                         `json:"disagreements"`
+	Caches          map[tc.CacheName]CacheVotes `json:"caches"`
+}
+
+// NewConsensus tallies the votes of the given available peers' states on each cache in the given local states. A threshold of 0 disables degrading, but the votes are still tallied.
+func NewConsensus(localStates tc.CRStates, availablePeerStates map[tc.TrafficMonitorName]tc.CRStates, threshold float64) Consensus {
+	consensus := Consensus{
+		Threshold:      threshold,
+		AvailablePeers: len(availablePeerStates),
+		Caches:         make(map[tc.CacheName]CacheVotes, len(localStates.Caches)),
+	}
+	for cacheName, localState := range localStates.Caches {
+		votes := CacheVotes{Local: localState.IsAvailable}
+		for _, peerStates := range availablePeerStates {
+			peerState, ok := peerStates.Caches[cacheName]
+			if !ok {
+				continue
+			}
+			if peerState.IsAvailable {
+				votes.Up++
+			} else {
+				votes.Down++
+			}
+		}
+		if consensus.AvailablePeers >= ConsensusMinPeers {
+			if votes.Up*2 > consensus.AvailablePeers {
+				up := true
+				votes.Consensus = &up
+			} else if votes.Down*2 > consensus.AvailablePeers {
+				up := false
+				votes.Consensus = &up
+			}
+		}
+		if votes.Consensus != nil {
+			consensus.ConsensusCaches++
+		}
+		if votes.Disagrees() {
+			consensus.Disagreements++
+		}
+		consensus.Caches[cacheName] = votes
+	}
+	consensus.Degraded = threshold > 0 && consensus.ConsensusCaches > 0 && float64(consensus.Disagreements)/float64(consensus.ConsensusCaches) > threshold
+	return consensus
+}
+
+// AvailablePeerStates returns the Crstates of the peers which are currently available.
+func (t *CRStatesPeersThreadsafe) AvailablePeerStates() map[tc.TrafficMonitorName]tc.CRStates {
+	m := map[tc.TrafficMonitorName]tc.CRStates{}
+	for peerName, peerStates := range t.GetCrstates() {
+		if t.GetPeerAvailability(peerName) {
+			m[peerName] = peerStates
+		}
+	}
+	return m
+}
+
+// ConsensusThreadsafe provides safe access for multiple goroutines to read the latest peer Consensus, with a single goroutine writer.
+type ConsensusThreadsafe struct {
+	consensus *Consensus
+	m         *sync.RWMutex
+}
+
+// NewConsensusThreadsafe creates a new ConsensusThreadsafe, which isn't degraded.
+func NewConsensusThreadsafe() ConsensusThreadsafe {
+	return ConsensusThreadsafe{consensus: &Consensus{Caches: map[tc.CacheName]CacheVotes{}}, m: &sync.RWMutex{}}
+}
+
+// Get returns the latest Consensus. Callers MUST NOT modify it.
+func (t ConsensusThreadsafe) Get() Consensus {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return *t.consensus
+}
+
+// Set sets the latest Consensus. This MUST NOT be called by multiple goroutines.
+func (t ConsensusThreadsafe) Set(consensus Consensus) {
+	t.m.Lock()
+	*t.consensus = consensus
+	t.m.Unlock()
+}
@@ -0,0 +1,98 @@
+package peer
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func makeCRStates(caches map[tc.CacheName]bool) tc.CRStates {
+	states := tc.NewCRStates()
+	for name, available := range caches {
+		states.Caches[name] = tc.IsAvailable{IsAvailable: available}
+	}
+	return states
+}
+
+func TestNewConsensus(t *testing.T) {
+	local := makeCRStates(map[tc.CacheName]bool{"a": false, "b": false, "c": true, "d": true})
+	peers := map[tc.TrafficMonitorName]tc.CRStates{
+		"tm1": makeCRStates(map[tc.CacheName]bool{"a": true, "b": true, "c": true, "d": false}),
+		"tm2": makeCRStates(map[tc.CacheName]bool{"a": true, "b": true, "c": true, "d": true}),
+		"tm3": makeCRStates(map[tc.CacheName]bool{"a": true, "b": false, "c": true}),
+	}
+
+	consensus := NewConsensus(local, peers, 0.5)
+	if consensus.AvailablePeers != 3 {
+		t.Errorf("expected 3 available peers, actual %v", consensus.AvailablePeers)
+	}
+	expected := map[tc.CacheName]struct {
+		up        int
+		down      int
+		consensus *bool
+	}{
+		"a": {3, 0, boolPtr(true)},
+		"b": {2, 1, boolPtr(true)},
+		"c": {3, 0, boolPtr(true)},
+		"d": {1, 1, nil}, // tm3 doesn't report d, so there's no majority of the 3 peers
+	}
+	for name, exp := range expected {
+		votes := consensus.Caches[name]
+		if votes.Up != exp.up || votes.Down != exp.down {
+			t.Errorf("cache %v expected %v up %v down, actual %v up %v down", name, exp.up, exp.down, votes.Up, votes.Down)
+		}
+		if (votes.Consensus == nil) != (exp.consensus == nil) || (votes.Consensus != nil && *votes.Consensus != *exp.consensus) {
+			t.Errorf("cache %v expected consensus %v, actual %v", name, exp.consensus, votes.Consensus)
+		}
+	}
+	if consensus.ConsensusCaches != 3 || consensus.Disagreements != 2 {
+		t.Errorf("expected 2 disagreements of 3 caches with consensus, actual %v of %v", consensus.Disagreements, consensus.ConsensusCaches)
+	}
+	if !consensus.Degraded {
+		t.Errorf("expected degraded with 2/3 disagreements over a 0.5 threshold")
+	}
+
+	if consensus := NewConsensus(local, peers, 0.7); consensus.Degraded {
+		t.Errorf("expected not degraded with 2/3 disagreements under a 0.7 threshold")
+	}
+	if consensus := NewConsensus(local, peers, 0); consensus.Degraded {
+		t.Errorf("expected not degraded with a 0 threshold")
+	}
+}
+
+func TestNewConsensusTooFewPeers(t *testing.T) {
+	local := makeCRStates(map[tc.CacheName]bool{"a": false})
+	peers := map[tc.TrafficMonitorName]tc.CRStates{
+		"tm1": makeCRStates(map[tc.CacheName]bool{"a": true}),
+	}
+	consensus := NewConsensus(local, peers, 0.5)
+	if consensus.Caches["a"].Consensus != nil {
+		t.Errorf("expected no consensus with a single peer, actual %v", *consensus.Caches["a"].Consensus)
+	}
+	if consensus.Degraded {
+		t.Errorf("expected not degraded with a single peer")
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
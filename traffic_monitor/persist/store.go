@@ -0,0 +1,511 @@
+// Package persist stores recent Traffic Monitor history on disk, so it can be
+// restored when Traffic Monitor restarts.
+//
+// The store is a directory of append-only segment files, each containing one
+// JSON record per line: a stat poll result of a cache, an event, or the local
+// CRStates. A new segment is started every segment duration, and segments
+// which ended longer than the retention ago are deleted.
+//
+// To keep segments small, a stat record only contains the stats of its cache
+// which changed since the cache's previous record in the same segment. The
+// first record of each cache in a segment contains all its stats, so every
+// segment can be read without the ones before it.
+package persist
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+)
+
+const segmentExt = ".seg"
+
+const (
+	recordTypeStat     = "stat"
+	recordTypeEvent    = "event"
+	recordTypeCRStates = "crstates"
+)
+
+// record is a line of a segment file. Which fields are set depends on the Type.
+type record struct {
+	Type     string                 `json:"type"`
+	Time     time.Time              `json:"time"`
+	Cache    tc.CacheName           `json:"cache,omitempty"`
+	Limit    uint64                 `json:"limit,omitempty"`
+	Stats    map[string]interface{} `json:"stats,omitempty"`
+	Removed  []string               `json:"removed,omitempty"`
+	Vitals   *cache.Vitals          `json:"vitals,omitempty"`
+	Event    *eventRecord           `json:"event,omitempty"`
+	CRStates *tc.CRStates           `json:"crstates,omitempty"`
+}
+
+// eventRecord is a persisted health.Event. It exists because health.Event can't be unmarshalled, and its availabilities share a JSON name.
+type eventRecord struct {
+	Index         uint64 `json:"index"`
+	Description   string `json:"description"`
+	Name          string `json:"name"`
+	Hostname      string `json:"hostname"`
+	Type          string `json:"type"`
+	Available     bool   `json:"available"`
+	IPv4Available bool   `json:"ipv4Available"`
+	IPv6Available bool   `json:"ipv6Available"`
+}
+
+// StatResult is a stat poll result of a cache, restored from a Store.
+type StatResult struct {
+	Cache  tc.CacheName
+	Time   time.Time
+	Stats  map[string]interface{}
+	Vitals cache.Vitals
+	// Limit is the number of stat values the cache's history was limited to when the result was polled.
+	Limit uint64
+}
+
+// Store is an on-disk store of recent stat history, events, and local CRStates.
+// A nil *Store is valid: it stores nothing, and loads and queries nothing. This allows callers to use a Store without checking whether one is configured.
+// Stats, events, and CRStates may each be added by a different goroutine, but each by only one.
+type Store struct {
+	dir             string
+	retention       time.Duration
+	segmentDuration time.Duration
+
+	m            sync.Mutex
+	file         *os.File
+	writer       *bufio.Writer
+	segmentStart time.Time
+	lastStats    map[tc.CacheName]map[string]interface{} // the stats of each cache as of its last record in the current segment
+	nextEvent    uint64                                  // the index of the first event which hasn't been written
+	lastCRStates []byte                                  // the last CRStates written to the current segment
+}
+
+// Open returns the Store in the given directory, creating the directory if it doesn't exist. If dir is empty, no store is configured, and nil is returned.
+func Open(dir string, retention time.Duration, segmentDuration time.Duration) (*Store, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if retention <= 0 {
+		return nil, errors.New("retention must be positive")
+	}
+	if segmentDuration <= 0 {
+		return nil, errors.New("segment duration must be positive")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.New("creating directory: " + err.Error())
+	}
+	return &Store{
+		dir:             dir,
+		retention:       retention,
+		segmentDuration: segmentDuration,
+		lastStats:       map[tc.CacheName]map[string]interface{}{},
+	}, nil
+}
+
+// AddStatResult appends the stats of the given stat poll result, whose cache's history is limited to the given number of values. Errored results are not stored, because they have no stats.
+func (s *Store) AddStatResult(result cache.Result, limit uint64) error {
+	if s == nil || result.Error != nil {
+		return nil
+	}
+	cacheName := tc.CacheName(result.ID)
+	stats := map[string]interface{}{}
+	for stat, val := range result.Miscellaneous {
+		switch val.(type) {
+		case string, float64, bool: // the same primitives ResultStatHistory compares
+			stats[stat] = val
+		}
+	}
+	vitals := result.Vitals
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := s.rotateIfNecessary(result.Time); err != nil {
+		return err
+	}
+
+	rec := record{Type: recordTypeStat, Time: result.Time, Cache: cacheName, Limit: limit, Vitals: &vitals}
+	if last, ok := s.lastStats[cacheName]; !ok {
+		rec.Stats = stats
+	} else {
+		rec.Stats = map[string]interface{}{}
+		for stat, val := range stats {
+			if lastVal, ok := last[stat]; !ok || lastVal != val {
+				rec.Stats[stat] = val
+			}
+		}
+		for stat := range last {
+			if _, ok := stats[stat]; !ok {
+				rec.Removed = append(rec.Removed, stat)
+			}
+		}
+	}
+	s.lastStats[cacheName] = stats
+	return s.write(rec)
+}
+
+// AddSnapshot appends the given events which haven't been written yet, and the given CRStates if they changed since they were last written, and then flushes everything written to disk, and deletes expired segments.
+func (s *Store) AddSnapshot(events []health.Event, crStates tc.CRStates, now time.Time) error {
+	if s == nil {
+		return nil
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := s.rotateIfNecessary(now); err != nil {
+		return err
+	}
+
+	// events are newest first, but are written oldest first, so they're loaded in order.
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if e.Index < s.nextEvent {
+			continue
+		}
+		rec := record{Type: recordTypeEvent, Time: time.Time(e.Time), Event: &eventRecord{
+			Index:         e.Index,
+			Description:   e.Description,
+			Name:          e.Name,
+			Hostname:      e.Hostname,
+			Type:          e.Type,
+			Available:     e.Available,
+			IPv4Available: e.IPv4Available,
+			IPv6Available: e.IPv6Available,
+		}}
+		if err := s.write(rec); err != nil {
+			return err
+		}
+		s.nextEvent = e.Index + 1
+	}
+
+	crStatesBts, err := json.Marshal(crStates)
+	if err != nil {
+		return errors.New("encoding crstates: " + err.Error())
+	}
+	if !bytes.Equal(crStatesBts, s.lastCRStates) {
+		if err := s.write(record{Type: recordTypeCRStates, Time: now, CRStates: &crStates}); err != nil {
+			return err
+		}
+		s.lastCRStates = crStatesBts
+	}
+
+	if err := s.writer.Flush(); err != nil {
+		return errors.New("flushing segment: " + err.Error())
+	}
+	return s.deleteExpired(now)
+}
+
+// Close flushes and closes the current segment.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.closeSegment()
+}
+
+// write writes the given record to the current segment. It MUST only be called with the lock held, after rotateIfNecessary.
+func (s *Store) write(rec record) error {
+	bts, err := json.Marshal(rec)
+	if err != nil {
+		return errors.New("encoding " + rec.Type + " record: " + err.Error())
+	}
+	if _, err := s.writer.Write(append(bts, '\n')); err != nil {
+		return errors.New("writing " + rec.Type + " record: " + err.Error())
+	}
+	return nil
+}
+
+// rotateIfNecessary starts a new segment, if there is no current segment, or the current one is older than the segment duration. It MUST only be called with the lock held.
+func (s *Store) rotateIfNecessary(now time.Time) error {
+	if s.file != nil && now.Sub(s.segmentStart) < s.segmentDuration {
+		return nil
+	}
+	if err := s.closeSegment(); err != nil {
+		return err
+	}
+
+	start := now
+	if !start.After(s.segmentStart) {
+		start = s.segmentStart.Add(time.Nanosecond) // segment names must be unique, and in order, even if results arrive out of order
+	}
+	path := filepath.Join(s.dir, segmentName(start))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.New("creating segment: " + err.Error())
+	}
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	s.segmentStart = start
+
+	// every segment must be readable on its own, so it starts with the full stats of each cache, and the CRStates.
+	s.lastStats = map[tc.CacheName]map[string]interface{}{}
+	s.lastCRStates = nil
+	return nil
+}
+
+// closeSegment flushes and closes the current segment, if any. It MUST only be called with the lock held.
+func (s *Store) closeSegment() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.writer.Flush()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	s.writer = nil
+	if err != nil {
+		return errors.New("closing segment: " + err.Error())
+	}
+	return nil
+}
+
+// deleteExpired deletes the segments which ended longer than the retention ago. A segment ends when the next one starts, so the current segment is never deleted.
+func (s *Store) deleteExpired(now time.Time) error {
+	segments, err := s.segments()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(segments)-1; i++ {
+		if now.Sub(segments[i+1].start) <= s.retention {
+			break
+		}
+		if err := os.Remove(segments[i].path); err != nil && !os.IsNotExist(err) {
+			return errors.New("deleting expired segment: " + err.Error())
+		}
+	}
+	return nil
+}
+
+type segment struct {
+	path  string
+	start time.Time
+}
+
+func segmentName(start time.Time) string {
+	return fmt.Sprintf("%020d%s", start.UnixNano(), segmentExt)
+}
+
+// segments returns the segment files in the store, oldest first.
+func (s *Store) segments() ([]segment, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, errors.New("reading directory: " + err.Error())
+	}
+	segments := []segment{}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		nanos, err := strconv.ParseInt(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			log.Warnln("history store: ignoring file '" + name + "' which isn't a segment")
+			continue
+		}
+		segments = append(segments, segment{path: filepath.Join(s.dir, name), start: time.Unix(0, nanos)})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].start.Before(segments[j].start) })
+	return segments, nil
+}
+
+// readSegments calls f with every record in the segments which ended at or after the given time, oldest first.
+// The Stats of stat records are the full stats of the cache as of that record, not only the changed ones, and are reused by later records, so f MUST copy them if they're kept.
+func (s *Store) readSegments(since time.Time, f func(rec record)) error {
+	s.m.Lock()
+	if s.writer != nil {
+		if err := s.writer.Flush(); err != nil {
+			s.m.Unlock()
+			return errors.New("flushing segment: " + err.Error())
+		}
+	}
+	s.m.Unlock()
+
+	segments, err := s.segments()
+	if err != nil {
+		return err
+	}
+	for i, seg := range segments {
+		if i+1 < len(segments) && segments[i+1].start.Before(since) {
+			continue
+		}
+		if err := readSegment(seg.path, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readSegment(path string, f func(rec record)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil // the segment expired since it was listed
+	} else if err != nil {
+		return errors.New("opening segment: " + err.Error())
+	}
+	defer file.Close()
+
+	stats := map[tc.CacheName]map[string]interface{}{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		rec := record{}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// the last line may be partial, if Traffic Monitor stopped while writing it.
+			log.Warnln("history store: skipping invalid record in segment '" + path + "': " + err.Error())
+			continue
+		}
+		if rec.Type == recordTypeStat {
+			cacheStats, ok := stats[rec.Cache]
+			if !ok {
+				cacheStats = map[string]interface{}{}
+				stats[rec.Cache] = cacheStats
+			}
+			for stat, val := range rec.Stats {
+				cacheStats[stat] = val
+			}
+			for _, stat := range rec.Removed {
+				delete(cacheStats, stat)
+			}
+			rec.Stats = cacheStats
+		}
+		f(rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.New("reading segment '" + path + "': " + err.Error())
+	}
+	return nil
+}
+
+// LoadStats calls f with every stored stat result which hasn't expired, oldest first. The Stats of each result are the cache's full stats, and may be kept.
+func (s *Store) LoadStats(now time.Time, f func(StatResult)) error {
+	if s == nil {
+		return nil
+	}
+	since := now.Add(-s.retention)
+	return s.readSegments(since, func(rec record) {
+		if rec.Type != recordTypeStat || rec.Time.Before(since) {
+			return
+		}
+		result := StatResult{Cache: rec.Cache, Time: rec.Time, Stats: make(map[string]interface{}, len(rec.Stats)), Limit: rec.Limit}
+		for stat, val := range rec.Stats {
+			result.Stats[stat] = val
+		}
+		if rec.Vitals != nil {
+			result.Vitals = *rec.Vitals
+		}
+		f(result)
+	})
+}
+
+// LoadState returns the up to maxEvents newest stored events which haven't expired, newest first, and the last stored CRStates, or nil if there are none. New events are only written by AddSnapshot if they're newer than the loaded events.
+func (s *Store) LoadState(now time.Time, maxEvents uint64) ([]health.Event, *tc.CRStates, error) {
+	if s == nil {
+		return nil, nil, nil
+	}
+	since := now.Add(-s.retention)
+	events := []health.Event{}
+	crStates := (*tc.CRStates)(nil)
+	err := s.readSegments(since, func(rec record) {
+		switch rec.Type {
+		case recordTypeEvent:
+			if rec.Event == nil || rec.Time.Before(since) {
+				return
+			}
+			events = append(events, health.Event{
+				Time:          health.Time(rec.Time),
+				Index:         rec.Event.Index,
+				Description:   rec.Event.Description,
+				Name:          rec.Event.Name,
+				Hostname:      rec.Event.Hostname,
+				Type:          rec.Event.Type,
+				Available:     rec.Event.Available,
+				IPv4Available: rec.Event.IPv4Available,
+				IPv6Available: rec.Event.IPv6Available,
+			})
+		case recordTypeCRStates:
+			if rec.CRStates != nil {
+				crStates = rec.CRStates
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if uint64(len(events)) > maxEvents {
+		events = events[uint64(len(events))-maxEvents:]
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	s.m.Lock()
+	if len(events) > 0 && events[0].Index >= s.nextEvent {
+		s.nextEvent = events[0].Index + 1
+	}
+	s.m.Unlock()
+	return events, crStates, nil
+}
+
+// QueryStat returns the stored values of the given stat of the given cache polled from since until until, newest first. Consecutive polls with the same value are a single value, whose Span is the number of polls and Time is the latest poll's, like the values of a ResultStatHistory.
+func (s *Store) QueryStat(cacheName tc.CacheName, stat string, since time.Time, until time.Time) ([]cache.ResultStatVal, error) {
+	vals := []cache.ResultStatVal{}
+	if s == nil {
+		return vals, nil
+	}
+	err := s.readSegments(since, func(rec record) {
+		if rec.Type != recordTypeStat || rec.Cache != cacheName || rec.Time.Before(since) || rec.Time.After(until) {
+			return
+		}
+		val, ok := rec.Stats[stat]
+		if !ok {
+			return
+		}
+		if len(vals) > 0 && vals[len(vals)-1].Val == val {
+			vals[len(vals)-1].Time = rec.Time
+			vals[len(vals)-1].Span++
+			return
+		}
+		vals = append(vals, cache.ResultStatVal{Val: val, Time: rec.Time, Span: 1})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(vals)-1; i < j; i, j = i+1, j-1 {
+		vals[i], vals[j] = vals[j], vals[i]
+	}
+	return vals, nil
+}
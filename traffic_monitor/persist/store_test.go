@@ -0,0 +1,202 @@
+package persist
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+)
+
+func openTestStore(t *testing.T, dir string) *Store {
+	s, err := Open(dir, time.Hour, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("Open expected no error, actual: %v", err)
+	}
+	return s
+}
+
+func TestOpenDisabled(t *testing.T) {
+	s, err := Open("", time.Hour, time.Minute)
+	if s != nil || err != nil {
+		t.Fatalf("Open with no directory expected nil nil, actual %v %v", s, err)
+	}
+	if err := s.AddStatResult(cache.Result{ID: "edge0"}, 10); err != nil {
+		t.Errorf("nil Store AddStatResult expected no error, actual: %v", err)
+	}
+	if vals, err := s.QueryStat("edge0", "bandwidth", time.Time{}, time.Now()); err != nil || len(vals) != 0 {
+		t.Errorf("nil Store QueryStat expected no values, actual %v %v", vals, err)
+	}
+}
+
+func TestStatsRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-history-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	start := time.Now().Add(-30 * time.Minute)
+	s := openTestStore(t, dir)
+	stats := []map[string]interface{}{
+		{"bandwidth": float64(1), "version": "8.0"},
+		{"bandwidth": float64(1), "version": "8.0"},
+		{"bandwidth": float64(2), "version": "8.0"},
+		{"bandwidth": float64(2)},
+	}
+	for i, misc := range stats {
+		result := cache.Result{ID: "edge0", Time: start.Add(time.Duration(i) * time.Minute), Miscellaneous: misc, Vitals: cache.Vitals{BytesOut: uint64(i)}}
+		if err := s.AddStatResult(result, 100); err != nil {
+			t.Fatalf("AddStatResult expected no error, actual: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close expected no error, actual: %v", err)
+	}
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+	loaded := []StatResult{}
+	if err := s.LoadStats(time.Now(), func(result StatResult) { loaded = append(loaded, result) }); err != nil {
+		t.Fatalf("LoadStats expected no error, actual: %v", err)
+	}
+	if len(loaded) != len(stats) {
+		t.Fatalf("LoadStats expected %v results, actual %v", len(stats), len(loaded))
+	}
+	for i, result := range loaded {
+		if result.Cache != "edge0" || result.Limit != 100 || result.Vitals.BytesOut != uint64(i) || !result.Time.Equal(start.Add(time.Duration(i)*time.Minute)) {
+			t.Errorf("LoadStats result %v expected edge0 limit 100 bytes out %v, actual %+v", i, i, result)
+		}
+		if len(result.Stats) != len(stats[i]) {
+			t.Errorf("LoadStats result %v expected stats %v, actual %v", i, stats[i], result.Stats)
+		}
+		for stat, val := range stats[i] {
+			if result.Stats[stat] != val {
+				t.Errorf("LoadStats result %v expected stat %v %v, actual %v", i, stat, val, result.Stats[stat])
+			}
+		}
+	}
+
+	vals, err := s.QueryStat("edge0", "bandwidth", start, time.Now())
+	if err != nil {
+		t.Fatalf("QueryStat expected no error, actual: %v", err)
+	}
+	if len(vals) != 2 || vals[0].Val != float64(2) || vals[0].Span != 2 || vals[1].Val != float64(1) || vals[1].Span != 2 {
+		t.Errorf("QueryStat expected values 2 and 1 spanning 2 polls each, actual %+v", vals)
+	}
+	if !vals[0].Time.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("QueryStat expected newest value time %v, actual %v", start.Add(3*time.Minute), vals[0].Time)
+	}
+
+	vals, err = s.QueryStat("edge0", "bandwidth", start.Add(2*time.Minute), time.Now())
+	if err != nil || len(vals) != 1 || vals[0].Span != 2 {
+		t.Errorf("QueryStat since the third poll expected 1 value spanning 2 polls, actual %+v %v", vals, err)
+	}
+}
+
+func TestStateRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-history-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	events := []health.Event{
+		{Time: health.Time(now), Index: 1, Description: "Available", Name: "edge0", Hostname: "edge0", Type: "EDGE", Available: true, IPv4Available: true},
+		{Time: health.Time(now.Add(-time.Minute)), Index: 0, Description: "Unavailable", Name: "edge0", Hostname: "edge0", Type: "EDGE"},
+	}
+	crStates := tc.NewCRStates()
+	crStates.Caches["edge0"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+
+	s := openTestStore(t, dir)
+	if err := s.AddSnapshot(events, crStates, now); err != nil {
+		t.Fatalf("AddSnapshot expected no error, actual: %v", err)
+	}
+	if err := s.AddSnapshot(events, crStates, now); err != nil {
+		t.Fatalf("AddSnapshot expected no error, actual: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close expected no error, actual: %v", err)
+	}
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+	loadedEvents, loadedCRStates, err := s.LoadState(now, 10)
+	if err != nil {
+		t.Fatalf("LoadState expected no error, actual: %v", err)
+	}
+	if len(loadedEvents) != len(events) {
+		t.Fatalf("LoadState expected %v events, the same events added twice only once, actual %+v", len(events), loadedEvents)
+	}
+	for i, event := range loadedEvents {
+		if event.Index != events[i].Index || !time.Time(event.Time).Equal(time.Time(events[i].Time)) || event.Available != events[i].Available || event.IPv4Available != events[i].IPv4Available || event.Description != events[i].Description {
+			t.Errorf("LoadState event %v expected %+v, actual %+v", i, events[i], event)
+		}
+	}
+	if loadedCRStates == nil || !loadedCRStates.Caches["edge0"].IsAvailable {
+		t.Errorf("LoadState expected CRStates with edge0 available, actual %+v", loadedCRStates)
+	}
+
+	if loadedEvents, _, err := s.LoadState(now, 1); err != nil || len(loadedEvents) != 1 || loadedEvents[0].Index != 1 {
+		t.Errorf("LoadState of 1 event expected the newest, actual %+v %v", loadedEvents, err)
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-history-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := openTestStore(t, dir)
+	defer s.Close()
+	start := time.Now().Add(-2 * time.Hour)
+	for i := 0; i < 4; i++ {
+		// each result is in a new segment, because they're further apart than the segment duration.
+		result := cache.Result{ID: "edge0", Time: start.Add(time.Duration(i) * 30 * time.Minute), Miscellaneous: map[string]interface{}{"bandwidth": float64(i)}}
+		if err := s.AddStatResult(result, 100); err != nil {
+			t.Fatalf("AddStatResult expected no error, actual: %v", err)
+		}
+	}
+	if err := s.AddSnapshot(nil, tc.NewCRStates(), start.Add(95*time.Minute)); err != nil {
+		t.Fatalf("AddSnapshot expected no error, actual: %v", err)
+	}
+
+	segments, err := s.segments()
+	if err != nil {
+		t.Fatalf("segments expected no error, actual: %v", err)
+	}
+	// the retention is an hour, so the first segment, which ended 65 minutes ago, is deleted, and the second, which ended 35 minutes ago, is kept.
+	if len(segments) != 3 {
+		t.Errorf("expected 3 segments after deleting expired segments, actual %v", len(segments))
+	}
+
+	vals, err := s.QueryStat("edge0", "bandwidth", time.Time{}, time.Now())
+	if err != nil || len(vals) != 3 || vals[len(vals)-1].Val != float64(1) {
+		t.Errorf("QueryStat expected the 3 values which weren't deleted, actual %+v %v", vals, err)
+	}
+}
@@ -0,0 +1,157 @@
+package poller
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+)
+
+// PollerTypeSynthetic is the poller which requests a Delivery Service URL through a cache, as a client would. Unlike the other pollers, it doesn't return the response body, but a JSON SyntheticResponse.
+const PollerTypeSynthetic = "synthetic"
+
+func init() {
+	AddPollerType(PollerTypeSynthetic, syntheticGlobalInit, syntheticInit, syntheticPoll)
+}
+
+// SyntheticResponse is the result of a synthetic request which received a response, of any status.
+type SyntheticResponse struct {
+	StatusCode int           `json:"statusCode"`
+	TTFB       time.Duration `json:"ttfb"`
+}
+
+type SyntheticPollGlobalCtx struct {
+	UserAgent string
+	Timeout   time.Duration
+
+	// transports are shared by the pollers of each TLS server name, so their idle connections are reused when
+	// pollers are recreated on config changes, rather than leaked with a transport per poller.
+	transports map[syntheticTransportKey]*http.Transport
+	m          sync.Mutex
+}
+
+type syntheticTransportKey struct {
+	ServerName  string
+	NoKeepAlive bool
+}
+
+// transport returns the transport of pollers with the given TLS server name and keep-alive setting, creating it if
+// it doesn't exist.
+func (g *SyntheticPollGlobalCtx) transport(serverName string, noKeepAlive bool) *http.Transport {
+	g.m.Lock()
+	defer g.m.Unlock()
+	key := syntheticTransportKey{ServerName: serverName, NoKeepAlive: noKeepAlive}
+	if transport, ok := g.transports[key]; ok {
+		return transport
+	}
+	transport := &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true, ServerName: serverName},
+		DisableKeepAlives: noKeepAlive,
+	}
+	g.transports[key] = transport
+	return transport
+}
+
+type SyntheticPollCtx struct {
+	Client    *http.Client
+	UserAgent string
+	PollerID  string
+}
+
+func syntheticGlobalInit(cfg config.Config, appData config.StaticAppData) interface{} {
+	return &SyntheticPollGlobalCtx{
+		UserAgent:  appData.UserAgent,
+		Timeout:    cfg.HTTPTimeout,
+		transports: map[syntheticTransportKey]*http.Transport{},
+	}
+}
+
+// syntheticInit creates a client for each poller, using the transport of its TLS server name, because the TLS server name must be the Delivery Service's, not the cache's, for the cache to serve the Delivery Service's certificate.
+func syntheticInit(cfg PollerConfig, globalCtxI interface{}) interface{} {
+	gctx := (globalCtxI).(*SyntheticPollGlobalCtx)
+
+	serverName := cfg.Host
+	if host, _, err := net.SplitHostPort(cfg.Host); err == nil {
+		serverName = host
+	}
+
+	timeout := gctx.Timeout
+	if cfg.Timeout != 0 {
+		timeout = cfg.Timeout
+	}
+
+	return &SyntheticPollCtx{
+		Client: &http.Client{
+			Transport: gctx.transport(serverName, cfg.NoKeepAlive),
+			Timeout:   timeout,
+			// redirects are the response of the Delivery Service, and following them would request something other than the configured URL.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		UserAgent: gctx.UserAgent,
+		PollerID:  cfg.PollerID,
+	}
+}
+
+func syntheticPoll(ctxI interface{}, url string, host string, pollID uint64) ([]byte, time.Time, time.Duration, error) {
+	ctx := (ctxI).(*SyntheticPollCtx)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, time.Now(), 0, errors.New("creating HTTP request: " + err.Error())
+	}
+	req.Header.Set("User-Agent", ctx.UserAgent)
+	req.Host = host
+
+	startReq := time.Now()
+	firstByte := time.Time{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}))
+
+	resp, err := ctx.Client.Do(req)
+	if err != nil {
+		reqEnd := time.Now()
+		return nil, reqEnd, reqEnd.Sub(startReq), fmt.Errorf("id %v url %v host %v fetch error: %v", ctx.PollerID, url, host, err)
+	}
+	defer resp.Body.Close()
+
+	// the body must be read, for the latency to include the whole response, as a client's would.
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		reqEnd := time.Now()
+		return nil, reqEnd, reqEnd.Sub(startReq), fmt.Errorf("id %v url %v host %v fetch error: reading body: %v", ctx.PollerID, url, host, err)
+	}
+	reqEnd := time.Now()
+
+	bts, err := json.Marshal(SyntheticResponse{StatusCode: resp.StatusCode, TTFB: firstByte.Sub(startReq)})
+	if err != nil {
+		return nil, reqEnd, reqEnd.Sub(startReq), errors.New("encoding synthetic response: " + err.Error())
+	}
+	return bts, reqEnd, reqEnd.Sub(startReq), nil
+}
@@ -0,0 +1,74 @@
+package stream
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// CRStatesDelta is the change of CRStates: the new states of the caches and Delivery Services which changed or were added, and the names of those which were removed.
+type CRStatesDelta struct {
+	Caches                  map[tc.CacheName]tc.IsAvailable                       `json:"caches"`
+	DeliveryServices        map[tc.DeliveryServiceName]tc.CRStatesDeliveryService `json:"deliveryServices"`
+	RemovedCaches           []tc.CacheName                                        `json:"removedCaches,omitempty"`
+	RemovedDeliveryServices []tc.DeliveryServiceName                              `json:"removedDeliveryServices,omitempty"`
+}
+
+// DiffCRStates returns the delta from the old CRStates to the new, and whether there is any change.
+func DiffCRStates(old tc.CRStates, new tc.CRStates) (CRStatesDelta, bool) {
+	delta := CRStatesDelta{
+		Caches:           map[tc.CacheName]tc.IsAvailable{},
+		DeliveryServices: map[tc.DeliveryServiceName]tc.CRStatesDeliveryService{},
+	}
+	for name, state := range new.Caches {
+		if oldState, ok := old.Caches[name]; !ok || oldState != state {
+			delta.Caches[name] = state
+		}
+	}
+	for name := range old.Caches {
+		if _, ok := new.Caches[name]; !ok {
+			delta.RemovedCaches = append(delta.RemovedCaches, name)
+		}
+	}
+	for name, state := range new.DeliveryService {
+		if oldState, ok := old.DeliveryService[name]; !ok || !deliveryServiceStatesEqual(oldState, state) {
+			delta.DeliveryServices[name] = state
+		}
+	}
+	for name := range old.DeliveryService {
+		if _, ok := new.DeliveryService[name]; !ok {
+			delta.RemovedDeliveryServices = append(delta.RemovedDeliveryServices, name)
+		}
+	}
+	changed := len(delta.Caches) > 0 || len(delta.DeliveryServices) > 0 || len(delta.RemovedCaches) > 0 || len(delta.RemovedDeliveryServices) > 0
+	return delta, changed
+}
+
+func deliveryServiceStatesEqual(a tc.CRStatesDeliveryService, b tc.CRStatesDeliveryService) bool {
+	if a.IsAvailable != b.IsAvailable || len(a.DisabledLocations) != len(b.DisabledLocations) {
+		return false
+	}
+	for i, location := range a.DisabledLocations {
+		if b.DisabledLocations[i] != location {
+			return false
+		}
+	}
+	return true
+}
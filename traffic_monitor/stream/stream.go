@@ -0,0 +1,87 @@
+// Package stream buffers the events pushed by Traffic Monitor's streaming endpoints, so clients can resume a stream from the sequence number of the last event they received.
+package stream
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"sync"
+)
+
+// Event is an event of a Stream.
+type Event struct {
+	// Seq is the sequence number of the event. The first event of a Stream is 1, and each following event is one more than the last.
+	Seq  uint64
+	Data []byte
+}
+
+// Stream is a sequence of events, which keeps its newest events so clients can resume it, and signals waiting clients when an event is published. It's safe for multiple goroutines.
+type Stream struct {
+	events  []Event // the newest events, oldest first
+	size    int
+	seq     uint64
+	publish chan struct{} // closed when the next event is published
+	m       *sync.Mutex
+}
+
+// New creates a new Stream, which keeps the given number of its newest events.
+func New(size int) *Stream {
+	if size < 1 {
+		size = 1
+	}
+	return &Stream{size: size, publish: make(chan struct{}), m: &sync.Mutex{}}
+}
+
+// Publish adds an event with the given data to the stream, and returns its sequence number. The data MUST NOT be modified afterwards.
+func (s *Stream) Publish(data []byte) uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.seq++
+	if len(s.events) == s.size {
+		copy(s.events, s.events[1:])
+		s.events = s.events[:len(s.events)-1]
+	}
+	s.events = append(s.events, Event{Seq: s.seq, Data: data})
+	close(s.publish)
+	s.publish = make(chan struct{})
+	return s.seq
+}
+
+// Since returns the kept events after the given sequence number, the sequence number of the newest event, and a channel which is closed when the next event is published.
+// If events after the given sequence number are no longer kept, it also returns false, and all the kept events; the client has missed events, and must start over.
+func (s *Stream) Since(seq uint64) ([]Event, uint64, bool, <-chan struct{}) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if seq > s.seq {
+		// the client has a sequence number from before this Traffic Monitor restarted.
+		return s.copyEvents(s.events), s.seq, false, s.publish
+	}
+	if len(s.events) == 0 || seq+1 >= s.events[0].Seq {
+		i := len(s.events) - int(s.seq-seq)
+		return s.copyEvents(s.events[i:]), s.seq, true, s.publish
+	}
+	return s.copyEvents(s.events), s.seq, false, s.publish
+}
+
+// copyEvents returns a copy of the given events, so the kept events can be modified after it's returned. Callers must lock s.
+func (s *Stream) copyEvents(events []Event) []Event {
+	c := make([]Event, len(events))
+	copy(c, events)
+	return c
+}
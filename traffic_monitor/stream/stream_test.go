@@ -0,0 +1,109 @@
+package stream
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestStreamSince(t *testing.T) {
+	s := New(3)
+	if events, latest, resumable, _ := s.Since(0); len(events) != 0 || latest != 0 || !resumable {
+		t.Errorf("empty stream Since(0) expected no events, latest 0, resumable; actual %v events, latest %v, resumable %v", len(events), latest, resumable)
+	}
+
+	for _, data := range []string{"a", "b", "c", "d", "e"} {
+		s.Publish([]byte(data))
+	}
+
+	events, latest, resumable, _ := s.Since(3)
+	if latest != 5 || !resumable {
+		t.Errorf("Since(3) expected latest 5, resumable; actual latest %v, resumable %v", latest, resumable)
+	}
+	if len(events) != 2 || events[0].Seq != 4 || string(events[0].Data) != "d" || events[1].Seq != 5 || string(events[1].Data) != "e" {
+		t.Errorf("Since(3) expected events 4 d, 5 e; actual %+v", events)
+	}
+
+	if events, _, resumable, _ := s.Since(2); len(events) != 3 || !resumable {
+		t.Errorf("Since(2) expected the 3 kept events, resumable; actual %v events, resumable %v", len(events), resumable)
+	}
+	if events, _, resumable, _ := s.Since(5); len(events) != 0 || !resumable {
+		t.Errorf("Since(5) expected no events, resumable; actual %v events, resumable %v", len(events), resumable)
+	}
+	if events, _, resumable, _ := s.Since(1); len(events) != 3 || resumable {
+		t.Errorf("Since(1) of evicted event expected the 3 kept events, not resumable; actual %v events, resumable %v", len(events), resumable)
+	}
+	if _, _, resumable, _ := s.Since(9); resumable {
+		t.Errorf("Since(9) of future event expected not resumable, actual resumable")
+	}
+}
+
+func TestStreamPublishSignals(t *testing.T) {
+	s := New(1)
+	_, _, _, published := s.Since(0)
+	select {
+	case <-published:
+		t.Fatalf("expected published channel open before Publish, actual closed")
+	default:
+	}
+	s.Publish([]byte("a"))
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatalf("expected published channel closed after Publish, actual open")
+	}
+}
+
+func TestDiffCRStates(t *testing.T) {
+	old := tc.NewCRStates()
+	old.Caches["same"] = tc.IsAvailable{IsAvailable: true}
+	old.Caches["changed"] = tc.IsAvailable{IsAvailable: true}
+	old.Caches["removed"] = tc.IsAvailable{IsAvailable: true}
+	old.DeliveryService["same"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{"cg"}}
+	old.DeliveryService["changed"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{}}
+
+	new := old.Copy()
+	new.Caches["changed"] = tc.IsAvailable{IsAvailable: false}
+	new.Caches["added"] = tc.IsAvailable{IsAvailable: true}
+	delete(new.Caches, "removed")
+	new.DeliveryService["same"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{"cg"}}
+	new.DeliveryService["changed"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{"cg"}}
+
+	delta, changed := DiffCRStates(old, new)
+	if !changed {
+		t.Fatalf("DiffCRStates expected changed, actual unchanged")
+	}
+	if len(delta.Caches) != 2 || delta.Caches["changed"].IsAvailable || !delta.Caches["added"].IsAvailable {
+		t.Errorf("DiffCRStates expected caches changed unavailable and added available, actual %+v", delta.Caches)
+	}
+	if len(delta.RemovedCaches) != 1 || delta.RemovedCaches[0] != "removed" {
+		t.Errorf("DiffCRStates expected removed caches [removed], actual %v", delta.RemovedCaches)
+	}
+	if _, ok := delta.DeliveryServices["changed"]; len(delta.DeliveryServices) != 1 || !ok {
+		t.Errorf("DiffCRStates expected delivery services [changed], actual %+v", delta.DeliveryServices)
+	}
+
+	if _, changed := DiffCRStates(new, new.Copy()); changed {
+		t.Errorf("DiffCRStates of equal states expected unchanged, actual changed")
+	}
+}
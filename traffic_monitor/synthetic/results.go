@@ -0,0 +1,92 @@
+package synthetic
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Results is the latest check result of each Delivery Service, through each cache.
+type Results map[tc.DeliveryServiceName]map[tc.CacheName]Result
+
+// Copy returns a deep copy of the Results.
+func (r Results) Copy() Results {
+	c := make(Results, len(r))
+	for ds, caches := range r {
+		c[ds] = make(map[tc.CacheName]Result, len(caches))
+		for cache, result := range caches {
+			c[ds][cache] = result
+		}
+	}
+	return c
+}
+
+// Failing returns whether the latest check of the given Delivery Service through the given cache failed. If it hasn't been checked, it isn't failing.
+func (r Results) Failing(ds tc.DeliveryServiceName, cache tc.CacheName) bool {
+	result, ok := r[ds][cache]
+	return ok && !result.Available
+}
+
+// ResultsThreadsafe provides safe access for multiple goroutines to read the latest check Results, with a single goroutine writer.
+type ResultsThreadsafe struct {
+	results *Results
+	m       *sync.RWMutex
+}
+
+// NewResultsThreadsafe returns a new ResultsThreadsafe object safe for multiple goroutine readers and a single writer.
+func NewResultsThreadsafe() ResultsThreadsafe {
+	r := Results{}
+	return ResultsThreadsafe{m: &sync.RWMutex{}, results: &r}
+}
+
+// Get returns a copy of the Results. Callers MUST NOT modify the returned object.
+func (t ResultsThreadsafe) Get() Results {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return t.results.Copy()
+}
+
+// Set sets the latest result of the check of the result's Delivery Service through its cache.
+func (t ResultsThreadsafe) Set(result Result) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if _, ok := (*t.results)[result.DeliveryService]; !ok {
+		(*t.results)[result.DeliveryService] = map[tc.CacheName]Result{}
+	}
+	(*t.results)[result.DeliveryService][result.Cache] = result
+}
+
+// Retain deletes the results of all checks whose poll ID isn't in the given set, so checks which are no longer configured aren't reported.
+func (t ResultsThreadsafe) Retain(pollIDs map[string]struct{}) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	for ds, caches := range *t.results {
+		for cache := range caches {
+			if _, ok := pollIDs[PollID(ds, cache)]; !ok {
+				delete(caches, cache)
+			}
+		}
+		if len(caches) == 0 {
+			delete(*t.results, ds)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package synthetic
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/poller"
+)
+
+const (
+	// URLParamPrefix is the prefix of the Traffic Monitor config Parameters whose value is the URL to check of the Delivery Service whose XMLID follows the prefix.
+	URLParamPrefix = "synthetic.check.url."
+	// IntervalParam is the Traffic Monitor config Parameter of the interval between checks, in milliseconds.
+	IntervalParam = "synthetic.polling.interval"
+	// TimeoutParam is the Traffic Monitor config Parameter of the timeout of a check, in milliseconds.
+	TimeoutParam = "synthetic.connection.timeout"
+	// AvailabilityParam is the Traffic Monitor config Parameter which, if true, makes failing checks disable Delivery Services in Cache Groups.
+	AvailabilityParam = "synthetic.availability"
+)
+
+const DefaultInterval = 30 * time.Second
+
+// pollIDSeparator separates the Delivery Service and cache of a check's poll ID. Neither XMLIDs nor host names may contain it.
+const pollIDSeparator = "/"
+
+// Handler handles synthetic check responses, taking a raw reader, parsing the data, and passing a result object to the ResultChannel. This fulfills the common `Handler` interface.
+type Handler struct {
+	ResultChannel chan Result
+}
+
+// NewHandler returns a new synthetic check Handler.
+func NewHandler() Handler {
+	return Handler{ResultChannel: make(chan Result)}
+}
+
+// Result is the result of checking a Delivery Service through a cache.
+type Result struct {
+	DeliveryService tc.DeliveryServiceName `json:"-"`
+	Cache           tc.CacheName           `json:"-"`
+	Available       bool                   `json:"available"`
+	StatusCode      int                    `json:"statusCode"`
+	LatencyMS       float64                `json:"latencyMs"`
+	TTFBMS          float64                `json:"ttfbMs"`
+	Error           string                 `json:"error,omitempty"`
+	Time            time.Time              `json:"time"`
+	PollID          uint64                 `json:"-"`
+	PollFinished    chan<- uint64          `json:"-"`
+}
+
+// Handle handles a response from a synthetic check, parsing the data and forwarding it to the ResultChannel.
+func (handler Handler) Handle(id string, r io.Reader, format string, reqTime time.Duration, reqEnd time.Time, err error, pollID uint64, usingIPv4 bool, pollFinished chan<- uint64) {
+	ds, cache := ParsePollID(id)
+	result := Result{
+		DeliveryService: ds,
+		Cache:           cache,
+		LatencyMS:       durationMS(reqTime),
+		Time:            reqEnd,
+		PollID:          pollID,
+		PollFinished:    pollFinished,
+	}
+
+	if err == nil && r == nil {
+		err = errors.New("no response")
+	}
+	if err == nil {
+		resp := poller.SyntheticResponse{}
+		if err = json.NewDecoder(r).Decode(&resp); err == nil {
+			result.StatusCode = resp.StatusCode
+			result.TTFBMS = durationMS(resp.TTFB)
+			if resp.StatusCode < 200 || resp.StatusCode > 399 {
+				err = fmt.Errorf("bad HTTP status: %v", resp.StatusCode)
+			}
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Available = true
+	}
+
+	handler.ResultChannel <- result
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// PollID returns the poller ID of the check of the given Delivery Service through the given cache.
+func PollID(ds tc.DeliveryServiceName, cache tc.CacheName) string {
+	return string(ds) + pollIDSeparator + string(cache)
+}
+
+// ParsePollID returns the Delivery Service and cache of the given poller ID, created by PollID.
+func ParsePollID(id string) (tc.DeliveryServiceName, tc.CacheName) {
+	i := strings.Index(id, pollIDSeparator)
+	if i == -1 {
+		return tc.DeliveryServiceName(id), ""
+	}
+	return tc.DeliveryServiceName(id[:i]), tc.CacheName(id[i+len(pollIDSeparator):])
+}
+
+// GetCheckURLs returns the URL to check of each Delivery Service with one, from the given Traffic Monitor config.
+func GetCheckURLs(cfg map[string]interface{}) map[tc.DeliveryServiceName]string {
+	urls := map[tc.DeliveryServiceName]string{}
+	for name, val := range cfg {
+		if !strings.HasPrefix(name, URLParamPrefix) {
+			continue
+		}
+		urlStr, ok := val.(string)
+		if !ok || urlStr == "" {
+			continue
+		}
+		urls[tc.DeliveryServiceName(strings.TrimPrefix(name, URLParamPrefix))] = urlStr
+	}
+	return urls
+}
+
+// GetInterval returns the interval between checks from the given Traffic Monitor config, or DefaultInterval if it's missing or invalid.
+func GetInterval(cfg map[string]interface{}) time.Duration {
+	if ms := getMS(cfg, IntervalParam); ms > 0 {
+		return ms
+	}
+	return DefaultInterval
+}
+
+// GetTimeout returns the timeout of a check from the given Traffic Monitor config, or 0 if it's missing or invalid.
+func GetTimeout(cfg map[string]interface{}) time.Duration {
+	return getMS(cfg, TimeoutParam)
+}
+
+func getMS(cfg map[string]interface{}, param string) time.Duration {
+	switch val := cfg[param].(type) {
+	case float64:
+		return time.Duration(val) * time.Millisecond
+	case string:
+		if ms, err := strconv.Atoi(val); err == nil {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return 0
+}
+
+// AffectsAvailability returns whether failing checks should disable Delivery Services in Cache Groups, per the given Traffic Monitor config.
+func AffectsAvailability(cfg map[string]interface{}) bool {
+	val, ok := cfg[AvailabilityParam].(string)
+	return ok && strings.HasPrefix(strings.ToLower(val), "t")
+}
+
+// CreatePollURLs returns the IPv4 and IPv6 URLs to request the given Delivery Service check URL from the given cache, and the Host to request it with.
+// The URLs are the check URL with its host replaced by the cache's address, and its port, if it has none, by the cache's. If the cache has no address of a protocol, its URL is empty.
+func CreatePollURLs(checkURL string, srv tc.LegacyTrafficServer) (string, string, string, error) {
+	u, err := url.Parse(checkURL)
+	if err != nil {
+		return "", "", "", errors.New("parsing URL: " + err.Error())
+	}
+	if u.Host == "" {
+		return "", "", "", errors.New("URL has no host")
+	}
+	host := u.Host
+
+	port := u.Port()
+	if port == "" {
+		if strings.ToLower(u.Scheme) == "https" {
+			if srv.HTTPSPort != 0 {
+				port = strconv.Itoa(srv.HTTPSPort)
+			}
+		} else if srv.Port != 0 {
+			port = strconv.Itoa(srv.Port)
+		}
+	}
+
+	withAddr := func(addr string) string {
+		pollURL := *u
+		pollURL.Host = addr
+		if port != "" {
+			pollURL.Host += ":" + port
+		}
+		return pollURL.String()
+	}
+
+	url4, url6 := "", ""
+	if srv.IP != "" {
+		url4 = withAddr(srv.IP)
+	}
+	if srv.IP6 != "" {
+		addr := srv.IP6
+		if i := strings.Index(addr, "/"); i != -1 {
+			addr = addr[:i]
+		}
+		url6 = withAddr("[" + addr + "]")
+	}
+	return url4, url6, host, nil
+}
@@ -0,0 +1,157 @@
+package synthetic
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestPollID(t *testing.T) {
+	ds, cache := ParsePollID(PollID("my-ds", "edge0"))
+	if ds != "my-ds" || cache != "edge0" {
+		t.Errorf("expected ParsePollID of PollID to return 'my-ds' 'edge0', actual '%s' '%s'", ds, cache)
+	}
+}
+
+func TestCreatePollURLs(t *testing.T) {
+	srv := tc.LegacyTrafficServer{IP: "192.0.2.1", IP6: "2001:db8::1/64", Port: 8080, HTTPSPort: 8443}
+
+	tests := []struct {
+		checkURL string
+		url4     string
+		url6     string
+		host     string
+	}{
+		{"http://demo1.mycdn.test/health?x=1", "http://192.0.2.1:8080/health?x=1", "http://[2001:db8::1]:8080/health?x=1", "demo1.mycdn.test"},
+		{"https://demo1.mycdn.test/health", "https://192.0.2.1:8443/health", "https://[2001:db8::1]:8443/health", "demo1.mycdn.test"},
+		{"http://demo1.mycdn.test:81/health", "http://192.0.2.1:81/health", "http://[2001:db8::1]:81/health", "demo1.mycdn.test:81"},
+	}
+	for _, test := range tests {
+		url4, url6, host, err := CreatePollURLs(test.checkURL, srv)
+		if err != nil {
+			t.Errorf("CreatePollURLs '%s' expected no error, actual: %v", test.checkURL, err)
+			continue
+		}
+		if url4 != test.url4 || url6 != test.url6 || host != test.host {
+			t.Errorf("CreatePollURLs '%s' expected '%s' '%s' '%s', actual '%s' '%s' '%s'", test.checkURL, test.url4, test.url6, test.host, url4, url6, host)
+		}
+	}
+
+	url4, url6, _, err := CreatePollURLs("http://demo1.mycdn.test/", tc.LegacyTrafficServer{IP: "192.0.2.1"})
+	if err != nil || url4 != "http://192.0.2.1/" || url6 != "" {
+		t.Errorf("CreatePollURLs for a cache with no IPv6 address or port expected 'http://192.0.2.1/' '', actual '%s' '%s' %v", url4, url6, err)
+	}
+
+	if _, _, _, err := CreatePollURLs("/health", srv); err == nil {
+		t.Errorf("CreatePollURLs of a URL with no host expected error, actual: nil")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg := map[string]interface{}{
+		URLParamPrefix + "ds0": "http://ds0.mycdn.test/health",
+		URLParamPrefix + "ds1": "",
+		IntervalParam:          float64(10000),
+		AvailabilityParam:      "true",
+		"health.polling.url":   "http://${hostname}/_astats",
+	}
+
+	urls := GetCheckURLs(cfg)
+	if len(urls) != 1 || urls["ds0"] != "http://ds0.mycdn.test/health" {
+		t.Errorf("expected check URLs {ds0: http://ds0.mycdn.test/health}, actual: %v", urls)
+	}
+	if interval := GetInterval(cfg); interval != 10*time.Second {
+		t.Errorf("expected interval 10s, actual: %v", interval)
+	}
+	if timeout := GetTimeout(cfg); timeout != 0 {
+		t.Errorf("expected missing timeout 0, actual: %v", timeout)
+	}
+	if !AffectsAvailability(cfg) {
+		t.Errorf("expected synthetic checks to affect availability, actual: false")
+	}
+	if interval := GetInterval(map[string]interface{}{}); interval != DefaultInterval {
+		t.Errorf("expected missing interval %v, actual: %v", DefaultInterval, interval)
+	}
+	if AffectsAvailability(map[string]interface{}{}) {
+		t.Errorf("expected synthetic checks without the availability parameter not to affect availability, actual: true")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	handler := NewHandler()
+
+	tests := []struct {
+		body      string
+		err       error
+		available bool
+		status    int
+	}{
+		{`{"statusCode":200,"ttfb":5000000}`, nil, true, 200},
+		{`{"statusCode":302,"ttfb":5000000}`, nil, true, 302},
+		{`{"statusCode":502,"ttfb":5000000}`, nil, false, 502},
+		{``, errors.New("connection refused"), false, 0},
+	}
+	for _, test := range tests {
+		r := io.Reader(nil)
+		if test.err == nil {
+			r = strings.NewReader(test.body)
+		}
+		go handler.Handle(PollID("ds0", "edge0"), r, "", 10*time.Millisecond, time.Now(), test.err, 1, true, nil)
+		result := <-handler.ResultChannel
+		if result.DeliveryService != "ds0" || result.Cache != "edge0" {
+			t.Errorf("expected result for ds0 edge0, actual %s %s", result.DeliveryService, result.Cache)
+		}
+		if result.Available != test.available || result.StatusCode != test.status {
+			t.Errorf("handling '%s' %v expected available %v status %v, actual %v %v", test.body, test.err, test.available, test.status, result.Available, result.StatusCode)
+		}
+		if result.LatencyMS != 10 {
+			t.Errorf("expected latency 10ms, actual: %v", result.LatencyMS)
+		}
+		if test.available && result.TTFBMS != 5 {
+			t.Errorf("expected TTFB 5ms, actual: %v", result.TTFBMS)
+		}
+		if !test.available && result.Error == "" {
+			t.Errorf("handling '%s' %v expected an error, actual: none", test.body, test.err)
+		}
+	}
+}
+
+func TestResultsRetain(t *testing.T) {
+	results := NewResultsThreadsafe()
+	results.Set(Result{DeliveryService: "ds0", Cache: "edge0"})
+	results.Set(Result{DeliveryService: "ds0", Cache: "edge1", Available: true})
+	results.Set(Result{DeliveryService: "ds1", Cache: "edge0"})
+
+	if !results.Get().Failing("ds0", "edge0") || results.Get().Failing("ds0", "edge1") || results.Get().Failing("ds2", "edge0") {
+		t.Errorf("expected only the failed checks to be failing, actual: %+v", results.Get())
+	}
+
+	results.Retain(map[string]struct{}{PollID("ds0", "edge1"): {}})
+	actual := results.Get()
+	if len(actual) != 1 || len(actual["ds0"]) != 1 {
+		t.Errorf("expected only the ds0 edge1 result to be retained, actual: %+v", actual)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE maintenance_window (
+    id bigserial PRIMARY KEY,
+    scope text NOT NULL,
+    server bigint,
+    cachegroup bigint,
+    topology text,
+    start_time timestamp with time zone NOT NULL,
+    end_time timestamp with time zone NOT NULL,
+    reason text NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT maintenance_window_scope_check CHECK (
+        (scope = 'server' AND server IS NOT NULL AND cachegroup IS NULL AND topology IS NULL) OR
+        (scope = 'cachegroup' AND server IS NULL AND cachegroup IS NOT NULL AND topology IS NULL) OR
+        (scope = 'topology' AND server IS NULL AND cachegroup IS NULL AND topology IS NOT NULL)
+    ),
+    CONSTRAINT maintenance_window_time_check CHECK (end_time > start_time),
+    CONSTRAINT maintenance_window_server_fkey FOREIGN KEY (server) REFERENCES server(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT maintenance_window_cachegroup_fkey FOREIGN KEY (cachegroup) REFERENCES cachegroup(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT maintenance_window_topology_fkey FOREIGN KEY (topology) REFERENCES topology(name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX maintenance_window_server_fkey ON maintenance_window USING btree (server);
+CREATE INDEX maintenance_window_cachegroup_fkey ON maintenance_window USING btree (cachegroup);
+CREATE INDEX maintenance_window_topology_fkey ON maintenance_window USING btree (topology);
+CREATE INDEX maintenance_window_time_idx ON maintenance_window USING btree (start_time, end_time);
+CREATE INDEX maintenance_window_last_updated_idx ON maintenance_window (last_updated DESC NULLS LAST);
+
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON maintenance_window;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON maintenance_window FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+INSERT INTO last_deleted (table_name) VALUES ('maintenance_window') ON CONFLICT (table_name) DO NOTHING;
+
+CREATE TRIGGER on_delete_current_timestamp
+AFTER DELETE
+ON maintenance_window
+FOR EACH ROW EXECUTE PROCEDURE on_delete_current_timestamp_last_updated('maintenance_window');
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM last_deleted WHERE table_name = 'maintenance_window';
+DROP TABLE IF EXISTS maintenance_window;
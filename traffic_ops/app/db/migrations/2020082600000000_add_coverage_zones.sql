@@ -0,0 +1,48 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE coverage_zone (
+    id bigserial PRIMARY KEY,
+    cdn bigint NOT NULL,
+    cachegroup bigint NOT NULL,
+    network cidr NOT NULL,
+    deep boolean DEFAULT FALSE NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT coverage_zone_cdn_deep_network_unique UNIQUE (cdn, deep, network),
+    CONSTRAINT coverage_zone_cdn_fkey FOREIGN KEY (cdn) REFERENCES cdn(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT coverage_zone_cachegroup_fkey FOREIGN KEY (cachegroup) REFERENCES cachegroup(id) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+CREATE INDEX coverage_zone_cdn_fkey ON coverage_zone USING btree (cdn);
+CREATE INDEX coverage_zone_cachegroup_fkey ON coverage_zone USING btree (cachegroup);
+CREATE INDEX coverage_zone_last_updated_idx ON coverage_zone (last_updated DESC NULLS LAST);
+
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON coverage_zone;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON coverage_zone FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+INSERT INTO last_deleted (table_name) VALUES ('coverage_zone') ON CONFLICT (table_name) DO NOTHING;
+
+CREATE TRIGGER on_delete_current_timestamp
+AFTER DELETE
+ON coverage_zone
+FOR EACH ROW EXECUTE PROCEDURE on_delete_current_timestamp_last_updated('coverage_zone');
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM last_deleted WHERE table_name = 'coverage_zone';
+DROP TABLE IF EXISTS coverage_zone;
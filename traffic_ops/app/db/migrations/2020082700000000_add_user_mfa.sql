@@ -0,0 +1,42 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE tm_user ADD COLUMN mfa_secret text;
+ALTER TABLE tm_user ADD COLUMN mfa_enabled boolean DEFAULT FALSE NOT NULL;
+ALTER TABLE tm_user ADD COLUMN mfa_last_step bigint;
+
+ALTER TABLE role ADD COLUMN mfa_required boolean DEFAULT FALSE NOT NULL;
+
+CREATE TABLE user_mfa_recovery_code (
+    id bigserial PRIMARY KEY,
+    tm_user bigint NOT NULL,
+    code_hash text NOT NULL,
+    CONSTRAINT user_mfa_recovery_code_tm_user_code_hash_unique UNIQUE (tm_user, code_hash),
+    CONSTRAINT user_mfa_recovery_code_tm_user_fkey FOREIGN KEY (tm_user) REFERENCES tm_user(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS user_mfa_recovery_code;
+
+ALTER TABLE role DROP COLUMN IF EXISTS mfa_required;
+
+ALTER TABLE tm_user DROP COLUMN IF EXISTS mfa_last_step;
+ALTER TABLE tm_user DROP COLUMN IF EXISTS mfa_enabled;
+ALTER TABLE tm_user DROP COLUMN IF EXISTS mfa_secret;
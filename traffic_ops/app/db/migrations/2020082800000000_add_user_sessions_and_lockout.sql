@@ -0,0 +1,55 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE tm_user ADD COLUMN failed_login_count integer DEFAULT 0 NOT NULL;
+ALTER TABLE tm_user ADD COLUMN locked_until timestamp with time zone;
+ALTER TABLE tm_user ADD COLUMN password_changed timestamp with time zone DEFAULT now() NOT NULL;
+
+CREATE TABLE user_password_history (
+    id bigserial PRIMARY KEY,
+    tm_user bigint NOT NULL,
+    local_passwd text NOT NULL,
+    created timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT user_password_history_tm_user_fkey FOREIGN KEY (tm_user) REFERENCES tm_user(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX user_password_history_tm_user_fkey ON user_password_history USING btree (tm_user);
+
+CREATE TABLE user_session (
+    id bigserial PRIMARY KEY,
+    session_key text NOT NULL,
+    tm_user bigint NOT NULL,
+    created timestamp with time zone DEFAULT now() NOT NULL,
+    last_seen timestamp with time zone DEFAULT now() NOT NULL,
+    expires timestamp with time zone NOT NULL,
+    user_agent text,
+    client_ip text,
+    CONSTRAINT user_session_session_key_unique UNIQUE (session_key),
+    CONSTRAINT user_session_tm_user_fkey FOREIGN KEY (tm_user) REFERENCES tm_user(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX user_session_tm_user_fkey ON user_session USING btree (tm_user);
+CREATE INDEX user_session_expires_idx ON user_session USING btree (expires);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS user_session;
+DROP TABLE IF EXISTS user_password_history;
+
+ALTER TABLE tm_user DROP COLUMN IF EXISTS password_changed;
+ALTER TABLE tm_user DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE tm_user DROP COLUMN IF EXISTS failed_login_count;
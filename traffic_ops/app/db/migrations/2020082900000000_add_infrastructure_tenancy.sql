@@ -0,0 +1,41 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE server ADD COLUMN tenant_id bigint;
+ALTER TABLE server ADD CONSTRAINT server_tenant_id_fkey FOREIGN KEY (tenant_id) REFERENCES tenant(id) MATCH FULL;
+CREATE INDEX server_tenant_id_idx ON server USING btree (tenant_id);
+
+ALTER TABLE cachegroup ADD COLUMN tenant_id bigint;
+ALTER TABLE cachegroup ADD CONSTRAINT cachegroup_tenant_id_fkey FOREIGN KEY (tenant_id) REFERENCES tenant(id) MATCH FULL;
+CREATE INDEX cachegroup_tenant_id_idx ON cachegroup USING btree (tenant_id);
+
+ALTER TABLE topology ADD COLUMN tenant_id bigint;
+ALTER TABLE topology ADD CONSTRAINT topology_tenant_id_fkey FOREIGN KEY (tenant_id) REFERENCES tenant(id) MATCH FULL;
+CREATE INDEX topology_tenant_id_idx ON topology USING btree (tenant_id);
+
+ALTER TABLE profile ADD COLUMN tenant_id bigint;
+ALTER TABLE profile ADD CONSTRAINT profile_tenant_id_fkey FOREIGN KEY (tenant_id) REFERENCES tenant(id) MATCH FULL;
+CREATE INDEX profile_tenant_id_idx ON profile USING btree (tenant_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE profile DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE topology DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE cachegroup DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE server DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,37 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS federation_resolver_history (
+    id bigserial PRIMARY KEY,
+    federation bigint NOT NULL,
+    ip_address text NOT NULL,
+    type text NOT NULL,
+    action text NOT NULL CHECK (action IN ('added', 'removed', 'ttl')),
+    cname text NOT NULL,
+    ttl bigint NOT NULL,
+    previous_ttl bigint,
+    username text NOT NULL,
+    timestamp timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT federation_resolver_history_federation_fkey FOREIGN KEY (federation) REFERENCES federation(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS federation_resolver_history_federation_timestamp_idx ON federation_resolver_history USING btree (federation, timestamp);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS federation_resolver_history;
@@ -0,0 +1,45 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS origin_group (
+    deliveryservice bigint PRIMARY KEY,
+    policy text NOT NULL DEFAULT 'failover' CHECK (policy IN ('failover', 'weighted')),
+    health_check_path text CHECK (health_check_path IS NULL OR health_check_path LIKE '/%'),
+    health_check_expected_status integer CHECK (health_check_expected_status IS NULL OR health_check_expected_status BETWEEN 100 AND 599),
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT origin_group_deliveryservice_fkey FOREIGN KEY (deliveryservice) REFERENCES deliveryservice(id) ON DELETE CASCADE
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON origin_group;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON origin_group FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+CREATE TABLE IF NOT EXISTS origin_group_member (
+    deliveryservice bigint NOT NULL,
+    origin bigint NOT NULL,
+    role text NOT NULL DEFAULT 'primary' CHECK (role IN ('primary', 'secondary')),
+    weight integer NOT NULL DEFAULT 1 CHECK (weight > 0),
+    rank integer NOT NULL,
+    PRIMARY KEY (deliveryservice, origin),
+    CONSTRAINT origin_group_member_deliveryservice_fkey FOREIGN KEY (deliveryservice) REFERENCES origin_group(deliveryservice) ON DELETE CASCADE,
+    CONSTRAINT origin_group_member_origin_fkey FOREIGN KEY (origin) REFERENCES origin(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS origin_group_member;
+DROP TABLE IF EXISTS origin_group;
@@ -0,0 +1,25 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with this
+ * work for additional information regarding copyright ownership.  The ASF
+ * licenses this file to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+INSERT INTO status (name, description) VALUES ('BURN_IN', 'Burn In. Hardware being tested before being put into service. Not active in any configuration.') ON CONFLICT (name) DO NOTHING;
+INSERT INTO status (name, description) VALUES ('DECOMMISSIONED', 'Decommissioned. Permanently taken out of service. Not active in any configuration.') ON CONFLICT (name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM status WHERE name IN ('BURN_IN', 'DECOMMISSIONED') AND NOT EXISTS (SELECT 1 FROM server WHERE server.status = status.id);
@@ -78,6 +78,8 @@ insert into status (name, description) values ('REPORTED', 'Server is online and
 insert into status (name, description) values ('ADMIN_DOWN', 'Sever is administrative down and does not receive traffic.') ON CONFLICT (name) DO NOTHING;
 insert into status (name, description) values ('CCR_IGNORE', 'Server is ignored by traffic router.') ON CONFLICT (name) DO NOTHING;
 insert into status (name, description) values ('PRE_PROD', 'Pre Production. Not active in any configuration.') ON CONFLICT (name) DO NOTHING;
+insert into status (name, description) values ('BURN_IN', 'Burn In. Hardware being tested before being put into service. Not active in any configuration.') ON CONFLICT (name) DO NOTHING;
+insert into status (name, description) values ('DECOMMISSIONED', 'Decommissioned. Permanently taken out of service. Not active in any configuration.') ON CONFLICT (name) DO NOTHING;
 
 -- tenants
 insert into tenant (name, active, parent_id) values ('root', true, null) ON CONFLICT DO NOTHING;
@@ -937,4 +939,6 @@ insert into last_deleted (table_name) VALUES ('type') ON CONFLICT (table_name) D
 insert into last_deleted (table_name) VALUES ('user_role') ON CONFLICT (table_name) DO NOTHING;
 insert into last_deleted (table_name) VALUES ('server_capability') ON CONFLICT (table_name) DO NOTHING;
 insert into last_deleted (table_name) VALUES ('server_server_capability') ON CONFLICT (table_name) DO NOTHING;
-insert into last_deleted (table_name) VALUES ('deliveryservices_required_capability') ON CONFLICT (table_name) DO NOTHING;
\ No newline at end of file
+insert into last_deleted (table_name) VALUES ('deliveryservices_required_capability') ON CONFLICT (table_name) DO NOTHING;
+insert into last_deleted (table_name) VALUES ('maintenance_window') ON CONFLICT (table_name) DO NOTHING;
+insert into last_deleted (table_name) VALUES ('coverage_zone') ON CONFLICT (table_name) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,104 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_COVERAGE_ZONES        = apiBase + "/coveragezones"
+	API_COVERAGE_ZONES_LOOKUP = API_COVERAGE_ZONES + "/lookup"
+)
+
+// CreateCoverageZone creates a coverage zone.
+func (to *Session) CreateCoverageZone(cz tc.CoverageZoneNullable) (tc.CoverageZoneResponse, ReqInf, error) {
+	var resp tc.CoverageZoneResponse
+	reqBody, err := json.Marshal(cz)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, API_COVERAGE_ZONES, reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// UpdateCoverageZoneByID replaces the coverage zone with the given ID.
+func (to *Session) UpdateCoverageZoneByID(id int, cz tc.CoverageZoneNullable) (tc.CoverageZoneResponse, ReqInf, error) {
+	var resp tc.CoverageZoneResponse
+	reqBody, err := json.Marshal(cz)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := put(to, fmt.Sprintf("%s?id=%d", API_COVERAGE_ZONES, id), reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// GetCoverageZones returns the coverage zones, optionally filtered by the given query parameters, e.g. "cdn",
+// "cachegroup" or "deep".
+func (to *Session) GetCoverageZones(params url.Values, header http.Header) ([]tc.CoverageZoneNullable, ReqInf, error) {
+	route := API_COVERAGE_ZONES
+	if len(params) > 0 {
+		route += "?" + params.Encode()
+	}
+	var resp tc.CoverageZonesResponse
+	reqInf, err := get(to, route, &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// GetCoverageZoneByID returns the coverage zone with the given ID.
+func (to *Session) GetCoverageZoneByID(id int, header http.Header) ([]tc.CoverageZoneNullable, ReqInf, error) {
+	var resp tc.CoverageZonesResponse
+	reqInf, err := get(to, fmt.Sprintf("%s?id=%d", API_COVERAGE_ZONES, id), &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// DeleteCoverageZoneByID deletes the coverage zone with the given ID.
+func (to *Session) DeleteCoverageZoneByID(id int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := del(to, fmt.Sprintf("%s?id=%d", API_COVERAGE_ZONES, id), &alerts)
+	return alerts, reqInf, err
+}
+
+// LookupCoverageZones returns the coverage zones whose networks contain the given IP address, in the given CDN or, if
+// cdn is empty, in every CDN.
+func (to *Session) LookupCoverageZones(ip string, cdn string, header http.Header) ([]tc.CoverageZoneNullable, ReqInf, error) {
+	params := url.Values{"ip": []string{ip}}
+	if cdn != "" {
+		params.Set("cdn", cdn)
+	}
+	var resp tc.CoverageZonesResponse
+	reqInf, err := get(to, API_COVERAGE_ZONES_LOOKUP+"?"+params.Encode(), &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// GetCoverageZoneFile returns the Coverage Zone File of the CDN with the given name.
+func (to *Session) GetCoverageZoneFile(cdn string, header http.Header) (tc.CoverageZoneFile, ReqInf, error) {
+	var resp tc.CoverageZoneFile
+	reqInf, err := get(to, fmt.Sprintf("%s/cdns/%s/coveragezones", apiBase, url.PathEscape(cdn)), &resp, header)
+	return resp, reqInf, err
+}
+
+// GetDeepCoverageZoneFile returns the Deep Coverage Zone File of the CDN with the given name.
+func (to *Session) GetDeepCoverageZoneFile(cdn string, header http.Header) (tc.DeepCoverageZoneFile, ReqInf, error) {
+	var resp tc.DeepCoverageZoneFile
+	reqInf, err := get(to, fmt.Sprintf("%s/cdns/%s/deepcoveragezones", apiBase, url.PathEscape(cdn)), &resp, header)
+	return resp, reqInf, err
+}
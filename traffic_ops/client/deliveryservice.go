@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
@@ -53,6 +54,13 @@ const (
 	// See Also: https://traffic-control-cdn.readthedocs.io/en/latest/api/v3/deliveryservices_id_capacity.html
 	API_DELIVERY_SERVICE_CAPACITY = API_DELIVERY_SERVICE_ID + "/capacity"
 
+	// API_DELIVERY_SERVICE_CONSISTENT_HASH is the API path on which Traffic Ops serves the caches
+	// a request path of a specific Delivery Service identified by an integral, unique identifier
+	// consistently hashes to. It is intended to be used with fmt.Sprintf to insert its required
+	// path parameter (namely the ID of the Delivery Service of interest).
+	// See Also: https://traffic-control-cdn.readthedocs.io/en/latest/api/v3/deliveryservices_id_consistenthash.html
+	API_DELIVERY_SERVICE_CONSISTENT_HASH = API_DELIVERY_SERVICE_ID + "/consistenthash"
+
 	// API_DELIVERY_SERVICE_ELIGIBLE_SERVERS is the API path on which Traffic Ops serves information about
 	// the servers which are eligible to be assigned to a specific Delivery Service identified by an integral,
 	// unique identifier. It is intended to be used with fmt.Sprintf to insert its required path parameter
@@ -60,6 +68,13 @@ const (
 	// See Also: https://traffic-control-cdn.readthedocs.io/en/latest/api/v3/deliveryservices_id_servers_eligible.html
 	API_DELIVERY_SERVICE_ELIGIBLE_SERVERS = API_DELIVERY_SERVICE_ID + "/servers/eligible"
 
+	// API_DELIVERY_SERVICE_TOPOLOGY_SERVERS is the API path on which Traffic Ops serves information about
+	// the servers of the Topology of a specific Delivery Service identified by an integral, unique
+	// identifier, and whether each of them is eligible to serve it. It is intended to be used with
+	// fmt.Sprintf to insert its required path parameter (namely the ID of the Delivery Service of interest).
+	// See Also: https://traffic-control-cdn.readthedocs.io/en/latest/api/v3/deliveryservices_id_topology_servers.html
+	API_DELIVERY_SERVICE_TOPOLOGY_SERVERS = API_DELIVERY_SERVICE_ID + "/topology/servers"
+
 	// API_DELIVERY_SERVICES_SAFE_UPDATE is the API path on which Traffic Ops provides the functionality to
 	// update the "safe" subset of properties of a Delivery Service identified by an integral, unique
 	// identifer. It is intended to be used with fmt.Sprintf to insert its required path parameter
@@ -273,6 +288,24 @@ func (to *Session) GetDeliveryServiceHealth(id string, header http.Header) (*tc.
 	return &data.Response, reqInf, nil
 }
 
+// GetDeliveryServiceConsistentHash gets the caches of each cachegroup to which the request path
+// 'requestPath' of the Delivery Service identified by the integral, unique identifier 'id'
+// consistently hashes, according to the current snapshot of its CDN. If 'cachegroup' is not
+// empty, only the caches of the Cache Group by that name are returned.
+func (to *Session) GetDeliveryServiceConsistentHash(id int, requestPath string, cachegroup string, header http.Header) (*tc.DeliveryServiceConsistentHash, ReqInf, error) {
+	params := url.Values{}
+	params.Set("requestPath", requestPath)
+	if cachegroup != "" {
+		params.Set("cachegroup", cachegroup)
+	}
+	var data tc.DeliveryServiceConsistentHashResponse
+	reqInf, err := get(to, fmt.Sprintf(API_DELIVERY_SERVICE_CONSISTENT_HASH, id)+"?"+params.Encode(), &data, header)
+	if err != nil {
+		return nil, reqInf, err
+	}
+	return &data.Response, reqInf, nil
+}
+
 // GetDeliveryServiceCapacity gets the 'capacity' of the Delivery Service identified by the
 // integral, unique identifier 'id' (which must be passed as a string).
 func (to *Session) GetDeliveryServiceCapacity(id string, header http.Header) (*tc.DeliveryServiceCapacity, ReqInf, error) {
@@ -335,6 +368,15 @@ func (to *Session) GetDeliveryServicesEligible(dsID int, header http.Header) ([]
 	return resp.Response, reqInf, nil
 }
 
+// GetDeliveryServiceTopologyServers returns the servers of each tier of the Topology of the
+// Delivery Service identified by the integral, unique identifier 'dsID', and whether each of them
+// is eligible to serve it.
+func (to *Session) GetDeliveryServiceTopologyServers(dsID int, header http.Header) (tc.DeliveryServiceTopologyServers, ReqInf, error) {
+	var resp tc.DeliveryServiceTopologyServersResponse
+	reqInf, err := get(to, fmt.Sprintf(API_DELIVERY_SERVICE_TOPOLOGY_SERVERS, dsID), &resp, header)
+	return resp.Response, reqInf, err
+}
+
 // GetDeliveryServiceURLSigKeys returns the URL-signing keys used by the Delivery Service
 // identified by the XMLID 'dsName'.
 func (to *Session) GetDeliveryServiceURLSigKeys(dsName string, header http.Header) (tc.URLSigKeys, ReqInf, error) {
@@ -209,3 +209,34 @@ func (to *Session) ReplaceFederationResolverMappingsForCurrentUser(mappings tc.D
 	err = json.NewDecoder(resp.Body).Decode(&alerts)
 	return alerts, reqInf, err
 }
+
+// PreviewFederationResolverMappingsForCurrentUser returns the changes that
+// ReplaceFederationResolverMappingsForCurrentUser would make with the given mappings, and the
+// resulting Federation data of the affected Delivery Services, without making them.
+func (to *Session) PreviewFederationResolverMappingsForCurrentUser(mappings tc.DeliveryServiceFederationResolverMappingRequest) (tc.FederationResolverMappingsPreviewResponse, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	var preview tc.FederationResolverMappingsPreviewResponse
+
+	bts, err := json.Marshal(mappings)
+	if err != nil {
+		return preview, reqInf, err
+	}
+
+	resp, remoteAddr, err := to.request(http.MethodPut, apiBase+"/federations?dryRun=true", bts, nil)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return preview, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&preview)
+	return preview, reqInf, err
+}
+
+// GetFederationResolverHistory retrieves the changes made to the Federation Resolver mappings of
+// the Federation with the given ID, most recent first.
+func (to *Session) GetFederationResolverHistory(federationID int, header http.Header) ([]tc.FederationResolverHistory, ReqInf, error) {
+	data := tc.FederationResolverHistoryResponse{}
+	inf, err := get(to, fmt.Sprintf("%s/federations/%d/history", apiBase, federationID), &data, header)
+	return data.Response, inf, err
+}
@@ -0,0 +1,38 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_GRAPHQL = apiBase + "/graphql"
+)
+
+// GraphQL executes the given GraphQL query, with the given variables, which may be nil. Errors in the query, or in
+// resolving it, are returned in the response's Errors, not as an error.
+func (to *Session) GraphQL(query string, variables map[string]interface{}) (tc.GraphQLResponse, ReqInf, error) {
+	reqBody, err := json.Marshal(tc.GraphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return tc.GraphQLResponse{}, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	var data tc.GraphQLResponse
+	reqInf, err := post(to, API_GRAPHQL, reqBody, &data)
+	return data, reqInf, err
+}
@@ -0,0 +1,77 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_MAINTENANCE_WINDOWS = apiBase + "/maintenance_windows"
+)
+
+// CreateMaintenanceWindow creates a maintenance window.
+func (to *Session) CreateMaintenanceWindow(mw tc.MaintenanceWindowNullable) (tc.MaintenanceWindowResponse, ReqInf, error) {
+	var resp tc.MaintenanceWindowResponse
+	reqBody, err := json.Marshal(mw)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, API_MAINTENANCE_WINDOWS, reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// UpdateMaintenanceWindowByID replaces the maintenance window with the given ID.
+func (to *Session) UpdateMaintenanceWindowByID(id int, mw tc.MaintenanceWindowNullable) (tc.MaintenanceWindowResponse, ReqInf, error) {
+	var resp tc.MaintenanceWindowResponse
+	reqBody, err := json.Marshal(mw)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := put(to, fmt.Sprintf("%s?id=%d", API_MAINTENANCE_WINDOWS, id), reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// GetMaintenanceWindows returns the maintenance windows, optionally filtered by the given query parameters, e.g.
+// "scope", "serverId", "cachegroupId" or "topology".
+func (to *Session) GetMaintenanceWindows(params url.Values, header http.Header) ([]tc.MaintenanceWindowNullable, ReqInf, error) {
+	route := API_MAINTENANCE_WINDOWS
+	if len(params) > 0 {
+		route += "?" + params.Encode()
+	}
+	var resp tc.MaintenanceWindowsResponse
+	reqInf, err := get(to, route, &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// GetMaintenanceWindowByID returns the maintenance window with the given ID.
+func (to *Session) GetMaintenanceWindowByID(id int, header http.Header) ([]tc.MaintenanceWindowNullable, ReqInf, error) {
+	var resp tc.MaintenanceWindowsResponse
+	reqInf, err := get(to, fmt.Sprintf("%s?id=%d", API_MAINTENANCE_WINDOWS, id), &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// DeleteMaintenanceWindowByID deletes the maintenance window with the given ID.
+func (to *Session) DeleteMaintenanceWindowByID(id int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := del(to, fmt.Sprintf("%s?id=%d", API_MAINTENANCE_WINDOWS, id), &alerts)
+	return alerts, reqInf, err
+}
@@ -0,0 +1,31 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_OPENAPI = apiBase + "/openapi.json"
+)
+
+// GetOpenAPIDocument gets the OpenAPI document describing the version of the Traffic Ops API used by the client.
+func (to *Session) GetOpenAPIDocument() (tc.OpenAPIDocument, ReqInf, error) {
+	var data tc.OpenAPIDocument
+	reqInf, err := get(to, API_OPENAPI, &data, nil)
+	return data, reqInf, err
+}
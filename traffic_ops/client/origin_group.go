@@ -0,0 +1,63 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_ORIGIN_GROUPS = apiBase + "/origin_groups"
+	// API_DELIVERY_SERVICE_ORIGIN_GROUP is the API path on which Traffic Ops serves the Origin Group of a Delivery
+	// Service. It is intended to be used with fmt.Sprintf to insert its required path parameter (namely the ID of the
+	// Delivery Service).
+	API_DELIVERY_SERVICE_ORIGIN_GROUP = apiBase + "/deliveryservices/%d/origin_group"
+)
+
+// GetOriginGroups returns the origin groups of delivery services, optionally filtered by the given query parameters,
+// e.g. "deliveryServiceId" or "cdn".
+func (to *Session) GetOriginGroups(params url.Values, header http.Header) ([]tc.OriginGroup, ReqInf, error) {
+	route := API_ORIGIN_GROUPS
+	if len(params) > 0 {
+		route += "?" + params.Encode()
+	}
+	var resp tc.OriginGroupsResponse
+	reqInf, err := get(to, route, &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// UpdateDeliveryServiceOriginGroup creates or replaces the origin group of the delivery service with the given ID.
+func (to *Session) UpdateDeliveryServiceOriginGroup(dsID int, group tc.OriginGroup) (tc.OriginGroupResponse, ReqInf, error) {
+	var resp tc.OriginGroupResponse
+	reqBody, err := json.Marshal(group)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := put(to, fmt.Sprintf(API_DELIVERY_SERVICE_ORIGIN_GROUP, dsID), reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// DeleteDeliveryServiceOriginGroup deletes the origin group of the delivery service with the given ID.
+func (to *Session) DeleteDeliveryServiceOriginGroup(dsID int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := del(to, fmt.Sprintf(API_DELIVERY_SERVICE_ORIGIN_GROUP, dsID), &alerts)
+	return alerts, reqInf, err
+}
@@ -0,0 +1,62 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_SERVER_LIFECYCLE = apiBase + "/servers/%d/lifecycle"
+	API_SERVER_HWINFO    = apiBase + "/servers/%d/hwinfo"
+)
+
+// GetServerLifecycle returns the lifecycle of the server with the given ID: the lifecycle states it may transition
+// to, and whether the gate checks of each transition pass.
+func (to *Session) GetServerLifecycle(id int, header http.Header) (tc.ServerLifecycle, ReqInf, error) {
+	var resp tc.ServerLifecycleResponse
+	reqInf, err := get(to, fmt.Sprintf(API_SERVER_LIFECYCLE, id), &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// TransitionServerLifecycle transitions the server with the given ID to the given lifecycle state.
+func (to *Session) TransitionServerLifecycle(id int, state tc.ServerLifecycleState) (tc.ServerLifecycleResponse, ReqInf, error) {
+	var resp tc.ServerLifecycleResponse
+	reqBody, err := json.Marshal(tc.ServerLifecycleTransitionRequest{State: state})
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, fmt.Sprintf(API_SERVER_LIFECYCLE, id), reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// PostServerHardwareInventory replaces the hwinfo of the server with the given ID with the given hardware inventory.
+func (to *Session) PostServerHardwareInventory(id int, inv tc.ServerHardwareInventory) ([]tc.HWInfo, ReqInf, error) {
+	resp := struct {
+		Response []tc.HWInfo `json:"response"`
+		tc.Alerts
+	}{}
+	reqBody, err := json.Marshal(inv)
+	if err != nil {
+		return nil, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, fmt.Sprintf(API_SERVER_HWINFO, id), reqBody, &resp)
+	return resp.Response, reqInf, err
+}
@@ -17,6 +17,7 @@ package client
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
@@ -42,3 +43,25 @@ func (to *Session) Steering(header http.Header) ([]tc.Steering, ReqInf, error) {
 	err = json.NewDecoder(resp.Body).Decode(&data)
 	return data.Response, reqInf, err
 }
+
+// SimulateSteering simulates Traffic Router's routing of a client request of the steering Delivery
+// Service with the given ID, according to the current snapshot of its CDN.
+func (to *Session) SimulateSteering(dsID int, req tc.SteeringSimulationRequest) (tc.SteeringSimulationResponse, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	var simulation tc.SteeringSimulationResponse
+
+	bts, err := json.Marshal(req)
+	if err != nil {
+		return simulation, reqInf, err
+	}
+
+	resp, remoteAddr, err := to.request(http.MethodPost, fmt.Sprintf("%s/steering/%d/simulate", apiBase, dsID), bts, nil)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return simulation, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&simulation)
+	return simulation, reqInf, err
+}
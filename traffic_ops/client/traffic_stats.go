@@ -15,6 +15,9 @@ package client
 */
 
 import (
+	"net/url"
+	"time"
+
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
@@ -27,3 +30,14 @@ func (to *Session) GetCurrentStats() (tc.TrafficStatsCDNStatsResponse, ReqInf, e
 	}
 	return resp, reqInf, nil
 }
+
+// GetCapacityPlan gets the capacity plan of the given CDN over the given period of time.
+func (to *Session) GetCapacityPlan(cdn string, start time.Time, end time.Time) (tc.CapacityPlanResponse, ReqInf, error) {
+	params := url.Values{}
+	params.Set("cdnName", cdn)
+	params.Set("startDate", start.Format(time.RFC3339))
+	params.Set("endDate", end.Format(time.RFC3339))
+	resp := tc.CapacityPlanResponse{}
+	reqInf, err := get(to, apiBase+"/capacity_planning?"+params.Encode(), &resp, nil)
+	return resp, reqInf, err
+}
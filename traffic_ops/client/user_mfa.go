@@ -0,0 +1,73 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_USER_CURRENT_MFA = apiBase + "/user/current/mfa"
+)
+
+// GetCurrentUserMFA returns the state of the multi-factor authentication of the current user.
+func (to *Session) GetCurrentUserMFA(header http.Header) (tc.UserMFAStatus, ReqInf, error) {
+	var resp tc.UserMFAStatusResponse
+	reqInf, err := get(to, API_USER_CURRENT_MFA, &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// EnrollCurrentUserMFA generates a new TOTP secret for the current user, which takes effect once a code of it is
+// verified with VerifyCurrentUserMFA.
+func (to *Session) EnrollCurrentUserMFA() (tc.UserMFAEnrollmentResponse, ReqInf, error) {
+	var resp tc.UserMFAEnrollmentResponse
+	reqInf, err := post(to, API_USER_CURRENT_MFA+"/enroll", nil, &resp)
+	return resp, reqInf, err
+}
+
+// VerifyCurrentUserMFA verifies a TOTP or recovery code of the current user, which enables multi-factor
+// authentication if they're enrolling and verifies the second factor of the session either way.
+func (to *Session) VerifyCurrentUserMFA(otp string) (tc.UserMFAVerificationResponse, ReqInf, error) {
+	var resp tc.UserMFAVerificationResponse
+	reqBody, err := json.Marshal(tc.UserMFACodeRequest{OTP: otp})
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, API_USER_CURRENT_MFA+"/verify", reqBody, &resp)
+	return resp, reqInf, err
+}
+
+// DisableCurrentUserMFA disables the multi-factor authentication of the current user, given a TOTP or recovery code.
+func (to *Session) DisableCurrentUserMFA(otp string) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqBody, err := json.Marshal(tc.UserMFACodeRequest{OTP: otp})
+	if err != nil {
+		return alerts, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, API_USER_CURRENT_MFA+"/disable", reqBody, &alerts)
+	return alerts, reqInf, err
+}
+
+// ResetUserMFA disables the multi-factor authentication of the user with the given ID.
+func (to *Session) ResetUserMFA(id int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := post(to, fmt.Sprintf("%s/users/%d/mfa/reset", apiBase, id), nil, &alerts)
+	return alerts, reqInf, err
+}
@@ -0,0 +1,48 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_USER_CURRENT_SESSIONS = apiBase + "/user/current/sessions"
+)
+
+// GetCurrentUserSessions returns the unexpired sessions of the current user.
+func (to *Session) GetCurrentUserSessions(header http.Header) ([]tc.UserSession, ReqInf, error) {
+	var resp tc.UserSessionsResponse
+	reqInf, err := get(to, API_USER_CURRENT_SESSIONS, &resp, header)
+	return resp.Response, reqInf, err
+}
+
+// DeleteUserSessions logs the user with the given ID out of all of their sessions, except the one of this Session.
+func (to *Session) DeleteUserSessions(id int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := del(to, fmt.Sprintf("%s/users/%d/sessions", apiBase, id), &alerts)
+	return alerts, reqInf, err
+}
+
+// UnlockUser unlocks the user with the given ID, if they were locked out after too many failed logins.
+func (to *Session) UnlockUser(id int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := post(to, fmt.Sprintf("%s/users/%d/unlock", apiBase, id), nil, &alerts)
+	return alerts, reqInf, err
+}
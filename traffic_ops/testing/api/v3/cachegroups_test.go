@@ -25,6 +25,8 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
 )
 
 func TestCacheGroups(t *testing.T) {
@@ -47,6 +49,79 @@ func TestCacheGroups(t *testing.T) {
 	})
 }
 
+func TestCacheGroupsTenancy(t *testing.T) {
+	WithObjs(t, []TCObj{Types, Tenants, Users, Parameters, CacheGroups}, func() {
+		CacheGroupTenancyTest(t)
+	})
+}
+
+func CacheGroupTenancyTest(t *testing.T) {
+	tenant3, _, err := TOSession.TenantByName("tenant3", nil)
+	if err != nil {
+		t.Fatalf("cannot GET tenant3: %v", err)
+	}
+	cgs, _, err := TOSession.GetCacheGroupNullableByName(*testData.CacheGroups[0].Name, nil)
+	if err != nil || len(cgs) != 1 {
+		t.Fatalf("cannot GET cachegroup %s: %v", *testData.CacheGroups[0].Name, err)
+	}
+	cg := cgs[0]
+	cg.ID = nil
+	cg.Name = util.StrPtr("tenant3Cachegroup")
+	cg.ShortName = util.StrPtr("t3cg")
+	cg.ParentCachegroupID, cg.ParentName = nil, nil
+	cg.SecondaryParentCachegroupID, cg.SecondaryParentName = nil, nil
+	cg.Fallbacks = &[]string{}
+	cg.TenantID = &tenant3.ID
+	resp, _, err := TOSession.CreateCacheGroupNullable(cg)
+	if err != nil {
+		t.Fatalf("cannot CREATE cachegroup owned by tenant3: %v", err)
+	}
+	tenant3CG := resp.Response
+	if tenant3CG.Tenant == nil || *tenant3CG.Tenant != "tenant3" {
+		t.Errorf("expected cachegroup tenant to be 'tenant3', actual: %v", tenant3CG.Tenant)
+	}
+	defer func() {
+		if _, _, err := TOSession.DeleteCacheGroupByID(*tenant3CG.ID); err != nil {
+			t.Errorf("cannot DELETE cachegroup %s: %v", *tenant3CG.Name, err)
+		}
+	}()
+
+	toReqTimeout := time.Second * time.Duration(Config.Default.Session.TimeoutInSecs)
+	tenant4TOClient, _, err := toclient.LoginWithAgent(TOSession.URL, "tenant4user", "pa$$word", true, "to-api-v3-client-tests/tenant4user", true, toReqTimeout)
+	if err != nil {
+		t.Fatalf("failed to log in with tenant4user: %v", err)
+	}
+
+	cachegroupsReadableByTenant4, _, err := tenant4TOClient.GetCacheGroupsNullable(nil)
+	if err != nil {
+		t.Fatalf("tenant4user cannot GET cachegroups: %v", err)
+	}
+	foundShared := false
+	for _, c := range cachegroupsReadableByTenant4 {
+		if *c.Name == *tenant3CG.Name {
+			t.Error("expected tenant4user to be unable to read cachegroups of tenant3")
+		}
+		if *c.Name == *testData.CacheGroups[0].Name {
+			foundShared = true
+		}
+	}
+	if !foundShared {
+		t.Errorf("expected tenant4user to be able to read cachegroup %s, which has no tenant", *testData.CacheGroups[0].Name)
+	}
+
+	if _, _, err := tenant4TOClient.UpdateCacheGroupNullableByID(*tenant3CG.ID, tenant3CG); err == nil {
+		t.Error("expected tenant4user to be unable to update a cachegroup of tenant3")
+	}
+	if _, _, err := tenant4TOClient.DeleteCacheGroupByID(*tenant3CG.ID); err == nil {
+		t.Error("expected tenant4user to be unable to delete a cachegroup of tenant3")
+	}
+	cg.Name = util.StrPtr("tenant4Cachegroup")
+	cg.ShortName = util.StrPtr("t4cg")
+	if _, _, err := tenant4TOClient.CreateCacheGroupNullable(cg); err == nil {
+		t.Error("expected tenant4user to be unable to create a cachegroup owned by tenant3")
+	}
+}
+
 func GetTestCacheGroupsAfterChangeIMS(t *testing.T, header http.Header) {
 	_, reqInf, err := TOSession.GetCacheGroupsByQueryParams(url.Values{}, header)
 	if err != nil {
@@ -0,0 +1,65 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v3
+
+import (
+	"testing"
+)
+
+func TestDeliveryServiceConsistentHash(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, Topologies, DeliveryServices}, func() {
+		GetTestDeliveryServiceConsistentHash(t)
+	})
+}
+
+func GetTestDeliveryServiceConsistentHash(t *testing.T) {
+	if len(testData.DeliveryServices) < 1 {
+		t.Fatal("need at least one delivery service to test consistent hashing")
+	}
+	dses, _, err := TOSession.GetDeliveryServiceByXMLIDNullable(*testData.DeliveryServices[0].XMLID, nil)
+	if err != nil || len(dses) != 1 {
+		t.Fatalf("cannot GET delivery service %s: %v", *testData.DeliveryServices[0].XMLID, err)
+	}
+	ds := dses[0]
+	if _, err := TOSession.SnapshotCRConfig(*ds.CDNName); err != nil {
+		t.Fatalf("cannot snapshot CDN %s: %v", *ds.CDNName, err)
+	}
+
+	hash, _, err := TOSession.GetDeliveryServiceConsistentHash(*ds.ID, "/some/path/asset.m3u8", "", nil)
+	if err != nil {
+		t.Fatalf("cannot GET consistent hash of delivery service %s: %v", *ds.XMLID, err)
+	}
+	if hash.DeliveryService != *ds.XMLID {
+		t.Errorf("expected delivery service %s, actual %s", *ds.XMLID, hash.DeliveryService)
+	}
+	if hash.ResultingPathToConsistentHash == "" {
+		t.Error("expected a resulting path to consistent hash, actual empty")
+	}
+	for _, cg := range hash.Cachegroups {
+		hash, _, err := TOSession.GetDeliveryServiceConsistentHash(*ds.ID, "/some/path/asset.m3u8", cg.Name, nil)
+		if err != nil {
+			t.Errorf("cannot GET consistent hash of delivery service %s for cachegroup %s: %v", *ds.XMLID, cg.Name, err)
+			continue
+		}
+		if len(hash.Cachegroups) != 1 || hash.Cachegroups[0].Name != cg.Name {
+			t.Errorf("expected only cachegroup %s, actual %+v", cg.Name, hash.Cachegroups)
+		}
+	}
+
+	if _, _, err := TOSession.GetDeliveryServiceConsistentHash(*ds.ID, "", "", nil); err == nil {
+		t.Error("expected an error without a request path, actual nil")
+	}
+}
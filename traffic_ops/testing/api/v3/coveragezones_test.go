@@ -0,0 +1,155 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v3
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestCoverageZones(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers}, func() {
+		CRUDTestCoverageZones(t)
+	})
+}
+
+// getCoverageZoneTestObjs returns a CDN, an EDGE_LOC cachegroup and a cachegroup of any other type from the test data.
+func getCoverageZoneTestObjs(t *testing.T) (tc.CDN, tc.CacheGroupNullable, tc.CacheGroupNullable) {
+	cdns, _, err := TOSession.GetCDNs(nil)
+	if err != nil || len(cdns) == 0 {
+		t.Fatalf("cannot GET CDNs: %v", err)
+	}
+	cgs, _, err := TOSession.GetCacheGroupsNullable(nil)
+	if err != nil {
+		t.Fatalf("cannot GET cachegroups: %v", err)
+	}
+	edge, other := tc.CacheGroupNullable{}, tc.CacheGroupNullable{}
+	for _, cg := range cgs {
+		if cg.Type == nil {
+			continue
+		}
+		if *cg.Type == tc.CacheGroupEdgeTypeName && edge.ID == nil {
+			edge = cg
+		} else if *cg.Type != tc.CacheGroupEdgeTypeName && other.ID == nil {
+			other = cg
+		}
+	}
+	if edge.ID == nil || other.ID == nil {
+		t.Fatal("expected an EDGE_LOC cachegroup and a cachegroup of another type in the test data")
+	}
+	return cdns[0], edge, other
+}
+
+func CRUDTestCoverageZones(t *testing.T) {
+	cdn, edge, other := getCoverageZoneTestObjs(t)
+	cz := tc.CoverageZoneNullable{
+		CDNID:        util.IntPtr(cdn.ID),
+		CachegroupID: edge.ID,
+		Network:      util.StrPtr("192.0.2.0/24"),
+	}
+	created, _, err := TOSession.CreateCoverageZone(cz)
+	if err != nil {
+		t.Fatalf("cannot POST coverage zone: %v - alerts: %+v", err, created.Alerts)
+	}
+	if created.Response.ID == nil {
+		t.Fatalf("expected the created coverage zone to have an ID, alerts: %+v", created.Alerts)
+	}
+	id := *created.Response.ID
+
+	invalid := map[string]tc.CoverageZoneNullable{
+		"an overlapping network":     {CDNID: cz.CDNID, CachegroupID: edge.ID, Network: util.StrPtr("192.0.2.128/25")},
+		"a non-edge cachegroup":      {CDNID: cz.CDNID, CachegroupID: other.ID, Network: util.StrPtr("198.51.100.0/24")},
+		"a nonexistent cachegroup":   {CDNID: cz.CDNID, CachegroupID: util.IntPtr(999999), Network: util.StrPtr("198.51.100.0/24")},
+		"a network with host bits":   {CDNID: cz.CDNID, CachegroupID: edge.ID, Network: util.StrPtr("198.51.100.1/24")},
+		"a network that isn't a net": {CDNID: cz.CDNID, CachegroupID: edge.ID, Network: util.StrPtr("198.51.100.0")},
+	}
+	for name, bad := range invalid {
+		_, reqInf, err := TOSession.CreateCoverageZone(bad)
+		if err == nil || reqInf.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected creating a coverage zone with %s to fail with 400, actual: %d", name, reqInf.StatusCode)
+		}
+	}
+
+	deep := tc.CoverageZoneNullable{
+		CDNID:        cz.CDNID,
+		CachegroupID: edge.ID,
+		Network:      util.StrPtr("192.0.2.0/26"),
+		Deep:         util.BoolPtr(true),
+	}
+	createdDeep, _, err := TOSession.CreateCoverageZone(deep)
+	if err != nil {
+		t.Fatalf("expected a deep coverage zone overlapping a coverage zone to be allowed, got: %v - alerts: %+v", err, createdDeep.Alerts)
+	}
+
+	zones, _, err := TOSession.LookupCoverageZones("192.0.2.7", cdn.Name, nil)
+	if err != nil {
+		t.Fatalf("cannot GET coverage zone lookup: %v", err)
+	}
+	if len(zones) != 2 {
+		t.Errorf("expected 192.0.2.7 to be in a coverage zone and a deep coverage zone, actual: %+v", zones)
+	}
+	if zones, _, err = TOSession.LookupCoverageZones("192.0.2.200", cdn.Name, nil); err != nil {
+		t.Errorf("cannot GET coverage zone lookup: %v", err)
+	} else if len(zones) != 1 || zones[0].ID == nil || *zones[0].ID != id {
+		t.Errorf("expected 192.0.2.200 to be in coverage zone %d only, actual: %+v", id, zones)
+	}
+
+	czf, _, err := TOSession.GetCoverageZoneFile(cdn.Name, nil)
+	if err != nil {
+		t.Fatalf("cannot GET coverage zone file: %v", err)
+	}
+	if loc, ok := czf.CoverageZones[*edge.Name]; !ok || len(loc.Network) != 1 || loc.Network[0] != "192.0.2.0/24" {
+		t.Errorf("expected cachegroup %s to cover 192.0.2.0/24 in the coverage zone file, actual: %+v", *edge.Name, czf)
+	}
+	dczf, _, err := TOSession.GetDeepCoverageZoneFile(cdn.Name, nil)
+	if err != nil {
+		t.Fatalf("cannot GET deep coverage zone file: %v", err)
+	}
+	if loc, ok := dczf.DeepCoverageZones[*edge.Name]; !ok || len(loc.Network) != 1 || loc.Network[0] != "192.0.2.0/26" {
+		t.Errorf("expected cachegroup %s to cover 192.0.2.0/26 in the deep coverage zone file, actual: %+v", *edge.Name, dczf)
+	}
+
+	updated := created.Response
+	updated.Network = util.StrPtr("198.51.100.0/24")
+	if _, _, err := TOSession.UpdateCoverageZoneByID(id, updated); err != nil {
+		t.Errorf("cannot PUT coverage zone: %v", err)
+	}
+	params := url.Values{}
+	params.Set("cdn", cdn.Name)
+	params.Set("deep", "false")
+	zones, _, err = TOSession.GetCoverageZones(params, nil)
+	if err != nil {
+		t.Fatalf("cannot GET coverage zones: %v", err)
+	}
+	if len(zones) != 1 || zones[0].Network == nil || *zones[0].Network != "198.51.100.0/24" {
+		t.Errorf("expected the coverage zone network to be updated, actual: %+v", zones)
+	}
+
+	for _, zoneID := range []int{id, *createdDeep.Response.ID} {
+		if _, _, err := TOSession.DeleteCoverageZoneByID(zoneID); err != nil {
+			t.Errorf("cannot DELETE coverage zone: %v", err)
+		}
+		if zones, _, err := TOSession.GetCoverageZoneByID(zoneID, nil); err != nil {
+			t.Errorf("cannot GET coverage zone by id: %v", err)
+		} else if len(zones) != 0 {
+			t.Errorf("expected the coverage zone to be deleted, actual: %+v", zones)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package v3
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestDeliveryServiceTopologyServers(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, ServerCapabilities, ServerServerCapabilities, Topologies, DeliveryServices}, func() {
+		GetTestDeliveryServiceTopologyServers(t)
+		CreateTestDeliveryServiceRequiredCapabilityMissingFromTopology(t)
+	})
+}
+
+func getTopologyDeliveryService(t *testing.T) tc.DeliveryServiceNullable {
+	dses, _, err := TOSession.GetDeliveryServiceByXMLIDNullable("ds-top", nil)
+	if err != nil {
+		t.Fatalf("cannot GET Delivery Service ds-top: %v", err)
+	}
+	if len(dses) != 1 || dses[0].ID == nil {
+		t.Fatalf("expected exactly one Delivery Service ds-top, got %d", len(dses))
+	}
+	return dses[0]
+}
+
+func GetTestDeliveryServiceTopologyServers(t *testing.T) {
+	ds := getTopologyDeliveryService(t)
+	topologyServers, _, err := TOSession.GetDeliveryServiceTopologyServers(*ds.ID, nil)
+	if err != nil {
+		t.Fatalf("cannot GET topology servers of Delivery Service ds-top: %v", err)
+	}
+	if topologyServers.Topology != *ds.Topology {
+		t.Errorf("expected topology %s, got %s", *ds.Topology, topologyServers.Topology)
+	}
+	if len(topologyServers.Tiers) == 0 {
+		t.Fatal("expected the topology of Delivery Service ds-top to have at least one tier, got none")
+	}
+	for _, tier := range topologyServers.Tiers {
+		if tier.Type == tc.CacheGroupOriginTypeName {
+			continue
+		}
+		if tier.EligibleServers == 0 {
+			t.Errorf("expected the %s tier of the topology of Delivery Service ds-top to have eligible servers, got none", tier.Type)
+		}
+	}
+
+	dses, _, err := TOSession.GetDeliveryServicesNullable(nil)
+	if err != nil {
+		t.Fatalf("cannot GET Delivery Services: %v", err)
+	}
+	for _, ds := range dses {
+		if ds.Topology != nil {
+			continue
+		}
+		_, reqInf, err := TOSession.GetDeliveryServiceTopologyServers(*ds.ID, nil)
+		if err == nil {
+			t.Errorf("expected an error getting the topology servers of Delivery Service %s without a topology, got none", *ds.XMLID)
+		}
+		if reqInf.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status code %d, got %d", http.StatusBadRequest, reqInf.StatusCode)
+		}
+		break
+	}
+}
+
+func CreateTestDeliveryServiceRequiredCapabilityMissingFromTopology(t *testing.T) {
+	ds := getTopologyDeliveryService(t)
+	// only an edge has the "bar" capability, so the mid tier of the topology
+	// could not serve the Delivery Service if it were required
+	_, reqInf, err := TOSession.CreateDeliveryServicesRequiredCapability(tc.DeliveryServicesRequiredCapability{
+		DeliveryServiceID:  ds.ID,
+		RequiredCapability: util.StrPtr("bar"),
+	})
+	if err == nil {
+		t.Fatal("expected an error requiring a capability no mid in the topology has, got none")
+	}
+	if reqInf.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, reqInf.StatusCode)
+	}
+}
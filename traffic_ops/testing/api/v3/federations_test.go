@@ -32,6 +32,8 @@ func TestFederations(t *testing.T) {
 		GetTestFederations(t)
 		GetTestFederationsIMS(t)
 		AddFederationResolversForCurrentUserTest(t)
+		PreviewReplaceFederationResolversForCurrentUserTest(t)
+		GetTestFederationResolverHistory(t)
 		RemoveFederationResolversForCurrentUserTest(t)
 	})
 }
@@ -266,3 +268,87 @@ func AddFederationResolversForCurrentUserTest(t *testing.T) {
 		}
 	}
 }
+
+func PreviewReplaceFederationResolversForCurrentUserTest(t *testing.T) {
+	before, _, err := TOSession.Federations(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error getting Federations of the current user: %v", err)
+	}
+	if len(before) < 1 {
+		t.Fatal("Current user has no Federations, previewing a replacement of its resolvers cannot be tested!")
+	}
+	ds := before[0].DeliveryService
+
+	mappings := tc.DeliveryServiceFederationResolverMappingRequest{
+		tc.DeliveryServiceFederationResolverMapping{
+			DeliveryService: string(ds),
+			Mappings: tc.ResolverMapping{
+				Resolve4: []string{"192.0.2.7/24"},
+			},
+		},
+	}
+	preview, _, err := TOSession.PreviewFederationResolverMappingsForCurrentUser(mappings)
+	if err != nil {
+		t.Fatalf("Unexpected error previewing replacement of Federation Resolver mappings for the current user: %v", err)
+	}
+
+	added := false
+	for _, fed := range preview.Response.Added {
+		if fed.DeliveryService != ds {
+			continue
+		}
+		for _, mapping := range fed.Mappings {
+			for _, res := range mapping.Resolve4 {
+				if res == "192.0.2.0/24" {
+					added = true
+				}
+			}
+		}
+	}
+	if !added {
+		t.Errorf("Expected the preview to add the normalized resolver 192.0.2.0/24 to Delivery Service %s, got: %+v", ds, preview.Response.Added)
+	}
+	if len(preview.Response.Removed) < 1 {
+		t.Errorf("Expected the preview to remove the existing resolvers, got none removed")
+	}
+
+	after, _, err := TOSession.Federations(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error getting Federations of the current user: %v", err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("Expected a dry run not to change the Federations of the current user, had %d Delivery Services' Federations before and %d after", len(before), len(after))
+	}
+	for _, fed := range after {
+		for _, mapping := range fed.Mappings {
+			for _, res := range mapping.Resolve4 {
+				if res == "192.0.2.0/24" {
+					t.Errorf("Expected a dry run not to add resolver %s to Delivery Service %s", res, fed.DeliveryService)
+				}
+			}
+		}
+	}
+}
+
+func GetTestFederationResolverHistory(t *testing.T) {
+	if len(fedIDs) == 0 {
+		t.Fatal("no federations, must have at least 1 federation to test federation resolver history")
+	}
+
+	history, _, err := TOSession.GetFederationResolverHistory(fedIDs[0], nil)
+	if err != nil {
+		t.Fatalf("Unexpected error getting Federation Resolver history: %v", err)
+	}
+	found := false
+	for _, h := range history {
+		if h.Action == tc.FederationResolverHistoryAdded && h.IPAddress == "0.0.0.0" {
+			found = true
+		}
+		if h.Action == tc.FederationResolverHistoryAdded && h.IPAddress == "192.0.2.0/24" {
+			t.Errorf("Expected a dry run not to be recorded in the Federation Resolver history, found: %+v", h)
+		}
+	}
+	if !found {
+		t.Errorf("Expected the addition of resolver 0.0.0.0 in the history of Federation %d, got: %+v", fedIDs[0], history)
+	}
+}
@@ -0,0 +1,159 @@
+package v3
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+func TestGraphQL(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, Topologies, DeliveryServices}, func() {
+		GetTestGraphQLTopologies(t)
+		GetTestGraphQLDeliveryServices(t)
+		GetTestGraphQLErrors(t)
+	})
+}
+
+func GetTestGraphQLTopologies(t *testing.T) {
+	resp, _, err := TOSession.GraphQL(`query($name: String) {
+		topologies(name: $name) {
+			name
+			nodes { cachegroup { name } }
+			deliveryServices { xmlId }
+		}
+	}`, map[string]interface{}{"name": testData.Topologies[0].Name})
+	if err != nil {
+		t.Fatalf("cannot POST GraphQL query: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("expected no GraphQL errors, actual: %+v", resp.Errors)
+	}
+
+	data := struct {
+		Topologies []struct {
+			Name  string `json:"name"`
+			Nodes []struct {
+				Cachegroup struct {
+					Name string `json:"name"`
+				} `json:"cachegroup"`
+			} `json:"nodes"`
+			DeliveryServices []struct {
+				XMLID string `json:"xmlId"`
+			} `json:"deliveryServices"`
+		} `json:"topologies"`
+	}{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("cannot decode GraphQL data: %v", err)
+	}
+	if len(data.Topologies) != 1 {
+		t.Fatalf("expected 1 topology named %s, actual: %d", testData.Topologies[0].Name, len(data.Topologies))
+	}
+
+	topology, _, err := TOSession.GetTopology(testData.Topologies[0].Name, nil)
+	if err != nil {
+		t.Fatalf("cannot GET topology %s: %v", testData.Topologies[0].Name, err)
+	}
+	expectedNodes := []string{}
+	for _, node := range topology.Nodes {
+		expectedNodes = append(expectedNodes, node.Cachegroup)
+	}
+	actualNodes := []string{}
+	for _, node := range data.Topologies[0].Nodes {
+		actualNodes = append(actualNodes, node.Cachegroup.Name)
+	}
+	sort.Strings(expectedNodes)
+	sort.Strings(actualNodes)
+	if len(expectedNodes) != len(actualNodes) {
+		t.Fatalf("expected topology nodes %v, actual: %v", expectedNodes, actualNodes)
+	}
+	for i := range expectedNodes {
+		if expectedNodes[i] != actualNodes[i] {
+			t.Errorf("expected topology nodes %v, actual: %v", expectedNodes, actualNodes)
+			break
+		}
+	}
+
+	dses, _, err := TOSession.GetDeliveryServicesNullable(nil)
+	if err != nil {
+		t.Fatalf("cannot GET delivery services: %v", err)
+	}
+	expectedDSes := map[string]struct{}{}
+	for _, ds := range dses {
+		if ds.Topology != nil && *ds.Topology == topology.Name {
+			expectedDSes[*ds.XMLID] = struct{}{}
+		}
+	}
+	if len(expectedDSes) != len(data.Topologies[0].DeliveryServices) {
+		t.Errorf("expected %d delivery services of topology %s, actual: %d", len(expectedDSes), topology.Name, len(data.Topologies[0].DeliveryServices))
+	}
+	for _, ds := range data.Topologies[0].DeliveryServices {
+		if _, ok := expectedDSes[ds.XMLID]; !ok {
+			t.Errorf("expected delivery service %s to not use topology %s", ds.XMLID, topology.Name)
+		}
+	}
+}
+
+func GetTestGraphQLDeliveryServices(t *testing.T) {
+	resp, _, err := TOSession.GraphQL(`{ deliveryServices { xmlId tenant servers { hostName } } }`, nil)
+	if err != nil {
+		t.Fatalf("cannot POST GraphQL query: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("expected no GraphQL errors, actual: %+v", resp.Errors)
+	}
+	data := struct {
+		DeliveryServices []struct {
+			XMLID string `json:"xmlId"`
+		} `json:"deliveryServices"`
+	}{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("cannot decode GraphQL data: %v", err)
+	}
+
+	// the tenancy of the GraphQL API must match that of the REST API
+	dses, _, err := TOSession.GetDeliveryServicesNullable(nil)
+	if err != nil {
+		t.Fatalf("cannot GET delivery services: %v", err)
+	}
+	if len(dses) != len(data.DeliveryServices) {
+		t.Errorf("expected %d delivery services, actual: %d", len(dses), len(data.DeliveryServices))
+	}
+}
+
+func GetTestGraphQLErrors(t *testing.T) {
+	resp, _, err := TOSession.GraphQL(`{ servers { noSuchField } }`, nil)
+	if err != nil {
+		t.Fatalf("expected GraphQL errors in the response, not an error: %v", err)
+	}
+	if len(resp.Errors) == 0 {
+		t.Error("expected GraphQL errors querying a nonexistent field, actual: none")
+	}
+
+	resp, _, err = TOSession.GraphQL(`mutation { servers { hostName } }`, nil)
+	if err != nil {
+		t.Fatalf("expected GraphQL errors in the response, not an error: %v", err)
+	}
+	if len(resp.Errors) == 0 {
+		t.Error("expected GraphQL errors executing a mutation, actual: none")
+	}
+
+	_, _, err = NoAuthTOSession.GraphQL(`{ servers { hostName } }`, nil)
+	if err == nil {
+		t.Error("expected error from GraphQL() when unauthenticated")
+	}
+}
@@ -0,0 +1,233 @@
+package v3
+
+/*
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
+)
+
+func TestInfrastructureTenancy(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Users, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, ServerCapabilities, Servers}, func() {
+		InfrastructureTenancyTest(t)
+	})
+}
+
+// InfrastructureTenancyTest creates a profile, server and cachegroup owned by tenant3, and checks that tenant4user
+// can't use any of the endpoints that change them without going through their CRUD handlers.
+func InfrastructureTenancyTest(t *testing.T) {
+	tenant3, _, err := TOSession.TenantByName("tenant3", nil)
+	if err != nil {
+		t.Fatalf("cannot GET tenant3: %v", err)
+	}
+	if len(testData.Servers) < 1 || len(testData.Parameters) < 1 || len(testData.ServerCapabilities) < 1 {
+		t.Fatal("need at least one server, parameter and server capability to test infrastructure tenancy")
+	}
+	testServer := testData.Servers[0]
+	if testServer.HostName == nil || testServer.Profile == nil || testServer.Cachegroup == nil || testServer.CDNName == nil {
+		t.Fatalf("found server with nil hostname, profile, cachegroup or CDN: %+v", testServer)
+	}
+
+	profiles, _, err := TOSession.GetProfileByName(*testServer.Profile, nil)
+	if err != nil || len(profiles) != 1 {
+		t.Fatalf("cannot GET profile %s: %v", *testServer.Profile, err)
+	}
+	profile := tc.ProfileNullable{
+		Name:            util.StrPtr("tenant3Profile"),
+		Description:     util.StrPtr("profile owned by tenant3"),
+		CDNID:           &profiles[0].CDNID,
+		RoutingDisabled: util.BoolPtr(false),
+		Type:            &profiles[0].Type,
+		TenantID:        &tenant3.ID,
+	}
+	profileBody, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatalf("cannot encode profile: %v", err)
+	}
+	if _, _, err := TOSession.RawRequest(http.MethodPost, TestAPIBase+"/profiles", profileBody, nil); err != nil {
+		t.Fatalf("cannot CREATE profile owned by tenant3: %v", err)
+	}
+	profiles, _, err = TOSession.GetProfileByName(*profile.Name, nil)
+	if err != nil || len(profiles) != 1 {
+		t.Fatalf("cannot GET profile %s: %v", *profile.Name, err)
+	}
+	tenant3ProfileID := profiles[0].ID
+	defer func() {
+		if _, _, err := TOSession.DeleteProfileByID(tenant3ProfileID); err != nil {
+			t.Errorf("cannot DELETE profile %s: %v", *profile.Name, err)
+		}
+	}()
+
+	server := testServer
+	server.ID = nil
+	server.HostName = util.StrPtr("tenant3-edge")
+	server.Profile = profile.Name
+	server.ProfileID = &tenant3ProfileID
+	server.TenantID = &tenant3.ID
+	server.Interfaces = []tc.ServerInterfaceInfo{
+		{
+			Name:    "bond0",
+			Monitor: true,
+			IPAddresses: []tc.ServerIPAddress{
+				{Address: "127.0.3.1/30", Gateway: util.StrPtr("127.0.3.2"), ServiceAddress: true},
+			},
+		},
+	}
+	if _, _, err := TOSession.CreateServer(server); err != nil {
+		t.Fatalf("cannot CREATE server owned by tenant3: %v", err)
+	}
+	params := url.Values{}
+	params.Set("hostName", *server.HostName)
+	servers, _, err := TOSession.GetServers(&params, nil)
+	if err != nil || len(servers.Response) != 1 || servers.Response[0].ID == nil || servers.Response[0].CachegroupID == nil {
+		t.Fatalf("cannot GET server %s: %v", *server.HostName, err)
+	}
+	tenant3ServerID := *servers.Response[0].ID
+	cachegroupID := *servers.Response[0].CachegroupID
+	defer func() {
+		if _, _, err := TOSession.DeleteServerByID(tenant3ServerID); err != nil {
+			t.Errorf("cannot DELETE server %s: %v", *server.HostName, err)
+		}
+	}()
+
+	cgs, _, err := TOSession.GetCacheGroupNullableByName(*testServer.Cachegroup, nil)
+	if err != nil || len(cgs) != 1 {
+		t.Fatalf("cannot GET cachegroup %s: %v", *testServer.Cachegroup, err)
+	}
+	cg := cgs[0]
+	cg.ID = nil
+	cg.Name = util.StrPtr("tenant3EdgeCachegroup")
+	cg.ShortName = util.StrPtr("t3edge")
+	cg.ParentCachegroupID, cg.ParentName = nil, nil
+	cg.SecondaryParentCachegroupID, cg.SecondaryParentName = nil, nil
+	cg.Fallbacks = &[]string{}
+	cg.TenantID = &tenant3.ID
+	cgResp, _, err := TOSession.CreateCacheGroupNullable(cg)
+	if err != nil {
+		t.Fatalf("cannot CREATE cachegroup owned by tenant3: %v", err)
+	}
+	tenant3CG := cgResp.Response
+	defer func() {
+		if _, _, err := TOSession.DeleteCacheGroupByID(*tenant3CG.ID); err != nil {
+			t.Errorf("cannot DELETE cachegroup %s: %v", *tenant3CG.Name, err)
+		}
+	}()
+
+	parameters, _, err := TOSession.GetParameterByName(testData.Parameters[0].Name, nil)
+	if err != nil || len(parameters) < 1 {
+		t.Fatalf("cannot GET parameter %s: %v", testData.Parameters[0].Name, err)
+	}
+	parameterID := parameters[0].ID
+
+	serverScope := tc.MaintenanceWindowScopeServer
+	cachegroupScope := tc.MaintenanceWindowScopeCachegroup
+	start := time.Now().Add(time.Hour)
+	end := start.Add(time.Hour)
+
+	fallbackCG := cg
+	fallbackCG.Name = util.StrPtr("tenant4FallbackCachegroup")
+	fallbackCG.ShortName = util.StrPtr("t4fb")
+	fallbackCG.Fallbacks = &[]string{*tenant3CG.Name}
+	fallbackCG.TenantID = nil
+
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"update server status", http.MethodPut, fmt.Sprintf("/servers/%d/status", tenant3ServerID), tc.ServerPutStatus{Status: util.JSONNameOrIDStr{Name: util.StrPtr("ADMIN_DOWN")}, OfflineReason: util.StrPtr("tenancy test")}},
+		{"queue server updates", http.MethodPost, fmt.Sprintf("/servers/%d/queue_update", tenant3ServerID), tc.ServerQueueUpdateRequest{Action: "queue"}},
+		{"set server update statuses", http.MethodPost, fmt.Sprintf("/servers/%d/update?updated=true", tenant3ServerID), nil},
+		{"assign delivery services to server", http.MethodPost, fmt.Sprintf("/servers/%d/deliveryservices?replace=false", tenant3ServerID), []int{}},
+		{"add server capability", http.MethodPost, "/server_server_capabilities", tc.ServerServerCapability{ServerID: &tenant3ServerID, ServerCapability: util.StrPtr(testData.ServerCapabilities[0].Name)}},
+		{"remove server capability", http.MethodDelete, fmt.Sprintf("/server_server_capabilities?serverId=%d&serverCapability=%s", tenant3ServerID, url.QueryEscape(testData.ServerCapabilities[0].Name)), nil},
+		{"create profile parameter", http.MethodPost, "/profileparameters", tc.ProfileParameter{ProfileID: tenant3ProfileID, ParameterID: parameterID}},
+		{"delete profile parameter", http.MethodDelete, fmt.Sprintf("/profileparameters/%d/%d", tenant3ProfileID, parameterID), nil},
+		{"assign parameters to profile", http.MethodPost, "/profileparameter", tc.PostProfileParam{ProfileID: util.Int64Ptr(int64(tenant3ProfileID)), ParamIDs: &[]int64{int64(parameterID)}}},
+		{"assign parameter to profiles", http.MethodPost, "/parameterprofile", tc.PostParamProfile{ParamID: util.Int64Ptr(int64(parameterID)), ProfileIDs: &[]int64{int64(tenant3ProfileID)}}},
+		{"create parameters on profile by ID", http.MethodPost, fmt.Sprintf("/profiles/%d/parameters", tenant3ProfileID), tc.ProfileParameterByNamePost{Name: util.StrPtr("tenancy"), ConfigFile: util.StrPtr("tenancy.config"), Value: util.StrPtr("tenancy")}},
+		{"create parameters on profile by name", http.MethodPost, fmt.Sprintf("/profiles/name/%s/parameters", *profile.Name), tc.ProfileParameterByNamePost{Name: util.StrPtr("tenancy"), ConfigFile: util.StrPtr("tenancy.config"), Value: util.StrPtr("tenancy")}},
+		{"queue cachegroup updates", http.MethodPost, fmt.Sprintf("/cachegroups/%d/queue_update", cachegroupID), tc.CachegroupQueueUpdatesRequest{Action: "queue", CDN: (*tc.CDNName)(testServer.CDNName)}},
+		{"assign delivery services to cachegroup", http.MethodPost, fmt.Sprintf("/cachegroups/%d/deliveryservices", cachegroupID), tc.CachegroupPostDSReq{DeliveryServices: []int{}}},
+		{"add cachegroup parameter", http.MethodPost, "/cachegroupparameters", []tc.CacheGroupParameterRequest{{CacheGroupID: *tenant3CG.ID, ParameterID: parameterID}}},
+		{"create cachegroup falling back to cachegroup of tenant3", http.MethodPost, "/cachegroups", fallbackCG},
+		{"schedule server maintenance", http.MethodPost, "/maintenance_windows", tc.MaintenanceWindowNullable{Scope: &serverScope, ServerID: &tenant3ServerID, StartTime: &start, EndTime: &end, Reason: util.StrPtr("tenancy test")}},
+		{"schedule maintenance of cachegroup with server of tenant3", http.MethodPost, "/maintenance_windows", tc.MaintenanceWindowNullable{Scope: &cachegroupScope, CachegroupID: &cachegroupID, StartTime: &start, EndTime: &end, Reason: util.StrPtr("tenancy test")}},
+		{"export profile", http.MethodGet, fmt.Sprintf("/profiles/%d/export", tenant3ProfileID), nil},
+		{"import profile owned by tenant3", http.MethodPost, "/profiles/import", tc.ProfileImportRequest{Profile: tc.ProfileExportImportNullable{Name: util.StrPtr("tenant4ImportedProfile"), Description: util.StrPtr("imported"), CDNName: testServer.CDNName, Type: &profiles[0].Type, TenantID: &tenant3.ID}, Parameters: []tc.ProfileExportImportParameterNullable{}}},
+	}
+
+	toReqTimeout := time.Second * time.Duration(Config.Default.Session.TimeoutInSecs)
+	tenant4TOClient, _, err := toclient.LoginWithAgent(TOSession.URL, "tenant4user", "pa$$word", true, "to-api-v3-client-tests/tenant4user", true, toReqTimeout)
+	if err != nil {
+		t.Fatalf("failed to log in with tenant4user: %v", err)
+	}
+
+	for _, req := range requests {
+		body := []byte(nil)
+		if req.body != nil {
+			if body, err = json.Marshal(req.body); err != nil {
+				t.Errorf("cannot encode request to %s: %v", req.name, err)
+				continue
+			}
+		}
+		// RawRequest returns the response as-is, so the status code can be checked
+		resp, _, err := tenant4TOClient.RawRequest(req.method, TestAPIBase+req.path, body, nil)
+		if err != nil {
+			t.Errorf("cannot request to %s: %v", req.name, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected tenant4user to be forbidden to %s of tenant3, actual status: %d", req.name, resp.StatusCode)
+		}
+	}
+
+	// profiles and servers of tenant3 don't exist as far as tenant4user's reads are concerned
+	resp, _, err := tenant4TOClient.RawRequest(http.MethodPost, TestAPIBase+fmt.Sprintf("/profiles/name/tenant4CopiedProfile/copy/%s", *profile.Name), nil, nil)
+	if err != nil {
+		t.Errorf("cannot request to copy profile: %v", err)
+	} else {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected tenant4user copying a profile of tenant3 to be not found, actual status: %d", resp.StatusCode)
+		}
+	}
+	resp, _, err = tenant4TOClient.RawRequest(http.MethodGet, TestAPIBase+"/servers/details?hostName="+url.QueryEscape(*server.HostName), nil, nil)
+	if err != nil {
+		t.Errorf("cannot request server details: %v", err)
+	} else {
+		details := struct {
+			Response []tc.ServerDetailV30 `json:"response"`
+		}{}
+		err := json.NewDecoder(resp.Body).Decode(&details)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("cannot decode server details: %v", err)
+		} else if len(details.Response) != 0 {
+			t.Errorf("expected tenant4user to get no details of servers of tenant3, actual: %d servers", len(details.Response))
+		}
+	}
+}
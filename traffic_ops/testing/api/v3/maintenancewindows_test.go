@@ -0,0 +1,278 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v3
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestMaintenanceWindows(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Topologies, Servers}, func() {
+		CreateTestMaintenanceWindowInvalidScope(t)
+		CRUDTestMaintenanceWindows(t)
+		CreateTestMaintenanceWindowCachegroupLimit(t)
+		CreateTestMaintenanceWindowTopologyLimit(t)
+	})
+}
+
+// getMaintenanceTestServer returns an ONLINE or REPORTED cache server from the test data.
+func getMaintenanceTestServer(t *testing.T) tc.ServerNullable {
+	resp, _, err := TOSession.GetServers(nil, nil)
+	if err != nil {
+		t.Fatalf("cannot GET servers: %v", err)
+	}
+	for _, s := range resp.Response {
+		if s.Status == nil || (*s.Status != string(tc.CacheStatusOnline) && *s.Status != string(tc.CacheStatusReported)) {
+			continue
+		}
+		if tc.CacheTypeFromString(s.Type) != tc.CacheTypeEdge && tc.CacheTypeFromString(s.Type) != tc.CacheTypeMid {
+			continue
+		}
+		return s
+	}
+	t.Fatal("no ONLINE or REPORTED cache server found in the test data")
+	return tc.ServerNullable{}
+}
+
+func CreateTestMaintenanceWindowInvalidScope(t *testing.T) {
+	server := getMaintenanceTestServer(t)
+	scope := tc.MaintenanceWindowScopeServer
+	start := time.Now()
+	end := start.Add(time.Hour)
+	mw := tc.MaintenanceWindowNullable{
+		Scope:        &scope,
+		ServerID:     server.ID,
+		CachegroupID: server.CachegroupID,
+		StartTime:    &start,
+		EndTime:      &end,
+		Reason:       util.StrPtr("invalid"),
+	}
+	_, reqInf, err := TOSession.CreateMaintenanceWindow(mw)
+	if err == nil && reqInf.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected creating a server maintenance window with a cachegroup to fail with 400, actual: %d", reqInf.StatusCode)
+	}
+}
+
+func CRUDTestMaintenanceWindows(t *testing.T) {
+	server := getMaintenanceTestServer(t)
+	scope := tc.MaintenanceWindowScopeServer
+	start := time.Now().Add(-time.Minute)
+	end := start.Add(time.Hour)
+	mw := tc.MaintenanceWindowNullable{
+		Scope:     &scope,
+		ServerID:  server.ID,
+		StartTime: &start,
+		EndTime:   &end,
+		Reason:    util.StrPtr("disk replacement"),
+	}
+	created, _, err := TOSession.CreateMaintenanceWindow(mw)
+	if err != nil {
+		t.Fatalf("cannot POST maintenance window: %v - alerts: %+v", err, created.Alerts)
+	}
+	if created.Response.ID == nil {
+		t.Fatalf("expected the created maintenance window to have an ID, alerts: %+v", created.Alerts)
+	}
+	id := *created.Response.ID
+
+	params := url.Values{}
+	params.Set("serverId", strconv.Itoa(*server.ID))
+	windows, _, err := TOSession.GetMaintenanceWindows(params, nil)
+	if err != nil {
+		t.Fatalf("cannot GET maintenance windows: %v", err)
+	}
+	if len(windows) != 1 {
+		t.Fatalf("expected 1 maintenance window for server %d, actual: %d", *server.ID, len(windows))
+	}
+	if windows[0].Active == nil || !*windows[0].Active {
+		t.Error("expected the maintenance window to be active")
+	}
+	if windows[0].ServerHostName == nil || *windows[0].ServerHostName != *server.HostName {
+		t.Errorf("expected the maintenance window server host name to be %s, actual: %v", *server.HostName, windows[0].ServerHostName)
+	}
+
+	tmConfig, _, err := TOSession.GetTrafficMonitorConfigMap(*server.CDNName)
+	if err != nil {
+		t.Fatalf("cannot GET monitoring config for CDN %s: %v", *server.CDNName, err)
+	}
+	if ts, ok := tmConfig.TrafficServer[*server.HostName]; !ok {
+		t.Errorf("expected server %s in the monitoring config", *server.HostName)
+	} else if ts.ServerStatus != string(tc.CacheStatusAdminDown) {
+		t.Errorf("expected server %s in maintenance to be %s in the monitoring config, actual: %s", *server.HostName, tc.CacheStatusAdminDown, ts.ServerStatus)
+	}
+
+	windows[0].Reason = util.StrPtr("disk and memory replacement")
+	if _, _, err := TOSession.UpdateMaintenanceWindowByID(id, windows[0]); err != nil {
+		t.Errorf("cannot PUT maintenance window: %v", err)
+	}
+	windows, _, err = TOSession.GetMaintenanceWindowByID(id, nil)
+	if err != nil {
+		t.Fatalf("cannot GET maintenance window by id: %v", err)
+	}
+	if len(windows) != 1 || windows[0].Reason == nil || *windows[0].Reason != "disk and memory replacement" {
+		t.Errorf("expected the maintenance window reason to be updated, actual: %+v", windows)
+	}
+
+	if _, _, err := TOSession.DeleteMaintenanceWindowByID(id); err != nil {
+		t.Errorf("cannot DELETE maintenance window: %v", err)
+	}
+	windows, _, err = TOSession.GetMaintenanceWindowByID(id, nil)
+	if err != nil {
+		t.Fatalf("cannot GET maintenance window by id: %v", err)
+	}
+	if len(windows) != 0 {
+		t.Errorf("expected the maintenance window to be deleted, actual: %+v", windows)
+	}
+}
+
+// getMaintenanceTestCacheCounts returns the number of cache servers in each cachegroup, by cachegroup name.
+func getMaintenanceTestCacheCounts(t *testing.T) map[string]int {
+	resp, _, err := TOSession.GetServers(nil, nil)
+	if err != nil {
+		t.Fatalf("cannot GET servers: %v", err)
+	}
+	counts := map[string]int{}
+	for _, s := range resp.Response {
+		if s.Cachegroup == nil {
+			continue
+		}
+		if tc.CacheTypeFromString(s.Type) != tc.CacheTypeEdge && tc.CacheTypeFromString(s.Type) != tc.CacheTypeMid {
+			continue
+		}
+		counts[*s.Cachegroup]++
+	}
+	return counts
+}
+
+// createTestMaintenanceWindow creates the maintenance window, expecting it to be rejected with a 400 if expectReject,
+// or deleting it again if not.
+func createTestMaintenanceWindow(t *testing.T, mw tc.MaintenanceWindowNullable, target string, expectReject bool) {
+	created, reqInf, err := TOSession.CreateMaintenanceWindow(mw)
+	if expectReject {
+		if err == nil {
+			t.Errorf("expected a maintenance window over %s to be rejected, but it was created", target)
+			if created.Response.ID != nil {
+				TOSession.DeleteMaintenanceWindowByID(*created.Response.ID)
+			}
+		} else if reqInf.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected a maintenance window over %s to be rejected with 400, actual: %d", target, reqInf.StatusCode)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("cannot POST maintenance window over %s: %v - alerts: %+v", target, err, created.Alerts)
+		return
+	}
+	if created.Response.ID == nil {
+		t.Errorf("expected the created maintenance window over %s to have an ID, alerts: %+v", target, created.Alerts)
+		return
+	}
+	if _, _, err := TOSession.DeleteMaintenanceWindowByID(*created.Response.ID); err != nil {
+		t.Errorf("cannot DELETE maintenance window over %s: %v", target, err)
+	}
+}
+
+// CreateTestMaintenanceWindowCachegroupLimit verifies a window over a cachegroup is subject to the limit of
+// unavailable caches, which allows a single cache but not all of several.
+func CreateTestMaintenanceWindowCachegroupLimit(t *testing.T) {
+	counts := getMaintenanceTestCacheCounts(t)
+	cgs, _, err := TOSession.GetCacheGroupsNullable(nil)
+	if err != nil {
+		t.Fatalf("cannot GET cachegroups: %v", err)
+	}
+	scope := tc.MaintenanceWindowScopeCachegroup
+	start := time.Now().Add(-time.Minute)
+	end := start.Add(time.Hour)
+	testedSingle := false
+	testedMultiple := false
+	for _, cg := range cgs {
+		if cg.ID == nil || cg.Name == nil || counts[*cg.Name] == 0 {
+			continue
+		}
+		multiple := counts[*cg.Name] > 1
+		if (multiple && testedMultiple) || (!multiple && testedSingle) {
+			continue
+		}
+		mw := tc.MaintenanceWindowNullable{
+			Scope:        &scope,
+			CachegroupID: cg.ID,
+			StartTime:    &start,
+			EndTime:      &end,
+			Reason:       util.StrPtr("cachegroup network maintenance"),
+		}
+		createTestMaintenanceWindow(t, mw, "cachegroup "+*cg.Name, multiple)
+		if multiple {
+			testedMultiple = true
+		} else {
+			testedSingle = true
+		}
+	}
+	if !testedSingle || !testedMultiple {
+		t.Error("expected the test data to have cachegroups with a single cache and with several caches")
+	}
+}
+
+// CreateTestMaintenanceWindowTopologyLimit verifies a window over a topology is subject to the limit of unavailable
+// caches in each of the topology's cachegroups.
+func CreateTestMaintenanceWindowTopologyLimit(t *testing.T) {
+	counts := getMaintenanceTestCacheCounts(t)
+	topologies, _, err := TOSession.GetTopologies(nil)
+	if err != nil {
+		t.Fatalf("cannot GET topologies: %v", err)
+	}
+	scope := tc.MaintenanceWindowScopeTopology
+	start := time.Now().Add(-time.Minute)
+	end := start.Add(time.Hour)
+	testedSingle := false
+	testedMultiple := false
+	for _, topology := range topologies {
+		maxCaches := 0
+		for _, node := range topology.Nodes {
+			if counts[node.Cachegroup] > maxCaches {
+				maxCaches = counts[node.Cachegroup]
+			}
+		}
+		if maxCaches == 0 {
+			continue
+		}
+		multiple := maxCaches > 1
+		if (multiple && testedMultiple) || (!multiple && testedSingle) {
+			continue
+		}
+		mw := tc.MaintenanceWindowNullable{
+			Scope:     &scope,
+			Topology:  util.StrPtr(topology.Name),
+			StartTime: &start,
+			EndTime:   &end,
+			Reason:    util.StrPtr("topology network maintenance"),
+		}
+		createTestMaintenanceWindow(t, mw, "topology "+topology.Name, multiple)
+		if multiple {
+			testedMultiple = true
+		} else {
+			testedSingle = true
+		}
+	}
+	if !testedSingle || !testedMultiple {
+		t.Error("expected the test data to have topologies whose cachegroups have at most a single cache, and with several caches")
+	}
+}
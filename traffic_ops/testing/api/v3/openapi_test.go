@@ -0,0 +1,166 @@
+package v3
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestOpenAPI(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, Topologies, DeliveryServices}, func() {
+		doc := GetTestOpenAPIDocument(t)
+		ValidateTestResponsesAgainstOpenAPIDocument(t, doc)
+	})
+}
+
+func GetTestOpenAPIDocument(t *testing.T) tc.OpenAPIDocument {
+	doc, _, err := TOSession.GetOpenAPIDocument()
+	if err != nil {
+		t.Fatalf("cannot GET openapi.json: %v", err)
+	}
+	if doc.OpenAPI != tc.OpenAPIVersion {
+		t.Errorf("expected OpenAPI version %s, actual: %s", tc.OpenAPIVersion, doc.OpenAPI)
+	}
+	if !strings.HasSuffix(TestAPIBase, "/"+doc.Info.Version) {
+		t.Errorf("expected document of API version %s, actual: %s", TestAPIBase, doc.Info.Version)
+	}
+	if _, ok := doc.Paths["/openapi.json"]["get"]; !ok {
+		t.Error("expected the document to describe GET /openapi.json")
+	}
+
+	_, _, err = NoAuthTOSession.GetOpenAPIDocument()
+	if err == nil {
+		t.Error("expected error from GetOpenAPIDocument() when unauthenticated")
+	}
+	return doc
+}
+
+// ValidateTestResponsesAgainstOpenAPIDocument requests every documented GET route which has no path parameters and a
+// documented response, and verifies the response matches the document.
+func ValidateTestResponsesAgainstOpenAPIDocument(t *testing.T, doc tc.OpenAPIDocument) {
+	for path, item := range doc.Paths {
+		op, ok := item["get"]
+		if !ok || len(op.Parameters) > 0 {
+			continue
+		}
+		media, ok := op.Responses["200"].Content[rfc.ApplicationJSON]
+		if !ok || media.Schema == nil {
+			continue
+		}
+
+		resp, _, err := TOSession.RawRequest(http.MethodGet, TestAPIBase+path, nil, nil)
+		if err != nil {
+			t.Errorf("cannot GET %s: %v", path, err)
+			continue
+		}
+		var body interface{}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Logf("skipping validation of GET %s: status %d", path, resp.StatusCode)
+			continue
+		}
+		if err != nil {
+			t.Errorf("cannot decode response of GET %s: %v", path, err)
+			continue
+		}
+		if err := validateOpenAPISchema(doc, media.Schema, body, "body"); err != nil {
+			t.Errorf("response of GET %s doesn't match the OpenAPI document: %v", path, err)
+		}
+	}
+}
+
+// validateOpenAPISchema returns an error describing how the decoded JSON value v fails to match the schema s, or nil
+// if it matches. Only the subset of JSON Schema generated by Traffic Ops is supported.
+func validateOpenAPISchema(doc tc.OpenAPIDocument, s *tc.OpenAPISchema, v interface{}, name string) error {
+	if s.Ref != "" {
+		ref, ok := doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema reference '%s'", name, s.Ref)
+		}
+		return validateOpenAPISchema(doc, ref, v, name)
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" && len(s.AllOf) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s: expected %s, actual: null", name, s.Type)
+	}
+	for _, sub := range s.AllOf {
+		if err := validateOpenAPISchema(doc, sub, v, name); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, actual: %T", name, v)
+		}
+		for _, required := range s.Required {
+			if _, ok := obj[required]; !ok {
+				return fmt.Errorf("%s: missing required property '%s'", name, required)
+			}
+		}
+		for key, val := range obj {
+			propSchema, ok := s.Properties[key]
+			if !ok {
+				propSchema = s.AdditionalProperties
+			}
+			if propSchema == nil {
+				return fmt.Errorf("%s: undocumented property '%s'", name, key)
+			}
+			if err := validateOpenAPISchema(doc, propSchema, val, name+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, actual: %T", name, v)
+		}
+		for i, val := range arr {
+			if err := validateOpenAPISchema(doc, s.Items, val, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected string, actual: %T", name, v)
+		}
+	case "integer":
+		if f, ok := v.(float64); !ok || f != float64(int64(f)) {
+			return fmt.Errorf("%s: expected integer, actual: %v", name, v)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected number, actual: %T", name, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, actual: %T", name, v)
+		}
+	}
+	return nil
+}
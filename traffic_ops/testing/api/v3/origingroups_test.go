@@ -0,0 +1,103 @@
+package v3
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestOriginGroups(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, Users, Topologies, DeliveryServices, Coordinates, Origins}, func() {
+		UpdateTestOriginGroups(t)
+		InvalidOriginGroupTest(t)
+		DeleteTestOriginGroups(t)
+	})
+}
+
+func getOriginGroupTestOrigin(t *testing.T) tc.Origin {
+	if len(testData.Origins) < 1 {
+		t.Fatal("need at least one origin to test origin groups")
+	}
+	resp, _, err := TOSession.GetOriginByName(*testData.Origins[0].Name)
+	if err != nil {
+		t.Fatalf("cannot GET origin by name: %v - %v", *testData.Origins[0].Name, err)
+	}
+	if len(resp) != 1 {
+		t.Fatalf("expected 1 origin named %s, actual: %d", *testData.Origins[0].Name, len(resp))
+	}
+	return resp[0]
+}
+
+func UpdateTestOriginGroups(t *testing.T) {
+	origin := getOriginGroupTestOrigin(t)
+	policy := tc.OriginGroupPolicyFailover
+	group := tc.OriginGroup{
+		Policy:          &policy,
+		HealthCheckPath: util.StrPtr("/health"),
+		Members:         []tc.OriginGroupMember{{OriginID: origin.ID}},
+	}
+	resp, _, err := TOSession.UpdateDeliveryServiceOriginGroup(*origin.DeliveryServiceID, group)
+	if err != nil {
+		t.Fatalf("cannot PUT origin group: %v", err)
+	}
+	if resp.Response.HealthCheckExpectedStatus == nil || *resp.Response.HealthCheckExpectedStatus != 200 {
+		t.Errorf("expected default health check status 200, actual: %v", resp.Response.HealthCheckExpectedStatus)
+	}
+	if len(resp.Response.Members) != 1 || resp.Response.Members[0].Role == nil || *resp.Response.Members[0].Role != tc.OriginGroupMemberRolePrimary {
+		t.Errorf("expected one primary member, actual: %+v", resp.Response.Members)
+	}
+
+	params := url.Values{}
+	params.Set("deliveryServiceId", strconv.Itoa(*origin.DeliveryServiceID))
+	groups, _, err := TOSession.GetOriginGroups(params, nil)
+	if err != nil {
+		t.Fatalf("cannot GET origin groups: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Policy == nil || *groups[0].Policy != tc.OriginGroupPolicyFailover {
+		t.Errorf("expected one failover origin group, actual: %+v", groups)
+	}
+}
+
+func InvalidOriginGroupTest(t *testing.T) {
+	origin := getOriginGroupTestOrigin(t)
+	secondary := tc.OriginGroupMemberRoleSecondary
+	group := tc.OriginGroup{
+		Members: []tc.OriginGroupMember{{OriginID: origin.ID, Role: &secondary}},
+	}
+	_, _, err := TOSession.UpdateDeliveryServiceOriginGroup(*origin.DeliveryServiceID, group)
+	if err == nil {
+		t.Error("expected an error putting an origin group without a primary member, actual: nil")
+	} else if !strings.Contains(err.Error(), "primary") {
+		t.Errorf("expected an error about a missing primary member, actual: %v", err)
+	}
+}
+
+func DeleteTestOriginGroups(t *testing.T) {
+	origin := getOriginGroupTestOrigin(t)
+	if _, _, err := TOSession.DeleteDeliveryServiceOriginGroup(*origin.DeliveryServiceID); err != nil {
+		t.Errorf("cannot DELETE origin group: %v", err)
+	}
+	_, _, err := TOSession.DeleteDeliveryServiceOriginGroup(*origin.DeliveryServiceID)
+	if err == nil {
+		t.Error("expected an error deleting an origin group that doesn't exist, actual: nil")
+	}
+}
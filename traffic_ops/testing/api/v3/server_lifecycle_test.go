@@ -0,0 +1,107 @@
+package v3
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestServerLifecycle(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers}, func() {
+		TransitionTestServerLifecycle(t)
+		PostTestServerHardwareInventory(t)
+	})
+}
+
+func getLifecycleTestServerID(t *testing.T) int {
+	params := url.Values{}
+	params.Set("hostName", "atlanta-edge-14")
+	resp, _, err := TOSession.GetServers(&params, nil)
+	if err != nil {
+		t.Fatalf("cannot GET server atlanta-edge-14: %v", err)
+	}
+	if len(resp.Response) != 1 || resp.Response[0].ID == nil {
+		t.Fatalf("expected exactly one server atlanta-edge-14, got %d", len(resp.Response))
+	}
+	return *resp.Response[0].ID
+}
+
+func TransitionTestServerLifecycle(t *testing.T) {
+	id := getLifecycleTestServerID(t)
+
+	lifecycle, _, err := TOSession.GetServerLifecycle(id, nil)
+	if err != nil {
+		t.Fatalf("cannot GET lifecycle of server atlanta-edge-14: %v", err)
+	}
+	if lifecycle.State == nil || *lifecycle.State != tc.ServerLifecycleStateReported {
+		t.Fatalf("expected lifecycle state %s, got %v", tc.ServerLifecycleStateReported, lifecycle.State)
+	}
+	if len(lifecycle.Transitions) != len(tc.ServerLifecycleTransitions[tc.ServerLifecycleStateReported]) {
+		t.Errorf("expected %d transitions from %s, got %d", len(tc.ServerLifecycleTransitions[tc.ServerLifecycleStateReported]), tc.ServerLifecycleStateReported, len(lifecycle.Transitions))
+	}
+
+	_, reqInf, err := TOSession.TransitionServerLifecycle(id, tc.ServerLifecycleStateBurnIn)
+	if err == nil {
+		t.Errorf("expected an error transitioning a server from %s to %s, got none", tc.ServerLifecycleStateReported, tc.ServerLifecycleStateBurnIn)
+	}
+	if reqInf.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, reqInf.StatusCode)
+	}
+
+	for _, state := range []tc.ServerLifecycleState{
+		tc.ServerLifecycleStateDecommissioned,
+		tc.ServerLifecycleStatePreProd,
+		tc.ServerLifecycleStateBurnIn,
+		tc.ServerLifecycleStateReported,
+	} {
+		resp, _, err := TOSession.TransitionServerLifecycle(id, state)
+		if err != nil {
+			t.Fatalf("cannot transition server atlanta-edge-14 to %s: %v", state, err)
+		}
+		if resp.Response.State == nil || *resp.Response.State != state {
+			t.Errorf("expected lifecycle state %s after transitioning, got %v", state, resp.Response.State)
+		}
+	}
+}
+
+func PostTestServerHardwareInventory(t *testing.T) {
+	id := getLifecycleTestServerID(t)
+	inv := tc.ServerHardwareInventory{
+		Manufacturer: "Acme",
+		SerialNumber: "SN123",
+		Disks:        []tc.ServerHardwareDisk{{Device: "sda", SizeGB: 960}},
+	}
+	hwInfo, _, err := TOSession.PostServerHardwareInventory(id, inv)
+	if err != nil {
+		t.Fatalf("cannot POST hardware inventory of server atlanta-edge-14: %v", err)
+	}
+	if len(hwInfo) != 3 {
+		t.Errorf("expected 3 hwinfo items, got %d", len(hwInfo))
+	}
+
+	inv.Disks = append(inv.Disks, tc.ServerHardwareDisk{Device: "sda"})
+	_, reqInf, err := TOSession.PostServerHardwareInventory(id, inv)
+	if err == nil {
+		t.Error("expected an error posting a hardware inventory with duplicate disks, got none")
+	}
+	if reqInf.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, reqInf.StatusCode)
+	}
+}
@@ -17,11 +17,14 @@ package v3
 
 import (
 	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
 func TestSteering(t *testing.T) {
 	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, Topologies, DeliveryServices, Users, SteeringTargets}, func() {
 		GetTestSteering(t)
+		SimulateTestSteeringInvalid(t)
 	})
 }
 
@@ -65,3 +68,29 @@ func GetTestSteering(t *testing.T) {
 		t.Errorf("steering get: Targets Order expected %v actual %+v", nil, *steerings[0].Targets[0].Latitude)
 	}
 }
+
+func SimulateTestSteeringInvalid(t *testing.T) {
+	if len(testData.SteeringTargets) < 1 {
+		t.Fatal("simulate steering: no steering target test data")
+	}
+	st := testData.SteeringTargets[0]
+	if st.DeliveryService == nil || st.Target == nil {
+		t.Fatal("simulate steering: test data missing ds or target")
+	}
+
+	dses, _, err := TOSession.GetDeliveryServiceByXMLIDNullable(string(*st.DeliveryService), nil)
+	if err != nil || len(dses) != 1 || dses[0].ID == nil {
+		t.Fatalf("simulate steering: getting delivery service %s: %v", *st.DeliveryService, err)
+	}
+	if _, _, err := TOSession.SimulateSteering(*dses[0].ID, tc.SteeringSimulationRequest{}); err == nil {
+		t.Error("simulate steering: expected an error simulating without a request path")
+	}
+
+	targets, _, err := TOSession.GetDeliveryServiceByXMLIDNullable(string(*st.Target), nil)
+	if err != nil || len(targets) != 1 || targets[0].ID == nil {
+		t.Fatalf("simulate steering: getting delivery service %s: %v", *st.Target, err)
+	}
+	if _, _, err := TOSession.SimulateSteering(*targets[0].ID, tc.SteeringSimulationRequest{RequestPath: "/asset.m3u8"}); err == nil {
+		t.Error("simulate steering: expected an error simulating a delivery service that isn't a steering delivery service")
+	}
+}
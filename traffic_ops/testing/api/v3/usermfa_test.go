@@ -0,0 +1,93 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+)
+
+func TestUserMFA(t *testing.T) {
+	WithObjs(t, []TCObj{Tenants, Users}, func() {
+		EnrollVerifyAndResetUserMFA(t)
+	})
+}
+
+func EnrollVerifyAndResetUserMFA(t *testing.T) {
+	const username = "readonlyuser"
+	const password = "pa$$word"
+	toReqTimeout := time.Second * time.Duration(Config.Default.Session.TimeoutInSecs)
+	userTOClient, _, err := toclient.LoginWithAgent(TOSession.URL, username, password, true, "to-api-v3-client-tests/"+username, true, toReqTimeout)
+	if err != nil {
+		t.Fatalf("failed to log in with %s: %v", username, err)
+	}
+
+	enrollment, _, err := userTOClient.EnrollCurrentUserMFA()
+	if err != nil {
+		t.Fatalf("cannot enroll in MFA: %v", err)
+	}
+	status, _, err := userTOClient.GetCurrentUserMFA(nil)
+	if err != nil {
+		t.Fatalf("cannot GET MFA status: %v", err)
+	}
+	if status.Enabled || !status.Enrolling {
+		t.Errorf("expected MFA to be enrolling but not enabled, actual: %+v", status)
+	}
+
+	if _, _, err := userTOClient.VerifyCurrentUserMFA("000000"); err == nil {
+		t.Error("expected verifying an invalid code to fail, actual: success")
+	}
+
+	code, err := auth.TOTPCode(enrollment.Response.Secret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("cannot generate TOTP code: %v", err)
+	}
+	verification, _, err := userTOClient.VerifyCurrentUserMFA(code)
+	if err != nil {
+		t.Fatalf("cannot verify MFA code: %v", err)
+	}
+	if len(verification.Response.RecoveryCodes) != auth.RecoveryCodeCount {
+		t.Errorf("expected %d recovery codes, actual: %d", auth.RecoveryCodeCount, len(verification.Response.RecoveryCodes))
+	}
+
+	// the verified session remains usable
+	status, _, err = userTOClient.GetCurrentUserMFA(nil)
+	if err != nil {
+		t.Fatalf("cannot GET MFA status after verifying: %v", err)
+	}
+	if !status.Enabled || !status.Verified || status.RecoveryCodesRemaining != auth.RecoveryCodeCount {
+		t.Errorf("expected MFA to be enabled and verified, actual: %+v", status)
+	}
+
+	// logging in with only a password is rejected
+	if _, _, err := toclient.LoginWithAgent(TOSession.URL, username, password, true, "to-api-v3-client-tests/"+username, true, toReqTimeout); err == nil {
+		t.Error("expected logging in without a one-time password to fail, actual: success")
+	}
+
+	users, _, err := TOSession.GetUserByUsername(username, nil)
+	if err != nil || len(users) != 1 || users[0].ID == nil {
+		t.Fatalf("cannot GET user %s: %v", username, err)
+	}
+	if _, _, err := TOSession.ResetUserMFA(*users[0].ID); err != nil {
+		t.Fatalf("cannot reset MFA of user %s: %v", username, err)
+	}
+	if _, _, err := toclient.LoginWithAgent(TOSession.URL, username, password, true, "to-api-v3-client-tests/"+username, true, toReqTimeout); err != nil {
+		t.Errorf("expected logging in with only a password to succeed after resetting MFA, actual: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
+)
+
+func TestUserSessions(t *testing.T) {
+	WithObjs(t, []TCObj{Tenants, Users}, func() {
+		GetAndDeleteUserSessions(t)
+	})
+}
+
+func GetAndDeleteUserSessions(t *testing.T) {
+	const username = "readonlyuser"
+	const password = "pa$$word"
+	toReqTimeout := time.Second * time.Duration(Config.Default.Session.TimeoutInSecs)
+	userTOClient, _, err := toclient.LoginWithAgent(TOSession.URL, username, password, true, "to-api-v3-client-tests/"+username, true, toReqTimeout)
+	if err != nil {
+		t.Fatalf("failed to log in with %s: %v", username, err)
+	}
+
+	sessions, _, err := userTOClient.GetCurrentUserSessions(nil)
+	if err != nil {
+		t.Fatalf("cannot GET current user sessions: %v", err)
+	}
+	current := 0
+	for _, session := range sessions {
+		if session.Current {
+			current++
+		}
+	}
+	if current != 1 {
+		t.Errorf("expected exactly one current session, actual: %d of %d sessions", current, len(sessions))
+	}
+
+	users, _, err := TOSession.GetUserByUsername(username, nil)
+	if err != nil {
+		t.Fatalf("cannot GET user %s: %v", username, err)
+	}
+	if len(users) != 1 || users[0].ID == nil {
+		t.Fatalf("expected one user named %s, actual: %d", username, len(users))
+	}
+	if _, _, err := TOSession.DeleteUserSessions(*users[0].ID); err != nil {
+		t.Fatalf("cannot DELETE sessions of user %s: %v", username, err)
+	}
+
+	// RawRequest doesn't log in again on Unauthorized, as the client's other methods do
+	resp, _, err := userTOClient.RawRequest(http.MethodGet, toclient.API_USER_CURRENT_SESSIONS, nil, nil)
+	if err != nil {
+		t.Fatalf("cannot GET current user sessions with an ended session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a request with an ended session to be Unauthorized, actual: %d", resp.StatusCode)
+	}
+}
@@ -895,9 +895,26 @@ func GetUserFromReq(w http.ResponseWriter, r *http.Request, secret string) (auth
 		return auth.CurrentUser{}, userErr, sysErr, code
 	}
 
+	// A session which didn't verify a second factor is limited to enrolling in or verifying one, if the user's role
+	// requires it or the user enabled it (e.g. after the session began, or when logging in by a means which doesn't
+	// verify it).
+	user.MFAPending = (user.MFARequired || user.MFAEnabled) && !oldCookie.MFA
+
 	duration := tocookie.DefaultDuration
-	newCookie := tocookie.GetCookie(oldCookie.AuthData, duration, secret)
-	http.SetCookie(w, newCookie)
+	sessionValid, err := auth.TouchSession(db, user.ID, oldCookie.Session, duration, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+	if err != nil {
+		return auth.CurrentUser{}, nil, errors.New("checking session: " + err.Error()), http.StatusInternalServerError
+	}
+	if !sessionValid {
+		return auth.CurrentUser{}, errors.New("Unauthorized, please log in."), nil, http.StatusUnauthorized
+	}
+	user.SessionID = oldCookie.Session
+
+	// a cookie without a session isn't refreshed, so it can't be used indefinitely without ever being revocable
+	if oldCookie.Session != "" {
+		newCookie := tocookie.GetSessionCookie(oldCookie.AuthData, oldCookie.Session, oldCookie.MFA, duration, secret)
+		http.SetCookie(w, newCookie)
+	}
 	return user, nil, nil, http.StatusOK
 }
 
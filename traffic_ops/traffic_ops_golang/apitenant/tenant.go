@@ -233,6 +233,14 @@ func parseDeleteErr(err error, id int, tx *sql.Tx) (error, error, int) {
 		existing = "deliveryservices"
 	case "origin":
 		existing = "origins"
+	case "server":
+		existing = "servers"
+	case "cachegroup":
+		existing = "cachegroups"
+	case "topology":
+		existing = "topologies"
+	case "profile":
+		existing = "profiles"
 	default:
 		existing = pqErr.Table
 	}
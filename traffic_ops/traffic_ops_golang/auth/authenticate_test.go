@@ -21,6 +21,8 @@ package auth
 
 import (
 	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 )
 
 func TestDeriveGoodPassword(t *testing.T) {
@@ -102,3 +104,31 @@ func TestCommonPassword(t *testing.T) {
 	}
 
 }
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := config.ConfigPasswordPolicy{
+		MinLength:        10,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+	passwords := map[string]bool{
+		"Sh0rt!":          false,
+		"alllowercase1!":  false,
+		"ALLUPPERCASE1!":  false,
+		"NoDigitsHere!!":  false,
+		"NoSymbols12345":  false,
+		"G00d-Passw0rd":   true,
+		"Spaced Out 2020": true,
+	}
+	for password, expected := range passwords {
+		if ok, err := IsGoodPasswordForPolicy(password, policy); ok != expected {
+			t.Errorf("IsGoodPasswordForPolicy(%q): expected %t, actual %t (%v)", password, expected, ok, err)
+		}
+	}
+
+	if ok, err := IsGoodPassword("lowercase"); !ok {
+		t.Errorf("IsGoodPassword with the default policy should allow a password of only lowercase letters, got: %v", err)
+	}
+}
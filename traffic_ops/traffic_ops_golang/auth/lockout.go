@@ -0,0 +1,111 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// A user is locked out while their tm_user.locked_until is in the future. A
+// lockout which lasts until an administrator unlocks the user is 'infinity'.
+
+// CheckLocalUserLocked returns whether the user logging in is locked out.
+func CheckLocalUserLocked(form PasswordForm, db *sqlx.DB, timeout time.Duration) (bool, error) {
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+	locked := false
+	if err := db.GetContext(dbCtx, &locked, `SELECT COALESCE(locked_until > now(), FALSE) FROM tm_user WHERE username = $1`, form.Username); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.New("querying user lockout: " + err.Error())
+	}
+	return locked, nil
+}
+
+// RecordFailedLogin counts a failed login attempt of the user logging in, and
+// locks them out if it's one too many. It returns whether the user is now
+// locked out. Failures aren't counted if the configuration doesn't lock users
+// out.
+func RecordFailedLogin(form PasswordForm, cfg config.ConfigLockout, db *sqlx.DB, timeout time.Duration) (bool, error) {
+	if cfg.MaxFailedLogins <= 0 {
+		return false, nil
+	}
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+
+	// a user whose lockout expired starts counting again
+	locked := false
+	err := db.GetContext(dbCtx, &locked, `
+WITH attempt AS (
+	SELECT id, CASE WHEN locked_until IS NULL THEN failed_login_count + 1 ELSE 1 END AS count
+	FROM tm_user
+	WHERE username = $1
+)
+UPDATE tm_user
+SET failed_login_count = attempt.count,
+    locked_until = CASE
+        WHEN attempt.count < $2 THEN NULL
+        WHEN $3::bigint > 0 THEN now() + $3::bigint * interval '1 second'
+        ELSE 'infinity'
+    END
+FROM attempt
+WHERE tm_user.id = attempt.id
+RETURNING tm_user.locked_until IS NOT NULL
+`, form.Username, cfg.MaxFailedLogins, cfg.DurationSeconds)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.New("recording failed login: " + err.Error())
+	}
+	return locked, nil
+}
+
+// RecordSuccessfulLogin resets the count of failed login attempts of the user
+// logging in.
+func RecordSuccessfulLogin(form PasswordForm, db *sqlx.DB, timeout time.Duration) error {
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+	if _, err := db.ExecContext(dbCtx, `UPDATE tm_user SET failed_login_count = 0, locked_until = NULL WHERE username = $1 AND (failed_login_count <> 0 OR locked_until IS NOT NULL)`, form.Username); err != nil {
+		return errors.New("resetting failed logins: " + err.Error())
+	}
+	return nil
+}
+
+// UnlockUser unlocks the user with the given ID, and returns whether they were
+// locked out.
+func UnlockUser(tx *sql.Tx, userID int) (bool, error) {
+	locked := false
+	if err := tx.QueryRow(`SELECT COALESCE(locked_until > now(), FALSE) FROM tm_user WHERE id = $1`, userID).Scan(&locked); err != nil {
+		return false, errors.New("querying user lockout: " + err.Error())
+	}
+	if _, err := tx.Exec(`UPDATE tm_user SET failed_login_count = 0, locked_until = NULL WHERE id = $1`, userID); err != nil {
+		return false, errors.New("unlocking user: " + err.Error())
+	}
+	return locked, nil
+}
@@ -0,0 +1,145 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// RecoveryCodeCount is the number of recovery codes generated for a user when
+// they enable multi-factor authentication.
+const RecoveryCodeCount = 10
+
+// HashRecoveryCode returns the hash of a recovery code, as stored in the
+// database. Recovery codes are long and random enough that a fast hash is
+// sufficient, unlike passwords.
+func HashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(NormalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyMFACode checks whether otp is a valid TOTP code of the secret the user
+// with the given ID enrolled, or - if allowRecovery is true - one of their
+// unused recovery codes. An accepted code is consumed, so that it can't be
+// used again. The user's row is locked until tx ends, so that concurrent
+// requests can't both use the same code.
+func VerifyMFACode(tx *sql.Tx, userID int, otp string, allowRecovery bool) (bool, error) {
+	secret := sql.NullString{}
+	lastStep := int64(0)
+	if err := tx.QueryRow(`SELECT mfa_secret, COALESCE(mfa_last_step, 0) FROM tm_user WHERE id = $1 FOR UPDATE`, userID).Scan(&secret, &lastStep); err != nil {
+		return false, errors.New("querying user MFA secret: " + err.Error())
+	}
+	if !secret.Valid || secret.String == "" {
+		return false, nil
+	}
+
+	step, ok, err := VerifyTOTP(secret.String, otp, time.Now(), lastStep)
+	if err != nil {
+		return false, errors.New("verifying TOTP code: " + err.Error())
+	}
+	if ok {
+		if _, err := tx.Exec(`UPDATE tm_user SET mfa_last_step = $1 WHERE id = $2`, step, userID); err != nil {
+			return false, errors.New("updating user MFA last step: " + err.Error())
+		}
+		return true, nil
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	result, err := tx.Exec(`DELETE FROM user_mfa_recovery_code WHERE tm_user = $1 AND code_hash = $2`, userID, HashRecoveryCode(otp))
+	if err != nil {
+		return false, errors.New("consuming user MFA recovery code: " + err.Error())
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.New("checking consumed user MFA recovery codes: " + err.Error())
+	}
+	return rows > 0, nil
+}
+
+// ReplaceRecoveryCodes replaces all of the recovery codes of the user with the
+// given ID with the given ones.
+func ReplaceRecoveryCodes(tx *sql.Tx, userID int, codes []string) error {
+	if _, err := tx.Exec(`DELETE FROM user_mfa_recovery_code WHERE tm_user = $1`, userID); err != nil {
+		return errors.New("deleting user MFA recovery codes: " + err.Error())
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	if _, err := tx.Exec(`INSERT INTO user_mfa_recovery_code (tm_user, code_hash) SELECT $1, UNNEST($2::text[])`, userID, pq.Array(hashes)); err != nil {
+		return errors.New("inserting user MFA recovery codes: " + err.Error())
+	}
+	return nil
+}
+
+// ResetMFA disables multi-factor authentication for the user with the given
+// ID, and removes their secret and recovery codes.
+func ResetMFA(tx *sql.Tx, userID int) error {
+	if _, err := tx.Exec(`UPDATE tm_user SET mfa_enabled = FALSE, mfa_secret = NULL, mfa_last_step = NULL WHERE id = $1`, userID); err != nil {
+		return errors.New("resetting user MFA: " + err.Error())
+	}
+	if _, err := tx.Exec(`DELETE FROM user_mfa_recovery_code WHERE tm_user = $1`, userID); err != nil {
+		return errors.New("deleting user MFA recovery codes: " + err.Error())
+	}
+	return nil
+}
+
+// CheckLocalUserMFA checks the one-time password given by a user logging in.
+// It returns whether the user has enabled multi-factor authentication and, if
+// they have, whether the form's OTP is a valid TOTP or recovery code.
+func CheckLocalUserMFA(form PasswordForm, db *sqlx.DB, timeout time.Duration) (bool, bool, error) {
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+	tx, err := db.BeginTx(dbCtx, nil)
+	if err != nil {
+		return false, false, errors.New("beginning transaction: " + err.Error())
+	}
+	defer tx.Rollback()
+
+	id := 0
+	enabled := false
+	if err := tx.QueryRow(`SELECT id, mfa_enabled FROM tm_user WHERE username = $1`, form.Username).Scan(&id, &enabled); err != nil {
+		if err == sql.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, errors.New("querying user MFA: " + err.Error())
+	}
+	if !enabled || form.OTP == "" {
+		return enabled, false, nil
+	}
+	ok, err := VerifyMFACode(tx, id, form.OTP, true)
+	if err != nil {
+		return true, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return true, false, errors.New("committing transaction: " + err.Error())
+	}
+	return true, ok, nil
+}
@@ -25,8 +25,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 )
 
 // A lookup table, bool will always be true
@@ -68,7 +70,15 @@ func IsCommonPassword(pw string) bool {
 	return yes
 }
 
+// DefaultPasswordPolicy is the policy of IsGoodPassword and IsGoodLoginPair.
+var DefaultPasswordPolicy = config.ConfigPasswordPolicy{MinLength: config.PasswordMinLengthDefault}
+
 func IsGoodLoginPair(username string, password string) (bool, error) {
+	return IsGoodLoginPairForPolicy(username, password, DefaultPasswordPolicy)
+}
+
+// IsGoodLoginPairForPolicy is like IsGoodLoginPair, but checks the password against the given policy.
+func IsGoodLoginPairForPolicy(username string, password string, policy config.ConfigPasswordPolicy) (bool, error) {
 
 	if username == "" {
 		return false, errors.New("Your username cannot be blank.")
@@ -78,18 +88,50 @@ func IsGoodLoginPair(username string, password string) (bool, error) {
 		return false, errors.New("Your password cannot be your username.")
 	}
 
-	return IsGoodPassword(password)
+	return IsGoodPasswordForPolicy(password, policy)
 }
 
 func IsGoodPassword(password string) (bool, error) {
+	return IsGoodPasswordForPolicy(password, DefaultPasswordPolicy)
+}
+
+// IsGoodPasswordForPolicy is like IsGoodPassword, but checks the password against the given policy.
+func IsGoodPasswordForPolicy(password string, policy config.ConfigPasswordPolicy) (bool, error) {
 
-	if len(password) < 8 {
-		return false, errors.New("Password must be greater than 7 characters.")
+	if len(password) < policy.MinLength {
+		return false, fmt.Errorf("Password must be greater than %d characters.", policy.MinLength-1)
 	}
 
 	if IsCommonPassword(password) {
 		return false, errors.New("Password is too common.")
 	}
 
+	hasUpper, hasLower, hasDigit, hasSymbol := false, false, false, false
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !hasUpper {
+		return false, errors.New("Password must contain an uppercase letter.")
+	}
+	if policy.RequireLowercase && !hasLower {
+		return false, errors.New("Password must contain a lowercase letter.")
+	}
+	if policy.RequireDigit && !hasDigit {
+		return false, errors.New("Password must contain a digit.")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		return false, errors.New("Password must contain a symbol.")
+	}
+
 	return true, nil
 }
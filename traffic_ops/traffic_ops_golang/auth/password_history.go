@@ -0,0 +1,105 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// IsPasswordReused returns whether password is the current password of the
+// user with the given ID, or one of the most recent historyCount passwords
+// recorded in their history.
+func IsPasswordReused(tx *sql.Tx, userID int, password string, historyCount int) (bool, error) {
+	if historyCount <= 0 {
+		return false, nil
+	}
+	rows, err := tx.Query(`
+SELECT local_passwd FROM tm_user WHERE id = $1 AND local_passwd IS NOT NULL
+UNION ALL
+(SELECT local_passwd FROM user_password_history WHERE tm_user = $1 ORDER BY created DESC, id DESC LIMIT $2)
+`, userID, historyCount)
+	if err != nil {
+		return false, errors.New("querying user password history: " + err.Error())
+	}
+	defer rows.Close()
+	hashes := []string{}
+	for rows.Next() {
+		hash := ""
+		if err := rows.Scan(&hash); err != nil {
+			return false, errors.New("scanning user password history: " + err.Error())
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return false, errors.New("iterating over user password history: " + err.Error())
+	}
+	for _, hash := range hashes {
+		if VerifySCRYPTPassword(password, hash) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RecordPasswordChange records that the password of the user with the given
+// ID changed to the one derived as hash, keeping only the most recent
+// historyCount passwords in their history.
+func RecordPasswordChange(tx *sql.Tx, userID int, hash string, historyCount int) error {
+	if _, err := tx.Exec(`UPDATE tm_user SET password_changed = now() WHERE id = $1`, userID); err != nil {
+		return errors.New("updating user password change time: " + err.Error())
+	}
+	if historyCount > 0 {
+		if _, err := tx.Exec(`INSERT INTO user_password_history (tm_user, local_passwd) VALUES ($1, $2)`, userID, hash); err != nil {
+			return errors.New("inserting user password history: " + err.Error())
+		}
+	}
+	if _, err := tx.Exec(`
+DELETE FROM user_password_history
+WHERE tm_user = $1 AND id NOT IN (
+	SELECT id FROM user_password_history WHERE tm_user = $1 ORDER BY created DESC, id DESC LIMIT $2
+)`, userID, historyCount); err != nil {
+		return errors.New("pruning user password history: " + err.Error())
+	}
+	return nil
+}
+
+// CheckLocalUserPasswordExpired returns whether the password of the user
+// logging in changed more than maxAgeDays ago. Passwords never expire if
+// maxAgeDays isn't positive.
+func CheckLocalUserPasswordExpired(form PasswordForm, maxAgeDays int, db *sqlx.DB, timeout time.Duration) (bool, error) {
+	if maxAgeDays <= 0 {
+		return false, nil
+	}
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+	expired := false
+	if err := db.GetContext(dbCtx, &expired, `SELECT password_changed < now() - $2 * interval '1 day' FROM tm_user WHERE username = $1`, form.Username, maxAgeDays); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.New("querying user password change time: " + err.Error())
+	}
+	return expired, nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sessionIDLen is the length in octets of session IDs, which are hex-encoded.
+const sessionIDLen = 16
+
+// Traffic Ops session cookies are signed, so can't be forged, but are otherwise
+// stateless. To let users see their sessions and to let sessions be ended
+// before they expire - e.g. when a user's password changes - each session is
+// also recorded in the user_session table, and a cookie is only valid while
+// its session is.
+
+// CreateSession records a new session of the user with the given username,
+// which expires after duration unless it's used, and returns its ID.
+func CreateSession(db *sqlx.DB, username string, userAgent string, clientIP string, duration time.Duration, timeout time.Duration) (string, error) {
+	b, err := generateSalt(sessionIDLen)
+	if err != nil {
+		return "", errors.New("generating session ID: " + err.Error())
+	}
+	id := hex.EncodeToString(b)
+
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+	tx, err := db.BeginTx(dbCtx, nil)
+	if err != nil {
+		return "", errors.New("beginning transaction: " + err.Error())
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_session WHERE expires < now()`); err != nil {
+		return "", errors.New("deleting expired sessions: " + err.Error())
+	}
+	result, err := tx.Exec(`
+INSERT INTO user_session (session_key, tm_user, expires, user_agent, client_ip)
+SELECT $1, id, now() + $3 * interval '1 second', $4, $5
+FROM tm_user
+WHERE username = $2
+`, id, username, int64(duration.Seconds()), userAgent, clientIP)
+	if err != nil {
+		return "", errors.New("inserting session: " + err.Error())
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return "", errors.New("getting inserted session rows: " + err.Error())
+	} else if rows != 1 {
+		return "", errors.New("inserting session: user '" + username + "' not found")
+	}
+	if err := tx.Commit(); err != nil {
+		return "", errors.New("committing transaction: " + err.Error())
+	}
+	return id, nil
+}
+
+// sessionTouchFraction is the fraction of the session duration after which a
+// use of the session extends it. Sessions aren't extended on every request,
+// which would write to the database on every request, so a session may expire
+// up to duration/sessionTouchFraction before its cookie does.
+const sessionTouchFraction = 10
+
+// TouchSession extends the session with the given ID of the user with the
+// given ID to expire after duration, if it was last extended more than
+// duration/sessionTouchFraction ago, and returns whether it exists and hadn't
+// already expired.
+//
+// An empty session ID is that of a cookie created before sessions were
+// recorded, or by the Perl Traffic Ops, which is valid until the cookie
+// itself expires.
+func TouchSession(db *sqlx.DB, userID int, sessionID string, duration time.Duration, timeout time.Duration) (bool, error) {
+	if sessionID == "" {
+		return true, nil
+	}
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+	result, err := db.ExecContext(dbCtx, `
+UPDATE user_session
+SET last_seen = now(), expires = now() + $3 * interval '1 second'
+WHERE session_key = $1 AND tm_user = $2 AND expires > now() AND last_seen < now() - $4 * interval '1 second'
+`, sessionID, userID, int64(duration.Seconds()), int64(duration.Seconds())/sessionTouchFraction)
+	if err != nil {
+		return false, errors.New("updating session: " + err.Error())
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.New("getting updated session rows: " + err.Error())
+	}
+	if rows > 0 {
+		return true, nil
+	}
+
+	// no rows were updated if the session was extended recently, as well as if it doesn't exist or has expired
+	valid := false
+	if err := db.QueryRowContext(dbCtx, `
+SELECT EXISTS(SELECT 1 FROM user_session WHERE session_key = $1 AND tm_user = $2 AND expires > now())
+`, sessionID, userID).Scan(&valid); err != nil {
+		return false, errors.New("querying session: " + err.Error())
+	}
+	return valid, nil
+}
+
+// DeleteSession ends the session with the given ID.
+func DeleteSession(tx *sql.Tx, sessionID string) error {
+	if _, err := tx.Exec(`DELETE FROM user_session WHERE session_key = $1`, sessionID); err != nil {
+		return errors.New("deleting session: " + err.Error())
+	}
+	return nil
+}
+
+// DeleteUserSessions ends all sessions of the user with the given ID, except
+// the one with the ID keepSessionID, if any, and returns how many it ended.
+func DeleteUserSessions(tx *sql.Tx, userID int, keepSessionID string) (int64, error) {
+	result, err := tx.Exec(`DELETE FROM user_session WHERE tm_user = $1 AND session_key <> $2`, userID, keepSessionID)
+	if err != nil {
+		return 0, errors.New("deleting user sessions: " + err.Error())
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.New("getting deleted user session rows: " + err.Error())
+	}
+	return rows, nil
+}
+
+// DeleteRoleSessions ends all sessions of the users with the role with the
+// given ID.
+func DeleteRoleSessions(tx *sql.Tx, roleID int) error {
+	if _, err := tx.Exec(`DELETE FROM user_session WHERE tm_user IN (SELECT id FROM tm_user WHERE role = $1)`, roleID); err != nil {
+		return errors.New("deleting role sessions: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The TOTP functionality defined in this package implements
+// https://tools.ietf.org/html/rfc6238 with the parameters supported by every
+// common authenticator app: HMAC-SHA1, a 30 second time step and 6 digits.
+const (
+	TOTPPeriod     = 30 * time.Second
+	TOTPDigits     = 6
+	TOTPSecretLen  = 20 // octets, per the recommendation of RFC 4226
+	TOTPSkewSteps  = 1  // how many time steps a code may be early or late, to allow for clock drift
+	totpModulo     = 1000000
+	totpCodeFormat = "%06d"
+
+	recoveryCodeLen      = 10 // octets, which base32-encode to exactly 16 characters
+	recoveryCodeGroupLen = 4
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new, random, base32-encoded TOTP secret.
+func GenerateTOTPSecret() (string, error) {
+	secret, err := generateSalt(TOTPSecretLen)
+	if err != nil {
+		return "", errors.New("generating random secret: " + err.Error())
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI returns the otpauth URI of the given secret, which authenticator
+// apps accept (typically as a QR code) to enroll it.
+func TOTPURI(issuer string, username string, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(TOTPDigits))
+	params.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(username)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPStep returns the TOTP time step containing t.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod.Seconds())
+}
+
+// TOTPCode returns the TOTP code of the given base32-encoded secret for the
+// given time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", errors.New("decoding secret: " + err.Error())
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, per RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf(totpCodeFormat, bin%totpModulo), nil
+}
+
+// VerifyTOTP checks whether code is a valid TOTP code of the given
+// base32-encoded secret at time t, allowing for TOTPSkewSteps of clock drift.
+//
+// To prevent a code from being replayed, lastStep is the time step of the
+// last code accepted for the secret, and no code of it or any earlier step is
+// accepted. The returned step is the one matched, which should be stored as
+// the next lastStep.
+func VerifyTOTP(secret string, code string, t time.Time, lastStep int64) (int64, bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false, nil
+	}
+	now := TOTPStep(t)
+	for step := now - TOTPSkewSteps; step <= now+TOTPSkewSteps; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false, err
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// GenerateRecoveryCodes returns n new, random recovery codes, each of which
+// may be used once in place of a TOTP code, e.g. by a user who lost the
+// device holding their secret.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b, err := generateSalt(recoveryCodeLen)
+		if err != nil {
+			return nil, errors.New("generating random recovery code: " + err.Error())
+		}
+		codes = append(codes, NormalizeRecoveryCode(base32.StdEncoding.EncodeToString(b)))
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode returns the canonical form of a recovery code as
+// entered by a user, who may have dropped the hyphen or changed the case.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+	groups := []string{}
+	for len(code) > recoveryCodeGroupLen {
+		groups = append(groups, code[:recoveryCodeGroupLen])
+		code = code[recoveryCodeGroupLen:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
@@ -0,0 +1,128 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 secret of the test vectors in RFC 6238 appendix B, base32-encoded.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B vectors, truncated to 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: unexpected error: %v", unix, err)
+		}
+		if code != expected {
+			t.Errorf("TOTPCode at %d: expected %s, actual %s", unix, expected, code)
+		}
+	}
+
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode with invalid secret: expected error, actual nil")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	matched, ok, err := VerifyTOTP(rfc6238Secret, "081804", now, 0)
+	if err != nil || !ok || matched != step {
+		t.Errorf("VerifyTOTP current code: expected step %d, true, nil; actual %d, %t, %v", step, matched, ok, err)
+	}
+
+	if _, ok, _ := VerifyTOTP(rfc6238Secret, "081804", now, step); ok {
+		t.Error("VerifyTOTP replayed code: expected false, actual true")
+	}
+
+	prev, _ := TOTPCode(rfc6238Secret, step-1)
+	if matched, ok, _ := VerifyTOTP(rfc6238Secret, prev, now, 0); !ok || matched != step-1 {
+		t.Errorf("VerifyTOTP code of previous step: expected step %d, true; actual %d, %t", step-1, matched, ok)
+	}
+
+	old, _ := TOTPCode(rfc6238Secret, step-TOTPSkewSteps-1)
+	if _, ok, _ := VerifyTOTP(rfc6238Secret, old, now, 0); ok {
+		t.Error("VerifyTOTP code outside skew: expected false, actual true")
+	}
+
+	if _, ok, _ := VerifyTOTP(rfc6238Secret, "81804", now, 0); ok {
+		t.Error("VerifyTOTP short code: expected false, actual true")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("generated secret %s can't generate codes: %v", secret, err)
+	}
+	uri := TOTPURI("Traffic Ops", "admin", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Traffic%20Ops:admin?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("unexpected URI: %s", uri)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("expected %d codes, actual %d", RecoveryCodeCount, len(codes))
+	}
+	seen := map[string]struct{}{}
+	for _, code := range codes {
+		if len(code) != 19 {
+			t.Errorf("expected code of form xxxx-xxxx-xxxx-xxxx, actual %s", code)
+		}
+		if _, ok := seen[code]; ok {
+			t.Errorf("duplicate code %s", code)
+		}
+		seen[code] = struct{}{}
+	}
+
+	code := codes[0]
+	entered := strings.ToUpper(strings.Replace(code, "-", "", -1))
+	if NormalizeRecoveryCode(entered) != code {
+		t.Errorf("NormalizeRecoveryCode(%s): expected %s, actual %s", entered, code, NormalizeRecoveryCode(entered))
+	}
+	if HashRecoveryCode(entered) != HashRecoveryCode(code) {
+		t.Error("HashRecoveryCode: expected equal hashes of equivalent codes")
+	}
+	if HashRecoveryCode(codes[0]) == HashRecoveryCode(codes[1]) {
+		t.Error("HashRecoveryCode: expected different hashes of different codes")
+	}
+}
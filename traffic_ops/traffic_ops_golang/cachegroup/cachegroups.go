@@ -34,7 +34,9 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
 	"github.com/go-ozzo/ozzo-validation"
 	"github.com/jmoiron/sqlx"
@@ -87,6 +89,60 @@ func (cg *TOCacheGroup) SetID(i int) {
 	cg.ID = &i
 }
 
+// isLegacy returns whether the request is of an API version before 3.0, of which the tenancy of cachegroups isn't a
+// part.
+func (cg *TOCacheGroup) isLegacy() bool {
+	return cg.ReqInfo.Version != nil && cg.ReqInfo.Version.Major < 3
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on both the tenant of the
+// cachegroup, if it exists and has one, and the tenant requested for it, if any.
+func (cg *TOCacheGroup) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
+	id := 0
+	if cg.ID != nil {
+		id = *cg.ID
+	}
+	requestedTenantID := cg.TenantID
+	if cg.isLegacy() {
+		requestedTenantID = nil
+	}
+	return tenant.IsOptionalResourceChangeAuthorizedToUserTx(`SELECT tenant_id FROM cachegroup WHERE id = $1`, id, requestedTenantID, user, cg.ReqInfo.Tx.Tx)
+}
+
+// checkTenancy returns an error if the user isn't authorized on the tenant of the cachegroup with the given ID, or on
+// the tenant of any of its servers, all of which are changed by queueing updates on the cachegroup or assigning delivery
+// services to it.
+func checkTenancy(tx *sql.Tx, cgID int, user *auth.CurrentUser) (error, error, int) {
+	authorized, err := tenant.AreOptionalResourcesAuthorizedToUserTx(`
+SELECT tenant_id FROM cachegroup WHERE id = $1
+UNION
+SELECT tenant_id FROM server WHERE cachegroup = $1
+`, []interface{}{cgID}, user, tx)
+	if err != nil {
+		return nil, errors.New("checking cachegroup tenancy: " + err.Error()), http.StatusInternalServerError
+	}
+	if !authorized {
+		return tc.TenantUserNotAuthError, nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
+
+// checkFallbacksTenancy returns an error if the user isn't authorized on the tenant of any of the cachegroup's
+// fallbacks, so that a cachegroup can't fall back to the caches of a tenant the user can't see.
+func (cg *TOCacheGroup) checkFallbacksTenancy() (error, error, int) {
+	if cg.Fallbacks == nil || len(*cg.Fallbacks) == 0 {
+		return nil, nil, http.StatusOK
+	}
+	authorized, err := tenant.AreOptionalResourcesAuthorizedToUserTx(`SELECT DISTINCT tenant_id FROM cachegroup WHERE name = ANY($1::text[])`, []interface{}{pq.Array(*cg.Fallbacks)}, cg.ReqInfo.User, cg.ReqInfo.Tx.Tx)
+	if err != nil {
+		return nil, errors.New("checking cachegroup fallbacks tenancy: " + err.Error()), http.StatusInternalServerError
+	}
+	if !authorized {
+		return tc.TenantUserNotAuthError, nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
+
 // Is the cachegroup being used?
 func isUsed(tx *sqlx.Tx, ID int) (bool, error) {
 
@@ -217,6 +273,14 @@ func (cg *TOCacheGroup) Create() (error, error, int) {
 		cg.FallbackToClosest = &fbc
 	}
 
+	if cg.isLegacy() {
+		cg.TenantID = nil
+	}
+
+	if userErr, sysErr, errCode := cg.checkFallbacksTenancy(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+
 	err := cg.ReqInfo.Tx.Tx.QueryRow(
 		InsertQuery(),
 		cg.Name,
@@ -225,11 +289,13 @@ func (cg *TOCacheGroup) Create() (error, error, int) {
 		cg.ParentCachegroupID,
 		cg.SecondaryParentCachegroupID,
 		cg.FallbackToClosest,
+		cg.TenantID,
 	).Scan(
 		&cg.ID,
 		&cg.Type,
 		&cg.ParentName,
 		&cg.SecondaryParentName,
+		&cg.Tenant,
 	)
 	if err != nil {
 		return api.ParseDBError(err)
@@ -257,6 +323,9 @@ func (cg *TOCacheGroup) Create() (error, error, int) {
 		return nil, errors.New("creating cachegroup: creating cache group fallbacks: " + err.Error()), http.StatusInternalServerError
 	}
 
+	if cg.isLegacy() {
+		cg.Tenant = nil
+	}
 	return nil, nil, http.StatusOK
 }
 
@@ -416,6 +485,8 @@ func GetCacheGroupsByName(names []string, Tx *sqlx.Tx) (map[string]tc.CacheGroup
 			&s.LastUpdated,
 			pq.Array(&cgfs),
 			&s.FallbackToClosest,
+			&s.Tenant,
+			&s.TenantID,
 		); err != nil {
 			return nil, nil, errors.New("cachegroup read: scanning: " + err.Error()), http.StatusInternalServerError
 		}
@@ -438,6 +509,7 @@ func (cg *TOCacheGroup) Read(h http.Header, useIMS bool) ([]interface{}, error,
 		"shortName": {"cachegroup.short_name", nil},
 		"type":      {"cachegroup.type", nil},
 		"topology":  {"topology_cachegroup.topology", nil},
+		"tenant":    {"cachegroup.tenant_id", api.IsInt},
 	}
 	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(cg.ReqInfo.Params, queryParamsToQueryCols)
 	if len(errs) > 0 {
@@ -467,6 +539,13 @@ LEFT JOIN topology_cachegroup ON cachegroup.name = topology_cachegroup.cachegrou
 			return nil, errors.New("cachegroup read: converting cachegroup type to integer " + err.Error()), nil, http.StatusBadRequest, nil
 		}
 	}
+
+	tenantIDs, err := tenant.GetUserTenantIDsTx(*cg.ReqInfo.User, cg.ReqInfo.Tx.Tx)
+	if err != nil {
+		return nil, nil, errors.New("cachegroup read: getting user tenants: " + err.Error()), http.StatusInternalServerError, nil
+	}
+	where, queryValues = dbhelpers.AddOptionalTenancyCheck(where, queryValues, "cachegroup.tenant_id", tenantIDs)
+
 	query := baseSelect + where + orderBy + pagination
 	rows, err := cg.ReqInfo.Tx.NamedQuery(query, queryValues)
 	if err != nil {
@@ -494,11 +573,17 @@ LEFT JOIN topology_cachegroup ON cachegroup.name = topology_cachegroup.cachegrou
 			&s.LastUpdated,
 			pq.Array(&cgfs),
 			&s.FallbackToClosest,
+			&s.Tenant,
+			&s.TenantID,
 		); err != nil {
 			return nil, nil, errors.New("cachegroup read: scanning: " + err.Error()), http.StatusInternalServerError, nil
 		}
 		s.LocalizationMethods = &lms
 		s.Fallbacks = &cgfs
+		if cg.isLegacy() {
+			s.Tenant = nil
+			s.TenantID = nil
+		}
 		cacheGroups = append(cacheGroups, s)
 	}
 	return cacheGroups, nil, nil, http.StatusOK, &maxTime
@@ -510,7 +595,8 @@ func selectMaxLastUpdatedQuery(where string) string {
 LEFT JOIN coordinate ON coordinate.id = cachegroup.coordinate
 INNER JOIN type ON cachegroup.type = type.id
 LEFT JOIN cachegroup AS cgp ON cachegroup.parent_cachegroup_id = cgp.id
-LEFT JOIN cachegroup AS cgs ON cachegroup.secondary_parent_cachegroup_id = cgs.id ` + where +
+LEFT JOIN cachegroup AS cgs ON cachegroup.secondary_parent_cachegroup_id = cgs.id
+LEFT JOIN tenant ON cachegroup.tenant_id = tenant.id ` + where +
 		` UNION ALL
 	select max(last_updated) as t from last_deleted l where l.table_name='cachegroup') as res`
 }
@@ -531,11 +617,22 @@ func (cg *TOCacheGroup) Update() (error, error, int) {
 		cg.FallbackToClosest = &fbc
 	}
 
+	if userErr, sysErr, errCode := cg.checkFallbacksTenancy(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+
 	coordinateID, userErr, sysErr, errCode := cg.handleCoordinateUpdate()
 	if userErr != nil || sysErr != nil {
 		return userErr, sysErr, errCode
 	}
 
+	// updates through legacy API versions don't change the tenant
+	if cg.isLegacy() {
+		if err := cg.ReqInfo.Tx.Tx.QueryRow(`SELECT tenant_id FROM cachegroup WHERE id = $1`, *cg.ID).Scan(&cg.TenantID); err != nil {
+			return nil, errors.New("cachegroup update: getting tenant: " + err.Error()), http.StatusInternalServerError
+		}
+	}
+
 	err := cg.ReqInfo.Tx.Tx.QueryRow(
 		UpdateQuery(),
 		cg.Name,
@@ -545,11 +642,13 @@ func (cg *TOCacheGroup) Update() (error, error, int) {
 		cg.SecondaryParentCachegroupID,
 		cg.TypeID,
 		cg.FallbackToClosest,
+		cg.TenantID,
 		cg.ID,
 	).Scan(
 		&cg.Type,
 		&cg.ParentName,
 		&cg.SecondaryParentName,
+		&cg.Tenant,
 		&cg.LastUpdated,
 	)
 	if err != nil {
@@ -564,6 +663,10 @@ func (cg *TOCacheGroup) Update() (error, error, int) {
 		return nil, errors.New("cachegroup update: creating cache group fallbacks: " + err.Error()), http.StatusInternalServerError
 	}
 
+	if cg.isLegacy() {
+		cg.Tenant = nil
+		cg.TenantID = nil
+	}
 	return nil, nil, http.StatusOK
 }
 
@@ -665,15 +768,17 @@ short_name,
 type,
 parent_cachegroup_id,
 secondary_parent_cachegroup_id,
-fallback_to_closest
-) VALUES($1,$2,$3,$4,$5,$6)
+fallback_to_closest,
+tenant_id
+) VALUES($1,$2,$3,$4,$5,$6,$7)
 RETURNING
 id,
 (SELECT name FROM type WHERE cachegroup.type = type.id),
 (SELECT name FROM cachegroup parent
 	WHERE cachegroup.parent_cachegroup_id = parent.id),
 (SELECT name FROM cachegroup secondary_parent
-	WHERE cachegroup.secondary_parent_cachegroup_id = secondary_parent.id)`
+	WHERE cachegroup.secondary_parent_cachegroup_id = secondary_parent.id),
+(SELECT name FROM tenant WHERE cachegroup.tenant_id = tenant.id)`
 }
 
 func SelectQuery() string {
@@ -696,12 +801,15 @@ type.name AS type_name,
 cachegroup.type AS type_id,
 cachegroup.last_updated,
 (SELECT COALESCE(array_agg(CAST(cg2.name as text) ORDER BY cgf.set_order ASC), '{}') AS fallbacks FROM cachegroup cg2 INNER JOIN cachegroup_fallbacks cgf ON cgf.backup_cg = cg2.id WHERE cgf.primary_cg = cachegroup.id),
-cachegroup.fallback_to_closest
+cachegroup.fallback_to_closest,
+tenant.name AS tenant,
+cachegroup.tenant_id
 FROM cachegroup
 LEFT JOIN coordinate ON coordinate.id = cachegroup.coordinate
 INNER JOIN type ON cachegroup.type = type.id
 LEFT JOIN cachegroup AS cgp ON cachegroup.parent_cachegroup_id = cgp.id
 LEFT JOIN cachegroup AS cgs ON cachegroup.secondary_parent_cachegroup_id = cgs.id
+LEFT JOIN tenant ON cachegroup.tenant_id = tenant.id
 `
 }
 
@@ -724,14 +832,16 @@ coordinate=$3,
 parent_cachegroup_id=$4,
 secondary_parent_cachegroup_id=$5,
 type=$6,
-fallback_to_closest=$7
-WHERE id=$8
+fallback_to_closest=$7,
+tenant_id=$8
+WHERE id=$9
 RETURNING
 (SELECT name FROM type WHERE cachegroup.type = type.id),
 (SELECT name FROM cachegroup parent
 	WHERE cachegroup.parent_cachegroup_id = parent.id),
 (SELECT name FROM cachegroup secondary_parent
 	WHERE cachegroup.secondary_parent_cachegroup_id = secondary_parent.id),
+(SELECT name FROM tenant WHERE cachegroup.tenant_id = tenant.id),
 last_updated`
 }
 
@@ -30,6 +30,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/test"
 	"github.com/jmoiron/sqlx"
 
@@ -112,6 +113,8 @@ func TestReadCacheGroups(t *testing.T) {
 		"last_updated",
 		"fallbacks",
 		"fallbackToClosest",
+		"tenant",
+		"tenant_id",
 	})
 
 	for _, ts := range testCGs {
@@ -131,13 +134,16 @@ func TestReadCacheGroups(t *testing.T) {
 			ts.LastUpdated,
 			[]byte("{cachegroup2,cachegroup3}"),
 			ts.FallbackToClosest,
+			nil,
+			nil,
 		)
 	}
 	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active", "parent_id", "last_updated"}).AddRow(1, "root", true, nil, time.Now()))
 	mock.ExpectQuery("SELECT").WillReturnRows(rows)
 	mock.ExpectCommit()
 
-	reqInfo := api.APIInfo{Tx: db.MustBegin(), Params: map[string]string{"id": "1"}}
+	reqInfo := api.APIInfo{Tx: db.MustBegin(), Params: map[string]string{"id": "1"}, User: &auth.CurrentUser{TenantID: 1}}
 	obj := TOCacheGroup{
 		api.APIInfoImpl{&reqInfo},
 		tc.CacheGroupNullable{},
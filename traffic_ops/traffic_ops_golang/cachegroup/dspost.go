@@ -92,6 +92,9 @@ func postDSes(tx *sql.Tx, user *auth.CurrentUser, cgID int, dsIDs []int) (tc.Cac
 	} else if !ok {
 		return tc.CacheGroupPostDSResp{}, fmt.Errorf("cachegroup %d does not exist", cgID), nil, http.StatusNotFound
 	}
+	if usrErr, sysErr, errCode := checkTenancy(tx, cgID, user); usrErr != nil || sysErr != nil {
+		return tc.CacheGroupPostDSResp{}, usrErr, sysErr, errCode
+	}
 
 	topologyDSes, err := dbhelpers.GetDeliveryServicesWithTopologies(tx, dsIDs)
 	if err != nil {
@@ -33,6 +33,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/lib/go-util"
@@ -40,6 +41,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 )
 
 const (
@@ -192,6 +194,12 @@ func (cgparam *TOCacheGroupParameter) GetKeys() (map[string]interface{}, bool) {
 }
 
 // Delete implements the api.CRUDer interface.
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the tenant of the
+// cachegroup, if it has one.
+func (cgparam *TOCacheGroupParameter) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
+	return tenant.IsOptionalResourceChangeAuthorizedToUserTx(`SELECT tenant_id FROM cachegroup WHERE id = $1`, cgparam.CacheGroupID, nil, user, cgparam.ReqInfo.Tx.Tx)
+}
+
 func (cgparam *TOCacheGroupParameter) Delete() (error, error, int) {
 	_, ok, err := dbhelpers.GetCacheGroupNameFromID(cgparam.ReqInfo.Tx.Tx, cgparam.CacheGroupID)
 	if err != nil {
@@ -322,6 +330,20 @@ func AddCacheGroupParameters(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	cgIDs := make([]int, 0, len(params))
+	for _, p := range params {
+		cgIDs = append(cgIDs, *p.CacheGroup)
+	}
+	authorized, err := tenant.AreOptionalResourcesAuthorizedToUserTx(`SELECT DISTINCT tenant_id FROM cachegroup WHERE id = ANY($1::bigint[])`, []interface{}{pq.Array(cgIDs)}, inf.User, inf.Tx.Tx)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking cachegroup tenancy: "+err.Error()))
+		return
+	}
+	if !authorized {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, tc.TenantUserNotAuthError, nil)
+		return
+	}
+
 	values := []string{}
 	for _, param := range params {
 		values = append(values, "("+strconv.Itoa(*param.CacheGroup)+", "+strconv.Itoa(*param.Parameter)+")")
@@ -32,6 +32,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federations"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 	"github.com/asaskevich/govalidator"
 	"github.com/go-ozzo/ozzo-validation"
@@ -203,7 +204,28 @@ func (fed *TOCDNFederation) Update() (error, error, int) {
 		fed.XmlId = nil
 		fed.DeliveryServiceIDs = nil
 	}
-	return api.GenericUpdate(fed)
+
+	previousTTL := 0
+	if err := fed.APIInfo().Tx.Tx.QueryRow(`SELECT ttl FROM federation WHERE id = $1`, *fed.ID).Scan(&previousTTL); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no " + fed.GetType() + " found with this id"), nil, http.StatusNotFound
+		}
+		return nil, errors.New("getting federation TTL: " + err.Error()), http.StatusInternalServerError
+	}
+
+	userErr, sysErr, errCode = api.GenericUpdate(fed)
+	if userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+
+	// The TTL of a federation is the TTL of each of its resolver mappings, so
+	// a change of it is recorded in their history.
+	if fed.TTL != nil && *fed.TTL != previousTTL {
+		if err := federations.RecordTTLChange(fed.APIInfo().Tx.Tx, *fed.ID, previousTTL, fed.APIInfo().User.UserName); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+	}
+	return nil, nil, http.StatusOK
 }
 
 // Delete implements the Deleter interface for TOCDNFederation.
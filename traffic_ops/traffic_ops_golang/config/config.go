@@ -102,6 +102,17 @@ type ConfigTrafficOpsGolang struct {
 	RoutingBlacklist         `json:"routing_blacklist"`
 	SupportedDSMetrics       []string `json:"supported_ds_metrics"`
 
+	// MaintenanceMaxCachegroupDownPercent is the maximum percentage of the caches in a cachegroup which may be
+	// unavailable at once, including those in maintenance windows. Maintenance windows which would exceed it are rejected.
+	MaintenanceMaxCachegroupDownPercent int `json:"maintenance_max_cachegroup_down_percent"`
+
+	// PasswordPolicy is the policy which the new passwords of local users must satisfy.
+	PasswordPolicy ConfigPasswordPolicy `json:"password_policy"`
+	// Lockout configures locking users out after repeated failed login attempts.
+	Lockout ConfigLockout `json:"lockout"`
+	// ServerLifecycle configures the gate checks of the transitions of the provisioning lifecycle of servers.
+	ServerLifecycle ConfigServerLifecycle `json:"server_lifecycle"`
+
 	// CRConfigUseRequestHost is whether to use the client request host header in the CRConfig. If false, uses the tm.url parameter.
 	// This defaults to false. Traffic Ops used to always use the host header, setting this true will resume that legacy behavior.
 	// See https://github.com/apache/trafficcontrol/issues/2224
@@ -120,6 +131,46 @@ type RoutingBlacklist struct {
 	DisabledRoutes      []int `json:"disabled_routes"`
 }
 
+// ConfigPasswordPolicy contains the requirements of the passwords of local users.
+type ConfigPasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	// HistoryCount is the number of a user's most recent passwords which they may not reuse. 0 allows reusing any.
+	HistoryCount int `json:"history_count"`
+	// MaxAgeDays is the number of days after which a password expires and must be reset. 0 means never.
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+// ConfigLockout contains the settings of locking users out after repeated failed login attempts.
+type ConfigLockout struct {
+	// MaxFailedLogins is the number of consecutive failed login attempts after which a user is locked out. 0 disables
+	// locking users out.
+	MaxFailedLogins int `json:"max_failed_logins"`
+	// DurationSeconds is how long a user stays locked out. 0 means until an administrator unlocks them.
+	DurationSeconds int `json:"duration_seconds"`
+}
+
+// ConfigServerLifecycle contains the gate checks of the transitions of the provisioning lifecycle of servers.
+type ConfigServerLifecycle struct {
+	// Gates are the checks which must pass before a server may transition to each lifecycle state, keyed by the state.
+	// Transitions to states without a gate have no checks.
+	Gates map[string]ConfigServerLifecycleGate `json:"gates"`
+}
+
+// ConfigServerLifecycleGate contains the checks which must pass before a server may transition to a lifecycle state.
+type ConfigServerLifecycleGate struct {
+	// ServerChecks are the minimum values of the server's servercheck results, keyed by the check's short name.
+	ServerChecks map[string]int `json:"server_checks"`
+	// ORTSuccess requires the server to have no pending updates or revalidations, i.e. ORT has successfully applied
+	// all of the configuration changes queued on it.
+	ORTSuccess bool `json:"ort_success"`
+	// MonitorAvailable requires the Traffic Monitor of the server's CDN to report it available.
+	MonitorAvailable bool `json:"monitor_available"`
+}
+
 // ConfigTO contains information to identify Traffic Ops in a network sense.
 type ConfigTO struct {
 	BaseURL               *rfc.URL          `json:"base_url"`
@@ -329,9 +380,11 @@ func (c Config) GetKeyPath() string {
 }
 
 const (
-	MojoliciousConcurrentConnectionsDefault = 12 // MojoliciousConcurrentConnectionsDefault
-	DBMaxIdleConnectionsDefault             = 10 // if this is higher than MaxDBConnections it will be automatically adjusted below it by the db/sql library
-	DBConnMaxLifetimeSecondsDefault         = 60
+	MojoliciousConcurrentConnectionsDefault    = 12 // MojoliciousConcurrentConnectionsDefault
+	DBMaxIdleConnectionsDefault                = 10 // if this is higher than MaxDBConnections it will be automatically adjusted below it by the db/sql library
+	DBConnMaxLifetimeSecondsDefault            = 60
+	MaintenanceMaxCachegroupDownPercentDefault = 50
+	PasswordMinLengthDefault                   = 8
 )
 
 // ParseConfig validates required fields, and parses non-JSON types
@@ -373,6 +426,12 @@ func ParseConfig(cfg Config) (Config, error) {
 	if cfg.DBQueryTimeoutSeconds == 0 {
 		cfg.DBQueryTimeoutSeconds = DefaultDBQueryTimeoutSecs
 	}
+	if cfg.MaintenanceMaxCachegroupDownPercent == 0 {
+		cfg.MaintenanceMaxCachegroupDownPercent = MaintenanceMaxCachegroupDownPercentDefault
+	}
+	if cfg.PasswordPolicy.MinLength == 0 {
+		cfg.PasswordPolicy.MinLength = PasswordMinLengthDefault
+	}
 
 	invalidTOURLStr := ""
 	var err error
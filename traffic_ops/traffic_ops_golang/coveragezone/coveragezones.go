@@ -0,0 +1,284 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// we need a type alias to define functions on
+type TOCoverageZone struct {
+	api.APIInfoImpl `json:"-"`
+	tc.CoverageZoneNullable
+}
+
+func (v *TOCoverageZone) SetLastUpdated(t tc.TimeNoMod) { v.LastUpdated = &t }
+func (v *TOCoverageZone) InsertQuery() string           { return insertQuery() }
+func (v *TOCoverageZone) NewReadObj() interface{}       { return &tc.CoverageZoneNullable{} }
+func (v *TOCoverageZone) SelectQuery() string           { return selectQuery() }
+func (v *TOCoverageZone) ParamColumns() map[string]dbhelpers.WhereColumnInfo {
+	return map[string]dbhelpers.WhereColumnInfo{
+		"id":           dbhelpers.WhereColumnInfo{"cz.id", api.IsInt},
+		"cdnId":        dbhelpers.WhereColumnInfo{"cz.cdn", api.IsInt},
+		"cdn":          dbhelpers.WhereColumnInfo{"cdn.name", nil},
+		"cachegroupId": dbhelpers.WhereColumnInfo{"cz.cachegroup", api.IsInt},
+		"cachegroup":   dbhelpers.WhereColumnInfo{"cg.name", nil},
+		"deep":         dbhelpers.WhereColumnInfo{"cz.deep", api.IsBool},
+	}
+}
+func (v *TOCoverageZone) UpdateQuery() string { return updateQuery() }
+func (v *TOCoverageZone) DeleteQuery() string { return deleteQuery() }
+
+func (cz TOCoverageZone) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+// Implementation of the Identifier, Validator interface functions
+func (cz TOCoverageZone) GetKeys() (map[string]interface{}, bool) {
+	if cz.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *cz.ID}, true
+}
+
+func (cz TOCoverageZone) GetAuditName() string {
+	if cz.Network != nil {
+		return *cz.Network
+	}
+	if cz.ID != nil {
+		return strconv.Itoa(*cz.ID)
+	}
+	return "0"
+}
+
+func (cz TOCoverageZone) GetType() string {
+	return "coverage_zone"
+}
+
+func (cz *TOCoverageZone) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	cz.ID = &i
+}
+
+// Validate fulfills the api.Validator interface
+func (cz TOCoverageZone) Validate() error {
+	errs := validation.Errors{
+		"cdnId":        validation.Validate(cz.CDNID, validation.Required),
+		"cachegroupId": validation.Validate(cz.CachegroupID, validation.Required),
+		"network":      validation.Validate(cz.Network, validation.Required, validation.By(validateNetwork)),
+	}
+	return util.JoinErrs(tovalidate.ToErrors(errs))
+}
+
+// validateNetwork checks that a network is in CIDR notation, with no bits set in the host part of its address, since
+// Traffic Router would silently treat e.g. 192.0.2.1/24 as 192.0.2.0/24.
+func validateNetwork(value interface{}) error {
+	network, ok := value.(*string)
+	if !ok || network == nil {
+		return nil
+	}
+	ip, ipn, err := net.ParseCIDR(*network)
+	if err != nil {
+		return errors.New("must be a network in CIDR notation, e.g. 192.0.2.0/24 or 2001:db8::/32")
+	}
+	if !ip.Equal(ipn.IP) {
+		return fmt.Errorf("must not have host bits set, did you mean %s?", ipn.String())
+	}
+	return nil
+}
+
+func (cz *TOCoverageZone) Create() (error, error, int) {
+	if userErr, sysErr, errCode := cz.checkReferences(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	return api.GenericCreate(cz)
+}
+
+func (cz *TOCoverageZone) Read(h http.Header, useIMS bool) ([]interface{}, error, error, int, *time.Time) {
+	return api.GenericRead(h, cz, useIMS)
+}
+
+func (v *TOCoverageZone) SelectMaxLastUpdatedQuery(where, orderBy, pagination, tableName string) string {
+	return `SELECT max(t) from (
+		SELECT max(cz.last_updated) as t from ` + tableName + ` cz
+		JOIN cdn ON cdn.id = cz.cdn
+		JOIN cachegroup cg ON cg.id = cz.cachegroup ` + where + orderBy + pagination +
+		` UNION ALL
+	select max(last_updated) as t from last_deleted l where l.table_name='` + tableName + `') as res`
+}
+
+func (cz *TOCoverageZone) Update() (error, error, int) {
+	if userErr, sysErr, errCode := cz.checkReferences(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	return api.GenericUpdate(cz)
+}
+
+func (cz *TOCoverageZone) Delete() (error, error, int) { return api.GenericDelete(cz) }
+
+// checkReferences returns a user error if the coverage zone's CDN or cachegroup doesn't exist, if the cachegroup is not
+// an edge cachegroup - Traffic Router ignores coverage zones of any others - or if its network overlaps that of another
+// coverage zone of the same kind in the same CDN. It also normalizes the network, so that e.g. 2001:DB8::/32 and
+// 2001:db8::/32 are stored the same way.
+func (cz *TOCoverageZone) checkReferences() (error, error, int) {
+	tx := cz.APIInfo().Tx.Tx
+	_, ipn, _ := net.ParseCIDR(*cz.Network) // already validated
+	network := ipn.String()
+	cz.Network = &network
+	if cz.Deep == nil {
+		deep := false
+		cz.Deep = &deep
+	}
+
+	if _, ok, err := dbhelpers.GetCDNNameFromID(tx, int64(*cz.CDNID)); err != nil {
+		return nil, errors.New("checking coverage zone CDN existence: " + err.Error()), http.StatusInternalServerError
+	} else if !ok {
+		return fmt.Errorf("no CDN with id %d", *cz.CDNID), nil, http.StatusBadRequest
+	}
+
+	cgName, cgType := "", ""
+	if err := tx.QueryRow(`SELECT cg.name, t.name FROM cachegroup cg JOIN type t ON t.id = cg.type WHERE cg.id = $1`, *cz.CachegroupID).Scan(&cgName, &cgType); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no cachegroup with id %d", *cz.CachegroupID), nil, http.StatusBadRequest
+		}
+		return nil, errors.New("checking coverage zone cachegroup: " + err.Error()), http.StatusInternalServerError
+	}
+	if cgType != tc.CacheGroupEdgeTypeName {
+		return fmt.Errorf("cachegroup '%s' is of type %s, but coverage zones may only map networks to %s cachegroups", cgName, cgType, tc.CacheGroupEdgeTypeName), nil, http.StatusBadRequest
+	}
+
+	id := 0
+	if cz.ID != nil {
+		id = *cz.ID
+	}
+	others, err := getNetworks(tx, *cz.CDNID, *cz.Deep, id)
+	if err != nil {
+		return nil, errors.New("getting coverage zone networks: " + err.Error()), http.StatusInternalServerError
+	}
+	if other := findOverlap(ipn, others); other != nil {
+		return fmt.Errorf("network %s overlaps network %s of cachegroup '%s' in the same CDN", network, other.Network.String(), other.Cachegroup), nil, http.StatusBadRequest
+	}
+	return nil, nil, http.StatusOK
+}
+
+// coverageZoneNetwork is the parsed network of a coverage zone.
+type coverageZoneNetwork struct {
+	ID         int
+	Cachegroup string
+	Network    *net.IPNet
+}
+
+// getNetworks returns the networks of the coverage zones of the given CDN which are or are not deep, except for the
+// one with the ID exceptID.
+func getNetworks(tx *sql.Tx, cdnID int, deep bool, exceptID int) ([]coverageZoneNetwork, error) {
+	rows, err := tx.Query(`
+SELECT cz.id, cg.name, cz.network
+FROM coverage_zone cz
+JOIN cachegroup cg ON cg.id = cz.cachegroup
+WHERE cz.cdn = $1 AND cz.deep = $2 AND cz.id <> $3
+`, cdnID, deep, exceptID)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	networks := []coverageZoneNetwork{}
+	for rows.Next() {
+		n := coverageZoneNetwork{}
+		network := ""
+		if err := rows.Scan(&n.ID, &n.Cachegroup, &network); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		if _, n.Network, err = net.ParseCIDR(network); err != nil {
+			return nil, fmt.Errorf("parsing network '%s' of coverage zone %d: %v", network, n.ID, err)
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// findOverlap returns the first of the given networks which overlaps ipn, or nil if none do. Two CIDR networks overlap
+// if and only if one of them is a subset of the other.
+func findOverlap(ipn *net.IPNet, networks []coverageZoneNetwork) *coverageZoneNetwork {
+	for i, n := range networks {
+		if util.CIDRIsSubset(ipn, n.Network) || util.CIDRIsSubset(n.Network, ipn) {
+			return &networks[i]
+		}
+	}
+	return nil
+}
+
+func selectQuery() string {
+	query := `SELECT
+cz.id,
+cz.cdn,
+cdn.name AS cdn_name,
+cz.cachegroup,
+cg.name AS cachegroup_name,
+cz.network,
+cz.deep,
+cz.last_updated
+
+FROM coverage_zone cz
+JOIN cdn ON cdn.id = cz.cdn
+JOIN cachegroup cg ON cg.id = cz.cachegroup`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE
+coverage_zone SET
+cdn=:cdn,
+cachegroup=:cachegroup,
+network=:network,
+deep=:deep
+WHERE id=:id RETURNING last_updated`
+	return query
+}
+
+func insertQuery() string {
+	query := `INSERT INTO coverage_zone (
+cdn,
+cachegroup,
+network,
+deep) VALUES (
+:cdn,
+:cachegroup,
+:network,
+:deep) RETURNING id,last_updated`
+	return query
+}
+
+func deleteQuery() string {
+	return `DELETE FROM coverage_zone WHERE id = :id`
+}
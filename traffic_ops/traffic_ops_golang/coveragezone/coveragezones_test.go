@@ -0,0 +1,140 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestValidate(t *testing.T) {
+	cz := TOCoverageZone{}
+	cz.CDNID = util.IntPtr(1)
+	cz.CachegroupID = util.IntPtr(2)
+	for _, network := range []string{"192.0.2.0/24", "192.0.2.1/32", "2001:db8::/32"} {
+		cz.Network = util.StrPtr(network)
+		if err := cz.Validate(); err != nil {
+			t.Errorf("expected network %s to be valid, got error: %v", network, err)
+		}
+	}
+	for _, network := range []string{"192.0.2.0", "192.0.2.0/33", "not a network", "192.0.2.1/24", "2001:db8::1/32"} {
+		cz.Network = util.StrPtr(network)
+		if err := cz.Validate(); err == nil || !strings.Contains(err.Error(), "network") {
+			t.Errorf("expected error about network %s, got: %v", network, err)
+		}
+	}
+
+	cz.Network = util.StrPtr("192.0.2.0/24")
+	cz.CachegroupID = nil
+	if err := cz.Validate(); err == nil || !strings.Contains(err.Error(), "cachegroupId") {
+		t.Errorf("expected error about missing cachegroupId, got: %v", err)
+	}
+}
+
+func TestFindOverlap(t *testing.T) {
+	parse := func(s string) *net.IPNet {
+		_, ipn, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", s, err)
+		}
+		return ipn
+	}
+	networks := []coverageZoneNetwork{
+		{ID: 1, Cachegroup: "edge1", Network: parse("192.0.2.0/25")},
+		{ID: 2, Cachegroup: "edge2", Network: parse("198.51.100.0/24")},
+		{ID: 3, Cachegroup: "edge3", Network: parse("2001:db8::/32")},
+	}
+
+	cases := map[string]int{
+		"192.0.2.128/25":  0,
+		"192.0.2.0/24":    1,
+		"192.0.2.64/26":   1,
+		"198.51.100.7/32": 2,
+		"203.0.113.0/24":  0,
+		"2001:db8:1::/48": 3,
+		"2001:db9::/32":   0,
+		"::/0":            3,
+	}
+	for network, expected := range cases {
+		actual := findOverlap(parse(network), networks)
+		if expected == 0 && actual != nil {
+			t.Errorf("expected %s to overlap nothing, got %s", network, actual.Network)
+		} else if expected != 0 && (actual == nil || actual.ID != expected) {
+			t.Errorf("expected %s to overlap coverage zone %d, got %+v", network, expected, actual)
+		}
+	}
+}
+
+func TestBuildLocations(t *testing.T) {
+	lat := 39.1178
+	long := -106.48025
+	earlier := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	zones := []fileZone{
+		{Cachegroup: "edge1", Network: "198.51.100.0/24", Latitude: &lat, Longitude: &long, Caches: []string{"edge1-a", "edge1-b"}, LastUpdated: earlier},
+		{Cachegroup: "edge1", Network: "192.0.2.0/24", Latitude: &lat, Longitude: &long, Caches: []string{"edge1-a", "edge1-b"}, LastUpdated: later},
+		{Cachegroup: "edge1", Network: "2001:db8::/32", Latitude: &lat, Longitude: &long, Caches: []string{"edge1-a", "edge1-b"}, LastUpdated: earlier},
+		{Cachegroup: "edge2", Network: "203.0.113.0/24", Caches: []string{}, LastUpdated: earlier},
+	}
+
+	locations, revision, err := buildLocations(zones, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revision != "2020-08-01T01:00:00Z" {
+		t.Errorf("expected revision to be the latest update, got %s", revision)
+	}
+	edge1, ok := locations["edge1"]
+	if !ok {
+		t.Fatalf("expected location edge1, got %+v", locations)
+	}
+	if !reflect.DeepEqual(edge1.Network, []string{"192.0.2.0/24", "198.51.100.0/24"}) {
+		t.Errorf("expected sorted IPv4 networks for edge1, got %v", edge1.Network)
+	}
+	if !reflect.DeepEqual(edge1.Network6, []string{"2001:db8::/32"}) {
+		t.Errorf("expected IPv6 networks for edge1, got %v", edge1.Network6)
+	}
+	if edge1.Coordinates == nil || edge1.Coordinates.Latitude != lat || edge1.Coordinates.Longitude != long {
+		t.Errorf("expected coordinates of edge1, got %+v", edge1.Coordinates)
+	}
+	if edge1.Caches != nil {
+		t.Errorf("expected no caches in a coverage zone file, got %v", edge1.Caches)
+	}
+	if edge2 := locations["edge2"]; edge2.Coordinates != nil || len(edge2.Network6) != 0 {
+		t.Errorf("expected edge2 to have no coordinates or IPv6 networks, got %+v", edge2)
+	}
+
+	locations, _, err = buildLocations(zones, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(locations["edge1"].Caches, []string{"edge1-a", "edge1-b"}) {
+		t.Errorf("expected caches in a deep coverage zone file, got %v", locations["edge1"].Caches)
+	}
+
+	if locations, revision, err = buildLocations(nil, false); err != nil || len(locations) != 0 || revision != "" {
+		t.Errorf("expected empty file with no revision, got %+v, %q, %v", locations, revision, err)
+	}
+}
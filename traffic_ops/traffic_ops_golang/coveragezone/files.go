@@ -0,0 +1,171 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	"github.com/lib/pq"
+)
+
+// GetCoverageZoneFile is the handler for GET requests to /cdns/{name}/coveragezones, which responds with the Coverage
+// Zone File of the CDN, as consumed by Traffic Router.
+func GetCoverageZoneFile(w http.ResponseWriter, r *http.Request) {
+	getFile(w, r, false)
+}
+
+// GetDeepCoverageZoneFile is the handler for GET requests to /cdns/{name}/deepcoveragezones, which responds with the
+// Deep Coverage Zone File of the CDN, as consumed by Traffic Router.
+func GetDeepCoverageZoneFile(w http.ResponseWriter, r *http.Request) {
+	getFile(w, r, true)
+}
+
+func getFile(w http.ResponseWriter, r *http.Request, deep bool) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["name"]
+	if ok, err := dbhelpers.CDNExists(cdn, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking CDN existence: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no such CDN: "+cdn), nil)
+		return
+	}
+
+	zones, err := getFileZones(inf.Tx.Tx, cdn, deep)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting coverage zones: "+err.Error()))
+		return
+	}
+	locations, revision, err := buildLocations(zones, deep)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("building coverage zone file: "+err.Error()))
+		return
+	}
+	if deep {
+		api.WriteRespRaw(w, r, tc.DeepCoverageZoneFile{Revision: revision, DeepCoverageZones: locations})
+		return
+	}
+	api.WriteRespRaw(w, r, tc.CoverageZoneFile{Revision: revision, CoverageZones: locations})
+}
+
+// fileZone is a single coverage zone, with everything about its cachegroup needed to render it into a file.
+type fileZone struct {
+	Cachegroup  string
+	Network     string
+	Latitude    *float64
+	Longitude   *float64
+	Caches      []string
+	LastUpdated time.Time
+}
+
+// getFileZones returns the coverage zones of the CDN named cdn which are or are not deep. The Caches of each are the
+// edge caches of its cachegroup in the CDN.
+func getFileZones(tx *sql.Tx, cdn string, deep bool) ([]fileZone, error) {
+	rows, err := tx.Query(`
+SELECT
+	cg.name,
+	cz.network,
+	co.latitude,
+	co.longitude,
+	ARRAY(
+		SELECT s.host_name
+		FROM server s
+		JOIN type t ON t.id = s.type
+		WHERE s.cachegroup = cg.id AND s.cdn_id = cz.cdn AND t.name LIKE '`+tc.EdgeTypePrefix+`%'
+		ORDER BY s.host_name
+	),
+	cz.last_updated
+FROM coverage_zone cz
+JOIN cdn ON cdn.id = cz.cdn
+JOIN cachegroup cg ON cg.id = cz.cachegroup
+LEFT JOIN coordinate co ON co.id = cg.coordinate
+WHERE cdn.name = $1 AND cz.deep = $2
+`, cdn, deep)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	zones := []fileZone{}
+	for rows.Next() {
+		z := fileZone{}
+		if err := rows.Scan(&z.Cachegroup, &z.Network, &z.Latitude, &z.Longitude, pq.Array(&z.Caches), &z.LastUpdated); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
+
+// buildLocations groups the given coverage zones by cachegroup into the locations of a Coverage Zone File or, if deep
+// is true, a Deep Coverage Zone File. It also returns the revision of the file, which is the time the most recently
+// changed of the zones was last updated, or an empty string if there are none.
+func buildLocations(zones []fileZone, deep bool) (map[string]tc.CoverageZoneLocation, string, error) {
+	locations := map[string]tc.CoverageZoneLocation{}
+	lastUpdated := time.Time{}
+	for _, z := range zones {
+		_, ipn, err := net.ParseCIDR(z.Network)
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing network '%s' of cachegroup '%s': %v", z.Network, z.Cachegroup, err)
+		}
+		loc, ok := locations[z.Cachegroup]
+		if !ok {
+			loc = tc.CoverageZoneLocation{Network: []string{}, Network6: []string{}}
+			if z.Latitude != nil && z.Longitude != nil {
+				loc.Coordinates = &tc.CoverageZoneCoordinates{Latitude: *z.Latitude, Longitude: *z.Longitude}
+			}
+			if deep {
+				loc.Caches = z.Caches
+			}
+		}
+		if ipn.IP.To4() != nil {
+			loc.Network = append(loc.Network, ipn.String())
+		} else {
+			loc.Network6 = append(loc.Network6, ipn.String())
+		}
+		locations[z.Cachegroup] = loc
+		if z.LastUpdated.After(lastUpdated) {
+			lastUpdated = z.LastUpdated
+		}
+	}
+	for _, loc := range locations {
+		sort.Strings(loc.Network)
+		sort.Strings(loc.Network6)
+	}
+	if lastUpdated.IsZero() {
+		return locations, "", nil
+	}
+	return locations, lastUpdated.UTC().Format(time.RFC3339), nil
+}
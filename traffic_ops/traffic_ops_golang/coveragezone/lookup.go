@@ -0,0 +1,101 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+// Lookup is the handler for GET requests to /coveragezones/lookup.
+//
+// It responds with the coverage zones whose networks contain the requested IP address, in every CDN or only in the
+// requested one. Since the networks of the coverage zones of a CDN can't overlap, this is at most one coverage zone and
+// one deep coverage zone per CDN - the ones Traffic Router would use to route a client with that address.
+func Lookup(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"ip"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	ip := net.ParseIP(inf.Params["ip"])
+	if ip == nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("ip must be an IPv4 or IPv6 address"), nil)
+		return
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	cdn := inf.Params["cdn"]
+	if cdn != "" {
+		if ok, err := dbhelpers.CDNExists(cdn, inf.Tx.Tx); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking CDN existence: "+err.Error()))
+			return
+		} else if !ok {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no such CDN: "+cdn), nil)
+			return
+		}
+	}
+
+	zones, err := LookupIP(inf.Tx.Tx, ip, cdn)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("looking up coverage zones: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, zones)
+}
+
+// LookupIP returns the coverage zones whose networks contain ip, in the CDN named cdn or, if it's empty, in every CDN.
+// IPv4 addresses must be in their 4-byte form.
+func LookupIP(tx *sql.Tx, ip net.IP, cdn string) ([]tc.CoverageZoneNullable, error) {
+	rows, err := tx.Query(selectQuery()+`
+WHERE ($1 = '' OR cdn.name = $1)
+ORDER BY cdn.name, cz.deep`, cdn)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+
+	ipn := util.IPToCIDR(ip)
+	zones := []tc.CoverageZoneNullable{}
+	for rows.Next() {
+		cz := tc.CoverageZoneNullable{LastUpdated: &tc.TimeNoMod{}}
+		if err := rows.Scan(&cz.ID, &cz.CDNID, &cz.CDNName, &cz.CachegroupID, &cz.CachegroupName, &cz.Network, &cz.Deep, cz.LastUpdated); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		_, network, err := net.ParseCIDR(*cz.Network)
+		if err != nil {
+			return nil, errors.New("parsing network '" + *cz.Network + "': " + err.Error())
+		}
+		if util.CIDRIsSubset(ipn, network) {
+			zones = append(zones, cz)
+		}
+	}
+	return zones, nil
+}
@@ -22,6 +22,8 @@ package crconfig
 import (
 	"database/sql"
 	"errors"
+	"math"
+	"sort"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/lib/pq"
@@ -112,5 +114,158 @@ and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
 	if err := rows.Err(); err != nil {
 		return nil, nil, errors.New("Error iterating cachegroup rows: " + err.Error())
 	}
+
+	topologyNodes, err := getTopologyEdgeNodes(cdn, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for topology, nodes := range topologyNodes {
+		for cachegroup, topologyFallbacks := range makeTopologyFallbacks(nodes, edgeLocs) {
+			latlon := edgeLocs[cachegroup]
+			if latlon.BackupLocations.Topologies == nil {
+				latlon.BackupLocations.Topologies = map[string][]string{}
+			}
+			latlon.BackupLocations.Topologies[topology] = topologyFallbacks
+			edgeLocs[cachegroup] = latlon
+		}
+	}
 	return edgeLocs, routerLocs, nil
 }
+
+// topologyEdgeNode is an edge cachegroup of a topology, with the names of its
+// parents in the topology, primary first.
+type topologyEdgeNode struct {
+	Cachegroup string
+	Parents    []string
+}
+
+// getTopologyEdgeNodes returns a map[topologyName][]topologyEdgeNode of the
+// topologies assigned to the Delivery Services of the given CDN.
+func getTopologyEdgeNodes(cdn string, tx *sql.Tx) (map[string][]topologyEdgeNode, error) {
+	q := `
+SELECT
+  tc.topology,
+  tc.cachegroup,
+  ARRAY(
+    SELECT p.cachegroup
+    FROM topology_cachegroup_parents tcp
+    JOIN topology_cachegroup p ON p.id = tcp.parent
+    WHERE tcp.child = tc.id
+    ORDER BY tcp.rank
+  ) AS parents
+FROM topology_cachegroup tc
+JOIN cachegroup c ON c.name = tc.cachegroup
+JOIN type t ON t.id = c.type
+WHERE t.name = $2
+AND tc.topology IN (
+  SELECT ds.topology
+  FROM deliveryservice ds
+  WHERE ds.cdn_id = (SELECT id FROM cdn WHERE name = $1)
+)
+ORDER BY tc.topology, tc.cachegroup
+`
+	rows, err := tx.Query(q, cdn, tc.CacheGroupEdgeTypeName)
+	if err != nil {
+		return nil, errors.New("querying topology edge cachegroups: " + err.Error())
+	}
+	defer rows.Close()
+
+	nodes := map[string][]topologyEdgeNode{}
+	for rows.Next() {
+		topology := ""
+		node := topologyEdgeNode{}
+		if err := rows.Scan(&topology, &node.Cachegroup, pq.Array(&node.Parents)); err != nil {
+			return nil, errors.New("scanning topology edge cachegroup: " + err.Error())
+		}
+		nodes[topology] = append(nodes[topology], node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("topology edge cachegroup rows: " + err.Error())
+	}
+	return nodes, nil
+}
+
+// makeTopologyFallbacks returns a map[cachegroupName][]fallbackCacheGroupName
+// of the edge cachegroups of a single topology, which fall back along the
+// topology: first to the cachegroups with the same primary parent, then to
+// those whose primary parent is its secondary parent or whose secondary
+// parent is its primary parent, then to those with the same secondary parent.
+// Cachegroups related the same way are ordered by distance.
+//
+// Only the cachegroups in edgeLocs, i.e. with caches in the CDN, are
+// included, and cachegroups with no fallbacks are omitted.
+func makeTopologyFallbacks(nodes []topologyEdgeNode, edgeLocs map[string]tc.CRConfigLatitudeLongitude) map[string][]string {
+	parent := func(node topologyEdgeNode, rank int) string {
+		if len(node.Parents) <= rank {
+			return ""
+		}
+		return node.Parents[rank]
+	}
+	relation := func(node topologyEdgeNode, other topologyEdgeNode) int {
+		primary, secondary := parent(node, 0), parent(node, 1)
+		otherPrimary, otherSecondary := parent(other, 0), parent(other, 1)
+		switch {
+		case primary != "" && otherPrimary == primary:
+			return 0
+		case secondary != "" && otherPrimary == secondary:
+			return 1
+		case primary != "" && otherSecondary == primary:
+			return 1
+		case secondary != "" && otherSecondary == secondary:
+			return 2
+		}
+		return -1
+	}
+
+	fallbacks := map[string][]string{}
+	for _, node := range nodes {
+		latlon, ok := edgeLocs[node.Cachegroup]
+		if !ok {
+			continue
+		}
+		relations := map[string]int{}
+		for _, other := range nodes {
+			if other.Cachegroup == node.Cachegroup {
+				continue
+			}
+			if _, ok := edgeLocs[other.Cachegroup]; !ok {
+				continue
+			}
+			if rel := relation(node, other); rel >= 0 {
+				relations[other.Cachegroup] = rel
+			}
+		}
+		if len(relations) == 0 {
+			continue
+		}
+		cachegroups := make([]string, 0, len(relations))
+		for cachegroup := range relations {
+			cachegroups = append(cachegroups, cachegroup)
+		}
+		sort.Slice(cachegroups, func(i, j int) bool {
+			a, b := cachegroups[i], cachegroups[j]
+			if relations[a] != relations[b] {
+				return relations[a] < relations[b]
+			}
+			distA := distance(latlon, edgeLocs[a])
+			distB := distance(latlon, edgeLocs[b])
+			if distA != distB {
+				return distA < distB
+			}
+			return a < b
+		})
+		fallbacks[node.Cachegroup] = cachegroups
+	}
+	return fallbacks
+}
+
+// distance returns the great-circle distance in kilometers between two
+// locations, computed with the haversine formula the way Traffic Router does.
+func distance(a tc.CRConfigLatitudeLongitude, b tc.CRConfigLatitudeLongitude) float64 {
+	const earthRadiusKM = 6371.0
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRadians(a.Lat - b.Lat)
+	dLon := toRadians(a.Lon - b.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRadians(a.Lat))*math.Cos(toRadians(b.Lat))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKM * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
@@ -71,6 +71,9 @@ func MockMakeLocations(mock sqlmock.Sqlmock, expectedEdgeLocs map[string]tc.CRCo
 	}
 
 	mock.ExpectQuery("SELECT").WithArgs(cdn).WillReturnRows(rows)
+
+	topologyRows := sqlmock.NewRows([]string{"topology", "cachegroup", "parents"})
+	mock.ExpectQuery("SELECT").WithArgs(cdn, tc.CacheGroupEdgeTypeName).WillReturnRows(topologyRows)
 }
 
 func TestMakeLocations(t *testing.T) {
@@ -106,3 +109,38 @@ func TestMakeLocations(t *testing.T) {
 		t.Errorf("makeLocations expected: %+v, actual: %+v", expectedRouterLocs, actualRouterLocs)
 	}
 }
+
+func TestMakeTopologyFallbacks(t *testing.T) {
+	edgeLocs := map[string]tc.CRConfigLatitudeLongitude{
+		"edge0": {Lat: 0, Lon: 0},
+		"edge1": {Lat: 0, Lon: 2},
+		"edge2": {Lat: 0, Lon: 1},
+		"edge3": {Lat: 0, Lon: 3},
+		"edge4": {Lat: 0, Lon: 4},
+		"edge5": {Lat: 0, Lon: 5},
+		"edge6": {Lat: 0, Lon: 6},
+	}
+	nodes := []topologyEdgeNode{
+		{Cachegroup: "edge0", Parents: []string{"mid0", "mid1"}},
+		{Cachegroup: "edge1", Parents: []string{"mid0"}},
+		{Cachegroup: "edge2", Parents: []string{"mid0", "mid2"}},
+		{Cachegroup: "edge3", Parents: []string{"mid1", "mid0"}},
+		{Cachegroup: "edge4", Parents: []string{"mid2", "mid1"}},
+		{Cachegroup: "edge5", Parents: []string{"mid3"}},
+		{Cachegroup: "edge6", Parents: []string{}},
+		// has no caches in the CDN
+		{Cachegroup: "edge7", Parents: []string{"mid0"}},
+	}
+
+	expected := map[string][]string{
+		"edge0": {"edge2", "edge1", "edge3", "edge4"},
+		"edge1": {"edge2", "edge0", "edge3"},
+		"edge2": {"edge0", "edge1", "edge3", "edge4"},
+		"edge3": {"edge1", "edge4", "edge2", "edge0"},
+		"edge4": {"edge3", "edge2", "edge0"},
+	}
+	actual := makeTopologyFallbacks(nodes, edgeLocs)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("makeTopologyFallbacks expected: %+v, actual: %+v", expected, actual)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/maintenance"
 )
 
 const RouterTypeName = "CCR"
@@ -128,7 +129,7 @@ func getAllServers(cdn string, tx *sql.Tx) (map[string]ServerUnion, error) {
 		s.tcp_port,
 		p.name AS profile_name,
 		cast(p.routing_disabled AS int),
-		st.name AS status,
+		` + maintenance.EffectiveStatusSQL("s", "st.name") + ` AS status,
 		t.name AS type,
 		(SELECT ARRAY_AGG(server_capability ORDER BY server_capability)
 			FROM server_server_capability
@@ -219,6 +219,20 @@ func AddTenancyCheck(where string, queryValues map[string]interface{}, tenantCol
 	return where, queryValues
 }
 
+// AddOptionalTenancyCheck is AddTenancyCheck for objects whose tenancy is optional: objects without a tenant (i.e.
+// whose tenantColumnName is NULL) are shared, and are not filtered out.
+func AddOptionalTenancyCheck(where string, queryValues map[string]interface{}, tenantColumnName string, tenantIDs []int) (string, map[string]interface{}) {
+	check := "(" + tenantColumnName + " IS NULL OR " + tenantColumnName + " = ANY(CAST(:accessibleTenants AS bigint[])))"
+	if where == "" {
+		where = BaseWhere + " " + check
+	} else {
+		where += " AND " + check
+	}
+	queryValues["accessibleTenants"] = pq.Array(tenantIDs)
+
+	return where, queryValues
+}
+
 // CommitIf commits if doCommit is true at the time of execution.
 // This is designed as a defer helper.
 //
@@ -20,15 +20,20 @@ package consistenthash
  */
 
 import (
-	"database/sql"
 	"encoding/json"
 	"errors"
-	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
-	"io/ioutil"
+	"fmt"
 	"net/http"
-	"net/url"
-	"strconv"
-	"time"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-consistenthash"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 )
 
 // struct for the response object from Traffic Router
@@ -42,7 +47,10 @@ type TRConsistentHashResult struct {
 type TRConsistentHashRequest struct {
 	ConsistentHashRegex string `json:"regex"`
 	RequestPath         string `json:"requestPath"`
-	CdnID               int64  `json:"cdnId"`
+	// CdnID is the CDN whose Traffic Routers the regex is tested for. The
+	// result is computed by Traffic Ops rather than a Traffic Router of the
+	// CDN, so it's only checked to exist, if given.
+	CdnID int64 `json:"cdnId"`
 }
 
 // endpoint to test Traffic Router's Pattern-Based Consistent Hashing feature
@@ -61,72 +69,223 @@ func Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	responseFromTR, err := getPatternBasedConsistentHash(inf.Tx.Tx, req.ConsistentHashRegex, req.RequestPath, req.CdnID)
+	if req.CdnID != 0 {
+		if _, ok, err := dbhelpers.GetCDNNameFromID(inf.Tx.Tx, req.CdnID); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting CDN name from ID: "+err.Error()))
+			return
+		} else if !ok {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, fmt.Errorf("no CDN with id %d", req.CdnID), nil)
+			return
+		}
+	}
+
+	regex, err := compileRegex(req.ConsistentHashRegex)
 	if err != nil {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting pattern based consistent hash from Traffic Router: "+err.Error()))
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
 		return
 	}
 
-	consistentHashResult := TRConsistentHashResult{}
-	json.Unmarshal(responseFromTR, &consistentHashResult)
+	api.WriteResp(w, r, TRConsistentHashResult{
+		ResultingPathToConsistentHash: consistenthash.PatternBasedHashString(regex, req.RequestPath),
+		ConsistentHashRegex:           req.ConsistentHashRegex,
+		RequestPath:                   req.RequestPath,
+	})
+}
 
-	api.WriteResp(w, r, consistentHashResult)
+// compileRegex compiles a consistent hash regex, returning nil for an empty
+// regex, which Traffic Router treats as selecting the whole request path.
+// Regexes using Java syntax that Go doesn't evaluate the same way Traffic
+// Router does are rejected; see consistenthash.CompileRegex.
+func compileRegex(regex string) (*regexp.Regexp, error) {
+	if regex == "" {
+		return nil, nil
+	}
+	compiled, err := consistenthash.CompileRegex(regex)
+	if err != nil {
+		return nil, errors.New("invalid or unsupported consistent hash regex '" + regex + "': " + err.Error())
+	}
+	return compiled, nil
 }
 
-const RouterRequestTimeout = time.Second * 10
+// GetDeliveryServiceHash is the handler for GET requests to
+// /deliveryservices/{id}/consistenthash, which reports the caches of each
+// cachegroup a request path of the Delivery Service consistently hashes to,
+// according to the current snapshot of its CDN.
+func GetDeliveryServiceHash(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id", "requestPath"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
 
-// queries database for active Traffic Router on the CDN specified by cdnId
-// passes regex and requestPath to the Traffic Router via API request,
-// and returns the response
-func getPatternBasedConsistentHash(tx *sql.Tx, regex string, requestPath string, cdnId int64) ([]byte, error) {
-	q := `
-SELECT concat(server.host_name, '.', server.domain_name) AS fqdn,
-   parameter.value AS apiport
-  FROM (((server
-    JOIN profile ON ((profile.id = server.profile)))
-    JOIN profile_parameter ON ((profile_parameter.profile = profile.id)))
-    JOIN parameter ON ((parameter.id = profile_parameter.parameter)))
-    JOIN status ON ((server.status = status.id))
- WHERE ((server.type = (select id from type where name = 'CCR')) AND
- (parameter.name = 'api.port'::text) AND
- (status.name = 'ONLINE') AND
- (server.cdn_id = $1))
-ORDER BY RANDOM()
-LIMIT 1
-`
+	if inf.Params["requestPath"] == "" {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("requestPath must not be empty"), nil)
+		return
+	}
 
-	trafficRouter := ""
-	apiPort := ""
-	if err := tx.QueryRow(q, cdnId).Scan(&trafficRouter, &apiPort); err != nil {
-		return nil, errors.New("querying for eligible Traffic Router to test pattern based consistent hashing: " + err.Error())
+	dsID := inf.IntParams["id"]
+	userErr, sysErr, errCode = tenant.CheckID(inf.Tx.Tx, inf.User, dsID)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
 	}
 
-	if trafficRouter == "" {
-		return nil, errors.New("no eligible Traffic Router found for pattern based consistent hashing with cdn Id: " + strconv.FormatInt(cdnId, 10))
+	ds, cdn, ok, err := dbhelpers.GetDSNameAndCDNFromID(inf.Tx.Tx, dsID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service name from ID: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, nil, nil)
+		return
+	}
+
+	snapshot, ok, err := crconfig.GetSnapshot(inf.Tx.Tx, string(cdn))
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot: "+err.Error()))
+		return
 	}
-	if apiPort == "" {
-		return nil, errors.New("no parameter 'api.port' found for pattern based consistent hashing with cdn Id: " + strconv.FormatInt(cdnId, 10))
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("delivery service "+string(ds)+" CDN "+string(cdn)+" not found"))
+		return
+	}
+	crc := tc.CRConfig{}
+	if err := json.Unmarshal([]byte(snapshot), &crc); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("unmarshalling snapshot: "+err.Error()))
+		return
 	}
 
-	trafficRouterAPI := "http://" + trafficRouter + ":" + apiPort + "/crs/consistenthash/patternbased/regex?regex=" + url.QueryEscape(regex) + "&requestPath=" + url.QueryEscape(requestPath)
+	hash, userErr := getDeliveryServiceHash(crc, string(ds), inf.Params["requestPath"], inf.Params["cachegroup"])
+	if userErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, userErr, nil)
+		return
+	}
+	api.WriteResp(w, r, hash)
+}
 
-	trClient := &http.Client{
-		Timeout: RouterRequestTimeout,
+// getDeliveryServiceHash consistently hashes the request path, which may
+// include a query string, of the Delivery Service ds the way Traffic Router
+// does with the given snapshot. If cachegroup is not empty, only that
+// cachegroup is reported.
+//
+// Only the snapshot is consulted, so every cache it deems available is
+// given its full share of requests: health from Traffic Monitor, including
+// the reduced weight of caches slowly starting after becoming available, is
+// not reflected.
+//
+// The returned error, if any, is suitable to be returned to the user.
+func getDeliveryServiceHash(crc tc.CRConfig, ds string, requestPath string, cachegroup string) (tc.DeliveryServiceConsistentHash, error) {
+	dsCfg, ok := crc.DeliveryServices[ds]
+	if !ok {
+		return tc.DeliveryServiceConsistentHash{}, errors.New("delivery service " + ds + " is not in the current snapshot of its CDN")
 	}
-	r, err := trClient.Get(trafficRouterAPI)
 
+	regexStr := ""
+	if dsCfg.ConsistentHashRegex != nil {
+		regexStr = *dsCfg.ConsistentHashRegex
+	}
+	regex, err := compileRegex(regexStr)
 	if err != nil {
-		return nil, errors.New("Error creating request to Traffic Router: " + err.Error())
+		return tc.DeliveryServiceConsistentHash{}, err
 	}
-	defer r.Body.Close()
 
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return nil, errors.New("failed to read body: " + err.Error())
+	path, query := requestPath, ""
+	if i := strings.Index(requestPath, "?"); i >= 0 {
+		path, query = requestPath[:i], requestPath[i+1:]
+	}
+	hashString := consistenthash.HashString(regex, dsCfg.ConsistentHashQueryParams, path, query)
+
+	dispersion := tc.CRConfigDispersion{Limit: 1}
+	if dsCfg.Dispersion != nil {
+		dispersion = *dsCfg.Dispersion
+	}
+
+	hashables := map[string][]consistenthash.Hashable{}
+	for hostName, server := range crc.ContentServers {
+		if !ServesDeliveryService(crc, ds, dsCfg, server) {
+			continue
+		}
+		if cachegroup != "" && *server.CacheGroup != cachegroup {
+			continue
+		}
+		hashID := hostName
+		if server.HashId != nil {
+			hashID = *server.HashId
+		}
+		hashCount := 0
+		if server.HashCount != nil {
+			hashCount = *server.HashCount
+		}
+		hashables[*server.CacheGroup] = append(hashables[*server.CacheGroup], consistenthash.NewHashable(hostName, hashID, hashCount))
+	}
+
+	cachegroupNames := make([]string, 0, len(hashables))
+	for name := range hashables {
+		cachegroupNames = append(cachegroupNames, name)
 	}
+	sort.Strings(cachegroupNames)
 
-	if r.StatusCode != 200 {
-		return nil, errors.New("Traffic Router returned " + strconv.Itoa(r.StatusCode))
+	cachegroups := make([]tc.DeliveryServiceConsistentHashCachegroup, 0, len(cachegroupNames))
+	for _, name := range cachegroupNames {
+		cg := tc.DeliveryServiceConsistentHashCachegroup{Name: name, Caches: []tc.DeliveryServiceConsistentHashCache{}}
+		for _, h := range consistenthash.Select(hashables[name], hashString) {
+			server := crc.ContentServers[h.ID]
+			cache := tc.DeliveryServiceConsistentHashCache{HostName: h.ID, Status: string(*server.ServerStatus)}
+			if server.Fqdn != nil {
+				cache.FQDN = *server.Fqdn
+			}
+			cg.Caches = append(cg.Caches, cache)
+		}
+		cachegroups = append(cachegroups, cg)
+	}
+
+	return tc.DeliveryServiceConsistentHash{
+		DeliveryService:               ds,
+		RequestPath:                   requestPath,
+		ResultingPathToConsistentHash: hashString,
+		Dispersion:                    dispersion,
+		Cachegroups:                   cachegroups,
+	}, nil
+}
+
+// ServesDeliveryService returns whether the snapshot server is an available
+// edge cache that Traffic Router would route clients of the Delivery Service
+// ds to.
+func ServesDeliveryService(crc tc.CRConfig, ds string, dsCfg tc.CRConfigDeliveryService, server tc.CRConfigTrafficOpsServer) bool {
+	if server.CacheGroup == nil || server.ServerStatus == nil || server.ServerType == nil {
+		return false
+	}
+	if !strings.HasPrefix(*server.ServerType, tc.EdgeTypePrefix) {
+		return false
+	}
+	if status := tc.CacheStatus(*server.ServerStatus); status != tc.CacheStatusOnline && status != tc.CacheStatusReported {
+		return false
+	}
+
+	if dsCfg.Topology == nil {
+		_, ok := server.DeliveryServices[ds]
+		return ok
+	}
+
+	inTopology := false
+	for _, node := range crc.Topologies[*dsCfg.Topology].Nodes {
+		if node == *server.CacheGroup {
+			inTopology = true
+			break
+		}
+	}
+	if !inTopology {
+		return false
+	}
+	capabilities := make(map[string]struct{}, len(server.Capabilities))
+	for _, capability := range server.Capabilities {
+		capabilities[capability] = struct{}{}
+	}
+	for _, required := range dsCfg.RequiredCapabilities {
+		if _, ok := capabilities[required]; !ok {
+			return false
+		}
 	}
-	return body, nil
+	return true
 }
@@ -0,0 +1,128 @@
+package consistenthash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func testServer(cachegroup string, status tc.CacheStatus, ds string) tc.CRConfigTrafficOpsServer {
+	st := tc.CRConfigServerStatus(status)
+	server := tc.CRConfigTrafficOpsServer{
+		CacheGroup:   util.StrPtr(cachegroup),
+		ServerStatus: &st,
+		ServerType:   util.StrPtr(tc.EdgeTypePrefix),
+		HashCount:    util.IntPtr(100),
+	}
+	if ds != "" {
+		server.DeliveryServices = map[string][]string{ds: {ds + ".example.net"}}
+	}
+	return server
+}
+
+func TestGetDeliveryServiceHash(t *testing.T) {
+	crc := tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge1": testServer("cg1", tc.CacheStatusReported, "ds1"),
+			"edge2": testServer("cg1", tc.CacheStatusOnline, "ds1"),
+			"edge3": testServer("cg1", tc.CacheStatusAdminDown, "ds1"),
+			"edge4": testServer("cg2", tc.CacheStatusReported, "ds1"),
+			"edge5": testServer("cg2", tc.CacheStatusReported, ""),
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"ds1": {
+				ConsistentHashRegex:       util.StrPtr(`/.*?(/.*?/).*?(m3u8)`),
+				ConsistentHashQueryParams: []string{"format"},
+				Dispersion:                &tc.CRConfigDispersion{Limit: 2},
+			},
+		},
+	}
+
+	hash, err := getDeliveryServiceHash(crc, "ds1", "/test/path/asset.m3u8?format=ts&foo=bar", "")
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if hash.ResultingPathToConsistentHash != "/path/m3u8format=ts" {
+		t.Errorf("expected resulting path '/path/m3u8format=ts', actual '%s'", hash.ResultingPathToConsistentHash)
+	}
+	if hash.Dispersion.Limit != 2 {
+		t.Errorf("expected dispersion limit 2, actual %d", hash.Dispersion.Limit)
+	}
+	if len(hash.Cachegroups) != 2 || hash.Cachegroups[0].Name != "cg1" || hash.Cachegroups[1].Name != "cg2" {
+		t.Fatalf("expected cachegroups cg1 and cg2, actual %+v", hash.Cachegroups)
+	}
+	if len(hash.Cachegroups[0].Caches) != 2 {
+		t.Errorf("expected the 2 available caches of cg1, actual %+v", hash.Cachegroups[0].Caches)
+	}
+	if len(hash.Cachegroups[1].Caches) != 1 || hash.Cachegroups[1].Caches[0].HostName != "edge4" {
+		t.Errorf("expected only edge4 in cg2, actual %+v", hash.Cachegroups[1].Caches)
+	}
+
+	hash, err = getDeliveryServiceHash(crc, "ds1", "/test/path/asset.m3u8", "cg2")
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if len(hash.Cachegroups) != 1 || hash.Cachegroups[0].Name != "cg2" {
+		t.Errorf("expected only cachegroup cg2, actual %+v", hash.Cachegroups)
+	}
+
+	if _, err := getDeliveryServiceHash(crc, "ds2", "/test", ""); err == nil {
+		t.Error("expected an error for a delivery service not in the snapshot")
+	}
+}
+
+func TestGetDeliveryServiceHashTopology(t *testing.T) {
+	crc := tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge1": testServer("cg1", tc.CacheStatusReported, ""),
+			"edge2": testServer("cg1", tc.CacheStatusReported, ""),
+			"edge3": testServer("cg2", tc.CacheStatusReported, ""),
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"ds1": {
+				Topology:             util.StrPtr("top1"),
+				RequiredCapabilities: []string{"disk"},
+			},
+		},
+		Topologies: map[string]tc.CRConfigTopology{
+			"top1": {Nodes: []string{"cg1"}},
+		},
+	}
+	edge1 := crc.ContentServers["edge1"]
+	edge1.Capabilities = []string{"disk"}
+	crc.ContentServers["edge1"] = edge1
+
+	hash, err := getDeliveryServiceHash(crc, "ds1", "/asset", "")
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if hash.ResultingPathToConsistentHash != "/asset" {
+		t.Errorf("expected resulting path '/asset', actual '%s'", hash.ResultingPathToConsistentHash)
+	}
+	if hash.Dispersion.Limit != 1 {
+		t.Errorf("expected default dispersion limit 1, actual %d", hash.Dispersion.Limit)
+	}
+	if len(hash.Cachegroups) != 1 || len(hash.Cachegroups[0].Caches) != 1 || hash.Cachegroups[0].Caches[0].HostName != "edge1" {
+		t.Errorf("expected only edge1, the cache of the topology with the required capability, actual %+v", hash.Cachegroups)
+	}
+}
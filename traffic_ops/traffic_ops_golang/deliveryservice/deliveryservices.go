@@ -261,6 +261,16 @@ func createV30(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 		return nil, http.StatusForbidden, errors.New("not authorized on this tenant"), nil
 	}
 
+	if ds.Topology != nil && ds.CDNID != nil {
+		if userErr, sysErr := validateTopologyCapabilities(tx, *ds.Topology, *ds.CDNID, nil); userErr != nil || sysErr != nil {
+			code := http.StatusBadRequest
+			if sysErr != nil {
+				code = http.StatusInternalServerError
+			}
+			return nil, code, userErr, sysErr
+		}
+	}
+
 	// TODO change DeepCachingType to implement sql.Valuer and sql.Scanner, so sqlx struct scan can be used.
 	deepCachingType := tc.DeepCachingType("").String()
 	if ds.DeepCachingType != nil {
@@ -756,6 +766,20 @@ func updateV30(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		return nil, http.StatusBadRequest, errors.New("missing id"), nil
 	}
 
+	if ds.Topology != nil && ds.CDNID != nil {
+		requiredCapabilities := []string{}
+		if err := tx.QueryRow(selectDSRequiredCapabilitiesQuery, *ds.ID).Scan(pq.Array(&requiredCapabilities)); err != nil {
+			return nil, http.StatusInternalServerError, nil, errors.New("getting delivery service required capabilities: " + err.Error())
+		}
+		if userErr, sysErr := validateTopologyCapabilities(tx, *ds.Topology, *ds.CDNID, requiredCapabilities); userErr != nil || sysErr != nil {
+			code := http.StatusBadRequest
+			if sysErr != nil {
+				code = http.StatusInternalServerError
+			}
+			return nil, code, userErr, sysErr
+		}
+	}
+
 	dsType, ok, err := getDSType(tx, *ds.XMLID)
 	if !ok {
 		return nil, http.StatusNotFound, errors.New("delivery service '" + *ds.XMLID + "' not found"), nil
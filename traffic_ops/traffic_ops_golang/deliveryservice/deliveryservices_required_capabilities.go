@@ -287,6 +287,12 @@ func (rc *RequiredCapability) Create() (error, error, int) {
 		return usrErr, sysErr, rCode
 	}
 
+	if usrErr, sysErr := validateDSTopologyCapabilities(rc.APIInfo().Tx.Tx, *rc.DeliveryServiceID, rc.RequiredCapability); usrErr != nil {
+		return fmt.Errorf("capability %v cannot be made required on the delivery service %v: %v", *rc.RequiredCapability, *rc.DeliveryServiceID, usrErr), nil, http.StatusBadRequest
+	} else if sysErr != nil {
+		return nil, sysErr, http.StatusInternalServerError
+	}
+
 	rows, err := rc.APIInfo().Tx.NamedQuery(rcInsertQuery(), rc)
 	if err != nil {
 		return api.ParseDBError(err)
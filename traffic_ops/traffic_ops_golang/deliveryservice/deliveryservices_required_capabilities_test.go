@@ -90,6 +90,9 @@ func TestCreateDeliveryServicesRequiredCapability(t *testing.T) {
 	arrayRows := sqlmock.NewRows([]string{"array"})
 	mock.ExpectQuery("SELECT ds.server FROM deliveryservice_server").WillReturnRows(arrayRows)
 
+	topologyRows := sqlmock.NewRows([]string{"xml_id", "topology", "cdn_id", "name", "array"}).AddRow("ds1", nil, 1, "cdn1", "{}")
+	mock.ExpectQuery("SELECT ds.xml_id, ds.topology").WithArgs(1).WillReturnRows(topologyRows)
+
 	rows := sqlmock.NewRows([]string{"required_capability", "deliveryservice_id", "last_updated"}).AddRow(
 		util.StrPtr("mem"),
 		util.IntPtr(1),
@@ -0,0 +1,342 @@
+package deliveryservice
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/lib/pq"
+)
+
+// topologyNode is a node of a Topology, with the names of its parents.
+type topologyNode struct {
+	Cachegroup string
+	Type       string
+	Parents    []string
+}
+
+// topologyServer is a server of a Topology's cachegroup, with its
+// capabilities.
+type topologyServer struct {
+	tc.DeliveryServiceTopologyServer
+	Cachegroup   string
+	Capabilities map[string]struct{}
+}
+
+const selectTopologyNodesQuery = `
+SELECT
+  tcg.cachegroup,
+  t.name,
+  ARRAY(
+    SELECT p.cachegroup
+    FROM topology_cachegroup_parents tcp
+    JOIN topology_cachegroup p ON p.id = tcp.parent
+    WHERE tcp.child = tcg.id
+    ORDER BY tcp.rank
+  )
+FROM topology_cachegroup tcg
+JOIN cachegroup cg ON cg.name = tcg.cachegroup
+JOIN type t ON t.id = cg.type
+WHERE tcg.topology = $1
+ORDER BY tcg.cachegroup
+`
+
+const selectTopologyServersQuery = `
+SELECT
+  s.id,
+  s.host_name,
+  t.name,
+  st.name,
+  cg.name,
+  ARRAY(
+    SELECT ssc.server_capability
+    FROM server_server_capability ssc
+    WHERE ssc.server = s.id
+  )
+FROM server s
+JOIN cachegroup cg ON cg.id = s.cachegroup
+JOIN type t ON t.id = s.type
+JOIN status st ON st.id = s.status
+WHERE cg.name IN (SELECT cachegroup FROM topology_cachegroup WHERE topology = $1)
+AND s.cdn_id = $2
+AND (t.name LIKE '` + tc.EdgeTypePrefix + `%' OR t.name LIKE '` + tc.MidTypePrefix + `%' OR t.name = '` + tc.OriginTypeName + `')
+ORDER BY s.host_name
+`
+
+const selectDSTopologyQuery = `
+SELECT
+  ds.xml_id,
+  ds.topology,
+  ds.cdn_id,
+  cdn.name,
+  ARRAY(
+    SELECT rc.required_capability
+    FROM deliveryservices_required_capability rc
+    WHERE rc.deliveryservice_id = ds.id
+    ORDER BY rc.required_capability
+  )
+FROM deliveryservice ds
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE ds.id = $1
+`
+
+const selectDSRequiredCapabilitiesQuery = `
+SELECT ARRAY(
+  SELECT required_capability
+  FROM deliveryservices_required_capability
+  WHERE deliveryservice_id = $1
+  ORDER BY required_capability
+)
+`
+
+// GetTopologyServers is the handler for GET requests to
+// /deliveryservices/{id}/topology/servers, which expands the Topology of a
+// Delivery Service into the cachegroups of each of its tiers, and the servers
+// eligible to serve the Delivery Service.
+func GetTopologyServers(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	dsID := inf.IntParams["id"]
+	dsTenantID, ok, err := getDSTenantIDByID(inf.Tx.Tx, dsID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking tenant: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("delivery service "+inf.Params["id"]+" not found"), nil)
+		return
+	}
+	if authorized, err := tenant.IsResourceAuthorizedToUserTx(*dsTenantID, inf.User, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking tenant: "+err.Error()))
+		return
+	} else if !authorized {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("not authorized on this tenant"), nil)
+		return
+	}
+
+	result := tc.DeliveryServiceTopologyServers{}
+	topology := sql.NullString{}
+	cdnID := 0
+	if err := inf.Tx.Tx.QueryRow(selectDSTopologyQuery, dsID).Scan(&result.DeliveryService, &topology, &cdnID, &result.CDNName, pq.Array(&result.RequiredCapabilities)); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service topology: "+err.Error()))
+		return
+	}
+	if !topology.Valid {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("delivery service "+result.DeliveryService+" has no topology"), nil)
+		return
+	}
+	result.Topology = topology.String
+
+	nodes, servers, err := getTopologyNodesAndServers(inf.Tx.Tx, result.Topology, cdnID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting topology servers: "+err.Error()))
+		return
+	}
+	result.Tiers = buildTopologyTiers(nodes, servers, result.RequiredCapabilities)
+	api.WriteResp(w, r, result)
+}
+
+// validateTopologyCapabilities checks that every cache tier of the named
+// Topology has at least one cache server on the CDN with the given ID which
+// has all of the given capabilities. The statuses of servers aren't
+// considered, so that taking servers out of service doesn't prevent the
+// Delivery Service from being changed.
+//
+// It returns an error suitable for the user if a tier has no such server,
+// and a system error if the Topology couldn't be read.
+func validateTopologyCapabilities(tx *sql.Tx, topology string, cdnID int, requiredCapabilities []string) (error, error) {
+	nodes, servers, err := getTopologyNodesAndServers(tx, topology, cdnID)
+	if err != nil {
+		return nil, errors.New("getting topology servers: " + err.Error())
+	}
+	for i := range servers {
+		servers[i].Status = tc.CacheStatusOnline.String()
+	}
+	capabilityMsg := ""
+	if len(requiredCapabilities) > 0 {
+		capabilityMsg = " with the required capabilities " + strings.Join(requiredCapabilities, ", ")
+	}
+	emptyTiers := []string{}
+	for _, tier := range buildTopologyTiers(nodes, servers, requiredCapabilities) {
+		if tier.Type != tc.CacheGroupOriginTypeName && tier.EligibleServers == 0 {
+			emptyTiers = append(emptyTiers, tier.Type)
+		}
+	}
+	if len(emptyTiers) > 0 {
+		return errors.New("topology " + topology + " has no servers in the delivery service's CDN" + capabilityMsg + " in its " + strings.Join(emptyTiers, ", ") + " tier(s)"), nil
+	}
+	return nil, nil
+}
+
+// validateDSTopologyCapabilities checks that the Topology of the Delivery
+// Service with the given ID can serve it with the given additional required
+// capability, if any, as validateTopologyCapabilities. Delivery Services
+// without a Topology are always valid.
+func validateDSTopologyCapabilities(tx *sql.Tx, dsID int, additionalCapability *string) (error, error) {
+	xmlID := ""
+	topology := sql.NullString{}
+	cdnID := 0
+	cdnName := ""
+	capabilities := []string{}
+	if err := tx.QueryRow(selectDSTopologyQuery, dsID).Scan(&xmlID, &topology, &cdnID, &cdnName, pq.Array(&capabilities)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.New("getting delivery service topology: " + err.Error())
+	}
+	if !topology.Valid {
+		return nil, nil
+	}
+	if additionalCapability != nil {
+		capabilities = append(capabilities, *additionalCapability)
+	}
+	return validateTopologyCapabilities(tx, topology.String, cdnID, capabilities)
+}
+
+// getTopologyNodesAndServers returns the nodes of the named Topology, and
+// the cache and origin servers of its cachegroups on the CDN with the given
+// ID.
+func getTopologyNodesAndServers(tx *sql.Tx, topology string, cdnID int) ([]topologyNode, []topologyServer, error) {
+	rows, err := tx.Query(selectTopologyNodesQuery, topology)
+	if err != nil {
+		return nil, nil, errors.New("querying topology nodes: " + err.Error())
+	}
+	defer rows.Close()
+	nodes := []topologyNode{}
+	for rows.Next() {
+		node := topologyNode{}
+		if err := rows.Scan(&node.Cachegroup, &node.Type, pq.Array(&node.Parents)); err != nil {
+			return nil, nil, errors.New("scanning topology nodes: " + err.Error())
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.New("iterating topology nodes: " + err.Error())
+	}
+
+	serverRows, err := tx.Query(selectTopologyServersQuery, topology, cdnID)
+	if err != nil {
+		return nil, nil, errors.New("querying topology servers: " + err.Error())
+	}
+	defer serverRows.Close()
+	servers := []topologyServer{}
+	for serverRows.Next() {
+		server := topologyServer{}
+		capabilities := []string{}
+		if err := serverRows.Scan(&server.ID, &server.HostName, &server.Type, &server.Status, &server.Cachegroup, pq.Array(&capabilities)); err != nil {
+			return nil, nil, errors.New("scanning topology servers: " + err.Error())
+		}
+		server.Capabilities = make(map[string]struct{}, len(capabilities))
+		for _, capability := range capabilities {
+			server.Capabilities[capability] = struct{}{}
+		}
+		servers = append(servers, server)
+	}
+	if err := serverRows.Err(); err != nil {
+		return nil, nil, errors.New("iterating topology servers: " + err.Error())
+	}
+	return nodes, servers, nil
+}
+
+// buildTopologyTiers groups the nodes of a Topology into tiers by the types of
+// their cachegroups, edges first, and determines which of their servers are
+// eligible to serve a Delivery Service with the given required capabilities.
+// Only cache servers are considered in cache cachegroups, and only origin
+// servers in origin cachegroups.
+func buildTopologyTiers(nodes []topologyNode, servers []topologyServer, requiredCapabilities []string) []tc.DeliveryServiceTopologyTier {
+	cgServers := map[string][]topologyServer{}
+	for _, server := range servers {
+		cgServers[server.Cachegroup] = append(cgServers[server.Cachegroup], server)
+	}
+
+	tierIndex := map[string]int{}
+	tiers := []tc.DeliveryServiceTopologyTier{}
+	for _, node := range nodes {
+		i, ok := tierIndex[node.Type]
+		if !ok {
+			i = len(tiers)
+			tierIndex[node.Type] = i
+			tiers = append(tiers, tc.DeliveryServiceTopologyTier{Type: node.Type, Cachegroups: []tc.DeliveryServiceTopologyCachegroup{}})
+		}
+		isOriginTier := node.Type == tc.CacheGroupOriginTypeName
+
+		cg := tc.DeliveryServiceTopologyCachegroup{
+			Name:    node.Cachegroup,
+			Parents: node.Parents,
+			Servers: []tc.DeliveryServiceTopologyServer{},
+		}
+		if cg.Parents == nil {
+			cg.Parents = []string{}
+		}
+		for _, server := range cgServers[node.Cachegroup] {
+			isOrigin := server.Type == tc.OriginTypeName
+			if isOrigin != isOriginTier {
+				continue
+			}
+			result := server.DeliveryServiceTopologyServer
+			result.MissingCapabilities = []string{}
+			if !isOrigin {
+				for _, capability := range requiredCapabilities {
+					if _, ok := server.Capabilities[capability]; !ok {
+						result.MissingCapabilities = append(result.MissingCapabilities, capability)
+					}
+				}
+			}
+			available := result.Status == tc.CacheStatusOnline.String() || result.Status == tc.CacheStatusReported.String()
+			result.Eligible = available && len(result.MissingCapabilities) == 0
+			if result.Eligible {
+				tiers[i].EligibleServers++
+			}
+			cg.Servers = append(cg.Servers, result)
+		}
+		tiers[i].Cachegroups = append(tiers[i].Cachegroups, cg)
+	}
+
+	sort.SliceStable(tiers, func(a, b int) bool { return topologyTierRank(tiers[a].Type) < topologyTierRank(tiers[b].Type) })
+	return tiers
+}
+
+// topologyTierRank returns the order of the tier of cachegroups of the given
+// type: edges, then mids, then origins, then any other types.
+func topologyTierRank(cgType string) int {
+	switch cgType {
+	case tc.CacheGroupEdgeTypeName:
+		return 0
+	case tc.CacheGroupMidTypeName:
+		return 1
+	case tc.CacheGroupOriginTypeName:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,89 @@
+package deliveryservice
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func makeTopologyServer(id int, hostName string, serverType string, status string, cachegroup string, capabilities ...string) topologyServer {
+	server := topologyServer{
+		DeliveryServiceTopologyServer: tc.DeliveryServiceTopologyServer{ID: id, HostName: hostName, Type: serverType, Status: status},
+		Cachegroup:                    cachegroup,
+		Capabilities:                  map[string]struct{}{},
+	}
+	for _, capability := range capabilities {
+		server.Capabilities[capability] = struct{}{}
+	}
+	return server
+}
+
+func TestBuildTopologyTiers(t *testing.T) {
+	nodes := []topologyNode{
+		{Cachegroup: "edge1", Type: tc.CacheGroupEdgeTypeName, Parents: []string{"mid1", "mid2"}},
+		{Cachegroup: "mid1", Type: tc.CacheGroupMidTypeName, Parents: []string{"org1"}},
+		{Cachegroup: "mid2", Type: tc.CacheGroupMidTypeName},
+		{Cachegroup: "org1", Type: tc.CacheGroupOriginTypeName},
+	}
+	servers := []topologyServer{
+		makeTopologyServer(1, "edge-a", "EDGE", "REPORTED", "edge1", "mem", "disk"),
+		makeTopologyServer(2, "edge-b", "EDGE", "OFFLINE", "edge1", "mem", "disk"),
+		makeTopologyServer(3, "edge-c", "EDGE", "ONLINE", "edge1", "mem"),
+		makeTopologyServer(4, "mid-a", "MID", "REPORTED", "mid1"),
+		makeTopologyServer(5, "mid-b", "MID", "REPORTED", "mid2", "disk"),
+		makeTopologyServer(6, "origin-a", "ORG", "ONLINE", "org1"),
+		makeTopologyServer(7, "cache-in-org", "MID", "ONLINE", "org1", "mem", "disk"),
+	}
+
+	tiers := buildTopologyTiers(nodes, servers, []string{"disk", "mem"})
+
+	if len(tiers) != 3 {
+		t.Fatalf("expected 3 tiers, actual: %+v", tiers)
+	}
+	if tiers[0].Type != tc.CacheGroupEdgeTypeName || tiers[1].Type != tc.CacheGroupMidTypeName || tiers[2].Type != tc.CacheGroupOriginTypeName {
+		t.Errorf("expected tiers ordered edge, mid, origin; actual: %s, %s, %s", tiers[0].Type, tiers[1].Type, tiers[2].Type)
+	}
+
+	edge := tiers[0]
+	if edge.EligibleServers != 1 {
+		t.Errorf("expected 1 eligible edge server, actual: %d", edge.EligibleServers)
+	}
+	if !reflect.DeepEqual(edge.Cachegroups[0].Parents, []string{"mid1", "mid2"}) {
+		t.Errorf("expected edge parents [mid1 mid2], actual: %v", edge.Cachegroups[0].Parents)
+	}
+	edgeServers := edge.Cachegroups[0].Servers
+	if len(edgeServers) != 3 || !edgeServers[0].Eligible || edgeServers[1].Eligible || edgeServers[2].Eligible {
+		t.Errorf("expected only edge-a to be eligible, actual: %+v", edgeServers)
+	}
+	if !reflect.DeepEqual(edgeServers[2].MissingCapabilities, []string{"disk"}) {
+		t.Errorf("expected edge-c to be missing capability disk, actual: %v", edgeServers[2].MissingCapabilities)
+	}
+
+	if tiers[1].EligibleServers != 0 || len(tiers[1].Cachegroups) != 2 {
+		t.Errorf("expected a mid tier of 2 cachegroups with no eligible servers, actual: %+v", tiers[1])
+	}
+	if parents := tiers[1].Cachegroups[1].Parents; parents == nil || len(parents) != 0 {
+		t.Errorf("expected empty, non-nil parents of mid2, actual: %v", parents)
+	}
+
+	originServers := tiers[2].Cachegroups[0].Servers
+	if tiers[2].EligibleServers != 1 || len(originServers) != 1 || originServers[0].HostName != "origin-a" {
+		t.Errorf("expected only the origin server in the origin tier, without capability requirements, actual: %+v", tiers[2])
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federations"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/util/ims"
 
 	"github.com/jmoiron/sqlx"
@@ -88,6 +89,9 @@ func Create(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, tx, http.StatusBadRequest, userErr, nil)
 		return
 	}
+	if ip, err := tc.NormalizeFederationResolver(*fr.IPAddress); err == nil {
+		fr.IPAddress = &ip
+	}
 
 	err := tx.QueryRow(insertFederationResolverQuery, fr.IPAddress, fr.TypeID).Scan(&fr.ID, &fr.IPAddress, &fr.Type, &fr.TypeID)
 	if err != nil {
@@ -270,6 +274,11 @@ func deleteFederationResolver(inf *api.APIInfo) (tc.Alert, tc.FederationResolver
 	var alert tc.Alert
 	var result tc.FederationResolver
 
+	// the resolver's federation mappings are deleted along with it, so their removal is recorded first
+	if err := federations.RecordResolverDeletion(inf.Tx.Tx, inf.IntParams["id"], inf.User.UserName); err != nil {
+		return alert, result, nil, err, http.StatusInternalServerError
+	}
+
 	err := inf.Tx.Tx.QueryRow(deleteQuery, inf.IntParams["id"]).Scan(&result.ID, &result.IPAddress, &result.Type)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -20,6 +20,7 @@ package federations
  */
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -27,11 +28,26 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	"github.com/lib/pq"
 )
 
 const deleteFederationFederationResolversQuery = `
 DELETE FROM federation_federation_resolver ffr
 WHERE ffr.federation = $1
+AND ffr.federation_resolver = ANY($2::bigint[])
+`
+
+const selectFederationResolverIDsQuery = `
+SELECT ffr.federation_resolver
+FROM federation_federation_resolver ffr
+WHERE ffr.federation = $1
+`
+
+const selectResolverIPAddressesQuery = `
+SELECT fr.ip_address
+FROM federation_resolver fr
+WHERE fr.id = ANY($1::bigint[])
 `
 
 // GetFederationFederationResolversHandler returns a subset of federation_resolvers belonging to the federation ID supplied.
@@ -80,18 +96,44 @@ func AssignFederationResolversToFederationHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	requested := make([]int64, 0, len(reqObj.FedResolverIDs))
+	for _, id := range reqObj.FedResolverIDs {
+		requested = append(requested, int64(id))
+	}
+	ips, err := getResolverIPAddresses(inf.Tx.Tx, requested)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("database exception: %v", err))
+		return
+	}
+	if userErr, sysErr := checkResolverOverlaps(inf.Tx.Tx, fedID, ips); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusConflict, userErr, sysErr)
+		return
+	}
+
 	if reqObj.Replace {
-		if _, err := inf.Tx.Tx.Exec(deleteFederationFederationResolversQuery, fedID); err != nil {
+		if err := removeUnrequestedResolvers(inf.Tx.Tx, fedID, requested, inf.User.UserName); err != nil {
 			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("database exception: %v", err))
 			return
 		}
 	}
 
-	for _, id := range reqObj.FedResolverIDs {
-		if _, err := inf.Tx.Tx.Exec(associateFederationWithResolverQuery, fedID, id); err != nil {
+	added := []int64{}
+	for _, id := range requested {
+		result, err := inf.Tx.Tx.Exec(associateFederationWithResolverQuery, fedID, id)
+		if err != nil {
 			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("database exception: %v", err))
 			return
 		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("database exception: %v", err))
+			return
+		} else if rowsAffected > 0 {
+			added = append(added, id)
+		}
+	}
+	if err := recordResolverHistory(inf.Tx.Tx, fedID, added, tc.FederationResolverHistoryAdded, nil, inf.User.UserName); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+		return
 	}
 
 	api.CreateChangeLogRawTx(
@@ -107,3 +149,59 @@ func AssignFederationResolversToFederationHandler(w http.ResponseWriter, r *http
 		tc.AssignFederationFederationResolversResponse{Response: reqObj},
 	)
 }
+
+// getResolverIPAddresses returns the IP addresses of the federation resolvers with the given IDs.
+func getResolverIPAddresses(tx *sql.Tx, ids []int64) ([]string, error) {
+	rows, err := tx.Query(selectResolverIPAddressesQuery, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ips := []string{}
+	for rows.Next() {
+		var ip string
+		if err := rows.Scan(&ip); err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip)
+	}
+	return ips, rows.Err()
+}
+
+// removeUnrequestedResolvers removes the federation resolvers not in requested from the federation, recording their
+// removal in its history.
+func removeUnrequestedResolvers(tx *sql.Tx, fedID int, requested []int64, userName string) error {
+	rows, err := tx.Query(selectFederationResolverIDsQuery, fedID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	keep := make(map[int64]struct{}, len(requested))
+	for _, id := range requested {
+		keep[id] = struct{}{}
+	}
+	removed := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		if _, ok := keep[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := recordResolverHistory(tx, fedID, removed, tc.FederationResolverHistoryRemoved, nil, userName); err != nil {
+		return err
+	}
+	_, err = tx.Exec(deleteFederationFederationResolversQuery, fedID, pq.Array(removed))
+	return err
+}
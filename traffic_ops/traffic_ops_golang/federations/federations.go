@@ -27,6 +27,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,15 +43,25 @@ import (
 	"github.com/lib/pq"
 )
 
+// insertResolverQuery creates a federation resolver, returning its IP address
+// and ID - or those of the existing resolver, if there already is one with
+// the same IP address.
 const insertResolverQuery = `
-INSERT INTO federation_resolver (ip_address, type)
-VALUES ($1, (
-	SELECT type.id
-	FROM type
-	WHERE type.name = $2
-))
-ON CONFLICT DO NOTHING
-RETURNING federation_resolver.ip_address, federation_resolver.id
+WITH inserted AS (
+	INSERT INTO federation_resolver (ip_address, type)
+	VALUES ($1, (
+		SELECT type.id
+		FROM type
+		WHERE type.name = $2
+	))
+	ON CONFLICT DO NOTHING
+	RETURNING federation_resolver.ip_address, federation_resolver.id
+)
+SELECT inserted.ip_address, inserted.id FROM inserted
+UNION ALL
+SELECT federation_resolver.ip_address, federation_resolver.id
+FROM federation_resolver
+WHERE federation_resolver.ip_address = $1
 `
 
 const associateFederationWithResolverQuery = `
@@ -350,6 +361,7 @@ func AddFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *http.
 		api.HandleErr(w, r, tx, errCode, userErr, nil)
 		return
 	}
+	normalizeMappings(mappings)
 
 	userErr, sysErr, errCode = addFederationResolverMappingsForCurrentUser(inf.User, tx, mappings)
 	if userErr != nil || sysErr != nil {
@@ -365,6 +377,15 @@ func AddFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *http.
 	}
 }
 
+// normalizeMappings rewrites the resolvers of each mapping in their canonical
+// form, so that equivalent addresses and networks are stored - and compared -
+// identically.
+func normalizeMappings(mappings []tc.DeliveryServiceFederationResolverMapping) {
+	for i := range mappings {
+		mappings[i].Mappings.Normalize()
+	}
+}
+
 // handles the main logic of the POST handler, separated out for convenience
 func addFederationResolverMappingsForCurrentUser(u *auth.CurrentUser, tx *sql.Tx, mappings []tc.DeliveryServiceFederationResolverMapping) (error, error, int) {
 	for _, fed := range mappings {
@@ -392,7 +413,12 @@ func addFederationResolverMappingsForCurrentUser(u *auth.CurrentUser, tx *sql.Tx
 			return err, nil, http.StatusConflict
 		}
 
-		inserted, err := addFederationResolverMappingsToFederation(fed.Mappings, fed.DeliveryService, fedID, tx)
+		resolvers := append(append([]string{}, fed.Mappings.Resolve4...), fed.Mappings.Resolve6...)
+		if userErr, sysErr := checkResolverOverlaps(tx, int(fedID), resolvers); userErr != nil || sysErr != nil {
+			return userErr, sysErr, http.StatusConflict
+		}
+
+		inserted, err := addFederationResolverMappingsToFederation(fed.Mappings, fed.DeliveryService, fedID, u.UserName, tx)
 		if err != nil {
 			err = fmt.Errorf("Adding federation resolver mapping(s) to federation: %v", err)
 			return nil, err, http.StatusInternalServerError
@@ -406,45 +432,59 @@ func addFederationResolverMappingsForCurrentUser(u *auth.CurrentUser, tx *sql.Tx
 }
 
 // adds federation resolver mappings for a particular delivery service to a given federation, creating said resolvers if
-// they don't already exist.
-func addFederationResolverMappingsToFederation(res tc.ResolverMapping, xmlid string, fed uint, tx *sql.Tx) (string, error) {
+// they don't already exist, and records the resolvers newly assigned to the federation in its history.
+func addFederationResolverMappingsToFederation(res tc.ResolverMapping, xmlid string, fed uint, userName string, tx *sql.Tx) (string, error) {
 	var resp string
+	ids := []int64{}
 	if len(res.Resolve4) > 0 {
-		inserted, err := addFederationResolver(res.Resolve4, tc.FederationResolverType4, fed, tx)
+		inserted, insertedIDs, err := addFederationResolver(res.Resolve4, tc.FederationResolverType4, fed, tx)
 		if err != nil {
 			return "", err
 		}
 		resp = strings.Join(inserted, ", ")
+		ids = append(ids, insertedIDs...)
 	}
 	if len(res.Resolve6) > 0 {
-		inserted, err := addFederationResolver(res.Resolve6, tc.FederationResolverType6, fed, tx)
+		inserted, insertedIDs, err := addFederationResolver(res.Resolve6, tc.FederationResolverType6, fed, tx)
 		if err != nil {
 			return "", err
 		}
 		resp += strings.Join(inserted, ", ")
+		ids = append(ids, insertedIDs...)
+	}
+	if err := recordResolverHistory(tx, int(fed), ids, tc.FederationResolverHistoryAdded, nil, userName); err != nil {
+		return "", err
 	}
 	return resp, nil
 }
 
-// adds federation resolvers of a specific type to the given federation
-func addFederationResolver(res []string, t tc.FederationResolverType, fedID uint, tx *sql.Tx) ([]string, error) {
+// adds federation resolvers of a specific type to the given federation, returning the IP addresses and IDs of those
+// that weren't already assigned to it
+func addFederationResolver(res []string, t tc.FederationResolverType, fedID uint, tx *sql.Tx) ([]string, []int64, error) {
 	inserted := []string{}
+	ids := []int64{}
 	for _, r := range res {
 		var ip string
-		var id uint
+		var id int64
 		if err := tx.QueryRow(insertResolverQuery, r, t).Scan(&ip, &id); err != nil && err != sql.ErrNoRows {
-			return nil, err
+			return nil, nil, err
 		}
 		if ip != "" && id > 0 {
-			inserted = append(inserted, ip)
-			if _, err := tx.Exec(associateFederationWithResolverQuery, fedID, id); err != nil {
-				return nil, err
+			result, err := tx.Exec(associateFederationWithResolverQuery, fedID, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if rowsAffected, err := result.RowsAffected(); err != nil {
+				return nil, nil, err
+			} else if rowsAffected > 0 {
+				inserted = append(inserted, ip)
+				ids = append(ids, id)
 			}
 		}
 
 	}
 
-	return inserted, nil
+	return inserted, ids, nil
 }
 
 // RemoveFederationResolverMappingsForCurrentUser is the handler for a DELETE request to /federations
@@ -487,6 +527,10 @@ func RemoveFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *ht
 
 // handles the main logic of the DELETE handler, separated out for convenience
 func removeFederationResolverMappingsForCurrentUser(tx *sql.Tx, u *auth.CurrentUser) ([]string, error, error, int) {
+	if _, err := tx.Exec(insertCurrentUserResolverHistoryQuery, u.ID, u.UserName); err != nil {
+		return nil, nil, fmt.Errorf("Recording removal of federation resolvers for user %s: %v", u.UserName, err), http.StatusInternalServerError
+	}
+
 	rows, err := tx.Query(deleteCurrentUserFederationResolversQuery, u.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -508,6 +552,9 @@ func removeFederationResolverMappingsForCurrentUser(tx *sql.Tx, u *auth.CurrentU
 	return ips, nil, nil, http.StatusOK
 }
 
+// ReplaceFederationResolverMappingsForCurrentUser is the handler for a PUT request to /federations, which replaces
+// all federation resolvers of the federations assigned to the authenticated user with those in the request. With
+// the dryRun query parameter (API version 3 and later), the replacement is previewed instead of made.
 func ReplaceFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *http.Request) {
 	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
 	tx := inf.Tx.Tx
@@ -517,6 +564,24 @@ func ReplaceFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *h
 	}
 	defer inf.Close()
 
+	dryRun := false
+	if dryRunParam, ok := inf.Params["dryRun"]; ok && inf.Version.Major >= 3 {
+		var err error
+		if dryRun, err = strconv.ParseBool(dryRunParam); err != nil {
+			api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("dryRun must be a boolean"), nil)
+			return
+		}
+	}
+
+	var before []tc.IAllFederation
+	if dryRun {
+		var err error
+		if before, err = getUserFederationMappings(tx, inf.User.UserName); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+	}
+
 	ips, userErr, sysErr, errCode := removeFederationResolverMappingsForCurrentUser(tx, inf.User)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
@@ -540,6 +605,7 @@ func ReplaceFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *h
 		api.HandleErr(w, r, tx, errCode, userErr, nil)
 		return
 	}
+	normalizeMappings(mappings)
 
 	userErr, sysErr, errCode = addFederationResolverMappingsForCurrentUser(inf.User, tx, mappings)
 	if userErr != nil || sysErr != nil {
@@ -547,6 +613,21 @@ func ReplaceFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *h
 		return
 	}
 
+	if dryRun {
+		preview, err := previewFederationMappings(tx, inf.User, before)
+		if err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+		// the replacement was only made to compute its result, so it must not be kept
+		if err := tx.Rollback(); err != nil {
+			api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("rolling back dry run: %v", err))
+			return
+		}
+		api.WriteRespAlertObj(w, r, tc.InfoLevel, "Dry run - no federation resolvers were changed.", preview)
+		return
+	}
+
 	createdMsg := fmt.Sprintf("%s successfully created federation resolvers.", inf.User.UserName)
 	if inf.Version.Major <= 1 && inf.Version.Minor <= 3 {
 		api.WriteResp(w, r, createdMsg)
@@ -585,6 +666,63 @@ func ReplaceFederationResolverMappingsForCurrentUser(w http.ResponseWriter, r *h
 	w.Write(append(respBts, '\n'))
 }
 
+// getUserFederationMappings returns the federation resolver mappings of the federations assigned to the named user.
+func getUserFederationMappings(tx *sql.Tx, userName string) ([]tc.IAllFederation, error) {
+	feds, err, _, _ := getUserFederations(tx, userName, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting user federations: %v", err)
+	}
+	fedsResolvers, err, _, _ := getFederationResolvers(tx, fedInfoIDs(feds), false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting user federations resolvers: %v", err)
+	}
+	return addResolvers([]tc.IAllFederation{}, feds, fedsResolvers), nil
+}
+
+// previewFederationMappings returns the difference between the given federation resolver mappings of the user and
+// their current ones, along with the federations/all data of the user's Delivery Services. Only administrators can
+// see the resolvers of other users' federations in that data.
+func previewFederationMappings(tx *sql.Tx, u *auth.CurrentUser, before []tc.IAllFederation) (tc.FederationResolverMappingsPreview, error) {
+	after, err := getUserFederationMappings(tx, u.UserName)
+	if err != nil {
+		return tc.FederationResolverMappingsPreview{}, err
+	}
+	added, removed := diffFederationMappings(before, after)
+	preview := tc.FederationResolverMappingsPreview{Added: added, Removed: removed, Result: []tc.AllDeliveryServiceFederationsMapping{}}
+
+	result := after
+	if u.PrivLevel >= auth.PrivLevelAdmin {
+		dses := map[tc.DeliveryServiceName]struct{}{}
+		for _, fed := range append(append([]tc.IAllFederation{}, before...), after...) {
+			if dsFeds, ok := fed.(tc.AllDeliveryServiceFederationsMapping); ok {
+				dses[dsFeds.DeliveryService] = struct{}{}
+			}
+		}
+		allFeds, err, _, _ := getAllFederations(tx, false, nil)
+		if err != nil {
+			return tc.FederationResolverMappingsPreview{}, fmt.Errorf("getting all federations: %v", err)
+		}
+		feds := []FedInfo{}
+		for _, fed := range allFeds {
+			if _, ok := dses[fed.DS]; ok {
+				feds = append(feds, fed)
+			}
+		}
+		fedsResolvers, err, _, _ := getFederationResolvers(tx, fedInfoIDs(feds), false, nil)
+		if err != nil {
+			return tc.FederationResolverMappingsPreview{}, fmt.Errorf("getting all federations resolvers: %v", err)
+		}
+		result = addResolvers([]tc.IAllFederation{}, feds, fedsResolvers)
+	}
+	for _, fed := range result {
+		if dsFeds, ok := fed.(tc.AllDeliveryServiceFederationsMapping); ok {
+			preview.Result = append(preview.Result, dsFeds)
+		}
+	}
+	sortFederationMappings(preview.Result)
+	return preview, nil
+}
+
 // retrieves mappings from the given request body using the rules of the given api Version
 func getMappingsFromRequestBody(v api.Version, body io.ReadCloser) (tc.DeliveryServiceFederationResolverMappingRequest, error, error) {
 	defer body.Close()
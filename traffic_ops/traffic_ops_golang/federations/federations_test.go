@@ -36,6 +36,67 @@ func TestAddFederationResolverMappingsForCurrentUser(t *testing.T) {
 	t.Run("add Federation Resolver Mappings for the current user", positiveTestAddFederationResolverMappingsForCurrentUser)
 	t.Run("add Federation Resolver Mappings for the current user when no federations exist/are assigned to them", testAddFederationResolverMappingsForCurrentUserWithoutFederations)
 	t.Run("add Federation Resolver Mappings for a DS unauthorized to the current user's tenant", testUnauthorizedDSOnResolverAdd)
+	t.Run("add Federation Resolver Mappings overlapping those of another federation of the DS", testOverlappingResolverAdd)
+}
+
+func testOverlappingResolverAdd(t *testing.T) {
+	u := auth.CurrentUser{
+		UserName:  "test",
+		ID:        1,
+		PrivLevel: 100,
+		TenantID:  1,
+		Role:      1,
+	}
+
+	mappings := []tc.DeliveryServiceFederationResolverMapping{
+		tc.DeliveryServiceFederationResolverMapping{
+			DeliveryService: "test",
+			Mappings: tc.ResolverMapping{
+				Resolve4: []string{"192.0.2.0/24"},
+			},
+		},
+	}
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	dsTenantIDRows := sqlmock.NewRows([]string{"tenant_id"})
+	dsTenantIDRows.AddRow(1)
+	authorizedRows := sqlmock.NewRows([]string{"id", "active"})
+	authorizedRows.AddRow(1, true)
+	fedIDRows := sqlmock.NewRows([]string{"federation"})
+	fedIDRows.AddRow(1)
+	overlapRows := sqlmock.NewRows([]string{"ip_address", "cname", "xml_id"})
+	overlapRows.AddRow("198.51.100.0/24", "other.", "test")
+	overlapRows.AddRow("192.0.2.128/25", "other.", "test")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT deliveryservice.tenant_id").WillReturnRows(dsTenantIDRows)
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(authorizedRows)
+	mock.ExpectQuery("SELECT federation_deliveryservice.federation").WillReturnRows(fedIDRows)
+	mock.ExpectQuery("SELECT DISTINCT fr.ip_address").WillReturnRows(overlapRows)
+	mock.ExpectRollback()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a mock transaction", err)
+	}
+
+	userErr, sysErr, errCode := addFederationResolverMappingsForCurrentUser(&u, tx, mappings)
+	if sysErr != nil {
+		t.Errorf("Unexpected system error: %v", sysErr)
+	}
+	if userErr == nil {
+		t.Error("Expected a user error adding a resolver overlapping another federation's resolver, got none")
+	} else if !strings.Contains(userErr.Error(), "192.0.2.128/25") {
+		t.Errorf("Expected the user error to name the overlapping resolver, got: %v", userErr)
+	}
+	if errCode != http.StatusConflict {
+		t.Errorf("Expected response code %d, got %d", http.StatusConflict, errCode)
+	}
 }
 
 func positiveTestAddFederationResolverMappingsForCurrentUser(t *testing.T) {
@@ -82,6 +143,7 @@ func positiveTestAddFederationResolverMappingsForCurrentUser(t *testing.T) {
 	mock.ExpectQuery("SELECT deliveryservice.tenant_id").WillReturnRows(dsTenantIDRows)
 	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(authorizedRows)
 	mock.ExpectQuery("SELECT federation_deliveryservice.federation").WillReturnRows(fedIDRows)
+	mock.ExpectQuery("SELECT DISTINCT fr.ip_address").WillReturnRows(sqlmock.NewRows([]string{"ip_address", "cname", "xml_id"}))
 	mock.ExpectQuery("INSERT INTO federation_resolver").WillReturnRows(insertFirstResolverRows)
 	mock.ExpectExec("INSERT INTO federation_federation_resolver").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectQuery("INSERT INTO federation_resolver").WillReturnRows(insertSecondResolverRows)
@@ -90,6 +152,7 @@ func positiveTestAddFederationResolverMappingsForCurrentUser(t *testing.T) {
 	mock.ExpectExec("INSERT INTO federation_federation_resolver").WillReturnResult(sqlmock.NewResult(3, 1))
 	mock.ExpectQuery("INSERT INTO federation_resolver").WillReturnRows(insertFourthResolverRows)
 	mock.ExpectExec("INSERT INTO federation_federation_resolver").WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("INSERT INTO federation_resolver_history").WillReturnResult(sqlmock.NewResult(4, 4))
 	mock.ExpectCommit()
 
 	tx, err := mockDB.Begin()
@@ -305,6 +368,7 @@ func TestRemoveFederationResolverMappingsForCurrentUser(t *testing.T) {
 	rows.AddRow(ips[3])
 
 	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO federation_resolver_history").WillReturnResult(sqlmock.NewResult(4, 4))
 	mock.ExpectQuery("DELETE").WillReturnRows(rows)
 	mock.ExpectCommit()
 
@@ -334,3 +398,69 @@ func TestRemoveFederationResolverMappingsForCurrentUser(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordResolverDeletion(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO federation_resolver_history").WithArgs(3, "test").WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a mock transaction", err)
+	}
+
+	if err := RecordResolverDeletion(tx, 3, "test"); err != nil {
+		t.Errorf("Unexpected error recording the deletion of a resolver: %v", err)
+	}
+	tx.Commit()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expected the removal of each mapping of the resolver to be recorded: %v", err)
+	}
+}
+
+func TestDiffFederationMappings(t *testing.T) {
+	ttl := 60
+	cname := "fed.example.com."
+	before := []tc.IAllFederation{
+		tc.AllDeliveryServiceFederationsMapping{
+			DeliveryService: "ds1",
+			Mappings: []tc.FederationResolverMapping{
+				{TTL: &ttl, CName: &cname, ResolverMapping: tc.ResolverMapping{Resolve4: []string{"192.0.2.0/24", "198.51.100.1"}, Resolve6: []string{"2001:db8::/32"}}},
+			},
+		},
+	}
+	after := []tc.IAllFederation{
+		tc.AllDeliveryServiceFederationsMapping{
+			DeliveryService: "ds1",
+			Mappings: []tc.FederationResolverMapping{
+				{TTL: &ttl, CName: &cname, ResolverMapping: tc.ResolverMapping{Resolve4: []string{"192.0.2.0/24", "203.0.113.0/24"}, Resolve6: []string{"2001:db8::/32"}}},
+			},
+		},
+	}
+
+	added, removed := diffFederationMappings(before, after)
+	if len(added) != 1 || len(added[0].Mappings) != 1 {
+		t.Fatalf("Expected one added mapping, got: %+v", added)
+	}
+	if added[0].DeliveryService != "ds1" || len(added[0].Mappings[0].Resolve4) != 1 || added[0].Mappings[0].Resolve4[0] != "203.0.113.0/24" || len(added[0].Mappings[0].Resolve6) != 0 {
+		t.Errorf("Expected 203.0.113.0/24 to be added to ds1, got: %+v", added[0].Mappings[0])
+	}
+	if len(removed) != 1 || len(removed[0].Mappings) != 1 {
+		t.Fatalf("Expected one removed mapping, got: %+v", removed)
+	}
+	if removed[0].DeliveryService != "ds1" || len(removed[0].Mappings[0].Resolve4) != 1 || removed[0].Mappings[0].Resolve4[0] != "198.51.100.1" || len(removed[0].Mappings[0].Resolve6) != 0 {
+		t.Errorf("Expected 198.51.100.1 to be removed from ds1, got: %+v", removed[0].Mappings[0])
+	}
+
+	added, removed = diffFederationMappings(before, before)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("Expected no differences between identical mappings, got added: %+v, removed: %+v", added, removed)
+	}
+}
@@ -0,0 +1,288 @@
+package federations
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	"github.com/lib/pq"
+)
+
+// insertResolverHistoryQuery records a change to the resolvers of federation
+// $1 whose IDs are in $2, or all of its resolvers if $2 is NULL.
+const insertResolverHistoryQuery = `
+INSERT INTO federation_resolver_history (federation, ip_address, type, action, cname, ttl, previous_ttl, username)
+SELECT f.id, fr.ip_address, frt.name, $3, f.cname, f.ttl, $4, $5
+FROM federation_federation_resolver ffr
+JOIN federation f ON f.id = ffr.federation
+JOIN federation_resolver fr ON fr.id = ffr.federation_resolver
+JOIN type frt ON frt.id = fr.type
+WHERE ffr.federation = $1
+AND ($2::bigint[] IS NULL OR ffr.federation_resolver = ANY($2::bigint[]))
+`
+
+// insertCurrentUserResolverHistoryQuery records the removal of every
+// federation mapping of the resolvers deleted by
+// deleteCurrentUserFederationResolversQuery, including those of federations
+// not assigned to the user which share the same resolvers.
+const insertCurrentUserResolverHistoryQuery = `
+INSERT INTO federation_resolver_history (federation, ip_address, type, action, cname, ttl, username)
+SELECT f.id, fr.ip_address, frt.name, 'removed', f.cname, f.ttl, $2
+FROM federation_federation_resolver ffr
+JOIN federation f ON f.id = ffr.federation
+JOIN federation_resolver fr ON fr.id = ffr.federation_resolver
+JOIN type frt ON frt.id = fr.type
+WHERE ffr.federation_resolver IN (
+	SELECT user_ffr.federation_resolver
+	FROM federation_federation_resolver user_ffr
+	JOIN federation_tmuser fu ON fu.federation = user_ffr.federation
+	WHERE fu.tm_user = $1
+)
+`
+
+// insertDeletedResolverHistoryQuery records the removal of every federation
+// mapping of resolver $1, which are deleted along with it.
+const insertDeletedResolverHistoryQuery = `
+INSERT INTO federation_resolver_history (federation, ip_address, type, action, cname, ttl, username)
+SELECT f.id, fr.ip_address, frt.name, 'removed', f.cname, f.ttl, $2
+FROM federation_federation_resolver ffr
+JOIN federation f ON f.id = ffr.federation
+JOIN federation_resolver fr ON fr.id = ffr.federation_resolver
+JOIN type frt ON frt.id = fr.type
+WHERE ffr.federation_resolver = $1
+`
+
+// selectOverlapCandidatesQuery selects the resolvers of every other federation
+// that shares a Delivery Service with federation $1.
+const selectOverlapCandidatesQuery = `
+SELECT DISTINCT fr.ip_address, f.cname, ds.xml_id
+FROM federation_deliveryservice fds
+JOIN federation_deliveryservice other_fds ON other_fds.deliveryservice = fds.deliveryservice AND other_fds.federation <> fds.federation
+JOIN federation f ON f.id = other_fds.federation
+JOIN federation_federation_resolver ffr ON ffr.federation = other_fds.federation
+JOIN federation_resolver fr ON fr.id = ffr.federation_resolver
+JOIN deliveryservice ds ON ds.id = fds.deliveryservice
+WHERE fds.federation = $1
+ORDER BY ds.xml_id, f.cname, fr.ip_address
+`
+
+const selectResolverHistoryQuery = `
+SELECT
+  h.id,
+  h.federation,
+  h.ip_address,
+  h.type,
+  h.action,
+  h.cname,
+  h.ttl,
+  h.previous_ttl,
+  h.username,
+  h.timestamp
+FROM federation_resolver_history h
+WHERE h.federation = $1
+ORDER BY h.timestamp DESC, h.id DESC
+`
+
+// recordResolverHistory records a change of the given action made by the
+// named user to the resolvers of the federation with the given IDs. A nil
+// slice of IDs records the change for every resolver of the federation.
+func recordResolverHistory(tx *sql.Tx, fedID int, resolverIDs []int64, action tc.FederationResolverHistoryAction, previousTTL *int, userName string) error {
+	if resolverIDs != nil && len(resolverIDs) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(insertResolverHistoryQuery, fedID, pq.Array(resolverIDs), action, previousTTL, userName); err != nil {
+		return fmt.Errorf("recording federation resolver history: %v", err)
+	}
+	return nil
+}
+
+// RecordTTLChange records in the history of each resolver of the federation
+// that the named user changed the TTL of the federation from previousTTL.
+func RecordTTLChange(tx *sql.Tx, fedID int, previousTTL int, userName string) error {
+	return recordResolverHistory(tx, fedID, nil, tc.FederationResolverHistoryTTL, &previousTTL, userName)
+}
+
+// RecordResolverDeletion records in the history of each federation the
+// resolver is mapped to that the named user removed it. It must be called
+// before the resolver is deleted, since its mappings are deleted with it.
+func RecordResolverDeletion(tx *sql.Tx, resolverID int, userName string) error {
+	if _, err := tx.Exec(insertDeletedResolverHistoryQuery, resolverID, userName); err != nil {
+		return fmt.Errorf("recording removal of federation resolver %d: %v", resolverID, err)
+	}
+	return nil
+}
+
+// checkResolverOverlaps returns a user error if any of the given resolvers
+// overlaps one of another federation serving the same Delivery Service, since
+// Traffic Router couldn't tell which federation a client of the overlapping
+// networks belongs to.
+func checkResolverOverlaps(tx *sql.Tx, fedID int, resolvers []string) (error, error) {
+	if len(resolvers) == 0 {
+		return nil, nil
+	}
+	rows, err := tx.Query(selectOverlapCandidatesQuery, fedID)
+	if err != nil {
+		return nil, fmt.Errorf("querying resolvers of federations sharing delivery services with federation %d: %v", fedID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ip, cname, xmlID string
+		if err := rows.Scan(&ip, &cname, &xmlID); err != nil {
+			return nil, fmt.Errorf("scanning resolvers of federations sharing delivery services with federation %d: %v", fedID, err)
+		}
+		for _, res := range resolvers {
+			if tc.FederationResolversOverlap(res, ip) {
+				return fmt.Errorf("resolver %s overlaps resolver %s of federation '%s' on Delivery Service '%s'", res, ip, cname, xmlID), nil
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating over resolvers of federations sharing delivery services with federation %d: %v", fedID, err)
+	}
+	return nil, nil
+}
+
+// GetFederationResolverHistoryHandler is the handler for GET requests to
+// /federations/{id}/history, which returns the changes made to the resolver
+// mappings of the federation, most recent first.
+func GetFederationResolverHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	fedID := inf.IntParams["id"]
+	if _, ok, err := dbhelpers.GetFederationNameFromID(fedID, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("database exception: %v", err))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, fmt.Errorf("'%d': no such Federation", fedID), nil)
+		return
+	}
+
+	history, err := getFederationResolverHistory(inf.Tx.Tx, fedID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.WriteResp(w, r, history)
+}
+
+func getFederationResolverHistory(tx *sql.Tx, fedID int) ([]tc.FederationResolverHistory, error) {
+	rows, err := tx.Query(selectResolverHistoryQuery, fedID)
+	if err != nil {
+		return nil, fmt.Errorf("querying federation resolver history: %v", err)
+	}
+	defer rows.Close()
+
+	history := []tc.FederationResolverHistory{}
+	for rows.Next() {
+		h := tc.FederationResolverHistory{}
+		if err := rows.Scan(&h.ID, &h.FederationID, &h.IPAddress, &h.Type, &h.Action, &h.CName, &h.TTL, &h.PreviousTTL, &h.Username, &h.Timestamp); err != nil {
+			return nil, fmt.Errorf("scanning federation resolver history: %v", err)
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// diffFederationMappings returns, per Delivery Service and federation CNAME,
+// the resolvers of after that aren't in before, and those of before that
+// aren't in after. Both are sorted by Delivery Service and then CNAME.
+func diffFederationMappings(before []tc.IAllFederation, after []tc.IAllFederation) ([]tc.AllDeliveryServiceFederationsMapping, []tc.AllDeliveryServiceFederationsMapping) {
+	return subtractFederationMappings(after, before), subtractFederationMappings(before, after)
+}
+
+type dsFederationKey struct {
+	ds    tc.DeliveryServiceName
+	cname string
+}
+
+// subtractFederationMappings returns the resolvers of a that aren't mapped to
+// the same Delivery Service and CNAME in b.
+func subtractFederationMappings(a []tc.IAllFederation, b []tc.IAllFederation) []tc.AllDeliveryServiceFederationsMapping {
+	inB := map[dsFederationKey]map[string]struct{}{}
+	for _, fed := range b {
+		dsFeds, ok := fed.(tc.AllDeliveryServiceFederationsMapping)
+		if !ok {
+			continue
+		}
+		for _, mapping := range dsFeds.Mappings {
+			key := dsFederationKey{ds: dsFeds.DeliveryService, cname: *mapping.CName}
+			if inB[key] == nil {
+				inB[key] = map[string]struct{}{}
+			}
+			for _, res := range append(append([]string{}, mapping.Resolve4...), mapping.Resolve6...) {
+				inB[key][res] = struct{}{}
+			}
+		}
+	}
+
+	diff := []tc.AllDeliveryServiceFederationsMapping{}
+	for _, fed := range a {
+		dsFeds, ok := fed.(tc.AllDeliveryServiceFederationsMapping)
+		if !ok {
+			continue
+		}
+		mappings := []tc.FederationResolverMapping{}
+		for _, mapping := range dsFeds.Mappings {
+			key := dsFederationKey{ds: dsFeds.DeliveryService, cname: *mapping.CName}
+			m := tc.FederationResolverMapping{TTL: mapping.TTL, CName: mapping.CName}
+			for _, res := range mapping.Resolve4 {
+				if _, ok := inB[key][res]; !ok {
+					m.Resolve4 = append(m.Resolve4, res)
+				}
+			}
+			for _, res := range mapping.Resolve6 {
+				if _, ok := inB[key][res]; !ok {
+					m.Resolve6 = append(m.Resolve6, res)
+				}
+			}
+			if len(m.Resolve4) > 0 || len(m.Resolve6) > 0 {
+				mappings = append(mappings, m)
+			}
+		}
+		if len(mappings) > 0 {
+			diff = append(diff, tc.AllDeliveryServiceFederationsMapping{DeliveryService: dsFeds.DeliveryService, Mappings: mappings})
+		}
+	}
+	sortFederationMappings(diff)
+	return diff
+}
+
+// sortFederationMappings sorts federation mappings by Delivery Service, and
+// the mappings of each Delivery Service by CNAME.
+func sortFederationMappings(feds []tc.AllDeliveryServiceFederationsMapping) {
+	sort.Slice(feds, func(i, j int) bool { return feds[i].DeliveryService < feds[j].DeliveryService })
+	for _, fed := range feds {
+		mappings := fed.Mappings
+		sort.Slice(mappings, func(i, j int) bool { return *mappings[i].CName < *mappings[j].CName })
+	}
+}
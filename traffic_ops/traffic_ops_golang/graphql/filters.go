@@ -0,0 +1,166 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// The filter functions return the given objects matching the arguments of a field. Arguments which are absent match
+// everything.
+
+func (l *loader) filterServers(servers []*server, args map[string]interface{}) ([]*server, error) {
+	var cgNames map[string]struct{}
+	if topologyName, ok := args["topology"].(string); ok {
+		names, err := l.topologyCachegroups(topologyName)
+		if err != nil {
+			return nil, err
+		}
+		cgNames = names
+	}
+	_, byCachegroup := args["cachegroup"].(string)
+	if byCachegroup || cgNames != nil {
+		if err := l.loadCachegroups(); err != nil {
+			return nil, err
+		}
+	}
+	_, byProfile := args["profile"].(string)
+	if byProfile {
+		if err := l.loadProfiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	filtered := []*server{}
+	for _, s := range servers {
+		if !intArgMatches(args, "id", s.ID) ||
+			!stringArgMatches(args, "hostName", s.HostName) ||
+			!stringArgMatches(args, "cdn", s.CDNName) ||
+			!stringArgMatches(args, "type", s.Type) ||
+			!stringArgMatches(args, "status", s.Status) {
+			continue
+		}
+		cgName := ""
+		if cg, ok := l.cachegroupsByID[s.CachegroupID]; ok {
+			cgName = cg.Name
+		}
+		if byCachegroup && !stringArgMatches(args, "cachegroup", cgName) {
+			continue
+		}
+		if cgNames != nil {
+			if _, ok := cgNames[cgName]; !ok {
+				continue
+			}
+		}
+		if byProfile {
+			profileName := ""
+			if p, ok := l.profilesByID[s.ProfileID]; ok {
+				profileName = p.Name
+			}
+			if !stringArgMatches(args, "profile", profileName) {
+				continue
+			}
+		}
+		filtered = append(filtered, s)
+	}
+	return filtered, nil
+}
+
+func (l *loader) filterCachegroups(cachegroups []*cachegroup, args map[string]interface{}) ([]*cachegroup, error) {
+	var cgNames map[string]struct{}
+	if topologyName, ok := args["topology"].(string); ok {
+		names, err := l.topologyCachegroups(topologyName)
+		if err != nil {
+			return nil, err
+		}
+		cgNames = names
+	}
+
+	filtered := []*cachegroup{}
+	for _, cg := range cachegroups {
+		if !intArgMatches(args, "id", cg.ID) ||
+			!stringArgMatches(args, "name", cg.Name) ||
+			!stringArgMatches(args, "type", cg.Type) {
+			continue
+		}
+		if cgNames != nil {
+			if _, ok := cgNames[cg.Name]; !ok {
+				continue
+			}
+		}
+		filtered = append(filtered, cg)
+	}
+	return filtered, nil
+}
+
+func filterDeliveryServices(dses []*deliveryService, args map[string]interface{}) []*deliveryService {
+	filtered := []*deliveryService{}
+	for _, ds := range dses {
+		if !intArgMatches(args, "id", ds.ID) ||
+			!stringArgMatches(args, "xmlId", ds.XMLID) ||
+			!stringArgMatches(args, "cdn", ds.CDNName) ||
+			!stringArgMatches(args, "type", ds.Type) {
+			continue
+		}
+		if active, ok := args["active"].(bool); ok && active != ds.Active {
+			continue
+		}
+		if topologyName, ok := args["topology"].(string); ok && (ds.Topology == nil || *ds.Topology != topologyName) {
+			continue
+		}
+		filtered = append(filtered, ds)
+	}
+	return filtered
+}
+
+func filterProfiles(profiles []*profile, args map[string]interface{}) []*profile {
+	filtered := []*profile{}
+	for _, p := range profiles {
+		if intArgMatches(args, "id", p.ID) &&
+			stringArgMatches(args, "name", p.Name) &&
+			stringArgMatches(args, "cdn", p.CDNName) &&
+			stringArgMatches(args, "type", p.Type) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func filterParameters(params []*parameter, args map[string]interface{}) []*parameter {
+	filtered := []*parameter{}
+	for _, p := range params {
+		configFile := ""
+		if p.ConfigFile != nil {
+			configFile = *p.ConfigFile
+		}
+		if intArgMatches(args, "id", p.ID) &&
+			stringArgMatches(args, "name", p.Name) &&
+			stringArgMatches(args, "configFile", configFile) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func intArgMatches(args map[string]interface{}, name string, val int) bool {
+	arg, ok := args[name].(int)
+	return !ok || arg == val
+}
+
+func stringArgMatches(args map[string]interface{}, name string, val string) bool {
+	arg, ok := args[name].(string)
+	return !ok || arg == val
+}
@@ -0,0 +1,93 @@
+// Package graphql implements the read-only Traffic Ops GraphQL API, which serves servers, cachegroups, topologies,
+// delivery services, profiles and parameters, and the relationships between them, in a single query.
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// Handler is the handler for GET and POST /api/{version}/graphql.
+//
+// A POST request's body is a tc.GraphQLRequest. A GET request gives the same as the "query", "operationName" and
+// "variables" query string parameters, the latter being a JSON-encoded object.
+//
+// Errors in the query, or in resolving it, are returned in the "errors" property of a 200 response, per GraphQL
+// convention, rather than as alerts.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	req, err := parseRequest(r)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	tenants, err := tenant.GetUserTenantListTx(*inf.User, inf.Tx.Tx)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting user tenants: "+err.Error()))
+		return
+	}
+
+	result := gql.Do(gql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        context.WithValue(r.Context(), loaderKey, newLoader(inf.Tx.Tx, inf.User, tenants)),
+	})
+	api.WriteRespRaw(w, r, result)
+}
+
+func parseRequest(r *http.Request) (tc.GraphQLRequest, error) {
+	req := tc.GraphQLRequest{}
+	if r.Method == http.MethodGet {
+		params := r.URL.Query()
+		req.Query = params.Get("query")
+		req.OperationName = params.Get("operationName")
+		if variables := params.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return req, errors.New("parsing variables: " + err.Error())
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, errors.New("parsing request: " + err.Error())
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return req, errors.New("query is required")
+	}
+	return req, nil
+}
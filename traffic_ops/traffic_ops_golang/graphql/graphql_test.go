@@ -0,0 +1,264 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	gql "github.com/graphql-go/graphql"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func mockServers(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "host_name", "domain_name", "tcp_port", "https_port", "rack", "offline_reason", "upd_pending", "reval_pending", "xmpp_id", "last_updated", "cdn", "type", "status", "phys_location", "cachegroup", "cdn_id", "profile"})
+	rows.AddRow(1, "edge1", "example.test", 80, 443, nil, nil, false, false, nil, now, "cdn1", "EDGE", "REPORTED", "pl1", 10, 100, 1000)
+	rows.AddRow(2, "edge2", "example.test", 80, 443, nil, nil, false, false, nil, now, "cdn1", "EDGE", "REPORTED", "pl1", 11, 100, 1000)
+	rows.AddRow(3, "mid1", "example.test", 80, 443, nil, nil, false, false, nil, now, "cdn1", "MID", "REPORTED", "pl1", 12, 100, 1001)
+	rows.AddRow(4, "edge3", "example.test", 80, 443, nil, nil, false, false, nil, now, "cdn1", "EDGE", "REPORTED", "pl1", 13, 100, 1000)
+	mock.ExpectQuery("SELECT .* FROM server s").WillReturnRows(rows)
+}
+
+func mockServerCapabilities(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"server", "server_capability"})
+	rows.AddRow(1, "ram")
+	rows.AddRow(3, "ram")
+	mock.ExpectQuery("SELECT .* FROM server_server_capability").WillReturnRows(rows)
+}
+
+func mockCachegroups(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "short_name", "type", "fallback_to_closest", "last_updated", "parent_cachegroup_id", "secondary_parent_cachegroup_id"})
+	rows.AddRow(10, "edgeCG1", "e1", "EDGE_LOC", true, now, 12, nil)
+	rows.AddRow(11, "edgeCG2", "e2", "EDGE_LOC", true, now, 12, nil)
+	rows.AddRow(12, "midCG", "m", "MID_LOC", true, now, nil, nil)
+	rows.AddRow(13, "edgeCG3", "e3", "EDGE_LOC", true, now, nil, nil)
+	mock.ExpectQuery("SELECT .* FROM cachegroup c").WillReturnRows(rows)
+}
+
+func mockTopologies(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"name", "description", "last_updated"})
+	rows.AddRow("top1", "a topology", time.Now())
+	mock.ExpectQuery("SELECT .* FROM topology t").WillReturnRows(rows)
+}
+
+func mockTopologyNodes(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"topology", "cachegroup", "parents"})
+	rows.AddRow("top1", "edgeCG1", "{midCG}")
+	rows.AddRow("top1", "edgeCG2", "{midCG}")
+	rows.AddRow("top1", "midCG", "{}")
+	mock.ExpectQuery("SELECT .* FROM topology_cachegroup tc").WillReturnRows(rows)
+}
+
+func mockDeliveryServices(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "xml_id", "display_name", "active", "type", "cdn", "routing_name", "tenant_id", "tenant", "protocol", "last_updated", "cdn_id", "profile", "topology", "required_capabilities"})
+	rows.AddRow(20, "ds-top", "DS Topology", true, "HTTP", "cdn1", "cdn", 1, "root", 0, now, 100, nil, "top1", "{ram}")
+	rows.AddRow(21, "ds-assigned", "DS Assigned", true, "HTTP", "cdn1", "cdn", 1, "root", 0, now, 100, nil, nil, "{}")
+	mock.ExpectQuery("SELECT .* FROM deliveryservice ds").WillReturnRows(rows)
+}
+
+func mockDeliveryServiceServers(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"deliveryservice", "server"})
+	rows.AddRow(21, 2)
+	rows.AddRow(22, 1) // a delivery service of another tenant
+	mock.ExpectQuery("SELECT .* FROM deliveryservice_server").WillReturnRows(rows)
+}
+
+func mockProfiles(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "type", "cdn", "routing_disabled", "last_updated", "cdn_id"})
+	rows.AddRow(1000, "EDGE_PROFILE", "edges", "ATS_PROFILE", "cdn1", false, now, 100)
+	rows.AddRow(1001, "MID_PROFILE", "mids", "ATS_PROFILE", "cdn1", false, now, 100)
+	mock.ExpectQuery("SELECT .* FROM profile p").WillReturnRows(rows)
+}
+
+func mockParameters(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "config_file", "value", "secure", "last_updated"})
+	rows.AddRow(500, "CONFIG proxy.config.http.server_ports", "records.config", "STRING 80", false, now)
+	rows.AddRow(501, "secret", "secrets.config", "hunter2", true, now)
+	mock.ExpectQuery("SELECT .* FROM parameter").WillReturnRows(rows)
+}
+
+func mockProfileParameters(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"profile", "parameter"})
+	rows.AddRow(1000, 500)
+	rows.AddRow(1000, 501)
+	rows.AddRow(1001, 500)
+	mock.ExpectQuery("SELECT .* FROM profile_parameter").WillReturnRows(rows)
+}
+
+// execute executes the given query with the loader returned by db.
+func execute(db func() (*loader, func()), query string) *gql.Result {
+	l, done := db()
+	defer done()
+	return gql.Do(gql.Params{
+		Schema:        schema,
+		RequestString: query,
+		Context:       context.WithValue(context.Background(), loaderKey, l),
+	})
+}
+
+// newTestLoader returns a mock database, and a function returning a loader using it on behalf of a user of the given
+// privilege level, who may see tenant 1, and a function to close the database.
+func newTestLoader(t *testing.T, privLevel int) (sqlmock.Sqlmock, func() (*loader, func())) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectBegin()
+	return mock, func() (*loader, func()) {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal("creating transaction: ", err)
+		}
+		user := &auth.CurrentUser{PrivLevel: privLevel, TenantID: 1}
+		l := newLoader(tx, user, []tc.TenantNullable{{ID: util.IntPtr(1)}})
+		return l, func() { db.Close() }
+	}
+}
+
+func resultData(t *testing.T, result *gql.Result) string {
+	if len(result.Errors) > 0 {
+		t.Fatalf("expected no errors, actual: %v", result.Errors)
+	}
+	bts, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatalf("marshalling result: %v", err)
+	}
+	return string(bts)
+}
+
+func TestTopologyServersQuery(t *testing.T) {
+	mock, db := newTestLoader(t, auth.PrivLevelReadOnly)
+	// Each table is expected to be queried exactly once, no matter how many objects need it.
+	mockTopologies(mock)
+	mockTopologyNodes(mock)
+	mockCachegroups(mock)
+	mockServers(mock)
+	mockServerCapabilities(mock)
+	mockDeliveryServices(mock)
+	mockDeliveryServiceServers(mock)
+	mockProfiles(mock)
+	mockParameters(mock)
+	mockProfileParameters(mock)
+
+	result := execute(db, `{
+		topologies(name: "top1") {
+			name
+			servers(type: "EDGE") {
+				hostName
+				cachegroup { name parentCachegroup { name } }
+				deliveryServices { xmlId }
+				profile { name parameters { name value } }
+			}
+		}
+	}`)
+
+	actual := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(resultData(t, result)), &actual); err != nil {
+		t.Fatalf("unmarshalling result: %v", err)
+	}
+	params := []interface{}{
+		map[string]interface{}{"name": "CONFIG proxy.config.http.server_ports", "value": "STRING 80"},
+		map[string]interface{}{"name": "secret", "value": hiddenField},
+	}
+	profile := map[string]interface{}{"name": "EDGE_PROFILE", "parameters": params}
+	expected := map[string]interface{}{
+		"topologies": []interface{}{
+			map[string]interface{}{
+				"name": "top1",
+				"servers": []interface{}{
+					map[string]interface{}{
+						"hostName":         "edge1",
+						"cachegroup":       map[string]interface{}{"name": "edgeCG1", "parentCachegroup": map[string]interface{}{"name": "midCG"}},
+						"deliveryServices": []interface{}{map[string]interface{}{"xmlId": "ds-top"}},
+						"profile":          profile,
+					},
+					map[string]interface{}{
+						"hostName":         "edge2",
+						"cachegroup":       map[string]interface{}{"name": "edgeCG2", "parentCachegroup": map[string]interface{}{"name": "midCG"}},
+						"deliveryServices": []interface{}{map[string]interface{}{"xmlId": "ds-assigned"}},
+						"profile":          profile,
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected every table to be queried once, actual: %v", err)
+	}
+}
+
+func TestDeliveryServiceServersQuery(t *testing.T) {
+	mock, db := newTestLoader(t, auth.PrivLevelAdmin)
+	mockTopologyNodes(mock)
+	mockCachegroups(mock)
+	mockServers(mock)
+	mockServerCapabilities(mock)
+	mockDeliveryServices(mock)
+	mockDeliveryServiceServers(mock)
+
+	result := execute(db, `{
+		deliveryServices { xmlId servers { hostName } }
+	}`)
+	expected := `{"deliveryServices":[{"servers":[{"hostName":"edge1"},{"hostName":"mid1"}],"xmlId":"ds-top"},{"servers":[{"hostName":"edge2"}],"xmlId":"ds-assigned"}]}`
+	if actual := resultData(t, result); actual != expected {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+}
+
+func TestSecureParameterValues(t *testing.T) {
+	for _, privLevel := range []int{auth.PrivLevelOperations, auth.PrivLevelAdmin} {
+		mock, db := newTestLoader(t, privLevel)
+		mockParameters(mock)
+		result := execute(db, `{ parameters(name: "secret") { value } }`)
+
+		expectedValue := "hunter2"
+		if privLevel < auth.PrivLevelAdmin {
+			expectedValue = hiddenField
+		}
+		expected := `{"parameters":[{"value":"` + expectedValue + `"}]}`
+		if actual := resultData(t, result); actual != expected {
+			t.Errorf("priv level %d expected: %s, actual: %s", privLevel, expected, actual)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	_, db := newTestLoader(t, auth.PrivLevelAdmin)
+	result := execute(db, `mutation { deleteServer(id: 1) }`)
+	if len(result.Errors) == 0 {
+		t.Error("expected an error executing a mutation, actual: none")
+	}
+}
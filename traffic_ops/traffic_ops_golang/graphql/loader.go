@@ -0,0 +1,566 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/lib/pq"
+)
+
+// hiddenField is the value given in place of the values of secure parameters to users who may not see them, the same
+// as the parameters endpoint gives.
+const hiddenField = "********"
+
+type server struct {
+	ID            int       `json:"id"`
+	HostName      string    `json:"hostName"`
+	DomainName    string    `json:"domainName"`
+	TCPPort       *int      `json:"tcpPort"`
+	HTTPSPort     *int      `json:"httpsPort"`
+	Rack          *string   `json:"rack"`
+	OfflineReason *string   `json:"offlineReason"`
+	UpdPending    bool      `json:"updPending"`
+	RevalPending  bool      `json:"revalPending"`
+	XMPPID        *string   `json:"xmppId"`
+	LastUpdated   time.Time `json:"lastUpdated"`
+	CDNName       string    `json:"cdnName"`
+	Type          string    `json:"type"`
+	Status        string    `json:"status"`
+	PhysLocation  string    `json:"physLocation"`
+	CachegroupID  int       `json:"-"`
+	CDNID         int       `json:"-"`
+	ProfileID     int       `json:"-"`
+}
+
+type cachegroup struct {
+	ID                          int       `json:"id"`
+	Name                        string    `json:"name"`
+	ShortName                   string    `json:"shortName"`
+	Type                        string    `json:"type"`
+	FallbackToClosest           *bool     `json:"fallbackToClosest"`
+	LastUpdated                 time.Time `json:"lastUpdated"`
+	ParentCachegroupID          *int      `json:"-"`
+	SecondaryParentCachegroupID *int      `json:"-"`
+}
+
+type topology struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+type topologyNode struct {
+	Topology   string   `json:"-"`
+	Cachegroup string   `json:"-"`
+	Parents    []string `json:"-"`
+}
+
+type deliveryService struct {
+	ID                   int       `json:"id"`
+	XMLID                string    `json:"xmlId"`
+	DisplayName          string    `json:"displayName"`
+	Active               bool      `json:"active"`
+	Type                 string    `json:"type"`
+	CDNName              string    `json:"cdnName"`
+	RoutingName          string    `json:"routingName"`
+	TenantID             int       `json:"tenantId"`
+	Tenant               string    `json:"tenant"`
+	Protocol             *int      `json:"protocol"`
+	LastUpdated          time.Time `json:"lastUpdated"`
+	CDNID                int       `json:"-"`
+	ProfileID            *int      `json:"-"`
+	Topology             *string   `json:"-"`
+	RequiredCapabilities []string  `json:"-"`
+}
+
+type profile struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	Description     *string   `json:"description"`
+	Type            string    `json:"type"`
+	CDNName         string    `json:"cdnName"`
+	RoutingDisabled bool      `json:"routingDisabled"`
+	LastUpdated     time.Time `json:"lastUpdated"`
+	CDNID           int       `json:"-"`
+}
+
+type parameter struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	ConfigFile  *string   `json:"configFile"`
+	Value       string    `json:"value"`
+	Secure      bool      `json:"secure"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// loader loads the Traffic Ops data queried by a single GraphQL request.
+//
+// Each table is queried at most once per request, the first time a resolver needs it, no matter how many objects
+// the request resolves, which avoids the N+1 queries a naive resolver of nested relationships would make. Delivery
+// services are limited to the tenants of the requesting user, and everything derived from them (e.g. a server's
+// delivery services) is limited likewise. Servers, cachegroups, topologies and profiles are limited to those without a
+// tenant and those of the tenants of the requesting user.
+//
+// The GraphQL executor resolves fields serially, so a loader is not safe for, and doesn't need, concurrent use.
+type loader struct {
+	tx        *sql.Tx
+	user      *auth.CurrentUser
+	tenantIDs map[int]struct{}
+
+	servers            []*server
+	serversByID        map[int]*server
+	serverCapabilities map[int]map[string]struct{}
+
+	cachegroups       []*cachegroup
+	cachegroupsByID   map[int]*cachegroup
+	cachegroupsByName map[string]*cachegroup
+
+	topologies       []*topology
+	topologiesByName map[string]*topology
+	topologyNodes    map[string][]*topologyNode
+
+	deliveryServices     []*deliveryService
+	deliveryServicesByID map[int]*deliveryService
+	dsServerIDs          map[int][]int
+	serverDSIDs          map[int][]int
+
+	profiles     []*profile
+	profilesByID map[int]*profile
+
+	parameters         []*parameter
+	parametersByID     map[int]*parameter
+	profileParameterID map[int][]int
+	parameterProfileID map[int][]int
+}
+
+func newLoader(tx *sql.Tx, user *auth.CurrentUser, tenants []tc.TenantNullable) *loader {
+	tenantIDs := make(map[int]struct{}, len(tenants))
+	for _, t := range tenants {
+		if t.ID != nil {
+			tenantIDs[*t.ID] = struct{}{}
+		}
+	}
+	return &loader{tx: tx, user: user, tenantIDs: tenantIDs}
+}
+
+// tenantIDList returns the IDs of the tenants of the requesting user, suitable for use as a query argument.
+func (l *loader) tenantIDList() interface{} {
+	tenantIDs := make([]int64, 0, len(l.tenantIDs))
+	for id := range l.tenantIDs {
+		tenantIDs = append(tenantIDs, int64(id))
+	}
+	return pq.Array(tenantIDs)
+}
+
+func (l *loader) loadServers() error {
+	if l.servers != nil {
+		return nil
+	}
+	qry := `
+SELECT
+	s.id,
+	s.host_name,
+	s.domain_name,
+	s.tcp_port,
+	s.https_port,
+	s.rack,
+	s.offline_reason,
+	s.upd_pending,
+	s.reval_pending,
+	s.xmpp_id,
+	s.last_updated,
+	cdn.name,
+	t.name,
+	st.name,
+	pl.name,
+	s.cachegroup,
+	s.cdn_id,
+	s.profile
+FROM server s
+JOIN cdn ON cdn.id = s.cdn_id
+JOIN type t ON t.id = s.type
+JOIN status st ON st.id = s.status
+JOIN phys_location pl ON pl.id = s.phys_location
+WHERE s.tenant_id IS NULL OR s.tenant_id = ANY($1::bigint[])
+ORDER BY s.host_name
+`
+	rows, err := l.tx.Query(qry, l.tenantIDList())
+	if err != nil {
+		return errors.New("querying servers: " + err.Error())
+	}
+	defer log.Close(rows, "closing server rows")
+
+	servers := []*server{}
+	serversByID := map[int]*server{}
+	for rows.Next() {
+		s := &server{}
+		if err := rows.Scan(&s.ID, &s.HostName, &s.DomainName, &s.TCPPort, &s.HTTPSPort, &s.Rack, &s.OfflineReason, &s.UpdPending, &s.RevalPending, &s.XMPPID, &s.LastUpdated, &s.CDNName, &s.Type, &s.Status, &s.PhysLocation, &s.CachegroupID, &s.CDNID, &s.ProfileID); err != nil {
+			return errors.New("scanning servers: " + err.Error())
+		}
+		servers = append(servers, s)
+		serversByID[s.ID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over servers: " + err.Error())
+	}
+	l.servers, l.serversByID = servers, serversByID
+	return nil
+}
+
+func (l *loader) loadServerCapabilities() error {
+	if l.serverCapabilities != nil {
+		return nil
+	}
+	rows, err := l.tx.Query(`SELECT server, server_capability FROM server_server_capability`)
+	if err != nil {
+		return errors.New("querying server capabilities: " + err.Error())
+	}
+	defer log.Close(rows, "closing server capability rows")
+
+	capabilities := map[int]map[string]struct{}{}
+	for rows.Next() {
+		serverID := 0
+		capability := ""
+		if err := rows.Scan(&serverID, &capability); err != nil {
+			return errors.New("scanning server capabilities: " + err.Error())
+		}
+		if _, ok := capabilities[serverID]; !ok {
+			capabilities[serverID] = map[string]struct{}{}
+		}
+		capabilities[serverID][capability] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over server capabilities: " + err.Error())
+	}
+	l.serverCapabilities = capabilities
+	return nil
+}
+
+func (l *loader) loadCachegroups() error {
+	if l.cachegroups != nil {
+		return nil
+	}
+	qry := `
+SELECT
+	c.id,
+	c.name,
+	c.short_name,
+	t.name,
+	c.fallback_to_closest,
+	c.last_updated,
+	c.parent_cachegroup_id,
+	c.secondary_parent_cachegroup_id
+FROM cachegroup c
+JOIN type t ON t.id = c.type
+WHERE c.tenant_id IS NULL OR c.tenant_id = ANY($1::bigint[])
+ORDER BY c.name
+`
+	rows, err := l.tx.Query(qry, l.tenantIDList())
+	if err != nil {
+		return errors.New("querying cachegroups: " + err.Error())
+	}
+	defer log.Close(rows, "closing cachegroup rows")
+
+	cachegroups := []*cachegroup{}
+	byID := map[int]*cachegroup{}
+	byName := map[string]*cachegroup{}
+	for rows.Next() {
+		cg := &cachegroup{}
+		if err := rows.Scan(&cg.ID, &cg.Name, &cg.ShortName, &cg.Type, &cg.FallbackToClosest, &cg.LastUpdated, &cg.ParentCachegroupID, &cg.SecondaryParentCachegroupID); err != nil {
+			return errors.New("scanning cachegroups: " + err.Error())
+		}
+		cachegroups = append(cachegroups, cg)
+		byID[cg.ID] = cg
+		byName[cg.Name] = cg
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over cachegroups: " + err.Error())
+	}
+	l.cachegroups, l.cachegroupsByID, l.cachegroupsByName = cachegroups, byID, byName
+	return nil
+}
+
+func (l *loader) loadTopologies() error {
+	if l.topologies != nil {
+		return nil
+	}
+	qry := `
+SELECT
+	t.name,
+	t.description,
+	t.last_updated
+FROM topology t
+WHERE t.tenant_id IS NULL OR t.tenant_id = ANY($1::bigint[])
+ORDER BY t.name
+`
+	rows, err := l.tx.Query(qry, l.tenantIDList())
+	if err != nil {
+		return errors.New("querying topologies: " + err.Error())
+	}
+	defer log.Close(rows, "closing topology rows")
+
+	topologies := []*topology{}
+	byName := map[string]*topology{}
+	for rows.Next() {
+		t := &topology{}
+		if err := rows.Scan(&t.Name, &t.Description, &t.LastUpdated); err != nil {
+			return errors.New("scanning topologies: " + err.Error())
+		}
+		topologies = append(topologies, t)
+		byName[t.Name] = t
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over topologies: " + err.Error())
+	}
+	l.topologies, l.topologiesByName = topologies, byName
+	return nil
+}
+
+func (l *loader) loadTopologyNodes() error {
+	if l.topologyNodes != nil {
+		return nil
+	}
+	qry := `
+SELECT
+	tc.topology,
+	tc.cachegroup,
+	COALESCE(ARRAY_AGG(p.cachegroup ORDER BY tcp.rank) FILTER (WHERE p.cachegroup IS NOT NULL), '{}')
+FROM topology_cachegroup tc
+LEFT JOIN topology_cachegroup_parents tcp ON tcp.child = tc.id
+LEFT JOIN topology_cachegroup p ON p.id = tcp.parent
+GROUP BY tc.id, tc.topology, tc.cachegroup
+ORDER BY tc.topology, tc.cachegroup
+`
+	rows, err := l.tx.Query(qry)
+	if err != nil {
+		return errors.New("querying topology nodes: " + err.Error())
+	}
+	defer log.Close(rows, "closing topology node rows")
+
+	nodes := map[string][]*topologyNode{}
+	for rows.Next() {
+		n := &topologyNode{}
+		if err := rows.Scan(&n.Topology, &n.Cachegroup, pq.Array(&n.Parents)); err != nil {
+			return errors.New("scanning topology nodes: " + err.Error())
+		}
+		nodes[n.Topology] = append(nodes[n.Topology], n)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over topology nodes: " + err.Error())
+	}
+	l.topologyNodes = nodes
+	return nil
+}
+
+// loadDeliveryServices loads the delivery services of the tenants of the requesting user.
+func (l *loader) loadDeliveryServices() error {
+	if l.deliveryServices != nil {
+		return nil
+	}
+	qry := `
+SELECT
+	ds.id,
+	ds.xml_id,
+	ds.display_name,
+	ds.active,
+	t.name,
+	cdn.name,
+	ds.routing_name,
+	ds.tenant_id,
+	tn.name,
+	ds.protocol,
+	ds.last_updated,
+	ds.cdn_id,
+	ds.profile,
+	ds.topology,
+	(SELECT COALESCE(ARRAY_AGG(required_capability ORDER BY required_capability), '{}')
+		FROM deliveryservices_required_capability
+		WHERE deliveryservice_id = ds.id)
+FROM deliveryservice ds
+JOIN type t ON t.id = ds.type
+JOIN cdn ON cdn.id = ds.cdn_id
+JOIN tenant tn ON tn.id = ds.tenant_id
+WHERE ds.tenant_id = ANY($1::bigint[])
+ORDER BY ds.xml_id
+`
+	rows, err := l.tx.Query(qry, l.tenantIDList())
+	if err != nil {
+		return errors.New("querying delivery services: " + err.Error())
+	}
+	defer log.Close(rows, "closing delivery service rows")
+
+	dses := []*deliveryService{}
+	byID := map[int]*deliveryService{}
+	for rows.Next() {
+		ds := &deliveryService{}
+		if err := rows.Scan(&ds.ID, &ds.XMLID, &ds.DisplayName, &ds.Active, &ds.Type, &ds.CDNName, &ds.RoutingName, &ds.TenantID, &ds.Tenant, &ds.Protocol, &ds.LastUpdated, &ds.CDNID, &ds.ProfileID, &ds.Topology, pq.Array(&ds.RequiredCapabilities)); err != nil {
+			return errors.New("scanning delivery services: " + err.Error())
+		}
+		dses = append(dses, ds)
+		byID[ds.ID] = ds
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over delivery services: " + err.Error())
+	}
+	l.deliveryServices, l.deliveryServicesByID = dses, byID
+	return nil
+}
+
+// loadDeliveryServiceServers loads the assignments of servers to the delivery services the requesting user may see.
+func (l *loader) loadDeliveryServiceServers() error {
+	if l.dsServerIDs != nil {
+		return nil
+	}
+	if err := l.loadDeliveryServices(); err != nil {
+		return err
+	}
+	rows, err := l.tx.Query(`SELECT deliveryservice, server FROM deliveryservice_server ORDER BY deliveryservice, server`)
+	if err != nil {
+		return errors.New("querying delivery service servers: " + err.Error())
+	}
+	defer log.Close(rows, "closing delivery service server rows")
+
+	dsServerIDs := map[int][]int{}
+	serverDSIDs := map[int][]int{}
+	for rows.Next() {
+		dsID, serverID := 0, 0
+		if err := rows.Scan(&dsID, &serverID); err != nil {
+			return errors.New("scanning delivery service servers: " + err.Error())
+		}
+		if _, ok := l.deliveryServicesByID[dsID]; !ok {
+			continue // not in the user's tenancy
+		}
+		dsServerIDs[dsID] = append(dsServerIDs[dsID], serverID)
+		serverDSIDs[serverID] = append(serverDSIDs[serverID], dsID)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over delivery service servers: " + err.Error())
+	}
+	l.dsServerIDs, l.serverDSIDs = dsServerIDs, serverDSIDs
+	return nil
+}
+
+func (l *loader) loadProfiles() error {
+	if l.profiles != nil {
+		return nil
+	}
+	qry := `
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.type,
+	cdn.name,
+	p.routing_disabled,
+	p.last_updated,
+	p.cdn
+FROM profile p
+JOIN cdn ON cdn.id = p.cdn
+WHERE p.tenant_id IS NULL OR p.tenant_id = ANY($1::bigint[])
+ORDER BY p.name
+`
+	rows, err := l.tx.Query(qry, l.tenantIDList())
+	if err != nil {
+		return errors.New("querying profiles: " + err.Error())
+	}
+	defer log.Close(rows, "closing profile rows")
+
+	profiles := []*profile{}
+	byID := map[int]*profile{}
+	for rows.Next() {
+		p := &profile{}
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Type, &p.CDNName, &p.RoutingDisabled, &p.LastUpdated, &p.CDNID); err != nil {
+			return errors.New("scanning profiles: " + err.Error())
+		}
+		profiles = append(profiles, p)
+		byID[p.ID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over profiles: " + err.Error())
+	}
+	l.profiles, l.profilesByID = profiles, byID
+	return nil
+}
+
+// loadParameters loads all parameters, hiding the values of secure parameters from users below the admin privilege
+// level, as the parameters endpoint does.
+func (l *loader) loadParameters() error {
+	if l.parameters != nil {
+		return nil
+	}
+	rows, err := l.tx.Query(`SELECT id, name, config_file, value, secure, last_updated FROM parameter ORDER BY name, config_file, value`)
+	if err != nil {
+		return errors.New("querying parameters: " + err.Error())
+	}
+	defer log.Close(rows, "closing parameter rows")
+
+	params := []*parameter{}
+	byID := map[int]*parameter{}
+	for rows.Next() {
+		p := &parameter{}
+		if err := rows.Scan(&p.ID, &p.Name, &p.ConfigFile, &p.Value, &p.Secure, &p.LastUpdated); err != nil {
+			return errors.New("scanning parameters: " + err.Error())
+		}
+		if p.Secure && l.user.PrivLevel < auth.PrivLevelAdmin {
+			p.Value = hiddenField
+		}
+		params = append(params, p)
+		byID[p.ID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over parameters: " + err.Error())
+	}
+	l.parameters, l.parametersByID = params, byID
+	return nil
+}
+
+func (l *loader) loadProfileParameters() error {
+	if l.profileParameterID != nil {
+		return nil
+	}
+	rows, err := l.tx.Query(`SELECT profile, parameter FROM profile_parameter ORDER BY profile, parameter`)
+	if err != nil {
+		return errors.New("querying profile parameters: " + err.Error())
+	}
+	defer log.Close(rows, "closing profile parameter rows")
+
+	profileParams := map[int][]int{}
+	paramProfiles := map[int][]int{}
+	for rows.Next() {
+		profileID, paramID := 0, 0
+		if err := rows.Scan(&profileID, &paramID); err != nil {
+			return errors.New("scanning profile parameters: " + err.Error())
+		}
+		profileParams[profileID] = append(profileParams[profileID], paramID)
+		paramProfiles[paramID] = append(paramProfiles[paramID], profileID)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating over profile parameters: " + err.Error())
+	}
+	l.profileParameterID, l.parameterProfileID = profileParams, paramProfiles
+	return nil
+}
@@ -0,0 +1,211 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// topologyCachegroups returns the names of the cachegroups of the given topology.
+func (l *loader) topologyCachegroups(topologyName string) (map[string]struct{}, error) {
+	if err := l.loadTopologyNodes(); err != nil {
+		return nil, err
+	}
+	names := map[string]struct{}{}
+	for _, node := range l.topologyNodes[topologyName] {
+		names[node.Cachegroup] = struct{}{}
+	}
+	return names, nil
+}
+
+// topologyServers returns the servers in the cachegroups of the given topology.
+func (l *loader) topologyServers(topologyName string) ([]*server, error) {
+	cgNames, err := l.topologyCachegroups(topologyName)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.loadCachegroups(); err != nil {
+		return nil, err
+	}
+	if err := l.loadServers(); err != nil {
+		return nil, err
+	}
+	servers := []*server{}
+	for _, s := range l.servers {
+		cg, ok := l.cachegroupsByID[s.CachegroupID]
+		if !ok {
+			continue
+		}
+		if _, ok := cgNames[cg.Name]; ok {
+			servers = append(servers, s)
+		}
+	}
+	return servers, nil
+}
+
+// hasCapabilities returns whether the given server has all the given capabilities.
+func (l *loader) hasCapabilities(s *server, capabilities []string) (bool, error) {
+	if len(capabilities) == 0 {
+		return true, nil
+	}
+	if err := l.loadServerCapabilities(); err != nil {
+		return false, err
+	}
+	for _, capability := range capabilities {
+		if _, ok := l.serverCapabilities[s.ID][capability]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// deliveryServiceServers returns the servers of a delivery service. The servers of a topology-based delivery service
+// are the servers of its CDN in the cachegroups of its topology which have all its required capabilities; the servers
+// of any other delivery service are those assigned to it.
+func (l *loader) deliveryServiceServers(ds *deliveryService) ([]*server, error) {
+	if ds.Topology != nil {
+		candidates, err := l.topologyServers(*ds.Topology)
+		if err != nil {
+			return nil, err
+		}
+		servers := []*server{}
+		for _, s := range candidates {
+			if s.CDNID != ds.CDNID {
+				continue
+			}
+			ok, err := l.hasCapabilities(s, ds.RequiredCapabilities)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				servers = append(servers, s)
+			}
+		}
+		return servers, nil
+	}
+
+	if err := l.loadDeliveryServiceServers(); err != nil {
+		return nil, err
+	}
+	if err := l.loadServers(); err != nil {
+		return nil, err
+	}
+	servers := []*server{}
+	for _, id := range l.dsServerIDs[ds.ID] {
+		if s, ok := l.serversByID[id]; ok {
+			servers = append(servers, s)
+		}
+	}
+	return servers, nil
+}
+
+// serverDeliveryServices returns the delivery services of a server, i.e. those of whose servers it is one, per
+// deliveryServiceServers.
+func (l *loader) serverDeliveryServices(s *server) ([]*deliveryService, error) {
+	if err := l.loadDeliveryServiceServers(); err != nil {
+		return nil, err
+	}
+	assigned := map[int]struct{}{}
+	for _, id := range l.serverDSIDs[s.ID] {
+		assigned[id] = struct{}{}
+	}
+	if err := l.loadCachegroups(); err != nil {
+		return nil, err
+	}
+	cg := l.cachegroupsByID[s.CachegroupID]
+
+	dses := []*deliveryService{}
+	for _, ds := range l.deliveryServices {
+		if ds.Topology == nil {
+			if _, ok := assigned[ds.ID]; ok {
+				dses = append(dses, ds)
+			}
+			continue
+		}
+		if cg == nil || ds.CDNID != s.CDNID {
+			continue
+		}
+		cgNames, err := l.topologyCachegroups(*ds.Topology)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := cgNames[cg.Name]; !ok {
+			continue
+		}
+		ok, err := l.hasCapabilities(s, ds.RequiredCapabilities)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			dses = append(dses, ds)
+		}
+	}
+	return dses, nil
+}
+
+// cachegroupTopologies returns the topologies of which the given cachegroup is a node.
+func (l *loader) cachegroupTopologies(cg *cachegroup) ([]*topology, error) {
+	if err := l.loadTopologies(); err != nil {
+		return nil, err
+	}
+	if err := l.loadTopologyNodes(); err != nil {
+		return nil, err
+	}
+	topologies := []*topology{}
+	for _, t := range l.topologies {
+		for _, node := range l.topologyNodes[t.Name] {
+			if node.Cachegroup == cg.Name {
+				topologies = append(topologies, t)
+				break
+			}
+		}
+	}
+	return topologies, nil
+}
+
+// profileParameters returns the parameters assigned to the given profile.
+func (l *loader) profileParameters(p *profile) ([]*parameter, error) {
+	if err := l.loadParameters(); err != nil {
+		return nil, err
+	}
+	if err := l.loadProfileParameters(); err != nil {
+		return nil, err
+	}
+	params := []*parameter{}
+	for _, id := range l.profileParameterID[p.ID] {
+		if param, ok := l.parametersByID[id]; ok {
+			params = append(params, param)
+		}
+	}
+	return params, nil
+}
+
+// parameterProfiles returns the profiles to which the given parameter is assigned.
+func (l *loader) parameterProfiles(param *parameter) ([]*profile, error) {
+	if err := l.loadProfiles(); err != nil {
+		return nil, err
+	}
+	if err := l.loadProfileParameters(); err != nil {
+		return nil, err
+	}
+	profiles := []*profile{}
+	for _, id := range l.parameterProfileID[param.ID] {
+		if p, ok := l.profilesByID[id]; ok {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
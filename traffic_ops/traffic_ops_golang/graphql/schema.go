@@ -0,0 +1,573 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+type loaderKeyType struct{}
+
+// loaderKey is the request context key of the request's *loader.
+var loaderKey = loaderKeyType{}
+
+// resolver is a resolve function which is given the request's loader.
+type resolver func(l *loader, p gql.ResolveParams) (interface{}, error)
+
+// resolve returns a gql.FieldResolveFn calling the given resolver with the request's loader. Errors returned by the
+// resolver are system errors: they are logged, and the client is only told an error occurred.
+func resolve(r resolver) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		l, ok := p.Context.Value(loaderKey).(*loader)
+		if !ok {
+			log.Errorln("graphql resolving " + p.Info.FieldName + ": request context has no loader")
+			return nil, errors.New(http.StatusText(http.StatusInternalServerError))
+		}
+		v, err := r(l, p)
+		if err != nil {
+			log.Errorln("graphql resolving " + p.Info.FieldName + ": " + err.Error())
+			return nil, errors.New(http.StatusText(http.StatusInternalServerError))
+		}
+		return v, nil
+	}
+}
+
+func listOf(t gql.Type) gql.Output {
+	return gql.NewNonNull(gql.NewList(gql.NewNonNull(t)))
+}
+
+var serverArgs = gql.FieldConfigArgument{
+	"id":         &gql.ArgumentConfig{Type: gql.Int},
+	"hostName":   &gql.ArgumentConfig{Type: gql.String},
+	"cdn":        &gql.ArgumentConfig{Type: gql.String},
+	"type":       &gql.ArgumentConfig{Type: gql.String},
+	"status":     &gql.ArgumentConfig{Type: gql.String},
+	"cachegroup": &gql.ArgumentConfig{Type: gql.String},
+	"profile":    &gql.ArgumentConfig{Type: gql.String},
+	"topology":   &gql.ArgumentConfig{Type: gql.String},
+}
+
+var cachegroupArgs = gql.FieldConfigArgument{
+	"id":       &gql.ArgumentConfig{Type: gql.Int},
+	"name":     &gql.ArgumentConfig{Type: gql.String},
+	"type":     &gql.ArgumentConfig{Type: gql.String},
+	"topology": &gql.ArgumentConfig{Type: gql.String},
+}
+
+var topologyArgs = gql.FieldConfigArgument{
+	"name": &gql.ArgumentConfig{Type: gql.String},
+}
+
+var deliveryServiceArgs = gql.FieldConfigArgument{
+	"id":       &gql.ArgumentConfig{Type: gql.Int},
+	"xmlId":    &gql.ArgumentConfig{Type: gql.String},
+	"cdn":      &gql.ArgumentConfig{Type: gql.String},
+	"type":     &gql.ArgumentConfig{Type: gql.String},
+	"active":   &gql.ArgumentConfig{Type: gql.Boolean},
+	"topology": &gql.ArgumentConfig{Type: gql.String},
+}
+
+var profileArgs = gql.FieldConfigArgument{
+	"id":   &gql.ArgumentConfig{Type: gql.Int},
+	"name": &gql.ArgumentConfig{Type: gql.String},
+	"cdn":  &gql.ArgumentConfig{Type: gql.String},
+	"type": &gql.ArgumentConfig{Type: gql.String},
+}
+
+var parameterArgs = gql.FieldConfigArgument{
+	"id":         &gql.ArgumentConfig{Type: gql.Int},
+	"name":       &gql.ArgumentConfig{Type: gql.String},
+	"configFile": &gql.ArgumentConfig{Type: gql.String},
+}
+
+var serverType *gql.Object
+var cachegroupType *gql.Object
+var topologyType *gql.Object
+var topologyNodeType *gql.Object
+var deliveryServiceType *gql.Object
+var profileType *gql.Object
+var parameterType *gql.Object
+
+// schema is the schema of the Traffic Ops GraphQL API. It has no mutations: the GraphQL API is read-only.
+var schema gql.Schema
+
+func init() {
+	serverType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Server",
+		Description: "A cache server, origin, Traffic Monitor, Traffic Router, or other server in a CDN.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":            &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"hostName":      &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"domainName":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"tcpPort":       &gql.Field{Type: gql.Int},
+				"httpsPort":     &gql.Field{Type: gql.Int},
+				"rack":          &gql.Field{Type: gql.String},
+				"offlineReason": &gql.Field{Type: gql.String},
+				"updPending":    &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+				"revalPending":  &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+				"xmppId":        &gql.Field{Type: gql.String},
+				"lastUpdated":   &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"cdnName":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"type":          &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"status":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"physLocation":  &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"cachegroup": &gql.Field{
+					Type: cachegroupType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadCachegroups(); err != nil {
+							return nil, err
+						}
+						return l.cachegroupsByID[p.Source.(*server).CachegroupID], nil
+					}),
+				},
+				"profile": &gql.Field{
+					Type: profileType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadProfiles(); err != nil {
+							return nil, err
+						}
+						return l.profilesByID[p.Source.(*server).ProfileID], nil
+					}),
+				},
+				"deliveryServices": &gql.Field{
+					Type:        listOf(deliveryServiceType),
+					Description: "The delivery services the server serves: those assigned to it, and the topology-based delivery services of its CDN whose topologies include its cachegroup, if it has all their required capabilities.",
+					Args:        deliveryServiceArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						dses, err := l.serverDeliveryServices(p.Source.(*server))
+						if err != nil {
+							return nil, err
+						}
+						return filterDeliveryServices(dses, p.Args), nil
+					}),
+				},
+			}
+		}),
+	})
+
+	cachegroupType = gql.NewObject(gql.ObjectConfig{
+		Name:        "CacheGroup",
+		Description: "A group of servers, generally in the same location.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":                &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"name":              &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"shortName":         &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"type":              &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"fallbackToClosest": &gql.Field{Type: gql.Boolean},
+				"lastUpdated":       &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"parentCachegroup": &gql.Field{
+					Type: cachegroupType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						return l.cachegroupByID(p.Source.(*cachegroup).ParentCachegroupID)
+					}),
+				},
+				"secondaryParentCachegroup": &gql.Field{
+					Type: cachegroupType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						return l.cachegroupByID(p.Source.(*cachegroup).SecondaryParentCachegroupID)
+					}),
+				},
+				"servers": &gql.Field{
+					Type: listOf(serverType),
+					Args: serverArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadServers(); err != nil {
+							return nil, err
+						}
+						cg := p.Source.(*cachegroup)
+						servers := []*server{}
+						for _, s := range l.servers {
+							if s.CachegroupID == cg.ID {
+								servers = append(servers, s)
+							}
+						}
+						return l.filterServers(servers, p.Args)
+					}),
+				},
+				"topologies": &gql.Field{
+					Type: listOf(topologyType),
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						return l.cachegroupTopologies(p.Source.(*cachegroup))
+					}),
+				},
+			}
+		}),
+	})
+
+	topologyNodeType = gql.NewObject(gql.ObjectConfig{
+		Name:        "TopologyNode",
+		Description: "A cachegroup in a topology, and its parents in that topology.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"cachegroup": &gql.Field{
+					Type: cachegroupType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadCachegroups(); err != nil {
+							return nil, err
+						}
+						return l.cachegroupsByName[p.Source.(*topologyNode).Cachegroup], nil
+					}),
+				},
+				"parents": &gql.Field{
+					Type:        listOf(cachegroupType),
+					Description: "The parents of the cachegroup in the topology, primary parent first.",
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadCachegroups(); err != nil {
+							return nil, err
+						}
+						parents := []*cachegroup{}
+						for _, name := range p.Source.(*topologyNode).Parents {
+							if cg, ok := l.cachegroupsByName[name]; ok {
+								parents = append(parents, cg)
+							}
+						}
+						return parents, nil
+					}),
+				},
+			}
+		}),
+	})
+
+	topologyType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Topology",
+		Description: "A structure of cachegroups, defining the servers of the delivery services which use it.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"name":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"description": &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"lastUpdated": &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"nodes": &gql.Field{
+					Type: listOf(topologyNodeType),
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadTopologyNodes(); err != nil {
+							return nil, err
+						}
+						nodes := l.topologyNodes[p.Source.(*topology).Name]
+						if nodes == nil {
+							nodes = []*topologyNode{}
+						}
+						return nodes, nil
+					}),
+				},
+				"cachegroups": &gql.Field{
+					Type: listOf(cachegroupType),
+					Args: cachegroupArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadCachegroups(); err != nil {
+							return nil, err
+						}
+						names, err := l.topologyCachegroups(p.Source.(*topology).Name)
+						if err != nil {
+							return nil, err
+						}
+						cgs := []*cachegroup{}
+						for _, cg := range l.cachegroups {
+							if _, ok := names[cg.Name]; ok {
+								cgs = append(cgs, cg)
+							}
+						}
+						return l.filterCachegroups(cgs, p.Args)
+					}),
+				},
+				"servers": &gql.Field{
+					Type:        listOf(serverType),
+					Description: "The servers in the cachegroups of the topology.",
+					Args:        serverArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						servers, err := l.topologyServers(p.Source.(*topology).Name)
+						if err != nil {
+							return nil, err
+						}
+						return l.filterServers(servers, p.Args)
+					}),
+				},
+				"deliveryServices": &gql.Field{
+					Type: listOf(deliveryServiceType),
+					Args: deliveryServiceArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadDeliveryServices(); err != nil {
+							return nil, err
+						}
+						t := p.Source.(*topology)
+						dses := []*deliveryService{}
+						for _, ds := range l.deliveryServices {
+							if ds.Topology != nil && *ds.Topology == t.Name {
+								dses = append(dses, ds)
+							}
+						}
+						return filterDeliveryServices(dses, p.Args), nil
+					}),
+				},
+			}
+		}),
+	})
+
+	deliveryServiceType = gql.NewObject(gql.ObjectConfig{
+		Name:        "DeliveryService",
+		Description: "A delivery service. Only the delivery services of the tenants of the requesting user are visible.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":          &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"xmlId":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"displayName": &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"active":      &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+				"type":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"cdnName":     &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"routingName": &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"tenantId":    &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"tenant":      &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"protocol":    &gql.Field{Type: gql.Int},
+				"lastUpdated": &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"requiredCapabilities": &gql.Field{
+					Type: listOf(gql.String),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						caps := p.Source.(*deliveryService).RequiredCapabilities
+						if caps == nil {
+							caps = []string{}
+						}
+						return caps, nil
+					},
+				},
+				"topology": &gql.Field{
+					Type: topologyType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						ds := p.Source.(*deliveryService)
+						if ds.Topology == nil {
+							return nil, nil
+						}
+						if err := l.loadTopologies(); err != nil {
+							return nil, err
+						}
+						return l.topologiesByName[*ds.Topology], nil
+					}),
+				},
+				"profile": &gql.Field{
+					Type: profileType,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						ds := p.Source.(*deliveryService)
+						if ds.ProfileID == nil {
+							return nil, nil
+						}
+						if err := l.loadProfiles(); err != nil {
+							return nil, err
+						}
+						return l.profilesByID[*ds.ProfileID], nil
+					}),
+				},
+				"servers": &gql.Field{
+					Type:        listOf(serverType),
+					Description: "The servers of the delivery service: for a topology-based delivery service, the servers of its CDN in the cachegroups of its topology which have all its required capabilities; otherwise, the servers assigned to it.",
+					Args:        serverArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						servers, err := l.deliveryServiceServers(p.Source.(*deliveryService))
+						if err != nil {
+							return nil, err
+						}
+						return l.filterServers(servers, p.Args)
+					}),
+				},
+			}
+		}),
+	})
+
+	profileType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Profile",
+		Description: "A set of parameters applied to servers or delivery services.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":              &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"name":            &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"description":     &gql.Field{Type: gql.String},
+				"type":            &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"cdnName":         &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"routingDisabled": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+				"lastUpdated":     &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"parameters": &gql.Field{
+					Type: listOf(parameterType),
+					Args: parameterArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						params, err := l.profileParameters(p.Source.(*profile))
+						if err != nil {
+							return nil, err
+						}
+						return filterParameters(params, p.Args), nil
+					}),
+				},
+				"servers": &gql.Field{
+					Type: listOf(serverType),
+					Args: serverArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadServers(); err != nil {
+							return nil, err
+						}
+						pr := p.Source.(*profile)
+						servers := []*server{}
+						for _, s := range l.servers {
+							if s.ProfileID == pr.ID {
+								servers = append(servers, s)
+							}
+						}
+						return l.filterServers(servers, p.Args)
+					}),
+				},
+				"deliveryServices": &gql.Field{
+					Type: listOf(deliveryServiceType),
+					Args: deliveryServiceArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						if err := l.loadDeliveryServices(); err != nil {
+							return nil, err
+						}
+						pr := p.Source.(*profile)
+						dses := []*deliveryService{}
+						for _, ds := range l.deliveryServices {
+							if ds.ProfileID != nil && *ds.ProfileID == pr.ID {
+								dses = append(dses, ds)
+							}
+						}
+						return filterDeliveryServices(dses, p.Args), nil
+					}),
+				},
+			}
+		}),
+	})
+
+	parameterType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Parameter",
+		Description: "A configuration parameter. The values of secure parameters are hidden from users below the admin privilege level.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":          &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"name":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"configFile":  &gql.Field{Type: gql.String},
+				"value":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"secure":      &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+				"lastUpdated": &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"profiles": &gql.Field{
+					Type: listOf(profileType),
+					Args: profileArgs,
+					Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+						profiles, err := l.parameterProfiles(p.Source.(*parameter))
+						if err != nil {
+							return nil, err
+						}
+						return filterProfiles(profiles, p.Args), nil
+					}),
+				},
+			}
+		}),
+	})
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"servers": &gql.Field{
+				Type: listOf(serverType),
+				Args: serverArgs,
+				Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+					if err := l.loadServers(); err != nil {
+						return nil, err
+					}
+					return l.filterServers(l.servers, p.Args)
+				}),
+			},
+			"cachegroups": &gql.Field{
+				Type: listOf(cachegroupType),
+				Args: cachegroupArgs,
+				Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+					if err := l.loadCachegroups(); err != nil {
+						return nil, err
+					}
+					return l.filterCachegroups(l.cachegroups, p.Args)
+				}),
+			},
+			"topologies": &gql.Field{
+				Type: listOf(topologyType),
+				Args: topologyArgs,
+				Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+					if err := l.loadTopologies(); err != nil {
+						return nil, err
+					}
+					name, ok := p.Args["name"].(string)
+					if !ok {
+						return l.topologies, nil
+					}
+					topologies := []*topology{}
+					if t, ok := l.topologiesByName[name]; ok {
+						topologies = append(topologies, t)
+					}
+					return topologies, nil
+				}),
+			},
+			"deliveryServices": &gql.Field{
+				Type: listOf(deliveryServiceType),
+				Args: deliveryServiceArgs,
+				Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+					if err := l.loadDeliveryServices(); err != nil {
+						return nil, err
+					}
+					return filterDeliveryServices(l.deliveryServices, p.Args), nil
+				}),
+			},
+			"profiles": &gql.Field{
+				Type: listOf(profileType),
+				Args: profileArgs,
+				Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+					if err := l.loadProfiles(); err != nil {
+						return nil, err
+					}
+					return filterProfiles(l.profiles, p.Args), nil
+				}),
+			},
+			"parameters": &gql.Field{
+				Type: listOf(parameterType),
+				Args: parameterArgs,
+				Resolve: resolve(func(l *loader, p gql.ResolveParams) (interface{}, error) {
+					if err := l.loadParameters(); err != nil {
+						return nil, err
+					}
+					return filterParameters(l.parameters, p.Args), nil
+				}),
+			},
+		},
+	})
+
+	var err error
+	schema, err = gql.NewSchema(gql.SchemaConfig{Query: query})
+	if err != nil {
+		panic("building GraphQL schema: " + err.Error()) // a programming error, caught by the tests
+	}
+}
+
+func (l *loader) cachegroupByID(id *int) (interface{}, error) {
+	if id == nil {
+		return nil, nil
+	}
+	if err := l.loadCachegroups(); err != nil {
+		return nil, err
+	}
+	return l.cachegroupsByID[*id], nil
+}
@@ -27,6 +27,7 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -119,7 +120,8 @@ func LoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 		resp := struct {
 			tc.Alerts
 		}{}
-		userAllowed, err, blockingErr := auth.CheckLocalUserIsAllowed(form, db, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+		dbTimeout := time.Duration(cfg.DBQueryTimeoutSeconds) * time.Second
+		userAllowed, err, blockingErr := auth.CheckLocalUserIsAllowed(form, db, dbTimeout)
 		if blockingErr != nil {
 			api.HandleErr(w, r, nil, http.StatusServiceUnavailable, nil, fmt.Errorf("error checking local user password: %s\n", blockingErr.Error()))
 			return
@@ -128,7 +130,17 @@ func LoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 			log.Errorf("checking local user: %s\n", err.Error())
 		}
 		if userAllowed {
-			authenticated, err, blockingErr = auth.CheckLocalUserPassword(form, db, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+			locked, err := auth.CheckLocalUserLocked(form, db, dbTimeout)
+			if err != nil {
+				api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("checking local user lockout: %v", err))
+				return
+			}
+			if locked {
+				api.HandleErr(w, r, nil, http.StatusUnauthorized, errors.New("This account is locked after too many failed login attempts. Try again later, or contact an administrator."), nil)
+				return
+			}
+
+			authenticated, err, blockingErr = auth.CheckLocalUserPassword(form, db, dbTimeout)
 			if blockingErr != nil {
 				api.HandleErr(w, r, nil, http.StatusServiceUnavailable, nil, fmt.Errorf("error checking local user password: %s\n", blockingErr.Error()))
 				return
@@ -136,6 +148,15 @@ func LoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 			if err != nil {
 				log.Errorf("checking local user password: %s\n", err.Error())
 			}
+			passwordExpired := false
+			if authenticated {
+				// LDAP passwords are subject to the LDAP server's policy, not Traffic Ops's
+				passwordExpired, err = auth.CheckLocalUserPasswordExpired(form, cfg.PasswordPolicy.MaxAgeDays, db, dbTimeout)
+				if err != nil {
+					api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("checking local user password expiration: %v", err))
+					return
+				}
+			}
 			var ldapErr error
 			if !authenticated {
 				if cfg.LDAPEnabled {
@@ -145,13 +166,43 @@ func LoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 					}
 				}
 			}
+			mfaEnabled, mfaVerified := false, false
 			if authenticated {
-				httpCookie := tocookie.GetCookie(form.Username, defaultCookieDuration, cfg.Secrets[0])
-				http.SetCookie(w, httpCookie)
+				mfaEnabled, mfaVerified, err = auth.CheckLocalUserMFA(form, db, dbTimeout)
+				if err != nil {
+					api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("checking local user MFA: %v", err))
+					return
+				}
+			}
+			// MFA is checked before password expiration, so the expiration doesn't tell a caller without the second factor that the password is correct
+			if authenticated && mfaEnabled && !mfaVerified {
+				authenticated = false
+				recordFailedLogin(form, cfg, db)
+				msg := "Invalid one-time password."
+				if form.OTP == "" {
+					msg = "A one-time password is required."
+				}
+				resp = struct {
+					tc.Alerts
+				}{tc.CreateAlerts(tc.ErrorLevel, msg)}
+			} else if passwordExpired {
+				authenticated = false
+				resp = struct {
+					tc.Alerts
+				}{tc.CreateAlerts(tc.ErrorLevel, "Your password has expired. Reset it, or ask an administrator to change it.")}
+			} else if authenticated {
+				if err := auth.RecordSuccessfulLogin(form, db, dbTimeout); err != nil {
+					log.Errorf("resetting failed logins of user '%s': %v", form.Username, err)
+				}
+				if err := setSessionCookie(w, r, db, cfg, form.Username, mfaEnabled); err != nil {
+					api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("starting session: %v", err))
+					return
+				}
 				resp = struct {
 					tc.Alerts
 				}{tc.CreateAlerts(tc.SuccessLevel, "Successfully logged in.")}
 			} else {
+				recordFailedLogin(form, cfg, db)
 				resp = struct {
 					tc.Alerts
 				}{tc.CreateAlerts(tc.ErrorLevel, "Invalid username or password.")}
@@ -196,8 +247,10 @@ func TokenLoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 			return
 		}
 
-		httpCookie := tocookie.GetCookie(username, defaultCookieDuration, cfg.Secrets[0])
-		http.SetCookie(w, httpCookie)
+		if err := setSessionCookie(w, r, db, cfg, username, false); err != nil {
+			api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("starting session: %v", err))
+			return
+		}
 		respBts, err := json.Marshal(tc.CreateAlerts(tc.SuccessLevel, "Successfully logged in."))
 		if err != nil {
 			sysErr := fmt.Errorf("Marshaling response: %v", err)
@@ -341,8 +394,10 @@ func OauthLoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 		}
 
 		if userAllowed && authenticated {
-			httpCookie := tocookie.GetCookie(userId, defaultCookieDuration, cfg.Secrets[0])
-			http.SetCookie(w, httpCookie)
+			if err := setSessionCookie(w, r, db, cfg, userId, false); err != nil {
+				api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, fmt.Errorf("starting session: %v", err))
+				return
+			}
 			resp = struct {
 				tc.Alerts
 			}{tc.CreateAlerts(tc.SuccessLevel, "Successfully logged in.")}
@@ -369,6 +424,32 @@ func OauthLoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 	}
 }
 
+// setSessionCookie starts a new session of the user with the given username, and sets the cookie of it in the
+// response. mfa is whether the user verified a second factor to log in.
+func setSessionCookie(w http.ResponseWriter, r *http.Request, db *sqlx.DB, cfg config.Config, username string, mfa bool) error {
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+	sessionID, err := auth.CreateSession(db, username, r.UserAgent(), clientIP, defaultCookieDuration, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, tocookie.GetSessionCookie(username, sessionID, mfa, defaultCookieDuration, cfg.Secrets[0]))
+	return nil
+}
+
+// recordFailedLogin counts a failed login attempt of the user logging in, logging any error, since the attempt failed
+// either way.
+func recordFailedLogin(form auth.PasswordForm, cfg config.Config, db *sqlx.DB) {
+	locked, err := auth.RecordFailedLogin(form, cfg.Lockout, db, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+	if err != nil {
+		log.Errorf("recording failed login of user '%s': %v", form.Username, err)
+	} else if locked {
+		log.Warnf("user '%s' locked out after %d failed login attempts", form.Username, cfg.Lockout.MaxFailedLogins)
+	}
+}
+
 func VerifyUrlOnWhiteList(urlString string, whiteListedUrls []string) (bool, error) {
 
 	for _, listing := range whiteListedUrls {
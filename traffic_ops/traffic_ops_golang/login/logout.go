@@ -26,6 +26,7 @@ import "net/http"
 import "github.com/apache/trafficcontrol/lib/go-tc"
 import "github.com/apache/trafficcontrol/lib/go-rfc"
 import "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+import "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 import "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
 
 func LogoutHandler(secret string) http.HandlerFunc {
@@ -38,6 +39,20 @@ func LogoutHandler(secret string) http.HandlerFunc {
 		}
 		defer inf.Close()
 
+		// revoke the session of the cookie being logged out, so the cookie can't be used again even if it was copied
+		sessionID := inf.User.SessionID
+		if cookie, err := r.Cookie(tocookie.Name); err == nil {
+			if parsed, err := tocookie.Parse(secret, cookie.Value); err == nil && parsed.AuthData == inf.User.UserName {
+				sessionID = parsed.Session
+			}
+		}
+		if sessionID != "" {
+			if err := auth.DeleteSession(tx, sessionID); err != nil {
+				api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+				return
+			}
+		}
+
 		cookie := tocookie.GetCookie(inf.User.UserName, 0, secret)
 		http.SetCookie(w, cookie)
 		resp := struct {
@@ -145,3 +145,52 @@ func TestLogout(t *testing.T) {
 		t.Errorf("Expected handler to set the '%s' cookie, but it didn't", tocookie.Name)
 	}
 }
+
+func TestLogoutRevokesSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize mock database: %v", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	sessionID := "0123456789abcdef"
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM user_session").WithArgs(sessionID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	cookie := tocookie.GetSessionCookie(testUser.UserName, sessionID, false, 24*time.Hour, "test")
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/3.0/user/logout", nil)
+	if err != nil {
+		t.Fatalf("Failed to create a request: %v", err)
+	}
+
+	user := testUser
+	user.SessionID = sessionID
+
+	ctx := req.Context()
+	ctx = context.WithValue(ctx, api.DBContextKey, db)
+	conf := config.Config{}
+	conf.ConfigTrafficOpsGolang.DBQueryTimeoutSeconds = 100
+	ctx = context.WithValue(ctx, api.ConfigContextKey, &conf)
+	ctx = context.WithValue(ctx, api.ReqIDContextKey, uint64(1))
+	ctx = context.WithValue(ctx, api.APIRespWrittenKey, false)
+	ctx = context.WithValue(ctx, auth.CurrentUserKey, user)
+	ctx = context.WithValue(ctx, api.PathParamsKey, map[string]string{})
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(24*time.Hour))
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	req.AddCookie(cookie)
+	LogoutHandler("test")(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected response code %d, got %d", http.StatusOK, rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expected the session to be deleted: %v", err)
+	}
+}
@@ -0,0 +1,387 @@
+package maintenance
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// we need a type alias to define functions on
+type TOMaintenanceWindow struct {
+	api.APIInfoImpl `json:"-"`
+	tc.MaintenanceWindowNullable
+}
+
+func (v *TOMaintenanceWindow) SetLastUpdated(t tc.TimeNoMod) { v.LastUpdated = &t }
+func (v *TOMaintenanceWindow) InsertQuery() string           { return insertQuery() }
+func (v *TOMaintenanceWindow) NewReadObj() interface{}       { return &tc.MaintenanceWindowNullable{} }
+func (v *TOMaintenanceWindow) SelectQuery() string           { return selectQuery() }
+func (v *TOMaintenanceWindow) ParamColumns() map[string]dbhelpers.WhereColumnInfo {
+	return map[string]dbhelpers.WhereColumnInfo{
+		"id":           dbhelpers.WhereColumnInfo{"mw.id", api.IsInt},
+		"scope":        dbhelpers.WhereColumnInfo{"mw.scope", nil},
+		"serverId":     dbhelpers.WhereColumnInfo{"mw.server", api.IsInt},
+		"cachegroupId": dbhelpers.WhereColumnInfo{"mw.cachegroup", api.IsInt},
+		"topology":     dbhelpers.WhereColumnInfo{"mw.topology", nil},
+	}
+}
+func (v *TOMaintenanceWindow) UpdateQuery() string { return updateQuery() }
+func (v *TOMaintenanceWindow) DeleteQuery() string { return deleteQuery() }
+
+func (mw TOMaintenanceWindow) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+// Implementation of the Identifier, Validator interface functions
+func (mw TOMaintenanceWindow) GetKeys() (map[string]interface{}, bool) {
+	if mw.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *mw.ID}, true
+}
+
+func (mw TOMaintenanceWindow) GetAuditName() string {
+	if mw.ID != nil {
+		return strconv.Itoa(*mw.ID)
+	}
+	return "0"
+}
+
+func (mw TOMaintenanceWindow) GetType() string {
+	return "maintenance_window"
+}
+
+func (mw *TOMaintenanceWindow) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	mw.ID = &i
+}
+
+// Validate fulfills the api.Validator interface
+func (mw TOMaintenanceWindow) Validate() error {
+	errs := validation.Errors{
+		"scope": validation.Validate(mw.Scope, validation.Required, validation.In(
+			tc.MaintenanceWindowScopeServer,
+			tc.MaintenanceWindowScopeCachegroup,
+			tc.MaintenanceWindowScopeTopology,
+		)),
+		"startTime": validation.Validate(mw.StartTime, validation.Required),
+		"endTime":   validation.Validate(mw.EndTime, validation.Required),
+		"reason":    validation.Validate(mw.Reason, validation.Required),
+	}
+	if mw.Scope != nil {
+		targets := map[tc.MaintenanceWindowScope]struct {
+			name string
+			set  bool
+		}{
+			tc.MaintenanceWindowScopeServer:     {"serverId", mw.ServerID != nil},
+			tc.MaintenanceWindowScopeCachegroup: {"cachegroupId", mw.CachegroupID != nil},
+			tc.MaintenanceWindowScopeTopology:   {"topology", mw.Topology != nil},
+		}
+		for scope, target := range targets {
+			if scope == *mw.Scope && !target.set {
+				errs[target.name] = errors.New("required for scope '" + string(scope) + "'")
+			} else if scope != *mw.Scope && target.set {
+				errs[target.name] = errors.New("must not be set for scope '" + string(*mw.Scope) + "'")
+			}
+		}
+	}
+	if mw.StartTime != nil && mw.EndTime != nil && !mw.EndTime.After(*mw.StartTime) {
+		errs["endTime"] = errors.New("must be after startTime")
+	}
+	return util.JoinErrs(tovalidate.ToErrors(errs))
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the tenants of the
+// servers, cachegroups and topologies the window covers - both the window as it exists, if it does, and as requested,
+// since a window over a cachegroup or topology takes down all of the servers in it.
+func (mw *TOMaintenanceWindow) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
+	id := 0
+	if mw.ID != nil {
+		id = *mw.ID
+	}
+	return tenant.AreOptionalResourcesAuthorizedToUserTx(targetTenantsQuery(), []interface{}{id, mw.ServerID, mw.CachegroupID, mw.Topology}, user, mw.APIInfo().Tx.Tx)
+}
+
+// targetTenantsQuery selects the tenants of the servers, cachegroups and topologies covered by the window with the ID
+// $1, if any, and by a window over the server with the ID $2, the cachegroup with the ID $3, or the topology named $4.
+func targetTenantsQuery() string {
+	return `
+WITH targets AS (
+	SELECT server, cachegroup, topology FROM maintenance_window WHERE id = $1
+	UNION ALL
+	SELECT $2::bigint, $3::bigint, $4::text
+), target_cachegroups AS (
+	SELECT cachegroup AS id FROM targets
+	UNION
+	SELECT cg.id FROM cachegroup cg
+	JOIN topology_cachegroup tc ON tc.cachegroup = cg.name
+	WHERE tc.topology IN (SELECT topology FROM targets)
+)
+SELECT tenant_id FROM server WHERE id IN (SELECT server FROM targets)
+UNION
+SELECT tenant_id FROM topology WHERE name IN (SELECT topology FROM targets)
+UNION
+SELECT tenant_id FROM cachegroup WHERE id IN (SELECT id FROM target_cachegroups)
+UNION
+SELECT tenant_id FROM server WHERE cachegroup IN (SELECT id FROM target_cachegroups)
+`
+}
+
+func (mw *TOMaintenanceWindow) Create() (error, error, int) {
+	if userErr, sysErr, errCode := mw.checkConflicts(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	return api.GenericCreate(mw)
+}
+
+// Read returns the windows whose server, cachegroup or topology the user is authorized on.
+func (mw *TOMaintenanceWindow) Read(h http.Header, useIMS bool) ([]interface{}, error, error, int, *time.Time) {
+	windows := []interface{}{}
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(mw.APIInfo().Params, mw.ParamColumns())
+	if len(errs) > 0 {
+		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest, nil
+	}
+	maxTime := time.Time{}
+	if useIMS {
+		runSecond := false
+		runSecond, maxTime = api.TryIfModifiedSinceQuery(mw, h, where, orderBy, pagination, queryValues)
+		if !runSecond {
+			log.Debugln("IMS HIT")
+			return windows, nil, nil, http.StatusNotModified, &maxTime
+		}
+		log.Debugln("IMS MISS")
+	} else {
+		log.Debugln("Non IMS request")
+	}
+
+	tenantIDs, err := tenant.GetUserTenantIDsTx(*mw.APIInfo().User, mw.APIInfo().Tx.Tx)
+	if err != nil {
+		return nil, nil, errors.New("maintenance window read: getting user tenants: " + err.Error()), http.StatusInternalServerError, nil
+	}
+	for _, column := range []string{"s.tenant_id", "cg.tenant_id", "tp.tenant_id"} {
+		where, queryValues = dbhelpers.AddOptionalTenancyCheck(where, queryValues, column, tenantIDs)
+	}
+
+	rows, err := mw.APIInfo().Tx.NamedQuery(selectQuery()+where+orderBy+pagination, queryValues)
+	if err != nil {
+		return nil, nil, errors.New("maintenance window read: querying: " + err.Error()), http.StatusInternalServerError, nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		window := tc.MaintenanceWindowNullable{}
+		if err := rows.StructScan(&window); err != nil {
+			return nil, nil, errors.New("maintenance window read: scanning: " + err.Error()), http.StatusInternalServerError, nil
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil, nil, http.StatusOK, &maxTime
+}
+
+func (v *TOMaintenanceWindow) SelectMaxLastUpdatedQuery(where, orderBy, pagination, tableName string) string {
+	return `SELECT max(t) from (
+		SELECT max(mw.last_updated) as t from ` + tableName + ` mw ` + where + orderBy + pagination +
+		` UNION ALL
+	select max(last_updated) as t from last_deleted l where l.table_name='` + tableName + `') as res`
+}
+
+func (mw *TOMaintenanceWindow) Update() (error, error, int) {
+	if userErr, sysErr, errCode := mw.checkConflicts(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	return api.GenericUpdate(mw)
+}
+
+func (mw *TOMaintenanceWindow) Delete() (error, error, int) { return api.GenericDelete(mw) }
+
+// checkConflicts returns a user error if the maintenance window would leave too many of the caches of a cachegroup
+// unavailable at once, per the maintenance_max_cachegroup_down_percent configuration option.
+//
+// Windows over a cachegroup or topology are expanded to the caches they cover, and each cachegroup containing any of
+// them is checked. The check is conservative, in that every other window overlapping this one is counted as if they
+// were all in effect at once.
+func (mw *TOMaintenanceWindow) checkConflicts() (error, error, int) {
+	if mw.Scope == nil {
+		return nil, nil, http.StatusOK
+	}
+	maxPercent := config.MaintenanceMaxCachegroupDownPercentDefault
+	if cfg := mw.APIInfo().Config; cfg != nil && cfg.MaintenanceMaxCachegroupDownPercent != 0 {
+		maxPercent = cfg.MaintenanceMaxCachegroupDownPercent
+	}
+	id := 0
+	if mw.ID != nil {
+		id = *mw.ID
+	}
+	tx := mw.APIInfo().Tx.Tx
+	if *mw.Scope == tc.MaintenanceWindowScopeServer && mw.ServerID != nil {
+		if _, ok, err := dbhelpers.GetServerNameFromID(tx, *mw.ServerID); err != nil {
+			return nil, errors.New("checking maintenance window server existence: " + err.Error()), http.StatusInternalServerError
+		} else if !ok {
+			return fmt.Errorf("no server with id %d", *mw.ServerID), nil, http.StatusBadRequest
+		}
+	}
+	return checkTargetConflicts(tx, id, mw.ServerID, mw.CachegroupID, mw.Topology, *mw.StartTime, *mw.EndTime, maxPercent)
+}
+
+// checkTargetConflicts checks the limit of unavailable caches in every cachegroup with a cache covered by a window,
+// with the ID windowID, over the given server, cachegroup or topology, of which exactly one must be non-nil.
+func checkTargetConflicts(tx *sql.Tx, windowID int, serverID *int, cachegroupID *int, topology *string, start time.Time, end time.Time, maxPercent int) (error, error, int) {
+	rows, err := tx.Query(conflictQuery(), serverID, cachegroupID, topology, start, end, windowID)
+	if err != nil {
+		return nil, errors.New("checking maintenance window conflicts: " + err.Error()), http.StatusInternalServerError
+	}
+	defer rows.Close()
+	for rows.Next() {
+		cachegroup := ""
+		total := 0
+		down := 0
+		if err := rows.Scan(&cachegroup, &total, &down); err != nil {
+			return nil, errors.New("checking maintenance window conflicts: scanning: " + err.Error()), http.StatusInternalServerError
+		}
+		allowed := total * maxPercent / 100
+		if allowed < 1 {
+			allowed = 1
+		}
+		if down > allowed {
+			return fmt.Errorf("maintenance window would leave %d of the %d caches in cachegroup '%s' unavailable, which exceeds the maximum of %d (%d%%)", down, total, cachegroup, allowed, maxPercent), nil, http.StatusBadRequest
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("checking maintenance window conflicts: iterating: " + err.Error()), http.StatusInternalServerError
+	}
+	return nil, nil, http.StatusOK
+}
+
+// conflictQuery selects, for each cachegroup with a cache covered by a window over the server $1, the cachegroup $2
+// or the topology $3, its name, how many caches are in it, and how many of them would be unavailable at some point
+// during the time between $4 and $5 if the window were in effect then - ignoring the window with the ID $6, which is
+// the one being updated, if any.
+//
+// Caches are unavailable if they are covered by the window, are ADMIN_DOWN or OFFLINE, or are covered by another
+// overlapping window, whether over the cache itself, its cachegroup, or a topology containing its cachegroup.
+func conflictQuery() string {
+	return `
+WITH caches AS (
+	SELECT s.id, s.cachegroup, cg.name AS cachegroup_name, st.name AS status
+	FROM server s
+	JOIN type t ON t.id = s.type
+	JOIN status st ON st.id = s.status
+	JOIN cachegroup cg ON cg.id = s.cachegroup
+	WHERE t.name LIKE '` + tc.EdgeTypePrefix + `%' OR t.name LIKE '` + tc.MidTypePrefix + `%'
+), targets AS (
+	SELECT caches.id FROM caches
+	WHERE caches.id = $1::bigint
+	OR caches.cachegroup = $2::bigint
+	OR caches.cachegroup_name IN (SELECT tc.cachegroup FROM topology_cachegroup tc WHERE tc.topology = $3::text)
+), overlapping AS (
+	SELECT mw.server, mw.cachegroup, mw.topology FROM maintenance_window mw
+	WHERE mw.id <> $6 AND mw.start_time < $5 AND mw.end_time > $4
+)
+SELECT
+	caches.cachegroup_name,
+	COUNT(*),
+	COUNT(*) FILTER (WHERE caches.id IN (SELECT id FROM targets)
+		OR caches.status IN ('` + string(tc.CacheStatusAdminDown) + `', '` + string(tc.CacheStatusOffline) + `')
+		OR caches.id IN (SELECT server FROM overlapping WHERE server IS NOT NULL)
+		OR caches.cachegroup IN (SELECT cachegroup FROM overlapping WHERE cachegroup IS NOT NULL)
+		OR caches.cachegroup_name IN (
+			SELECT tc.cachegroup FROM topology_cachegroup tc
+			WHERE tc.topology IN (SELECT topology FROM overlapping WHERE topology IS NOT NULL)))
+FROM caches
+WHERE caches.cachegroup IN (SELECT caches.cachegroup FROM caches WHERE caches.id IN (SELECT id FROM targets))
+GROUP BY caches.cachegroup_name
+ORDER BY caches.cachegroup_name
+`
+}
+
+func selectQuery() string {
+	query := `SELECT
+mw.id,
+mw.scope,
+mw.server,
+s.host_name AS server_host_name,
+mw.cachegroup,
+cg.name AS cachegroup_name,
+mw.topology,
+mw.start_time,
+mw.end_time,
+mw.reason,
+(now() >= mw.start_time AND now() < mw.end_time) AS active,
+mw.last_updated
+
+FROM maintenance_window mw
+LEFT JOIN server s ON s.id = mw.server
+LEFT JOIN cachegroup cg ON cg.id = mw.cachegroup
+LEFT JOIN topology tp ON tp.name = mw.topology`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE
+maintenance_window SET
+scope=:scope,
+server=:server,
+cachegroup=:cachegroup,
+topology=:topology,
+start_time=:start_time,
+end_time=:end_time,
+reason=:reason
+WHERE id=:id RETURNING last_updated`
+	return query
+}
+
+func insertQuery() string {
+	query := `INSERT INTO maintenance_window (
+scope,
+server,
+cachegroup,
+topology,
+start_time,
+end_time,
+reason) VALUES (
+:scope,
+:server,
+:cachegroup,
+:topology,
+:start_time,
+:end_time,
+:reason) RETURNING id,last_updated`
+	return query
+}
+
+func deleteQuery() string {
+	return `DELETE FROM maintenance_window WHERE id = :id`
+}
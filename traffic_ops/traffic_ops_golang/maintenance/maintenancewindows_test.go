@@ -0,0 +1,142 @@
+package maintenance
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestValidate(t *testing.T) {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	scope := tc.MaintenanceWindowScopeServer
+
+	mw := TOMaintenanceWindow{}
+	mw.Scope = &scope
+	mw.ServerID = util.IntPtr(1)
+	mw.StartTime = &start
+	mw.EndTime = &end
+	mw.Reason = util.StrPtr("disk replacement")
+	if err := mw.Validate(); err != nil {
+		t.Errorf("expected valid server maintenance window, got error: %v", err)
+	}
+
+	mw.CachegroupID = util.IntPtr(2)
+	if err := mw.Validate(); err == nil || !strings.Contains(err.Error(), "cachegroupId") {
+		t.Errorf("expected error about cachegroupId on a server maintenance window, got: %v", err)
+	}
+
+	mw.ServerID = nil
+	mw.CachegroupID = nil
+	if err := mw.Validate(); err == nil || !strings.Contains(err.Error(), "serverId") {
+		t.Errorf("expected error about missing serverId, got: %v", err)
+	}
+
+	mw.ServerID = util.IntPtr(1)
+	mw.EndTime = &start
+	if err := mw.Validate(); err == nil || !strings.Contains(err.Error(), "endTime") {
+		t.Errorf("expected error about endTime not after startTime, got: %v", err)
+	}
+
+	badScope := tc.MaintenanceWindowScope("region")
+	mw.Scope = &badScope
+	mw.EndTime = &end
+	if err := mw.Validate(); err == nil || !strings.Contains(err.Error(), "scope") {
+		t.Errorf("expected error about invalid scope, got: %v", err)
+	}
+}
+
+func TestCheckTargetConflicts(t *testing.T) {
+	type cachegroup struct {
+		total int
+		down  int
+	}
+	type testCase struct {
+		name        string
+		cachegroups []cachegroup
+		maxPercent  int
+		expectErr   bool
+	}
+	testCases := []testCase{
+		{name: "within limit", cachegroups: []cachegroup{{total: 10, down: 5}}, maxPercent: 50, expectErr: false},
+		{name: "over limit", cachegroups: []cachegroup{{total: 10, down: 6}}, maxPercent: 50, expectErr: true},
+		{name: "one always allowed", cachegroups: []cachegroup{{total: 1, down: 1}}, maxPercent: 50, expectErr: false},
+		{name: "two of three", cachegroups: []cachegroup{{total: 3, down: 2}}, maxPercent: 50, expectErr: true},
+		{name: "no caches", cachegroups: nil, maxPercent: 50, expectErr: false},
+		{name: "every cachegroup within limit", cachegroups: []cachegroup{{total: 4, down: 2}, {total: 10, down: 1}}, maxPercent: 50, expectErr: false},
+		{name: "one cachegroup over limit", cachegroups: []cachegroup{{total: 4, down: 2}, {total: 10, down: 10}}, maxPercent: 50, expectErr: true},
+	}
+
+	start := time.Now()
+	end := start.Add(time.Hour)
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer mockDB.Close()
+
+			mock.ExpectBegin()
+			rows := sqlmock.NewRows([]string{"cachegroup_name", "total", "down"})
+			for i, cg := range c.cachegroups {
+				rows.AddRow("cg"+strconv.Itoa(i), cg.total, cg.down)
+			}
+			mock.ExpectQuery("SELECT").WithArgs(nil, 42, nil, start, end, 7).WillReturnRows(rows)
+			mock.ExpectCommit()
+
+			tx, err := mockDB.Begin()
+			if err != nil {
+				t.Fatalf("creating transaction: %v", err)
+			}
+			userErr, sysErr, code := checkTargetConflicts(tx, 7, nil, util.IntPtr(42), nil, start, end, c.maxPercent)
+			if sysErr != nil {
+				t.Fatalf("unexpected system error: %v", sysErr)
+			}
+			if c.expectErr && (userErr == nil || code != http.StatusBadRequest) {
+				t.Errorf("expected a conflict error with code 400, got error %v and code %d", userErr, code)
+			} else if !c.expectErr && userErr != nil {
+				t.Errorf("expected no conflict, got: %v", userErr)
+			}
+			tx.Commit()
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestEffectiveStatusSQL(t *testing.T) {
+	q := EffectiveStatusSQL("me", "status.name")
+	for _, expected := range []string{"mw.server = me.id", "mw.cachegroup = me.cachegroup", "THEN 'ADMIN_DOWN' ELSE status.name END"} {
+		if !strings.Contains(q, expected) {
+			t.Errorf("expected effective status SQL to contain '%s', actual: %s", expected, q)
+		}
+	}
+}
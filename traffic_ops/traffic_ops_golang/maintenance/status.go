@@ -0,0 +1,47 @@
+package maintenance
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// InMaintenanceSQL returns an SQL boolean expression which is true if the server with the given table alias is covered
+// by a maintenance window at the time the query is run, whether directly, through its cachegroup, or through a
+// topology including its cachegroup.
+func InMaintenanceSQL(serverAlias string) string {
+	return `EXISTS (
+	SELECT 1 FROM maintenance_window mw
+	WHERE now() >= mw.start_time AND now() < mw.end_time
+	AND (mw.server = ` + serverAlias + `.id
+		OR mw.cachegroup = ` + serverAlias + `.cachegroup
+		OR mw.topology IN (
+			SELECT tc.topology FROM topology_cachegroup tc
+			JOIN cachegroup mcg ON mcg.name = tc.cachegroup
+			WHERE mcg.id = ` + serverAlias + `.cachegroup)))`
+}
+
+// EffectiveStatusSQL returns an SQL expression for the status of the server with the given table alias, as it should
+// be reported to Traffic Monitor and Traffic Router: its status column, unless that is ONLINE or REPORTED and the
+// server is in maintenance, in which case ADMIN_DOWN.
+func EffectiveStatusSQL(serverAlias string, statusColumn string) string {
+	return `(CASE WHEN ` + statusColumn + ` IN ('` + string(tc.CacheStatusOnline) + `', '` + string(tc.CacheStatusReported) + `') AND ` + InMaintenanceSQL(serverAlias) +
+		` THEN '` + string(tc.CacheStatusAdminDown) + `' ELSE ` + statusColumn + ` END)`
+}
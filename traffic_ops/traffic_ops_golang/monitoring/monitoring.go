@@ -30,6 +30,7 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/maintenance"
 
 	"github.com/lib/pq"
 )
@@ -184,7 +185,7 @@ func getMonitoringServers(tx *sql.Tx, cdn string) ([]Monitor, []Cache, []Router,
 SELECT
 	me.host_name as hostName,
 	CONCAT(me.host_name, '.', me.domain_name) as fqdn,
-	status.name as status,
+	` + maintenance.EffectiveStatusSQL("me", "status.name") + ` as status,
 	cachegroup.name as cachegroup,
 	me.tcp_port as port,
 	profile.name as profile,
@@ -0,0 +1,283 @@
+// Package openapi generates OpenAPI 3 documents describing the Traffic Ops API from its route table and the Go types
+// its handlers accept and return.
+package openapi
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+)
+
+const securitySchemeName = "cookieAuth"
+
+// Route is the information about a Traffic Ops API route needed to document it.
+type Route struct {
+	Version       api.Version
+	Method        string
+	Path          string
+	PrivLevel     int
+	Authenticated bool
+	ID            int
+	Types
+}
+
+// Types are the Go types of the payloads of a route. Any of them may be nil, if the route has no such payload, or it
+// isn't (yet) known.
+type Types struct {
+	// Request is a value of the type decoded from the request body.
+	Request interface{}
+	// Required are the properties of Request which its handler rejects when missing.
+	Required []string
+	// Response is a value of the type written in the "response" property of a successful response.
+	Response interface{}
+	// Unwrapped indicates that Response is the entire body of a successful response, rather than being wrapped in a
+	// "response" property.
+	Unwrapped bool
+	// Summary indicates that a successful response has a "summary" property holding the total count of objects, as
+	// written by api.WriteRespWithSummary.
+	Summary bool
+}
+
+// Spec holds the OpenAPI document of each major version of the API.
+type Spec struct {
+	m    sync.RWMutex
+	docs map[uint64]tc.OpenAPIDocument
+}
+
+// NewSpec returns a Spec documenting no routes. Routes are documented by calling Load.
+func NewSpec() *Spec {
+	return &Spec{docs: map[uint64]tc.OpenAPIDocument{}}
+}
+
+// Load generates the documents of every major API version served by the given routes, replacing any previously
+// loaded documents.
+func (s *Spec) Load(routes []Route) {
+	majors := map[uint64][]Route{}
+	for _, r := range routes {
+		majors[r.Version.Major] = append(majors[r.Version.Major], r)
+	}
+	docs := make(map[uint64]tc.OpenAPIDocument, len(majors))
+	for major, majorRoutes := range majors {
+		docs[major] = Generate(major, majorRoutes)
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.docs = docs
+}
+
+// Get returns the document of the given major API version, and whether it exists.
+func (s *Spec) Get(major uint64) (tc.OpenAPIDocument, bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	doc, ok := s.docs[major]
+	return doc, ok
+}
+
+// Handler is the handler for GET /api/{version}/openapi.json, which serves the document of the requested major API
+// version.
+func (s *Spec) Handler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	if inf.Version == nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("an API version is required"), nil)
+		return
+	}
+	doc, ok := s.Get(inf.Version.Major)
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no document for API version "+strconv.FormatUint(inf.Version.Major, 10)), nil)
+		return
+	}
+	api.WriteRespRaw(w, r, doc)
+}
+
+// Generate returns the OpenAPI document of the given major API version, describing the given routes. Routes of other
+// major versions are ignored.
+//
+// As with the router, when more than one route has the same method and path, only the first is documented, because
+// only the first is ever served.
+func Generate(major uint64, routes []Route) tc.OpenAPIDocument {
+	gen := newSchemaGenerator()
+	alertsSchema := gen.Schema(tc.Alerts{})
+
+	minors := map[uint64]struct{}{}
+	paths := map[string]tc.OpenAPIPathItem{}
+	for _, r := range routes {
+		if r.Version.Major != major {
+			continue
+		}
+		minors[r.Version.Minor] = struct{}{}
+
+		path := PathTemplate(r.Path)
+		method := strings.ToLower(r.Method)
+		if _, ok := paths[path]; !ok {
+			paths[path] = tc.OpenAPIPathItem{}
+		}
+		if _, ok := paths[path][method]; ok {
+			continue
+		}
+		paths[path][method] = operation(gen, r, path, alertsSchema)
+	}
+
+	versions := []string{}
+	for minor := range minors {
+		versions = append(versions, versionString(api.Version{Major: major, Minor: minor}))
+	}
+	sort.Slice(versions, func(i, j int) bool { return minorOf(versions[i]) < minorOf(versions[j]) })
+	latest := ""
+	if len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+
+	return tc.OpenAPIDocument{
+		OpenAPI: tc.OpenAPIVersion,
+		Info: tc.OpenAPIInfo{
+			Title:       "Traffic Ops API",
+			Description: "The Traffic Ops API, version " + strconv.FormatUint(major, 10) + ". Generated from the Traffic Ops route table.",
+			Version:     latest,
+		},
+		Servers: []tc.OpenAPIServer{{
+			URL:       "/api/{version}",
+			Variables: map[string]tc.OpenAPIServerVariable{"version": {Default: latest, Enum: versions}},
+		}},
+		Paths: paths,
+		Components: tc.OpenAPIComponents{
+			Schemas: gen.components,
+			SecuritySchemes: map[string]tc.OpenAPISecurityScheme{
+				securitySchemeName: {Type: "apiKey", In: "cookie", Name: tocookie.Name},
+			},
+		},
+	}
+}
+
+func operation(gen *schemaGenerator, r Route, path string, alertsSchema *tc.OpenAPISchema) tc.OpenAPIOperation {
+	op := tc.OpenAPIOperation{
+		OperationID: operationID(r.Method, path),
+		Tags:        []string{strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]},
+		Parameters:  pathParameters(path),
+		Responses: map[string]tc.OpenAPIResponse{
+			"default": {
+				Description: "Error",
+				Content:     map[string]tc.OpenAPIMediaType{rfc.ApplicationJSON: {Schema: alertsSchema}},
+			},
+		},
+		Security:  []map[string][]string{},
+		RouteID:   r.ID,
+		PrivLevel: r.PrivLevel,
+		Since:     versionString(r.Version),
+	}
+	if r.Authenticated {
+		op.Security = []map[string][]string{{securitySchemeName: {}}}
+		op.Responses[strconv.Itoa(http.StatusUnauthorized)] = tc.OpenAPIResponse{Description: "Unauthorized"}
+		op.Responses[strconv.Itoa(http.StatusForbidden)] = tc.OpenAPIResponse{Description: "Forbidden"}
+	}
+
+	if r.Request != nil {
+		reqSchema := gen.Schema(r.Request)
+		if obj := gen.inlineObjectSchema(r.Request); obj != nil {
+			// Required properties belong to the handler's type, not the type it embeds, so they can't be shared.
+			obj.Required = append([]string(nil), r.Required...)
+			sort.Strings(obj.Required)
+			reqSchema = obj
+		}
+		op.RequestBody = &tc.OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]tc.OpenAPIMediaType{rfc.ApplicationJSON: {Schema: reqSchema}},
+		}
+	}
+
+	success := tc.OpenAPIResponse{Description: "Success"}
+	if r.Response != nil {
+		respSchema := gen.Schema(r.Response)
+		if !r.Unwrapped {
+			respSchema = &tc.OpenAPISchema{
+				Type: "object",
+				Properties: map[string]*tc.OpenAPISchema{
+					"response": respSchema,
+					"alerts":   gen.resolve(alertsSchema).Properties["alerts"],
+				},
+				Required: []string{"response"},
+			}
+			if r.Summary {
+				respSchema.Properties["summary"] = &tc.OpenAPISchema{
+					Type:       "object",
+					Properties: map[string]*tc.OpenAPISchema{"count": {Type: "integer", Format: "int64"}},
+				}
+			}
+		}
+		success.Content = map[string]tc.OpenAPIMediaType{rfc.ApplicationJSON: {Schema: respSchema}}
+	}
+	op.Responses[strconv.Itoa(http.StatusOK)] = success
+	return op
+}
+
+var pathRegexCleaner = strings.NewReplacer(`(\.json)?`, ``, `(/|\.json)?`, ``, `/?`, ``, `?`, ``, `$`, ``, `\.`, `.`)
+
+// PathTemplate converts the path regular expression of a route to an OpenAPI path template.
+func PathTemplate(routePath string) string {
+	path := pathRegexCleaner.Replace(routePath)
+	path = strings.TrimSuffix(path, "/")
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+func pathParameters(path string) []tc.OpenAPIParameter {
+	params := []tc.OpenAPIParameter{}
+	for _, match := range pathParamRegex.FindAllStringSubmatch(path, -1) {
+		schema := &tc.OpenAPISchema{Type: "string"}
+		if match[1] == "id" {
+			schema = &tc.OpenAPISchema{Type: "integer", Format: "int64"}
+		}
+		params = append(params, tc.OpenAPIParameter{Name: match[1], In: "path", Required: true, Schema: schema})
+	}
+	return params
+}
+
+var operationIDCleaner = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func operationID(method string, path string) string {
+	return strings.ToLower(method) + strings.TrimSuffix(operationIDCleaner.ReplaceAllString(path, "_"), "_")
+}
+
+func versionString(v api.Version) string {
+	return strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10)
+}
+
+func minorOf(version string) uint64 {
+	minor, _ := strconv.ParseUint(version[strings.Index(version, ".")+1:], 10, 64)
+	return minor
+}
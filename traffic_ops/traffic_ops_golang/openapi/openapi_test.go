@@ -0,0 +1,176 @@
+package openapi
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+func TestPathTemplate(t *testing.T) {
+	expected := map[string]string{
+		`asns/?$`:                            `/asns`,
+		`asns/{id}$`:                         `/asns/{id}`,
+		`cachegroups/?(\.json)?$`:            `/cachegroups`,
+		`capabilities(/|\.json)?$`:           `/capabilities`,
+		`cdns/dnsseckeys/generate?$`:         `/cdns/dnsseckeys/generate`,
+		`dbdump/?`:                           `/dbdump`,
+		`deliveryservice_stats`:              `/deliveryservice_stats`,
+		`cdns/{cdn}/configs/monitoring?$`:    `/cdns/{cdn}/configs/monitoring`,
+		`ats/regex_revalidate\.config/?$`:    `/ats/regex_revalidate.config`,
+		`steering/{deliveryservice}/targets`: `/steering/{deliveryservice}/targets`,
+	}
+	for routePath, expectedPath := range expected {
+		if actual := PathTemplate(routePath); actual != expectedPath {
+			t.Errorf("expected path template of '%s' to be '%s', actual: '%s'", routePath, expectedPath, actual)
+		}
+	}
+}
+
+type testEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testObject struct {
+	testEmbedded
+	Name        *string        `json:"name"`
+	Count       int            `json:"count"`
+	Ratio       float64        `json:"ratio"`
+	Enabled     bool           `json:"enabled,omitempty"`
+	Tags        []string       `json:"tags"`
+	Values      map[string]int `json:"values"`
+	LastUpdated *tc.TimeNoMod  `json:"lastUpdated"`
+	Child       *testObject    `json:"child"`
+	Quoted      int            `json:"quoted,string"`
+	Ignored     string         `json:"-"`
+	unexported  string         // nolint
+	NoTag       tc.LocalizationMethod
+}
+
+func TestSchema(t *testing.T) {
+	gen := newSchemaGenerator()
+	ref := gen.Schema(testObject{})
+	if ref.Ref != componentSchemaPrefix+"openapi.testObject" {
+		t.Fatalf("expected a reference to the openapi.testObject component, actual: %+v", *ref)
+	}
+	s := gen.resolve(ref)
+	if s == nil {
+		t.Fatal("expected openapi.testObject component to be generated, actual: not found")
+	}
+
+	expected := map[string]tc.OpenAPISchema{
+		"embedded":    {Type: "string"},
+		"name":        {Type: "string", Nullable: true},
+		"count":       {Type: "integer", Format: "int64"},
+		"ratio":       {Type: "number", Format: "double"},
+		"enabled":     {Type: "boolean"},
+		"tags":        {Type: "array", Items: &tc.OpenAPISchema{Type: "string"}, Nullable: true},
+		"values":      {Type: "object", AdditionalProperties: &tc.OpenAPISchema{Type: "integer", Format: "int64"}, Nullable: true},
+		"lastUpdated": {Type: "string", Nullable: true},
+		"child":       {AllOf: []*tc.OpenAPISchema{ref}, Nullable: true},
+		"quoted":      {Type: "string"},
+		"NoTag":       {Type: "string"},
+	}
+	if len(s.Properties) != len(expected) {
+		t.Errorf("expected %d properties, actual: %d", len(expected), len(s.Properties))
+	}
+	for name, expectedProp := range expected {
+		prop, ok := s.Properties[name]
+		if !ok {
+			t.Errorf("expected property '%s', actual: missing", name)
+			continue
+		}
+		if !reflect.DeepEqual(*prop, expectedProp) {
+			t.Errorf("expected property '%s' to be %+v, actual: %+v", name, expectedProp, *prop)
+		}
+	}
+}
+
+type testRequest struct {
+	api.APIInfoImpl `json:"-"`
+	testEmbedded
+	Name *string `json:"name"`
+}
+
+func TestGenerate(t *testing.T) {
+	routes := []Route{
+		{api.Version{Major: 3, Minor: 0}, http.MethodGet, `widgets/?$`, 10, true, 1, Types{Response: []testObject{}}},
+		{api.Version{Major: 3, Minor: 0}, http.MethodPost, `widgets/?$`, 20, true, 2, Types{Request: &testRequest{}, Required: []string{"name"}, Response: testObject{}}},
+		{api.Version{Major: 3, Minor: 0}, http.MethodPut, `widgets/{id}$`, 20, true, 3, Types{Request: &testRequest{}}},
+		{api.Version{Major: 3, Minor: 1}, http.MethodGet, `ping$`, 0, false, 4, Types{Response: map[string]string{}, Unwrapped: true}},
+		{api.Version{Major: 3, Minor: 1}, http.MethodGet, `widgets/?$`, 10, true, 5, Types{}},
+		{api.Version{Major: 2, Minor: 0}, http.MethodGet, `gadgets/?$`, 10, true, 6, Types{}},
+	}
+	doc := Generate(3, routes)
+
+	if doc.OpenAPI != tc.OpenAPIVersion {
+		t.Errorf("expected OpenAPI version %s, actual: %s", tc.OpenAPIVersion, doc.OpenAPI)
+	}
+	if doc.Info.Version != "3.1" {
+		t.Errorf("expected latest version 3.1, actual: %s", doc.Info.Version)
+	}
+	if versions := doc.Servers[0].Variables["version"].Enum; !reflect.DeepEqual(versions, []string{"3.0", "3.1"}) {
+		t.Errorf("expected versions [3.0 3.1], actual: %v", versions)
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatalf("expected 3 paths, actual: %d", len(doc.Paths))
+	}
+
+	get := doc.Paths["/widgets"]["get"]
+	if get.RouteID != 1 {
+		t.Errorf("expected the first route of a method and path to be documented, actual: route %d", get.RouteID)
+	}
+	if get.PrivLevel != 10 || get.Since != "3.0" || len(get.Security) != 1 {
+		t.Errorf("expected route information to be documented, actual: %+v", get)
+	}
+	resp := get.Responses["200"].Content["application/json"].Schema
+	if resp.Properties["response"].Items.Ref != componentSchemaPrefix+"openapi.testObject" {
+		t.Errorf("expected a wrapped array of openapi.testObject response, actual: %+v", resp)
+	}
+
+	post := doc.Paths["/widgets"]["post"]
+	req := post.RequestBody.Content["application/json"].Schema
+	if !reflect.DeepEqual(req.Required, []string{"name"}) {
+		t.Errorf("expected declared required property 'name', actual: %v", req.Required)
+	}
+
+	put := doc.Paths["/widgets/{id}"]["put"]
+	if len(put.Parameters) != 1 || put.Parameters[0].Name != "id" || put.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("expected an integer 'id' path parameter, actual: %+v", put.Parameters)
+	}
+	if len(put.RequestBody.Content["application/json"].Schema.Required) != 0 {
+		t.Error("expected no required properties when none are declared")
+	}
+
+	ping := doc.Paths["/ping"]["get"]
+	if len(ping.Security) != 0 {
+		t.Errorf("expected unauthenticated route to have no security requirements, actual: %v", ping.Security)
+	}
+	if ping.Since != "3.1" {
+		t.Errorf("expected route to be available since 3.1, actual: %s", ping.Since)
+	}
+	if ping.Responses["200"].Content["application/json"].Schema.Type != "object" {
+		t.Errorf("expected unwrapped object response, actual: %+v", ping.Responses["200"])
+	}
+}
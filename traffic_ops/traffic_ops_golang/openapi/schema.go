@@ -0,0 +1,226 @@
+package openapi
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// stringTypes are types which marshal to JSON strings via custom marshallers.
+var stringTypes = map[reflect.Type]string{
+	reflect.TypeOf(time.Time{}):        "date-time",
+	reflect.TypeOf(tc.Time{}):          "",
+	reflect.TypeOf(tc.TimeNoMod{}):     "",
+	reflect.TypeOf(rfc.URL{}):          "uri",
+	reflect.TypeOf(rfc.EmailAddress{}): "email",
+	reflect.TypeOf(net.IP{}):           "",
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// schemaGenerator builds OpenAPI schemas from Go types, collecting named struct types as reusable components.
+type schemaGenerator struct {
+	components map[string]*tc.OpenAPISchema
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{components: map[string]*tc.OpenAPISchema{}}
+}
+
+// Schema returns the schema of the JSON encoding of values of the type of v.
+func (g *schemaGenerator) Schema(v interface{}) *tc.OpenAPISchema {
+	if v == nil {
+		return &tc.OpenAPISchema{}
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *tc.OpenAPISchema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	s := g.nonNullSchema(t)
+	if !nullable {
+		return s
+	}
+	if s.Ref != "" {
+		// OpenAPI 3.0 ignores the siblings of a $ref, so a nullable reference must be wrapped.
+		return &tc.OpenAPISchema{AllOf: []*tc.OpenAPISchema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+func (g *schemaGenerator) nonNullSchema(t reflect.Type) *tc.OpenAPISchema {
+	if format, ok := stringTypes[t]; ok {
+		return &tc.OpenAPISchema{Type: "string", Format: format}
+	}
+	if t == rawMessageType {
+		return &tc.OpenAPISchema{}
+	}
+	if t.Kind() != reflect.Struct && (t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType)) {
+		// Enumerated types with custom marshallers are encoded as their names.
+		return &tc.OpenAPISchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &tc.OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &tc.OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &tc.OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &tc.OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &tc.OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &tc.OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &tc.OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		// nil slices are encoded as null.
+		return &tc.OpenAPISchema{Type: "array", Items: g.schema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &tc.OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	// interfaces, and anything else encoding/json can encode, may be any JSON value.
+	return &tc.OpenAPISchema{}
+}
+
+// structSchema returns a reference to the component schema of a named struct type, or the schema itself for anonymous
+// structs.
+func (g *schemaGenerator) structSchema(t reflect.Type) *tc.OpenAPISchema {
+	name := componentName(t)
+	if name == "" {
+		return g.objectSchema(t)
+	}
+	ref := &tc.OpenAPISchema{Ref: componentSchemaPrefix + name}
+	if _, ok := g.components[name]; ok {
+		return ref
+	}
+	g.components[name] = &tc.OpenAPISchema{} // placeholder, so recursive types terminate
+	*g.components[name] = *g.objectSchema(t)
+	return ref
+}
+
+// inlineObjectSchema returns the schema of the struct type of v (or to which v points) without collecting it as a
+// component, or nil if v isn't a struct.
+func (g *schemaGenerator) inlineObjectSchema(v interface{}) *tc.OpenAPISchema {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := stringTypes[t]; ok {
+		return nil
+	}
+	return g.objectSchema(t)
+}
+
+// resolve returns the component schema to which s refers, or s itself if it isn't a reference.
+func (g *schemaGenerator) resolve(s *tc.OpenAPISchema) *tc.OpenAPISchema {
+	if s.Ref == "" {
+		return s
+	}
+	return g.components[strings.TrimPrefix(s.Ref, componentSchemaPrefix)]
+}
+
+// objectSchema returns the schema of a struct type, following the encoding/json rules for field names and embedding.
+func (g *schemaGenerator) objectSchema(t reflect.Type) *tc.OpenAPISchema {
+	s := &tc.OpenAPISchema{Type: "object", Properties: map[string]*tc.OpenAPISchema{}}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *tc.OpenAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := s.Properties[name]; ok {
+			continue // shallower fields take precedence over promoted ones
+		}
+		if strings.Contains(opts, "string") {
+			s.Properties[name] = &tc.OpenAPISchema{Type: "string"}
+			continue
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+}
+
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// componentName returns the name of the component schema for a struct type, or an empty string if the type is
+// anonymous.
+func componentName(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	pkg = strings.Replace(pkg, "go-", "", 1)
+	return pkg + "." + t.Name()
+}
@@ -0,0 +1,420 @@
+// Package origingroup implements the Origin Groups of Delivery Services: the
+// Origins of a Delivery Service among which caches fail over, or distribute
+// requests by weight.
+package origingroup
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/lib/pq"
+)
+
+// DefaultHealthCheckExpectedStatus is the status a health check of an Origin
+// Group member is expected to respond with, if the group doesn't specify one.
+const DefaultHealthCheckExpectedStatus = http.StatusOK
+
+const selectGroupsQuery = `
+SELECT
+  og.deliveryservice,
+  ds.xml_id,
+  cdn.name,
+  og.policy,
+  og.health_check_path,
+  og.health_check_expected_status,
+  og.last_updated
+FROM origin_group og
+JOIN deliveryservice ds ON ds.id = og.deliveryservice
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE ds.tenant_id = ANY($1)
+AND ($2::bigint IS NULL OR og.deliveryservice = $2)
+AND ($3 = '' OR cdn.name = $3)
+ORDER BY ds.xml_id
+`
+
+const selectMembersQuery = `
+SELECT
+  m.deliveryservice,
+  m.origin,
+  o.name,
+  o.protocol,
+  o.fqdn,
+  o.port,
+  m.role,
+  m.weight
+FROM origin_group_member m
+JOIN origin o ON o.id = m.origin
+WHERE m.deliveryservice = ANY($1)
+ORDER BY m.deliveryservice, m.rank
+`
+
+const upsertGroupQuery = `
+INSERT INTO origin_group (deliveryservice, policy, health_check_path, health_check_expected_status)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (deliveryservice) DO UPDATE SET
+  policy = EXCLUDED.policy,
+  health_check_path = EXCLUDED.health_check_path,
+  health_check_expected_status = EXCLUDED.health_check_expected_status
+`
+
+const deleteMembersQuery = `DELETE FROM origin_group_member WHERE deliveryservice = $1`
+
+const insertMembersQuery = `
+INSERT INTO origin_group_member (deliveryservice, origin, role, weight, rank)
+SELECT $1, m.origin, m.role, m.weight, m.rank
+FROM UNNEST($2::bigint[], $3::text[], $4::bigint[]) WITH ORDINALITY AS m(origin, role, weight, rank)
+`
+
+const selectDSOriginsQuery = `SELECT id FROM origin WHERE deliveryservice = $1 AND id = ANY($2)`
+
+const deleteGroupQuery = `DELETE FROM origin_group WHERE deliveryservice = $1`
+
+// Get is the handler for GET requests to /origin_groups, which lists the
+// Origin Groups of the Delivery Services the user's Tenant may see,
+// optionally only those of one Delivery Service or CDN.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, []string{"deliveryServiceId"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	var dsID *int
+	if id, ok := inf.IntParams["deliveryServiceId"]; ok {
+		dsID = &id
+	}
+	tenantIDs, err := tenant.GetUserTenantIDListTx(inf.Tx.Tx, inf.User.TenantID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting user tenants: "+err.Error()))
+		return
+	}
+
+	groups, err := getOriginGroups(inf.Tx.Tx, tenantIDs, dsID, inf.Params["cdn"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting origin groups: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, groups)
+}
+
+// Put is the handler for PUT requests to /deliveryservices/{id}/origin_group,
+// which creates or replaces the Origin Group of a Delivery Service.
+func Put(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	group := tc.OriginGroup{}
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	setDefaults(&group)
+	if err := validate(group); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	dsID := inf.IntParams["id"]
+	userErr, sysErr, errCode = tenant.CheckID(inf.Tx.Tx, inf.User, dsID)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	ds, ok, err := dbhelpers.GetDSNameFromID(inf.Tx.Tx, dsID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service name from ID: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("delivery service not found"), nil)
+		return
+	}
+
+	if userErr, sysErr := checkOrigins(inf.Tx.Tx, dsID, group.Members); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, userErr, sysErr)
+		return
+	}
+	if err := putOriginGroup(inf.Tx.Tx, dsID, group); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("putting origin group: "+err.Error()))
+		return
+	}
+
+	tenantIDs, err := tenant.GetUserTenantIDListTx(inf.Tx.Tx, inf.User.TenantID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting user tenants: "+err.Error()))
+		return
+	}
+	groups, err := getOriginGroups(inf.Tx.Tx, tenantIDs, &dsID, "")
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting origin group: "+err.Error()))
+		return
+	}
+	if len(groups) != 1 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting origin group: expected 1 group, got "+strconv.Itoa(len(groups))))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "DS: "+string(ds)+", ID: "+strconv.Itoa(dsID)+", ACTION: Updated origin group", inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Origin group of delivery service "+string(ds)+" was updated.", groups[0])
+}
+
+// Delete is the handler for DELETE requests to
+// /deliveryservices/{id}/origin_group, which removes the Origin Group of a
+// Delivery Service.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	dsID := inf.IntParams["id"]
+	userErr, sysErr, errCode = tenant.CheckID(inf.Tx.Tx, inf.User, dsID)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	ds, ok, err := dbhelpers.GetDSNameFromID(inf.Tx.Tx, dsID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service name from ID: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("delivery service not found"), nil)
+		return
+	}
+
+	result, err := inf.Tx.Tx.Exec(deleteGroupQuery, dsID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting origin group: "+err.Error()))
+		return
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting origin group: getting rows affected: "+err.Error()))
+		return
+	} else if rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("delivery service "+string(ds)+" has no origin group"), nil)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "DS: "+string(ds)+", ID: "+strconv.Itoa(dsID)+", ACTION: Deleted origin group", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Origin group of delivery service "+string(ds)+" was deleted.")
+}
+
+// setDefaults sets the optional fields of a requested Origin Group which
+// weren't given to their defaults.
+func setDefaults(group *tc.OriginGroup) {
+	if group.Policy == nil {
+		policy := tc.OriginGroupPolicyFailover
+		group.Policy = &policy
+	}
+	if group.HealthCheckPath != nil && *group.HealthCheckPath == "" {
+		group.HealthCheckPath = nil
+	}
+	if group.HealthCheckPath != nil && group.HealthCheckExpectedStatus == nil {
+		group.HealthCheckExpectedStatus = util.IntPtr(DefaultHealthCheckExpectedStatus)
+	}
+	for i, m := range group.Members {
+		if m.Role == nil {
+			role := tc.OriginGroupMemberRolePrimary
+			group.Members[i].Role = &role
+		}
+		if m.Weight == nil {
+			group.Members[i].Weight = util.IntPtr(1)
+		}
+	}
+}
+
+// validate validates a requested Origin Group, after its defaults are set.
+// The returned error, if any, is suitable to be returned to the user.
+func validate(group tc.OriginGroup) error {
+	errs := validation.Errors{
+		"policy": validation.Validate(group.Policy, validation.Required, validation.In(
+			tc.OriginGroupPolicyFailover,
+			tc.OriginGroupPolicyWeighted,
+		)),
+		"members": validation.Validate(group.Members, validation.Required),
+	}
+	if group.HealthCheckPath != nil && !strings.HasPrefix(*group.HealthCheckPath, "/") {
+		errs["healthCheckPath"] = errors.New("must begin with '/'")
+	}
+	if status := group.HealthCheckExpectedStatus; status != nil {
+		if group.HealthCheckPath == nil {
+			errs["healthCheckExpectedStatus"] = errors.New("must not be set without healthCheckPath")
+		} else if *status < 100 || *status > 599 {
+			errs["healthCheckExpectedStatus"] = errors.New("must be an HTTP status between 100 and 599")
+		}
+	}
+
+	originIDs := map[int]struct{}{}
+	hasPrimary := false
+	memberErrs := []error{}
+	for i, m := range group.Members {
+		prefix := "member " + strconv.Itoa(i) + " "
+		if m.OriginID == nil {
+			memberErrs = append(memberErrs, errors.New(prefix+"originId: required"))
+		} else if _, ok := originIDs[*m.OriginID]; ok {
+			memberErrs = append(memberErrs, errors.New(prefix+"originId: origin "+strconv.Itoa(*m.OriginID)+" is already a member"))
+		} else {
+			originIDs[*m.OriginID] = struct{}{}
+		}
+		switch *m.Role {
+		case tc.OriginGroupMemberRolePrimary:
+			hasPrimary = true
+		case tc.OriginGroupMemberRoleSecondary:
+		default:
+			memberErrs = append(memberErrs, errors.New(prefix+"role: must be 'primary' or 'secondary'"))
+		}
+		if *m.Weight <= 0 {
+			memberErrs = append(memberErrs, errors.New(prefix+"weight: must be greater than 0"))
+		}
+	}
+	if len(group.Members) > 0 && !hasPrimary {
+		memberErrs = append(memberErrs, errors.New("members: must include a primary member"))
+	}
+	return util.JoinErrs(append(tovalidate.ToErrors(errs), memberErrs...))
+}
+
+// checkOrigins checks that the members of an Origin Group are Origins of the
+// Delivery Service with the given ID.
+func checkOrigins(tx *sql.Tx, dsID int, members []tc.OriginGroupMember) (error, error) {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, int64(*m.OriginID))
+	}
+	rows, err := tx.Query(selectDSOriginsQuery, dsID, pq.Array(ids))
+	if err != nil {
+		return nil, errors.New("querying delivery service origins: " + err.Error())
+	}
+	defer rows.Close()
+	found := map[int]struct{}{}
+	for rows.Next() {
+		id := 0
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.New("scanning delivery service origins: " + err.Error())
+		}
+		found[id] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating delivery service origins: " + err.Error())
+	}
+	for _, m := range members {
+		if _, ok := found[*m.OriginID]; !ok {
+			return errors.New("origin " + strconv.Itoa(*m.OriginID) + " is not an origin of the delivery service"), nil
+		}
+	}
+	return nil, nil
+}
+
+// putOriginGroup creates or replaces the Origin Group of the Delivery Service
+// with the given ID.
+func putOriginGroup(tx *sql.Tx, dsID int, group tc.OriginGroup) error {
+	if _, err := tx.Exec(upsertGroupQuery, dsID, *group.Policy, group.HealthCheckPath, group.HealthCheckExpectedStatus); err != nil {
+		return errors.New("upserting origin group: " + err.Error())
+	}
+	if _, err := tx.Exec(deleteMembersQuery, dsID); err != nil {
+		return errors.New("deleting origin group members: " + err.Error())
+	}
+	origins := make([]int64, 0, len(group.Members))
+	roles := make([]string, 0, len(group.Members))
+	weights := make([]int64, 0, len(group.Members))
+	for _, m := range group.Members {
+		origins = append(origins, int64(*m.OriginID))
+		roles = append(roles, string(*m.Role))
+		weights = append(weights, int64(*m.Weight))
+	}
+	if _, err := tx.Exec(insertMembersQuery, dsID, pq.Array(origins), pq.Array(roles), pq.Array(weights)); err != nil {
+		return errors.New("inserting origin group members: " + err.Error())
+	}
+	return nil
+}
+
+// getOriginGroups returns the Origin Groups of the Delivery Services of the
+// given Tenants, optionally only that of the Delivery Service with the given
+// ID or those of the named CDN.
+func getOriginGroups(tx *sql.Tx, tenantIDs []int, dsID *int, cdn string) ([]tc.OriginGroup, error) {
+	rows, err := tx.Query(selectGroupsQuery, pq.Array(tenantIDs), dsID, cdn)
+	if err != nil {
+		return nil, errors.New("querying origin groups: " + err.Error())
+	}
+	defer rows.Close()
+
+	groups := []tc.OriginGroup{}
+	dsIDs := []int64{}
+	for rows.Next() {
+		g := tc.OriginGroup{Members: []tc.OriginGroupMember{}}
+		if err := rows.Scan(&g.DeliveryServiceID, &g.DeliveryService, &g.CDNName, &g.Policy, &g.HealthCheckPath, &g.HealthCheckExpectedStatus, &g.LastUpdated); err != nil {
+			return nil, errors.New("scanning origin groups: " + err.Error())
+		}
+		groups = append(groups, g)
+		dsIDs = append(dsIDs, int64(*g.DeliveryServiceID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating origin groups: " + err.Error())
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	memberRows, err := tx.Query(selectMembersQuery, pq.Array(dsIDs))
+	if err != nil {
+		return nil, errors.New("querying origin group members: " + err.Error())
+	}
+	defer memberRows.Close()
+
+	members := map[int][]tc.OriginGroupMember{}
+	for memberRows.Next() {
+		id := 0
+		m := tc.OriginGroupMember{}
+		if err := memberRows.Scan(&id, &m.OriginID, &m.Origin, &m.Protocol, &m.FQDN, &m.Port, &m.Role, &m.Weight); err != nil {
+			return nil, errors.New("scanning origin group members: " + err.Error())
+		}
+		members[id] = append(members[id], m)
+	}
+	if err := memberRows.Err(); err != nil {
+		return nil, errors.New("iterating origin group members: " + err.Error())
+	}
+	for i, g := range groups {
+		if m, ok := members[*g.DeliveryServiceID]; ok {
+			groups[i].Members = m
+		}
+	}
+	return groups, nil
+}
@@ -0,0 +1,133 @@
+package origingroup
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	"github.com/lib/pq"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestValidate(t *testing.T) {
+	secondary := tc.OriginGroupMemberRoleSecondary
+	group := tc.OriginGroup{
+		HealthCheckPath: util.StrPtr("/health"),
+		Members: []tc.OriginGroupMember{
+			{OriginID: util.IntPtr(1)},
+			{OriginID: util.IntPtr(2), Role: &secondary},
+		},
+	}
+	setDefaults(&group)
+	if *group.Policy != tc.OriginGroupPolicyFailover {
+		t.Errorf("expected default policy '%s', actual: '%s'", tc.OriginGroupPolicyFailover, *group.Policy)
+	}
+	if *group.HealthCheckExpectedStatus != DefaultHealthCheckExpectedStatus {
+		t.Errorf("expected default health check status %d, actual: %d", DefaultHealthCheckExpectedStatus, *group.HealthCheckExpectedStatus)
+	}
+	if *group.Members[0].Role != tc.OriginGroupMemberRolePrimary || *group.Members[0].Weight != 1 {
+		t.Errorf("expected default member role primary and weight 1, actual: %s %d", *group.Members[0].Role, *group.Members[0].Weight)
+	}
+	if err := validate(group); err != nil {
+		t.Errorf("expected valid origin group, got error: %v", err)
+	}
+
+	group.Members[1].OriginID = util.IntPtr(1)
+	if err := validate(group); err == nil || !strings.Contains(err.Error(), "already a member") {
+		t.Errorf("expected error about duplicate member, got: %v", err)
+	}
+
+	group.Members = group.Members[1:]
+	if err := validate(group); err == nil || !strings.Contains(err.Error(), "primary member") {
+		t.Errorf("expected error about missing primary member, got: %v", err)
+	}
+
+	group.Members[0].Role = nil
+	group.Members[0].Weight = util.IntPtr(0)
+	setDefaults(&group)
+	if err := validate(group); err == nil || !strings.Contains(err.Error(), "weight") {
+		t.Errorf("expected error about member weight, got: %v", err)
+	}
+
+	group.Members[0].Weight = util.IntPtr(1)
+	group.HealthCheckPath = util.StrPtr("health")
+	group.HealthCheckExpectedStatus = util.IntPtr(700)
+	err := validate(group)
+	if err == nil || !strings.Contains(err.Error(), "healthCheckPath") || !strings.Contains(err.Error(), "healthCheckExpectedStatus") {
+		t.Errorf("expected errors about health check path and status, got: %v", err)
+	}
+
+	badPolicy := tc.OriginGroupPolicy("random")
+	group.Policy = &badPolicy
+	group.HealthCheckPath = nil
+	group.HealthCheckExpectedStatus = nil
+	if err := validate(group); err == nil || !strings.Contains(err.Error(), "policy") {
+		t.Errorf("expected error about invalid policy, got: %v", err)
+	}
+}
+
+func TestGetOriginGroups(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectBegin()
+	groupRows := sqlmock.NewRows([]string{"deliveryservice", "xml_id", "name", "policy", "health_check_path", "health_check_expected_status", "last_updated"})
+	groupRows.AddRow(7, "ds1", "cdn1", "weighted", "/health", 200, time.Now())
+	mock.ExpectQuery("SELECT").WillReturnRows(groupRows)
+	memberRows := sqlmock.NewRows([]string{"deliveryservice", "origin", "name", "protocol", "fqdn", "port", "role", "weight"})
+	memberRows.AddRow(7, 1, "o1", "https", "o1.example.net", nil, "primary", 3)
+	memberRows.AddRow(7, 2, "o2", "http", "o2.example.net", 8080, "secondary", 1)
+	mock.ExpectQuery("SELECT").WithArgs(pq.Array([]int64{7})).WillReturnRows(memberRows)
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("creating transaction: %v", err)
+	}
+	groups, err := getOriginGroups(tx, []int{1}, nil, "cdn1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tx.Commit()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 origin group, actual: %d", len(groups))
+	}
+	if len(groups[0].Members) != 2 {
+		t.Fatalf("expected 2 origin group members, actual: %d", len(groups[0].Members))
+	}
+	if url := groups[0].HealthCheckURL(groups[0].Members[0]); url != "https://o1.example.net:443/health" {
+		t.Errorf("expected health check URL 'https://o1.example.net:443/health', actual: '%s'", url)
+	}
+	if secondaries := groups[0].MembersByRole(tc.OriginGroupMemberRoleSecondary); len(secondaries) != 1 || *secondaries[0].Origin != "o2" {
+		t.Errorf("expected secondary member o2, actual: %+v", secondaries)
+	}
+}
@@ -20,6 +20,7 @@ package profile
  */
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/profileparameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 )
 
 type errorDetails struct {
@@ -116,9 +118,30 @@ func copyProfile(inf *api.APIInfo, p *tc.ProfileCopy) errorDetails {
 		}
 	}
 
+	// the new profile is owned by the tenant of the existing profile, which legacy API versions don't read
+	existingTenantID := (*int)(nil)
+	if err := inf.Tx.Tx.QueryRow(`SELECT tenant_id FROM profile WHERE id = $1`, *profiles[0].(tc.ProfileNullable).ID).Scan(&existingTenantID); err != nil {
+		return errorDetails{
+			sysErr:  errors.New("getting existing profile tenant: " + err.Error()),
+			errCode: http.StatusInternalServerError,
+		}
+	}
+	if authorized, err := tenant.IsOptionalResourceAuthorizedToUserTx(existingTenantID, inf.User, inf.Tx.Tx); err != nil {
+		return errorDetails{
+			sysErr:  errors.New("checking existing profile tenancy: " + err.Error()),
+			errCode: http.StatusInternalServerError,
+		}
+	} else if !authorized {
+		return errorDetails{
+			userErr: tc.TenantUserNotAuthError,
+			errCode: http.StatusForbidden,
+		}
+	}
+
 	// use existing CRUD helpers to create the new profile
 	toProfile.ProfileNullable = profiles[0].(tc.ProfileNullable)
 	toProfile.ProfileNullable.Name = &p.Name
+	toProfile.ProfileNullable.TenantID = existingTenantID
 	userErr, sysErr, errCode = api.GenericCreate(toProfile)
 	if userErr != nil || sysErr != nil {
 		return errorDetails{
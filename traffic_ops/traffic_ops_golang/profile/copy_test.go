@@ -100,7 +100,8 @@ func TestCopyProfileInvalidExistingProfile(t *testing.T) {
 			mockReadProfile(t, mock, c.existingProfile, c.mockReadProfile)
 
 			inf := api.APIInfo{
-				Tx: db.MustBegin(),
+				Tx:   db.MustBegin(),
+				User: &auth.CurrentUser{TenantID: 1},
 				Params: map[string]string{
 					"existing_profile": c.profile.Response.ExistingName,
 					"new_profile":      c.profile.Response.Name,
@@ -155,7 +156,8 @@ func TestCopyNewProfileExists(t *testing.T) {
 	mockFindProfile(t, mock, profile.Response.Name, 1)
 
 	inf := api.APIInfo{
-		Tx: db.MustBegin(),
+		Tx:   db.MustBegin(),
+		User: &auth.CurrentUser{TenantID: 1},
 		Params: map[string]string{
 			"existing_profile": profile.Response.ExistingName,
 			"new_profile":      profile.Response.Name,
@@ -208,6 +210,7 @@ func TestCopyProfile(t *testing.T) {
 	mock.ExpectBegin()
 	mockFindProfile(t, mock, profile.Response.Name, 0)
 	mockReadProfile(t, mock, existingProfile, 1)
+	mock.ExpectQuery("SELECT tenant_id FROM profile").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow(nil))
 	mockInsertProfile(t, mock, expectedID)
 	mockFindParams(t, mock, profile.Response.ExistingName)
 	mockInsertParams(t, mock, profile.Response.ID)
@@ -257,6 +260,7 @@ func mockReadProfile(t *testing.T, mock sqlmock.Sqlmock, profile tc.ProfileNulla
 		)
 	}
 
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active", "parent_id", "last_updated"}).AddRow(1, "root", true, nil, time.Now()))
 	mock.ExpectQuery("SELECT .* FROM profile").WillReturnRows(existingRow)
 }
 
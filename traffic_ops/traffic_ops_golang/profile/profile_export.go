@@ -20,6 +20,7 @@ package profile
  */
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -29,7 +30,7 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
-	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 )
 
 // ExportProfileHandler exports a profile per ID
@@ -43,14 +44,20 @@ func ExportProfileHandler(w http.ResponseWriter, r *http.Request) {
 
 	profileID := inf.IntParams[IDQueryParam]
 
-	// Check that the profile attempting to be exported exists
-	_, exists, err := dbhelpers.GetProfileNameFromID(profileID, inf.Tx.Tx)
-	if err != nil {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+	// Check that the profile attempting to be exported exists, and is in a tenant the user is authorized on
+	tenantID := (*int)(nil)
+	if err := inf.Tx.Tx.QueryRow(`SELECT tenant_id FROM profile WHERE id = $1`, profileID).Scan(&tenantID); err == sql.ErrNoRows {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("profile does not exist"), nil)
+		return
+	} else if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting profile tenant: "+err.Error()))
 		return
 	}
-	if !exists {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("profile does not exist"), nil)
+	if authorized, err := tenant.IsOptionalResourceAuthorizedToUserTx(tenantID, inf.User, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking profile tenancy: "+err.Error()))
+		return
+	} else if !authorized {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, tc.TenantUserNotAuthError, nil)
 		return
 	}
 
@@ -60,6 +67,9 @@ func ExportProfileHandler(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
 		return
 	}
+	if inf.Version.Major >= 3 {
+		exportedProfileResp.Profile.TenantID = tenantID
+	}
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%v.json\"", *exportedProfileResp.Profile.Name))
 	api.WriteRespRaw(w, r, exportedProfileResp)
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/lib/pq"
 
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 )
 
 // ImportProfileHandler handles importing profile
@@ -47,6 +48,17 @@ func ImportProfileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if inf.Version.Major < 3 {
+		importedProfile.Profile.TenantID = nil // tenancy of profiles isn't a part of legacy API versions
+	}
+	if authorized, err := tenant.IsOptionalResourceAuthorizedToUserTx(importedProfile.Profile.TenantID, inf.User, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking profile tenancy: "+err.Error()))
+		return
+	} else if !authorized {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, tc.TenantUserNotAuthError, nil)
+		return
+	}
+
 	id, err := importProfile(&importedProfile.Profile, inf.Tx.Tx)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("importing profile: "+err.Error()))
@@ -74,8 +86,8 @@ func ImportProfileHandler(w http.ResponseWriter, r *http.Request) {
 func importProfile(importedProfile *tc.ProfileExportImportNullable, tx *sql.Tx) (int, error) {
 	var id int
 	insertQuery := `
-INSERT INTO profile (name, description, cdn, type)
-SELECT $1, $2, id, $4
+INSERT INTO profile (name, description, cdn, type, tenant_id)
+SELECT $1, $2, id, $4, $5
 FROM cdn
 WHERE name = $3
 RETURNING id`
@@ -84,7 +96,8 @@ RETURNING id`
 		importedProfile.Name,
 		importedProfile.Description,
 		importedProfile.CDNName,
-		importedProfile.Type).Scan(&id); err != nil {
+		importedProfile.Type,
+		importedProfile.TenantID).Scan(&id); err != nil {
 		if err == sql.ErrNoRows {
 			return id, fmt.Errorf("imported profile %v was not inserted, no id was returned", importedProfile.Name)
 		}
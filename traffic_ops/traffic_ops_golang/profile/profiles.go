@@ -20,6 +20,7 @@ package profile
  */
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/util/ims"
 	"net/http"
@@ -34,6 +35,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/jmoiron/sqlx"
@@ -46,9 +48,10 @@ const (
 	NameQueryParam        = "name"
 	ParamQueryParam       = "param"
 	TypeQueryParam        = "type"
+	TenantQueryParam      = "tenant"
 )
 
-//we need a type alias to define functions on
+// we need a type alias to define functions on
 type TOProfile struct {
 	api.APIInfoImpl `json:"-"`
 	tc.ProfileNullable
@@ -63,7 +66,7 @@ func (prof TOProfile) GetKeyFieldsInfo() []api.KeyFieldInfo {
 	return []api.KeyFieldInfo{{IDQueryParam, api.GetIntKey}}
 }
 
-//Implementation of the Identifier, Validator interface functions
+// Implementation of the Identifier, Validator interface functions
 func (prof TOProfile) GetKeys() (map[string]interface{}, bool) {
 	if prof.ID == nil {
 		return map[string]interface{}{IDQueryParam: 0}, false
@@ -90,6 +93,25 @@ func (prof *TOProfile) GetType() string {
 	return "profile"
 }
 
+// isLegacy returns whether the request is of an API version before 3.0, of which the tenancy of profiles isn't a part.
+func (prof *TOProfile) isLegacy() bool {
+	return prof.ReqInfo.Version != nil && prof.ReqInfo.Version.Major < 3
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on both the tenant of the
+// profile, if it exists and has one, and the tenant requested for it, if any.
+func (prof *TOProfile) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
+	id := 0
+	if prof.ID != nil {
+		id = *prof.ID
+	}
+	requestedTenantID := prof.TenantID
+	if prof.isLegacy() {
+		requestedTenantID = nil
+	}
+	return tenant.IsOptionalResourceChangeAuthorizedToUserTx(`SELECT tenant_id FROM profile WHERE id = $1`, id, requestedTenantID, user, prof.ReqInfo.Tx.Tx)
+}
+
 func (prof *TOProfile) Validate() error {
 	errs := validation.Errors{
 		NameQueryParam:        validation.Validate(prof.Name, validation.Required),
@@ -109,9 +131,10 @@ func (prof *TOProfile) Read(h http.Header, useIMS bool) ([]interface{}, error, e
 	// Query Parameters to Database Query column mappings
 	// see the fields mapped in the SQL query
 	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
-		CDNQueryParam:  dbhelpers.WhereColumnInfo{"c.id", nil},
-		NameQueryParam: dbhelpers.WhereColumnInfo{"prof.name", nil},
-		IDQueryParam:   dbhelpers.WhereColumnInfo{"prof.id", api.IsInt},
+		CDNQueryParam:    dbhelpers.WhereColumnInfo{"c.id", nil},
+		NameQueryParam:   dbhelpers.WhereColumnInfo{"prof.name", nil},
+		IDQueryParam:     dbhelpers.WhereColumnInfo{"prof.id", api.IsInt},
+		TenantQueryParam: dbhelpers.WhereColumnInfo{"prof.tenant_id", api.IsInt},
 	}
 	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(prof.APIInfo().Params, queryParamsToQueryCols)
 
@@ -140,6 +163,12 @@ func (prof *TOProfile) Read(h http.Header, useIMS bool) ([]interface{}, error, e
 		log.Debugln("Non IMS request")
 	}
 
+	tenantIDs, err := tenant.GetUserTenantIDsTx(*prof.ReqInfo.User, prof.ReqInfo.Tx.Tx)
+	if err != nil {
+		return nil, nil, errors.New("profile read getting user tenants: " + err.Error()), http.StatusInternalServerError, nil
+	}
+	where, queryValues = dbhelpers.AddOptionalTenancyCheck(where, queryValues, "prof.tenant_id", tenantIDs)
+
 	query := selectProfilesQuery() + where + orderBy + pagination
 	log.Debugln("Query is ", query)
 
@@ -156,6 +185,10 @@ func (prof *TOProfile) Read(h http.Header, useIMS bool) ([]interface{}, error, e
 		if err = rows.StructScan(&p); err != nil {
 			return nil, nil, errors.New("profile read scanning: " + err.Error()), http.StatusInternalServerError, nil
 		}
+		if prof.isLegacy() {
+			p.Tenant = nil
+			p.TenantID = nil
+		}
 		profiles = append(profiles, p)
 	}
 	rows.Close()
@@ -178,7 +211,8 @@ func (prof *TOProfile) Read(h http.Header, useIMS bool) ([]interface{}, error, e
 func selectMaxLastUpdatedQuery(where string) string {
 	return `SELECT max(t) from (
 		SELECT max(prof.last_updated) as t FROM profile prof
-LEFT JOIN cdn c ON prof.cdn = c.id ` + where +
+LEFT JOIN cdn c ON prof.cdn = c.id
+LEFT JOIN tenant t ON prof.tenant_id = t.id ` + where +
 		` UNION ALL
 	select max(last_updated) as t from last_deleted l where l.table_name='profile') as res`
 }
@@ -193,9 +227,12 @@ prof.name,
 prof.routing_disabled,
 prof.type,
 c.id as cdn,
-c.name as cdn_name
+c.name as cdn_name,
+t.name as tenant,
+prof.tenant_id
 FROM profile prof
-LEFT JOIN cdn c ON prof.cdn = c.id`
+LEFT JOIN cdn c ON prof.cdn = c.id
+LEFT JOIN tenant t ON prof.tenant_id = t.id`
 
 	return query
 }
@@ -243,8 +280,27 @@ JOIN profile_parameter pp ON pp.parameter = p.id
 WHERE pp.profile = :profile_id`
 }
 
-func (pr *TOProfile) Update() (error, error, int) { return api.GenericUpdate(pr) }
-func (pr *TOProfile) Create() (error, error, int) { return api.GenericCreate(pr) }
+func (pr *TOProfile) Update() (error, error, int) {
+	if !pr.isLegacy() {
+		return api.GenericUpdate(pr)
+	}
+	// updates through legacy API versions don't change the tenant
+	if err := pr.ReqInfo.Tx.Tx.QueryRow(`SELECT tenant_id FROM profile WHERE id = $1`, *pr.ID).Scan(&pr.TenantID); err != nil && err != sql.ErrNoRows {
+		return nil, errors.New("profile update: getting tenant: " + err.Error()), http.StatusInternalServerError
+	}
+	userErr, sysErr, errCode := api.GenericUpdate(pr)
+	pr.TenantID = nil
+	return userErr, sysErr, errCode
+}
+
+func (pr *TOProfile) Create() (error, error, int) {
+	if pr.isLegacy() {
+		pr.Tenant = nil
+		pr.TenantID = nil
+	}
+	return api.GenericCreate(pr)
+}
+
 func (pr *TOProfile) Delete() (error, error, int) { return api.GenericDelete(pr) }
 
 func updateQuery() string {
@@ -254,7 +310,8 @@ cdn=:cdn,
 description=:description,
 name=:name,
 routing_disabled=:routing_disabled,
-type=:type
+type=:type,
+tenant_id=:tenant_id
 WHERE id=:id RETURNING last_updated`
 	return query
 }
@@ -265,12 +322,14 @@ cdn,
 description,
 name,
 routing_disabled,
-type) VALUES (
+type,
+tenant_id) VALUES (
 :cdn,
 :description,
 :name,
 :routing_disabled,
-:type) RETURNING id,last_updated`
+:type,
+:tenant_id) RETURNING id,last_updated`
 	return query
 }
 
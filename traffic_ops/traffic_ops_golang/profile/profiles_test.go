@@ -28,6 +28,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/test"
 	"github.com/jmoiron/sqlx"
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
@@ -91,13 +92,16 @@ func TestGetProfiles(t *testing.T) {
 			ts.CDNID,
 			ts.RoutingDisabled,
 			ts.Type,
+			ts.Tenant,
+			ts.TenantID,
 		)
 	}
 	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active", "parent_id", "last_updated"}).AddRow(1, "root", true, nil, time.Now()))
 	mock.ExpectQuery("SELECT").WillReturnRows(rows)
 	mock.ExpectCommit()
 
-	reqInfo := api.APIInfo{Tx: db.MustBegin(), Params: map[string]string{"name": "1"}}
+	reqInfo := api.APIInfo{Tx: db.MustBegin(), Params: map[string]string{"name": "1"}, User: &auth.CurrentUser{TenantID: 1}}
 
 	obj := TOProfile{
 		api.APIInfoImpl{&reqInfo},
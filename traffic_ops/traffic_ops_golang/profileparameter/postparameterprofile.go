@@ -45,6 +45,20 @@ func PostParamProfile(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("parse error: "+err.Error()), nil)
 		return
 	}
+	profileIDs := *paramProfile.ProfileIDs
+	if *paramProfile.Replace {
+		// replacing removes the parameter from the profiles which have it now, too
+		assignedIDs := []int64{}
+		if err := inf.Tx.Tx.QueryRow(`SELECT COALESCE(array_agg(profile), '{}') FROM profile_parameter WHERE parameter = $1`, *paramProfile.ParamID).Scan(pq.Array(&assignedIDs)); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting parameter profiles: "+err.Error()))
+			return
+		}
+		profileIDs = append(append([]int64{}, profileIDs...), assignedIDs...)
+	}
+	if userErr, sysErr, errCode := checkProfilesTenancy(profileIDs, inf.User, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
 	if err := insertParameterProfile(paramProfile, inf.Tx.Tx); err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("posting parameter profile: "+err.Error()))
 		return
@@ -20,6 +20,7 @@ package profileparameter
  */
 
 import (
+	"database/sql"
 	"errors"
 	"net/http"
 	"strconv"
@@ -29,9 +30,12 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
 	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/lib/pq"
 )
 
 const (
@@ -98,6 +102,27 @@ func (pp *TOProfileParameter) SetKeys(keys map[string]interface{}) {
 	pp.ParameterID = &paramId
 }
 
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the tenant of the profile,
+// if it has one.
+func (pp *TOProfileParameter) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
+	if pp.ProfileID == nil {
+		return true, nil
+	}
+	return tenant.IsOptionalResourceChangeAuthorizedToUserTx(`SELECT tenant_id FROM profile WHERE id = $1`, *pp.ProfileID, nil, user, pp.APIInfo().Tx.Tx)
+}
+
+// checkProfilesTenancy returns an error if the user isn't authorized on the tenant of any of the given profiles.
+func checkProfilesTenancy(profileIDs []int64, user *auth.CurrentUser, tx *sql.Tx) (error, error, int) {
+	authorized, err := tenant.AreOptionalResourcesAuthorizedToUserTx(`SELECT DISTINCT tenant_id FROM profile WHERE id = ANY($1::bigint[])`, []interface{}{pq.Array(profileIDs)}, user, tx)
+	if err != nil {
+		return nil, errors.New("checking profile tenancy: " + err.Error()), http.StatusInternalServerError
+	}
+	if !authorized {
+		return tc.TenantUserNotAuthError, nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
+
 // Validate fulfills the api.Validator interface
 func (pp *TOProfileParameter) Validate() error {
 
@@ -31,6 +31,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -183,6 +184,9 @@ func (role *TORole) Read(h http.Header, useIMS bool) ([]interface{}, error, erro
 		case version.Major > 1 || version.Minor >= 3:
 			caps := ([]string)(*rl.PQCapabilities)
 			rl.Capabilities = &caps
+			if version.Major < 3 {
+				rl.MFARequired = nil
+			}
 			returnable = append(returnable, rl)
 		case version.Minor >= 1:
 			returnable = append(returnable, rl.RoleV11)
@@ -195,11 +199,22 @@ func (role *TORole) Update() (error, error, int) {
 	if *role.PrivLevel > role.ReqInfo.User.PrivLevel {
 		return errors.New("can not create a role with a higher priv level than your own"), nil, http.StatusForbidden
 	}
+	oldPrivLevel, _, err := dbhelpers.GetPrivLevelFromRoleID(role.ReqInfo.Tx.Tx, *role.ID)
+	if err != nil {
+		return nil, errors.New("role update getting old priv level: " + err.Error()), http.StatusInternalServerError
+	}
 	userErr, sysErr, errCode := api.GenericUpdate(role)
 	if userErr != nil || sysErr != nil {
 		return userErr, sysErr, errCode
 	}
 
+	// users of a role which loses privileges are logged out
+	if *role.PrivLevel < oldPrivLevel {
+		if err := auth.DeleteRoleSessions(role.ReqInfo.Tx.Tx, *role.ID); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+	}
+
 	// TODO cascade delete, to automatically do this in SQL?
 	if role.Capabilities != nil && *role.Capabilities != nil {
 		userErr, sysErr, errCode = role.deleteRoleCapabilityAssociations(role.ReqInfo.Tx)
@@ -232,7 +247,8 @@ id,
 name,
 description,
 priv_level,
-ARRAY(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id=id) AS capabilities
+ARRAY(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id=id) AS capabilities,
+mfa_required
 FROM role`
 }
 
@@ -240,7 +256,8 @@ func updateQuery() string {
 	return `UPDATE
 role SET
 name=:name,
-description=:description
+description=:description,
+mfa_required=COALESCE(:mfa_required, mfa_required)
 WHERE id=:id RETURNING last_updated`
 }
 
@@ -262,11 +279,13 @@ func insertQuery() string {
 	return `INSERT INTO role (
 name,
 description,
-priv_level
+priv_level,
+mfa_required
 ) VALUES (
 :name,
 :description,
-:priv_level
+:priv_level,
+COALESCE(:mfa_required, FALSE)
 )
 RETURNING id, last_updated`
 }
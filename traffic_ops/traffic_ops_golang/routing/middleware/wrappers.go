@@ -89,6 +89,16 @@ type AuthBase struct {
 // GetWrapper returns a Middleware which performs authentication of the current user at the given privilege level.
 // The returned Middleware also adds the auth.CurrentUser object to the request context, which may be retrieved by a handler via api.NewInfo or auth.GetCurrentUser.
 func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
+	return a.getWrapper(privLevelRequired, false)
+}
+
+// GetMFAWrapper is like GetWrapper, but also allows users whose sessions are limited to enrolling in or verifying
+// multi-factor authentication. See auth.CurrentUser.MFAPending.
+func (a AuthBase) GetMFAWrapper(privLevelRequired int) Middleware {
+	return a.getWrapper(privLevelRequired, true)
+}
+
+func (a AuthBase) getWrapper(privLevelRequired int, allowMFAPending bool) Middleware {
 	if a.Override != nil {
 		return a.Override
 	}
@@ -99,6 +109,10 @@ func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
 				api.HandleErr(w, r, nil, errCode, userErr, sysErr)
 				return
 			}
+			if user.MFAPending && !allowMFAPending {
+				api.HandleErr(w, r, nil, http.StatusForbidden, errors.New("Multi-factor authentication is required. Enroll in or verify it at user/current/mfa, or log in again with a one-time password."), nil)
+				return
+			}
 			if user.PrivLevel < privLevelRequired {
 				api.HandleErr(w, r, nil, http.StatusForbidden, errors.New("Forbidden."), nil)
 				return
@@ -163,14 +163,16 @@ func TestWrapAuth(t *testing.T) {
 	userName := "user1"
 	id := 1
 	secret := "secret"
+	sessionID := "0123456789abcdef"
 
 	rows := sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id"})
 	rows.AddRow(30, "user1", 1, 1)
 	mock.ExpectQuery("SELECT").WithArgs(userName).WillReturnRows(rows)
+	mock.ExpectExec("UPDATE user_session").WithArgs(sessionID, id, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
 
 	authBase := AuthBase{secret, nil}
 
-	cookie := tocookie.GetCookie(userName, time.Minute, secret)
+	cookie := tocookie.GetSessionCookie(userName, sessionID, false, time.Minute, secret)
 
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -235,6 +237,77 @@ func TestWrapAuth(t *testing.T) {
 	if !bytes.Equal(w.Body.Bytes(), []byte(expectedError)) {
 		t.Errorf("received: %s\n expected: %s\n", w.Body.Bytes(), expectedError)
 	}
+
+	// a cookie of a session which was extended recently isn't extended again, but is accepted
+	rows = sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id"})
+	rows.AddRow(30, "user1", 1, 1)
+	mock.ExpectQuery("SELECT").WithArgs(userName).WillReturnRows(rows)
+	mock.ExpectExec("UPDATE user_session").WithArgs(sessionID, id, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(sessionID, id).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "/", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Add("Cookie", tocookie.Name+"="+cookie.Value)
+	r = r.WithContext(context.WithValue(context.Background(), api.DBContextKey, db))
+	r = r.WithContext(context.WithValue(r.Context(), api.ConfigContextKey, &config.Config{ConfigTrafficOpsGolang: config.ConfigTrafficOpsGolang{DBQueryTimeoutSeconds: 20}}))
+
+	f(w, r)
+
+	if !bytes.Equal(w.Body.Bytes(), expectedBody) {
+		t.Errorf("recently extended session - received: %s\n expected: %s\n", w.Body.Bytes(), expectedBody)
+	}
+
+	// a cookie of a session which isn't in the session registry is rejected
+	rows = sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id"})
+	rows.AddRow(30, "user1", 1, 1)
+	mock.ExpectQuery("SELECT").WithArgs(userName).WillReturnRows(rows)
+	mock.ExpectExec("UPDATE user_session").WithArgs(sessionID, id, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(sessionID, id).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "/", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Add("Cookie", tocookie.Name+"="+cookie.Value)
+	r = r.WithContext(context.WithValue(context.Background(), api.DBContextKey, db))
+	r = r.WithContext(context.WithValue(r.Context(), api.ConfigContextKey, &config.Config{ConfigTrafficOpsGolang: config.ConfigTrafficOpsGolang{DBQueryTimeoutSeconds: 20}}))
+
+	f(w, r)
+
+	if !bytes.Equal(w.Body.Bytes(), []byte(expectedError)) {
+		t.Errorf("ended session - received: %s\n expected: %s\n", w.Body.Bytes(), expectedError)
+	}
+
+	// a cookie without a session, from before sessions were recorded, is accepted without being refreshed
+	rows = sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id"})
+	rows.AddRow(30, "user1", 1, 1)
+	mock.ExpectQuery("SELECT").WithArgs(userName).WillReturnRows(rows)
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "/", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Add("Cookie", tocookie.Name+"="+tocookie.GetCookie(userName, time.Minute, secret).Value)
+	r = r.WithContext(context.WithValue(context.Background(), api.DBContextKey, db))
+	r = r.WithContext(context.WithValue(r.Context(), api.ConfigContextKey, &config.Config{ConfigTrafficOpsGolang: config.ConfigTrafficOpsGolang{DBQueryTimeoutSeconds: 20}}))
+
+	f(w, r)
+
+	if !bytes.Equal(w.Body.Bytes(), expectedBody) {
+		t.Errorf("session-less cookie - received: %s\n expected: %s\n", w.Body.Bytes(), expectedBody)
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("session-less cookie - expected no refreshed cookie, actual: %+v", cookies)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
 }
 
 // TODO: TestWrapAccessLog
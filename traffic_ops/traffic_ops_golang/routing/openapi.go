@@ -0,0 +1,331 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/apitenant"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/asn"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cachegroup"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cachegroupparameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdn"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdnfederation"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/coordinate"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/coveragezone"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	dsrequest "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request/comment"
+	dsserver "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/servers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/division"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federations"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/invalidationjobs"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/maintenance"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/openapi"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/origin"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/physlocation"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/profile"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/profileparameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/region"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/role"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/server"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/servercapability"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/staticdnsentry"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/status"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/steeringtargets"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/topology"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/types"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/user"
+)
+
+// crudRouteObjects maps the IDs of routes served by the generic CRUD handlers (api.ReadHandler, api.CreateHandler,
+// etc.) to the object given to the handler, from which the route's payload types are derived.
+//
+// When adding a generic CRUD route, add its object here so it is documented in /api/{version}/openapi.json.
+var crudRouteObjects = map[int]interface{}{
+	// ASNs
+	22641723173: &asn.TOASNV11{},
+	2738777223:  &asn.TOASNV11{},
+	29511986293: &asn.TOASNV11{},
+	29994921883: &asn.TOASNV11{},
+
+	// Cache Groups
+	2230791103: &cachegroup.TOCacheGroup{},
+	2129545463: &cachegroup.TOCacheGroup{},
+	229826653:  &cachegroup.TOCacheGroup{},
+	2124497233: &cachegroupparameter.TOCacheGroupParameter{},
+
+	// CDNs
+	22303186213: &cdn.TOCDN{},
+	23111789343: &cdn.TOCDN{},
+	21605052893: &cdn.TOCDN{},
+	2892250323:  &cdnfederation.TOCDNFederation{},
+	29548942193: &cdnfederation.TOCDNFederation{},
+	2260654663:  &cdnfederation.TOCDNFederation{},
+
+	// Coordinates
+	2967007453:  &coordinate.TOCoordinate{},
+	2689261743:  &coordinate.TOCoordinate{},
+	24281121573: &coordinate.TOCoordinate{},
+
+	// Coverage Zones
+	2614093571: &coveragezone.TOCoverageZone{},
+	2614093572: &coveragezone.TOCoverageZone{},
+	2614093573: &coveragezone.TOCoverageZone{},
+
+	// Delivery Services
+	22383172943: &deliveryservice.TODeliveryService{},
+	21585222273: &deliveryservice.RequiredCapability{},
+	20968739923: &deliveryservice.RequiredCapability{},
+	26811639353: &dsrequest.TODeliveryServiceRequest{},
+	22499079183: &dsrequest.TODeliveryServiceRequest{},
+	293850393:   &dsrequest.TODeliveryServiceRequest{},
+	20326507373: &comment.TODeliveryServiceRequestComment{},
+	2604878473:  &comment.TODeliveryServiceRequestComment{},
+	2272276723:  &comment.TODeliveryServiceRequestComment{},
+	2331154113:  &dsserver.TODSSDeliveryService{},
+
+	// Divisions
+	20851815343: &division.TODivision{},
+	2063691403:  &division.TODivision{},
+	2537138003:  &division.TODivision{},
+
+	// Federations
+	2537730343: &federations.TOFedDSes{},
+	2940750153: &federations.TOUsers{},
+
+	// Content Invalidation Jobs
+	29667820413: &invalidationjobs.InvalidationJob{},
+
+	// Maintenance Windows
+	2536120891: &maintenance.TOMaintenanceWindow{},
+	2536120892: &maintenance.TOMaintenanceWindow{},
+	2536120893: &maintenance.TOMaintenanceWindow{},
+
+	// Origins
+	2446492563:  &origin.TOOrigin{},
+	215677463:   &origin.TOOrigin{},
+	20995616433: &origin.TOOrigin{},
+
+	// Parameters
+	22125542923: &parameter.TOParameter{},
+	28739361153: &parameter.TOParameter{},
+	26695108593: &parameter.TOParameter{},
+
+	// Physical Locations
+	2204051823:  &physlocation.TOPhysLocation{},
+	2227950213:  &physlocation.TOPhysLocation{},
+	22464566483: &physlocation.TOPhysLocation{},
+
+	// Profiles
+	2687585893:  &profile.TOProfile{},
+	284391723:   &profile.TOProfile{},
+	25402115563: &profile.TOProfile{},
+	2506098053:  &profileparameter.TOProfileParameter{},
+	2288096933:  &profileparameter.TOProfileParameter{},
+
+	// Regions
+	2100370853:  &region.TORegion{},
+	2223082243:  &region.TORegion{},
+	22883344883: &region.TORegion{},
+
+	// Roles
+	2870885833:  &role.TORole{},
+	26128974893: &role.TORole{},
+	2306524063:  &role.TORole{},
+
+	// Server Capabilities
+	2104073913:  &servercapability.TOServerCapability{},
+	20744707083: &servercapability.TOServerCapability{},
+	28002318893: &server.TOServerServerCapability{},
+	22931668343: &server.TOServerServerCapability{},
+
+	// Static DNS Entries
+	2289394773:  &staticdnsentry.TOStaticDNSEntry{},
+	2424571113:  &staticdnsentry.TOStaticDNSEntry{},
+	26291482383: &staticdnsentry.TOStaticDNSEntry{},
+
+	// Statuses
+	22449056563: &status.TOStatus{},
+	22079665043: &status.TOStatus{},
+	23691236123: &status.TOStatus{},
+
+	// Steering Targets
+	25696078243: &steeringtargets.TOSteeringTargetV11{},
+	23382163973: &steeringtargets.TOSteeringTargetV11{},
+	24386082953: &steeringtargets.TOSteeringTargetV11{},
+
+	// Tenants
+	26779678143: &apitenant.TOTenant{},
+	20941314783: &apitenant.TOTenant{},
+	2172480133:  &apitenant.TOTenant{},
+
+	// Topologies
+	3871452221: &topology.TOTopology{},
+	3871452222: &topology.TOTopology{},
+	3871452223: &topology.TOTopology{},
+
+	// Types
+	22267018233: &types.TOType{},
+	288601153:   &types.TOType{},
+	25133081953: &types.TOType{},
+
+	// Users
+	24919299003: &user.TOUser{},
+	2138099803:  &user.TOUser{},
+	2354334043:  &user.TOUser{},
+	2762448163:  &user.TOUser{},
+}
+
+// routeTypes maps the IDs of routes with bespoke handlers to the types of their payloads.
+//
+// When adding such a route, add its types here so it is documented in /api/{version}/openapi.json.
+var routeTypes = map[int]openapi.Types{
+	23175011663: {Response: map[string]string{}, Unwrapped: true},                                        // GET about
+	25556615973: {Response: map[string]string{}, Unwrapped: true},                                        // GET ping
+	28132065893: {Response: []tc.APICapability{}},                                                        // GET api_capabilities
+	20081353:    {Response: []tc.Capability{}},                                                           // GET capabilities
+	26107016143: {Response: tc.UserCurrent{}},                                                            // GET user/current
+	27209592853: {Response: []tc.ServerNullable{}, Summary: true},                                        // GET servers
+	22255580613: {Request: tc.ServerNullable{}, Response: tc.ServerNullable{}},                           // POST servers
+	2586341033:  {Request: tc.ServerNullable{}, Response: tc.ServerNullable{}},                           // PUT servers/{id}
+	2064314323:  {Request: tc.DeliveryServiceNullableV30{}, Response: []tc.DeliveryServiceNullableV30{}}, // POST deliveryservices
+	27665675273: {Request: tc.DeliveryServiceNullableV30{}, Response: []tc.DeliveryServiceNullableV30{}}, // PUT deliveryservices/{id}
+	21748524573: {Response: []tc.Steering{}},                                                             // GET steering
+	2055014533:  {Response: []tc.DeliveryServiceRegexes{}},                                               // GET deliveryservices_regexes
+	2566087593:  {Response: []tc.FederationResolver{}},                                                   // GET federation_resolvers
+	21343736613: {Request: tc.FederationResolver{}, Response: tc.FederationResolver{}},                   // POST federation_resolvers
+	2781645201:  {Response: tc.GraphQLResponse{}, Unwrapped: true},                                       // GET graphql
+	2781645202:  {Request: tc.GraphQLRequest{}, Response: tc.GraphQLResponse{}, Unwrapped: true},         // POST graphql
+	2468013571:  {Response: tc.CapacityPlan{}},                                                           // GET capacity_planning
+	2607550764:  {Response: tc.DeliveryServiceConsistentHash{}},                                          // GET deliveryservices/{id}/consistenthash
+	2614093575:  {Response: []tc.CoverageZoneNullable{}},                                                 // GET coveragezones/lookup
+	2614093576:  {Response: tc.CoverageZoneFile{}, Unwrapped: true},                                      // GET cdns/{name}/coveragezones
+	2614093577:  {Response: tc.DeepCoverageZoneFile{}, Unwrapped: true},                                  // GET cdns/{name}/deepcoveragezones
+	2731568201:  {Response: tc.UserMFAStatus{}},                                                          // GET user/current/mfa
+	2731568202:  {Response: tc.UserMFAEnrollment{}},                                                      // POST user/current/mfa/enroll
+	2731568203:  {Request: tc.UserMFACodeRequest{}, Response: tc.UserMFAVerification{}},                  // POST user/current/mfa/verify
+	2731568204:  {Request: tc.UserMFACodeRequest{}},                                                      // POST user/current/mfa/disable
+	2731568205:  {},                                                                                      // POST users/{id}/mfa/reset
+	2846201731:  {Response: []tc.UserSession{}},                                                          // GET user/current/sessions
+	2846201732:  {},                                                                                      // DELETE users/{id}/sessions
+	2846201733:  {},                                                                                      // POST users/{id}/unlock
+	2566087623:  {Response: []tc.FederationResolverHistory{}},                                            // GET federations/{id}/history
+	2569607831:  {Request: tc.SteeringSimulationRequest{}, Response: tc.SteeringSimulation{}},            // POST steering/{deliveryservice}/simulate
+	2446138201:  {Response: []tc.OriginGroup{}},                                                          // GET origin_groups
+	2446138202:  {Request: tc.OriginGroup{}, Response: tc.OriginGroup{}},                                 // PUT deliveryservices/{id}/origin_group
+	2446138203:  {},                                                                                      // DELETE deliveryservices/{id}/origin_group
+	2607550781:  {Response: tc.DeliveryServiceTopologyServers{}},                                         // GET deliveryservices/{id}/topology/servers
+	2771309301:  {Response: tc.ServerLifecycle{}},                                                        // GET servers/{id}/lifecycle
+	2771309302:  {Request: tc.ServerLifecycleTransitionRequest{}, Response: tc.ServerLifecycle{}},        // POST servers/{id}/lifecycle
+	2771309303:  {Request: tc.ServerHardwareInventory{}, Response: []tc.HWInfo{}},                        // POST servers/{id}/hwinfo
+}
+
+// requiredProperties maps request types to the properties their handlers reject when missing.
+//
+// When adding or changing the validation of a request type, update its properties here so they are documented in
+// /api/{version}/openapi.json.
+var requiredProperties = map[reflect.Type][]string{
+	reflect.TypeOf(apitenant.TOTenant{}):                      {"name", "parentId"},
+	reflect.TypeOf(asn.TOASNV11{}):                            {"asn", "cachegroupId"},
+	reflect.TypeOf(cachegroup.TOCacheGroup{}):                 {"name", "shortName", "typeId"},
+	reflect.TypeOf(cdn.TOCDN{}):                               {"domainName", "name"},
+	reflect.TypeOf(cdnfederation.TOCDNFederation{}):           {"cname", "ttl"},
+	reflect.TypeOf(comment.TODeliveryServiceRequestComment{}): {"deliveryServiceRequestId", "value"},
+	reflect.TypeOf(coordinate.TOCoordinate{}):                 {"name"},
+	reflect.TypeOf(coveragezone.TOCoverageZone{}):             {"cachegroupId", "cdnId", "network"},
+	reflect.TypeOf(deliveryservice.RequiredCapability{}):      {"deliveryServiceID", "requiredCapability"},
+	reflect.TypeOf(division.TODivision{}):                     {"name"},
+	reflect.TypeOf(dsrequest.TODeliveryServiceRequest{}):      {"changeType", "deliveryService", "status"},
+	reflect.TypeOf(maintenance.TOMaintenanceWindow{}):         {"endTime", "reason", "scope", "startTime"},
+	reflect.TypeOf(origin.TOOrigin{}):                         {"deliveryServiceId", "fqdn", "name", "protocol"},
+	reflect.TypeOf(parameter.TOParameter{}):                   {"configFile", "name"},
+	reflect.TypeOf(physlocation.TOPhysLocation{}):             {"address", "city", "name", "regionId", "shortName", "state", "zip"},
+	reflect.TypeOf(profile.TOProfile{}):                       {"cdn", "description", "name", "type"},
+	reflect.TypeOf(profileparameter.TOProfileParameter{}):     {"parameterId", "profileId"},
+	reflect.TypeOf(region.TORegion{}):                         {"name"},
+	reflect.TypeOf(role.TORole{}):                             {"description", "name", "privLevel"},
+	reflect.TypeOf(server.TOServerServerCapability{}):         {"serverCapability", "serverId"},
+	reflect.TypeOf(servercapability.TOServerCapability{}):     {"name"},
+	reflect.TypeOf(staticdnsentry.TOStaticDNSEntry{}):         {"address", "deliveryserviceId", "host", "ttl", "typeId"},
+	reflect.TypeOf(status.TOStatus{}):                         {"name"},
+	reflect.TypeOf(steeringtargets.TOSteeringTargetV11{}):     {"typeId", "value"},
+	reflect.TypeOf(topology.TOTopology{}):                     {"name", "nodes"},
+	reflect.TypeOf(types.TOType{}):                            {"description", "name", "useInTable"},
+	reflect.TypeOf(user.TOUser{}):                             {"email", "fullName", "role", "tenantId", "username"},
+	reflect.TypeOf(tc.DeliveryServiceNullableV30{}): {"active", "cdnId", "displayName", "dscp", "geoLimit", "geoProvider",
+		"logsEnabled", "regionalGeoBlocking", "typeId", "xmlId"},
+	reflect.TypeOf(tc.FederationResolver{}):               {"ipAddress", "typeId"},
+	reflect.TypeOf(tc.GraphQLRequest{}):                   {"query"},
+	reflect.TypeOf(tc.ServerLifecycleTransitionRequest{}): {"state"},
+	reflect.TypeOf(tc.ServerNullable{}): {"cachegroupId", "cdnId", "domainName", "hostName", "interfaces",
+		"physLocationId", "profileId", "statusId", "typeId", "updPending"},
+	reflect.TypeOf(tc.SteeringSimulationRequest{}): {"requestPath"},
+	reflect.TypeOf(tc.UserMFACodeRequest{}):        {"otp"},
+}
+
+// openAPIRoutes returns the documentation information of the given routes.
+func openAPIRoutes(routes []Route) []openapi.Route {
+	docRoutes := make([]openapi.Route, 0, len(routes))
+	for _, r := range routes {
+		docRoute := openapi.Route{
+			Version:       r.Version,
+			Method:        r.Method,
+			Path:          r.Path,
+			PrivLevel:     r.RequiredPrivLevel,
+			Authenticated: r.Authenticated,
+			ID:            r.ID,
+		}
+		if obj, ok := crudRouteObjects[r.ID]; ok {
+			docRoute.Types = crudTypes(r.Method, obj)
+		} else if types, ok := routeTypes[r.ID]; ok {
+			docRoute.Types = types
+		}
+		if req := reflect.TypeOf(docRoute.Types.Request); req != nil {
+			if req.Kind() == reflect.Ptr {
+				req = req.Elem()
+			}
+			docRoute.Types.Required = requiredProperties[req]
+		}
+		docRoutes = append(docRoutes, docRoute)
+	}
+	return docRoutes
+}
+
+// crudTypes returns the payload types of a route served by the generic CRUD handler of the given method for the
+// given object.
+func crudTypes(method string, obj interface{}) openapi.Types {
+	objType := reflect.TypeOf(obj).Elem()
+	readType := objType
+	if reader, ok := obj.(api.GenericReader); ok {
+		readType = reflect.TypeOf(reader.NewReadObj()).Elem()
+	}
+	switch method {
+	case http.MethodGet:
+		return openapi.Types{Response: reflect.MakeSlice(reflect.SliceOf(readType), 0, 0).Interface()}
+	case http.MethodPost, http.MethodPut:
+		return openapi.Types{Request: obj, Response: reflect.Zero(objType).Interface()}
+	}
+	return openapi.Types{}
+}
@@ -51,6 +51,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdn"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdnfederation"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/coordinate"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/coveragezone"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crstats"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbdump"
@@ -64,12 +65,16 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/division"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federation_resolvers"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federations"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/graphql"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/hwinfo"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/invalidationjobs"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/iso"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/login"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/logs"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/maintenance"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/openapi"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/origin"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/origingroup"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/physlocation"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ping"
@@ -132,6 +137,7 @@ func GetRouteIDMap(IDs []int) map[int]struct{} {
 // Routes returns the API routes, raw non-API root level routes, and a catchall route for when no route matches.
 func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 	proxyHandler := rootHandler(d)
+	openAPISpec := openapi.NewSpec()
 
 	routes := []Route{
 		// 1.1 and 1.2 routes are simply a Go replacement for the equivalent Perl route. They may or may not conform with the API guidelines (https://cwiki.apache.org/confluence/display/TC/API+Guidelines).
@@ -163,6 +169,7 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{3, 0}, http.MethodGet, `deliveryservice_stats`, trafficstats.GetDSStats, auth.PrivLevelReadOnly, Authenticated, nil, 23195690283, noPerlBypass},
 		{api.Version{3, 0}, http.MethodGet, `cache_stats`, trafficstats.GetCacheStats, auth.PrivLevelReadOnly, Authenticated, nil, 24979979063, noPerlBypass},
 		{api.Version{3, 0}, http.MethodGet, `current_stats/?$`, trafficstats.GetCurrentStats, auth.PrivLevelReadOnly, Authenticated, nil, 27854428933, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `capacity_planning/?$`, trafficstats.GetCapacityPlan, auth.PrivLevelReadOnly, Authenticated, nil, 2468013571, noPerlBypass},
 
 		{api.Version{3, 0}, http.MethodGet, `caches/stats/?$`, cachesstats.Get, auth.PrivLevelReadOnly, Authenticated, nil, 28132065883, noPerlBypass},
 
@@ -248,6 +255,18 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{3, 0}, http.MethodGet, `user/current/?$`, user.Current, auth.PrivLevelReadOnly, Authenticated, nil, 26107016143, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPut, `user/current/?$`, user.ReplaceCurrent, auth.PrivLevelReadOnly, Authenticated, nil, 2203, noPerlBypass},
 
+		//User: multi-factor authentication
+		{api.Version{3, 0}, http.MethodGet, `user/current/mfa/?$`, user.GetMFA, auth.PrivLevelReadOnly, Authenticated, nil, 2731568201, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `user/current/mfa/enroll/?$`, user.EnrollMFA, auth.PrivLevelReadOnly, Authenticated, nil, 2731568202, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `user/current/mfa/verify/?$`, user.VerifyMFA, auth.PrivLevelReadOnly, Authenticated, nil, 2731568203, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `user/current/mfa/disable/?$`, user.DisableMFA, auth.PrivLevelReadOnly, Authenticated, nil, 2731568204, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `users/{id}/mfa/reset/?$`, user.ResetMFA, auth.PrivLevelAdmin, Authenticated, nil, 2731568205, noPerlBypass},
+
+		//User: sessions and lockout
+		{api.Version{3, 0}, http.MethodGet, `user/current/sessions/?$`, user.GetCurrentSessions, auth.PrivLevelReadOnly, Authenticated, nil, 2846201731, noPerlBypass},
+		{api.Version{3, 0}, http.MethodDelete, `users/{id}/sessions/?$`, user.DeleteSessions, auth.PrivLevelAdmin, Authenticated, nil, 2846201732, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `users/{id}/unlock/?$`, user.Unlock, auth.PrivLevelAdmin, Authenticated, nil, 2846201733, noPerlBypass},
+
 		//Parameter: CRUD
 		{api.Version{3, 0}, http.MethodGet, `parameters/?$`, api.ReadHandler(&parameter.TOParameter{}), auth.PrivLevelReadOnly, Authenticated, nil, 22125542923, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPut, `parameters/{id}$`, api.UpdateHandler(&parameter.TOParameter{}), auth.PrivLevelOperations, Authenticated, nil, 28739361153, noPerlBypass},
@@ -314,6 +333,9 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 
 		//Server status
 		{api.Version{3, 0}, http.MethodPut, `servers/{id}/status$`, server.UpdateStatusHandler, auth.PrivLevelOperations, Authenticated, nil, 2766638513, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `servers/{id}/lifecycle/?$`, server.GetLifecycle, auth.PrivLevelReadOnly, Authenticated, nil, 2771309301, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `servers/{id}/lifecycle/?$`, server.TransitionLifecycle, auth.PrivLevelOperations, Authenticated, nil, 2771309302, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `servers/{id}/hwinfo/?$`, server.PostHardwareInventory, auth.PrivLevelOperations, Authenticated, nil, 2771309303, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPost, `servers/{id}/queue_update$`, server.QueueUpdateHandler, auth.PrivLevelOperations, Authenticated, nil, 21894713, noPerlBypass},
 		{api.Version{3, 0}, http.MethodGet, `servers/{host_name}/update_status$`, server.GetServerUpdateStatusHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2384515993, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPost, `servers/{id-or-name}/update$`, server.UpdateHandler, auth.PrivLevelOperations, Authenticated, nil, 143813233, noPerlBypass},
@@ -352,6 +374,28 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		//About
 		{api.Version{3, 0}, http.MethodGet, `about/?$`, about.Handler(), auth.PrivLevelReadOnly, Authenticated, nil, 23175011663, noPerlBypass},
 
+		//OpenAPI
+		{api.Version{3, 0}, http.MethodGet, `openapi.json$`, openAPISpec.Handler, auth.PrivLevelReadOnly, Authenticated, nil, 2912739401, noPerlBypass},
+
+		//GraphQL
+		{api.Version{3, 0}, http.MethodGet, `graphql/?$`, graphql.Handler, auth.PrivLevelReadOnly, Authenticated, nil, 2781645201, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `graphql/?$`, graphql.Handler, auth.PrivLevelReadOnly, Authenticated, nil, 2781645202, noPerlBypass},
+
+		//Maintenance Windows
+		{api.Version{3, 0}, http.MethodGet, `maintenance_windows/?$`, api.ReadHandler(&maintenance.TOMaintenanceWindow{}), auth.PrivLevelReadOnly, Authenticated, nil, 2536120891, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPut, `maintenance_windows/?$`, api.UpdateHandler(&maintenance.TOMaintenanceWindow{}), auth.PrivLevelOperations, Authenticated, nil, 2536120892, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `maintenance_windows/?$`, api.CreateHandler(&maintenance.TOMaintenanceWindow{}), auth.PrivLevelOperations, Authenticated, nil, 2536120893, noPerlBypass},
+		{api.Version{3, 0}, http.MethodDelete, `maintenance_windows/?$`, api.DeleteHandler(&maintenance.TOMaintenanceWindow{}), auth.PrivLevelOperations, Authenticated, nil, 2536120894, noPerlBypass},
+
+		//Coverage Zones
+		{api.Version{3, 0}, http.MethodGet, `coveragezones/?$`, api.ReadHandler(&coveragezone.TOCoverageZone{}), auth.PrivLevelReadOnly, Authenticated, nil, 2614093571, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPut, `coveragezones/?$`, api.UpdateHandler(&coveragezone.TOCoverageZone{}), auth.PrivLevelOperations, Authenticated, nil, 2614093572, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `coveragezones/?$`, api.CreateHandler(&coveragezone.TOCoverageZone{}), auth.PrivLevelOperations, Authenticated, nil, 2614093573, noPerlBypass},
+		{api.Version{3, 0}, http.MethodDelete, `coveragezones/?$`, api.DeleteHandler(&coveragezone.TOCoverageZone{}), auth.PrivLevelOperations, Authenticated, nil, 2614093574, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `coveragezones/lookup/?$`, coveragezone.Lookup, auth.PrivLevelReadOnly, Authenticated, nil, 2614093575, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `cdns/{name}/coveragezones/?$`, coveragezone.GetCoverageZoneFile, auth.PrivLevelReadOnly, Authenticated, nil, 2614093576, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `cdns/{name}/deepcoveragezones/?$`, coveragezone.GetDeepCoverageZoneFile, auth.PrivLevelReadOnly, Authenticated, nil, 2614093577, noPerlBypass},
+
 		//Coordinates
 		{api.Version{3, 0}, http.MethodGet, `coordinates/?$`, api.ReadHandler(&coordinate.TOCoordinate{}), auth.PrivLevelReadOnly, Authenticated, nil, 2967007453, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPut, `coordinates/?$`, api.UpdateHandler(&coordinate.TOCoordinate{}), auth.PrivLevelOperations, Authenticated, nil, 2689261743, noPerlBypass},
@@ -405,6 +449,11 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{3, 0}, http.MethodPost, `origins/?$`, api.CreateHandler(&origin.TOOrigin{}), auth.PrivLevelOperations, Authenticated, nil, 20995616433, noPerlBypass},
 		{api.Version{3, 0}, http.MethodDelete, `origins/?$`, api.DeleteHandler(&origin.TOOrigin{}), auth.PrivLevelOperations, Authenticated, nil, 2602732633, noPerlBypass},
 
+		//Origin Groups
+		{api.Version{3, 0}, http.MethodGet, `origin_groups/?$`, origingroup.Get, auth.PrivLevelReadOnly, Authenticated, nil, 2446138201, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPut, `deliveryservices/{id}/origin_group/?$`, origingroup.Put, auth.PrivLevelOperations, Authenticated, nil, 2446138202, noPerlBypass},
+		{api.Version{3, 0}, http.MethodDelete, `deliveryservices/{id}/origin_group/?$`, origingroup.Delete, auth.PrivLevelOperations, Authenticated, nil, 2446138203, noPerlBypass},
+
 		//Roles
 		{api.Version{3, 0}, http.MethodGet, `roles/?$`, api.ReadHandler(&role.TORole{}), auth.PrivLevelReadOnly, Authenticated, nil, 2870885833, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPut, `roles/?$`, api.UpdateHandler(&role.TORole{}), auth.PrivLevelAdmin, Authenticated, nil, 26128974893, noPerlBypass},
@@ -461,6 +510,7 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{3, 0}, http.MethodGet, `federation_resolvers/?$`, federation_resolvers.Read, auth.PrivLevelReadOnly, Authenticated, nil, 2566087593, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPost, `federations/{id}/federation_resolvers/?$`, federations.AssignFederationResolversToFederationHandler, auth.PrivLevelAdmin, Authenticated, nil, 2566087603, noPerlBypass},
 		{api.Version{3, 0}, http.MethodGet, `federations/{id}/federation_resolvers/?$`, federations.GetFederationFederationResolversHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2566087613, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `federations/{id}/history/?$`, federations.GetFederationResolverHistoryHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2566087623, noPerlBypass},
 		{api.Version{3, 0}, http.MethodDelete, `federation_resolvers/?$`, federation_resolvers.Delete, auth.PrivLevelAdmin, Authenticated, nil, 20013, noPerlBypass},
 
 		// Federations Users
@@ -475,6 +525,7 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{3, 0}, http.MethodPut, `deliveryservices/{id}/safe/?$`, deliveryservice.UpdateSafe, auth.PrivLevelOperations, Authenticated, nil, 2472109313, perlBypass},
 		{api.Version{3, 0}, http.MethodDelete, `deliveryservices/{id}/?$`, api.DeleteHandler(&deliveryservice.TODeliveryService{}), auth.PrivLevelOperations, Authenticated, nil, 2226420743, noPerlBypass},
 		{api.Version{3, 0}, http.MethodGet, `deliveryservices/{id}/servers/eligible/?$`, deliveryservice.GetServersEligible, auth.PrivLevelReadOnly, Authenticated, nil, 2747615843, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `deliveryservices/{id}/topology/servers/?$`, deliveryservice.GetTopologyServers, auth.PrivLevelReadOnly, Authenticated, nil, 2607550781, noPerlBypass},
 
 		{api.Version{3, 0}, http.MethodGet, `deliveryservices/xmlId/{xmlid}/sslkeys$`, deliveryservice.GetSSLKeysByXMLIDV15, auth.PrivLevelAdmin, Authenticated, nil, 21357729073, noPerlBypass},
 		{api.Version{3, 0}, http.MethodPost, `deliveryservices/sslkeys/add$`, deliveryservice.AddSSLKeys, auth.PrivLevelAdmin, Authenticated, nil, 28728785833, noPerlBypass},
@@ -506,8 +557,10 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 
 		//Pattern based consistent hashing endpoint
 		{api.Version{3, 0}, http.MethodPost, `consistenthash/?$`, consistenthash.Post, auth.PrivLevelReadOnly, Authenticated, nil, 2607550763, noPerlBypass},
+		{api.Version{3, 0}, http.MethodGet, `deliveryservices/{id}/consistenthash/?$`, consistenthash.GetDeliveryServiceHash, auth.PrivLevelReadOnly, Authenticated, nil, 2607550764, noPerlBypass},
 
 		{api.Version{3, 0}, http.MethodGet, `steering/?$`, steering.Get, auth.PrivLevelSteering, Authenticated, nil, 21748524573, noPerlBypass},
+		{api.Version{3, 0}, http.MethodPost, `steering/{deliveryservice}/simulate/?$`, steering.Simulate, auth.PrivLevelReadOnly, Authenticated, nil, 2569607831, noPerlBypass},
 
 		// Plugins
 		{api.Version{3, 0}, http.MethodGet, `plugins/?$`, plugins.Get(d.Plugins), auth.PrivLevelReadOnly, Authenticated, nil, 2834985393, noPerlBypass},
@@ -716,6 +769,9 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		//About
 		{api.Version{2, 0}, http.MethodGet, `about/?$`, about.Handler(), auth.PrivLevelReadOnly, Authenticated, nil, 2317501166, noPerlBypass},
 
+		//OpenAPI
+		{api.Version{2, 0}, http.MethodGet, `openapi.json$`, openAPISpec.Handler, auth.PrivLevelReadOnly, Authenticated, nil, 2912739402, noPerlBypass},
+
 		//Coordinates
 		{api.Version{2, 0}, http.MethodGet, `coordinates/?$`, api.ReadHandler(&coordinate.TOCoordinate{}), auth.PrivLevelReadOnly, Authenticated, nil, 296700745, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPut, `coordinates/?$`, api.UpdateHandler(&coordinate.TOCoordinate{}), auth.PrivLevelOperations, Authenticated, nil, 268926174, noPerlBypass},
@@ -1379,6 +1435,8 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		}
 	}
 
+	openAPISpec.Load(openAPIRoutes(routes))
+
 	// rawRoutes are served at the root path. These should be almost exclusively old Perl pre-API routes, which have yet to be converted in all clients. New routes should be in the versioned API path.
 	rawRoutes := []RawRoute{
 		// DEPRECATED - use PUT /api/1.2/snapshot/{cdn}
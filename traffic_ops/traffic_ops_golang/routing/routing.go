@@ -146,6 +146,17 @@ type PathHandler struct {
 	ID      int
 }
 
+// mfaRouteIDs are the IDs of the Routes which may be used by a user whose session is limited to enrolling in or
+// verifying multi-factor authentication. See auth.CurrentUser.MFAPending.
+var mfaRouteIDs = []int{
+	2434348253,  // POST user/logout
+	26107016143, // GET user/current
+	2731568201,  // GET user/current/mfa
+	2731568202,  // POST user/current/mfa/enroll
+	2731568203,  // POST user/current/mfa/verify
+	2731568204,  // POST user/current/mfa/disable
+}
+
 // CreateRouteMap returns a map of methods to a slice of paths and handlers; wrapping the handlers in the appropriate middleware. Uses Semantic Versioning: routes are added to every subsequent minor version, but not subsequent major versions. For example, a 1.2 route is added to 1.3 but not 2.1. Also truncates '2.0' to '2', creating succinct major versions.
 // Returns the map of routes, and a map of API versions served.
 func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRouteIDs []int, perlHandler http.HandlerFunc, authBase middleware.AuthBase, reqTimeOutSeconds int) (map[string][]PathHandler, map[api.Version]struct{}) {
@@ -157,19 +168,21 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 	}
 	perlRoutes := GetRouteIDMap(perlRouteIDs)
 	disabledRoutes := GetRouteIDMap(disabledRouteIDs)
+	mfaRoutes := GetRouteIDMap(mfaRouteIDs)
 	m := map[string][]PathHandler{}
 	for _, r := range rs {
 		versionI := indexOfApiVersion(versions, r.Version)
 		nextMajorVer := r.Version.Major + 1
 		_, isPerlRoute := perlRoutes[r.ID]
 		_, isDisabledRoute := disabledRoutes[r.ID]
+		_, isMFARoute := mfaRoutes[r.ID]
 		for _, version := range versions[versionI:] {
 			if version.Major >= nextMajorVer {
 				break
 			}
 			vstr := strconv.FormatUint(version.Major, 10) + "." + strconv.FormatUint(version.Minor, 10)
 			path := RoutePrefix + "/" + vstr + "/" + r.Path
-			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, isMFARoute, requestTimeout)
 
 			if isPerlRoute {
 				m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: perlHandler, ID: r.ID})
@@ -182,7 +195,7 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 		}
 	}
 	for _, r := range rawRoutes {
-		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, false, requestTimeout)
 		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: middleware.Use(r.Handler, middlewares)})
 		log.Infof("adding raw route %v %v\n", r.Method, r.Path)
 	}
@@ -194,12 +207,15 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 	return m, versionSet
 }
 
-func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware.AuthBase, authenticated bool, privLevel int, requestTimeout time.Duration) []middleware.Middleware {
+func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware.AuthBase, authenticated bool, privLevel int, allowMFAPending bool, requestTimeout time.Duration) []middleware.Middleware {
 	if middlewares == nil {
 		middlewares = middleware.GetDefault(authBase.Secret, requestTimeout)
 	}
 	if authenticated { // a privLevel of zero is an unauthenticated endpoint.
 		authWrapper := authBase.GetWrapper(privLevel)
+		if allowMFAPending {
+			authWrapper = authBase.GetMFAWrapper(privLevel)
+		}
 		middlewares = append(middlewares, authWrapper)
 	}
 	return middlewares
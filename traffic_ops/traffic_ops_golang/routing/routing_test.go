@@ -32,8 +32,10 @@ import (
 	"context"
 	"net/http/httptest"
 
+	"github.com/apache/trafficcontrol/lib/go-rfc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/openapi"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/routing/middleware"
 )
 
@@ -202,6 +204,62 @@ func TestRoutes(t *testing.T) {
 	}
 }
 
+func TestOpenAPIRouteIDs(t *testing.T) {
+	fake := ServerData{Config: config.NewFakeConfig()}
+	routes, _, _, err := Routes(fake)
+	if err != nil {
+		t.Fatalf("expected: no error getting Routes, actual: %v", err)
+	}
+	ids := map[int]struct{}{}
+	for _, r := range routes {
+		ids[r.ID] = struct{}{}
+	}
+	for id := range crudRouteObjects {
+		if _, ok := ids[id]; !ok {
+			t.Errorf("expected: documented CRUD route ID %d to exist, actual: not found", id)
+		}
+	}
+	for id := range routeTypes {
+		if _, ok := ids[id]; !ok {
+			t.Errorf("expected: documented route ID %d to exist, actual: not found", id)
+		}
+	}
+}
+
+func TestOpenAPIRequiredProperties(t *testing.T) {
+	fake := ServerData{Config: config.NewFakeConfig()}
+	routes, _, _, err := Routes(fake)
+	if err != nil {
+		t.Fatalf("expected: no error getting Routes, actual: %v", err)
+	}
+	used := map[reflect.Type]struct{}{}
+	for _, r := range openAPIRoutes(routes) {
+		if len(r.Types.Required) == 0 {
+			continue
+		}
+		req := reflect.TypeOf(r.Types.Request)
+		if req.Kind() == reflect.Ptr {
+			req = req.Elem()
+		}
+		used[req] = struct{}{}
+		for _, op := range openapi.Generate(r.Version.Major, []openapi.Route{r}).Paths {
+			for _, o := range op {
+				schema := o.RequestBody.Content[rfc.ApplicationJSON].Schema
+				for _, name := range r.Types.Required {
+					if _, ok := schema.Properties[name]; !ok {
+						t.Errorf("expected: required property '%s' of %v to be a property of its request, actual: not found", name, req)
+					}
+				}
+			}
+		}
+	}
+	for req := range requiredProperties {
+		if _, ok := used[req]; !ok {
+			t.Errorf("expected: required properties of %v to be used by a documented route, actual: not used", req)
+		}
+	}
+}
+
 func TestCreateRouteMap(t *testing.T) {
 	authBase := middleware.AuthBase{"secret", func(handlerFunc http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
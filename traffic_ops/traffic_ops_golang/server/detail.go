@@ -29,6 +29,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
 	"github.com/lib/pq"
 )
@@ -236,21 +237,22 @@ JOIN type t ON server.type = t.id
 	if orderBy != "" {
 		orderByStr = " ORDER BY " + orderBy
 	}
-	rows := (*sql.Rows)(nil)
-	err := error(nil)
-	if hostName != "" && physLocationID != -1 {
-		q += ` WHERE server.host_name = $1::text AND server.phys_location = $2::bigint` + orderByStr + limitStr
-		rows, err = tx.Query(q, hostName, physLocationID)
-	} else if hostName != "" {
-		q += ` WHERE server.host_name = $1::text` + orderByStr + limitStr
-		rows, err = tx.Query(q, hostName)
-	} else if physLocationID != -1 {
-		q += ` WHERE server.phys_location = $1::int` + orderByStr + limitStr
-		rows, err = tx.Query(q, physLocationID)
-	} else {
-		q += orderByStr + limitStr
-		rows, err = tx.Query(q) // Should never happen for API <1.3, which don't allow querying without hostName or physLocation
+	tenantIDs, err := tenant.GetUserTenantIDsTx(*user, tx)
+	if err != nil {
+		return nil, errors.New("getting user tenants: " + err.Error())
+	}
+	args := []interface{}{pq.Array(tenantIDs)}
+	where := ` WHERE (server.tenant_id IS NULL OR server.tenant_id = ANY($1::bigint[]))`
+	if hostName != "" {
+		args = append(args, hostName)
+		where += ` AND server.host_name = $` + strconv.Itoa(len(args)) + `::text`
+	}
+	if physLocationID != -1 {
+		args = append(args, physLocationID)
+		where += ` AND server.phys_location = $` + strconv.Itoa(len(args)) + `::bigint`
 	}
+	// querying without hostName or physLocation should never happen for API <1.3, which don't allow it
+	rows, err := tx.Query(q+where+orderByStr+limitStr, args...)
 	if err != nil {
 		return nil, errors.New("Error querying detail servers: " + err.Error())
 	}
@@ -130,11 +130,12 @@ func TestGetDetailServers(t *testing.T) {
 	hwRows = hwRows.AddRow(1, "desc3", "val3")
 
 	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(tenantRows())
 	mock.ExpectQuery("SELECT cg.name").WillReturnRows(rows)
 	mock.ExpectQuery("SELECT serverid").WillReturnRows(hwRows)
 	mock.ExpectCommit()
 
-	actualSrvs, err := getDetailServers(db.MustBegin().Tx, &auth.CurrentUser{PrivLevel: 30}, "test", 1, "id", 10, api.Version{3, 0})
+	actualSrvs, err := getDetailServers(db.MustBegin().Tx, &auth.CurrentUser{PrivLevel: 30, TenantID: 1}, "test", 1, "id", 10, api.Version{3, 0})
 	if err != nil {
 		t.Fatalf("an error '%s' occurred during read", err)
 	}
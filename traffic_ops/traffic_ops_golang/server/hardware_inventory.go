@@ -0,0 +1,153 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/lib/pq"
+)
+
+const replaceHWInfoQuery = `
+INSERT INTO hwinfo (serverid, description, val)
+SELECT $1, description, val
+FROM UNNEST($2::text[], $3::text[]) AS inv(description, val)
+RETURNING id, description, val, last_updated
+`
+
+// PostHardwareInventory handles POST requests of the hardware inventory of a server, typically from the server itself,
+// which replaces all of its hwinfo.
+func PostHardwareInventory(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+
+	inv := tc.ServerHardwareInventory{}
+	if err := json.NewDecoder(r.Body).Decode(&inv); err != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	if err := inv.Validate(); err != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	server, userErr, sysErr, errCode := getAuthorizedLifecycleServer(inf)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
+	hwInfo, err := replaceHWInfo(tx, server, hardwareInventoryToHWInfo(inv))
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+
+	msg := fmt.Sprintf("Updated hardware inventory of %s.%s with %d items", server.HostName, server.DomainName, len(hwInfo))
+	api.CreateChangeLogRawTx(api.ApiChange, msg, inf.User, tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, msg, hwInfo)
+}
+
+// hardwareInventoryToHWInfo flattens a hardware inventory into hwinfo descriptions and values, omitting unknown
+// values.
+func hardwareInventoryToHWInfo(inv tc.ServerHardwareInventory) []tc.HWInfo {
+	hwInfo := []tc.HWInfo{}
+	add := func(description string, val string) {
+		if val != "" {
+			hwInfo = append(hwInfo, tc.HWInfo{Description: description, Val: val})
+		}
+	}
+	addInt := func(description string, val int) {
+		if val != 0 {
+			add(description, strconv.Itoa(val))
+		}
+	}
+
+	add("Manufacturer", inv.Manufacturer)
+	add("Model", inv.Model)
+	add("Serial Number", inv.SerialNumber)
+	add("BIOS Version", inv.BIOSVersion)
+	addInt("Memory (MB)", inv.MemoryMB)
+	for i, cpu := range inv.CPUs {
+		prefix := "CPU " + strconv.Itoa(i) + " "
+		add(prefix+"Model", cpu.Model)
+		addInt(prefix+"Cores", cpu.Cores)
+		addInt(prefix+"Threads", cpu.Threads)
+	}
+	for _, disk := range inv.Disks {
+		prefix := "Disk " + disk.Device + " "
+		add(prefix+"Model", disk.Model)
+		add(prefix+"Serial Number", disk.SerialNumber)
+		addInt(prefix+"Size (GB)", disk.SizeGB)
+	}
+	for _, nic := range inv.NICs {
+		prefix := "NIC " + nic.Name + " "
+		add(prefix+"MAC Address", nic.MACAddress)
+		add(prefix+"Driver", nic.Driver)
+		add(prefix+"Firmware", nic.Firmware)
+		addInt(prefix+"Speed (Mbps)", nic.SpeedMbps)
+	}
+	return hwInfo
+}
+
+// replaceHWInfo replaces all of the hwinfo of the given server, and returns the inserted rows.
+func replaceHWInfo(tx *sql.Tx, server lifecycleServer, hwInfo []tc.HWInfo) ([]tc.HWInfo, error) {
+	if _, err := tx.Exec(`DELETE FROM hwinfo WHERE serverid = $1`, server.ID); err != nil {
+		return nil, errors.New("deleting hwinfo: " + err.Error())
+	}
+
+	descriptions := make([]string, 0, len(hwInfo))
+	vals := make([]string, 0, len(hwInfo))
+	for _, info := range hwInfo {
+		descriptions = append(descriptions, info.Description)
+		vals = append(vals, info.Val)
+	}
+	rows, err := tx.Query(replaceHWInfoQuery, server.ID, pq.Array(descriptions), pq.Array(vals))
+	if err != nil {
+		return nil, errors.New("inserting hwinfo: " + err.Error())
+	}
+	defer rows.Close()
+
+	inserted := []tc.HWInfo{}
+	for rows.Next() {
+		info := tc.HWInfo{ServerID: server.ID, ServerHostName: server.HostName}
+		if err := rows.Scan(&info.ID, &info.Description, &info.Val, &info.LastUpdated); err != nil {
+			return nil, errors.New("scanning hwinfo: " + err.Error())
+		}
+		inserted = append(inserted, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("hwinfo rows: " + err.Error())
+	}
+	return inserted, nil
+}
@@ -0,0 +1,471 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/util/monitorhlp"
+
+	"github.com/lib/pq"
+)
+
+const selectLifecycleServerQuery = `
+SELECT
+  s.host_name,
+  s.domain_name,
+  st.name AS status,
+  t.name AS type,
+  s.cachegroup,
+  s.cdn_id,
+  cdn.name AS cdn_name,
+  s.tenant_id,
+  s.upd_pending,
+  s.reval_pending
+FROM server s
+JOIN status st ON st.id = s.status
+JOIN type t ON t.id = s.type
+JOIN cdn ON cdn.id = s.cdn_id
+WHERE s.id = $1
+`
+
+const selectServerCheckColumnsQuery = `
+SELECT servercheck_short_name, servercheck_column_name
+FROM to_extension
+WHERE servercheck_short_name = ANY($1)
+AND servercheck_column_name IS NOT NULL
+`
+
+// serverCheckColumnRegex matches the names of the result columns of the servercheck table.
+var serverCheckColumnRegex = regexp.MustCompile(`^[a-z]{2}$`)
+
+// lifecycleServer is a server whose lifecycle transitions are being checked.
+type lifecycleServer struct {
+	ID           int
+	HostName     string
+	DomainName   string
+	Status       string
+	Type         string
+	CachegroupID int
+	CDNID        int
+	CDNName      string
+	TenantID     *int
+	UpdPending   bool
+	RevalPending bool
+}
+
+// lifecycleFacts are what the gate checks of the lifecycle transitions of a server are evaluated against.
+type lifecycleFacts struct {
+	// ServerChecks are the servercheck results of the server, keyed by the check's short name. Checks without a
+	// result are missing.
+	ServerChecks map[string]int
+	UpdPending   bool
+	RevalPending bool
+	// MonitorAvailable is whether Traffic Monitor reports the server available, or nil if that couldn't be
+	// determined, in which case MonitorErr is why.
+	MonitorAvailable *bool
+	MonitorErr       string
+}
+
+// GetLifecycle handles GET requests for the lifecycle of a server: the states it may transition to, and whether the
+// gate checks of each transition pass.
+func GetLifecycle(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	server, userErr, sysErr, errCode := getAuthorizedLifecycleServer(inf)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	lifecycle, err := getLifecycle(inf.Tx.Tx, inf.Config.ServerLifecycle, server)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.WriteResp(w, r, lifecycle)
+}
+
+// TransitionLifecycle handles POST requests to transition a server to a lifecycle state, which is rejected unless
+// the transition is allowed from the server's current state and all of its gate checks pass.
+func TransitionLifecycle(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+
+	req := tc.ServerLifecycleTransitionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	server, userErr, sysErr, errCode := getAuthorizedLifecycleServer(inf)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
+	if !lifecycleTransitionAllowed(server.Status, req.State) {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, fmt.Errorf("server %s cannot transition from %s to %s", server.HostName, server.Status, req.State), nil)
+		return
+	}
+
+	if userErr, sysErr, errCode := checkLifecycleGateOfState(tx, inf.Config.ServerLifecycle, server, req.State); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
+	status, ok, err := dbhelpers.GetStatusByName(string(req.State), tx)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting lifecycle status: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("lifecycle status "+string(req.State)+" does not exist"))
+		return
+	}
+	if err := updateServerStatusAndOfflineReason(server.ID, *status.ID, nil, tx); err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+
+	msg := "Transitioned " + server.HostName + "." + server.DomainName + " from " + server.Status + " to " + string(req.State)
+	if strings.HasPrefix(server.Type, tc.CacheTypeEdge.String()) || strings.HasPrefix(server.Type, tc.CacheTypeMid.String()) {
+		if err := queueUpdatesOnChildCaches(tx, server.CDNID, server.CachegroupID); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+		msg += " and queued updates on all child caches"
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, msg, inf.User, tx)
+
+	server.Status = string(req.State)
+	lifecycle, err := getLifecycle(tx, inf.Config.ServerLifecycle, server)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, msg, lifecycle)
+}
+
+// checkLifecycleGateOfState returns a user error if any of the gate checks of the given state fail for the server.
+func checkLifecycleGateOfState(tx *sql.Tx, cfg config.ConfigServerLifecycle, server lifecycleServer, state tc.ServerLifecycleState) (error, error, int) {
+	gate := cfg.Gates[string(state)]
+	facts, err := getLifecycleFacts(tx, []config.ConfigServerLifecycleGate{gate}, server)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	failures := []string{}
+	for _, check := range checkLifecycleGate(gate, facts) {
+		if !check.Passed {
+			failures = append(failures, check.Name+": "+check.Message)
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("server %s is not ready to transition to %s: %s", server.HostName, state, strings.Join(failures, "; ")), nil, http.StatusBadRequest
+	}
+	return nil, nil, http.StatusOK
+}
+
+// checkLifecycleStatusChange returns a user error if changing the Status of the server with the given ID to the one
+// with the given name would enter a lifecycle state whose gate checks fail, so that setting a server's Status
+// directly can't bypass the gates of its lifecycle.
+//
+// Unlike transitions through the lifecycle endpoint, which states the server may enter from its current Status
+// isn't restricted, so that e.g. an ADMIN_DOWN server can still be returned to REPORTED.
+func checkLifecycleStatusChange(tx *sql.Tx, cfg config.ConfigServerLifecycle, serverID int, status string) (error, error, int) {
+	state, ok := tc.ServerLifecycleStateFromStatus(status)
+	if !ok {
+		return nil, nil, http.StatusOK
+	}
+	server, ok, err := getLifecycleServer(tx, serverID)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	if !ok {
+		return fmt.Errorf("server ID %d not found", serverID), nil, http.StatusNotFound
+	}
+	if server.Status == status {
+		return nil, nil, http.StatusOK
+	}
+	return checkLifecycleGateOfState(tx, cfg, server, state)
+}
+
+// getAuthorizedLifecycleServer returns the server of the request's "id" parameter, or an error if it doesn't exist
+// or the user isn't authorized on its tenant.
+func getAuthorizedLifecycleServer(inf *api.APIInfo) (lifecycleServer, error, error, int) {
+	server, ok, err := getLifecycleServer(inf.Tx.Tx, inf.IntParams["id"])
+	if err != nil {
+		return server, nil, err, http.StatusInternalServerError
+	}
+	if !ok {
+		return server, fmt.Errorf("server ID %d not found", inf.IntParams["id"]), nil, http.StatusNotFound
+	}
+	if userErr, sysErr, errCode := checkTenancy(server.TenantID, inf.User, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		return server, userErr, sysErr, errCode
+	}
+	return server, nil, nil, http.StatusOK
+}
+
+func getLifecycleServer(tx *sql.Tx, id int) (lifecycleServer, bool, error) {
+	server := lifecycleServer{ID: id}
+	if err := tx.QueryRow(selectLifecycleServerQuery, id).Scan(
+		&server.HostName,
+		&server.DomainName,
+		&server.Status,
+		&server.Type,
+		&server.CachegroupID,
+		&server.CDNID,
+		&server.CDNName,
+		&server.TenantID,
+		&server.UpdPending,
+		&server.RevalPending,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return server, false, nil
+		}
+		return server, false, errors.New("querying lifecycle server: " + err.Error())
+	}
+	return server, true, nil
+}
+
+// lifecycleTransitionAllowed returns whether a server with the given Status may transition to the given state.
+func lifecycleTransitionAllowed(status string, state tc.ServerLifecycleState) bool {
+	for _, next := range tc.NextServerLifecycleStates(status) {
+		if next == state {
+			return true
+		}
+	}
+	return false
+}
+
+// getLifecycle returns the lifecycle of the given server, checking the gates of all of the transitions it may make.
+func getLifecycle(tx *sql.Tx, cfg config.ConfigServerLifecycle, server lifecycleServer) (tc.ServerLifecycle, error) {
+	lifecycle := tc.ServerLifecycle{
+		ServerID:    server.ID,
+		HostName:    server.HostName,
+		Status:      server.Status,
+		Transitions: []tc.ServerLifecycleTransition{},
+	}
+	if state, ok := tc.ServerLifecycleStateFromStatus(server.Status); ok {
+		lifecycle.State = &state
+	}
+
+	next := tc.NextServerLifecycleStates(server.Status)
+	gates := make([]config.ConfigServerLifecycleGate, 0, len(next))
+	for _, state := range next {
+		gates = append(gates, cfg.Gates[string(state)])
+	}
+	facts, err := getLifecycleFacts(tx, gates, server)
+	if err != nil {
+		return lifecycle, err
+	}
+	for i, state := range next {
+		transition := tc.ServerLifecycleTransition{State: state, Ready: true, Checks: checkLifecycleGate(gates[i], facts)}
+		for _, check := range transition.Checks {
+			transition.Ready = transition.Ready && check.Passed
+		}
+		lifecycle.Transitions = append(lifecycle.Transitions, transition)
+	}
+	return lifecycle, nil
+}
+
+// getLifecycleFacts returns the facts about the given server needed to check the given gates. Traffic Monitor is
+// only asked about the server if a gate requires it.
+func getLifecycleFacts(tx *sql.Tx, gates []config.ConfigServerLifecycleGate, server lifecycleServer) (lifecycleFacts, error) {
+	facts := lifecycleFacts{
+		ServerChecks: map[string]int{},
+		UpdPending:   server.UpdPending,
+		RevalPending: server.RevalPending,
+	}
+	checkNames := []string{}
+	monitorRequired := false
+	for _, gate := range gates {
+		for name := range gate.ServerChecks {
+			checkNames = append(checkNames, name)
+		}
+		monitorRequired = monitorRequired || gate.MonitorAvailable
+	}
+
+	if len(checkNames) > 0 {
+		checks, err := getServerCheckResults(tx, server.ID, checkNames)
+		if err != nil {
+			return facts, err
+		}
+		facts.ServerChecks = checks
+	}
+
+	if monitorRequired {
+		available, monitorErr, err := getMonitorAvailable(tx, server)
+		if err != nil {
+			return facts, err
+		}
+		facts.MonitorAvailable = available
+		facts.MonitorErr = monitorErr
+	}
+	return facts, nil
+}
+
+// getServerCheckResults returns the servercheck results of the given server for the checks with the given short
+// names, keyed by short name. Checks which don't exist or have no result for the server are omitted.
+func getServerCheckResults(tx *sql.Tx, serverID int, names []string) (map[string]int, error) {
+	rows, err := tx.Query(selectServerCheckColumnsQuery, pq.Array(names))
+	if err != nil {
+		return nil, errors.New("querying servercheck columns: " + err.Error())
+	}
+	defer rows.Close()
+	columns := map[string]string{}
+	for rows.Next() {
+		name, column := "", ""
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, errors.New("scanning servercheck columns: " + err.Error())
+		}
+		if !serverCheckColumnRegex.MatchString(column) {
+			return nil, errors.New("servercheck " + name + " has invalid column '" + column + "'")
+		}
+		columns[name] = column
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("servercheck column rows: " + err.Error())
+	}
+
+	results := map[string]int{}
+	for name, column := range columns {
+		result := sql.NullInt64{}
+		if err := tx.QueryRow(`SELECT `+column+` FROM servercheck WHERE server = $1`, serverID).Scan(&result); err != nil {
+			if err == sql.ErrNoRows {
+				return results, nil
+			}
+			return nil, errors.New("querying servercheck result: " + err.Error())
+		}
+		if result.Valid {
+			results[name] = int(result.Int64)
+		}
+	}
+	return results, nil
+}
+
+// getMonitorAvailable returns whether the Traffic Monitor of the server's CDN reports it available. If that can't
+// be determined, e.g. because Traffic Monitor can't be reached or doesn't monitor the server, it returns nil and a
+// message saying why.
+func getMonitorAvailable(tx *sql.Tx, server lifecycleServer) (*bool, string, error) {
+	monitors, err := monitorhlp.GetURLs(tx)
+	if err != nil {
+		return nil, "", errors.New("getting monitors: " + err.Error())
+	}
+	monitor, ok := monitors[tc.CDNName(server.CDNName)]
+	if !ok {
+		return nil, "no Traffic Monitor is online for CDN " + server.CDNName, nil
+	}
+	client, err := monitorhlp.GetClient(tx)
+	if err != nil {
+		return nil, "", errors.New("getting monitor client: " + err.Error())
+	}
+	crStates, err := monitorhlp.GetCRStates(monitor, client)
+	if err != nil {
+		return nil, "getting states from Traffic Monitor " + monitor + ": " + err.Error(), nil
+	}
+	state, ok := crStates.Caches[tc.CacheName(server.HostName)]
+	if !ok {
+		return nil, "not monitored by Traffic Monitor " + monitor, nil
+	}
+	return &state.IsAvailable, "", nil
+}
+
+// checkLifecycleGate returns the results of the checks of the given gate against the given facts.
+func checkLifecycleGate(gate config.ConfigServerLifecycleGate, facts lifecycleFacts) []tc.ServerLifecycleCheck {
+	checks := []tc.ServerLifecycleCheck{}
+
+	names := make([]string, 0, len(gate.ServerChecks))
+	for name := range gate.ServerChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		minimum := gate.ServerChecks[name]
+		check := tc.ServerLifecycleCheck{Name: "servercheck " + name}
+		result, ok := facts.ServerChecks[name]
+		switch {
+		case !ok:
+			check.Message = "no result"
+		case result < minimum:
+			check.Message = fmt.Sprintf("%d is below the minimum of %d", result, minimum)
+		default:
+			check.Passed = true
+			check.Message = fmt.Sprintf("%d meets the minimum of %d", result, minimum)
+		}
+		checks = append(checks, check)
+	}
+
+	if gate.ORTSuccess {
+		check := tc.ServerLifecycleCheck{Name: "ORT"}
+		switch {
+		case facts.UpdPending && facts.RevalPending:
+			check.Message = "updates and revalidations are pending"
+		case facts.UpdPending:
+			check.Message = "updates are pending"
+		case facts.RevalPending:
+			check.Message = "revalidations are pending"
+		default:
+			check.Passed = true
+			check.Message = "no updates or revalidations are pending"
+		}
+		checks = append(checks, check)
+	}
+
+	if gate.MonitorAvailable {
+		check := tc.ServerLifecycleCheck{Name: "Traffic Monitor"}
+		switch {
+		case facts.MonitorAvailable == nil:
+			check.Message = facts.MonitorErr
+		case !*facts.MonitorAvailable:
+			check.Message = "unavailable"
+		default:
+			check.Passed = true
+			check.Message = "available"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
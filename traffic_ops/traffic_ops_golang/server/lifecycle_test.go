@@ -0,0 +1,173 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestLifecycleTransitionAllowed(t *testing.T) {
+	tests := []struct {
+		status   string
+		state    tc.ServerLifecycleState
+		expected bool
+	}{
+		{"PRE_PROD", tc.ServerLifecycleStateBurnIn, true},
+		{"PRE_PROD", tc.ServerLifecycleStateOnline, false},
+		{"BURN_IN", tc.ServerLifecycleStateReported, true},
+		{"REPORTED", tc.ServerLifecycleStateOnline, true},
+		{"ONLINE", tc.ServerLifecycleStateDecommissioned, true},
+		{"DECOMMISSIONED", tc.ServerLifecycleStateReported, false},
+		{"ADMIN_DOWN", tc.ServerLifecycleStateDecommissioned, true},
+		{"ADMIN_DOWN", tc.ServerLifecycleStateReported, false},
+	}
+	for _, test := range tests {
+		if actual := lifecycleTransitionAllowed(test.status, test.state); actual != test.expected {
+			t.Errorf("transition from %s to %s allowed expected: %v, actual: %v", test.status, test.state, test.expected, actual)
+		}
+	}
+}
+
+func TestCheckLifecycleGate(t *testing.T) {
+	gate := config.ConfigServerLifecycleGate{
+		ServerChecks:     map[string]int{"ILO": 1, "MTU": 1, "10G": 1},
+		ORTSuccess:       true,
+		MonitorAvailable: true,
+	}
+	facts := lifecycleFacts{
+		ServerChecks: map[string]int{"ILO": 1, "MTU": 0},
+		UpdPending:   true,
+		MonitorErr:   "not monitored by Traffic Monitor tm.example.net",
+	}
+
+	expected := []tc.ServerLifecycleCheck{
+		{Name: "servercheck 10G", Passed: false, Message: "no result"},
+		{Name: "servercheck ILO", Passed: true, Message: "1 meets the minimum of 1"},
+		{Name: "servercheck MTU", Passed: false, Message: "0 is below the minimum of 1"},
+		{Name: "ORT", Passed: false, Message: "updates are pending"},
+		{Name: "Traffic Monitor", Passed: false, Message: "not monitored by Traffic Monitor tm.example.net"},
+	}
+	if actual := checkLifecycleGate(gate, facts); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("checkLifecycleGate expected: %+v, actual: %+v", expected, actual)
+	}
+
+	facts = lifecycleFacts{ServerChecks: map[string]int{"ILO": 1, "MTU": 1, "10G": 2}, MonitorAvailable: util.BoolPtr(true)}
+	for _, check := range checkLifecycleGate(gate, facts) {
+		if !check.Passed {
+			t.Errorf("checkLifecycleGate expected all checks to pass, actual: %s failed: %s", check.Name, check.Message)
+		}
+	}
+
+	if actual := checkLifecycleGate(config.ConfigServerLifecycleGate{}, facts); len(actual) != 0 {
+		t.Errorf("checkLifecycleGate of an empty gate expected: no checks, actual: %+v", actual)
+	}
+}
+
+func TestCheckLifecycleStatusChange(t *testing.T) {
+	cfg := config.ConfigServerLifecycle{
+		Gates: map[string]config.ConfigServerLifecycleGate{
+			string(tc.ServerLifecycleStateReported): {ORTSuccess: true},
+		},
+	}
+	tests := []struct {
+		name      string
+		current   string
+		status    string
+		queried   bool
+		expectErr bool
+	}{
+		{"gate fails", "BURN_IN", "REPORTED", true, true},
+		{"unchanged status", "REPORTED", "REPORTED", true, false},
+		{"not a lifecycle state", "REPORTED", "ADMIN_DOWN", false, false},
+		{"state without a gate", "REPORTED", "DECOMMISSIONED", true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer mockDB.Close()
+
+			mock.ExpectBegin()
+			if test.queried {
+				rows := sqlmock.NewRows([]string{"host_name", "domain_name", "status", "type", "cachegroup", "cdn_id", "cdn_name", "tenant_id", "upd_pending", "reval_pending"})
+				rows.AddRow("edge", "example.net", test.current, "EDGE", 1, 1, "cdn", nil, true, false)
+				mock.ExpectQuery("SELECT").WithArgs(42).WillReturnRows(rows)
+			}
+			mock.ExpectCommit()
+
+			tx, err := mockDB.Begin()
+			if err != nil {
+				t.Fatalf("creating transaction: %v", err)
+			}
+			userErr, sysErr, code := checkLifecycleStatusChange(tx, cfg, 42, test.status)
+			if sysErr != nil {
+				t.Fatalf("unexpected system error: %v", sysErr)
+			}
+			if test.expectErr && (userErr == nil || code != http.StatusBadRequest) {
+				t.Errorf("expected a gate failure with code 400, actual: error %v and code %d", userErr, code)
+			} else if !test.expectErr && userErr != nil {
+				t.Errorf("expected no error, actual: %v", userErr)
+			}
+			tx.Commit()
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestHardwareInventoryToHWInfo(t *testing.T) {
+	inv := tc.ServerHardwareInventory{
+		Manufacturer: "Acme",
+		SerialNumber: "SN123",
+		MemoryMB:     262144,
+		CPUs:         []tc.ServerHardwareCPU{{Model: "Xeon", Cores: 16}},
+		Disks:        []tc.ServerHardwareDisk{{Device: "sda", SizeGB: 960}},
+		NICs:         []tc.ServerHardwareNIC{{Name: "eth0", MACAddress: "00:11:22:33:44:55", SpeedMbps: 10000}},
+	}
+	expected := []tc.HWInfo{
+		{Description: "Manufacturer", Val: "Acme"},
+		{Description: "Serial Number", Val: "SN123"},
+		{Description: "Memory (MB)", Val: "262144"},
+		{Description: "CPU 0 Model", Val: "Xeon"},
+		{Description: "CPU 0 Cores", Val: "16"},
+		{Description: "Disk sda Size (GB)", Val: "960"},
+		{Description: "NIC eth0 MAC Address", Val: "00:11:22:33:44:55"},
+		{Description: "NIC eth0 Speed (Mbps)", Val: "10000"},
+	}
+	if actual := hardwareInventoryToHWInfo(inv); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("hardwareInventoryToHWInfo expected: %+v, actual: %+v", expected, actual)
+	}
+
+	inv.Disks = append(inv.Disks, tc.ServerHardwareDisk{Device: "sda"})
+	if err := inv.Validate(); err == nil {
+		t.Error("expected an error validating an inventory with duplicate disks, actual: nil")
+	}
+}
@@ -53,6 +53,10 @@ func QueueUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	serverID := int64(inf.IntParams["id"])
+	if userErr, sysErr, errCode := checkServerTenancy(inf.IntParams["id"], inf.User, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
 	queue := reqObj.Action == "queue"
 	ok, err := queueUpdate(inf.Tx.Tx, serverID, queue)
 	if err != nil {
@@ -60,6 +60,7 @@ JOIN phys_location pl ON s.phys_location = pl.id
 JOIN profile p ON s.profile = p.id
 JOIN status st ON s.status = st.id
 JOIN type t ON s.type = t.id
+LEFT JOIN tenant tn ON s.tenant_id = tn.id
 `
 
 const serverCountQuery = `
@@ -103,7 +104,9 @@ SELECT
 	s.type AS server_type_id,
 	s.upd_pending AS upd_pending,
 	s.xmpp_id,
-	s.xmpp_passwd
+	s.xmpp_passwd,
+	tn.name AS tenant,
+	s.tenant_id
 ` + serversFromAndJoin
 
 const InterfacesArray = `
@@ -165,7 +168,8 @@ INSERT INTO server (
 	type,
 	upd_pending,
 	xmpp_id,
-	xmpp_passwd
+	xmpp_passwd,
+	tenant_id
 ) VALUES (
 	:cachegroup_id,
 	:cdn_id,
@@ -191,7 +195,8 @@ INSERT INTO server (
 	:server_type_id,
 	:upd_pending,
 	:xmpp_id,
-	:xmpp_passwd
+	:xmpp_passwd,
+	:tenant_id
 ) RETURNING
 	(SELECT name FROM cachegroup WHERE cachegroup.id=server.cachegroup) AS cachegroup,
 	cachegroup AS cachegroup_id,
@@ -226,7 +231,9 @@ INSERT INTO server (
 	tcp_port,
 	(SELECT name FROM type WHERE type.id=server.type) AS server_type,
 	type AS server_type_id,
-	upd_pending
+	upd_pending,
+	(SELECT name FROM tenant WHERE tenant.id=server.tenant_id) AS tenant,
+	tenant_id
 `
 
 const updateQuery = `
@@ -254,7 +261,8 @@ UPDATE server SET
 	tcp_port=:tcp_port,
 	type=:server_type_id,
 	upd_pending=:upd_pending,
-	xmpp_passwd=:xmpp_passwd
+	xmpp_passwd=:xmpp_passwd,
+	tenant_id=:tenant_id
 WHERE id=:id
 RETURNING
 	(SELECT name FROM cachegroup WHERE cachegroup.id=server.cachegroup) AS cachegroup,
@@ -290,7 +298,9 @@ RETURNING
 	tcp_port,
 	(SELECT name FROM type WHERE type.id=server.type) AS server_type,
 	type AS server_type_id,
-	upd_pending
+	upd_pending,
+	(SELECT name FROM tenant WHERE tenant.id=server.tenant_id) AS tenant,
+	tenant_id
 `
 
 const deleteServerQuery = `DELETE FROM server WHERE id=$1`
@@ -534,6 +544,10 @@ func Read(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for i := range servers {
+		clearTenant(&servers[i].CommonServerProperties)
+	}
+
 	if version.Major <= 1 {
 		legacyServers := make([]tc.ServerNullableV11, 0, len(servers))
 		for _, server := range servers {
@@ -592,6 +606,7 @@ func ReadID(w http.ResponseWriter, r *http.Request) {
 	}
 	legacyServers := make([]tc.ServerNullableV11, 0, len(servers))
 	for _, server := range servers {
+		clearTenant(&server.CommonServerProperties)
 		legacyServer, err := server.ToServerV2()
 		if err != nil {
 			api.HandleDeprecatedErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("failed to convert servers to legacy format: %v", err), &alternative)
@@ -611,7 +626,8 @@ JOIN cdn cdn ON s.cdn_id = cdn.id
 JOIN phys_location pl ON s.phys_location = pl.id
 JOIN profile p ON s.profile = p.id
 JOIN status st ON s.status = st.id
-JOIN type t ON s.type = t.id ` + where +
+JOIN type t ON s.type = t.id
+LEFT JOIN tenant tn ON s.tenant_id = tn.id ` + where +
 		` UNION ALL
 	select max(last_updated) as t from last_deleted l where l.table_name='server') as res`
 }
@@ -667,6 +683,12 @@ func getServers(h http.Header, params map[string]string, tx *sqlx.Tx, user *auth
 		return nil, 0, util.JoinErrs(errs), nil, http.StatusBadRequest, nil
 	}
 
+	tenantIDs, err := tenant.GetUserTenantIDsTx(*user, tx.Tx)
+	if err != nil {
+		return nil, 0, nil, errors.New("getting user tenants: " + err.Error()), http.StatusInternalServerError, nil
+	}
+	where, queryValues = dbhelpers.AddOptionalTenancyCheck(where, queryValues, "s.tenant_id", tenantIDs)
+
 	// TODO there's probably a cleaner way to do this by preparing a NamedStmt first and using its QueryRow method
 	var serverCount uint64
 	countRows, err := tx.NamedQuery(serverCountQuery+queryAddition+where, queryValues)
@@ -732,7 +754,7 @@ func getServers(h http.Header, params map[string]string, tx *sqlx.Tx, user *auth
 
 	// if ds requested uses mid-tier caches, add those to the list as well
 	if usesMids {
-		midIDs, userErr, sysErr, errCode := getMidServers(ids, servers, tx)
+		midIDs, userErr, sysErr, errCode := getMidServers(ids, servers, tenantIDs, tx)
 
 		log.Debugf("getting mids: %v, %v, %s\n", userErr, sysErr, http.StatusText(errCode))
 
@@ -820,7 +842,7 @@ func getServers(h http.Header, params map[string]string, tx *sqlx.Tx, user *auth
 	return returnable, serverCount, nil, nil, http.StatusOK, &maxTime
 }
 
-// getMidServers gets the mids used by the servers in this DS.
+// getMidServers gets the mids used by the servers in this DS, of those owned by the given tenants or by none.
 //
 // Original comment from the Perl code:
 //
@@ -828,7 +850,7 @@ func getServers(h http.Header, params map[string]string, tx *sqlx.Tx, user *auth
 // pulling the cachegroups of the edges and finding those cachegroups parent
 // cachegroup... then we see which servers have cachegroup in parent cachegroup
 // list...that's how we find mids for the ds :)
-func getMidServers(edgeIDs []int, servers map[int]tc.ServerNullable, tx *sqlx.Tx) ([]int, error, error, int) {
+func getMidServers(edgeIDs []int, servers map[int]tc.ServerNullable, tenantIDs []int, tx *sqlx.Tx) ([]int, error, error, int) {
 	if len(edgeIDs) == 0 {
 		return nil, nil, nil, http.StatusOK
 	}
@@ -840,9 +862,10 @@ func getMidServers(edgeIDs []int, servers map[int]tc.ServerNullable, tx *sqlx.Tx
 	WHERE cg.id IN (
 	SELECT s.cachegroup FROM server AS s
 	WHERE s.id IN (?)))
+	AND (s.tenant_id IS NULL OR s.tenant_id = ANY(CAST(? AS bigint[])))
 	`
 
-	query, args, err := sqlx.In(q, edgeIDs)
+	query, args, err := sqlx.In(q, edgeIDs, pq.Array(tenantIDs))
 	if err != nil {
 		return nil, nil, fmt.Errorf("constructing mid servers query: %v", err), http.StatusInternalServerError
 	}
@@ -973,6 +996,35 @@ func deleteInterfaces(id int, tx *sql.Tx) (error, error, int) {
 	return nil, nil, http.StatusOK
 }
 
+// checkTenancy returns an error if the user isn't authorized on the given tenant of a server, which may be nil if the
+// server has no tenant.
+func checkTenancy(tenantID *int, user *auth.CurrentUser, tx *sql.Tx) (error, error, int) {
+	authorized, err := tenant.IsOptionalResourceAuthorizedToUserTx(tenantID, user, tx)
+	if err != nil {
+		return nil, errors.New("checking server tenancy: " + err.Error()), http.StatusInternalServerError
+	}
+	if !authorized {
+		return tc.TenantUserNotAuthError, nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
+
+// checkServerTenancy returns an error if the user isn't authorized on the tenant of the server with the given ID. A
+// server which doesn't exist has no tenant; callers are responsible for checking existence.
+func checkServerTenancy(serverID int, user *auth.CurrentUser, tx *sql.Tx) (error, error, int) {
+	tenantID := (*int)(nil)
+	if err := tx.QueryRow(`SELECT tenant_id FROM server WHERE id = $1`, serverID).Scan(&tenantID); err != nil && err != sql.ErrNoRows {
+		return nil, errors.New("getting server tenant: " + err.Error()), http.StatusInternalServerError
+	}
+	return checkTenancy(tenantID, user, tx)
+}
+
+// clearTenant removes the tenant of a server, which isn't a part of legacy API versions.
+func clearTenant(s *tc.CommonServerProperties) {
+	s.Tenant = nil
+	s.TenantID = nil
+}
+
 func Update(w http.ResponseWriter, r *http.Request) {
 	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
 	tx := inf.Tx.Tx
@@ -983,11 +1035,19 @@ func Update(w http.ResponseWriter, r *http.Request) {
 	defer inf.Close()
 
 	//Get original xmppid
-	origSer, _, userErr, sysErr, _, _ := getServers(r.Header, inf.Params, inf.Tx, inf.User, false)
+	origSer, _, userErr, sysErr, errCode, _ := getServers(r.Header, inf.Params, inf.Tx, inf.User, false)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
 		return
 	}
+	if len(origSer) < 1 {
+		api.HandleErr(w, r, tx, http.StatusNotFound, errors.New("no server found with this id"), nil)
+		return
+	}
+	if userErr, sysErr, errCode = checkTenancy(origSer[0].TenantID, inf.User, tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
 	originalXMPPID := *origSer[0].XMPPID
 	changeXMPPID := false
 
@@ -1062,6 +1122,14 @@ func Update(w http.ResponseWriter, r *http.Request) {
 	server.ID = new(int)
 	*server.ID = inf.IntParams["id"]
 
+	// the tenancy of servers isn't a part of legacy API versions, so updates through them don't change it
+	if inf.Version.Major < 3 {
+		server.TenantID = origSer[0].TenantID
+	} else if userErr, sysErr, errCode = checkTenancy(server.TenantID, inf.User, tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
 	if userErr, sysErr, errCode = checkTypeChangeSafety(server.CommonServerProperties, inf.Tx); userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
 		return
@@ -1072,6 +1140,20 @@ func Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	status, ok, err := dbhelpers.GetStatusByID(*server.StatusID, tx)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("getting server status: %v", err))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, fmt.Errorf("status #%d does not exist", *server.StatusID), nil)
+		return
+	}
+	if userErr, sysErr, errCode = checkLifecycleStatusChange(tx, inf.Config.ServerLifecycle, *server.ID, *status.Name); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
 	rows, err := inf.Tx.NamedQuery(updateQuery, server)
 	if err != nil {
 		userErr, sysErr, errCode = api.ParseDBError(err)
@@ -1108,6 +1190,9 @@ func Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if inf.Version.Major < 3 {
+		clearTenant(&server.CommonServerProperties)
+	}
 	if inf.Version.Major >= 3 {
 		api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Server updated", tc.ServerNullable{CommonServerProperties: server.CommonServerProperties, Interfaces: interfaces})
 	} else if inf.Version.Minor <= 1 {
@@ -1129,6 +1214,7 @@ func createV1(inf *api.APIInfo, w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, tx, http.StatusBadRequest, err, nil)
 		return
 	}
+	clearTenant(&server.CommonServerProperties)
 
 	if err := validateV1(&server, tx); err != nil {
 		api.HandleErr(w, r, tx, http.StatusBadRequest, err, nil)
@@ -1185,6 +1271,7 @@ func createV2(inf *api.APIInfo, w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, tx, http.StatusBadRequest, err, nil)
 		return
 	}
+	clearTenant(&server.CommonServerProperties)
 
 	str := uuid.New().String()
 	server.XMPPID = &str
@@ -1253,6 +1340,11 @@ func createV3(inf *api.APIInfo, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if userErr, sysErr, errCode := checkTenancy(server.TenantID, inf.User, tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
 	v2Server, err := server.ToServerV2()
 	if err != nil {
 		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
@@ -1342,6 +1434,10 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("there are somehow two servers with id %d - cannot delete", id))
 		return
 	}
+	if userErr, sysErr, errCode = checkTenancy(servers[0].TenantID, inf.User, tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
 
 	userErr, sysErr, errCode = deleteInterfaces(id, tx)
 	if userErr != nil || sysErr != nil {
@@ -1367,7 +1463,7 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 	if inf.Version.Major >= 3 {
 		api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Server deleted", server)
 	} else {
-
+		clearTenant(&server.CommonServerProperties)
 		serverV2, err := server.ToServerV2()
 		if err != nil {
 			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
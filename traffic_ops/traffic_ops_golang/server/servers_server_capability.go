@@ -137,6 +137,10 @@ JOIN server s ON sc.server = s.id ` + where + orderBy + pagination +
 }
 
 func (ssc *TOServerServerCapability) Delete() (error, error, int) {
+	if userErr, sysErr, errCode := checkServerTenancy(*ssc.ServerID, ssc.APIInfo().User, ssc.APIInfo().Tx.Tx); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+
 	// Ensure that the user is not removing a server capability from the server
 	// that is required by the delivery services the server is assigned to (if applicable)
 	dsIDs := []int64{}
@@ -197,6 +201,9 @@ func (ssc *TOServerServerCapability) Create() (error, error, int) {
 	if !exists {
 		return fmt.Errorf("server %v does not exist", *ssc.ServerID), nil, http.StatusNotFound
 	}
+	if userErr, sysErr, errCode := checkServerTenancy(*ssc.ServerID, ssc.APIInfo().User, tx.Tx); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
 
 	// Ensure type is correct
 	correctType := true
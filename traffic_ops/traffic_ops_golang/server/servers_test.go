@@ -162,6 +162,11 @@ func TestUpdateServer(t *testing.T) {
 	}
 }
 
+// tenantRows returns the rows of the tenants of a user of the root tenant, as returned by tenant.GetUserTenantListTx.
+func tenantRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "active", "parent_id", "last_updated"}).AddRow(1, "root", true, nil, time.Now())
+}
+
 func TestGetServersByCachegroup(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
@@ -226,6 +231,8 @@ func TestGetServersByCachegroup(t *testing.T) {
 			ts.UpdPending,
 			ts.XMPPID,
 			ts.XMPPPasswd,
+			nil,
+			nil,
 		)
 		interfaceRows = interfaceRows.AddRow(
 			srv.Interface.MaxBandwidth,
@@ -247,6 +254,7 @@ func TestGetServersByCachegroup(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(tenantRows())
 	mock.ExpectQuery("SELECT COUNT\\(s.id\\) FROM s").WillReturnRows(unfilteredRows)
 	mock.ExpectQuery("SELECT").WillReturnRows(rows)
 	mock.ExpectQuery("SELECT").WillReturnRows(interfaceRows)
@@ -333,6 +341,8 @@ func TestGetMidServers(t *testing.T) {
 			ts.UpdPending,
 			ts.XMPPID,
 			ts.XMPPPasswd,
+			nil,
+			nil,
 		)
 		interfaceRows = interfaceRows.AddRow(
 			srv.Interface.MaxBandwidth,
@@ -353,6 +363,7 @@ func TestGetMidServers(t *testing.T) {
 		}
 	}
 	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE").WillReturnRows(tenantRows())
 	mock.ExpectQuery("SELECT COUNT\\(s.id\\) FROM s").WillReturnRows(unfilteredRows)
 	mock.ExpectQuery("SELECT").WillReturnRows(rows)
 	mock.ExpectQuery("SELECT").WillReturnRows(interfaceRows)
@@ -467,11 +478,13 @@ func TestGetMidServers(t *testing.T) {
 		ts.UpdPending,
 		ts.XMPPID,
 		ts.XMPPPasswd,
+		nil,
+		nil,
 	)
 
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT").WillReturnRows(rows2)
-	mid, userErr, sysErr, errCode := getMidServers(serverIDs, serverMap, db.MustBegin())
+	mid, userErr, sysErr, errCode := getMidServers(serverIDs, serverMap, []int{1}, db.MustBegin())
 
 	if userErr != nil || sysErr != nil {
 		t.Fatalf("getMidServers expected: no errors, actual: %v %v with status: %s", userErr, sysErr, http.StatusText(errCode))
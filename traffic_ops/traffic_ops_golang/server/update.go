@@ -55,13 +55,19 @@ func UpdateHandler(w http.ResponseWriter, r *http.Request) {
 		hostName = name
 	} else {
 		hostName = idOrName
-		if _, ok, err := dbhelpers.GetServerIDFromName(hostName, inf.Tx.Tx); err != nil {
+		serverID, ok, err := dbhelpers.GetServerIDFromName(hostName, inf.Tx.Tx)
+		if err != nil {
 			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server id from name '"+idOrName+"': "+err.Error()))
 			return
 		} else if !ok {
 			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("server name '"+idOrName+"' not found"), nil)
 			return
 		}
+		id = serverID
+	}
+	if userErr, sysErr, errCode := checkServerTenancy(id, inf.User, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
 	}
 
 	updated, hasUpdated := inf.Params["updated"]
@@ -0,0 +1,557 @@
+package steering
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-consistenthash"
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/coveragezone"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	dsconsistenthash "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/consistenthash"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/util/monitorhlp"
+)
+
+// earthRadiusKM is the mean radius of the Earth Traffic Router uses to compute distances.
+const earthRadiusKM = 6371.0
+
+// Simulate is the handler for POST requests to /steering/{deliveryservice}/simulate, which reports the targets
+// Traffic Router would route a client request of the steering Delivery Service to, in order of preference, according
+// to the current snapshot of its CDN.
+func Simulate(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"deliveryservice"}, []string{"deliveryservice"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	req := tc.SteeringSimulationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	clientIP, userErr := validateSimulationRequest(req)
+	if userErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, userErr, nil)
+		return
+	}
+
+	dsID := inf.IntParams["deliveryservice"]
+	userErr, sysErr, errCode = tenant.CheckID(inf.Tx.Tx, inf.User, dsID)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	ds, cdn, ok, err := dbhelpers.GetDSNameAndCDNFromID(inf.Tx.Tx, dsID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service name from ID: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, nil, nil)
+		return
+	}
+	dsType, _, err := dbhelpers.GetDeliveryServiceType(dsID, inf.Tx.Tx)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service type: "+err.Error()))
+		return
+	}
+	if !dsType.IsSteering() {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("delivery service "+string(ds)+" is not a steering delivery service"), nil)
+		return
+	}
+
+	steering, err := findDeliveryServiceSteering(inf.Tx.Tx, ds, dsType)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("finding steering: "+err.Error()))
+		return
+	}
+
+	snapshot, ok, err := crconfig.GetSnapshot(inf.Tx.Tx, string(cdn))
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("CDN "+string(cdn)+" of delivery service "+string(ds)+" has not been snapshotted"), nil)
+		return
+	}
+	crc := tc.CRConfig{}
+	if err := json.Unmarshal([]byte(snapshot), &crc); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("unmarshalling snapshot: "+err.Error()))
+		return
+	}
+
+	var clientLocation *tc.SteeringSimulationLocation
+	if req.Latitude != nil {
+		clientLocation = &tc.SteeringSimulationLocation{
+			Latitude:  *req.Latitude,
+			Longitude: *req.Longitude,
+			Source:    tc.SteeringSimulationLocationSourceRequest,
+		}
+	} else if clientIP != nil {
+		zones, err := coveragezone.LookupIP(inf.Tx.Tx, clientIP, string(cdn))
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("looking up coverage zones: "+err.Error()))
+			return
+		}
+		clientLocation = coverageZoneLocation(crc, zones)
+	}
+
+	unavailable, err := getUnavailableDeliveryServices(inf.Tx.Tx, cdn)
+	if err != nil {
+		log.Warnln("simulating steering of delivery service " + string(ds) + ": assuming every target is available: " + err.Error())
+	}
+
+	simulation, userErr := simulateSteering(crc, steering, req.RequestPath, clientLocation, unavailable)
+	if userErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, userErr, nil)
+		return
+	}
+	if err != nil {
+		api.WriteRespAlertObj(w, r, tc.WarnLevel, "the availability of delivery services couldn't be retrieved from Traffic Monitor, so every target is assumed to be available", simulation)
+		return
+	}
+	api.WriteResp(w, r, simulation)
+}
+
+// getUnavailableDeliveryServices returns the Delivery Services of the CDN which Traffic Monitor reports as
+// unavailable, which Traffic Router doesn't route clients to.
+func getUnavailableDeliveryServices(tx *sql.Tx, cdn tc.CDNName) (map[string]struct{}, error) {
+	monitors, err := monitorhlp.GetURLs(tx)
+	if err != nil {
+		return nil, errors.New("getting monitors: " + err.Error())
+	}
+	monitor, ok := monitors[cdn]
+	if !ok {
+		return nil, errors.New("no online monitor found for CDN " + string(cdn))
+	}
+	client, err := monitorhlp.GetClient(tx)
+	if err != nil {
+		return nil, errors.New("getting monitor client: " + err.Error())
+	}
+	crStates, err := monitorhlp.GetCRStates(monitor, client)
+	if err != nil {
+		return nil, err
+	}
+	unavailable := map[string]struct{}{}
+	for ds, state := range crStates.DeliveryService {
+		if !state.IsAvailable {
+			unavailable[string(ds)] = struct{}{}
+		}
+	}
+	return unavailable, nil
+}
+
+// validateSimulationRequest validates a steering simulation request, returning its client IP address, if any. IPv4
+// addresses are returned in their 4-byte form.
+//
+// The returned error, if any, is suitable to be returned to the user.
+func validateSimulationRequest(req tc.SteeringSimulationRequest) (net.IP, error) {
+	errs := []string{}
+	if req.RequestPath == "" {
+		errs = append(errs, "requestPath must not be empty")
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		errs = append(errs, "latitude and longitude must be given together")
+	}
+	if req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90) {
+		errs = append(errs, "latitude must be between -90 and 90")
+	}
+	if req.Longitude != nil && (*req.Longitude < -180 || *req.Longitude > 180) {
+		errs = append(errs, "longitude must be between -180 and 180")
+	}
+	var ip net.IP
+	if req.ClientIP != nil && *req.ClientIP != "" {
+		if ip = net.ParseIP(*req.ClientIP); ip == nil {
+			errs = append(errs, "clientIp must be an IPv4 or IPv6 address")
+		} else if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, ", "))
+	}
+	return ip, nil
+}
+
+// findDeliveryServiceSteering returns the steering of the steering Delivery Service ds of type dsType. A steering
+// Delivery Service without targets has no steering, so one without targets or filters is returned for it.
+func findDeliveryServiceSteering(tx *sql.Tx, ds tc.DeliveryServiceName, dsType tc.DSType) (tc.Steering, error) {
+	steerings, err := findSteering(tx)
+	if err != nil {
+		return tc.Steering{}, err
+	}
+	for _, steering := range steerings {
+		if steering.DeliveryService == ds {
+			return steering, nil
+		}
+	}
+	return tc.Steering{
+		DeliveryService: ds,
+		ClientSteering:  dsType == tc.DSTypeClientSteering,
+		Filters:         []tc.SteeringFilter{},
+		Targets:         []tc.SteeringSteeringTarget{},
+	}, nil
+}
+
+// coverageZoneLocation returns the location Traffic Router uses for a client in the given coverage zones: the
+// location of the cachegroup of its (non-deep) coverage zone. If the client isn't in a coverage zone of a cachegroup
+// in the snapshot, nil is returned, since Traffic Router would fall back to its geolocation database.
+func coverageZoneLocation(crc tc.CRConfig, zones []tc.CoverageZoneNullable) *tc.SteeringSimulationLocation {
+	for _, zone := range zones {
+		if zone.Deep == nil || *zone.Deep || zone.CachegroupName == nil {
+			continue
+		}
+		loc, ok := crc.EdgeLocations[*zone.CachegroupName]
+		if !ok {
+			return nil
+		}
+		return &tc.SteeringSimulationLocation{
+			Latitude:   loc.Lat,
+			Longitude:  loc.Lon,
+			Source:     tc.SteeringSimulationLocationSourceCoverageZone,
+			Cachegroup: util.StrPtr(*zone.CachegroupName),
+		}
+	}
+	return nil
+}
+
+// simulateSteering orders the targets of steering for a client request of requestPath, which may include a query
+// string, the way Traffic Router does with the given snapshot and the Delivery Services Traffic Monitor reports as
+// unavailable. If clientLocation is nil, the client location is unknown, and client steering targets aren't
+// geographically sorted.
+//
+// The returned error, if any, is suitable to be returned to the user.
+func simulateSteering(crc tc.CRConfig, steering tc.Steering, requestPath string, clientLocation *tc.SteeringSimulationLocation, unavailable map[string]struct{}) (tc.SteeringSimulation, error) {
+	ds := string(steering.DeliveryService)
+	dsCfg, ok := crc.DeliveryServices[ds]
+	if !ok {
+		return tc.SteeringSimulation{}, errors.New("delivery service " + ds + " is not in the current snapshot of its CDN")
+	}
+
+	var regex *regexp.Regexp
+	if dsCfg.ConsistentHashRegex != nil && *dsCfg.ConsistentHashRegex != "" {
+		var err error
+		if regex, err = consistenthash.CompileRegex(*dsCfg.ConsistentHashRegex); err != nil {
+			return tc.SteeringSimulation{}, errors.New("invalid or unsupported consistent hash regex '" + *dsCfg.ConsistentHashRegex + "': " + err.Error())
+		}
+	}
+	path, query := requestPath, ""
+	if i := strings.Index(requestPath, "?"); i >= 0 {
+		path, query = requestPath[:i], requestPath[i+1:]
+	}
+	hashString := consistenthash.HashString(regex, dsCfg.ConsistentHashQueryParams, path, query)
+
+	dispersion := tc.CRConfigDispersion{Limit: 1}
+	if dsCfg.Dispersion != nil {
+		dispersion = *dsCfg.Dispersion
+	}
+
+	simulation := tc.SteeringSimulation{
+		DeliveryService:               steering.DeliveryService,
+		ClientSteering:                steering.ClientSteering,
+		RequestPath:                   requestPath,
+		ResultingPathToConsistentHash: hashString,
+		Dispersion:                    dispersion,
+		ClientLocation:                clientLocation,
+		Targets:                       []tc.SteeringSimulationTarget{},
+	}
+
+	if steering.ClientSteering {
+		simulation.Targets = clientSteeringTargets(crc, steering, hashString, clientLocation, unavailable)
+	} else {
+		simulation.BypassTarget, simulation.Targets = steeringTargets(crc, steering, path, hashString, unavailable)
+	}
+	return simulation, nil
+}
+
+// steeringTargets returns the targets of the (non-client) steering Traffic Router would route a request of path with
+// the given hash string to, in order of preference, and the bypass target of the first filter matching path, if any.
+//
+// Traffic Router routes the request to the bypass target or the first target, and fails the request rather than use
+// another target if that Delivery Service is unavailable, in which case no targets are returned.
+func steeringTargets(crc tc.CRConfig, steering tc.Steering, path string, hashString string, unavailable map[string]struct{}) (*tc.DeliveryServiceName, []tc.SteeringSimulationTarget) {
+	var bypassName *tc.DeliveryServiceName
+	ordered := []tc.SteeringSimulationTarget{}
+	if bypass, ok := bypassTarget(crc, steering, path); ok {
+		bypassName = &bypass.DeliveryService
+		ordered = append(ordered, tc.SteeringSimulationTarget{SteeringSteeringTarget: bypass})
+	} else {
+		// Traffic Router only hashes the targets which are in its snapshot
+		targets := make(map[string]tc.SteeringSteeringTarget, len(steering.Targets))
+		hashables := make([]consistenthash.Hashable, 0, len(steering.Targets))
+		for _, target := range steering.Targets {
+			name := string(target.DeliveryService)
+			if _, ok := crc.DeliveryServices[name]; !ok {
+				continue
+			}
+			targets[name] = target
+			hashables = append(hashables, consistenthash.NewOrderedHashable(name, name, int(target.Weight), int(target.Order)))
+		}
+		for _, h := range consistenthash.Select(hashables, hashString) {
+			ordered = append(ordered, tc.SteeringSimulationTarget{SteeringSteeringTarget: targets[h.ID]})
+		}
+	}
+
+	if len(ordered) > 0 {
+		if _, ok := unavailable[string(ordered[0].DeliveryService)]; ok {
+			return bypassName, []tc.SteeringSimulationTarget{}
+		}
+	}
+	return bypassName, ordered
+}
+
+// clientSteeringTargets returns the targets of the client steering Traffic Router would return to a client at
+// clientLocation for a request with the given hash string, in order of preference.
+//
+// Like Traffic Router, every target is hashed, and then those which aren't in the snapshot yet, those which are
+// unavailable, and those without caches to route the client to are dropped, before the rest are sorted by distance.
+func clientSteeringTargets(crc tc.CRConfig, steering tc.Steering, hashString string, clientLocation *tc.SteeringSimulationLocation, unavailable map[string]struct{}) []tc.SteeringSimulationTarget {
+	targets := make(map[string]tc.SteeringSteeringTarget, len(steering.Targets))
+	hashables := make([]consistenthash.Hashable, 0, len(steering.Targets))
+	for _, target := range steering.Targets {
+		name := string(target.DeliveryService)
+		targets[name] = target
+		hashables = append(hashables, consistenthash.NewOrderedHashable(name, name, int(target.Weight), int(target.Order)))
+	}
+
+	ordered := []tc.SteeringSimulationTarget{}
+	for _, h := range consistenthash.Select(hashables, hashString) {
+		if _, ok := crc.DeliveryServices[h.ID]; !ok {
+			continue
+		}
+		if _, ok := unavailable[h.ID]; ok {
+			continue
+		}
+		cachegroups := servingCachegroups(crc, h.ID)
+		if len(cachegroups) == 0 {
+			continue
+		}
+		ordered = append(ordered, tc.SteeringSimulationTarget{
+			SteeringSteeringTarget: targets[h.ID],
+			Cachegroup:             clientCachegroup(crc, cachegroups, clientLocation),
+		})
+	}
+	geoSortTargets(crc, ordered, clientLocation)
+	return ordered
+}
+
+// bypassTarget returns the target of the first filter of steering whose pattern matches the whole request path, if
+// there is one in the snapshot.
+func bypassTarget(crc tc.CRConfig, steering tc.Steering, path string) (tc.SteeringSteeringTarget, bool) {
+	for _, filter := range steering.Filters {
+		pattern, err := consistenthash.CompileRegex(`^(?:` + filter.Pattern + `)$`)
+		if err != nil || !pattern.MatchString(path) {
+			continue
+		}
+		for _, target := range steering.Targets {
+			if target.DeliveryService != filter.DeliveryService {
+				continue
+			}
+			// Traffic Router falls back to the other targets if the bypass target isn't in its snapshot yet
+			if _, ok := crc.DeliveryServices[string(target.DeliveryService)]; !ok {
+				return tc.SteeringSteeringTarget{}, false
+			}
+			return target, true
+		}
+	}
+	return tc.SteeringSteeringTarget{}, false
+}
+
+// servingCachegroups returns the names of the cachegroups with caches Traffic Router would route clients of the
+// Delivery Service ds to.
+func servingCachegroups(crc tc.CRConfig, ds string) map[string]struct{} {
+	cachegroups := map[string]struct{}{}
+	dsCfg, ok := crc.DeliveryServices[ds]
+	if !ok {
+		return cachegroups
+	}
+	for _, server := range crc.ContentServers {
+		if dsconsistenthash.ServesDeliveryService(crc, ds, dsCfg, server) {
+			cachegroups[*server.CacheGroup] = struct{}{}
+		}
+	}
+	return cachegroups
+}
+
+// clientCachegroup returns the one of the given cachegroups Traffic Router would route a client at clientLocation
+// to: the cachegroup of the client's coverage zone, if it's one of them, and otherwise the closest one. If the client
+// location is unknown, nil is returned.
+func clientCachegroup(crc tc.CRConfig, cachegroups map[string]struct{}, clientLocation *tc.SteeringSimulationLocation) *string {
+	if clientLocation == nil {
+		return nil
+	}
+	if clientLocation.Cachegroup != nil {
+		if _, ok := cachegroups[*clientLocation.Cachegroup]; ok {
+			return util.StrPtr(*clientLocation.Cachegroup)
+		}
+	}
+
+	names := make([]string, 0, len(cachegroups))
+	for name := range cachegroups {
+		if _, ok := crc.EdgeLocations[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	closest := ""
+	closestDistance := math.Inf(1)
+	for _, name := range names {
+		loc := crc.EdgeLocations[name]
+		if d := distance(clientLocation.Latitude, clientLocation.Longitude, loc.Lat, loc.Lon); d < closestDistance {
+			closest, closestDistance = name, d
+		}
+	}
+	if closest == "" {
+		return nil
+	}
+	return &closest
+}
+
+// geoSortTargets sorts client steering targets by the distance from the client through the cachegroup serving it to
+// the primary origin of the target, and then by order, the way Traffic Router does. Targets are only sorted if the
+// client location is known and a target has a location.
+func geoSortTargets(crc tc.CRConfig, targets []tc.SteeringSimulationTarget, clientLocation *tc.SteeringSimulationLocation) {
+	if clientLocation == nil {
+		return
+	}
+	hasLocation := false
+	for _, target := range targets {
+		if _, ok := targetLocation(target); ok {
+			hasLocation = true
+			break
+		}
+	}
+	if !hasLocation {
+		return
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return compareTargetDistances(crc, *clientLocation, targets[i], targets[j]) < 0
+	})
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Order < targets[j].Order
+	})
+}
+
+// targetLocation returns the location of the primary origin of a steering target. Like Traffic Router, a latitude
+// or longitude of 0 is considered to be missing.
+func targetLocation(target tc.SteeringSimulationTarget) (Coord, bool) {
+	if target.Latitude == nil || target.Longitude == nil || *target.Latitude == 0 || *target.Longitude == 0 {
+		return Coord{}, false
+	}
+	return Coord{Lat: *target.Latitude, Lon: *target.Longitude}, true
+}
+
+// compareTargetDistances compares two client steering targets the way Traffic Router's
+// SteeringGeolocationComparator does, returning a negative number if a is preferred, a positive number if b is
+// preferred, and 0 if neither is.
+func compareTargetDistances(crc tc.CRConfig, clientLocation tc.SteeringSimulationLocation, a tc.SteeringSimulationTarget, b tc.SteeringSimulationTarget) int {
+	originA, okA := targetLocation(a)
+	originB, okB := targetLocation(b)
+	if !okA || !okB {
+		// targets without an origin location are farther than any with one
+		if okA {
+			return -1
+		}
+		if okB {
+			return 1
+		}
+		return 0
+	}
+
+	cacheA, cacheB := cachegroupLocation(crc, a.Cachegroup), cachegroupLocation(crc, b.Cachegroup)
+	if cacheA == cacheB && originA == originB {
+		return compareInts(geoOrder(a), geoOrder(b))
+	}
+
+	clientToCacheA := distance(clientLocation.Latitude, clientLocation.Longitude, cacheA.Lat, cacheA.Lon)
+	clientToCacheB := distance(clientLocation.Latitude, clientLocation.Longitude, cacheB.Lat, cacheB.Lon)
+	totalA := clientToCacheA + distance(cacheA.Lat, cacheA.Lon, originA.Lat, originA.Lon)
+	totalB := clientToCacheB + distance(cacheB.Lat, cacheB.Lon, originB.Lat, originB.Lon)
+	if totalA != totalB {
+		return compareFloats(totalA, totalB)
+	}
+	return compareFloats(clientToCacheA, clientToCacheB)
+}
+
+// cachegroupLocation returns the location of the named cachegroup in the snapshot, or a location of 0,0 if the
+// cachegroup is unknown.
+func cachegroupLocation(crc tc.CRConfig, cachegroup *string) Coord {
+	if cachegroup == nil {
+		return Coord{}
+	}
+	loc := crc.EdgeLocations[*cachegroup]
+	return Coord{Lat: loc.Lat, Lon: loc.Lon}
+}
+
+func geoOrder(target tc.SteeringSimulationTarget) int {
+	if target.GeoOrder == nil {
+		return 0
+	}
+	return *target.GeoOrder
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// distance returns the great-circle distance in kilometers between two points, computed with the haversine formula
+// the way Traffic Router does.
+func distance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRadians(lat1 - lat2)
+	dLon := toRadians(lon1 - lon2)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKM * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
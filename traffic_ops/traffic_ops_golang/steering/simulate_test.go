@@ -0,0 +1,208 @@
+package steering
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func testServer(cachegroup string, ds string) tc.CRConfigTrafficOpsServer {
+	st := tc.CRConfigServerStatus(tc.CacheStatusReported)
+	return tc.CRConfigTrafficOpsServer{
+		CacheGroup:       util.StrPtr(cachegroup),
+		ServerStatus:     &st,
+		ServerType:       util.StrPtr(tc.EdgeTypePrefix),
+		DeliveryServices: map[string][]string{ds: {ds + ".example.net"}},
+	}
+}
+
+func targetIDs(targets []tc.SteeringSimulationTarget) []string {
+	ids := []string{}
+	for _, target := range targets {
+		ids = append(ids, string(target.DeliveryService))
+	}
+	return ids
+}
+
+func TestSimulateSteering(t *testing.T) {
+	crc := tc.CRConfig{
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"steering": {ConsistentHashQueryParams: []string{"format"}},
+			"target1":  {},
+			"target2":  {},
+			"bypass":   {},
+		},
+	}
+	steering := tc.Steering{
+		DeliveryService: "steering",
+		Filters:         []tc.SteeringFilter{{DeliveryService: "bypass", Pattern: `/live/.*`}},
+		Targets: []tc.SteeringSteeringTarget{
+			{DeliveryService: "target1", Order: 1},
+			{DeliveryService: "target2", Order: -1},
+			{DeliveryService: "bypass", Weight: 10},
+			{DeliveryService: "missing", Order: -2},
+		},
+	}
+
+	sim, err := simulateSteering(crc, steering, "/vod/asset.m3u8?format=ts", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if sim.ResultingPathToConsistentHash != "/vod/asset.m3u8format=ts" {
+		t.Errorf("expected resulting path '/vod/asset.m3u8format=ts', actual '%s'", sim.ResultingPathToConsistentHash)
+	}
+	if sim.BypassTarget != nil {
+		t.Errorf("expected no bypass target, actual %s", *sim.BypassTarget)
+	}
+	expected := []string{"target2", "bypass", "target1"}
+	if actual := targetIDs(sim.Targets); len(actual) != len(expected) || actual[0] != expected[0] || actual[1] != expected[1] || actual[2] != expected[2] {
+		t.Errorf("expected targets %v, actual %v", expected, actual)
+	}
+
+	sim, err = simulateSteering(crc, steering, "/live/channel.m3u8", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if sim.BypassTarget == nil || *sim.BypassTarget != "bypass" {
+		t.Errorf("expected bypass target 'bypass', actual %v", sim.BypassTarget)
+	}
+	if actual := targetIDs(sim.Targets); len(actual) != 1 || actual[0] != "bypass" {
+		t.Errorf("expected only the bypass target, actual %v", actual)
+	}
+
+	sim, err = simulateSteering(crc, steering, "/live/channel.m3u8", nil, map[string]struct{}{"bypass": {}})
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if sim.BypassTarget == nil || len(sim.Targets) != 0 {
+		t.Errorf("expected no targets when the bypass target is unavailable, actual %v", targetIDs(sim.Targets))
+	}
+
+	sim, err = simulateSteering(crc, steering, "/vod/asset.m3u8?format=ts", nil, map[string]struct{}{"target1": {}})
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if actual := targetIDs(sim.Targets); len(actual) != len(expected) {
+		t.Errorf("expected an unavailable target other than the first not to change the targets, actual %v", actual)
+	}
+
+	sim, err = simulateSteering(crc, steering, "/vod/asset.m3u8?format=ts", nil, map[string]struct{}{"target2": {}})
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if len(sim.Targets) != 0 {
+		t.Errorf("expected no targets when the first target is unavailable, actual %v", targetIDs(sim.Targets))
+	}
+
+	if _, err := simulateSteering(crc, tc.Steering{DeliveryService: "nonexistent"}, "/vod/asset.m3u8", nil, nil); err == nil {
+		t.Error("expected an error simulating a delivery service which isn't in the snapshot")
+	}
+}
+
+func TestSimulateClientSteering(t *testing.T) {
+	crc := tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge1": testServer("denver", "far"),
+			"edge2": testServer("denver", "near"),
+			"edge3": testServer("newyork", "near"),
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"steering": {},
+			"far":      {},
+			"near":     {},
+			"nocaches": {},
+		},
+		EdgeLocations: map[string]tc.CRConfigLatitudeLongitude{
+			"denver":  {Lat: 39.7, Lon: -105.0},
+			"newyork": {Lat: 40.7, Lon: -74.0},
+		},
+	}
+	steering := tc.Steering{
+		DeliveryService: "steering",
+		ClientSteering:  true,
+		Targets: []tc.SteeringSteeringTarget{
+			{DeliveryService: "far", GeoOrder: util.IntPtr(0), Latitude: util.FloatPtr(51.5), Longitude: util.FloatPtr(-0.1)},
+			{DeliveryService: "near", GeoOrder: util.IntPtr(0), Latitude: util.FloatPtr(39.8), Longitude: util.FloatPtr(-104.9)},
+			{DeliveryService: "nocaches", GeoOrder: util.IntPtr(0), Latitude: util.FloatPtr(39.8), Longitude: util.FloatPtr(-104.9)},
+		},
+	}
+
+	sim, err := simulateSteering(crc, steering, "/asset.m3u8", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if actual := targetIDs(sim.Targets); len(actual) != 2 || actual[0] != "far" || actual[1] != "near" {
+		t.Errorf("expected targets without caches to be removed and the rest not to be sorted without a client location, actual %v", actual)
+	}
+
+	sim, err = simulateSteering(crc, steering, "/asset.m3u8", nil, map[string]struct{}{"far": {}})
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if actual := targetIDs(sim.Targets); len(actual) != 1 || actual[0] != "near" {
+		t.Errorf("expected unavailable targets to be removed, actual %v", actual)
+	}
+
+	client := &tc.SteeringSimulationLocation{Latitude: 40.7, Longitude: -74.0, Source: tc.SteeringSimulationLocationSourceRequest}
+	sim, err = simulateSteering(crc, steering, "/asset.m3u8", client, nil)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if actual := targetIDs(sim.Targets); len(actual) != 2 || actual[0] != "near" || actual[1] != "far" {
+		t.Fatalf("expected targets sorted by distance, actual %v", actual)
+	}
+	if sim.Targets[0].Cachegroup == nil || *sim.Targets[0].Cachegroup != "newyork" {
+		t.Errorf("expected the closest cachegroup 'newyork' to serve target 'near', actual %v", sim.Targets[0].Cachegroup)
+	}
+	if sim.Targets[1].Cachegroup == nil || *sim.Targets[1].Cachegroup != "denver" {
+		t.Errorf("expected the only cachegroup 'denver' to serve target 'far', actual %v", sim.Targets[1].Cachegroup)
+	}
+
+	client.Cachegroup = util.StrPtr("denver")
+	sim, err = simulateSteering(crc, steering, "/asset.m3u8", client, nil)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if actual := sim.Targets[0]; actual.DeliveryService != "near" || actual.Cachegroup == nil || *actual.Cachegroup != "denver" {
+		t.Errorf("expected the coverage zone cachegroup 'denver' to serve target 'near', actual %+v", actual)
+	}
+}
+
+func TestValidateSimulationRequest(t *testing.T) {
+	if _, err := validateSimulationRequest(tc.SteeringSimulationRequest{}); err == nil {
+		t.Error("expected an error for a missing request path")
+	}
+	if _, err := validateSimulationRequest(tc.SteeringSimulationRequest{RequestPath: "/", Latitude: util.FloatPtr(1)}); err == nil {
+		t.Error("expected an error for a latitude without a longitude")
+	}
+	if _, err := validateSimulationRequest(tc.SteeringSimulationRequest{RequestPath: "/", ClientIP: util.StrPtr("not an ip")}); err == nil {
+		t.Error("expected an error for an invalid client IP")
+	}
+	ip, err := validateSimulationRequest(tc.SteeringSimulationRequest{RequestPath: "/", ClientIP: util.StrPtr("192.0.2.1")})
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if len(ip) != 4 {
+		t.Errorf("expected an IPv4 address in its 4-byte form, actual %v", ip)
+	}
+}
@@ -1,45 +0,0 @@
-package main
-
-/*
- * Licensed to the Apache Software Foundation (ASF) under one
- * or more contributor license agreements.  See the NOTICE file
- * distributed with this work for additional information
- * regarding copyright ownership.  The ASF licenses this file
- * to you under the Apache License, Version 2.0 (the
- * "License"); you may not use this file except in compliance
- * with the License.  You may obtain a copy of the License at
- *
- *   http://www.apache.org/licenses/LICENSE-2.0
- *
- * Unless required by applicable law or agreed to in writing,
- * software distributed under the License is distributed on an
- * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
- * KIND, either express or implied.  See the License for the
- * specific language governing permissions and limitations
- * under the License.
- */
-
-import (
-	"flag"
-	"log"
-	"net/http"
-)
-
-func main() {
-	hostName := flag.String("h", "localhost", "hostname to serve on")
-	port := flag.String("p", "8000", "port to serve on")
-	flag.Parse()
-
-	swaggerFile := "/swaggerspec/swagger.json"
-
-	http.HandleFunc(swaggerFile, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Set-Cookie, Cookie")
-		w.Header().Set("Access-Control-Allow-Methods", "POST,GET,OPTIONS,PUT,DELETE")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		http.ServeFile(w, r, swaggerFile)
-	})
-	log.Printf("Serving swagger spec file here: http://%s:%s%s\n", *hostName, *port, swaggerFile)
-	log.Printf("Serving Swagger UI here: http://localhost:8080\n")
-	log.Fatal(http.ListenAndServe(":"+*port, nil))
-}
@@ -1,25 +0,0 @@
-#!/usr/bin/env bash
-
-# 
-# Licensed to the Apache Software Foundation (ASF) under one
-# or more contributor license agreements.  See the NOTICE file
-# distributed with this work for additional information
-# regarding copyright ownership.  The ASF licenses this file
-# to you under the Apache License, Version 2.0 (the
-# "License"); you may not use this file except in compliance
-# with the License.  You may obtain a copy of the License at
-
-#  http://www.apache.org/licenses/LICENSE-2.0
-
-# Unless required by applicable law or agreed to in writing,
-# software distributed under the License is distributed on an
-# "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
-# KIND, either express or implied.  See the License for the
-# specific language governing permissions and limitations
-# under the License.
-
-OUTPUT_DIR=./swagger2rst
-OUTPUT_FILE=v13_api_docs.rst
-RST_FILE=$OUTPUT_DIR/$OUTPUT_FILE
-swg2rst swagger.json -f rst -o $RST_FILE
-echo "Created $OUTPUT_FILE from swagger.json"
@@ -139,6 +139,21 @@ func GetUserTenantListTx(user auth.CurrentUser, tx *sql.Tx) ([]tc.TenantNullable
 	return tenants, nil
 }
 
+// GetUserTenantIDsTx returns the IDs of the tenants in the list returned by GetUserTenantListTx.
+func GetUserTenantIDsTx(user auth.CurrentUser, tx *sql.Tx) ([]int, error) {
+	tenants, err := GetUserTenantListTx(user, tx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(tenants))
+	for _, t := range tenants {
+		if t.ID != nil {
+			ids = append(ids, *t.ID)
+		}
+	}
+	return ids, nil
+}
+
 // GetUserTenantIDListTx returns a list of tenant IDs accessible to the given tenant.
 // Note: If the given tenant or any of its parents are inactive, no IDs will be returned. If child tenants are needed even if the current tenant is inactive, use GetUserTenantListTx instead.
 func GetUserTenantIDListTx(tx *sql.Tx, userTenantID int) ([]int, error) {
@@ -226,6 +241,59 @@ FETCH FIRST 1 ROW ONLY;
 	}
 }
 
+// IsOptionalResourceAuthorizedToUserTx is IsResourceAuthorizedToUserTx for resources whose tenancy is optional, such
+// as servers, cachegroups, topologies and profiles: a resource without a tenant is shared, and authorized to every user.
+func IsOptionalResourceAuthorizedToUserTx(resourceTenantID *int, user *auth.CurrentUser, tx *sql.Tx) (bool, error) {
+	if resourceTenantID == nil {
+		return true, nil
+	}
+	return IsResourceAuthorizedToUserTx(*resourceTenantID, user, tx)
+}
+
+// IsOptionalResourceChangeAuthorizedToUserTx returns whether the user may create, change or delete a resource whose
+// tenancy is optional, i.e. whether they're authorized on both the tenant the resource has now - the one returned by
+// tenantQuery given the resource's key, if it exists - and the tenant requested for it, per
+// IsOptionalResourceAuthorizedToUserTx.
+func IsOptionalResourceChangeAuthorizedToUserTx(tenantQuery string, key interface{}, requestedTenantID *int, user *auth.CurrentUser, tx *sql.Tx) (bool, error) {
+	currentTenantID := (*int)(nil)
+	if err := tx.QueryRow(tenantQuery, key).Scan(&currentTenantID); err != nil && err != sql.ErrNoRows {
+		return false, errors.New("querying current tenant: " + err.Error())
+	}
+	if authorized, err := IsOptionalResourceAuthorizedToUserTx(currentTenantID, user, tx); err != nil || !authorized {
+		return false, err
+	}
+	return IsOptionalResourceAuthorizedToUserTx(requestedTenantID, user, tx)
+}
+
+// AreOptionalResourcesAuthorizedToUserTx returns whether the user is authorized, per
+// IsOptionalResourceAuthorizedToUserTx, on every tenant returned by tenantQuery, which selects the tenant_id of any
+// number of resources whose tenancy is optional.
+func AreOptionalResourcesAuthorizedToUserTx(tenantQuery string, args []interface{}, user *auth.CurrentUser, tx *sql.Tx) (bool, error) {
+	rows, err := tx.Query(tenantQuery, args...)
+	if err != nil {
+		return false, errors.New("querying tenants: " + err.Error())
+	}
+	defer rows.Close()
+	tenantIDs := []*int{}
+	for rows.Next() {
+		tenantID := (*int)(nil)
+		if err := rows.Scan(&tenantID); err != nil {
+			return false, errors.New("scanning tenant: " + err.Error())
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	if err := rows.Err(); err != nil {
+		return false, errors.New("iterating tenants: " + err.Error())
+	}
+	rows.Close()
+	for _, tenantID := range tenantIDs {
+		if authorized, err := IsOptionalResourceAuthorizedToUserTx(tenantID, user, tx); err != nil || !authorized {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 // getDSTenantIDByIDTx returns the tenant ID, whether the delivery service exists, and any error.
 // Note the id may be nil, even if true is returned, if the delivery service exists but its tenant_id field is null.
 // TODO move somewhere generic
@@ -32,6 +32,10 @@ type Cookie struct {
 	AuthData    string `json:"auth_data"`
 	ExpiresUnix int64  `json:"expires"`
 	By          string `json:"by"`
+	// MFA is whether the user verified a second factor, in addition to their password, to obtain the session.
+	MFA bool `json:"mfa,omitempty"`
+	// Session is the ID of the session in the Traffic Ops session registry. A cookie without one isn't valid.
+	Session string `json:"session,omitempty"`
 }
 
 func checkHmac(message, messageMAC, key []byte) bool {
@@ -95,9 +99,15 @@ func NewRawMsg(msg, key []byte) string {
 }
 
 func GetCookie(authData string, duration time.Duration, secret string) *http.Cookie {
+	return GetSessionCookie(authData, "", false, duration, secret)
+}
+
+// GetSessionCookie is like GetCookie, but for the session with the given ID, in which the user did or did not verify
+// a second factor in addition to their password.
+func GetSessionCookie(authData string, session string, mfa bool, duration time.Duration, secret string) *http.Cookie {
 	expiry := time.Now().Add(duration)
 	maxAge := int(duration.Seconds())
-	c := Cookie{By: GeneratedByStr, AuthData: authData, ExpiresUnix: expiry.Unix()}
+	c := Cookie{By: GeneratedByStr, AuthData: authData, ExpiresUnix: expiry.Unix(), MFA: mfa, Session: session}
 	m, _ := json.Marshal(c)
 	msg := NewRawMsg(m, []byte(secret))
 	httpCookie := http.Cookie{Name: "mojolicious", Value: msg, Path: "/", Expires: expiry, MaxAge: maxAge, HttpOnly: true}
@@ -27,8 +27,10 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cachegroup"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/util/ims"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/lib/pq"
@@ -60,6 +62,7 @@ func (topology *TOTopology) ParamColumns() map[string]dbhelpers.WhereColumnInfo
 		"name":        {Column: "t.name"},
 		"description": {Column: "t.description"},
 		"lastUpdated": {Column: "t.last_updated"},
+		"tenant":      {Column: "t.tenant_id", Checker: api.IsInt},
 	}
 }
 
@@ -76,6 +79,12 @@ func (topology TOTopology) GetKeyFieldsInfo() []api.KeyFieldInfo {
 	return []api.KeyFieldInfo{{"name", api.GetStringKey}}
 }
 
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on both the tenant of the
+// topology, if it exists and has one, and the tenant requested for it, if any.
+func (topology *TOTopology) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
+	return tenant.IsOptionalResourceChangeAuthorizedToUserTx(`SELECT tenant_id FROM topology WHERE name = $1`, topology.Name, topology.TenantID, user, topology.ReqInfo.Tx.Tx)
+}
+
 // GetType returns the human-readable type of TOTopology as a string.
 func (topology *TOTopology) GetType() string {
 	return "topology"
@@ -252,7 +261,7 @@ func (topology *TOTopology) GetAuditName() string {
 // Create is a requirement of the api.Creator interface.
 func (topology *TOTopology) Create() (error, error, int) {
 	tx := topology.APIInfo().Tx.Tx
-	err := tx.QueryRow(insertQuery(), topology.Name, topology.Description).Scan(&topology.Name, &topology.Description, &topology.LastUpdated)
+	err := tx.QueryRow(insertQuery(), topology.Name, topology.Description, topology.TenantID).Scan(&topology.Name, &topology.Description, &topology.LastUpdated, &topology.Tenant)
 	if err != nil {
 		return api.ParseDBError(err)
 	}
@@ -287,6 +296,13 @@ func (topology *TOTopology) Read(h http.Header, useIMS bool) ([]interface{}, err
 	} else {
 		log.Debugln("Non IMS request")
 	}
+
+	tenantIDs, err := tenant.GetUserTenantIDsTx(*topology.ReqInfo.User, topology.ReqInfo.Tx.Tx)
+	if err != nil {
+		return nil, nil, errors.New("topology read: getting user tenants: " + err.Error()), http.StatusInternalServerError, nil
+	}
+	where, queryValues = dbhelpers.AddOptionalTenancyCheck(where, queryValues, "t.tenant_id", tenantIDs)
+
 	// Case where we need to run the second query
 	query := selectQuery() + where + orderBy + pagination
 	rows, err := topology.ReqInfo.Tx.NamedQuery(query, queryValues)
@@ -300,6 +316,8 @@ func (topology *TOTopology) Read(h http.Header, useIMS bool) ([]interface{}, err
 		var (
 			name, description string
 			lastUpdated       tc.TimeNoMod
+			tenantName        *string
+			tenantID          *int
 		)
 		topologyNode := tc.TopologyNode{}
 		topologyNode.Parents = []int{}
@@ -311,6 +329,8 @@ func (topology *TOTopology) Read(h http.Header, useIMS bool) ([]interface{}, err
 			&topologyNode.Id,
 			&topologyNode.Cachegroup,
 			&parents,
+			&tenantName,
+			&tenantID,
 		); err != nil {
 			return nil, nil, errors.New("topology read: scanning: " + err.Error()), http.StatusInternalServerError, nil
 		}
@@ -324,6 +344,8 @@ func (topology *TOTopology) Read(h http.Header, useIMS bool) ([]interface{}, err
 			topology.Name = name
 			topology.Description = description
 			topology.LastUpdated = &lastUpdated
+			topology.Tenant = tenantName
+			topology.TenantID = tenantID
 		}
 		topologies[name].Nodes = append(topologies[name].Nodes, topologyNode)
 	}
@@ -419,13 +441,13 @@ func (topology *TOTopology) addParents() (error, error, int) {
 }
 
 func (topology *TOTopology) setDescription() (error, error, int) {
-	rows, err := topology.ReqInfo.Tx.Query(updateQuery(), topology.Description, topology.Name)
+	rows, err := topology.ReqInfo.Tx.Query(updateQuery(), topology.Description, topology.TenantID, topology.Name)
 	if err != nil {
 		return nil, fmt.Errorf("topology update: error setting the description for topology %v: %v", topology.Name, err.Error()), http.StatusInternalServerError
 	}
 	defer log.Close(rows, "unable to close DB connection")
 	for rows.Next() {
-		err = rows.Scan(&topology.Name, &topology.Description, &topology.LastUpdated)
+		err = rows.Scan(&topology.Name, &topology.Description, &topology.LastUpdated, &topology.Tenant)
 		if err != nil {
 			return api.ParseDBError(err)
 		}
@@ -506,9 +528,10 @@ func (topology *TOTopology) OptionsDelete() (error, error, int) {
 
 func insertQuery() string {
 	query := `
-INSERT INTO topology (name, description)
-VALUES ($1, $2)
-RETURNING name, description, last_updated
+INSERT INTO topology (name, description, tenant_id)
+VALUES ($1, $2, $3)
+RETURNING name, description, last_updated,
+	(SELECT name FROM tenant WHERE tenant.id = topology.tenant_id)
 `
 	return query
 }
@@ -540,9 +563,11 @@ tc.id, tc.cachegroup,
 	INNER JOIN topology_cachegroup_parents tcp ON tc2.id = tcp.child
 	WHERE tc2.topology = tc.topology
 	AND tc2.cachegroup = tc.cachegroup
-	)
+	),
+tn.name, t.tenant_id
 FROM topology t
 JOIN topology_cachegroup tc on t.name = tc.topology
+LEFT JOIN tenant tn ON t.tenant_id = tn.id
 `
 	return query
 }
@@ -632,9 +657,11 @@ WHERE tcp.child IN
 func updateQuery() string {
 	query := `
 UPDATE topology t SET
-description = $1
-WHERE t.name = $2
-RETURNING t.name, t.description, t.last_updated
+description = $1,
+tenant_id = $2
+WHERE t.name = $3
+RETURNING t.name, t.description, t.last_updated,
+	(SELECT name FROM tenant WHERE tenant.id = t.tenant_id)
 `
 	return query
 }
@@ -0,0 +1,562 @@
+package trafficstats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/lib/pq"
+
+	influx "github.com/influxdata/influxdb/client/v2"
+)
+
+const (
+	// cgBandwidthSeriesQuery sums the per-minute bandwidth of the caches of each cachegroup, giving the total
+	// bandwidth of each cachegroup for each minute.
+	cgBandwidthSeriesQuery = `
+SELECT sum(value)
+FROM "%s"."monthly"."bandwidth.1min"
+WHERE cdn = $cdn_name
+AND time > $start
+AND time < $end
+GROUP BY time(1m), cachegroup`
+
+	dsBandwidthSummaryQuery = `
+SELECT max(value) AS "peak",
+	percentile(value, 95) AS "ninetyFifthPercentile"
+FROM "%s"."monthly"."kbps.ds.1min"
+WHERE cdn = $cdn_name
+AND time > $start
+AND time < $end
+GROUP BY deliveryservice`
+)
+
+// capacityPoint is the total bandwidth, in kbps, of something at some time.
+type capacityPoint struct {
+	Time time.Time
+	Kbps float64
+}
+
+// capacityCachegroup is a cachegroup of a CDN, with the capacity of its caches.
+type capacityCachegroup struct {
+	Name                   string
+	Type                   string
+	Parent                 string
+	Fallbacks              []string
+	Servers                int
+	ServersWithoutCapacity int
+	CapacityKbps           float64
+}
+
+// GetCapacityPlan is the handler for GET requests to /capacity_planning.
+func GetCapacityPlan(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdnName", "startDate", "endDate"}, nil)
+	tx := inf.Tx.Tx
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdnName"]
+	start, err := parseTime(inf.Params["startDate"])
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("Invalid startDate!"), nil)
+		return
+	}
+	end, err := parseTime(inf.Params["endDate"])
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("Invalid endDate!"), nil)
+		return
+	}
+	if !end.After(start) {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("endDate must be after startDate"), nil)
+		return
+	}
+
+	exists, err := dbhelpers.CDNExists(cdn, tx)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	} else if !exists {
+		api.HandleErr(w, r, tx, http.StatusNotFound, fmt.Errorf("no such CDN: %s", cdn), nil)
+		return
+	}
+
+	cachegroups, err := getCapacityCachegroups(tx, cdn)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting cachegroup capacities: "+err.Error()))
+		return
+	}
+	topologies, err := getCapacityTopologies(tx, cdn)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting topologies: "+err.Error()))
+		return
+	}
+
+	tenantIDs, err := tenant.GetUserTenantIDListTx(tx, inf.User.TenantID)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting user tenants: "+err.Error()))
+		return
+	}
+	authorizedDSes, err := getCapacityDeliveryServices(tx, cdn, tenantIDs)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting delivery services: "+err.Error()))
+		return
+	}
+
+	client, err := inf.CreateInfluxClient()
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	} else if client == nil {
+		sysErr = errors.New("Traffic Stats is not configured, but a capacity plan was requested")
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, sysErr)
+		return
+	}
+	defer (*client).Close()
+
+	series, err := getCachegroupBandwidthSeries(client, inf.Config.ConfigInflux.CacheDBName, cdn, start, end)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting cachegroup bandwidth from Influx: "+err.Error()))
+		return
+	}
+	dses, err := getDeliveryServiceBandwidthSummaries(client, inf.Config.ConfigInflux.DSDBName, cdn, start, end, authorizedDSes)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("getting delivery service bandwidth from Influx: "+err.Error()))
+		return
+	}
+
+	plan := buildCapacityPlan(cachegroups, series, topologies)
+	plan.CDN = cdn
+	plan.StartDate = start
+	plan.EndDate = end
+	plan.DeliveryServices = dses
+	api.WriteResp(w, r, plan)
+}
+
+// getCapacityCachegroups returns the cachegroups of the given CDN's caches, with the capacity of their ONLINE and
+// REPORTED caches.
+func getCapacityCachegroups(tx *sql.Tx, cdn string) ([]capacityCachegroup, error) {
+	inService := `st.name IN ('` + string(tc.CacheStatusOnline) + `', '` + string(tc.CacheStatusReported) + `')`
+	qry := `
+SELECT
+	cg.name,
+	cgt.name,
+	COALESCE(pcg.name, ''),
+	ARRAY(SELECT bcg.name
+		FROM cachegroup_fallbacks cgf
+		JOIN cachegroup bcg ON bcg.id = cgf.backup_cg
+		WHERE cgf.primary_cg = cg.id
+		ORDER BY cgf.set_order),
+	COUNT(s.id) FILTER (WHERE ` + inService + `),
+	COUNT(s.id) FILTER (WHERE ` + inService + ` AND cap.interfaces = 0),
+	COALESCE(SUM(cap.kbps) FILTER (WHERE ` + inService + `), 0)
+FROM server s
+JOIN cachegroup cg ON cg.id = s.cachegroup
+JOIN type cgt ON cgt.id = cg.type
+LEFT JOIN cachegroup pcg ON pcg.id = cg.parent_cachegroup_id
+JOIN type t ON t.id = s.type
+JOIN status st ON st.id = s.status
+JOIN cdn c ON c.id = s.cdn_id
+JOIN LATERAL (
+	SELECT COUNT(i.max_bandwidth) AS interfaces, SUM(i.max_bandwidth) AS kbps
+	FROM interface i
+	WHERE i.server = s.id AND i.monitor
+) cap ON true
+WHERE c.name = $1
+AND (t.name LIKE '` + tc.EdgeTypePrefix + `%' OR t.name LIKE '` + tc.MidTypePrefix + `%')
+GROUP BY cg.id, cg.name, cgt.name, pcg.name
+ORDER BY cg.name
+`
+	rows, err := tx.Query(qry, cdn)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer log.Close(rows, "closing capacity cachegroup rows")
+
+	cachegroups := []capacityCachegroup{}
+	for rows.Next() {
+		cg := capacityCachegroup{}
+		if err := rows.Scan(&cg.Name, &cg.Type, &cg.Parent, pq.Array(&cg.Fallbacks), &cg.Servers, &cg.ServersWithoutCapacity, &cg.CapacityKbps); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		cachegroups = append(cachegroups, cg)
+	}
+	return cachegroups, rows.Err()
+}
+
+// getCapacityTopologies returns the names of the cachegroups of each topology used by a delivery service of the given
+// CDN, keyed by topology name.
+func getCapacityTopologies(tx *sql.Tx, cdn string) (map[string][]string, error) {
+	qry := `
+SELECT DISTINCT tc.topology, tc.cachegroup
+FROM topology_cachegroup tc
+JOIN deliveryservice ds ON ds.topology = tc.topology
+JOIN cdn c ON c.id = ds.cdn_id
+WHERE c.name = $1
+ORDER BY tc.topology, tc.cachegroup
+`
+	rows, err := tx.Query(qry, cdn)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer log.Close(rows, "closing capacity topology rows")
+
+	topologies := map[string][]string{}
+	for rows.Next() {
+		topology := ""
+		cachegroup := ""
+		if err := rows.Scan(&topology, &cachegroup); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		topologies[topology] = append(topologies[topology], cachegroup)
+	}
+	return topologies, rows.Err()
+}
+
+// getCapacityDeliveryServices returns the XMLIDs of the delivery services of the given CDN which are in one of the
+// given tenants, or have no tenant.
+func getCapacityDeliveryServices(tx *sql.Tx, cdn string, tenantIDs []int) (map[string]struct{}, error) {
+	qry := `
+SELECT ds.xml_id
+FROM deliveryservice ds
+JOIN cdn c ON c.id = ds.cdn_id
+WHERE c.name = $1
+AND (ds.tenant_id IS NULL OR ds.tenant_id = ANY($2::bigint[]))
+`
+	rows, err := tx.Query(qry, cdn, pq.Array(tenantIDs))
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer log.Close(rows, "closing capacity delivery service rows")
+
+	dses := map[string]struct{}{}
+	for rows.Next() {
+		xmlID := ""
+		if err := rows.Scan(&xmlID); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		dses[xmlID] = struct{}{}
+	}
+	return dses, rows.Err()
+}
+
+// getCachegroupBandwidthSeries returns the per-minute total bandwidth of each cachegroup of the CDN, keyed by
+// cachegroup name.
+func getCachegroupBandwidthSeries(client *influx.Client, db string, cdn string, start time.Time, end time.Time) (map[string][]capacityPoint, error) {
+	q := influx.NewQueryWithParameters(fmt.Sprintf(cgBandwidthSeriesQuery, db),
+		db,
+		"rfc3339",
+		map[string]interface{}{
+			"cdn_name": cdn,
+			"start":    start,
+			"end":      end,
+		})
+	log.Debugf("InfluxDB cachegroup bandwidth query: %+v", q)
+
+	resp, err := (*client).Query(q)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	if len(resp.Results) != 1 {
+		return nil, errors.New("'results' missing or improper")
+	}
+
+	series := map[string][]capacityPoint{}
+	for _, s := range resp.Results[0].Series {
+		cachegroup := s.Tags["cachegroup"]
+		for _, row := range s.Values {
+			if len(row) != 2 || row[1] == nil {
+				continue // no data for this minute
+			}
+			t, ok := row[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid type for time - expected string, got %T", row[0])
+			}
+			pointTime, err := time.Parse(time.RFC3339, t)
+			if err != nil {
+				return nil, errors.New("parsing time: " + err.Error())
+			}
+			kbps, err := extractFloat64("value", map[string]interface{}{"value": row[1]})
+			if err != nil {
+				return nil, err
+			}
+			series[cachegroup] = append(series[cachegroup], capacityPoint{Time: pointTime, Kbps: kbps})
+		}
+	}
+	return series, nil
+}
+
+// getDeliveryServiceBandwidthSummaries returns the peak and 95th percentile bandwidth of each delivery service of the
+// CDN whose XMLID is in authorizedDSes.
+func getDeliveryServiceBandwidthSummaries(client *influx.Client, db string, cdn string, start time.Time, end time.Time, authorizedDSes map[string]struct{}) ([]tc.CapacityPlanDeliveryService, error) {
+	q := influx.NewQueryWithParameters(fmt.Sprintf(dsBandwidthSummaryQuery, db),
+		db,
+		"rfc3339",
+		map[string]interface{}{
+			"cdn_name": cdn,
+			"start":    start,
+			"end":      end,
+		})
+	log.Debugf("InfluxDB delivery service bandwidth query: %+v", q)
+
+	resp, err := (*client).Query(q)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	if len(resp.Results) != 1 {
+		return nil, errors.New("'results' missing or improper")
+	}
+
+	dses := []tc.CapacityPlanDeliveryService{}
+	for _, s := range resp.Results[0].Series {
+		if _, ok := authorizedDSes[s.Tags["deliveryservice"]]; !ok {
+			continue
+		}
+		if len(s.Values) != 1 || len(s.Values[0]) != len(s.Columns) {
+			return nil, fmt.Errorf("improper number of rows or values for delivery service '%s'", s.Tags["deliveryservice"])
+		}
+		mappedValues := map[string]interface{}{}
+		for i, v := range s.Values[0] {
+			mappedValues[s.Columns[i]] = v
+		}
+		ds := tc.CapacityPlanDeliveryService{XMLID: s.Tags["deliveryservice"]}
+		if ds.PeakKbps, err = extractFloat64("peak", mappedValues); err != nil {
+			return nil, err
+		}
+		if ds.P95Kbps, err = extractFloat64("ninetyFifthPercentile", mappedValues); err != nil {
+			return nil, err
+		}
+		dses = append(dses, ds)
+	}
+	sort.Slice(dses, func(i, j int) bool { return dses[i].XMLID < dses[j].XMLID })
+	return dses, nil
+}
+
+// buildCapacityPlan builds the cachegroup and topology tier parts of a capacity plan from the cachegroups of a CDN,
+// their bandwidth series, and the cachegroups of its topologies.
+func buildCapacityPlan(cachegroups []capacityCachegroup, series map[string][]capacityPoint, topologies map[string][]string) tc.CapacityPlan {
+	plan := tc.CapacityPlan{
+		Cachegroups:   []tc.CapacityPlanCachegroup{},
+		TopologyTiers: []tc.CapacityPlanTopologyTier{},
+	}
+	cgIndex := map[string]int{}
+	for _, cg := range cachegroups {
+		cgIndex[cg.Name] = len(plan.Cachegroups)
+		plan.Cachegroups = append(plan.Cachegroups, tc.CapacityPlanCachegroup{
+			Name:                    cg.Name,
+			Type:                    cg.Type,
+			Servers:                 cg.Servers,
+			ServersWithoutCapacity:  cg.ServersWithoutCapacity,
+			CapacityPlanUtilization: summarizeUtilization(series[cg.Name], cg.CapacityKbps),
+			SiblingFailures:         []tc.CapacityPlanSiblingFailure{},
+		})
+	}
+
+	for _, failed := range cachegroups {
+		for target, share := range failoverShares(failed, cachegroups, cgIndex) {
+			planCG := &plan.Cachegroups[cgIndex[target]]
+			failure := tc.CapacityPlanSiblingFailure{
+				Cachegroup:    failed.Name,
+				ProjectedKbps: planCG.P95Kbps + share*plan.Cachegroups[cgIndex[failed.Name]].P95Kbps,
+			}
+			failure.ProjectedUtilizationPercent = utilizationPercent(failure.ProjectedKbps, planCG.CapacityKbps)
+			failure.ExceedsCapacity = planCG.CapacityKbps > 0 && failure.ProjectedKbps > planCG.CapacityKbps
+			planCG.SiblingFailureRisk = planCG.SiblingFailureRisk || failure.ExceedsCapacity
+			planCG.SiblingFailures = append(planCG.SiblingFailures, failure)
+		}
+	}
+	for i := range plan.Cachegroups {
+		failures := plan.Cachegroups[i].SiblingFailures
+		sort.Slice(failures, func(a, b int) bool { return failures[a].Cachegroup < failures[b].Cachegroup })
+	}
+
+	topologyNames := []string{}
+	for name := range topologies {
+		topologyNames = append(topologyNames, name)
+	}
+	sort.Strings(topologyNames)
+	for _, topology := range topologyNames {
+		tiers := map[string][]string{}
+		for _, cgName := range topologies[topology] {
+			if i, ok := cgIndex[cgName]; ok {
+				tiers[cachegroups[i].Type] = append(tiers[cachegroups[i].Type], cgName)
+			}
+		}
+		tierNames := []string{}
+		for tier := range tiers {
+			tierNames = append(tierNames, tier)
+		}
+		sort.Strings(tierNames)
+		for _, tier := range tierNames {
+			capacity := 0.0
+			tierSeries := [][]capacityPoint{}
+			for _, cgName := range tiers[tier] {
+				capacity += cachegroups[cgIndex[cgName]].CapacityKbps
+				tierSeries = append(tierSeries, series[cgName])
+			}
+			plan.TopologyTiers = append(plan.TopologyTiers, tc.CapacityPlanTopologyTier{
+				Topology:                topology,
+				Tier:                    tier,
+				Cachegroups:             tiers[tier],
+				CapacityPlanUtilization: summarizeUtilization(sumSeries(tierSeries), capacity),
+			})
+		}
+	}
+	return plan
+}
+
+// failoverShares returns the fraction of the traffic of the failed cachegroup which would move to each other
+// cachegroup, keyed by name. If the failed cachegroup has fallbacks, all of it moves to the first one in the CDN, as
+// Traffic Router would send it there; otherwise it is shared evenly among its siblings, i.e. the other cachegroups of
+// the same type with the same parent.
+func failoverShares(failed capacityCachegroup, cachegroups []capacityCachegroup, cgIndex map[string]int) map[string]float64 {
+	for _, fallback := range failed.Fallbacks {
+		if _, ok := cgIndex[fallback]; ok && fallback != failed.Name {
+			return map[string]float64{fallback: 1}
+		}
+	}
+	if failed.Parent == "" {
+		return nil
+	}
+	siblings := []string{}
+	for _, cg := range cachegroups {
+		if cg.Name != failed.Name && cg.Parent == failed.Parent && cg.Type == failed.Type {
+			siblings = append(siblings, cg.Name)
+		}
+	}
+	shares := map[string]float64{}
+	for _, sibling := range siblings {
+		shares[sibling] = 1 / float64(len(siblings))
+	}
+	return shares
+}
+
+// sumSeries returns the sum of the given series at each time in any of them.
+func sumSeries(series [][]capacityPoint) []capacityPoint {
+	sums := map[time.Time]float64{}
+	for _, s := range series {
+		for _, p := range s {
+			sums[p.Time] += p.Kbps
+		}
+	}
+	sum := make([]capacityPoint, 0, len(sums))
+	for t, kbps := range sums {
+		sum = append(sum, capacityPoint{Time: t, Kbps: kbps})
+	}
+	sort.Slice(sum, func(i, j int) bool { return sum[i].Time.Before(sum[j].Time) })
+	return sum
+}
+
+// summarizeUtilization returns the peak, 95th percentile and projected headroom of the given bandwidth series,
+// relative to the given capacity.
+func summarizeUtilization(points []capacityPoint, capacityKbps float64) tc.CapacityPlanUtilization {
+	u := tc.CapacityPlanUtilization{CapacityKbps: capacityKbps}
+	if len(points) > 0 {
+		values := make([]float64, 0, len(points))
+		for _, p := range points {
+			values = append(values, p.Kbps)
+		}
+		sort.Float64s(values)
+		u.PeakKbps = values[len(values)-1]
+		u.P95Kbps = percentile(values, 95)
+	}
+	u.PeakUtilizationPercent = utilizationPercent(u.PeakKbps, capacityKbps)
+	u.P95UtilizationPercent = utilizationPercent(u.P95Kbps, capacityKbps)
+	u.HeadroomKbps = capacityKbps - u.PeakKbps
+	u.GrowthKbpsPerDay = dailyPeakGrowth(points)
+	if capacityKbps > 0 && u.GrowthKbpsPerDay > 0 {
+		days := math.Max(u.HeadroomKbps, 0) / u.GrowthKbpsPerDay
+		u.DaysUntilExhausted = &days
+	}
+	return u
+}
+
+// percentile returns the nearest-rank pth percentile of the given sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func utilizationPercent(kbps float64, capacityKbps float64) *float64 {
+	if capacityKbps <= 0 {
+		return nil
+	}
+	percent := kbps * 100 / capacityKbps
+	return &percent
+}
+
+// dailyPeakGrowth returns the slope, in kbps per day, of the least-squares linear fit of the daily peaks of the given
+// series. It returns 0 if there are fewer than two days of data.
+func dailyPeakGrowth(points []capacityPoint) float64 {
+	peaks := map[int64]float64{}
+	firstDay := int64(math.MaxInt64)
+	for _, p := range points {
+		day := p.Time.Unix() / int64((24 * time.Hour).Seconds())
+		if peak, ok := peaks[day]; !ok || p.Kbps > peak {
+			peaks[day] = p.Kbps
+		}
+		if day < firstDay {
+			firstDay = day
+		}
+	}
+	if len(peaks) < 2 {
+		return 0
+	}
+	n := float64(len(peaks))
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for day, peak := range peaks {
+		x := float64(day - firstDay)
+		sumX += x
+		sumY += peak
+		sumXY += x * peak
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
@@ -0,0 +1,135 @@
+package trafficstats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeUtilization(t *testing.T) {
+	start := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	points := []capacityPoint{}
+	// 100 minutes a day for 3 days, peaking at 100, 110 and 120 - 10kbps/day of growth
+	for day := 0; day < 3; day++ {
+		for minute := 1; minute <= 100; minute++ {
+			points = append(points, capacityPoint{
+				Time: start.Add(time.Duration(day)*24*time.Hour + time.Duration(minute)*time.Minute),
+				Kbps: float64(minute + day*10),
+			})
+		}
+	}
+
+	u := summarizeUtilization(points, 200)
+	if u.PeakKbps != 120 {
+		t.Errorf("expected peak 120, actual: %v", u.PeakKbps)
+	}
+	if u.P95Kbps != 108 {
+		t.Errorf("expected p95 108, actual: %v", u.P95Kbps)
+	}
+	if u.PeakUtilizationPercent == nil || *u.PeakUtilizationPercent != 60 {
+		t.Errorf("expected peak utilization 60%%, actual: %v", u.PeakUtilizationPercent)
+	}
+	if u.HeadroomKbps != 80 {
+		t.Errorf("expected headroom 80, actual: %v", u.HeadroomKbps)
+	}
+	if u.GrowthKbpsPerDay != 10 {
+		t.Errorf("expected growth of 10kbps/day, actual: %v", u.GrowthKbpsPerDay)
+	}
+	if u.DaysUntilExhausted == nil || *u.DaysUntilExhausted != 8 {
+		t.Errorf("expected 8 days until exhausted, actual: %v", u.DaysUntilExhausted)
+	}
+
+	u = summarizeUtilization(points, 0)
+	if u.PeakUtilizationPercent != nil || u.DaysUntilExhausted != nil {
+		t.Errorf("expected no utilization or exhaustion for unknown capacity, actual: %v, %v", u.PeakUtilizationPercent, u.DaysUntilExhausted)
+	}
+}
+
+func TestBuildCapacityPlan(t *testing.T) {
+	now := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	cachegroups := []capacityCachegroup{
+		{Name: "edge1", Type: "EDGE_LOC", Parent: "mid", CapacityKbps: 100},
+		{Name: "edge2", Type: "EDGE_LOC", Parent: "mid", CapacityKbps: 100},
+		{Name: "edge3", Type: "EDGE_LOC", Parent: "mid", CapacityKbps: 100, Fallbacks: []string{"edge1"}},
+		{Name: "mid", Type: "MID_LOC", CapacityKbps: 500},
+	}
+	series := map[string][]capacityPoint{
+		"edge1": {{Time: now, Kbps: 50}},
+		"edge2": {{Time: now, Kbps: 40}},
+		"edge3": {{Time: now, Kbps: 20}},
+		"mid":   {{Time: now, Kbps: 100}},
+	}
+	topologies := map[string][]string{"top": {"edge1", "edge2", "mid"}}
+
+	plan := buildCapacityPlan(cachegroups, series, topologies)
+	if len(plan.Cachegroups) != 4 {
+		t.Fatalf("expected 4 cachegroups, actual: %d", len(plan.Cachegroups))
+	}
+
+	failures := map[string]map[string]float64{}
+	risks := map[string]bool{}
+	for _, cg := range plan.Cachegroups {
+		failures[cg.Name] = map[string]float64{}
+		for _, f := range cg.SiblingFailures {
+			failures[cg.Name][f.Cachegroup] = f.ProjectedKbps
+		}
+		risks[cg.Name] = cg.SiblingFailureRisk
+	}
+
+	// edge1 fails: shared between edge2 and edge3; edge2 fails: shared between edge1 and edge3; edge3 fails: all to its fallback edge1
+	if projected := failures["edge1"]["edge3"]; projected != 70 {
+		t.Errorf("expected edge1 to be projected at 70kbps if its fallback source edge3 fails, actual: %v", projected)
+	}
+	if projected := failures["edge1"]["edge2"]; projected != 70 {
+		t.Errorf("expected edge1 to be projected at 70kbps if sibling edge2 fails, actual: %v", projected)
+	}
+	if projected := failures["edge2"]["edge1"]; projected != 65 {
+		t.Errorf("expected edge2 to be projected at 65kbps if sibling edge1 fails, actual: %v", projected)
+	}
+	if _, ok := failures["edge2"]["edge3"]; ok {
+		t.Error("expected edge3 failing to go only to its fallback, but it was projected onto edge2")
+	}
+	if len(failures["mid"]) != 0 {
+		t.Errorf("expected no sibling failures for a cachegroup with no siblings, actual: %v", failures["mid"])
+	}
+	for name, risk := range risks {
+		if risk {
+			t.Errorf("expected no sibling failure risk for %s", name)
+		}
+	}
+
+	series["edge1"] = []capacityPoint{{Time: now, Kbps: 60}}
+	series["edge2"] = []capacityPoint{{Time: now, Kbps: 90}}
+	plan = buildCapacityPlan(cachegroups, series, topologies)
+	for _, cg := range plan.Cachegroups {
+		if cg.Name == "edge1" && !cg.SiblingFailureRisk {
+			t.Error("expected edge1 to be at risk if edge2 fails")
+		}
+	}
+
+	if len(plan.TopologyTiers) != 2 {
+		t.Fatalf("expected 2 topology tiers, actual: %d", len(plan.TopologyTiers))
+	}
+	edgeTier := plan.TopologyTiers[0]
+	if edgeTier.Tier != "EDGE_LOC" || edgeTier.CapacityKbps != 200 || edgeTier.PeakKbps != 150 {
+		t.Errorf("expected EDGE_LOC tier with capacity 200 and peak 150, actual: %+v", edgeTier)
+	}
+}
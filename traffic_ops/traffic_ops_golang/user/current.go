@@ -190,11 +190,12 @@ func ReplaceCurrent(w http.ResponseWriter, r *http.Request) {
 
 	changePasswd := false
 	changeConfirmPasswd := false
+	newPasswd := ""
 
 	// obfuscate passwords (UnmarshalAndValidate checks for equality with ConfirmLocalPassword)
 	// TODO: check for valid password via bad password list like Perl did? User creation doesn't...
 	if user.LocalPassword != nil && *user.LocalPassword != "" {
-		if ok, err := auth.IsGoodPassword(*user.LocalPassword); !ok {
+		if ok, err := auth.IsGoodPasswordForPolicy(*user.LocalPassword, inf.Config.PasswordPolicy); !ok {
 			errCode = http.StatusBadRequest
 			if err != nil {
 				userErr = err
@@ -205,6 +206,15 @@ func ReplaceCurrent(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if reused, err := auth.IsPasswordReused(tx, inf.User.ID, *user.LocalPassword, inf.Config.PasswordPolicy.HistoryCount); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		} else if reused {
+			userErr = fmt.Errorf("Password must not be any of your last %d passwords.", inf.Config.PasswordPolicy.HistoryCount)
+			api.HandleErr(w, r, tx, http.StatusBadRequest, userErr, nil)
+			return
+		}
+
 		hashPass, err := auth.DerivePassword(*user.LocalPassword)
 		if err != nil {
 			sysErr = fmt.Errorf("Hashing new password: %v", err)
@@ -213,6 +223,7 @@ func ReplaceCurrent(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		changePasswd = true
+		newPasswd = hashPass
 		user.LocalPassword = util.StrPtr(hashPass)
 	}
 
@@ -294,6 +305,18 @@ func ReplaceCurrent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if changePasswd {
+		if err := auth.RecordPasswordChange(tx, inf.User.ID, newPasswd, inf.Config.PasswordPolicy.HistoryCount); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+		// the user's other sessions end, but not the one they changed their password with
+		if _, err := auth.DeleteUserSessions(tx, inf.User.ID, inf.User.SessionID); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+	}
+
 	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "User profile was successfully updated", user)
 }
 
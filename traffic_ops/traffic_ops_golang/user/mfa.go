@@ -0,0 +1,270 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+)
+
+// mfaIssuer is the issuer of the TOTP secrets of Traffic Ops users, which authenticator apps display alongside the
+// user's name.
+const mfaIssuer = "Traffic Ops"
+
+const mfaStatusQuery = `
+SELECT mfa_enabled,
+       mfa_secret IS NOT NULL,
+       (SELECT COUNT(*) FROM user_mfa_recovery_code WHERE tm_user = tm_user.id)
+FROM tm_user
+WHERE id = $1
+`
+
+// GetMFA is the handler for GET requests to /user/current/mfa.
+func GetMFA(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	status := tc.UserMFAStatus{Required: inf.User.MFARequired}
+	hasSecret := false
+	if err := inf.Tx.Tx.QueryRow(mfaStatusQuery, inf.User.ID).Scan(&status.Enabled, &hasSecret, &status.RecoveryCodesRemaining); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying MFA status of user #%d: %v", inf.User.ID, err))
+		return
+	}
+	status.Enrolling = hasSecret && !status.Enabled
+	status.Verified = (status.Required || status.Enabled) && !inf.User.MFAPending
+	api.WriteResp(w, r, status)
+}
+
+// EnrollMFA is the handler for POST requests to /user/current/mfa/enroll.
+//
+// It generates a new TOTP secret for the current user, which doesn't take effect until the user verifies a code of
+// it. Enrolling again before verifying replaces the secret.
+func EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+
+	if inf.User.MFAEnabled {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("multi-factor authentication is already enabled - disable it, or ask an administrator to reset it, to enroll a new device"), nil)
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE tm_user SET mfa_secret = $1, mfa_last_step = NULL WHERE id = $2`, secret, inf.User.ID); err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("setting MFA secret of user #%d: %v", inf.User.ID, err))
+		return
+	}
+
+	enrollment := tc.UserMFAEnrollment{Secret: secret, URI: auth.TOTPURI(mfaIssuer, inf.User.UserName, secret)}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Add the secret to an authenticator app, then verify a code from it to enable multi-factor authentication.", enrollment)
+}
+
+// VerifyMFA is the handler for POST requests to /user/current/mfa/verify.
+//
+// If the current user is enrolling in multi-factor authentication, a valid TOTP code enables it, and the response
+// contains the user's new recovery codes. Otherwise, a valid TOTP or recovery code verifies the second factor for
+// the current session. Either way, the session cookie is replaced with one which records that verification.
+func VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+
+	otp, userErr := decodeMFACodeRequest(r)
+	if userErr != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, userErr, nil)
+		return
+	}
+
+	enabled, hasSecret := false, false
+	count := 0
+	if err := tx.QueryRow(mfaStatusQuery, inf.User.ID).Scan(&enabled, &hasSecret, &count); err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("querying MFA status of user #%d: %v", inf.User.ID, err))
+		return
+	}
+	if !hasSecret {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("not enrolled in multi-factor authentication"), nil)
+		return
+	}
+
+	// recovery codes can't be used to finish enrolling, since the point is to prove the user's device has the secret
+	ok, err := auth.VerifyMFACode(tx, inf.User.ID, otp, enabled)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("verifying MFA code of user #%d: %v", inf.User.ID, err))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("invalid one-time password"), nil)
+		return
+	}
+
+	verification := tc.UserMFAVerification{RecoveryCodes: []string{}}
+	msg := "Multi-factor authentication verified."
+	if !enabled {
+		if verification.RecoveryCodes, err = auth.GenerateRecoveryCodes(auth.RecoveryCodeCount); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+		if err := auth.ReplaceRecoveryCodes(tx, inf.User.ID, verification.RecoveryCodes); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+		if _, err := tx.Exec(`UPDATE tm_user SET mfa_enabled = TRUE WHERE id = $1`, inf.User.ID); err != nil {
+			api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("enabling MFA of user #%d: %v", inf.User.ID, err))
+			return
+		}
+		api.CreateChangeLogRawTx(api.ApiChange, fmt.Sprintf("USER: %s, ID: %d, ACTION: Enabled multi-factor authentication", inf.User.UserName, inf.User.ID), inf.User, tx)
+		msg = "Multi-factor authentication enabled. Store the recovery codes somewhere safe - they will not be shown again."
+	}
+
+	http.SetCookie(w, tocookie.GetSessionCookie(inf.User.UserName, inf.User.SessionID, true, tocookie.DefaultDuration, inf.Config.Secrets[0]))
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, msg, verification)
+}
+
+// DisableMFA is the handler for POST requests to /user/current/mfa/disable.
+func DisableMFA(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+
+	otp, userErr := decodeMFACodeRequest(r)
+	if userErr != nil {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, userErr, nil)
+		return
+	}
+	if !inf.User.MFAEnabled {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("multi-factor authentication is not enabled"), nil)
+		return
+	}
+	if inf.User.MFARequired {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("your role requires multi-factor authentication - ask an administrator to reset it to enroll a new device"), nil)
+		return
+	}
+
+	ok, err := auth.VerifyMFACode(tx, inf.User.ID, otp, true)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("verifying MFA code of user #%d: %v", inf.User.ID, err))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, tx, http.StatusBadRequest, errors.New("invalid one-time password"), nil)
+		return
+	}
+	if err := auth.ResetMFA(tx, inf.User.ID); err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, fmt.Sprintf("USER: %s, ID: %d, ACTION: Disabled multi-factor authentication", inf.User.UserName, inf.User.ID), inf.User, tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Multi-factor authentication disabled.")
+}
+
+// ResetMFA is the handler for POST requests to /users/{id}/mfa/reset, with which an administrator disables the
+// multi-factor authentication of a user who lost their device and recovery codes. If the user's role requires it,
+// they must enroll again the next time they log in.
+func ResetMFA(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+	id := inf.IntParams["id"]
+
+	username, userErr, sysErr, errCode := getAuthorizedUsername(tx, inf.User, id)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
+	if err := auth.ResetMFA(tx, id); err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, fmt.Sprintf("USER: %s, ID: %d, ACTION: Reset multi-factor authentication", username, id), inf.User, tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Multi-factor authentication reset for user "+username+".")
+}
+
+// decodeMFACodeRequest returns the one-time password in the body of r, or a user error if there isn't one.
+func decodeMFACodeRequest(r *http.Request) (string, error) {
+	req := tc.UserMFACodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return "", fmt.Errorf("couldn't parse request: %v", err)
+	}
+	if strings.TrimSpace(req.OTP) == "" {
+		return "", errors.New("otp: required")
+	}
+	return req.OTP, nil
+}
+
+// getAuthorizedUsername returns the username of the user with the given ID, or a user error if there's no such user or
+// the current user isn't authorized on their tenant.
+func getAuthorizedUsername(tx *sql.Tx, currentUser *auth.CurrentUser, id int) (string, error, error, int) {
+	user, exists, err := dbhelpers.GetUserByID(id, tx)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting user #%d: %v", id, err), http.StatusInternalServerError
+	}
+	if !exists {
+		return "", fmt.Errorf("no user with id %d", id), nil, http.StatusNotFound
+	}
+	if user.TenantID != nil {
+		if authorized, err := tenant.IsResourceAuthorizedToUserTx(*user.TenantID, currentUser, tx); err != nil {
+			return "", nil, fmt.Errorf("checking tenancy of user #%d: %v", id, err), http.StatusInternalServerError
+		} else if !authorized {
+			return "", errors.New("not authorized on this tenant"), nil, http.StatusForbidden
+		}
+	}
+	username := ""
+	if user.Username != nil {
+		username = *user.Username
+	}
+	return username, nil, nil, http.StatusOK
+}
@@ -0,0 +1,131 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+)
+
+const currentSessionsQuery = `
+SELECT id, created, last_seen, expires, COALESCE(user_agent, ''), COALESCE(client_ip, ''), session_key = $2
+FROM user_session
+WHERE tm_user = $1 AND expires > now()
+ORDER BY last_seen DESC
+`
+
+// GetCurrentSessions is the handler for GET requests to /user/current/sessions.
+func GetCurrentSessions(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	rows, err := inf.Tx.Tx.Query(currentSessionsQuery, inf.User.ID, inf.User.SessionID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying sessions of user #%d: %v", inf.User.ID, err))
+		return
+	}
+	defer rows.Close()
+
+	sessions := []tc.UserSession{}
+	for rows.Next() {
+		s := tc.UserSession{}
+		if err := rows.Scan(&s.ID, &s.Created, &s.LastSeen, &s.Expires, &s.UserAgent, &s.ClientIP, &s.Current); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning sessions of user #%d: %v", inf.User.ID, err))
+			return
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("iterating over sessions of user #%d: %v", inf.User.ID, err))
+		return
+	}
+	api.WriteResp(w, r, sessions)
+}
+
+// DeleteSessions is the handler for DELETE requests to /users/{id}/sessions, which logs the user out of all of their
+// sessions - except the one making the request, if the user is the current user.
+func DeleteSessions(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+	id := inf.IntParams["id"]
+
+	username, userErr, sysErr, errCode := getAuthorizedUsername(tx, inf.User, id)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
+	keepSessionID := ""
+	if id == inf.User.ID {
+		keepSessionID = inf.User.SessionID
+	}
+	ended, err := auth.DeleteUserSessions(tx, id, keepSessionID)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, fmt.Sprintf("USER: %s, ID: %d, ACTION: Ended %d sessions", username, id, ended), inf.User, tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, fmt.Sprintf("Ended %d sessions of user %s.", ended, username))
+}
+
+// Unlock is the handler for POST requests to /users/{id}/unlock, which unlocks a user who was locked out after too
+// many failed login attempts.
+func Unlock(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tx := inf.Tx.Tx
+	id := inf.IntParams["id"]
+
+	username, userErr, sysErr, errCode := getAuthorizedUsername(tx, inf.User, id)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
+	locked, err := auth.UnlockUser(tx, id)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	if !locked {
+		api.WriteRespAlert(w, r, tc.InfoLevel, "User "+username+" was not locked.")
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, fmt.Sprintf("USER: %s, ID: %d, ACTION: Unlocked", username, id), inf.User, tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "User "+username+" was unlocked.")
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-util"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
@@ -104,7 +105,7 @@ func (user *TOUser) Validate() error {
 
 	// Password is not required for update
 	if user.LocalPassword != nil {
-		_, err := auth.IsGoodLoginPair(*user.Username, *user.LocalPassword)
+		_, err := auth.IsGoodLoginPairForPolicy(*user.Username, *user.LocalPassword, user.passwordPolicy())
 		if err != nil {
 			return err
 		}
@@ -113,6 +114,14 @@ func (user *TOUser) Validate() error {
 	return util.JoinErrs(tovalidate.ToErrors(validateErrs))
 }
 
+// passwordPolicy returns the configured policy which the user's password must satisfy.
+func (user *TOUser) passwordPolicy() config.ConfigPasswordPolicy {
+	if user.ReqInfo == nil || user.ReqInfo.Config == nil {
+		return auth.DefaultPasswordPolicy
+	}
+	return user.ReqInfo.Config.PasswordPolicy
+}
+
 func (user *TOUser) postValidate() error {
 	validateErrs := validation.Errors{
 		"localPasswd": validation.Validate(user.LocalPassword, validation.Required),
@@ -165,6 +174,10 @@ func (user *TOUser) Create() (error, error, int) {
 		return nil, fmt.Errorf("too many rows affected from user insert"), http.StatusInternalServerError
 	}
 
+	if err := auth.RecordPasswordChange(user.ReqInfo.Tx.Tx, id, *user.LocalPassword, user.passwordPolicy().HistoryCount); err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+
 	user.ID = &id
 	user.LastUpdated = &lastUpdated
 	user.Tenant = &tenant
@@ -218,6 +231,9 @@ func (this *TOUser) Read(h http.Header, useIMS bool) ([]interface{}, error, erro
 		if err = rows.StructScan(user); err != nil {
 			return nil, nil, fmt.Errorf("parsing user rows: %v", err), http.StatusInternalServerError, nil
 		}
+		if inf.Version.Major < 3 {
+			user.Locked = nil
+		}
 		users = append(users, *user)
 	}
 
@@ -258,7 +274,14 @@ func (user *TOUser) Update() (error, error, int) {
 		return usrErr, sysErr, code
 	}
 
+	tx := user.ReqInfo.Tx.Tx
+	historyCount := user.passwordPolicy().HistoryCount
 	if user.LocalPassword != nil {
+		if reused, err := auth.IsPasswordReused(tx, *user.ID, *user.LocalPassword, historyCount); err != nil {
+			return nil, err, http.StatusInternalServerError
+		} else if reused {
+			return fmt.Errorf("password must not be any of the user's last %d passwords", historyCount), nil, http.StatusBadRequest
+		}
 		var err error
 		*user.LocalPassword, err = auth.DerivePassword(*user.LocalPassword)
 		if err != nil {
@@ -266,6 +289,17 @@ func (user *TOUser) Update() (error, error, int) {
 		}
 	}
 
+	// a user whose role changes to a less privileged one is logged out, as is one whose password changes
+	oldPrivLevel := 0
+	if err := tx.QueryRow(`SELECT r.priv_level FROM tm_user u JOIN role r ON u.role = r.id WHERE u.id = $1`, *user.ID).Scan(&oldPrivLevel); err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("querying priv level of user #%d: %v", *user.ID, err), http.StatusInternalServerError
+	}
+	newPrivLevel, _, err := dbhelpers.GetPrivLevelFromRoleID(tx, *user.Role)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	changedPasswd := user.LocalPassword
+
 	resultRows, err := user.ReqInfo.Tx.NamedQuery(user.UpdateQuery(), user)
 	if err != nil {
 		return api.ParseDBError(err)
@@ -296,6 +330,21 @@ func (user *TOUser) Update() (error, error, int) {
 		return nil, fmt.Errorf("this update affected too many rows: %d", rowsAffected), http.StatusInternalServerError
 	}
 
+	if changedPasswd != nil {
+		if err := auth.RecordPasswordChange(tx, *user.ID, *changedPasswd, historyCount); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+	}
+	if changedPasswd != nil || newPrivLevel < oldPrivLevel {
+		keepSessionID := ""
+		if *user.ID == user.ReqInfo.User.ID {
+			keepSessionID = user.ReqInfo.User.SessionID
+		}
+		if _, err := auth.DeleteUserSessions(tx, *user.ID, keepSessionID); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+	}
+
 	return nil, nil, http.StatusOK
 }
 
@@ -370,7 +419,8 @@ func (user *TOUser) SelectQuery() string {
 	u.registration_sent,
 	u.tenant_id,
 	t.name as tenant,
-	u.last_updated
+	u.last_updated,
+	COALESCE(u.locked_until > now(), FALSE) as locked
 	FROM tm_user u
 	LEFT JOIN tenant t ON u.tenant_id = t.id
 	LEFT JOIN role r ON u.role = r.id`
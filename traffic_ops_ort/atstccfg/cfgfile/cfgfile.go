@@ -208,9 +208,54 @@ func GetTOData(cfg config.TCCfg) (*config.TOData, error) {
 			toData.Profile = profile
 			return nil
 		}
+		originGroupsF := func() error {
+			defer func(start time.Time) { log.Infof("originGroupsF took %v\n", time.Since(start)) }(time.Now())
+			groups, unsupported, err := cfg.TOClientNew.GetCDNOriginGroups(tc.CDNName(server.CDNName))
+			if err != nil {
+				return errors.New("getting origin groups: " + err.Error())
+			}
+			if unsupported {
+				log.Warnln("Traffic Ops does not support Origin Groups, generating config without them!")
+			}
+			originGroups := map[int]tc.OriginGroup{}
+			for _, group := range groups {
+				if group.DeliveryServiceID == nil {
+					continue // TODO warn?
+				}
+				originGroups[*group.DeliveryServiceID] = group
+			}
+			toData.OriginGroups = originGroups
+			return nil
+		}
+		topologiesF := func() error {
+			defer func(start time.Time) { log.Infof("topologiesF took %v\n", time.Since(start)) }(time.Now())
+			dsTopologies, unsupported, err := cfg.TOClientNew.GetCDNDeliveryServiceTopologies(server.CDNID)
+			if err != nil {
+				return errors.New("getting delivery service topologies: " + err.Error())
+			}
+			if unsupported {
+				log.Warnln("Traffic Ops does not support Topologies, generating config without them!")
+				toData.Topologies = map[string]tc.Topology{}
+				toData.DSTopologies = map[int]string{}
+				return nil
+			}
+			topologies := map[string]tc.Topology{}
+			if len(dsTopologies) > 0 {
+				tops, _, err := cfg.TOClientNew.GetTopologies()
+				if err != nil {
+					return errors.New("getting topologies: " + err.Error())
+				}
+				for _, topology := range tops {
+					topologies[topology.Name] = topology
+				}
+			}
+			toData.Topologies = topologies
+			toData.DSTopologies = dsTopologies
+			return nil
+		}
 		fs := []func() error{dsF, serverParamsF, cdnF, profileF}
 		if !cfg.RevalOnly {
-			fs = append([]func() error{sslF}, fs...) // skip ssl keys for reval only, which doesn't need them
+			fs = append([]func() error{sslF, originGroupsF, topologiesF}, fs...) // skip ssl keys, origin groups and topologies for reval only, which doesn't need them
 		}
 		return util.JoinErrs(runParallel(fs))
 	}
@@ -201,5 +201,49 @@ func GetMeta(toData *config.TOData, dir string) (*tc.ATSConfigMetaData, error) {
 	if err != nil {
 		return nil, errors.New("generating: " + err.Error())
 	}
+
+	// remap.config uses strategies.yaml for Origin Groups on ATS 9+, so it must be in the meta config whenever remap.config refers to it.
+	atsVersionParam := ""
+	for _, param := range toData.ServerParams {
+		if param.ConfigFile != "package" || param.Name != "trafficserver" {
+			continue
+		}
+		atsVersionParam = param.Value
+		break
+	}
+	if atsVersionParam == "" {
+		atsVersionParam = atscfg.DefaultATSVersion
+	}
+	atsMajorVer, err := atscfg.GetATSMajorVersionFromATSVersion(atsVersionParam)
+	if err != nil {
+		return nil, errors.New("getting ATS major version from version parameter (profile '" + toData.Server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
+	}
+	dsArr := []tc.DeliveryServiceNullable{}
+	for _, ds := range dses {
+		dsArr = append(dsArr, ds)
+	}
+	strategyDSes, err := strategyDSIDs(toData, &serverInfo, atsMajorVer, dsArr)
+	if err != nil {
+		return nil, errors.New("getting delivery services using strategies: " + err.Error())
+	}
+	if len(strategyDSes) > 0 {
+		hasStrategies := false
+		for _, fi := range metaObj.ConfigFiles {
+			if fi.FileNameOnDisk == atscfg.StrategiesYAMLFileName {
+				hasStrategies = true
+				break
+			}
+		}
+		if !hasStrategies {
+			if dir == "" {
+				return nil, errors.New("required file '" + atscfg.StrategiesYAMLFileName + "' has no location Parameter, and ATS config directory not found.")
+			}
+			metaObj.ConfigFiles = append(metaObj.ConfigFiles, tc.ATSConfigMetaDataConfigFile{
+				FileNameOnDisk: atscfg.StrategiesYAMLFileName,
+				Location:       dir,
+				Scope:          string(tc.ATSConfigMetaDataConfigFileScopeServers),
+			})
+		}
+	}
 	return &metaObj, nil
 }
@@ -388,6 +388,9 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 		}
 
 		ds.RequiredCapabilities = toData.DSRequiredCapabilities[*tcDS.ID]
+		if group, ok := toData.OriginGroups[*tcDS.ID]; ok {
+			ds.OriginGroup = &group
+		}
 
 		parentConfigDSes = append(parentConfigDSes, ds)
 	}
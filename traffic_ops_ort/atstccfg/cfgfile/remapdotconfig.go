@@ -288,6 +288,17 @@ func GetConfigFileServerRemapDotConfig(toData *config.TOData) (string, string, s
 		SecondaryParentCacheGroupType: secondaryParentCGType,
 		Type:                          toData.Server.Type,
 	}
+
+	strategyDSes, err := strategyDSIDs(toData, serverInfo, atsMajorVer, filteredDSes)
+	if err != nil {
+		return "", "", "", errors.New("getting delivery services using strategies: " + err.Error())
+	}
+	for i, dsData := range remapConfigDSData {
+		if _, ok := strategyDSes[dsData.ID]; ok {
+			remapConfigDSData[i].Strategy = util.StrPtr(dsData.Name)
+		}
+	}
+
 	return atscfg.MakeRemapDotConfig(tc.CacheName(toData.Server.HostName), toData.TOToolName, toData.TOURL, atsMajorVer, cacheURLParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapConfigDSData), atscfg.ContentTypeRemapDotConfig, atscfg.LineCommentRemapDotConfig, nil
 }
 
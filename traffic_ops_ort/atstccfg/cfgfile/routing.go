@@ -158,6 +158,7 @@ func ServerConfigFileFuncs() map[string]func(toData *config.TOData) (string, str
 		"hosting.config":  GetConfigFileServerHostingDotConfig,
 		"packages":        GetConfigFileServerPackages,
 		"chkconfig":       GetConfigFileServerChkconfig,
+		"strategies.yaml": GetConfigFileServerStrategiesDotYAML,
 	}
 }
 
@@ -0,0 +1,77 @@
+package cfgfile
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops_ort/atstccfg/config"
+)
+
+func GetConfigFileServerStrategiesDotYAML(toData *config.TOData) (string, string, string, error) {
+	groups := []tc.OriginGroup{}
+	for _, ds := range toData.DeliveryServices {
+		if ds.ID == nil {
+			continue
+		}
+		if group, ok := toData.OriginGroups[*ds.ID]; ok {
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return *groups[i].DeliveryServiceID < *groups[j].DeliveryServiceID })
+	return atscfg.MakeStrategiesDotYAML(tc.CacheName(toData.Server.HostName), toData.TOToolName, toData.TOURL, groups), atscfg.ContentTypeStrategiesDotYAML, atscfg.LineCommentStrategiesDotYAML, nil
+}
+
+// strategyDSIDs returns the IDs of the given Delivery Services whose remap rules on the server
+// use their strategies.yaml strategy, rather than parent.config.
+func strategyDSIDs(toData *config.TOData, server *atscfg.ServerInfo, atsMajorVer int, dses []tc.DeliveryServiceNullable) (map[int]struct{}, error) {
+	dsIDs := map[int]struct{}{}
+	if atsMajorVer < atscfg.StrategiesDotYAMLMinATSMajorVersion {
+		return dsIDs, nil
+	}
+	for _, ds := range dses {
+		if ds.ID == nil {
+			continue
+		}
+		if _, ok := toData.OriginGroups[*ds.ID]; !ok {
+			continue
+		}
+		if (ds.OriginShield != nil && *ds.OriginShield != "") || (ds.MultiSiteOrigin != nil && *ds.MultiSiteOrigin) {
+			continue // origin shield and MSO take precedence over the Origin Group, like parent.config
+		}
+		topology := (*tc.Topology)(nil)
+		if topologyName, ok := toData.DSTopologies[*ds.ID]; ok {
+			top, ok := toData.Topologies[topologyName]
+			if !ok {
+				return nil, errors.New("delivery service " + strconv.Itoa(*ds.ID) + " topology '" + topologyName + "' not found in Topologies")
+			}
+			topology = &top
+		}
+		if !atscfg.UsesOriginGroup(server, toData.Server.Cachegroup, topology) {
+			continue
+		}
+		dsIDs[*ds.ID] = struct{}{}
+	}
+	return dsIDs, nil
+}
@@ -0,0 +1,70 @@
+package cfgfile
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops_ort/atstccfg/config"
+)
+
+func TestStrategyDSIDs(t *testing.T) {
+	toData := &config.TOData{
+		Server:       tc.Server{Cachegroup: "cg0"},
+		OriginGroups: map[int]tc.OriginGroup{1: {}, 2: {}},
+	}
+	dses := []tc.DeliveryServiceNullable{
+		{ID: util.IntPtr(1)},
+		{ID: util.IntPtr(2), OriginShield: util.StrPtr("myshield")},
+		{ID: util.IntPtr(3)},
+	}
+	topLevel := &atscfg.ServerInfo{ParentCacheGroupID: atscfg.InvalidID, SecondaryParentCacheGroupID: atscfg.InvalidID}
+	child := &atscfg.ServerInfo{ParentCacheGroupID: 42, ParentCacheGroupType: tc.CacheGroupMidTypeName, SecondaryParentCacheGroupID: atscfg.InvalidID}
+
+	dsIDs, err := strategyDSIDs(toData, topLevel, 9, dses)
+	if err != nil {
+		t.Fatalf("strategyDSIDs expected: nil error, actual: %v", err)
+	}
+	if len(dsIDs) != 1 {
+		t.Errorf("strategyDSIDs expected: only the origin group DS without an origin shield, actual: %v", dsIDs)
+	} else if _, ok := dsIDs[1]; !ok {
+		t.Errorf("strategyDSIDs expected: DS 1, actual: %v", dsIDs)
+	}
+
+	if dsIDs, err = strategyDSIDs(toData, topLevel, 8, dses); err != nil {
+		t.Fatalf("strategyDSIDs expected: nil error, actual: %v", err)
+	} else if len(dsIDs) != 0 {
+		t.Errorf("strategyDSIDs for ATS 8 expected: no DSes, actual: %v", dsIDs)
+	}
+
+	if dsIDs, err = strategyDSIDs(toData, child, 9, dses); err != nil {
+		t.Fatalf("strategyDSIDs expected: nil error, actual: %v", err)
+	} else if len(dsIDs) != 0 {
+		t.Errorf("strategyDSIDs for a cache with a parent expected: no DSes, actual: %v", dsIDs)
+	}
+
+	toData.DSTopologies = map[int]string{1: "missing"}
+	if _, err = strategyDSIDs(toData, topLevel, 9, dses); err == nil {
+		t.Error("strategyDSIDs with a missing topology expected: error, actual: nil")
+	}
+}
@@ -297,4 +297,13 @@ type TOData struct {
 
 	// SSLKeys must be all the ssl keys for the server's cdn.
 	SSLKeys []tc.CDNSSLKeys
+
+	// OriginGroups must be a map of all delivery service IDs on this server's CDN with an Origin Group, to their Origin Group. Delivery Services with no Origin Group must not have an entry in the map.
+	OriginGroups map[int]tc.OriginGroup
+
+	// Topologies must be a map of the names of all topologies of delivery services on this server's CDN, to their Topology.
+	Topologies map[string]tc.Topology
+
+	// DSTopologies must be a map of all delivery service IDs on this server's CDN with a Topology, to the name of their Topology. Delivery Services with no Topology must not have an entry in the map.
+	DSTopologies map[int]string
 }
@@ -28,7 +28,9 @@ package toreqnew
  */
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"strconv"
@@ -95,3 +97,124 @@ func (cl *TOClient) GetCDNDeliveryServices(cdnID int) ([]tc.DeliveryServiceNulla
 	}
 	return deliveryServices, false, nil
 }
+
+// GetCDNOriginGroups returns the origin groups of the delivery services on the given CDN, whether this client's version is unsupported by the server, and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+func (cl *TOClient) GetCDNOriginGroups(cdnName tc.CDNName) ([]tc.OriginGroup, bool, error) {
+	originGroups := []tc.OriginGroup{}
+	unsupported := false
+	err := torequtil.GetRetry(cl.NumRetries, "cdn_"+string(cdnName)+"_origin_groups", &originGroups, func(obj interface{}) error {
+		// C and RawRequest should generally never be used, but the v2 client has no origin group funcs, because origin groups are new in API 3.0.
+		// TODO change to a client func, when ORT uses a 3.0 client.
+		resp, remoteAddr, err := cl.C.RawRequest(http.MethodGet, "/api/3.0/origin_groups?cdn="+url.QueryEscape(string(cdnName)), nil)
+		if err != nil {
+			return errors.New("getting origin groups from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': " + err.Error())
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusServiceUnavailable {
+			unsupported = true
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.New("getting origin groups from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': returned " + strconv.Itoa(resp.StatusCode))
+		}
+		toResp := tc.OriginGroupsResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(&toResp); err != nil {
+			return errors.New("decoding origin groups from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': " + err.Error())
+		}
+		groups := obj.(*[]tc.OriginGroup)
+		*groups = toResp.Response
+		return nil
+	})
+	if unsupported {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("getting origin groups: " + err.Error())
+	}
+	return originGroups, false, nil
+}
+
+// GetTopologies returns all topologies, whether this client's version is unsupported by the server, and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+func (cl *TOClient) GetTopologies() ([]tc.Topology, bool, error) {
+	topologies := []tc.Topology{}
+	unsupported := false
+	err := torequtil.GetRetry(cl.NumRetries, "topologies", &topologies, func(obj interface{}) error {
+		// C and RawRequest should generally never be used, but the v2 client has no topology funcs, because topologies are new in API 3.0.
+		// TODO change to a client func, when ORT uses a 3.0 client.
+		resp, remoteAddr, err := cl.C.RawRequest(http.MethodGet, "/api/3.0/topologies", nil)
+		if err != nil {
+			return errors.New("getting topologies from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': " + err.Error())
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusServiceUnavailable {
+			unsupported = true
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.New("getting topologies from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': returned " + strconv.Itoa(resp.StatusCode))
+		}
+		toResp := tc.TopologiesResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(&toResp); err != nil {
+			return errors.New("decoding topologies from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': " + err.Error())
+		}
+		topologies := obj.(*[]tc.Topology)
+		*topologies = toResp.Response
+		return nil
+	})
+	if unsupported {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("getting topologies: " + err.Error())
+	}
+	return topologies, false, nil
+}
+
+// GetCDNDeliveryServiceTopologies returns a map of the IDs of the delivery services on the given CDN with a topology, to the name of their topology; whether this client's version is unsupported by the server; and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+func (cl *TOClient) GetCDNDeliveryServiceTopologies(cdnID int) (map[int]string, bool, error) {
+	dsTopologies := map[int]string{}
+	unsupported := false
+	err := torequtil.GetRetry(cl.NumRetries, "cdn_"+strconv.Itoa(cdnID)+"_deliveryservice_topologies", &dsTopologies, func(obj interface{}) error {
+		// C and RawRequest should generally never be used, but the v2 client's delivery services have no topology, because topologies are new in API 3.0.
+		// TODO remove, and use the delivery services' Topology, when ORT uses a 3.0 client.
+		resp, remoteAddr, err := cl.C.RawRequest(http.MethodGet, "/api/3.0/deliveryservices?cdn="+strconv.Itoa(cdnID), nil)
+		if err != nil {
+			return errors.New("getting delivery services from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': " + err.Error())
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusServiceUnavailable {
+			unsupported = true
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.New("getting delivery services from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': returned " + strconv.Itoa(resp.StatusCode))
+		}
+		toResp := struct {
+			Response []struct {
+				ID       *int    `json:"id"`
+				Topology *string `json:"topology"`
+			} `json:"response"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&toResp); err != nil {
+			return errors.New("decoding delivery services from Traffic Ops '" + torequtil.MaybeIPStr(remoteAddr) + "': " + err.Error())
+		}
+		dsTopologies := obj.(*map[int]string)
+		for _, ds := range toResp.Response {
+			if ds.ID == nil || ds.Topology == nil || *ds.Topology == "" {
+				continue
+			}
+			(*dsTopologies)[*ds.ID] = *ds.Topology
+		}
+		return nil
+	})
+	if unsupported {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("getting delivery service topologies: " + err.Error())
+	}
+	return dsTopologies, false, nil
+}
@@ -756,6 +756,7 @@ public class ConfigHandler {
 			final String loc = locIter.next();
 			final JsonNode jo = JsonUtils.getJsonNode(locationsJo, loc);
 			List<String> backupCacheGroups = null;
+			final Map<String, List<String>> topologyBackupCacheGroups = new HashMap<>();
 			boolean useClosestOnBackupFailure = true;
 
 			if (jo != null && jo.has("backupLocations")) {
@@ -767,6 +768,14 @@ public class ConfigHandler {
 					}
 					useClosestOnBackupFailure = JsonUtils.optBoolean(backupConfigJson, "fallbackToClosest", false);
 				}
+				if (backupConfigJson.has("topologies")) {
+					final JsonNode topologiesJson = JsonUtils.getJsonNode(backupConfigJson, "topologies");
+					topologiesJson.fieldNames().forEachRemaining((String topologyName) -> {
+						final List<String> topologyBackups = new ArrayList<>();
+						topologiesJson.get(topologyName).forEach((JsonNode cacheGroup) -> topologyBackups.add(cacheGroup.asText()));
+						topologyBackupCacheGroups.put(topologyName, topologyBackups);
+					});
+				}
 
 			}
 
@@ -780,6 +789,7 @@ public class ConfigHandler {
 										JsonUtils.getDouble(jo, "latitude"),
 										JsonUtils.getDouble(jo, "longitude")),
 								backupCacheGroups,
+								topologyBackupCacheGroups,
 								useClosestOnBackupFailure,
 								enabledLocalizationMethods));
 			} catch (JsonUtilsException e) {
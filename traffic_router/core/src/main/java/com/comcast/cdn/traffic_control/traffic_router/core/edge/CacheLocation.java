@@ -38,6 +38,7 @@ public class CacheLocation extends Location {
 
 	private final Map<String, Cache> caches;
 	private List<String> backupCacheGroups = null;
+	private Map<String, List<String>> topologyBackupCacheGroups = new HashMap<>();
 	private boolean useClosestGeoOnBackupFailure = true;
 	private final Set<LocalizationMethod> enabledLocalizationMethods;
 
@@ -83,9 +84,38 @@ public class CacheLocation extends Location {
 			final List<String> backupCacheGroups,
 			final boolean useClosestGeoOnBackupFailure,
 			final Set<LocalizationMethod> enabledLocalizationMethods
+	) {
+		this(id, geolocation, backupCacheGroups, new HashMap<>(), useClosestGeoOnBackupFailure, enabledLocalizationMethods);
+	}
+
+	/**
+	 * Creates a CacheLocation with the specified ID at the specified location.
+	 *
+	 * @param id
+	 *            the id of the location
+	 * @param geolocation
+	 *            the coordinates of this location
+	 *
+	 * @param backupCacheGroups
+	 *            the backup cache groups for this id
+	 *
+	 * @param topologyBackupCacheGroups
+	 *            the backup cache groups for this id for the delivery services of each topology, keyed by topology name
+	 *
+	 * @param useClosestGeoOnBackupFailure
+	 *            the backup fallback setting for this id
+	 */
+	public CacheLocation(
+			final String id,
+			final Geolocation geolocation,
+			final List<String> backupCacheGroups,
+			final Map<String, List<String>> topologyBackupCacheGroups,
+			final boolean useClosestGeoOnBackupFailure,
+			final Set<LocalizationMethod> enabledLocalizationMethods
 	) {
 		super(id, geolocation);
 		this.backupCacheGroups = backupCacheGroups;
+		this.topologyBackupCacheGroups = topologyBackupCacheGroups;
 		this.useClosestGeoOnBackupFailure = useClosestGeoOnBackupFailure;
 		this.enabledLocalizationMethods = enabledLocalizationMethods;
 		if (this.enabledLocalizationMethods.isEmpty()) {
@@ -173,6 +203,29 @@ public class CacheLocation extends Location {
 		return backupCacheGroups;
 	}
 
+	/**
+	 * Gets the backup cache groups for a delivery service of the given topology: the backup cache groups of the
+	 * topology, followed by the other backupCacheGroups.
+	 *
+	 * @param topology
+	 *            the name of the topology of the delivery service, or <code>null</code> if it has none
+	 * @return the backup cache groups, or <code>null</code> if there are none configured
+	 */
+	public List<String> getBackupCacheGroups(final String topology) {
+		final List<String> topologyBackups = topology == null ? null : topologyBackupCacheGroups.get(topology);
+		if (topologyBackups == null || topologyBackups.isEmpty()) {
+			return backupCacheGroups;
+		}
+
+		final List<String> backups = new ArrayList<>(topologyBackups);
+		if (backupCacheGroups != null) {
+			backupCacheGroups.stream()
+					.filter(cacheGroup -> !backups.contains(cacheGroup))
+					.forEach(backups::add);
+		}
+		return backups;
+	}
+
 	/**
 	 * Tests useClosestGeoOnBackupFailure.
 	 * 
@@ -240,7 +240,9 @@ public class Node extends DefaultHashable {
 
 		this.setIsAvailable(isAvailable);
 
-
+		// caches which recently became available are "warming", and only receive the weight fraction of their share of load
+		final boolean warming = JsonUtils.optBoolean(state, "warming", false);
+		this.setSlowStartWeight(warming ? JsonUtils.optDouble(state, "weight", 0) : 1);
 	}
 
 	public int getHttpsPort() {
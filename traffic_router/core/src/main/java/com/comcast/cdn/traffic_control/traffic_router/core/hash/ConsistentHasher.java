@@ -18,6 +18,7 @@ package com.comcast.cdn.traffic_control.traffic_router.core.hash;
 import com.comcast.cdn.traffic_control.traffic_router.core.ds.Dispersion;
 
 import java.util.ArrayList;
+import java.util.Collection;
 import java.util.Collections;
 import java.util.List;
 import java.util.NoSuchElementException;
@@ -25,6 +26,7 @@ import java.util.SortedMap;
 import java.util.TreeMap;
 
 public class ConsistentHasher {
+	private static final double MAX_HASH = Math.pow(2, 128);
 	final private MD5HashFunction hashFunction = new MD5HashFunction();
 
 	public <T extends Hashable> T selectHashable(final List<T> hashables, final Dispersion dispersion, final String s) {
@@ -40,7 +42,7 @@ public class ConsistentHasher {
 		final SortedMap<Double, T> sortedHashables = sortHashables(hashables, s);
 		final List<T> selectedHashables = new ArrayList<T>();
 
-		for (final T hashable : sortedHashables.values()) {
+		for (final T hashable : applySlowStartWeights(sortedHashables.values(), s)) {
 			if (dispersion != null && selectedHashables.size() >= dispersion.getLimit()) {
 				break;
 			}
@@ -55,6 +57,34 @@ public class ConsistentHasher {
 		return selectedHashables;
 	}
 
+	/*
+	 * Hashables with a slow start weight below 1, such as caches ramping up after becoming available, keep their place
+	 * in the order for only that fraction of strings; for the rest, they're moved after all other hashables. Whether a
+	 * string keeps a hashable in place is consistent, and as the weight grows, the strings keeping it in place only grow.
+	 */
+	private <T extends Hashable> List<T> applySlowStartWeights(final Collection<T> sortedHashables, final String s) {
+		final List<T> orderedHashables = new ArrayList<T>();
+		final List<T> demotedHashables = new ArrayList<T>();
+
+		for (final T hashable : sortedHashables) {
+			if (hashable.getSlowStartWeight() >= 1 || !hashable.hasHashes() || getSlowStartFraction(hashable, s) < hashable.getSlowStartWeight()) {
+				orderedHashables.add(hashable);
+			} else {
+				demotedHashables.add(hashable);
+			}
+		}
+
+		orderedHashables.addAll(demotedHashables);
+		return orderedHashables;
+	}
+
+	/*
+	 * Returns a number from 0 to 1 that's consistent for the string and hashable, and uniformly distributed over strings.
+	 */
+	private <T extends Hashable> double getSlowStartFraction(final T hashable, final String s) {
+		return hashFunction.hash(s + "--" + hashable.getClosestHash(0)) / MAX_HASH;
+	}
+
 	@SuppressWarnings("PMD.EmptyCatchBlock")
 	private <T extends Hashable> SortedMap<Double, T> sortHashables(final List<T> hashables, final String s) {
 		final double hash = hashFunction.hash(s);
@@ -22,6 +22,7 @@ import java.util.TreeSet;
 public class DefaultHashable implements Hashable<DefaultHashable>, Comparable<DefaultHashable> {
 	private Double[] hashes;
 	private int order = 0;
+	private double slowStartWeight = 1;
 
 	@Override
 	public void setOrder(final int order) {
@@ -33,6 +34,18 @@ public class DefaultHashable implements Hashable<DefaultHashable>, Comparable<De
 		return order;
 	}
 
+	/**
+	 * Sets the fraction of its full share of selections this hashable should receive while slow starting, from 0 to 1.
+	 */
+	public void setSlowStartWeight(final double slowStartWeight) {
+		this.slowStartWeight = slowStartWeight;
+	}
+
+	@Override
+	public double getSlowStartWeight() {
+		return slowStartWeight;
+	}
+
 	@Override
 	public boolean hasHashes() {
 		return hashes.length > 0 ? true : false;
@@ -24,4 +24,5 @@ public interface Hashable <E> extends Comparable<E> {
 	boolean hasHashes();
 	int getOrder();
 	void setOrder(int order);
+	double getSlowStartWeight();
 }
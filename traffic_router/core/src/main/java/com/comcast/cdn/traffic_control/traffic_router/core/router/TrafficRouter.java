@@ -1133,8 +1133,10 @@ public class TrafficRouter {
 			return cacheLocation;
 		}
 
-		if (cacheLocation != null && cacheLocation.getBackupCacheGroups() != null) {
-			for (final String cacheGroup : cacheLocation.getBackupCacheGroups()) {
+		final String topology = deliveryService != null ? deliveryService.getTopology() : null;
+		final List<String> backupCacheGroups = cacheLocation != null ? cacheLocation.getBackupCacheGroups(topology) : null;
+		if (backupCacheGroups != null) {
+			for (final String cacheGroup : backupCacheGroups) {
 				final CacheLocation bkCacheLocation = getCacheRegister().getCacheLocationById(cacheGroup);
 				if (bkCacheLocation != null && !bkCacheLocation.isEnabledFor(localizationMethod)) {
 					continue;
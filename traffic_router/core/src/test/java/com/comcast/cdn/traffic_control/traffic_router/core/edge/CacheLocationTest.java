@@ -0,0 +1,58 @@
+/*
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package com.comcast.cdn.traffic_control.traffic_router.core.edge;
+
+import com.comcast.cdn.traffic_control.traffic_router.geolocation.Geolocation;
+import org.junit.Before;
+import org.junit.Test;
+
+import java.util.Arrays;
+import java.util.HashMap;
+import java.util.HashSet;
+import java.util.List;
+import java.util.Map;
+
+import static org.hamcrest.MatcherAssert.assertThat;
+import static org.hamcrest.Matchers.contains;
+import static org.hamcrest.Matchers.nullValue;
+
+public class CacheLocationTest {
+	private CacheLocation cacheLocation;
+
+	@Before
+	public void before() {
+		final Map<String, List<String>> topologyBackupCacheGroups = new HashMap<>();
+		topologyBackupCacheGroups.put("topology1", Arrays.asList("edge2", "edge3"));
+		cacheLocation = new CacheLocation("edge1", new Geolocation(0, 0), Arrays.asList("edge3", "edge4"), topologyBackupCacheGroups, true, new HashSet<>());
+	}
+
+	@Test
+	public void itFallsBackAlongTheTopologyFirst() {
+		assertThat(cacheLocation.getBackupCacheGroups("topology1"), contains("edge2", "edge3", "edge4"));
+	}
+
+	@Test
+	public void itFallsBackToTheBackupCacheGroupsWithoutATopology() {
+		assertThat(cacheLocation.getBackupCacheGroups(null), contains("edge3", "edge4"));
+		assertThat(cacheLocation.getBackupCacheGroups("topology2"), contains("edge3", "edge4"));
+	}
+
+	@Test
+	public void itHasNoBackupsWhenNoneAreConfigured() {
+		final CacheLocation location = new CacheLocation("edge1", new Geolocation(0, 0));
+		assertThat(location.getBackupCacheGroups("topology1"), nullValue());
+	}
+}
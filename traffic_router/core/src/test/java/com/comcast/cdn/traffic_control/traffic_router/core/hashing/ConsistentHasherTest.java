@@ -181,6 +181,64 @@ public class ConsistentHasherTest {
 		}
 	}
 
+	@Test
+	public void itShiftsLoadGraduallyToSlowStartingHashables() {
+		final ObjectMapper mapper = new ObjectMapper();
+		final Dispersion dispersion = new Dispersion(mapper.createObjectNode());
+
+		List<String> randomPaths = new ArrayList<>();
+
+		for (int i = 0; i < 10000; i++) {
+			randomPaths.add(generateRandomPath());
+		}
+
+		Map<String, DefaultHashable> fullWeightSelections = new HashMap<>();
+
+		for (String randomPath : randomPaths) {
+			fullWeightSelections.put(randomPath, consistentHasher.selectHashable(hashables, dispersion, randomPath));
+		}
+
+		hashable1.setSlowStartWeight(0);
+
+		for (String randomPath : randomPaths) {
+			assertThat(consistentHasher.selectHashable(hashables, dispersion, randomPath), anyOf(equalTo(hashable2), equalTo(hashable3)));
+		}
+
+		List<DefaultHashable> onlyHashable = new ArrayList<>();
+		onlyHashable.add(hashable1);
+		assertThat(consistentHasher.selectHashable(onlyHashable, dispersion, "some-string"), equalTo(hashable1));
+
+		hashable1.setSlowStartWeight(0.5);
+
+		Map<String, DefaultHashable> halfWeightSelections = new HashMap<>();
+		int fullWeightCount = 0;
+		int halfWeightCount = 0;
+
+		for (String randomPath : randomPaths) {
+			final DefaultHashable hashable = consistentHasher.selectHashable(hashables, dispersion, randomPath);
+			halfWeightSelections.put(randomPath, hashable);
+
+			if (fullWeightSelections.get(randomPath) == hashable1) {
+				fullWeightCount++;
+			}
+
+			if (hashable == hashable1) {
+				halfWeightCount++;
+				assertThat(fullWeightSelections.get(randomPath), equalTo(hashable1));
+			}
+		}
+
+		assertThat(halfWeightCount, allOf(greaterThan(fullWeightCount * 4 / 10), lessThan(fullWeightCount * 6 / 10)));
+
+		hashable1.setSlowStartWeight(0.75);
+
+		for (String randomPath : randomPaths) {
+			if (halfWeightSelections.get(randomPath) == hashable1) {
+				assertThat(consistentHasher.selectHashable(hashables, dispersion, randomPath), equalTo(hashable1));
+			}
+		}
+	}
+
 	@Test
 	public void testPatternBasedHashing() throws Exception {
 		// use regex to standardize path